@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/chaos"
+)
+
+var (
+	interval   = flag.Duration("interval", 30*time.Minute, "Average time between two injected faults.")
+	seed       = flag.Int64("seed", 0, "Seed of the random schedule, the same seed injects the same faults after the same delays. Default is the start time.")
+	outPutPath = flag.String("path", "", "Identify the path of the soak log files and of the recorded events.")
+	runTime    = flag.Duration("runTime", 48*time.Hour, "Run time duration.")
+)
+
+func main() {
+	flag.Parse()
+	if *outPutPath == "" {
+		if runtime.GOOS == "windows" {
+			*outPutPath = "C:\\tmp\\soakTest"
+		} else {
+			*outPutPath = "/tmp/soakTest"
+		}
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	os.MkdirAll(*outPutPath, 0755)
+	eventsPath := filepath.Join(*outPutPath, chaos.EventsFileName)
+	f, err := os.OpenFile(eventsPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("Failed to open %s: %v\n", eventsPath, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	fmt.Printf("Injecting faults every %s on average with seed %d, recording them in %s\n", *interval, *seed, eventsPath)
+	scheduler := chaos.Start(chaos.DefaultFaults(filepath.Join(*outPutPath, "*.log")), *interval, *seed, f)
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	select {
+	case <-stop:
+	case <-time.After(*runTime):
+	}
+	// the fault in flight is finished, so the host is not left without network or with the clock moved
+	scheduler.Stop()
+}
@@ -0,0 +1,113 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	canarySuccess             = "Success"
+	canaryConsecutiveFailures = "ConsecutiveFailures"
+	canaryDuration            = "Duration"
+)
+
+// canary runs the suite on an interval until interrupted. Every iteration gets its own run id, so the log groups of
+// an iteration can be swept once it rotates out, and its own namespace, so an iteration never validates metrics
+// emitted by the previous one. The namespaces of the suite are scoped to the run id of the iteration, a -namespace
+// passed is rotated through a ring of -rotate namespaces instead.
+func canary(args []string) error {
+	fs := flag.NewFlagSet("canary", flag.ExitOnError)
+	opts := registerSuiteOptions(fs)
+	interval := fs.Duration("interval", 30*time.Minute, "Time between the start of two iterations")
+	iterations := fs.Int("iterations", 0, "Number of iterations to run. Default is 0, which runs until interrupted")
+	rotate := fs.Int("rotate", 3, "Number of namespaces and run ids to rotate through. Log groups of an iteration are deleted when it rotates out")
+	metricNamespace := fs.String("metricNamespace", "CWAgentCanary", "CloudWatch namespace to publish the canary results to")
+	fs.Parse(args)
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	if *rotate < 1 {
+		return fmt.Errorf("-rotate must be at least 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runIds := make([]string, *rotate)
+	consecutiveFailures := 0
+	for i := 0; *iterations == 0 || i < *iterations; i++ {
+		slot := i % *rotate
+		if runIds[slot] != "" {
+			swept, err := awsservice.SweepLogGroupsForRun(runIds[slot], false)
+			if err != nil {
+				log.Printf("Failed to sweep log groups of run %s: %v", runIds[slot], err)
+			}
+			log.Printf("Swept %d log groups of run %s", len(swept), runIds[slot])
+		}
+		runIds[slot] = runid.New(time.Now())
+
+		namespace := *opts.namespace
+		extraArgs := []string{"-healthNamespace=" + *metricNamespace}
+		if namespace != "" {
+			namespace = fmt.Sprintf("%s-%d", namespace, slot)
+		} else {
+			extraArgs = append(extraArgs, "-runScopedNamespace")
+		}
+		extraArgs = append(extraArgs, fs.Args()...)
+
+		iterationStart := time.Now()
+		r, err := runSuite(opts, namespace, extraArgs, []string{runid.Env + "=" + runIds[slot]})
+		if err != nil {
+			log.Printf("Canary iteration %d could not run: %v", i, err)
+		}
+		if err == nil && r.Passed {
+			consecutiveFailures = 0
+		} else {
+			consecutiveFailures++
+		}
+		log.Printf("Canary iteration %d of %s finished, passed: %v, consecutive failures: %d", i, *opts.suite, r.Passed, consecutiveFailures)
+
+		if err = publishCanaryResult(*metricNamespace, *opts.suite, r.Passed, consecutiveFailures, time.Since(iterationStart)); err != nil {
+			log.Printf("Failed to publish canary result: %v", err)
+		}
+
+		if *iterations != 0 && i == *iterations-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("Canary interrupted after %d iterations", i+1)
+			return nil
+		case <-time.After(time.Until(iterationStart.Add(*interval))):
+		}
+	}
+	return nil
+}
+
+func publishCanaryResult(namespace, suite string, passed bool, consecutiveFailures int, duration time.Duration) error {
+	success := 0.0
+	if passed {
+		success = 1
+	}
+	dims := []types.Dimension{{Name: aws.String("Suite"), Value: aws.String(suite)}}
+	return awsservice.PutMetricData(namespace, []types.MetricDatum{
+		{MetricName: aws.String(canarySuccess), Dimensions: dims, Value: aws.Float64(success), Unit: types.StandardUnitCount},
+		{MetricName: aws.String(canaryConsecutiveFailures), Dimensions: dims, Value: aws.Float64(float64(consecutiveFailures)), Unit: types.StandardUnitCount},
+		{MetricName: aws.String(canaryDuration), Dimensions: dims, Value: aws.Float64(duration.Seconds()), Unit: types.StandardUnitSeconds},
+	})
+}
@@ -0,0 +1,308 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentconfig"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	testDirectory = "test"
+	usage         = `cwa-test lists and runs the integration test suites of this repo.
+
+Usage:
+  cwa-test list [-suite <name>]
+  cwa-test run -suite <name> [flags] [-- extra test args]
+  cwa-test canary -suite <name> [flags] [-- extra test args]
+  cwa-test fanout -suite <name> -targets <role arn>@<region>,... [flags] [-- extra test args]
+  cwa-test history -agentVersion <version> [flags]
+  cwa-test validate [-suite <name>]
+
+Run "cwa-test run -h", "cwa-test canary -h", "cwa-test fanout -h" or "cwa-test history -h" for the flags.`
+)
+
+// report is written once a suite finishes so CI can pick up the result without parsing the test output
+type report struct {
+	Suite     string        `json:"suite"`
+	RunId     string        `json:"run_id"`
+	Passed    bool          `json:"passed"`
+	StartTime time.Time     `json:"start_time"`
+	Duration  time.Duration `json:"duration"`
+	Command   []string      `json:"command"`
+	Failures  []string      `json:"failures,omitempty"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "list":
+		err = list(os.Args[2:])
+	case "run":
+		err = run(os.Args[2:])
+	case "canary":
+		err = canary(os.Args[2:])
+	case "fanout":
+		err = fanout(os.Args[2:])
+	case "history":
+		err = history(os.Args[2:])
+	case "validate":
+		err = validate(os.Args[2:])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// list prints every suite under ./test, or the tests of a single suite when -suite is provided
+func list(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	suite := fs.String("suite", "", "List the tests of this suite instead of all suites")
+	fs.Parse(args)
+
+	if *suite != "" {
+		cmd := exec.Command("go", "test", "-list", ".", "./"+filepath.ToSlash(filepath.Join(testDirectory, *suite)))
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}
+
+	suites, err := findSuites()
+	if err != nil {
+		return err
+	}
+	for _, s := range suites {
+		fmt.Println(s)
+	}
+	return nil
+}
+
+// validate checks the agent configs of every suite, or of a single suite when -suite is provided, without the agent
+// or any AWS resource, so CI can fail on a malformed config before provisioning the hosts
+func validate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	suite := fs.String("suite", "", "Validate the agent configs of this suite instead of all suites")
+	fs.Parse(args)
+
+	dir := testDirectory
+	if *suite != "" {
+		dir = filepath.Join(testDirectory, *suite)
+	}
+	if err := agentconfig.ValidateDir(dir); err != nil {
+		return err
+	}
+	fmt.Printf("agent configs under %s are valid\n", dir)
+	return nil
+}
+
+// findSuites returns every directory under ./test that has at least one _test.go file
+func findSuites() ([]string, error) {
+	suiteSet := map[string]struct{}{}
+	err := filepath.Walk(testDirectory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), "_test.go") {
+			rel, err := filepath.Rel(testDirectory, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			suiteSet[filepath.ToSlash(rel)] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	suites := make([]string, 0, len(suiteSet))
+	for s := range suiteSet {
+		suites = append(suites, s)
+	}
+	sort.Strings(suites)
+	return suites, nil
+}
+
+// suiteOptions are the flags shared by run and canary
+type suiteOptions struct {
+	suite        *string
+	runners      *string
+	computeType  *string
+	region       *string
+	namespace    *string
+	timeout      *time.Duration
+	reportPath   *string
+	agentVersion *string
+	historyTable *string
+	// label prefixes the output of the suite, so the output of suites running concurrently can be told apart
+	label string
+}
+
+func registerSuiteOptions(fs *flag.FlagSet) suiteOptions {
+	return suiteOptions{
+		suite:        fs.String("suite", "", "Suite to run, as printed by cwa-test list"),
+		runners:      fs.String("runners", "", "Comma-delimited list of runners (plugins) to run. Default is empty, which runs all"),
+		computeType:  fs.String("computeType", "", "EC2/ECS/EKS. Default is empty, which detects it from the host"),
+		region:       fs.String("region", "", "AWS region for the tests. Default is the region of the default config resolution"),
+		namespace:    fs.String("namespace", "", "CloudWatch namespace to validate metrics in. Default is the namespace of each suite"),
+		timeout:      fs.Duration("timeout", time.Hour, "Maximum duration of the suite"),
+		reportPath:   fs.String("report", "", "Path to write the JSON report to. Default is cwa-test-<suite>.json"),
+		agentVersion: fs.String("agentVersion", "", "Version of the agent under test the result is recorded for in the history table"),
+		historyTable: fs.String("historyTable", "", "DynamoDB table to record the result in, ex "+awsservice.ResultHistoryTable+". Default is empty, which does not record it"),
+	}
+}
+
+func (o suiteOptions) validate() error {
+	if *o.suite == "" {
+		return fmt.Errorf("-suite is required")
+	}
+	if *o.historyTable != "" && *o.agentVersion == "" {
+		return fmt.Errorf("-agentVersion is required to record the result in %s", *o.historyTable)
+	}
+	if *o.reportPath == "" {
+		*o.reportPath = fmt.Sprintf("cwa-test-%s.json", strings.ReplaceAll(*o.suite, "/", "-"))
+	}
+	return nil
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	opts := registerSuiteOptions(fs)
+	fs.Parse(args)
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	// anything after "--" is passed to the suite as is
+	r, err := runSuite(opts, *opts.namespace, fs.Args(), nil)
+	if err != nil {
+		return err
+	}
+	if !r.Passed {
+		return fmt.Errorf("suite %s failed", *opts.suite)
+	}
+	return nil
+}
+
+// runSuite runs the suite once with the namespace, extra test args and extra environment variables and writes the report
+func runSuite(opts suiteOptions, namespace string, extraArgs []string, extraEnv []string) (report, error) {
+	testArgs := []string{
+		"test", "./" + filepath.ToSlash(filepath.Join(testDirectory, *opts.suite)),
+		"-p", "1", "-v", "-count=1", "-timeout", opts.timeout.String(),
+		"-args",
+	}
+	if *opts.computeType != "" {
+		testArgs = append(testArgs, "-computeType="+*opts.computeType)
+	}
+	if *opts.runners != "" {
+		testArgs = append(testArgs, "-plugins="+*opts.runners)
+	}
+	if namespace != "" {
+		testArgs = append(testArgs, "-namespace="+namespace)
+	}
+	testArgs = append(testArgs, extraArgs...)
+
+	// the suite is given the run id so the result recorded in the history can be matched with the resources it created
+	runId := runIdOf(extraEnv)
+	if runId == "" {
+		runId = runid.Get()
+		extraEnv = append(extraEnv, runid.Env+"="+runId)
+	}
+
+	cmd := exec.Command("go", testArgs...)
+	cmd.Env = append(os.Environ(), extraEnv...)
+	if *opts.region != "" {
+		cmd.Env = append(cmd.Env, "AWS_REGION="+*opts.region)
+	}
+
+	r := report{
+		Suite:     *opts.suite,
+		RunId:     runId,
+		StartTime: time.Now(),
+		Command:   append([]string{"go"}, testArgs...),
+	}
+	log.Printf("%sRunning %s", opts.label, strings.Join(r.Command, " "))
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return r, err
+	}
+	cmd.Stderr = cmd.Stdout
+	if err = cmd.Start(); err != nil {
+		return r, err
+	}
+	r.Failures = streamProgress(stdout, opts.label)
+	runErr := cmd.Wait()
+
+	r.Duration = time.Since(r.StartTime)
+	r.Passed = runErr == nil
+	if err = writeReport(*opts.reportPath, r); err != nil {
+		return r, err
+	}
+	log.Printf("%sSuite %s finished in %s, passed: %v, report written to %s", opts.label, r.Suite, r.Duration, r.Passed, *opts.reportPath)
+	if *opts.historyTable != "" {
+		if err = recordHistory(*opts.historyTable, *opts.agentVersion, r); err != nil {
+			log.Printf("Failed to record the result of %s in %s: %v", r.Suite, *opts.historyTable, err)
+		}
+	}
+	return r, nil
+}
+
+// runIdOf returns the run id set by the environment variables, empty when they do not set it
+func runIdOf(env []string) string {
+	for _, e := range env {
+		if strings.HasPrefix(e, runid.Env+"=") {
+			return strings.TrimPrefix(e, runid.Env+"=")
+		}
+	}
+	return ""
+}
+
+// streamProgress echoes the test output as it arrives, each line prefixed with the label, and collects the lines
+// reporting a failure
+func streamProgress(r io.Reader, label string) []string {
+	var failures []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fmt.Println(label + line)
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "--- FAIL") || strings.Contains(trimmed, "Failed") {
+			failures = append(failures, trimmed)
+		}
+	}
+	return failures
+}
+
+func writeReport(path string, r interface{}) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
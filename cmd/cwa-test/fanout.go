@@ -0,0 +1,177 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+// target is an account and region a suite is fanned out to. An empty role runs the suite with the credentials of
+// cwa-test itself, in the region.
+type target struct {
+	RoleArn string `json:"role_arn,omitempty"`
+	Account string `json:"account,omitempty"`
+	Region  string `json:"region"`
+}
+
+func (t target) String() string {
+	if t.Account == "" {
+		return t.Region
+	}
+	return t.Account + "/" + t.Region
+}
+
+// targetResult is the result of the suite in one target, Error is set when the suite could not run there at all
+type targetResult struct {
+	target
+	Error  string  `json:"error,omitempty"`
+	Report *report `json:"report,omitempty"`
+}
+
+// fanoutReport aggregates the results of every target, the suite passed when it passed in all of them
+type fanoutReport struct {
+	Suite     string         `json:"suite"`
+	RunId     string         `json:"run_id"`
+	Passed    bool           `json:"passed"`
+	StartTime time.Time      `json:"start_time"`
+	Duration  time.Duration  `json:"duration"`
+	Targets   []targetResult `json:"targets"`
+}
+
+// fanout runs the suite in every target concurrently, with the credentials of the role of the target, and writes a
+// single report of every target. The targets share the run id, so the resources of the run can be found in every
+// account from their names alone.
+func fanout(args []string) error {
+	fs := flag.NewFlagSet("fanout", flag.ExitOnError)
+	opts := registerSuiteOptions(fs)
+	targetList := fs.String("targets", "", "Comma-delimited <role arn>@<region> to run the suite in, ex arn:aws:iam::123456789012:role/cwa-test@us-west-2,arn:aws-cn:iam::123456789012:role/cwa-test@cn-north-1. A target without a role, ex @eu-west-1, runs with the credentials of cwa-test")
+	parallel := fs.Int("parallel", 4, "Number of targets to run the suite in at the same time")
+	sessionDuration := fs.Duration("sessionDuration", time.Hour, "Lifetime of the credentials of the assumed roles, which must cover the suite since they are not refreshed")
+	fs.Parse(args)
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	targets, err := parseTargets(*targetList)
+	if err != nil {
+		return err
+	}
+	if *parallel < 1 {
+		return fmt.Errorf("-parallel must be at least 1")
+	}
+	if *sessionDuration < *opts.timeout {
+		log.Printf("-sessionDuration %s is shorter than -timeout %s, the suites fail once the credentials expire", *sessionDuration, *opts.timeout)
+	}
+
+	r := fanoutReport{
+		Suite:     *opts.suite,
+		RunId:     runid.Get(),
+		StartTime: time.Now(),
+		Targets:   make([]targetResult, len(targets)),
+	}
+	sem := make(chan struct{}, *parallel)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t target) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			r.Targets[i] = runTarget(opts, t, r.RunId, *sessionDuration, fs.Args())
+		}(i, t)
+	}
+	wg.Wait()
+
+	r.Duration = time.Since(r.StartTime)
+	r.Passed = true
+	for _, t := range r.Targets {
+		if t.Report == nil || !t.Report.Passed {
+			r.Passed = false
+			log.Printf("Suite %s failed in %s", r.Suite, t.target)
+		}
+	}
+	if err = writeReport(*opts.reportPath, r); err != nil {
+		return err
+	}
+	log.Printf("Suite %s finished in %d targets in %s, passed: %v, report written to %s", r.Suite, len(targets), r.Duration, r.Passed, *opts.reportPath)
+	if !r.Passed {
+		return fmt.Errorf("suite %s failed", *opts.suite)
+	}
+	return nil
+}
+
+// runTarget runs the suite in the target, with a report of its own next to the aggregated one
+func runTarget(opts suiteOptions, t target, runId string, sessionDuration time.Duration, extraArgs []string) targetResult {
+	result := targetResult{target: t}
+	env := []string{runid.Env + "=" + runId}
+	if t.RoleArn != "" {
+		creds, err := awsservice.AssumeRole(t.RoleArn, runid.Name("fanout"), t.Region, sessionDuration)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		env = append(env,
+			"AWS_ACCESS_KEY_ID="+creds.AccessKeyID,
+			"AWS_SECRET_ACCESS_KEY="+creds.SecretAccessKey,
+			"AWS_SESSION_TOKEN="+creds.SessionToken,
+		)
+	}
+
+	region := t.Region
+	reportPath := strings.TrimSuffix(*opts.reportPath, ".json") + "-" + strings.ReplaceAll(t.String(), "/", "-") + ".json"
+	opts.region = &region
+	opts.reportPath = &reportPath
+	opts.label = "[" + t.String() + "] "
+	r, err := runSuite(opts, *opts.namespace, extraArgs, env)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Report = &r
+	return result
+}
+
+// parseTargets parses the comma-delimited <role arn>@<region> of -targets, the account of a target is the one of
+// its role
+func parseTargets(s string) ([]target, error) {
+	var targets []target
+	// a suite running twice in the same account and region would validate the telemetry of the other
+	seen := map[string]bool{}
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		at := strings.LastIndex(spec, "@")
+		if at < 0 || at == len(spec)-1 {
+			return nil, fmt.Errorf("target %q is not <role arn>@<region>", spec)
+		}
+		t := target{RoleArn: spec[:at], Region: spec[at+1:]}
+		if t.RoleArn != "" {
+			parsed, err := arn.Parse(t.RoleArn)
+			if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+				return nil, fmt.Errorf("target %q does not have an iam role arn", spec)
+			}
+			t.Account = parsed.AccountID
+		}
+		if seen[t.String()] {
+			return nil, fmt.Errorf("target %s is listed more than once", t)
+		}
+		seen[t.String()] = true
+		targets = append(targets, t)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("-targets is required")
+	}
+	return targets, nil
+}
@@ -0,0 +1,74 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+// recordHistory puts the result of the suite in the history table so later runs can be compared with it
+func recordHistory(table, agentVersion string, r report) error {
+	return awsservice.PutRunResult(table, awsservice.RunResult{
+		AgentVersion:    agentVersion,
+		Timestamp:       r.StartTime.UnixMilli(),
+		RunId:           r.RunId,
+		Suite:           r.Suite,
+		Passed:          r.Passed,
+		DurationSeconds: r.Duration.Seconds(),
+		Failures:        r.Failures,
+	})
+}
+
+// history prints the prior runs of an agent version, oldest first, for trend reporting
+func history(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	agentVersion := fs.String("agentVersion", "", "Version of the agent to list the runs of")
+	table := fs.String("historyTable", awsservice.ResultHistoryTable, "DynamoDB table the results are recorded in")
+	suite := fs.String("suite", "", "Only list the runs of this suite. Default is empty, which lists all")
+	since := fs.Duration("since", 30*24*time.Hour, "How far back to list the runs")
+	asJson := fs.Bool("json", false, "Print the runs as JSON instead of a table")
+	fs.Parse(args)
+	if *agentVersion == "" {
+		return fmt.Errorf("-agentVersion is required")
+	}
+
+	now := time.Now()
+	results, err := awsservice.QueryRunResults(*table, *agentVersion, now.Add(-*since), now)
+	if err != nil {
+		return err
+	}
+	if *suite != "" {
+		filtered := results[:0]
+		for _, r := range results {
+			if r.Suite == *suite {
+				filtered = append(filtered, r)
+			}
+		}
+		results = filtered
+	}
+
+	if *asJson {
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "START\tSUITE\tRUN ID\tPASSED\tDURATION\tFAILURES")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%d\n", r.Time().UTC().Format(time.RFC3339), r.Suite, r.RunId, r.Passed,
+			time.Duration(r.DurationSeconds*float64(time.Second)).Round(time.Second), len(r.Failures))
+	}
+	return w.Flush()
+}
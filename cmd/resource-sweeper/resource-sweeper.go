@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+var (
+	olderThan = flag.Duration("olderThan", 6*time.Hour, "Only sweep resources created before now minus this duration.")
+	dryRun    = flag.Bool("dryRun", false, "Print the resources that would be swept without deleting them.")
+)
+
+// resource-sweeper deletes the log groups, alarms and instances tagged with a test run id that were left behind by
+// aborted runs. The log groups a suite retained by its cleanup policy are kept until their retention expires. Metric
+// namespaces cannot be deleted, the metrics in them expire on their own.
+func main() {
+	flag.Parse()
+	log.Printf("Sweeping test resources older than %s, dry run: %v", olderThan.String(), *dryRun)
+
+	sweepers := map[string]func(time.Duration, bool) ([]string, error){
+		"log group": awsservice.SweepLogGroups,
+		"alarm":     awsservice.SweepAlarms,
+		"instance":  awsservice.SweepInstances,
+	}
+
+	failed := false
+	for resourceType, sweep := range sweepers {
+		swept, err := sweep(*olderThan, *dryRun)
+		for _, name := range swept {
+			log.Printf("Swept %s %s", resourceType, name)
+		}
+		if err != nil {
+			log.Printf("Failed to sweep %ss: %v", resourceType, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		log.Fatal("Sweeper did not complete successfully")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package environment
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	probeTimeout = 2 * time.Second
+
+	kubernetesServiceHostEnv      = "KUBERNETES_SERVICE_HOST"
+	kubernetesServiceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	eksClusterNameTag             = "aws:eks:cluster-name"
+)
+
+// detectedMetaData holds whatever could be inferred from the host the tests are running on.
+// Empty fields mean detection did not find a value and the flag value should be used instead.
+type detectedMetaData struct {
+	ComputeType   computetype.ComputeType
+	InstanceId    string
+	EcsClusterArn string
+	EcsLaunchType string
+	EKSCluster    string
+}
+
+func registerAutoDetect(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.DisableAutoDetect), "disableAutoDetect", false,
+		"Skip probing IMDS/ECS/Kubernetes and rely only on the flags provided")
+}
+
+// shouldAutoDetect is false only when -disableAutoDetect is passed, the detected values fill the flags left empty
+func shouldAutoDetect(data *MetaDataStrings) bool {
+	return !data.DisableAutoDetect
+}
+
+// detectEnvironment probes the Kubernetes service account, the ECS task metadata endpoint and IMDS, in that order,
+// since containers on ECS and EKS can usually also reach IMDS of the underlying host.
+func detectEnvironment() detectedMetaData {
+	d := detectedMetaData{}
+
+	// instance id is useful for every compute type, so always try IMDS
+	if doc, err := awsservice.TryGetImdsMetadata(probeTimeout); err == nil {
+		d.InstanceId = doc.InstanceID
+	} else {
+		logger.Warnf("IMDS is not reachable, skip detecting instance id: %v", err)
+	}
+
+	switch {
+	case detectKubernetes(&d):
+	case detectECS(&d):
+	case d.InstanceId != "":
+		d.ComputeType = computetype.EC2
+	}
+
+	logger.Infof("Auto-detected environment: %+v", d)
+	return d
+}
+
+func detectKubernetes(d *detectedMetaData) bool {
+	if os.Getenv(kubernetesServiceHostEnv) == "" {
+		return false
+	}
+	if _, err := os.Stat(kubernetesServiceAccountToken); err != nil {
+		return false
+	}
+	d.ComputeType = computetype.EKS
+
+	if d.InstanceId == "" {
+		return true
+	}
+	clusterName, err := awsservice.GetInstanceTag(d.InstanceId, eksClusterNameTag)
+	if err != nil {
+		logger.Warnf("Could not detect EKS cluster name: %v", err)
+		return true
+	}
+	d.EKSCluster = clusterName
+	return true
+}
+
+func detectECS(d *detectedMetaData) bool {
+	if awsservice.GetTaskMetadataUri() == "" {
+		return false
+	}
+	d.ComputeType = computetype.ECS
+
+	task, err := awsservice.GetTaskMetadata(probeTimeout)
+	if err != nil {
+		logger.Warnf("Could not read ECS task metadata: %v", err)
+		return true
+	}
+	// the v3 endpoint only returns the cluster name, which is not enough to build the arn
+	if strings.Contains(task.Cluster, ":cluster/") {
+		d.EcsClusterArn = task.Cluster
+	}
+	d.EcsLaunchType = task.LaunchType
+	return true
+}
+
+// applyDetectedMetaData fills the flags left empty with the detected values, the flags passed always win. The
+// validators of the ECS and EKS clusters run the suites on a runner host which is itself an EC2 instance, so nothing
+// detected on a host of another compute type than the one passed is used, not even its instance id.
+func applyDetectedMetaData(data *MetaDataStrings, d detectedMetaData) {
+	if explicit, ok := computetype.FromString(data.ComputeType); ok && explicit != d.ComputeType {
+		return
+	}
+	fillEmpty(&data.ComputeType, string(d.ComputeType))
+	fillEmpty(&data.InstanceId, d.InstanceId)
+	fillEmpty(&data.EcsClusterArn, d.EcsClusterArn)
+	fillEmpty(&data.EcsLaunchType, d.EcsLaunchType)
+	fillEmpty(&data.EKSClusterName, d.EKSCluster)
+}
+
+func fillEmpty(flagValue *string, detected string) {
+	if *flagValue == "" {
+		*flagValue = detected
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package environment
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+)
+
+// parseFlags parses the args with the flags autodetect depends on, registered on a fresh command line
+func parseFlags(t *testing.T, args ...string) *MetaDataStrings {
+	prev := flag.CommandLine
+	flag.CommandLine = flag.NewFlagSet(t.Name(), flag.ContinueOnError)
+	t.Cleanup(func() { flag.CommandLine = prev })
+
+	data := &MetaDataStrings{}
+	registerComputeType(data)
+	registerInstanceId(data)
+	registerEKSData(data)
+	registerAutoDetect(data)
+	require.NoError(t, flag.CommandLine.Parse(args))
+	return data
+}
+
+func TestShouldAutoDetect(t *testing.T) {
+	assert.True(t, shouldAutoDetect(parseFlags(t)))
+	// the suites of CI always pass the compute type, detection still fills the flags left empty
+	assert.True(t, shouldAutoDetect(parseFlags(t, "-computeType=EKS")))
+	assert.False(t, shouldAutoDetect(parseFlags(t, "-computeType=EKS", "-disableAutoDetect")))
+}
+
+func TestApplyDetectedMetaData(t *testing.T) {
+	data := parseFlags(t)
+	applyDetectedMetaData(data, detectedMetaData{ComputeType: computetype.EKS, InstanceId: "i-detected", EKSCluster: "detected-cluster"})
+	assert.Equal(t, "EKS", data.ComputeType)
+	assert.Equal(t, "i-detected", data.InstanceId)
+	assert.Equal(t, "detected-cluster", data.EKSClusterName)
+
+	// the flags passed win over the detected values
+	data = parseFlags(t, "-computeType=eks", "-instanceId=i-flag", "-eksClusterName=flag-cluster")
+	applyDetectedMetaData(data, detectedMetaData{ComputeType: computetype.EKS, InstanceId: "i-detected", EKSCluster: "detected-cluster"})
+	assert.Equal(t, "eks", data.ComputeType)
+	assert.Equal(t, "i-flag", data.InstanceId)
+	assert.Equal(t, "flag-cluster", data.EKSClusterName)
+
+	// the values that were not passed are detected
+	data = &MetaDataStrings{EcsClusterArn: "arn:aws:ecs:us-west-2:123456789012:cluster/flag"}
+	applyDetectedMetaData(data, detectedMetaData{ComputeType: computetype.ECS, EcsLaunchType: "FARGATE"})
+	assert.Equal(t, "ECS", data.ComputeType)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:cluster/flag", data.EcsClusterArn)
+	assert.Equal(t, "FARGATE", data.EcsLaunchType)
+}
+
+// TestApplyDetectedMetaDataKeepsExplicitComputeType is the EKS validator running the suite on a self-hosted EC2
+// runner, which reaches IMDS of the runner host
+func TestApplyDetectedMetaDataKeepsExplicitComputeType(t *testing.T) {
+	for _, computeType := range []string{"EKS", "ECS"} {
+		data := parseFlags(t, "-computeType="+computeType)
+		require.True(t, shouldAutoDetect(data))
+		applyDetectedMetaData(data, detectedMetaData{ComputeType: computetype.EC2, InstanceId: "i-runner"})
+		assert.Equal(t, computeType, data.ComputeType)
+		assert.Empty(t, data.InstanceId)
+	}
+}
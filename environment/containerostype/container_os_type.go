@@ -0,0 +1,26 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package containerostype
+
+import "strings"
+
+// ContainerOsType is the OS of the ECS container instances or EKS nodes the workloads and the agent run on
+type ContainerOsType string
+
+const (
+	LINUX   ContainerOsType = "LINUX"
+	WINDOWS ContainerOsType = "WINDOWS"
+)
+
+var (
+	containerOsTypes = map[string]ContainerOsType{
+		"LINUX":   LINUX,
+		"WINDOWS": WINDOWS,
+	}
+)
+
+func FromString(str string) (ContainerOsType, bool) {
+	c, ok := containerOsTypes[strings.ToUpper(str)]
+	return c, ok
+}
@@ -6,13 +6,35 @@ package environment
 import (
 	"flag"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/containerostype"
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/ecsdeploymenttype"
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/ecslaunchtype"
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/eksdeploymenttype"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentdebug"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentversion"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/artifact"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/cleanup"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/flaky"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/golden"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/leak"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/notify"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/pprof"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/readiness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/suitelock"
 )
 
 type MetaData struct {
@@ -20,6 +42,7 @@ type MetaData struct {
 	EcsLaunchType             ecslaunchtype.ECSLaunchType
 	EcsDeploymentStrategy     ecsdeploymenttype.ECSDeploymentType
 	EksDeploymentStrategy     eksdeploymenttype.EKSDeploymentType
+	ContainerOs               containerostype.ContainerOsType
 	EcsClusterArn             string
 	EcsClusterName            string
 	CwagentConfigSsmParamName string
@@ -32,7 +55,25 @@ type MetaData struct {
 	EKSClusterName            string
 	ProxyUrl                  string
 	AssumeRoleArn             string
+	MinimalPolicyRoleArn      string
+	MissingPermissionRoleArn  string
 	InstanceId                string
+	Namespace                 string
+	KmsKeyArn                 string
+	MetricStreamFirehoseArn   string
+	MetricStreamRoleArn       string
+	MetricStreamBucket        string
+	IPv6Only                  bool
+	Region                    string
+	Partition                 string
+	EndpointOverride          string
+	UpgradeFromVersion        string
+	SoakChaosInterval         time.Duration
+	SoakChaosSeed             int64
+	SpotInterruptionTemplate  string
+	KillStaleListeners        bool
+	// CardinalityCeilings caps the dimension combinations of a metric by its name, * for every other metric
+	CardinalityCeilings map[string]int
 }
 
 type MetaDataStrings struct {
@@ -40,6 +81,7 @@ type MetaDataStrings struct {
 	EcsLaunchType             string
 	EcsDeploymentStrategy     string
 	EksDeploymentStrategy     string
+	ContainerOs               string
 	EcsClusterArn             string
 	CwagentConfigSsmParamName string
 	EcsServiceName            string
@@ -51,7 +93,59 @@ type MetaDataStrings struct {
 	EKSClusterName            string
 	ProxyUrl                  string
 	AssumeRoleArn             string
+	MinimalPolicyRoleArn      string
+	MissingPermissionRoleArn  string
 	InstanceId                string
+	Namespace                 string
+	RunScopedNamespace        bool
+	ArtifactBucket            string
+	NotifySnsTopicArn         string
+	NotifyWebhookUrl          string
+	ReportStdout              bool
+	ReportFile                string
+	ReportBucket              string
+	ReportLogGroup            string
+	HealthNamespace           string
+	Retries                   int
+	QuarantinedRunners        string // input comma delimited list of runner names
+	ApiRateLimits             string
+	ExpectedAgentVersion      string
+	KmsKeyArn                 string
+	MetricStreamFirehoseArn   string
+	MetricStreamRoleArn       string
+	MetricStreamBucket        string
+	ClockSkewTolerance        time.Duration
+	NtpServer                 string
+	IPv6Only                  bool
+	Region                    string
+	Partition                 string
+	EndpointOverride          string
+	LeakSampleInterval        time.Duration
+	MaxRssGrowthPerHour       float64
+	MaxFdGrowthPerHour        float64
+	MaxThreadGrowthPerHour    float64
+	AgentDebug                bool
+	AgentAwsSdkLogLevel       string
+	PprofAddress              string
+	PprofCpuDuration          time.Duration
+	PprofCapturePoints        string // input comma delimited list of durations
+	AgentReadyTimeout         time.Duration
+	AgentReadyMarkers         string // input comma delimited list of log lines
+	UpgradeFromVersion        string
+	CardinalityCeilings       string // input comma delimited list of metric=ceiling
+	SuiteLockTable            string
+	SuiteLockWait             time.Duration
+	SoakChaosInterval         time.Duration
+	SoakChaosSeed             int64
+	SpotInterruptionTemplate  string
+	KillStaleListeners        bool
+	MaxStartupLatency         time.Duration
+	UpdateGolden              bool
+	CleanupPolicy             string
+	CleanupPolicies           string // input comma delimited list of suite=policy
+	CleanupRetention          time.Duration
+	DisableAutoDetect         bool
+	Verbose                   bool
 }
 
 func registerComputeType(dataString *MetaDataStrings) {
@@ -83,6 +177,10 @@ func registerEKSData(d *MetaDataStrings) {
 	flag.StringVar(&(d.EksDeploymentStrategy), "eksDeploymentStrategy", "", "Daemon/Replica/Sidecar")
 }
 
+func registerContainerOs(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.ContainerOs), "containerOs", "", "OS of the ECS container instances or EKS nodes, linux or windows. Default is empty, which is linux")
+}
+
 func registerPluginTestsToExecute(dataString *MetaDataStrings) {
 	flag.StringVar(&(dataString.EC2PluginTests), "plugins", "", "Comma-delimited list of plugins to test. Default is empty, which tests all")
 }
@@ -101,12 +199,148 @@ func fillComputeType(e *MetaData, data *MetaDataStrings) {
 
 func registerAssumeRoleArn(dataString *MetaDataStrings) {
 	flag.StringVar(&(dataString.AssumeRoleArn), "assumeRoleArn", "", "Arn for assume role to be used")
+	flag.StringVar(&(dataString.MinimalPolicyRoleArn), "minimalPolicyRoleArn", "", "Arn of a role with only CloudWatchAgentServerPolicy attached, the agent must deliver with it")
+	flag.StringVar(&(dataString.MissingPermissionRoleArn), "missingPermissionRoleArn", "", "Arn of a role whose permissions boundary denies logs:PutLogEvents, the agent must report the AccessDenied with it")
 }
 
 func registerInstanceId(dataString *MetaDataStrings) {
 	flag.StringVar(&(dataString.InstanceId), "instanceId", "", "ec2 instance ID that is being used by a test")
 }
 
+func registerKmsKeyArn(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.KmsKeyArn), "kmsKeyArn", "", "Arn of the KMS key to encrypt test log groups with. Default is empty, which skips the KMS tests")
+}
+
+func registerMetricStream(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.MetricStreamFirehoseArn), "metricStreamFirehoseArn", "", "Arn of the firehose delivery stream the metric stream tests stream to. Default is empty, which skips the metric stream tests")
+	flag.StringVar(&(dataString.MetricStreamRoleArn), "metricStreamRoleArn", "", "Arn of the role CloudWatch assumes to write to the firehose delivery stream")
+	flag.StringVar(&(dataString.MetricStreamBucket), "metricStreamBucket", "", "s3 bucket the firehose delivery stream writes the metric stream records to")
+}
+
+func registerNamespace(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.Namespace), "namespace", "", "CloudWatch namespace to validate metrics in. Default is empty, which uses the suite namespace")
+	flag.BoolVar(&(dataString.RunScopedNamespace), "runScopedNamespace", false, "Suffix the namespace with the run id, ex CWAgentTest/<run id>, so concurrent runs do not validate each other's metrics")
+}
+
+func registerArtifactBucket(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.ArtifactBucket), "artifactBucket", "", "s3 bucket to upload agent logs and config of failed runners to. Default is empty, which disables the upload")
+}
+
+func registerNotifiers(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.NotifySnsTopicArn), "notifySnsTopicArn", "", "sns topic to publish suite results to. Default is empty, which disables the notification")
+	flag.StringVar(&(dataString.NotifyWebhookUrl), "notifyWebhookUrl", "", "webhook, e.g. a slack incoming webhook, to post suite results to. Default is empty, which disables the notification")
+}
+
+func registerReporters(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.ReportStdout), "reportStdout", true, "Print the human-readable suite results. Default is true")
+	flag.StringVar(&(dataString.ReportFile), "reportFile", "", "File the JSON suite results are appended to, one line per suite. Default is empty, which disables the file report")
+	flag.StringVar(&(dataString.ReportBucket), "reportBucket", "", "s3 bucket to upload the JSON suite results to, under reports/<run id>/. Default is empty, which disables the upload")
+	flag.StringVar(&(dataString.ReportLogGroup), "reportLogGroup", "", "CloudWatch log group to put the JSON suite results to, in a log stream named after the run id. Default is empty, which disables the log events")
+}
+
+func registerHealthNamespace(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.HealthNamespace), "healthNamespace", "", "CloudWatch namespace the framework publishes its own health metrics to. Default is empty, which disables publishing")
+}
+
+func registerFlakyPolicy(dataString *MetaDataStrings) {
+	flag.IntVar(&(dataString.Retries), "retries", 0, "Number of times a failed runner is retried. Default is 0, which does not retry")
+	flag.StringVar(&(dataString.QuarantinedRunners), "quarantine", "", "Comma-delimited list of known flaky runners, which are reported but never fail the suite")
+}
+
+func registerApiRateLimits(dataString *MetaDataStrings) {
+	// the limits are applied while parsing, so an invalid spec fails flag parsing with the error
+	flag.Func("apiTps", "Comma-delimited list of operation=tps client side rate limits, * for every other operation, ex GetMetricData=5,*=25. Default is empty, which does not limit", func(spec string) error {
+		dataString.ApiRateLimits = spec
+		return awsservice.SetRateLimits(spec)
+	})
+}
+
+func registerExpectedAgentVersion(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.ExpectedAgentVersion), "expectedAgentVersion", "", "Version the installed agent must match, ex the version of the build under test. Default is empty, which does not check")
+}
+
+func registerLeakDetection(dataString *MetaDataStrings) {
+	flag.DurationVar(&(dataString.LeakSampleInterval), "leakSampleInterval", 10*time.Second, "How often the RSS, VSZ, open FDs and threads of the agent are sampled while a runner runs, 0 disables the sampling. Default is 10s")
+	flag.Float64Var(&(dataString.MaxRssGrowthPerHour), "maxRssGrowthPerHour", 0, "Fail a runner whose agent RSS grows faster than this many bytes per hour. Default is 0, which does not check")
+	flag.Float64Var(&(dataString.MaxFdGrowthPerHour), "maxFdGrowthPerHour", 0, "Fail a runner whose agent open FDs grow faster than this many per hour. Default is 0, which does not check")
+	flag.Float64Var(&(dataString.MaxThreadGrowthPerHour), "maxThreadGrowthPerHour", 0, "Fail a runner whose agent threads grow faster than this many per hour. Default is 0, which does not check")
+}
+
+func registerAgentDebug(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.AgentDebug), "agentDebug", false, "Start the agent with agent.debug set, the agent log written while a failed runner ran is kept and uploaded with the artifacts. Default is false")
+	flag.StringVar(&(dataString.AgentAwsSdkLogLevel), "agentAwsSdkLogLevel", "", "| delimited aws_sdk_log_level to start the agent with, ex LogDebug|LogDebugWithHTTPBody. Default is empty, which keeps the level of the agent configs")
+}
+
+func registerPprof(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.PprofAddress), "pprofAddress", "", "host:port a debug build of the agent serves pprof on, ex localhost:6060, profiles are captured while a runner runs and uploaded with the artifacts. Default is empty, which disables the capture")
+	flag.DurationVar(&(dataString.PprofCpuDuration), "pprofCpuDuration", 30*time.Second, "How long each CPU profile samples the agent for, 0 only captures the heap and goroutine profiles. Default is 30s")
+	flag.StringVar(&(dataString.PprofCapturePoints), "pprofCapturePoints", "", "Comma delimited offsets after the agent starts to capture profiles at, ex 5m,30m. Profiles are always captured before the agent stops. Default is empty")
+}
+
+func registerAgentReadiness(dataString *MetaDataStrings) {
+	flag.DurationVar(&(dataString.AgentReadyTimeout), "agentReadyTimeout", 0, "How long to wait for the started agent to run and flush before the measurement window starts. The default markers are only logged with -agentDebug. Default is 0, which disables the wait")
+	flag.StringVar(&(dataString.AgentReadyMarkers), "agentReadyMarkers", "", "Comma delimited agent log lines which show the agent flushed, ex \"Wrote batch of\". Default is empty, which uses the debug line of the first batch the telegraf outputs wrote")
+}
+
+func registerUpgradeFromVersion(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.UpgradeFromVersion), "upgradeFromVersion", "", "Released agent version the upgrade test installs before upgrading to the build under test, ex 1.300032.2. Default is empty, which uses the latest release")
+}
+
+func registerCardinalityCeilings(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.CardinalityCeilings), "cardinalityCeilings", "*=100", "Comma-delimited list of metric=ceiling caps of the distinct dimension combinations a metric of the host may have, * for every other metric, ex procstat_cpu_usage=20,*=100. Default is *=100")
+}
+
+func registerSuiteLock(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.SuiteLockTable), "suiteLockTable", "", "DynamoDB table the exclusive suites lock, ex "+awsservice.SuiteLockTable+", so concurrent runs in the account take turns. Default is empty, which does not lock")
+	flag.DurationVar(&(dataString.SuiteLockWait), "suiteLockWait", 30*time.Minute, "How long an exclusive suite waits for the lock held by another run before it fails. Default is 30m")
+}
+
+func registerSoakChaos(dataString *MetaDataStrings) {
+	flag.DurationVar(&(dataString.SoakChaosInterval), "soakChaosInterval", 0, "Average time between two faults, e.g. an agent restart or a network block, the soak test injects, ex 30m. Default is 0, which injects none")
+	flag.Int64Var(&(dataString.SoakChaosSeed), "soakChaosSeed", 0, "Seed of the random fault schedule of the soak test, to replay the schedule of a previous run. Default is 0, which seeds with the start time")
+}
+
+func registerSpotInterruption(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.SpotInterruptionTemplate), "spotInterruptionTemplateId", "", "Id of the FIS experiment template interrupting the spot instance the spot interruption test runs on. Default is empty, which simulates the interruption notice")
+}
+
+func registerIPv6Only(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.IPv6Only), "ipv6Only", false, "The host is in an IPv6-only subnet, the clients use the dual-stack endpoints and IMDS over IPv6. Default is false")
+}
+
+func registerEndpoints(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.Region), "region", "", "Region the clients call instead of the one of the default config, ex eu-west-1. Default is empty, which resolves it from the environment")
+	flag.StringVar(&(dataString.Partition), "partition", "", "Partition of -region, ex aws-cn. Default is empty, which infers it from the region")
+	flag.StringVar(&(dataString.EndpointOverride), "endpointOverride", "", "Endpoint every client except IMDS calls, ex http://localhost:4566. Default is empty, which uses the endpoints of the region or "+awsservice.EndpointOverrideEnv)
+}
+
+func registerKillStaleListeners(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.KillStaleListeners), "killStaleListeners", false, "Kill the processes a previous run left listening on the StatsD, collectd, OTLP and EMF ports of the agent instead of failing the suite. Default is false, which only stops a stale agent")
+}
+
+func registerMaxStartupLatency(dataString *MetaDataStrings) {
+	flag.DurationVar(&(dataString.MaxStartupLatency), "maxStartupLatency", 0, "Fail a runner whose metrics or logs take longer than this from the agent start to their first visible datapoint or log event, ex 2m. Default is 0, which only reports the latency")
+}
+
+func registerUpdateGolden(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.UpdateGolden), "updateGolden", false, "Rewrite the golden documents the performance logs are diffed against with the structure of the logs instead of failing on the differences. Default is false")
+}
+
+func registerCleanupPolicy(dataString *MetaDataStrings) {
+	flag.StringVar(&(dataString.CleanupPolicy), "cleanupPolicy", string(cleanup.OnSuccess), "When the runners release their resources, always, on-success or never, the resources of the runners not released are retained for investigation. Default is on-success")
+	flag.StringVar(&(dataString.CleanupPolicies), "cleanupPolicies", "", "Cleanup policy of individual suites, ex CollectDTestRunner=always,StatsDTestRunner=never. Default is empty, which uses -cleanupPolicy for every suite")
+	flag.DurationVar(&(dataString.CleanupRetention), "cleanupRetention", cleanup.DefaultRetention, "How long the sweeper keeps the resources retained for investigation, ex 72h. Default is 24h")
+}
+
+func registerClockSkew(dataString *MetaDataStrings) {
+	flag.DurationVar(&(dataString.ClockSkewTolerance), "clockSkewTolerance", 0, "How far the host clock may drift from CloudWatch, every query window is widened by it, ex 5s. Default is 0")
+	flag.StringVar(&(dataString.NtpServer), "ntpServer", "", "NTP server, ex "+clock.AmazonTimeSyncServer+", the host clock is checked against at setup. Default is empty, which does not check")
+}
+
+func registerVerbose(dataString *MetaDataStrings) {
+	flag.BoolVar(&(dataString.Verbose), "verbose", false, "Print DEBUG level logs of the test framework")
+}
+
 func fillECSData(e *MetaData, data *MetaDataStrings) {
 	if e.ComputeType != computetype.ECS {
 		return
@@ -114,14 +348,14 @@ func fillECSData(e *MetaData, data *MetaDataStrings) {
 
 	ecsLaunchType, ok := ecslaunchtype.FromString(data.EcsLaunchType)
 	if !ok {
-		log.Printf("Invalid launch type %s. This might be because it wasn't provided for non-ECS tests", data.ComputeType)
+		logger.Warnf("Invalid launch type %s. This might be because it wasn't provided for non-ECS tests", data.ComputeType)
 	} else {
 		e.EcsLaunchType = ecsLaunchType
 	}
 
 	ecsDeploymentStrategy, ok := ecsdeploymenttype.FromString(data.EcsDeploymentStrategy)
 	if !ok {
-		log.Printf("Invalid deployment strategy %s. This might be because it wasn't provided for non-ECS tests", data.ComputeType)
+		logger.Warnf("Invalid deployment strategy %s. This might be because it wasn't provided for non-ECS tests", data.ComputeType)
 	} else {
 		e.EcsDeploymentStrategy = ecsDeploymentStrategy
 	}
@@ -132,18 +366,35 @@ func fillECSData(e *MetaData, data *MetaDataStrings) {
 	e.EcsClusterName = awsservice.GetClusterName(data.EcsClusterArn)
 }
 
+func fillContainerOs(e *MetaData, data *MetaDataStrings) {
+	if e.ComputeType != computetype.ECS && e.ComputeType != computetype.EKS {
+		return
+	}
+
+	e.ContainerOs = containerostype.LINUX
+	if data.ContainerOs == "" {
+		return
+	}
+	containerOs, ok := containerostype.FromString(data.ContainerOs)
+	if !ok {
+		logger.Warnf("Invalid container os %s, defaulting to %s", data.ContainerOs, containerostype.LINUX)
+		return
+	}
+	e.ContainerOs = containerOs
+}
+
 func fillEC2PluginTests(e *MetaData, data *MetaDataStrings) {
 	if e.ComputeType != computetype.EC2 {
 		return
 	}
 
 	if len(data.EC2PluginTests) == 0 {
-		log.Println("Testing all EC2 plugins")
+		logger.Infof("Testing all EC2 plugins")
 		return
 	}
 
 	plugins := strings.Split(strings.ReplaceAll(data.EC2PluginTests, " ", ""), ",")
-	log.Printf("Executing subset of plugin tests: %v", plugins)
+	logger.Infof("Executing subset of plugin tests: %v", plugins)
 	m := make(map[string]struct{}, len(plugins))
 	for _, p := range plugins {
 		m[strings.ToLower(p)] = struct{}{}
@@ -151,6 +402,23 @@ func fillEC2PluginTests(e *MetaData, data *MetaDataStrings) {
 	e.EC2PluginTests = m
 }
 
+// fillCardinalityCeilings skips the invalid ceilings instead of failing the suite, like the other optional checks
+func fillCardinalityCeilings(e *MetaData, data *MetaDataStrings) {
+	e.CardinalityCeilings = map[string]int{}
+	for _, entry := range strings.Split(strings.ReplaceAll(data.CardinalityCeilings, " ", ""), ",") {
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		ceiling, err := strconv.Atoi(value)
+		if !ok || name == "" || err != nil || ceiling <= 0 {
+			logger.Errorf("ignoring cardinality ceiling %q, expected metric=ceiling with a positive ceiling", entry)
+			continue
+		}
+		e.CardinalityCeilings[name] = ceiling
+	}
+}
+
 func fillEKSData(e *MetaData, data *MetaDataStrings) {
 	if e.ComputeType != computetype.EKS {
 		return
@@ -158,7 +426,7 @@ func fillEKSData(e *MetaData, data *MetaDataStrings) {
 
 	eksDeploymentStrategy, ok := eksdeploymenttype.FromString(data.EksDeploymentStrategy)
 	if !ok {
-		log.Printf("Invalid deployment strategy %s. This might be because it wasn't provided for non-EKS tests", data.ComputeType)
+		logger.Warnf("Invalid deployment strategy %s. This might be because it wasn't provided for non-EKS tests", data.ComputeType)
 	} else {
 		e.EksDeploymentStrategy = eksDeploymentStrategy
 	}
@@ -170,6 +438,7 @@ func RegisterEnvironmentMetaDataFlags(metaDataStrings *MetaDataStrings) *MetaDat
 	registerComputeType(metaDataStrings)
 	registerECSData(metaDataStrings)
 	registerEKSData(metaDataStrings)
+	registerContainerOs(metaDataStrings)
 	registerBucket(metaDataStrings)
 	registerS3Key(metaDataStrings)
 	registerCwaCommitSha(metaDataStrings)
@@ -178,14 +447,114 @@ func RegisterEnvironmentMetaDataFlags(metaDataStrings *MetaDataStrings) *MetaDat
 	registerProxyUrl(metaDataStrings)
 	registerAssumeRoleArn(metaDataStrings)
 	registerInstanceId(metaDataStrings)
+	registerNamespace(metaDataStrings)
+	registerAutoDetect(metaDataStrings)
+	registerVerbose(metaDataStrings)
+	registerArtifactBucket(metaDataStrings)
+	registerNotifiers(metaDataStrings)
+	registerReporters(metaDataStrings)
+	registerHealthNamespace(metaDataStrings)
+	registerFlakyPolicy(metaDataStrings)
+	registerApiRateLimits(metaDataStrings)
+	registerExpectedAgentVersion(metaDataStrings)
+	registerKmsKeyArn(metaDataStrings)
+	registerMetricStream(metaDataStrings)
+	registerClockSkew(metaDataStrings)
+	registerIPv6Only(metaDataStrings)
+	registerEndpoints(metaDataStrings)
+	registerUpdateGolden(metaDataStrings)
+	registerKillStaleListeners(metaDataStrings)
+	registerMaxStartupLatency(metaDataStrings)
+	registerCleanupPolicy(metaDataStrings)
+	registerLeakDetection(metaDataStrings)
+	registerAgentDebug(metaDataStrings)
+	registerPprof(metaDataStrings)
+	registerAgentReadiness(metaDataStrings)
+	registerUpgradeFromVersion(metaDataStrings)
+	registerCardinalityCeilings(metaDataStrings)
+	registerSuiteLock(metaDataStrings)
+	registerSoakChaos(metaDataStrings)
+	registerSpotInterruption(metaDataStrings)
 	return metaDataStrings
 }
 
+var (
+	setupOnce       sync.Once
+	detected        detectedMetaData
+	tagInstanceOnce sync.Once
+)
+
+// setup configures the framework packages from the flags and probes the host. Suites get the metadata once per
+// test, so this runs only on the first call and the global state is not reset in the middle of a run.
+func setup(data *MetaDataStrings) {
+	logger.SetVerbose(data.Verbose)
+	// a run against the default region and endpoints would test the wrong partition, so it does not start at all
+	if err := awsservice.ConfigureEndpoints(data.Region, data.Partition, data.EndpointOverride); err != nil {
+		logger.Fatalf("failed to configure the region and endpoint overrides of the clients: %v", err)
+	}
+	if data.IPv6Only {
+		if err := awsservice.UseDualStackEndpoints(); err != nil {
+			logger.Fatalf("failed to switch the clients to the dual-stack endpoints: %v", err)
+		}
+	}
+	artifact.SetBucket(data.ArtifactBucket)
+	notify.Configure(data.NotifySnsTopicArn, data.NotifyWebhookUrl)
+	report.Configure(data.ReportStdout, data.ReportFile, data.ReportBucket, data.ReportLogGroup)
+	health.SetNamespace(data.HealthNamespace)
+	flaky.Configure(data.Retries, strings.Split(data.QuarantinedRunners, ","))
+	scopeRunId := ""
+	if data.RunScopedNamespace {
+		scopeRunId = runid.Get()
+	}
+	namespace.Configure(data.Namespace, scopeRunId)
+	agentversion.SetExpected(data.ExpectedAgentVersion)
+	clock.Configure(data.ClockSkewTolerance)
+	leak.Configure(data.LeakSampleInterval, leak.Thresholds{
+		RSS:     data.MaxRssGrowthPerHour,
+		FDs:     data.MaxFdGrowthPerHour,
+		Threads: data.MaxThreadGrowthPerHour,
+	})
+	capturePoints, err := pprof.ParseCapturePoints(data.PprofCapturePoints)
+	if err != nil {
+		logger.Fatalf("invalid -pprofCapturePoints: %v", err)
+	}
+	sdkLogLevel, err := agentdebug.ParseAwsSdkLogLevel(data.AgentAwsSdkLogLevel)
+	if err != nil {
+		logger.Fatalf("invalid -agentAwsSdkLogLevel: %v", err)
+	}
+	agentdebug.Configure(data.AgentDebug, sdkLogLevel)
+	pprof.Configure(data.PprofAddress, data.PprofCpuDuration, capturePoints)
+	readiness.Configure(data.AgentReadyTimeout, strings.Split(data.AgentReadyMarkers, ","))
+	suitelock.Configure(data.SuiteLockTable, data.SuiteLockWait)
+	golden.Configure(data.UpdateGolden)
+	latency.Configure(data.MaxStartupLatency)
+	cleanupPolicy, err := cleanup.ParsePolicy(data.CleanupPolicy)
+	if err != nil {
+		logger.Fatalf("invalid -cleanupPolicy: %v", err)
+	}
+	cleanupPolicies, err := cleanup.ParsePolicies(data.CleanupPolicies)
+	if err != nil {
+		logger.Fatalf("invalid -cleanupPolicies: %v", err)
+	}
+	cleanup.Configure(cleanupPolicy, cleanupPolicies, data.CleanupRetention)
+	if data.NtpServer != "" {
+		clock.CheckOffset(data.NtpServer)
+	}
+	if shouldAutoDetect(data) {
+		detected = detectEnvironment()
+	}
+}
+
 func GetEnvironmentMetaData(data *MetaDataStrings) *MetaData {
+	setupOnce.Do(func() { setup(data) })
+	if shouldAutoDetect(data) {
+		applyDetectedMetaData(data, detected)
+	}
 	metaData := &(MetaData{})
 	fillComputeType(metaData, data)
 	fillECSData(metaData, data)
 	fillEKSData(metaData, data)
+	fillContainerOs(metaData, data)
 	fillEC2PluginTests(metaData, data)
 	metaData.Bucket = data.Bucket
 	metaData.S3Key = data.S3Key
@@ -193,6 +562,35 @@ func GetEnvironmentMetaData(data *MetaDataStrings) *MetaData {
 	metaData.CaCertPath = data.CaCertPath
 	metaData.ProxyUrl = data.ProxyUrl
 	metaData.AssumeRoleArn = data.AssumeRoleArn
+	metaData.MinimalPolicyRoleArn = data.MinimalPolicyRoleArn
+	metaData.MissingPermissionRoleArn = data.MissingPermissionRoleArn
 	metaData.InstanceId = data.InstanceId
+	metaData.Namespace = data.Namespace
+	metaData.KmsKeyArn = data.KmsKeyArn
+	metaData.MetricStreamFirehoseArn = data.MetricStreamFirehoseArn
+	metaData.MetricStreamRoleArn = data.MetricStreamRoleArn
+	metaData.MetricStreamBucket = data.MetricStreamBucket
+	metaData.IPv6Only = data.IPv6Only
+	metaData.Region = awsservice.GetRegion()
+	metaData.Partition = awsservice.GetPartition()
+	metaData.EndpointOverride = data.EndpointOverride
+	metaData.UpgradeFromVersion = data.UpgradeFromVersion
+	metaData.SoakChaosInterval = data.SoakChaosInterval
+	metaData.SoakChaosSeed = data.SoakChaosSeed
+	metaData.SpotInterruptionTemplate = data.SpotInterruptionTemplate
+	metaData.KillStaleListeners = data.KillStaleListeners
+	fillCardinalityCeilings(metaData, data)
+	tagInstanceOnce.Do(func() { tagInstanceForRun(metaData) })
 	return metaData
 }
+
+// tagInstanceForRun tags the EC2 instance under test with the run tags, so the sweeper terminates it when the run
+// aborts before terraform destroys it
+func tagInstanceForRun(metaData *MetaData) {
+	if metaData.ComputeType != computetype.EC2 || metaData.InstanceId == "" {
+		return
+	}
+	if err := awsservice.TagInstancesForRun([]string{metaData.InstanceId}); err != nil {
+		logger.Errorf("Failed to tag instance %s: %v", metaData.InstanceId, err)
+	}
+}
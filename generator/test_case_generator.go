@@ -65,6 +65,18 @@ var testTypeToTestConfig = map[string][]testConfig{
 		//	testDir: "./test/fips",
 		//	targets: map[string]map[string]struct{}{"os": {"rhel8": {}}},
 		//},
+		{
+			testDir: "./test/alarm",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/entity",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/metric_stream",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
 		{
 			testDir: "./test/lvm",
 			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
@@ -73,10 +85,42 @@ var testTypeToTestConfig = map[string][]testConfig{
 			testDir: "./test/proxy",
 			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
 		},
+		{
+			testDir: "./test/common_config",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
 		{
 			testDir: "./test/ssl_cert",
 			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
 		},
+		{
+			testDir: "./test/logs_tls",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir:      "./test/ipv6",
+			terraformDir: "terraform/ec2/ipv6",
+			targets:      map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/upgrade",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}, "ubuntu-22.04": {}}},
+		},
+		{
+			testDir: "./test/systemd_limits",
+			targets: map[string]map[string]struct{}{"os": {"ubuntu-22.04": {}}},
+		},
+		// ubuntu 22.04 mounts the cgroup v2 hierarchy, the suite checks the metrics of the host under the limits of the
+		// systemd unit and of a container
+		{
+			testDir: "./test/cgroup_scope",
+			targets: map[string]map[string]struct{}{"os": {"ubuntu-22.04": {}}},
+		},
+		{
+			testDir:      "./test/privatelink",
+			terraformDir: "terraform/ec2/privatelink",
+			targets:      map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
 		{
 			testDir:      "./test/userdata",
 			terraformDir: "terraform/ec2/userdata",
@@ -87,6 +131,29 @@ var testTypeToTestConfig = map[string][]testConfig{
 			terraformDir: "terraform/ec2/creds",
 			targets:      map[string]map[string]struct{}{"os": {"al2": {}}},
 		},
+		{
+			testDir:      "./test/iam_permission",
+			terraformDir: "terraform/ec2/creds",
+			targets:      map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir:      "./test/spot_interruption",
+			terraformDir: "terraform/ec2/spot",
+			targets:      map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/large_config",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+		{
+			testDir: "./test/cloudwatch_degradation",
+			targets: map[string]map[string]struct{}{"os": {"al2": {}}},
+		},
+	},
+	// the instance types of the matrix cover burstable, nitro, xen and graviton hosts, the suite checks the metrics
+	// fixed by the type, e.g. mem_total, against DescribeInstanceTypes
+	"ec2_instance_type": {
+		{testDir: "./test/metric_value_benchmark", terraformDir: "terraform/ec2/linux"},
 	},
 	/*
 		You can only place 1 mac instance on a dedicate host a single time.
@@ -99,6 +166,9 @@ var testTypeToTestConfig = map[string][]testConfig{
 	"ec2_windows": {
 		{testDir: "../../../test/feature/windows"},
 		{testDir: "../../../test/restart"},
+		{testDir: "../../../test/iis"},
+		{testDir: "../../../test/service_recovery"},
+		{testDir: "../../../test/ground_truth"},
 		// assume role test doesn't add much value, and it already being tested with linux
 		//{testDir: "../../../test/assume_role"},
 	},
@@ -118,6 +188,7 @@ var testTypeToTestConfig = map[string][]testConfig{
 	},
 	"ecs_fargate": {
 		{testDir: "./test/ecs/ecs_metadata"},
+		{testDir: "./test/ecs/ecs_sd"},
 	},
 	"ecs_ec2_daemon": {
 		{testDir: "./test/metric_value_benchmark"},
@@ -142,6 +213,22 @@ var testTypeToTestConfig = map[string][]testConfig{
 			targets: map[string]map[string]struct{}{"arc": {"amd64": {}}},
 		},
 		{testDir: "./test/fluent", terraformDir: "terraform/eks/daemon/fluent/bit"},
+		{
+			testDir: "./test/metric_value_benchmark", terraformDir: "terraform/eks/daemon/install/addon",
+			targets: map[string]map[string]struct{}{"arc": {"amd64": {}}},
+		},
+		{
+			testDir: "./test/metric_value_benchmark", terraformDir: "terraform/eks/daemon/install/helm",
+			targets: map[string]map[string]struct{}{"arc": {"amd64": {}}},
+		},
+		{
+			testDir: "./test/metric_value_benchmark", terraformDir: "terraform/eks/daemon/gpu",
+			targets: map[string]map[string]struct{}{"arc": {"amd64": {}}},
+		},
+		{
+			testDir: "./test/metric_value_benchmark", terraformDir: "terraform/eks/daemon/windows",
+			targets: map[string]map[string]struct{}{"arc": {"amd64": {}}},
+		},
 	},
 	"eks_deployment": {
 		{testDir: "./test/metric_value_benchmark"},
@@ -33,3 +33,11 @@ output "performance-dynamodb-table" {
   value = "CWAPerformanceMetrics"
 }
 
+output "result-history-dynamodb-table" {
+  value = "CWATestResultHistory"
+}
+
+output "suite-lock-dynamodb-table" {
+  value = "CWATestSuiteLock"
+}
+
@@ -92,6 +92,44 @@ resource "aws_iam_role_policy_attachment" "assume_role_policy_attachment" {
   policy_arn = aws_iam_policy.assume_role_policy.arn
 }
 
+#####################################################################
+# Generate IAM Roles with only the managed policy of the agent, one of
+# them bounded so it misses logs:PutLogEvents
+#####################################################################
+resource "aws_iam_role" "minimal_policy_role" {
+  name               = "cwa-integ-minimal-policy-role-${module.common.testing_id}"
+  assume_role_policy = data.aws_iam_policy_document.assume_role_trust_policy.json
+}
+
+resource "aws_iam_role_policy_attachment" "minimal_policy_attachment" {
+  role       = aws_iam_role.minimal_policy_role.name
+  policy_arn = "arn:aws:iam::aws:policy/CloudWatchAgentServerPolicy"
+}
+
+data "aws_iam_policy_document" "missing_permission_boundary_doc" {
+  statement {
+    effect      = "Allow"
+    not_actions = ["logs:PutLogEvents"]
+    resources   = ["*"]
+  }
+}
+
+resource "aws_iam_policy" "missing_permission_boundary" {
+  name   = "cwa-integ-missing-permission-boundary-${module.common.testing_id}"
+  policy = data.aws_iam_policy_document.missing_permission_boundary_doc.json
+}
+
+resource "aws_iam_role" "missing_permission_role" {
+  name                 = "cwa-integ-missing-permission-role-${module.common.testing_id}"
+  assume_role_policy   = data.aws_iam_policy_document.assume_role_trust_policy.json
+  permissions_boundary = aws_iam_policy.missing_permission_boundary.arn
+}
+
+resource "aws_iam_role_policy_attachment" "missing_permission_attachment" {
+  role       = aws_iam_role.missing_permission_role.name
+  policy_arn = "arn:aws:iam::aws:policy/CloudWatchAgentServerPolicy"
+}
+
 #####################################################################
 # Generate EC2 Instance and execute test commands
 #####################################################################
@@ -113,7 +151,11 @@ resource "aws_instance" "cwagent" {
     Name = "cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
   }
 
-  depends_on = [aws_iam_role.assume_role]
+  depends_on = [
+    aws_iam_role.assume_role,
+    aws_iam_role_policy_attachment.minimal_policy_attachment,
+    aws_iam_role_policy_attachment.missing_permission_attachment,
+  ]
 }
 
 resource "null_resource" "integration_test_setup" {
@@ -161,7 +203,7 @@ resource "null_resource" "integration_test_run" {
       "echo run integration test",
       "cd ~/amazon-cloudwatch-agent-test",
       "echo run sanity test && go test ./test/sanity -p 1 -v",
-      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -plugins='${var.plugin_tests}' -cwaCommitSha=${var.cwa_github_sha} -caCertPath=${var.ca_cert_path} -assumeRoleArn=${aws_iam_role.assume_role.arn} -instanceId=${aws_instance.cwagent.id} -v"
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -plugins='${var.plugin_tests}' -cwaCommitSha=${var.cwa_github_sha} -caCertPath=${var.ca_cert_path} -assumeRoleArn=${aws_iam_role.assume_role.arn} -minimalPolicyRoleArn=${aws_iam_role.minimal_policy_role.arn} -missingPermissionRoleArn=${aws_iam_role.missing_permission_role.arn} -instanceId=${aws_instance.cwagent.id} -v"
     ]
   }
 
@@ -0,0 +1,227 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "common" {
+  source = "../../common"
+}
+
+module "basic_components" {
+  source = "../../basic_components"
+
+  region = var.region
+}
+
+#####################################################################
+# Generate EC2 Key Pair for log in access to EC2
+#####################################################################
+
+resource "tls_private_key" "ssh_key" {
+  count     = var.ssh_key_name == "" ? 1 : 0
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}
+
+resource "aws_key_pair" "aws_ssh_key" {
+  count      = var.ssh_key_name == "" ? 1 : 0
+  key_name   = "ec2-key-pair-${module.common.testing_id}"
+  public_key = tls_private_key.ssh_key[0].public_key_openssh
+}
+
+locals {
+  ssh_key_name        = var.ssh_key_name != "" ? var.ssh_key_name : aws_key_pair.aws_ssh_key[0].key_name
+  private_key_content = var.ssh_key_name != "" ? var.ssh_key_value : tls_private_key.ssh_key[0].private_key_pem
+  binary_uri          = "${var.s3_bucket}/integration-test/binary/${var.cwa_github_sha}/linux/${var.arc}/${var.binary_name}"
+}
+
+#####################################################################
+# Generate the VPC with an IPv6-only subnet for the instance
+#####################################################################
+
+# The IPv4 CIDR is required by the VPC, it is only used by the public subnet of the NAT gateway
+resource "aws_vpc" "ipv6" {
+  cidr_block                       = "10.0.0.0/16"
+  assign_generated_ipv6_cidr_block = true
+  enable_dns_support               = true
+  enable_dns_hostnames             = true
+
+  tags = {
+    Name = "cwagent-integ-test-ipv6-${module.common.testing_id}"
+  }
+}
+
+resource "aws_internet_gateway" "ipv6" {
+  vpc_id = aws_vpc.ipv6.id
+}
+
+resource "aws_subnet" "nat" {
+  vpc_id            = aws_vpc.ipv6.id
+  availability_zone = var.availability_zone
+  cidr_block        = cidrsubnet(aws_vpc.ipv6.cidr_block, 8, 0)
+}
+
+resource "aws_route_table" "nat" {
+  vpc_id = aws_vpc.ipv6.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.ipv6.id
+  }
+}
+
+resource "aws_route_table_association" "nat" {
+  subnet_id      = aws_subnet.nat.id
+  route_table_id = aws_route_table.nat.id
+}
+
+resource "aws_eip" "nat" {
+  vpc = true
+}
+
+# The NAT64 of the gateway only serves the hosts without an AAAA record, e.g. github.com to clone the tests. The
+# agent and the framework clients use the dual-stack endpoints, test/ipv6 checks they resolve outside of NAT64.
+resource "aws_nat_gateway" "nat" {
+  allocation_id = aws_eip.nat.id
+  subnet_id     = aws_subnet.nat.id
+  depends_on    = [aws_internet_gateway.ipv6]
+}
+
+resource "aws_subnet" "ipv6_only" {
+  vpc_id                                         = aws_vpc.ipv6.id
+  availability_zone                              = var.availability_zone
+  ipv6_native                                    = true
+  ipv6_cidr_block                                = cidrsubnet(aws_vpc.ipv6.ipv6_cidr_block, 8, 1)
+  assign_ipv6_address_on_creation                = true
+  enable_dns64                                   = true
+  enable_resource_name_dns_aaaa_record_on_launch = true
+}
+
+resource "aws_route_table" "ipv6_only" {
+  vpc_id = aws_vpc.ipv6.id
+
+  route {
+    ipv6_cidr_block = "::/0"
+    gateway_id      = aws_internet_gateway.ipv6.id
+  }
+
+  route {
+    ipv6_cidr_block = "64:ff9b::/96"
+    nat_gateway_id  = aws_nat_gateway.nat.id
+  }
+}
+
+resource "aws_route_table_association" "ipv6_only" {
+  subnet_id      = aws_subnet.ipv6_only.id
+  route_table_id = aws_route_table.ipv6_only.id
+}
+
+resource "aws_security_group" "ipv6_only" {
+  name   = "cwagent-integ-test-ipv6-${module.common.testing_id}"
+  vpc_id = aws_vpc.ipv6.id
+
+  ingress {
+    from_port        = 22
+    to_port          = 22
+    protocol         = "tcp"
+    ipv6_cidr_blocks = ["::/0"]
+  }
+
+  egress {
+    from_port        = 0
+    to_port          = 0
+    protocol         = "-1"
+    ipv6_cidr_blocks = ["::/0"]
+  }
+}
+
+#####################################################################
+# Generate EC2 Instance and execute test commands
+#####################################################################
+
+# The instance has no IPv4 address, so the host running terraform reaches it over IPv6
+resource "aws_instance" "cwagent" {
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = var.ec2_instance_type
+  key_name                             = local.ssh_key_name
+  iam_instance_profile                 = module.basic_components.instance_profile
+  subnet_id                            = aws_subnet.ipv6_only.id
+  vpc_security_group_ids               = [aws_security_group.ipv6_only.id]
+  ipv6_address_count                   = 1
+  instance_initiated_shutdown_behavior = "terminate"
+
+  metadata_options {
+    http_endpoint      = "enabled"
+    http_tokens        = "required"
+    http_protocol_ipv6 = "enabled"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
+  }
+
+  depends_on = [aws_route_table_association.ipv6_only]
+}
+
+resource "null_resource" "integration_test_setup" {
+  connection {
+    type        = "ssh"
+    user        = var.user
+    private_key = local.private_key_content
+    host        = aws_instance.cwagent.ipv6_addresses[0]
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "echo sha ${var.cwa_github_sha}",
+      "sudo cloud-init status --wait",
+      "echo clone and install agent",
+      "git clone --branch ${var.github_test_repo_branch} ${var.github_test_repo}",
+      "cd amazon-cloudwatch-agent-test",
+      "aws s3 cp s3://${local.binary_uri} . --endpoint-url https://s3.dualstack.${var.region}.amazonaws.com",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      var.install_agent,
+      "echo configure the agent for the dual-stack endpoints and IMDS over IPv6",
+      "sudo mkdir -p /etc/systemd/system/amazon-cloudwatch-agent.service.d",
+      "printf '[Service]\\nEnvironment=AWS_USE_DUALSTACK_ENDPOINT=true\\nEnvironment=AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE=IPv6\\n' | sudo tee /etc/systemd/system/amazon-cloudwatch-agent.service.d/ipv6.conf",
+      "sudo systemctl daemon-reload",
+    ]
+  }
+
+  depends_on = [
+    aws_instance.cwagent,
+  ]
+}
+
+resource "null_resource" "integration_test_run" {
+  connection {
+    type        = "ssh"
+    user        = var.user
+    private_key = local.private_key_content
+    host        = aws_instance.cwagent.ipv6_addresses[0]
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "echo prepare environment",
+      "export AWS_REGION=${var.region}",
+      "export AWS_USE_DUALSTACK_ENDPOINT=true",
+      "export AWS_EC2_METADATA_SERVICE_ENDPOINT_MODE=IPv6",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      "echo run integration test",
+      "cd ~/amazon-cloudwatch-agent-test",
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -cwaCommitSha=${var.cwa_github_sha} -instanceId=${aws_instance.cwagent.id} -ipv6Only -v"
+    ]
+  }
+
+  depends_on = [
+    null_resource.integration_test_setup,
+  ]
+}
+
+data "aws_ami" "latest" {
+  most_recent = true
+
+  filter {
+    name   = "name"
+    values = [var.ami]
+  }
+}
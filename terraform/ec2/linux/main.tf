@@ -48,6 +48,119 @@ module "proxy_instance" {
   user                = var.user
 }
 
+#####################################################################
+# Generate a KMS key for the tests validating KMS encrypted log groups
+#####################################################################
+data "aws_caller_identity" "current" {}
+
+resource "aws_kms_key" "log_group" {
+  count                   = length(regexall("cloudwatchlogs", var.test_dir)) > 0 ? 1 : 0
+  description             = "cwagent-integ-test-log-group-${module.common.testing_id}"
+  deletion_window_in_days = 7
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Sid       = "AccountAdministration"
+        Effect    = "Allow"
+        Principal = { AWS = "arn:aws:iam::${data.aws_caller_identity.current.account_id}:root" }
+        Action    = "kms:*"
+        Resource  = "*"
+      },
+      {
+        Sid       = "CloudWatchLogsEncryption"
+        Effect    = "Allow"
+        Principal = { Service = "logs.${var.region}.amazonaws.com" }
+        Action    = ["kms:Encrypt*", "kms:Decrypt*", "kms:ReEncrypt*", "kms:GenerateDataKey*", "kms:Describe*"]
+        Resource  = "*"
+      },
+    ]
+  })
+}
+
+#####################################################################
+# Generate a firehose delivery stream to s3 for the metric stream tests
+#####################################################################
+locals {
+  metric_stream_count = length(regexall("metric_stream", var.test_dir)) > 0 ? 1 : 0
+}
+
+resource "aws_s3_bucket" "metric_stream" {
+  count         = local.metric_stream_count
+  bucket        = "cwagent-integ-test-metric-stream-${module.common.testing_id}"
+  force_destroy = true
+}
+
+resource "aws_iam_role" "metric_stream_firehose" {
+  count = local.metric_stream_count
+  name  = "cwagent-integ-test-metric-stream-firehose-${module.common.testing_id}"
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect    = "Allow"
+        Principal = { Service = "firehose.amazonaws.com" }
+        Action    = "sts:AssumeRole"
+      },
+    ]
+  })
+  inline_policy {
+    name = "s3"
+    policy = jsonencode({
+      Version = "2012-10-17"
+      Statement = [
+        {
+          Effect   = "Allow"
+          Action   = ["s3:AbortMultipartUpload", "s3:GetBucketLocation", "s3:GetObject", "s3:ListBucket", "s3:PutObject"]
+          Resource = [aws_s3_bucket.metric_stream[0].arn, "${aws_s3_bucket.metric_stream[0].arn}/*"]
+        },
+      ]
+    })
+  }
+}
+
+resource "aws_kinesis_firehose_delivery_stream" "metric_stream" {
+  count       = local.metric_stream_count
+  name        = "cwagent-integ-test-metric-stream-${module.common.testing_id}"
+  destination = "extended_s3"
+
+  extended_s3_configuration {
+    role_arn   = aws_iam_role.metric_stream_firehose[0].arn
+    bucket_arn = aws_s3_bucket.metric_stream[0].arn
+    // the smallest buffer, so the records land in the bucket within the test
+    buffer_interval = 60
+    buffer_size     = 1
+  }
+}
+
+resource "aws_iam_role" "metric_stream" {
+  count = local.metric_stream_count
+  name  = "cwagent-integ-test-metric-stream-${module.common.testing_id}"
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect    = "Allow"
+        Principal = { Service = "streams.metrics.cloudwatch.amazonaws.com" }
+        Action    = "sts:AssumeRole"
+      },
+    ]
+  })
+  inline_policy {
+    name = "firehose"
+    policy = jsonencode({
+      Version = "2012-10-17"
+      Statement = [
+        {
+          Effect   = "Allow"
+          Action   = ["firehose:PutRecord", "firehose:PutRecordBatch"]
+          Resource = aws_kinesis_firehose_delivery_stream.metric_stream[0].arn
+        },
+      ]
+    })
+  }
+}
+
 #####################################################################
 # Generate EC2 Instance and execute test commands
 #####################################################################
@@ -199,7 +312,7 @@ resource "null_resource" "integration_test_run" {
       "echo run integration test",
       "cd ~/amazon-cloudwatch-agent-test",
       "echo run sanity test && go test ./test/sanity -p 1 -v",
-      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -plugins='${var.plugin_tests}' -cwaCommitSha=${var.cwa_github_sha} -caCertPath=${var.ca_cert_path} -proxyUrl=${module.proxy_instance.proxy_ip} -instanceId=${aws_instance.cwagent.id} -v"
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -plugins='${var.plugin_tests}' -cwaCommitSha=${var.cwa_github_sha} -caCertPath=${var.ca_cert_path} -proxyUrl=${module.proxy_instance.proxy_ip} -instanceId=${aws_instance.cwagent.id} -kmsKeyArn=${join("", aws_kms_key.log_group[*].arn)} -metricStreamFirehoseArn=${join("", aws_kinesis_firehose_delivery_stream.metric_stream[*].arn)} -metricStreamRoleArn=${join("", aws_iam_role.metric_stream[*].arn)} -metricStreamBucket=${join("", aws_s3_bucket.metric_stream[*].bucket)} -v"
     ]
   }
 
@@ -0,0 +1,136 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "common" {
+  source = "../../common"
+}
+
+module "basic_components" {
+  source = "../../basic_components"
+
+  region = var.region
+}
+
+#####################################################################
+# Generate EC2 Key Pair for log in access to EC2
+#####################################################################
+
+resource "tls_private_key" "ssh_key" {
+  count     = var.ssh_key_name == "" ? 1 : 0
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}
+
+resource "aws_key_pair" "aws_ssh_key" {
+  count      = var.ssh_key_name == "" ? 1 : 0
+  key_name   = "ec2-key-pair-${module.common.testing_id}"
+  public_key = tls_private_key.ssh_key[0].public_key_openssh
+}
+
+locals {
+  ssh_key_name        = var.ssh_key_name != "" ? var.ssh_key_name : aws_key_pair.aws_ssh_key[0].key_name
+  private_key_content = var.ssh_key_name != "" ? var.ssh_key_value : tls_private_key.ssh_key[0].private_key_pem
+  // Canary downloads latest binary. Integration test downloads binary connect to git hash.
+  binary_uri = var.is_canary ? "${var.s3_bucket}/release/amazon_linux/${var.arc}/latest/${var.binary_name}" : "${var.s3_bucket}/integration-test/binary/${var.cwa_github_sha}/linux/${var.arc}/${var.binary_name}"
+  // every writer sends its logs to the same group, each to a stream named after its instance id
+  log_group = "cwagent-multi-instance-${module.common.testing_id}"
+}
+
+#####################################################################
+# Generate the writer instances, which install the agent and write their logs from userdata
+#####################################################################
+resource "aws_instance" "writer" {
+  count                                = var.writer_count
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = var.ec2_instance_type
+  key_name                             = local.ssh_key_name
+  iam_instance_profile                 = module.basic_components.instance_profile
+  vpc_security_group_ids               = [module.basic_components.security_group]
+  associate_public_ip_address          = true
+  instance_initiated_shutdown_behavior = "terminate"
+  user_data                            = data.template_file.writer.rendered
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-ec2-${var.test_name}-writer-${count.index}-${module.common.testing_id}"
+  }
+}
+
+#####################################################################
+# Generate the validator instance, which only reads the shared log group
+#####################################################################
+resource "aws_instance" "cwagent" {
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = var.ec2_instance_type
+  key_name                             = local.ssh_key_name
+  iam_instance_profile                 = module.basic_components.instance_profile
+  vpc_security_group_ids               = [module.basic_components.security_group]
+  associate_public_ip_address          = true
+  instance_initiated_shutdown_behavior = "terminate"
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
+  }
+}
+
+resource "null_resource" "integration_test" {
+  connection {
+    type        = "ssh"
+    user        = var.user
+    private_key = local.private_key_content
+    host        = aws_instance.cwagent.public_ip
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "sudo cloud-init status --wait",
+      "git clone --branch ${var.github_test_repo_branch} ${var.github_test_repo}",
+      "echo prepare environment",
+      "export AWS_REGION=${var.region}",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      "echo run integration test",
+      "cd ~/amazon-cloudwatch-agent-test",
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -v -logGroup=${local.log_group} -writerInstanceIds=${join(",", aws_instance.writer[*].id)} -eventsPerWriter=${var.events_per_writer}"
+    ]
+  }
+
+  depends_on = [
+    aws_instance.writer,
+    aws_instance.cwagent,
+  ]
+}
+
+data "aws_ami" "latest" {
+  most_recent = true
+
+  filter {
+    name   = "name"
+    values = [var.ami]
+  }
+}
+
+#####################################################################
+# Generate template file for the writer userdata script
+#####################################################################
+data "template_file" "writer" {
+  template = file("write_logs.sh")
+
+  vars = {
+    cwa_github_sha          = var.cwa_github_sha
+    github_test_repo_branch = var.github_test_repo_branch
+    github_test_repo        = var.github_test_repo
+    binary_uri              = local.binary_uri
+    install_agent           = var.install_agent
+    log_group               = local.log_group
+    events_per_writer       = var.events_per_writer
+  }
+}
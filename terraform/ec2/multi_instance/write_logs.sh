@@ -0,0 +1,23 @@
+#! /bin/bash
+echo sha ${cwa_github_sha}
+echo clone and install agent
+cd /home/ec2-user/
+git clone --branch ${github_test_repo_branch} ${github_test_repo}
+cd amazon-cloudwatch-agent-test
+aws s3 cp s3://${binary_uri} .
+export HOME=/root
+export GOPATH=$HOME/go
+export PATH=$PATH:$GOPATH/bin
+echo Running command ${install_agent}
+${install_agent}
+echo Starting agent now
+config=/home/ec2-user/amazon-cloudwatch-agent-test/test/multi_instance_logs/agent_configs/config.json
+sed -i "s/__LOG_GROUP__/${log_group}/" $config
+touch /tmp/multi_instance.log
+/opt/aws/amazon-cloudwatch-agent/bin/amazon-cloudwatch-agent-ctl -a fetch-config -m ec2 -s -c file:$config
+token=$(curl -s -X PUT "http://169.254.169.254/latest/api/token" -H "X-aws-ec2-metadata-token-ttl-seconds: 300")
+instance_id=$(curl -s -H "X-aws-ec2-metadata-token: $token" http://169.254.169.254/latest/meta-data/instance-id)
+for i in $(seq 1 ${events_per_writer}); do
+  echo "$instance_id line $i" >> /tmp/multi_instance.log
+done
+cloud-init status --wait
@@ -0,0 +1,265 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "common" {
+  source = "../../common"
+}
+
+module "basic_components" {
+  source = "../../basic_components"
+
+  region = var.region
+}
+
+#####################################################################
+# Generate EC2 Key Pair for log in access to EC2
+#####################################################################
+
+resource "tls_private_key" "ssh_key" {
+  count     = var.ssh_key_name == "" ? 1 : 0
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}
+
+resource "aws_key_pair" "aws_ssh_key" {
+  count      = var.ssh_key_name == "" ? 1 : 0
+  key_name   = "ec2-key-pair-${module.common.testing_id}"
+  public_key = tls_private_key.ssh_key[0].public_key_openssh
+}
+
+locals {
+  ssh_key_name        = var.ssh_key_name != "" ? var.ssh_key_name : aws_key_pair.aws_ssh_key[0].key_name
+  private_key_content = var.ssh_key_name != "" ? var.ssh_key_value : tls_private_key.ssh_key[0].private_key_pem
+  binary_uri          = "${var.s3_bucket}/integration-test/binary/${var.cwa_github_sha}/linux/${var.arc}/${var.binary_name}"
+  // test/privatelink expects an interface endpoint with private DNS for each of these
+  interface_endpoint_services = toset(["monitoring", "logs", "sts", "ec2"])
+}
+
+#####################################################################
+# Generate the VPC with a private subnet which only reaches AWS through the VPC endpoints
+#####################################################################
+
+resource "aws_vpc" "privatelink" {
+  cidr_block           = "10.0.0.0/16"
+  enable_dns_support   = true
+  enable_dns_hostnames = true
+
+  tags = {
+    Name = "cwagent-integ-test-privatelink-${module.common.testing_id}"
+  }
+}
+
+resource "aws_internet_gateway" "privatelink" {
+  vpc_id = aws_vpc.privatelink.id
+}
+
+# The public subnet holds the bastion the tests are run through and the NAT gateway used during the setup
+resource "aws_subnet" "public" {
+  vpc_id                  = aws_vpc.privatelink.id
+  availability_zone       = var.availability_zone
+  cidr_block              = cidrsubnet(aws_vpc.privatelink.cidr_block, 8, 0)
+  map_public_ip_on_launch = true
+}
+
+resource "aws_route_table" "public" {
+  vpc_id = aws_vpc.privatelink.id
+
+  route {
+    cidr_block = "0.0.0.0/0"
+    gateway_id = aws_internet_gateway.privatelink.id
+  }
+}
+
+resource "aws_route_table_association" "public" {
+  subnet_id      = aws_subnet.public.id
+  route_table_id = aws_route_table.public.id
+}
+
+resource "aws_eip" "nat" {
+  vpc = true
+}
+
+resource "aws_nat_gateway" "nat" {
+  allocation_id = aws_eip.nat.id
+  subnet_id     = aws_subnet.public.id
+  depends_on    = [aws_internet_gateway.privatelink]
+}
+
+resource "aws_subnet" "private" {
+  vpc_id            = aws_vpc.privatelink.id
+  availability_zone = var.availability_zone
+  cidr_block        = cidrsubnet(aws_vpc.privatelink.cidr_block, 8, 1)
+}
+
+resource "aws_route_table" "private" {
+  vpc_id = aws_vpc.privatelink.id
+}
+
+# The NAT route is only used to clone the tests and download the agent and the go modules, it is removed before the
+# test runs by null_resource.remove_nat_route
+resource "aws_route" "private_nat" {
+  route_table_id         = aws_route_table.private.id
+  destination_cidr_block = "0.0.0.0/0"
+  nat_gateway_id         = aws_nat_gateway.nat.id
+}
+
+resource "aws_route_table_association" "private" {
+  subnet_id      = aws_subnet.private.id
+  route_table_id = aws_route_table.private.id
+}
+
+resource "aws_security_group" "privatelink" {
+  name   = "cwagent-integ-test-privatelink-${module.common.testing_id}"
+  vpc_id = aws_vpc.privatelink.id
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    protocol    = "tcp"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    protocol    = "tcp"
+    cidr_blocks = [aws_vpc.privatelink.cidr_block]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    protocol    = "-1"
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_vpc_endpoint" "interface" {
+  for_each            = local.interface_endpoint_services
+  vpc_id              = aws_vpc.privatelink.id
+  service_name        = "com.amazonaws.${var.region}.${each.value}"
+  vpc_endpoint_type   = "Interface"
+  subnet_ids          = [aws_subnet.private.id]
+  security_group_ids  = [aws_security_group.privatelink.id]
+  private_dns_enabled = true
+}
+
+#####################################################################
+# Generate the bastion and the EC2 Instance and execute test commands
+#####################################################################
+
+resource "aws_instance" "bastion" {
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = "t3a.micro"
+  key_name                             = local.ssh_key_name
+  subnet_id                            = aws_subnet.public.id
+  vpc_security_group_ids               = [aws_security_group.privatelink.id]
+  instance_initiated_shutdown_behavior = "terminate"
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-bastion-${var.test_name}-${module.common.testing_id}"
+  }
+
+  depends_on = [aws_route_table_association.public]
+}
+
+resource "aws_instance" "cwagent" {
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = var.ec2_instance_type
+  key_name                             = local.ssh_key_name
+  iam_instance_profile                 = module.basic_components.instance_profile
+  subnet_id                            = aws_subnet.private.id
+  vpc_security_group_ids               = [aws_security_group.privatelink.id]
+  instance_initiated_shutdown_behavior = "terminate"
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
+  }
+
+  depends_on = [aws_route.private_nat, aws_route_table_association.private]
+}
+
+resource "null_resource" "integration_test_setup" {
+  connection {
+    type         = "ssh"
+    user         = var.user
+    private_key  = local.private_key_content
+    host         = aws_instance.cwagent.private_ip
+    bastion_host = aws_instance.bastion.public_ip
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "echo sha ${var.cwa_github_sha}",
+      "sudo cloud-init status --wait",
+      "echo clone and install agent",
+      "git clone --branch ${var.github_test_repo_branch} ${var.github_test_repo}",
+      "cd amazon-cloudwatch-agent-test",
+      "aws s3 cp s3://${local.binary_uri} .",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      var.install_agent,
+      "echo download the go modules while the subnet still reaches the internet",
+      "go mod download",
+    ]
+  }
+
+  depends_on = [
+    aws_instance.cwagent,
+  ]
+}
+
+resource "null_resource" "remove_nat_route" {
+  provisioner "local-exec" {
+    command = "aws ec2 delete-route --region ${var.region} --route-table-id ${aws_route_table.private.id} --destination-cidr-block 0.0.0.0/0"
+  }
+
+  depends_on = [
+    null_resource.integration_test_setup,
+  ]
+}
+
+resource "null_resource" "integration_test_run" {
+  connection {
+    type         = "ssh"
+    user         = var.user
+    private_key  = local.private_key_content
+    host         = aws_instance.cwagent.private_ip
+    bastion_host = aws_instance.bastion.public_ip
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "echo prepare environment",
+      "export AWS_REGION=${var.region}",
+      "export GOPROXY=off",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      "echo run integration test",
+      "cd ~/amazon-cloudwatch-agent-test",
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -cwaCommitSha=${var.cwa_github_sha} -instanceId=${aws_instance.cwagent.id} -v"
+    ]
+  }
+
+  depends_on = [
+    aws_vpc_endpoint.interface,
+    null_resource.remove_nat_route,
+  ]
+}
+
+data "aws_ami" "latest" {
+  most_recent = true
+
+  filter {
+    name   = "name"
+    values = [var.ami]
+  }
+}
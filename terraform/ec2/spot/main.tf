@@ -0,0 +1,217 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "common" {
+  source = "../../common"
+}
+
+module "basic_components" {
+  source = "../../basic_components"
+
+  region = var.region
+}
+
+data "aws_caller_identity" "current" {}
+
+#####################################################################
+# Generate EC2 Key Pair for log in access to EC2
+#####################################################################
+
+resource "tls_private_key" "ssh_key" {
+  count     = var.ssh_key_name == "" ? 1 : 0
+  algorithm = "RSA"
+  rsa_bits  = 4096
+}
+
+resource "aws_key_pair" "aws_ssh_key" {
+  count      = var.ssh_key_name == "" ? 1 : 0
+  key_name   = "ec2-key-pair-${module.common.testing_id}"
+  public_key = tls_private_key.ssh_key[0].public_key_openssh
+}
+
+locals {
+  ssh_key_name        = var.ssh_key_name != "" ? var.ssh_key_name : aws_key_pair.aws_ssh_key[0].key_name
+  private_key_content = var.ssh_key_name != "" ? var.ssh_key_value : tls_private_key.ssh_key[0].private_key_pem
+  // Canary downloads latest binary. Integration test downloads binary connect to git hash.
+  binary_uri = var.is_canary ? "${var.s3_bucket}/release/amazon_linux/${var.arc}/latest/${var.binary_name}" : "${var.s3_bucket}/integration-test/binary/${var.cwa_github_sha}/linux/${var.arc}/${var.binary_name}"
+}
+
+#####################################################################
+# Generate the FIS experiment interrupting the spot instance
+#####################################################################
+
+data "aws_iam_policy_document" "fis_trust_policy" {
+  statement {
+    actions = ["sts:AssumeRole"]
+    principals {
+      type        = "Service"
+      identifiers = ["fis.amazonaws.com"]
+    }
+  }
+}
+
+resource "aws_iam_role" "fis" {
+  name               = "cwa-integ-fis-spot-${module.common.testing_id}"
+  assume_role_policy = data.aws_iam_policy_document.fis_trust_policy.json
+}
+
+data "aws_iam_policy_document" "fis_policy" {
+  statement {
+    actions   = ["ec2:SendSpotInstanceInterruptions"]
+    resources = ["arn:aws:ec2:${var.region}:${data.aws_caller_identity.current.account_id}:instance/*"]
+  }
+  statement {
+    actions   = ["ec2:DescribeInstances"]
+    resources = ["*"]
+  }
+}
+
+resource "aws_iam_role_policy" "fis" {
+  name   = "cwa-integ-fis-spot-${module.common.testing_id}"
+  role   = aws_iam_role.fis.id
+  policy = data.aws_iam_policy_document.fis_policy.json
+}
+
+# The notice is sent when the experiment starts and the instance is interrupted two minutes later, the test starts
+# the experiment once the agent buffered logs and datapoints
+resource "aws_fis_experiment_template" "spot" {
+  description = "Interrupt the spot instance of cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
+  role_arn    = aws_iam_role.fis.arn
+
+  stop_condition {
+    source = "none"
+  }
+
+  action {
+    name      = "interrupt"
+    action_id = "aws:ec2:send-spot-instance-interruptions"
+
+    parameter {
+      key   = "durationBeforeInterruption"
+      value = "PT2M"
+    }
+
+    target {
+      key   = "SpotInstances"
+      value = "spot"
+    }
+  }
+
+  target {
+    name           = "spot"
+    resource_type  = "aws:ec2:spot-instance"
+    selection_mode = "ALL"
+    resource_arns  = [aws_instance.cwagent.arn]
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-spot-${module.common.testing_id}"
+  }
+}
+
+# The instance role starts the experiment from the test, it is shared by the runs so each adds a policy of its own
+data "aws_iam_policy_document" "start_experiment_policy" {
+  statement {
+    actions   = ["fis:StartExperiment", "fis:GetExperiment"]
+    resources = ["*"]
+  }
+}
+
+resource "aws_iam_role_policy" "start_experiment" {
+  name   = "cwa-integ-start-fis-spot-${module.common.testing_id}"
+  role   = module.common.cwa_iam_role
+  policy = data.aws_iam_policy_document.start_experiment_policy.json
+}
+
+#####################################################################
+# Generate EC2 Spot Instance and execute test commands
+#####################################################################
+resource "aws_instance" "cwagent" {
+  ami                                  = data.aws_ami.latest.id
+  instance_type                        = var.ec2_instance_type
+  key_name                             = local.ssh_key_name
+  iam_instance_profile                 = module.basic_components.instance_profile
+  vpc_security_group_ids               = [module.basic_components.security_group]
+  associate_public_ip_address          = true
+  instance_initiated_shutdown_behavior = "terminate"
+
+  instance_market_options {
+    market_type = "spot"
+    spot_options {
+      spot_instance_type             = "one-time"
+      instance_interruption_behavior = "terminate"
+    }
+  }
+
+  metadata_options {
+    http_endpoint = "enabled"
+    http_tokens   = "required"
+  }
+
+  tags = {
+    Name = "cwagent-integ-test-ec2-${var.test_name}-${module.common.testing_id}"
+  }
+}
+
+resource "null_resource" "integration_test_setup" {
+  connection {
+    type        = "ssh"
+    user        = var.user
+    private_key = local.private_key_content
+    host        = aws_instance.cwagent.public_ip
+  }
+
+  # Prepare Integration Test
+  provisioner "remote-exec" {
+    inline = [
+      "echo sha ${var.cwa_github_sha}",
+      "sudo cloud-init status --wait",
+      "echo clone and install agent",
+      "git clone --branch ${var.github_test_repo_branch} ${var.github_test_repo}",
+      "cd amazon-cloudwatch-agent-test",
+      "aws s3 cp s3://${local.binary_uri} .",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      var.install_agent,
+    ]
+  }
+
+  depends_on = [
+    aws_instance.cwagent,
+  ]
+}
+
+resource "null_resource" "integration_test_run" {
+  connection {
+    type        = "ssh"
+    user        = var.user
+    private_key = local.private_key_content
+    host        = aws_instance.cwagent.public_ip
+  }
+
+  # The instance is terminated by the interruption, so the test validates before then
+  provisioner "remote-exec" {
+    inline = [
+      "echo prepare environment",
+      "export AWS_REGION=${var.region}",
+      "export PATH=$PATH:/snap/bin:/usr/local/go/bin",
+      "echo run integration test",
+      "cd ~/amazon-cloudwatch-agent-test",
+      "go test ${var.test_dir} -p 1 -timeout 1h -computeType=EC2 -bucket=${var.s3_bucket} -cwaCommitSha=${var.cwa_github_sha} -caCertPath=${var.ca_cert_path} -spotInterruptionTemplateId=${aws_fis_experiment_template.spot.id} -instanceId=${aws_instance.cwagent.id} -v"
+    ]
+  }
+
+  depends_on = [
+    null_resource.integration_test_setup,
+    aws_iam_role_policy.start_experiment,
+    aws_iam_role_policy.fis,
+  ]
+}
+
+data "aws_ami" "latest" {
+  most_recent = true
+
+  filter {
+    name   = "name"
+    values = [var.ami]
+  }
+}
@@ -35,6 +35,7 @@ locals {
 data "template_file" "cwagent_config" {
   template = file(local.cwagent_config)
   vars = {
+    cluster_name = aws_ecs_cluster.cluster.name
   }
 }
 
@@ -0,0 +1,62 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "install_common" {
+  source        = "../install/common"
+  region        = var.region
+  k8s_version   = var.k8s_version
+  ami_type      = var.ami_type
+  instance_type = var.instance_type
+}
+
+# The GPUs of the node are only allocatable to pods once the NVIDIA device plugin runs
+resource "helm_release" "nvidia_device_plugin" {
+  name             = "nvidia-device-plugin"
+  repository       = "https://nvidia.github.io/k8s-device-plugin"
+  chart            = "nvidia-device-plugin"
+  namespace        = "kube-system"
+  create_namespace = false
+  depends_on       = [module.install_common]
+}
+
+# The add-on deploys the DCGM exporter on the GPU nodes, which the agent scrapes for the GPU metrics
+resource "aws_eks_addon" "cloudwatch_observability" {
+  cluster_name = module.install_common.cluster_name
+  addon_name   = "amazon-cloudwatch-observability"
+  depends_on   = [helm_release.nvidia_device_plugin]
+}
+
+# Keeps the GPU busy so the pod has GPU metrics, test/metric_value_benchmark/eks_gpu_test.go expects its name
+resource "kubernetes_pod" "gpu_burn" {
+  metadata {
+    name      = "gpu-burn"
+    namespace = "default"
+  }
+  spec {
+    restart_policy = "Always"
+    container {
+      name  = "gpu-burn"
+      image = "nvcr.io/nvidia/k8s/cuda-sample:nbody"
+      args  = ["-benchmark", "-numbodies=512000", "-iterations=1000000"]
+      resources {
+        limits = {
+          "nvidia.com/gpu" = 1
+        }
+      }
+    }
+  }
+  depends_on = [helm_release.nvidia_device_plugin]
+}
+
+resource "null_resource" "validator" {
+  depends_on = [aws_eks_addon.cloudwatch_observability, kubernetes_pod.gpu_burn]
+  provisioner "local-exec" {
+    command = <<-EOT
+      echo "Validating EKS GPU metrics"
+      aws eks update-kubeconfig --name ${module.install_common.cluster_name} --region ${var.region}
+      kubectl rollout status daemonset/cloudwatch-agent -n amazon-cloudwatch --timeout=10m
+      cd ../../../..
+      go test ${var.test_dir} -eksClusterName=${module.install_common.cluster_name} -computeType=EKS -v -eksDeploymentStrategy=DAEMON
+    EOT
+  }
+}
@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "install_common" {
+  source        = "../common"
+  region        = var.region
+  k8s_version   = var.k8s_version
+  ami_type      = var.ami_type
+  instance_type = var.instance_type
+}
+
+# Installs the agent the way customers do, with the Amazon CloudWatch Observability EKS add-on, instead of the raw
+# manifests of the other EKS suites
+resource "aws_eks_addon" "cloudwatch_observability" {
+  cluster_name  = module.install_common.cluster_name
+  addon_name    = "amazon-cloudwatch-observability"
+  addon_version = var.addon_version
+  depends_on    = [module.install_common]
+}
+
+resource "null_resource" "validator" {
+  depends_on = [aws_eks_addon.cloudwatch_observability]
+  provisioner "local-exec" {
+    command = <<-EOT
+      echo "Validating EKS metrics/logs of the add-on install"
+      aws eks update-kubeconfig --name ${module.install_common.cluster_name} --region ${var.region}
+      kubectl rollout status daemonset/cloudwatch-agent -n amazon-cloudwatch --timeout=10m
+      cd ../../../../..
+      go test ${var.test_dir} -eksClusterName=${module.install_common.cluster_name} -computeType=EKS -v -eksDeploymentStrategy=DAEMON
+    EOT
+  }
+}
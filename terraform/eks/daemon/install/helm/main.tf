@@ -0,0 +1,56 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+module "install_common" {
+  source        = "../common"
+  region        = var.region
+  k8s_version   = var.k8s_version
+  ami_type      = var.ami_type
+  instance_type = var.instance_type
+}
+
+# Installs the agent with the amazon-cloudwatch-observability helm chart, the alternative to the EKS add-on, with the
+# agent image under test
+resource "helm_release" "cloudwatch_observability" {
+  name             = "amazon-cloudwatch-observability"
+  repository       = "https://aws-observability.github.io/helm-charts"
+  chart            = "amazon-cloudwatch-observability"
+  version          = var.chart_version
+  namespace        = "amazon-cloudwatch"
+  create_namespace = true
+
+  set {
+    name  = "clusterName"
+    value = module.install_common.cluster_name
+  }
+  set {
+    name  = "region"
+    value = var.region
+  }
+  set {
+    name  = "agent.image.repositoryDomainMap.public"
+    value = dirname(var.cwagent_image_repo)
+  }
+  set {
+    name  = "agent.image.repository"
+    value = basename(var.cwagent_image_repo)
+  }
+  set {
+    name  = "agent.image.tag"
+    value = var.cwagent_image_tag
+  }
+  depends_on = [module.install_common]
+}
+
+resource "null_resource" "validator" {
+  depends_on = [helm_release.cloudwatch_observability]
+  provisioner "local-exec" {
+    command = <<-EOT
+      echo "Validating EKS metrics/logs of the helm install"
+      aws eks update-kubeconfig --name ${module.install_common.cluster_name} --region ${var.region}
+      kubectl rollout status daemonset/cloudwatch-agent -n amazon-cloudwatch --timeout=10m
+      cd ../../../../..
+      go test ${var.test_dir} -eksClusterName=${module.install_common.cluster_name} -computeType=EKS -v -eksDeploymentStrategy=DAEMON
+    EOT
+  }
+}
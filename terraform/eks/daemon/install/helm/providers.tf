@@ -0,0 +1,14 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+provider "aws" {
+  region = var.region
+}
+
+provider "helm" {
+  kubernetes {
+    host                   = module.install_common.cluster_endpoint
+    cluster_ca_certificate = base64decode(module.install_common.cluster_cert)
+    token                  = module.install_common.cluster_auth_token
+  }
+}
@@ -362,7 +362,7 @@ resource "kubernetes_cluster_role" "clusterrole" {
   }
   rule {
     verbs      = ["list", "watch"]
-    resources  = ["pods", "nodes", "endpoints"]
+    resources  = ["pods", "nodes", "endpoints", "services", "namespaces"]
     api_groups = [""]
   }
   rule {
@@ -391,6 +391,11 @@ resource "kubernetes_cluster_role" "clusterrole" {
     resources      = ["configmaps"]
     api_groups     = [""]
   }
+  // the control plane metrics of enhanced container insights are scraped from the apiserver
+  rule {
+    verbs             = ["get"]
+    non_resource_urls = ["/metrics"]
+  }
 }
 
 resource "kubernetes_cluster_role_binding" "rolebinding" {
@@ -420,6 +425,7 @@ resource "null_resource" "validator" {
   provisioner "local-exec" {
     command = <<-EOT
       echo "Validating EKS metrics/logs"
+      aws eks update-kubeconfig --name ${aws_eks_cluster.this.name} --region ${var.region}
       cd ../../..
       go test ${var.test_dir} -eksClusterName=${aws_eks_cluster.this.name} -computeType=EKS -v -eksDeploymentStrategy=DAEMON
     EOT
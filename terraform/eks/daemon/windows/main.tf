@@ -0,0 +1,83 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+# The linux node group of the common cluster runs the cluster add-ons which have no Windows images, e.g. coredns
+module "install_common" {
+  source      = "../install/common"
+  region      = var.region
+  k8s_version = var.k8s_version
+}
+
+# Windows pods only get IPs once the VPC resource controller manages the Windows IP addresses
+resource "kubernetes_config_map_v1_data" "amazon_vpc_cni" {
+  metadata {
+    name      = "amazon-vpc-cni"
+    namespace = "kube-system"
+  }
+  data = {
+    "enable-windows-ipam" = "true"
+  }
+  force      = true
+  depends_on = [module.install_common]
+}
+
+resource "aws_eks_node_group" "windows_node_group" {
+  cluster_name    = module.install_common.cluster_name
+  node_group_name = "cwagent-eks-integ-windows-node"
+  node_role_arn   = module.install_common.node_role_arn
+  subnet_ids      = module.install_common.subnet_ids
+
+  scaling_config {
+    desired_size = 1
+    max_size     = 1
+    min_size     = 1
+  }
+
+  ami_type       = var.windows_ami_type
+  capacity_type  = "ON_DEMAND"
+  disk_size      = 50
+  instance_types = [var.windows_instance_type]
+
+  depends_on = [kubernetes_config_map_v1_data.amazon_vpc_cni]
+}
+
+# The add-on deploys the Windows daemonsets of the agent and Fluent Bit next to the linux ones
+resource "aws_eks_addon" "cloudwatch_observability" {
+  cluster_name = module.install_common.cluster_name
+  addon_name   = "amazon-cloudwatch-observability"
+  depends_on   = [aws_eks_node_group.windows_node_group]
+}
+
+# Writes application logs on the Windows node, test/metric_value_benchmark/eks_windows_test.go expects its name and
+# log line
+resource "kubernetes_pod" "windows_logger" {
+  metadata {
+    name      = "windows-logger"
+    namespace = "default"
+  }
+  spec {
+    restart_policy = "Always"
+    node_selector = {
+      "kubernetes.io/os" = "windows"
+    }
+    container {
+      name    = "windows-logger"
+      image   = "mcr.microsoft.com/windows/servercore:ltsc2019"
+      command = ["powershell.exe", "-Command", "while ($true) { Write-Output 'windows-logger heartbeat'; Start-Sleep -Seconds 1 }"]
+    }
+  }
+  depends_on = [aws_eks_node_group.windows_node_group]
+}
+
+resource "null_resource" "validator" {
+  depends_on = [aws_eks_addon.cloudwatch_observability, kubernetes_pod.windows_logger]
+  provisioner "local-exec" {
+    command = <<-EOT
+      echo "Validating EKS Windows metrics and logs"
+      aws eks update-kubeconfig --name ${module.install_common.cluster_name} --region ${var.region}
+      kubectl rollout status daemonset/cloudwatch-agent-windows -n amazon-cloudwatch --timeout=20m
+      cd ../../../..
+      go test ${var.test_dir} -eksClusterName=${module.install_common.cluster_name} -computeType=EKS -v -eksDeploymentStrategy=DAEMON -containerOs=windows
+    EOT
+  }
+}
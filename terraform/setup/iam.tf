@@ -48,6 +48,7 @@ data "aws_iam_policy_document" "user-managed-policy-document" {
       "ec2:DescribeVolumes",
       "ec2:DescribeTags",
       "ec2:DescribeInstances",
+      "ec2:DescribeInstanceTypes",
       "logs:PutLogEvents",
       "logs:DescribeLogStreams",
       "logs:DescribeLogGroups",
@@ -67,6 +67,43 @@ resource "aws_dynamodb_table" "performance-dynamodb-table" {
   }
 }
 
+# Setup Dynamo Table for the result history of the suites, queried by "cwa-test history" for trend reporting
+resource "aws_dynamodb_table" "result-history-dynamodb-table" {
+  name           = module.common.result-history-dynamodb-table
+  read_capacity  = 10
+  write_capacity = 10
+  hash_key       = "AgentVersion"
+  range_key      = "Timestamp"
+
+  attribute {
+    name = "AgentVersion"
+    type = "S"
+  }
+
+  attribute {
+    name = "Timestamp"
+    type = "N"
+  }
+}
+
+# Setup Dynamo Table for the locks of the exclusive suites, expired locks of aborted runs are deleted by the ttl
+resource "aws_dynamodb_table" "suite-lock-dynamodb-table" {
+  name           = module.common.suite-lock-dynamodb-table
+  read_capacity  = 5
+  write_capacity = 5
+  hash_key       = "LockName"
+
+  attribute {
+    name = "LockName"
+    type = "S"
+  }
+
+  ttl {
+    attribute_name = "ExpiresAt"
+    enabled        = true
+  }
+}
+
 ## Setup Dedicated Host for Mac Resources
 ## https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/ec2_host
 ## It is a requirement before creating an EC2 Mac Host
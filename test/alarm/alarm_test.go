@@ -0,0 +1,168 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package alarm
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/suitelock"
+)
+
+const (
+	namespace      = "AlarmTest"
+	alarmMetric    = "cpu_usage_active"
+	alarmThreshold = 50
+	// alarmTimeout covers the alarm period and the delay of CloudWatch evaluating it
+	alarmTimeout = 5 * time.Minute
+)
+
+// AlarmTestRunner creates an alarm on the CPU usage the agent reports, loads every core and validates the alarm
+// goes to ALARM, which covers the agent data being usable end to end rather than only present
+type AlarmTestRunner struct {
+	test_runner.BaseTestRunner
+	alarmName string
+	stopLoad  chan struct{}
+}
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+var _ test_runner.ITestRunner = (*AlarmTestRunner)(nil)
+
+func (t *AlarmTestRunner) Validate() status.TestGroupResult {
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: []status.TestResult{t.validateAlarm()},
+	}
+}
+
+func (t *AlarmTestRunner) GetTestName() string {
+	return namespace
+}
+
+func (t *AlarmTestRunner) GetAgentConfigFileName() string {
+	return "config.json"
+}
+
+func (t *AlarmTestRunner) GetMeasuredMetrics() []string {
+	return []string{alarmMetric}
+}
+
+// SetupAfterAgentRun creates the alarm and starts loading every core until Cleanup is called
+func (t *AlarmTestRunner) SetupAfterAgentRun() error {
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+		{
+			Key:   "cpu",
+			Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	t.alarmName = runid.Name("alarm")
+	err = awsservice.PutMetricAlarm(&cloudwatch.PutMetricAlarmInput{
+		AlarmName:          aws.String(t.alarmName),
+		Namespace:          aws.String(ns.Resolve(namespace)),
+		MetricName:         aws.String(alarmMetric),
+		Dimensions:         dims,
+		Statistic:          types.StatisticAverage,
+		Period:             aws.Int32(60),
+		EvaluationPeriods:  aws.Int32(1),
+		DatapointsToAlarm:  aws.Int32(1),
+		Threshold:          aws.Float64(alarmThreshold),
+		ComparisonOperator: types.ComparisonOperatorGreaterThanThreshold,
+		TreatMissingData:   aws.String("missing"),
+	})
+	if err != nil {
+		return err
+	}
+
+	t.stopLoad = make(chan struct{})
+	for i := 0; i < runtime.NumCPU(); i++ {
+		go burnCpu(t.stopLoad)
+	}
+	return nil
+}
+
+func (t *AlarmTestRunner) validateAlarm() status.TestResult {
+	testResult := status.TestResult{
+		Name:     t.alarmName,
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("state %s with %s > %d", types.StateValueAlarm, alarmMetric, alarmThreshold),
+	}
+
+	if err := awsservice.WaitForAlarmState(t.alarmName, types.StateValueAlarm, alarmTimeout); err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// Cleanup stops the load and deletes the alarm, whichever of them SetupAfterAgentRun got to create
+func (t *AlarmTestRunner) Cleanup() {
+	if t.stopLoad != nil {
+		close(t.stopLoad)
+		t.stopLoad = nil
+	}
+	if t.alarmName != "" {
+		if err := awsservice.DeleteAlarm(t.alarmName); err != nil {
+			logger.Errorf("Failed to delete alarm %s: %v", t.alarmName, err)
+		}
+		t.alarmName = ""
+	}
+}
+
+func burnCpu(stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+func TestAlarm(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	// alarms of concurrent runs in the account are evaluated later than the timeout of validateAlarm covers
+	release, err := suitelock.Acquire(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	runner := test_runner.TestRunner{TestRunner: &AlarmTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	if result.GetStatus() != status.SUCCESSFUL {
+		result.Print()
+		t.Fatal("Alarm test failed")
+	}
+}
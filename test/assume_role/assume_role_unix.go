@@ -34,19 +34,10 @@ type RoleTestRunner struct {
 }
 
 func (t RoleTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(&t)
 }
 
-func (t *RoleTestRunner) validateMetric(metricName string) status.TestResult {
+func (t *RoleTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
@@ -93,7 +84,7 @@ func (t *RoleTestRunner) SetupBeforeAgentRun() error {
 	return t.SetUpConfig()
 }
 
-var _ test_runner.ITestRunner = (*RoleTestRunner)(nil)
+var _ test_runner.MetricRunner = (*RoleTestRunner)(nil)
 
 func getCommands(roleArn string) []string {
 	return []string{
@@ -107,7 +98,7 @@ func getCommands(roleArn string) []string {
 func getDimensions(instanceId string) []types.Dimension {
 	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
 	factory := dimension.GetDimensionFactory(*env)
-	dims, failed := factory.GetDimensions([]dimension.Instruction{
+	dims, err := factory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -118,7 +109,8 @@ func getDimensions(instanceId string) []types.Dimension {
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		log.Printf("failed to get dimensions: %v", err)
 		return []types.Dimension{}
 	}
 
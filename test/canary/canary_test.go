@@ -45,9 +45,9 @@ func TestCanary(t *testing.T) {
 	common.CopyFile(configInputPath, common.ConfigOutputPath)
 	err = common.StartAgent(common.ConfigOutputPath, false, false)
 	reportMetric(t, "StartFail", err)
-	actualVersion, _ := os.ReadFile(common.InstallAgentVersionPath)
+	actualVersion, _ := common.GetInstalledAgentVersion()
 	expectedVersion, _ := getVersionFromS3(e.Bucket)
-	if expectedVersion != string(actualVersion) {
+	if strings.TrimSpace(expectedVersion) != actualVersion {
 		err = errors.New("agent version mismatch")
 	}
 	reportMetric(t, "VersionFail", err)
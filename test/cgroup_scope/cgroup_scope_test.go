@@ -0,0 +1,309 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package cgroup_scope
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/scenario"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/cgroup"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	agentRuntime       = 3 * time.Minute
+	collectionInterval = 15
+	// memoryMax and cpus limit the agent, far below the host so a metric of the wrong scope cannot pass for the host
+	memoryMax = 256 * 1024 * 1024
+	cpus      = 0.25
+	// memTotalTolerance is how far mem_total may be from the MemTotal of the host, it does not change while the agent runs
+	memTotalTolerance = 0.01
+	containerImage    = "public.ecr.aws/amazonlinux/amazonlinux:2"
+	agentDir          = "/opt/aws/amazon-cloudwatch-agent"
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestCgroupV2Host runs the agent in its systemd unit with a memory limit and a CPU quota on a cgroup v2 host. The
+// mem and cpu plugins must still report the host, while procstat reports the agent within the limits of its unit.
+func TestCgroupV2Host(t *testing.T) {
+	if !cgroup.IsV2() {
+		t.Skip("the host does not mount the cgroup v2 hierarchy")
+	}
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	ns := namespace.Scope("CgroupScopeHostTest")
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := writeAgentConfig(configPath, ns); err != nil {
+		t.Fatal(err)
+	}
+	common.CopyFile(configPath, common.ConfigOutputPath)
+
+	run(t, env, ns, scenario.Scenario{
+		Name: "CgroupV2Host",
+		Steps: []scenario.Step{
+			scenario.StartAgent(common.ConfigOutputPath),
+			{Name: "apply the cgroup limits", Run: func(scenario.State) error {
+				return common.SetAgentServiceProperties(fmt.Sprintf("CPUQuota=%d%%", int(cpus*100)), fmt.Sprintf("MemoryMax=%d", memoryMax))
+			}},
+			{Name: "check the cgroup limits are applied", Run: func(scenario.State) error {
+				properties, err := common.GetAgentServiceProperties("MainPID")
+				if err != nil {
+					return err
+				}
+				pid, err := strconv.Atoi(properties["MainPID"])
+				if err != nil || pid == 0 {
+					return fmt.Errorf("the agent is not running, MainPID is %q", properties["MainPID"])
+				}
+				return checkLimits(pid)
+			}},
+		},
+		Cleanup: []scenario.Step{
+			{Name: "reset the cgroup limits", Run: func(scenario.State) error { return common.ResetAgentServiceProperties() }},
+			scenario.StopAgent(),
+		},
+	})
+}
+
+// TestLimitedContainer runs the agent in a container limited to less memory and CPU than the host, the way it runs
+// as a sidecar or a daemon set. The container shares /proc/meminfo and /proc/stat of the host, so the mem and cpu
+// plugins must report the host, while procstat reports the agent within the limits of its container.
+func TestLimitedContainer(t *testing.T) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not installed")
+	}
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	ns := namespace.Scope("CgroupScopeContainerTest")
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := writeAgentConfig(configPath, ns); err != nil {
+		t.Fatal(err)
+	}
+	container := runid.Name("cgroup-scope")
+
+	run(t, env, ns, scenario.Scenario{
+		Name: "LimitedContainer",
+		Steps: []scenario.Step{
+			// the container runs the agent installed on the host, which must not run at the same time
+			scenario.StopAgent(),
+			{Name: "start the agent in a limited container", Run: func(scenario.State) error {
+				out, err := exec.Command("sudo", "docker", "run", "-d", "--name", container,
+					"--memory", strconv.Itoa(memoryMax), "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64),
+					"--network", "host", "-e", "RUN_IN_CONTAINER=True",
+					"-v", agentDir+":"+agentDir,
+					"-v", configPath+":/etc/cwagentconfig/config.json:ro",
+					containerImage, agentDir+"/bin/start-amazon-cloudwatch-agent").CombinedOutput()
+				if err != nil {
+					return fmt.Errorf("failed to start container %s: %w: %s", container, err, out)
+				}
+				return nil
+			}},
+			{Name: "check the cgroup limits are applied", Run: func(scenario.State) error {
+				out, err := exec.Command("sudo", "docker", "inspect", "-f", "{{.State.Pid}}", container).Output()
+				if err != nil {
+					return fmt.Errorf("failed to inspect container %s: %w", container, err)
+				}
+				pid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+				if err != nil || pid == 0 {
+					return fmt.Errorf("container %s is not running, its pid is %q", container, out)
+				}
+				return checkLimits(pid)
+			}},
+		},
+		Cleanup: []scenario.Step{
+			{Name: "remove the container", Run: func(scenario.State) error {
+				if out, err := exec.Command("sudo", "docker", "logs", "--tail", "50", container).CombinedOutput(); err == nil {
+					logger.Infof("Last logs of the agent in container %s:\n%s", container, out)
+				}
+				return exec.Command("sudo", "docker", "rm", "-f", container).Run()
+			}},
+		},
+	})
+}
+
+// run appends the steps validating the scope of the metrics to the scenario of the case and runs it
+func run(t *testing.T, env *environment.MetaData, ns string, s scenario.Scenario) {
+	s.Steps = append(s.Steps,
+		scenario.Mark("start"),
+		scenario.Wait(agentRuntime),
+		scenario.Mark("end"),
+		// lets CloudWatch aggregate the last minute
+		scenario.Wait(time.Minute),
+		scenario.Step{Name: "validate mem_total is the memory of the host", Run: func(state scenario.State) error {
+			return validateMemTotal(env, ns, state.Time("start"), state.Time("end"))
+		}},
+		scenario.Step{Name: "validate the cpu metrics are of every cpu of the host", Run: func(scenario.State) error {
+			return validatePerCpu(ns)
+		}},
+		scenario.Step{Name: "validate procstat reports the agent within its limits", Run: func(state scenario.State) error {
+			return validateAgentMemory(env, ns, state.Time("start"), state.Time("end"))
+		}},
+	)
+	result := s.Run()
+	for _, r := range result.TestResults {
+		if r.Status != status.SUCCESSFUL {
+			t.Errorf("step %s failed: %s", r.Name, r.Reason)
+		}
+	}
+}
+
+// checkLimits fails unless the cgroup of the process has the limits, e.g. when the host ignores the CPU quota
+func checkLimits(pid int) error {
+	limits, err := cgroup.ProcessLimits(pid)
+	if err != nil {
+		return fmt.Errorf("failed to read the cgroup limits of pid %d: %w", pid, err)
+	}
+	if limits.MemoryMax != memoryMax {
+		return fmt.Errorf("the memory limit of pid %d is %d bytes, expected %d", pid, limits.MemoryMax, memoryMax)
+	}
+	if math.Abs(limits.CPUs-cpus) > 0.01 {
+		return fmt.Errorf("the CPU quota of pid %d is %v CPUs, expected %v", pid, limits.CPUs, cpus)
+	}
+	return nil
+}
+
+// validateMemTotal fails when mem_total is the limit of the cgroup instead of the memory of the host
+func validateMemTotal(env *environment.MetaData, ns string, start, end time.Time) error {
+	host, err := groundtruth.Read()
+	if err != nil {
+		return err
+	}
+	values, err := fetch(env, ns, "mem_total", nil, metric.MAXIMUM, start, end)
+	if err != nil {
+		return err
+	}
+	expected := host.Values["mem_total"]
+	for _, v := range values {
+		if v <= 2*memoryMax {
+			return fmt.Errorf("mem_total is %v, the %d bytes limit of the agent instead of the %v bytes of the host", v, memoryMax, expected)
+		}
+		if math.Abs(v-expected) > expected*memTotalTolerance {
+			return fmt.Errorf("mem_total is %v, expected the %v bytes of the host", v, expected)
+		}
+	}
+	return nil
+}
+
+// validatePerCpu fails unless cpu_usage_active is published for every cpu of the host, not only those of the quota
+func validatePerCpu(ns string) error {
+	metrics, err := awsservice.ListMetrics(&cloudwatch.ListMetricsInput{
+		Namespace:  aws.String(ns),
+		MetricName: aws.String("cpu_usage_active"),
+		Dimensions: []types.DimensionFilter{{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())}},
+	})
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, m := range metrics {
+		for _, d := range m.Dimensions {
+			if aws.ToString(d.Name) == "cpu" && aws.ToString(d.Value) != "cpu-total" {
+				seen[aws.ToString(d.Value)] = true
+			}
+		}
+	}
+	if len(seen) != runtime.NumCPU() {
+		return fmt.Errorf("cpu_usage_active is published for %d cpus, the host has %d", len(seen), runtime.NumCPU())
+	}
+	return nil
+}
+
+// validateAgentMemory fails when the agent process is reported over the memory limit of its cgroup, i.e. procstat
+// did not report the agent itself
+func validateAgentMemory(env *environment.MetaData, ns string, start, end time.Time) error {
+	values, err := fetch(env, ns, "procstat_memory_rss", []dimension.Instruction{
+		{Key: "exe", Value: dimension.ExpectedDimensionValue{Value: aws.String("cloudwatch-agent")}},
+		{Key: "process_name", Value: dimension.ExpectedDimensionValue{Value: aws.String("amazon-cloudwatch-agent")}},
+	}, metric.MAXIMUM, start, end)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if v <= 0 || v > memoryMax {
+			return fmt.Errorf("procstat_memory_rss of the agent is %v, expected within the %d bytes limit", v, memoryMax)
+		}
+	}
+	return nil
+}
+
+func fetch(env *environment.MetaData, ns, name string, instructions []dimension.Instruction, stat metric.Statistics, start, end time.Time) (metric.MetricValues, error) {
+	instructions = append(instructions, dimension.Instruction{Key: "InstanceId", Value: dimension.UnknownDimensionValue()})
+	factory := dimension.GetDimensionFactory(*env)
+	dims, err := factory.GetDimensions(instructions)
+	if err != nil {
+		return nil, err
+	}
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.FetchWindow(ns, name, dims, stat, 60, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("no values of %s while the agent ran", name)
+	}
+	return values, nil
+}
+
+// writeAgentConfig writes a config collecting the host with the mem and per cpu plugins and the agent with procstat
+func writeAgentConfig(path, ns string) error {
+	config := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"metrics_collection_interval": collectionInterval,
+			"run_as_user":                 "root",
+		},
+		"metrics": map[string]interface{}{
+			"namespace":            ns,
+			"force_flush_interval": 5,
+			"append_dimensions": map[string]interface{}{
+				"InstanceId": "${aws:InstanceId}",
+			},
+			"metrics_collected": map[string]interface{}{
+				"cpu": map[string]interface{}{
+					"measurement": []string{"usage_active"},
+					"totalcpu":    true,
+					"resources":   []string{"*"},
+				},
+				"mem": map[string]interface{}{
+					"measurement": []string{"total", "used_percent"},
+				},
+				"procstat": []map[string]interface{}{{
+					"exe":         "cloudwatch-agent",
+					"measurement": []string{"memory_rss"},
+				}},
+			},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
@@ -77,15 +77,15 @@ func TestCloudformation(t *testing.T) {
 	log.Printf("Sleep for one minute to collect metrics")
 	time.Sleep(time.Minute)
 
-	dims, failed := dimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := dimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   instanceIdKey,
 			Value: dimension.ExpectedDimensionValue{Value: aws.String(instanceId)},
 		},
 	})
 
-	if len(failed) > 0 {
-		t.Fatalf("Failed to generate dimensions")
+	if err != nil {
+		t.Fatalf("Failed to generate dimensions: %v", err)
 		return
 	}
 
@@ -0,0 +1,262 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatch_degradation
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/chaos"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/faultproxy"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	metricName = "mem_used_percent"
+	// collectionPeriod is the interval of the metric and the period its gaps are looked for in
+	collectionPeriod = 10 * time.Second
+	// recovery is how long the agent runs after the trace is over, to drain what it queued during the degradation
+	recovery = 2 * time.Minute
+	// deliveryTimeout lets CloudWatch make the last of the lines and datapoints available
+	deliveryTimeout = 5 * time.Minute
+	pollInterval    = 15 * time.Second
+)
+
+var (
+	envMetaDataStrings = &(environment.MetaDataStrings{})
+	traceFile          string
+	recordTrace        string
+	recordDuration     time.Duration
+)
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+	flag.StringVar(&traceFile, "degradationTrace", "", "Trace to replay, every trace of the traces directory is replayed by default")
+	flag.StringVar(&recordTrace, "recordTrace", "", "Record the responses of CloudWatch to this file instead of replaying the traces, to capture a new trace while the service degrades")
+	flag.DurationVar(&recordDuration, "recordDuration", 10*time.Minute, "How long the responses are recorded for with -recordTrace")
+}
+
+// TestCloudWatchDegradation replays every recorded degradation of CloudWatch between the agent and the endpoints,
+// with the agent collecting a log file and a metric. The agent must retry and queue through the degradation, so
+// every line and every period of the metric is delivered once the trace is over.
+func TestCloudWatchDegradation(t *testing.T) {
+	environment.GetEnvironmentMetaData(envMetaDataStrings)
+	region := awsservice.GetImdsMetadata().Region
+	if recordTrace != "" {
+		require.NoError(t, record(t.TempDir(), region))
+		return
+	}
+
+	paths := []string{traceFile}
+	if traceFile == "" {
+		var err error
+		paths, err = filepath.Glob("traces/*.jsonl")
+		require.NoError(t, err)
+		require.NotEmpty(t, paths, "no traces to replay")
+	}
+	for _, path := range paths {
+		path := path
+		t.Run(strings.TrimSuffix(filepath.Base(path), ".jsonl"), func(t *testing.T) {
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			trace, err := faultproxy.ReadTrace(f)
+			f.Close()
+			require.NoError(t, err)
+			replay(t, region, trace)
+		})
+	}
+}
+
+func replay(t *testing.T, region string, trace faultproxy.Trace) {
+	dir := t.TempDir()
+	logGroup := runid.Name("cloudwatch-degradation")
+	logStream := awsservice.GetInstanceId()
+	ns := namespace.Scope("CloudWatchDegradationTest")
+	defer awsservice.DeleteLogGroup(logGroup)
+	logger.Infof("Replaying %s", trace)
+
+	monitoring, logs, err := startProxies(region, func(target *url.URL) (*faultproxy.Proxy, error) {
+		return faultproxy.Replay(target, trace)
+	})
+	require.NoError(t, err)
+	defer monitoring.Close()
+	defer logs.Close()
+
+	logFile := filepath.Join(dir, "degradation.log")
+	require.NoError(t, writeAgentConfig(dir, monitoring.URL(), logs.URL(), logFile, logGroup, logStream, ns))
+	start := time.Now()
+	require.NoError(t, common.StartAgent(common.ConfigOutputPath, false, false))
+	lines := writeLines(logFile, trace.Duration()+recovery)
+	end := time.Now()
+	common.StopAgent()
+	logger.Infof("Replayed the trace, metrics %+v, logs %+v", monitoring.Stats(), logs.Stats())
+
+	assert.NoError(t, retry(func() error { return awsservice.AssertLogCount(logGroup, logStream, start, time.Now(), lines, 0) }))
+	assert.NoError(t, retry(func() error { return validateMetric(ns, start, end) }))
+}
+
+// record runs the agent through proxies recording the responses of the endpoints, both are written to the trace
+func record(dir, region string) error {
+	f, err := os.Create(recordTrace)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	monitoring, logs, err := startProxies(region, func(target *url.URL) (*faultproxy.Proxy, error) {
+		return faultproxy.Record(target, f)
+	})
+	if err != nil {
+		return err
+	}
+	defer monitoring.Close()
+	defer logs.Close()
+
+	logGroup := runid.Name("cloudwatch-degradation-record")
+	defer awsservice.DeleteLogGroup(logGroup)
+	logFile := filepath.Join(dir, "record.log")
+	if err = writeAgentConfig(dir, monitoring.URL(), logs.URL(), logFile, logGroup, awsservice.GetInstanceId(), namespace.Scope("CloudWatchDegradationTest")); err != nil {
+		return err
+	}
+	if err = common.StartAgent(common.ConfigOutputPath, false, false); err != nil {
+		return err
+	}
+	defer common.StopAgent()
+	writeLines(logFile, recordDuration)
+	logger.Infof("Recorded %d metric and %d logs responses to %s", monitoring.Stats().Forwarded, logs.Stats().Forwarded, recordTrace)
+	return nil
+}
+
+// startProxies starts a proxy in front of the CloudWatch and the CloudWatch Logs endpoints of the region
+func startProxies(region string, start func(*url.URL) (*faultproxy.Proxy, error)) (*faultproxy.Proxy, *faultproxy.Proxy, error) {
+	var proxies []*faultproxy.Proxy
+	for _, service := range []string{"monitoring", "logs"} {
+		target, err := url.Parse(fmt.Sprintf("https://%s.%s.amazonaws.com", service, region))
+		if err != nil {
+			return nil, nil, err
+		}
+		p, err := start(target)
+		if err != nil {
+			for _, started := range proxies {
+				started.Close()
+			}
+			return nil, nil, err
+		}
+		proxies = append(proxies, p)
+	}
+	return proxies[0], proxies[1], nil
+}
+
+// writeLines appends a line to the file every second for the duration and returns how many were written
+func writeLines(path string, d time.Duration) int {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		logger.Errorf("Failed to open %s: %v", path, err)
+		return 0
+	}
+	defer f.Close()
+	lines := 0
+	for deadline := time.Now().Add(d); time.Now().Before(deadline); time.Sleep(time.Second) {
+		if _, err = fmt.Fprintf(f, "%s degradation line %d\n", time.Now().Format(time.RFC3339Nano), lines); err != nil {
+			logger.Errorf("Failed to write line %d: %v", lines, err)
+			continue
+		}
+		lines++
+	}
+	return lines
+}
+
+// validateMetric checks every period the agent ran in has a datapoint, the first and last may be partial
+func validateMetric(ns string, start, end time.Time) error {
+	start, end = start.Add(collectionPeriod), end.Add(-collectionPeriod)
+	fetcher := metric.MetricValueFetcher{}
+	dims := []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())}}
+	values, err := fetcher.FetchPeriods(ns, metricName, dims, metric.SAMPLE_COUNT, int32(collectionPeriod.Seconds()), start, end)
+	if err != nil {
+		return err
+	}
+	if gaps := chaos.MissingPeriods(values, start, end, collectionPeriod); len(gaps) > 0 {
+		return fmt.Errorf("%s has %d gaps through the degradation, the first from %s to %s", metricName, len(gaps),
+			gaps[0].Start.Format(time.RFC3339), gaps[0].End.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func retry(fn func() error) error {
+	deadline := time.Now().Add(deliveryTimeout)
+	for {
+		err := fn()
+		if err == nil || time.Now().Add(pollInterval).After(deadline) {
+			return err
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// writeAgentConfig installs a config sending the log file and the memory of the host through the proxies
+func writeAgentConfig(dir, metricsEndpoint, logsEndpoint, logFile, logGroup, logStream, ns string) error {
+	config := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"run_as_user": "root",
+			"debug":       true,
+		},
+		"metrics": map[string]interface{}{
+			"namespace":            ns,
+			"endpoint_override":    metricsEndpoint,
+			"force_flush_interval": 5,
+			"append_dimensions": map[string]interface{}{
+				"InstanceId": "${aws:InstanceId}",
+			},
+			"metrics_collected": map[string]interface{}{
+				"mem": map[string]interface{}{
+					"measurement":                 []string{"used_percent"},
+					"metrics_collection_interval": int(collectionPeriod.Seconds()),
+				},
+			},
+		},
+		"logs": map[string]interface{}{
+			"endpoint_override":    logsEndpoint,
+			"force_flush_interval": 5,
+			"logs_collected": map[string]interface{}{
+				"files": map[string]interface{}{
+					"collect_list": []map[string]interface{}{{
+						"file_path":       logFile,
+						"log_group_name":  logGroup,
+						"log_stream_name": logStream,
+						"timezone":        "UTC",
+					}},
+				},
+			},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.json")
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	common.CopyFile(path, common.ConfigOutputPath)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	insightClients      = 5
+	insightReportPeriod = 60
+	// Contributor Insights takes a few minutes to evaluate new log events
+	insightReportTimeout = 10 * time.Minute
+)
+
+// TestContributorInsightsRule validates a Contributor Insights rule on the log group the agent delivers to sees every
+// client in the generated logs, with the number of events each client wrote
+func TestContributorInsightsRule(t *testing.T) {
+	instanceId := awsservice.GetInstanceId()
+	logger.Infof("Found instance id %s", instanceId)
+	logGroup := instanceId + "Insights"
+	logStream := instanceId
+	ruleName := runid.Name("insights")
+
+	defer awsservice.DeleteLogGroupAndStream(logGroup, logStream)
+
+	// the rule only evaluates events ingested after it is created, so the group and rule are set up first
+	require.NoError(t, awsservice.CreateLogGroup(logGroup, ""))
+	require.NoError(t, awsservice.PutLogInsightRule(ruleName, logGroup, []string{"$.client"}))
+	defer func() {
+		if err := awsservice.DeleteInsightRule(ruleName); err != nil {
+			logger.Errorf("Failed to delete insight rule %s: %v", ruleName, err)
+		}
+	}()
+
+	f, err := os.Create(logFilePath)
+	require.NoError(t, err)
+	defer f.Close()
+	defer os.Remove(logFilePath)
+
+	start := time.Now()
+	common.CopyFile("resources/config_log_insights.json", configOutputPath)
+	common.StartAgent(configOutputPath, true, false)
+
+	time.Sleep(agentRuntime)
+	expected := writeClientLogs(t, f)
+	time.Sleep(agentRuntime)
+	common.StopAgent()
+
+	total := 0
+	for _, count := range expected {
+		total += count
+	}
+	report, err := awsservice.WaitForInsightRuleReport(ruleName, start.Truncate(time.Minute), insightReportPeriod, insightReportTimeout, func(report *cloudwatch.GetInsightRuleReportOutput) bool {
+		return aws.ToInt64(report.ApproximateUniqueCount) >= insightClients && sumContributors(report) >= float64(total)
+	})
+	require.NoError(t, err)
+
+	assert.EqualValues(t, insightClients, aws.ToInt64(report.ApproximateUniqueCount))
+	actual := map[string]int{}
+	for _, contributor := range report.Contributors {
+		require.Len(t, contributor.Keys, 1)
+		actual[contributor.Keys[0]] = int(aws.ToFloat64(contributor.ApproximateAggregateValue))
+	}
+	assert.Equal(t, expected, actual)
+}
+
+// writeClientLogs writes JSON log lines for insightClients clients, where client i writes 10*(i+1) lines so the
+// contributors are distinguishable by count, and returns the number of lines per client
+func writeClientLogs(t *testing.T, f *os.File) map[string]int {
+	expected := map[string]int{}
+	for i := 0; i < insightClients; i++ {
+		client := fmt.Sprintf("client-%d", i)
+		expected[client] = 10 * (i + 1)
+		for j := 0; j < expected[client]; j++ {
+			_, err := f.WriteString(fmt.Sprintf("{\"client\":\"%s\",\"request\":%d,\"message\":\"This is a log line.\"}\n", client, j))
+			if err != nil {
+				t.Logf("Error occurred writing log line: %v", err)
+			}
+		}
+	}
+	logger.Infof("Wrote JSON lines for %d clients to %s", insightClients, f.Name())
+	return expected
+}
+
+func sumContributors(report *cloudwatch.GetInsightRuleReportOutput) float64 {
+	sum := 0.0
+	for _, contributor := range report.Contributors {
+		sum += aws.ToFloat64(contributor.ApproximateAggregateValue)
+	}
+	return sum
+}
@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+// TestKmsEncryptedLogGroup validates the agent delivers to a log group encrypted with the KMS key passed with
+// -kmsKeyArn, and the group is still encrypted with that key afterwards
+func TestKmsEncryptedLogGroup(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	if env.KmsKeyArn == "" {
+		t.Skip("-kmsKeyArn is not set")
+	}
+
+	instanceId := awsservice.GetInstanceId()
+	log.Printf("Found instance id %s", instanceId)
+	logGroup := instanceId + "Kms"
+	logStream := instanceId
+
+	defer awsservice.DeleteLogGroupAndStream(logGroup, logStream)
+
+	// the agent creates unencrypted groups, so the group is created first and associated with the key
+	require.NoError(t, awsservice.CreateLogGroup(logGroup, ""))
+	require.NoError(t, awsservice.AssociateKmsKey(logGroup, env.KmsKeyArn))
+
+	f, err := os.Create(logFilePath)
+	require.NoError(t, err)
+	defer f.Close()
+	defer os.Remove(logFilePath)
+
+	start := time.Now()
+	common.CopyFile("resources/config_log_kms.json", configOutputPath)
+	common.StartAgent(configOutputPath, true, false)
+
+	time.Sleep(agentRuntime)
+	writeLogs(t, f, 100)
+	time.Sleep(agentRuntime)
+	common.StopAgent()
+	end := time.Now()
+
+	ok, err := awsservice.ValidateLogs(logGroup, logStream, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		return len(logs) == 100*len(logLineIds)
+	}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	kmsKeyId, err := awsservice.GetLogGroupKmsKeyId(logGroup)
+	assert.NoError(t, err)
+	assert.Equal(t, env.KmsKeyArn, kmsKeyId)
+}
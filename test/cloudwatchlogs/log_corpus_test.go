@@ -0,0 +1,164 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logcorpus"
+)
+
+const (
+	// Must match the file_path of log_corpus_config.json, whose multi_line_start_pattern is logcorpus.StartPattern
+	logCorpusDir     = "/tmp/cwagent_corpus"
+	logCorpusEntries = 200
+	// logCorpusSeed fixes the mix of the entries from one run to the next
+	logCorpusSeed = 1
+)
+
+// logCorpusFilter must match the include filter of the filtered file of log_corpus_config.json
+var logCorpusFilter = regexp.MustCompile("ERROR|Exception")
+
+// LogCorpusTestRunner writes the same realistic log corpus to two files, one collected with the multiline start
+// pattern of the corpus and one with an include filter on top, and validates every entry, stack traces included, is
+// delivered as one event and the filter keeps exactly the entries it matches
+type LogCorpusTestRunner struct {
+	test_runner.BaseTestRunner
+	logGroup string
+	start    time.Time
+	entries  []logcorpus.Entry
+}
+
+var _ test_runner.ITestRunner = (*LogCorpusTestRunner)(nil)
+
+func (t *LogCorpusTestRunner) Validate() status.TestGroupResult {
+	var filtered []string
+	all := make([]string, len(t.entries))
+	for i, e := range t.entries {
+		all[i] = e.String()
+		if logCorpusFilter.MatchString(all[i]) {
+			filtered = append(filtered, all[i])
+		}
+	}
+	return status.TestGroupResult{
+		Name: t.GetTestName(),
+		TestResults: []status.TestResult{
+			t.validateStream("Multiline", all),
+			t.validateStream("Filtered", filtered),
+		},
+	}
+}
+
+func (t *LogCorpusTestRunner) GetTestName() string {
+	return "LogCorpus"
+}
+
+func (t *LogCorpusTestRunner) GetAgentConfigFileName() string {
+	return "log_corpus_config.json"
+}
+
+func (t *LogCorpusTestRunner) GetMeasuredMetrics() []string {
+	return nil
+}
+
+func (t *LogCorpusTestRunner) SetupBeforeAgentRun() error {
+	t.logGroup = awsservice.GetInstanceId() + "Corpus"
+	t.start = time.Now()
+	if err := os.MkdirAll(logCorpusDir, 0755); err != nil {
+		return err
+	}
+	return t.SetUpConfig()
+}
+
+// SetupAfterAgentRun writes the corpus once the agent tails the files, so it starts from their first entry
+func (t *LogCorpusTestRunner) SetupAfterAgentRun() error {
+	corpus, err := logcorpus.New(logcorpus.DefaultMix, logCorpusSeed)
+	if err != nil {
+		return err
+	}
+	var f *os.File
+	if f, err = os.Create(filepath.Join(logCorpusDir, "multiline.log")); err != nil {
+		return err
+	}
+	defer f.Close()
+	if t.entries, err = corpus.Write(f, logCorpusEntries); err != nil {
+		return err
+	}
+	var b []byte
+	for _, e := range t.entries {
+		b = append(b, e.String()+"\n"...)
+	}
+	return os.WriteFile(filepath.Join(logCorpusDir, "filtered.log"), b, 0644)
+}
+
+// Cleanup deletes the log group and the files
+func (t *LogCorpusTestRunner) Cleanup() {
+	if t.logGroup != "" {
+		awsservice.DeleteLogGroup(t.logGroup)
+		t.logGroup = ""
+	}
+	os.RemoveAll(logCorpusDir)
+}
+
+// validateStream expects every entry delivered as a single event as many times as it was written, and nothing else
+func (t *LogCorpusTestRunner) validateStream(name string, expected []string) status.TestResult {
+	testResult := status.TestResult{
+		Name:     name + " entries",
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("%d entries", len(expected)),
+	}
+	events, err := awsservice.GetLogEvents(t.logGroup, awsservice.GetInstanceId()+name, &t.start, nil)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Actual = fmt.Sprintf("%d events", len(events))
+
+	delivered := make(map[string]int, len(events))
+	for _, event := range events {
+		delivered[aws.ToString(event.Message)]++
+	}
+	// the event lines written in the same second are identical, so the entries are counted too
+	written := make(map[string]int, len(expected))
+	for _, entry := range expected {
+		written[entry]++
+	}
+	for entry, count := range written {
+		if delivered[entry] != count {
+			testResult.Reason = fmt.Sprintf("%q was delivered %d times instead of %d", entry, delivered[entry], count)
+			return testResult
+		}
+	}
+	if len(events) != len(expected) {
+		testResult.Reason = "events other than the entries were delivered, e.g. lines of an entry split from it"
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func TestLogCorpus(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	runner := test_runner.TestRunner{TestRunner: &LogCorpusTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	result.Print()
+	if result.GetStatus() != status.SUCCESSFUL {
+		t.Fatal("Log corpus test failed")
+	}
+}
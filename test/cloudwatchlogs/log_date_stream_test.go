@@ -0,0 +1,173 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+const (
+	// Must match the file_path of log_date_stream_config.json, which names the streams {instance_id}-{date}
+	logDateStreamFile = "/tmp/cwagent_date_stream.log"
+	// logDateStreamLayout is how {date} formats the UTC date of an event
+	logDateStreamLayout = "2006-01-02"
+	// logDateStreamTimestampLayout matches the timestamp_format of the config
+	logDateStreamTimestampLayout = "2006-01-02T15:04:05"
+	// the lines are timestamped every step from span before until span after the day boundary
+	logDateStreamSpan = time.Minute
+	logDateStreamStep = 2 * time.Second
+)
+
+// LogDateStreamTestRunner writes lines timestamped around a day boundary and validates each lands, once, in the
+// stream named after the date of its timestamp. Instead of waiting for midnight, the boundary is the last midnight UTC
+// and the timestamps are parsed with timestamp_format, CloudWatch Logs accepts events up to 14 days old.
+type LogDateStreamTestRunner struct {
+	test_runner.BaseTestRunner
+	logGroup string
+	boundary time.Time
+}
+
+var _ test_runner.ITestRunner = (*LogDateStreamTestRunner)(nil)
+
+func (t *LogDateStreamTestRunner) Validate() status.TestGroupResult {
+	return status.TestGroupResult{
+		Name: t.GetTestName(),
+		TestResults: []status.TestResult{
+			t.validateStream(t.boundary.Add(-time.Nanosecond)),
+			t.validateStream(t.boundary),
+		},
+	}
+}
+
+func (t *LogDateStreamTestRunner) GetTestName() string {
+	return "LogDateStream"
+}
+
+func (t *LogDateStreamTestRunner) GetAgentConfigFileName() string {
+	return "log_date_stream_config.json"
+}
+
+func (t *LogDateStreamTestRunner) GetAgentRunDuration() time.Duration {
+	return 30 * time.Second
+}
+
+func (t *LogDateStreamTestRunner) GetMeasuredMetrics() []string {
+	return nil
+}
+
+func (t *LogDateStreamTestRunner) SetupBeforeAgentRun() error {
+	t.logGroup = awsservice.GetInstanceId() + "DateStream"
+	t.boundary = time.Now().UTC().Truncate(24 * time.Hour)
+	if err := os.WriteFile(logDateStreamFile, nil, 0644); err != nil {
+		return err
+	}
+	return t.SetUpConfig()
+}
+
+// SetupAfterAgentRun writes the lines of both days once the agent tails the file
+func (t *LogDateStreamTestRunner) SetupAfterAgentRun() error {
+	var b strings.Builder
+	for _, line := range t.lines() {
+		b.WriteString(line + "\n")
+	}
+	f, err := os.OpenFile(logDateStreamFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(b.String())
+	return err
+}
+
+// Cleanup deletes the log group with the streams of both days and the file
+func (t *LogDateStreamTestRunner) Cleanup() {
+	if t.logGroup != "" {
+		awsservice.DeleteLogGroup(t.logGroup)
+		t.logGroup = ""
+	}
+	os.Remove(logDateStreamFile)
+}
+
+// lines are timestamped around the boundary in order, each line is unique
+func (t *LogDateStreamTestRunner) lines() []string {
+	var lines []string
+	for ts := t.boundary.Add(-logDateStreamSpan); ts.Before(t.boundary.Add(logDateStreamSpan)); ts = ts.Add(logDateStreamStep) {
+		lines = append(lines, fmt.Sprintf("%s date stream line %d", ts.Format(logDateStreamTimestampLayout), len(lines)))
+	}
+	return lines
+}
+
+// linesOf are the lines with a timestamp on the date of day
+func (t *LogDateStreamTestRunner) linesOf(day time.Time) []string {
+	var lines []string
+	for _, line := range t.lines() {
+		timestamp, _, _ := strings.Cut(line, " ")
+		ts, _ := time.Parse(logDateStreamTimestampLayout, timestamp)
+		if ts.Format(logDateStreamLayout) == day.Format(logDateStreamLayout) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// validateStream expects the stream of the day has every line of that day once and no line of another day
+func (t *LogDateStreamTestRunner) validateStream(day time.Time) status.TestResult {
+	stream := awsservice.GetInstanceId() + "-" + day.Format(logDateStreamLayout)
+	want := t.linesOf(day)
+	testResult := status.TestResult{
+		Name:     stream,
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("%d lines once each", len(want)),
+	}
+	since := t.boundary.Add(-2 * logDateStreamSpan)
+	events, err := awsservice.GetLogEvents(t.logGroup, stream, &since, nil)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+
+	delivered := make(map[string]int, len(events))
+	for _, event := range events {
+		delivered[aws.ToString(event.Message)]++
+	}
+	testResult.Actual = fmt.Sprintf("%d lines", len(events))
+	for _, line := range want {
+		if count := delivered[line]; count != 1 {
+			testResult.Reason = fmt.Sprintf("%q was delivered %d times", line, count)
+			return testResult
+		}
+		delete(delivered, line)
+	}
+	for line := range delivered {
+		testResult.Reason = fmt.Sprintf("%q of another day was delivered to the stream", line)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func TestLogDateStream(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	runner := test_runner.TestRunner{TestRunner: &LogDateStreamTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	result.Print()
+	if result.GetStatus() != status.SUCCESSFUL {
+		t.Fatal("Log date stream test failed")
+	}
+}
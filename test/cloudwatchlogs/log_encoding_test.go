@@ -0,0 +1,191 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+const logEncodingLines = 20
+
+// encodedLogFile is a file the agent decodes with the encoding option of log_encoding_config.json
+type encodedLogFile struct {
+	// encoding is the encoding option, the file is /tmp/cwagent_encoding_<encoding>.log and the log stream
+	// <instance id><encoding>
+	encoding string
+	// text is appended to every line, it has characters which are encoded differently than in UTF-8
+	text   string
+	encode func(string) []byte
+}
+
+var encodedLogFiles = []encodedLogFile{
+	{
+		// windows-1252 is a superset of the printable latin-1 characters
+		encoding: "windows-1252",
+		text:     "café naïve façade ÆØÅ ¿señor? £5 ±1°",
+		encode:   encodeLatin1,
+	},
+	{
+		encoding: "utf-16le",
+		text:     "café 日本語 ελληνικά кириллица 😀",
+		encode:   encodeUTF16LE,
+	},
+}
+
+func (f encodedLogFile) path() string {
+	return fmt.Sprintf("/tmp/cwagent_encoding_%s.log", f.encoding)
+}
+
+func (f encodedLogFile) lines() []string {
+	lines := make([]string, logEncodingLines)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s line %d %s", f.encoding, i, f.text)
+	}
+	return lines
+}
+
+// LogEncodingTestRunner writes files in encodings other than UTF-8, and validates the agent delivers the lines
+// transcoded to UTF-8 with the encoding the config sets for each file
+type LogEncodingTestRunner struct {
+	test_runner.BaseTestRunner
+	logGroup string
+	start    time.Time
+}
+
+var _ test_runner.ITestRunner = (*LogEncodingTestRunner)(nil)
+
+func (t *LogEncodingTestRunner) Validate() status.TestGroupResult {
+	result := status.TestGroupResult{Name: t.GetTestName()}
+	for _, f := range encodedLogFiles {
+		result.TestResults = append(result.TestResults, t.validateEncoding(f))
+	}
+	return result
+}
+
+func (t *LogEncodingTestRunner) GetTestName() string {
+	return "LogEncoding"
+}
+
+func (t *LogEncodingTestRunner) GetAgentConfigFileName() string {
+	return "log_encoding_config.json"
+}
+
+func (t *LogEncodingTestRunner) GetMeasuredMetrics() []string {
+	return nil
+}
+
+func (t *LogEncodingTestRunner) SetupBeforeAgentRun() error {
+	t.logGroup = awsservice.GetInstanceId() + "Encoding"
+	t.start = time.Now()
+	return t.SetUpConfig()
+}
+
+func (t *LogEncodingTestRunner) SetupAfterAgentRun() error {
+	for _, f := range encodedLogFiles {
+		var content []byte
+		for _, line := range f.lines() {
+			content = append(content, f.encode(line+"\n")...)
+		}
+		if err := os.WriteFile(f.path(), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup deletes the log group and the files
+func (t *LogEncodingTestRunner) Cleanup() {
+	if t.logGroup != "" {
+		for _, f := range encodedLogFiles {
+			awsservice.DeleteLogStream(t.logGroup, awsservice.GetInstanceId()+f.encoding)
+		}
+		awsservice.DeleteLogGroup(t.logGroup)
+		t.logGroup = ""
+	}
+	for _, f := range encodedLogFiles {
+		os.Remove(f.path())
+	}
+}
+
+// validateEncoding expects every line is delivered once exactly as it was before it was encoded, a mangled line has
+// replacement characters, split characters or stray NUL bytes
+func (t *LogEncodingTestRunner) validateEncoding(f encodedLogFile) status.TestResult {
+	testResult := status.TestResult{
+		Name:     f.encoding,
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("%d lines transcoded to UTF-8", logEncodingLines),
+	}
+	events, err := awsservice.GetLogEvents(t.logGroup, awsservice.GetInstanceId()+f.encoding, &t.start, nil)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	delivered := make(map[string]int, len(events))
+	for _, event := range events {
+		delivered[aws.ToString(event.Message)]++
+	}
+	testResult.Actual = fmt.Sprintf("%d lines", len(events))
+
+	for _, line := range f.lines() {
+		if count := delivered[line]; count != 1 {
+			testResult.Reason = fmt.Sprintf("%q was delivered %d times", line, count)
+			if len(events) > 0 {
+				testResult.Reason += fmt.Sprintf(", the first event is %q", aws.ToString(events[0].Message))
+			}
+			return testResult
+		}
+	}
+	if len(events) != logEncodingLines {
+		testResult.Reason = "unexpected events were delivered"
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// encodeLatin1 encodes the text in ISO-8859-1, it must not have characters beyond U+00FF
+func encodeLatin1(s string) []byte {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		b = append(b, byte(r))
+	}
+	return b
+}
+
+// encodeUTF16LE encodes the text in UTF-16 little endian without a byte order mark
+func encodeUTF16LE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		binary.LittleEndian.PutUint16(b[2*i:], u)
+	}
+	return b
+}
+
+func TestLogEncoding(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	runner := test_runner.TestRunner{TestRunner: &LogEncodingTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	result.Print()
+	if result.GetStatus() != status.SUCCESSFUL {
+		t.Fatal("Log encoding test failed")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+const (
+	// Must match the file_path of log_glob_config.json, which collects *.log except the files matching ^excluded_
+	logGlobDir   = "/tmp/cwagent_glob"
+	logGlobLines = 20
+	// logGlobDiscovery is how long the agent gets to discover the files created after it started
+	logGlobDiscovery = 30 * time.Second
+)
+
+// globFile is a file in logGlobDir and whether the glob and blacklist of the config select it
+type globFile struct {
+	name     string
+	selected bool
+}
+
+var (
+	// globFilesBefore exist before the agent starts
+	globFilesBefore = []globFile{
+		{name: "before.log", selected: true},
+		{name: "excluded_before.log", selected: false},
+		{name: "before.txt", selected: false},
+	}
+	// globFilesAfter are created while the agent runs, the agent must pick up the selected one without a restart
+	globFilesAfter = []globFile{
+		{name: "after.log", selected: true},
+		{name: "excluded_after.log", selected: false},
+		{name: "after.txt", selected: false},
+	}
+)
+
+// LogGlobTestRunner collects a directory with a file_path glob and a blacklist, and validates only the lines of the
+// files they select are delivered, including the files created after the agent started
+type LogGlobTestRunner struct {
+	test_runner.BaseTestRunner
+	logGroup string
+	start    time.Time
+}
+
+var _ test_runner.ITestRunner = (*LogGlobTestRunner)(nil)
+
+func (t *LogGlobTestRunner) Validate() status.TestGroupResult {
+	result := status.TestGroupResult{Name: t.GetTestName()}
+	events, err := awsservice.GetLogEvents(t.logGroup, awsservice.GetInstanceId(), &t.start, nil)
+	if err != nil {
+		testResult := status.TestResult{Name: "Log events", Status: status.FAILED}
+		testResult.SetError(err)
+		result.TestResults = []status.TestResult{testResult}
+		return result
+	}
+
+	// the lines start with the name of the file they were written to
+	delivered := make(map[string]map[string]int)
+	for _, event := range events {
+		message := aws.ToString(event.Message)
+		name, _, _ := strings.Cut(message, " ")
+		if delivered[name] == nil {
+			delivered[name] = make(map[string]int)
+		}
+		delivered[name][message]++
+	}
+	for _, f := range append(append([]globFile(nil), globFilesBefore...), globFilesAfter...) {
+		result.TestResults = append(result.TestResults, validateGlobFile(f, delivered[f.name]))
+	}
+	return result
+}
+
+func (t *LogGlobTestRunner) GetTestName() string {
+	return "LogGlob"
+}
+
+func (t *LogGlobTestRunner) GetAgentConfigFileName() string {
+	return "log_glob_config.json"
+}
+
+func (t *LogGlobTestRunner) GetAgentRunDuration() time.Duration {
+	return logGlobDiscovery
+}
+
+func (t *LogGlobTestRunner) GetMeasuredMetrics() []string {
+	return nil
+}
+
+func (t *LogGlobTestRunner) SetupBeforeAgentRun() error {
+	t.logGroup = awsservice.GetInstanceId() + "Glob"
+	t.start = time.Now()
+	if err := os.MkdirAll(logGlobDir, 0755); err != nil {
+		return err
+	}
+	if err := writeGlobFiles(globFilesBefore); err != nil {
+		return err
+	}
+	return t.SetUpConfig()
+}
+
+// SetupAfterAgentRun creates the files the agent has to discover while it runs
+func (t *LogGlobTestRunner) SetupAfterAgentRun() error {
+	return writeGlobFiles(globFilesAfter)
+}
+
+// Cleanup deletes the log group and the files
+func (t *LogGlobTestRunner) Cleanup() {
+	if t.logGroup != "" {
+		awsservice.DeleteLogGroupAndStream(t.logGroup, awsservice.GetInstanceId())
+		t.logGroup = ""
+	}
+	os.RemoveAll(logGlobDir)
+}
+
+func writeGlobFiles(files []globFile) error {
+	for _, f := range files {
+		var b strings.Builder
+		for _, line := range globFileLines(f.name) {
+			b.WriteString(line + "\n")
+		}
+		if err := os.WriteFile(filepath.Join(logGlobDir, f.name), []byte(b.String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func globFileLines(name string) []string {
+	lines := make([]string, logGlobLines)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%s line %d", name, i)
+	}
+	return lines
+}
+
+// validateGlobFile expects every line of a selected file is delivered once, and none of the others
+func validateGlobFile(f globFile, delivered map[string]int) status.TestResult {
+	testResult := status.TestResult{
+		Name:   f.name + " excluded",
+		Status: status.FAILED,
+	}
+	if !f.selected {
+		testResult.Expected = "no lines"
+		testResult.Actual = fmt.Sprintf("%d lines", len(delivered))
+		if len(delivered) > 0 {
+			testResult.Reason = "the lines of a file the glob or the blacklist excludes were delivered"
+			return testResult
+		}
+		testResult.Status = status.SUCCESSFUL
+		return testResult
+	}
+
+	testResult.Name = f.name + " delivered"
+	testResult.Expected = fmt.Sprintf("%d lines once each", logGlobLines)
+	testResult.Actual = fmt.Sprintf("%d lines", len(delivered))
+	for _, line := range globFileLines(f.name) {
+		if count := delivered[line]; count != 1 {
+			testResult.Reason = fmt.Sprintf("%q was delivered %d times", line, count)
+			return testResult
+		}
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func TestLogGlob(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	runner := test_runner.TestRunner{TestRunner: &LogGlobTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	result.Print()
+	if result.GetStatus() != status.SUCCESSFUL {
+		t.Fatal("Log glob test failed")
+	}
+}
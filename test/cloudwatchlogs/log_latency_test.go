@@ -0,0 +1,125 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package cloudwatchlogs
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/completeness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+)
+
+const (
+	latencyLogFilePath = "/tmp/test_latency.log"
+	latencyLines       = 60
+	latencyInterval    = time.Second
+)
+
+// LogLatencyTestRunner writes a line every second while the agent runs and reports the p50/p90/p99 of the time from
+// each line being written to CloudWatch Logs ingesting it. Only lines that are never delivered fail the runner, the
+// latency is reported for tracking.
+type LogLatencyTestRunner struct {
+	test_runner.BaseTestRunner
+	logGroup string
+	start    time.Time
+	recorder *latency.Recorder
+}
+
+var _ test_runner.ITestRunner = (*LogLatencyTestRunner)(nil)
+
+func (t *LogLatencyTestRunner) Validate() status.TestGroupResult {
+	testResult := status.TestResult{
+		Name:     "Delivered lines",
+		Status:   status.FAILED,
+		Expected: fmt.Sprint(latencyLines),
+	}
+	result := status.TestGroupResult{Name: t.GetTestName()}
+
+	events, err := awsservice.GetLogEvents(t.logGroup, awsservice.GetInstanceId(), &t.start, nil)
+	if err != nil {
+		testResult.Reason = err.Error()
+		result.TestResults = []status.TestResult{testResult}
+		return result
+	}
+
+	stats := t.recorder.Measure(events)
+	result.LogLatency = &stats
+	result.Completeness = []completeness.Report{{Kind: completeness.LogLines, Name: t.logGroup, Sent: latencyLines, Observed: int64(stats.Count)}}
+	testResult.Actual = fmt.Sprint(stats.Count)
+	if stats.Missing > 0 {
+		testResult.Reason = fmt.Sprintf("%d lines were not delivered", stats.Missing)
+	} else {
+		testResult.Status = status.SUCCESSFUL
+	}
+	result.TestResults = []status.TestResult{testResult}
+	return result
+}
+
+func (t *LogLatencyTestRunner) GetTestName() string {
+	return "LogLatency"
+}
+
+func (t *LogLatencyTestRunner) GetAgentConfigFileName() string {
+	return "log_latency_config.json"
+}
+
+func (t *LogLatencyTestRunner) GetMeasuredMetrics() []string {
+	return nil
+}
+
+func (t *LogLatencyTestRunner) SetupBeforeAgentRun() error {
+	t.logGroup = awsservice.GetInstanceId() + "Latency"
+	t.start = time.Now()
+	t.recorder = latency.NewRecorder()
+	return t.SetUpConfig()
+}
+
+// SetupAfterAgentRun writes the lines, recording the time each one is written
+func (t *LogLatencyTestRunner) SetupAfterAgentRun() error {
+	f, err := os.Create(latencyLogFilePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for i := 0; i < latencyLines; i++ {
+		line := fmt.Sprintf("latency line %d %d", i, time.Now().UnixNano())
+		if _, err = f.WriteString(line + "\n"); err != nil {
+			return err
+		}
+		t.recorder.Record(line)
+		time.Sleep(latencyInterval)
+	}
+	return nil
+}
+
+// Cleanup deletes the log group and the log file
+func (t *LogLatencyTestRunner) Cleanup() {
+	if t.logGroup != "" {
+		awsservice.DeleteLogGroupAndStream(t.logGroup, awsservice.GetInstanceId())
+		t.logGroup = ""
+	}
+	os.Remove(latencyLogFilePath)
+}
+
+func TestLogDeliveryLatency(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	factory := dimension.GetDimensionFactory(*env)
+	runner := test_runner.TestRunner{TestRunner: &LogLatencyTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}}
+	result := runner.Run()
+	result.Print()
+	if result.GetStatus() != status.SUCCESSFUL {
+		t.Fatal("Log delivery latency test failed")
+	}
+}
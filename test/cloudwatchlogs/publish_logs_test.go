@@ -101,9 +101,10 @@ func TestWriteLogsToCloudWatch(t *testing.T) {
 			end := time.Now()
 
 			// check CWL to ensure we got the expected number of logs in the log stream
-			ok, err := awsservice.ValidateLogs(instanceId, instanceId, &start, &end, func(logs []string) bool {
+			awsservice.TagLogGroupForRun(instanceId)
+			ok, err := awsservice.ValidateLogs(instanceId, instanceId, &start, &end, awsservice.StringValidator(func(logs []string) bool {
 				return param.numExpectedLogs == len(logs)
-			})
+			}))
 			assert.NoError(t, err)
 			assert.True(t, ok)
 		})
@@ -153,7 +154,8 @@ func TestRotatingLogsDoesNotSkipLines(t *testing.T) {
 
 	end := time.Now()
 
-	ok, err := awsservice.ValidateLogs(logGroup, logStream, &start, &end, func(logs []string) bool {
+	awsservice.TagLogGroupForRun(logGroup)
+	ok, err := awsservice.ValidateLogs(logGroup, logStream, &start, &end, awsservice.StringValidator(func(logs []string) bool {
 		if len(logs) != len(lines) {
 			return false
 		}
@@ -167,7 +169,7 @@ func TestRotatingLogsDoesNotSkipLines(t *testing.T) {
 		}
 
 		return true
-	})
+	}))
 	assert.NoError(t, err)
 	assert.True(t, ok)
 }
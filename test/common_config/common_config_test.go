@@ -0,0 +1,67 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package common_config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
+)
+
+type CommonConfigTestSuite struct {
+	suite.Suite
+	test_runner.TestSuite
+}
+
+func (suite *CommonConfigTestSuite) SetupSuite() {
+	fmt.Println(">>>> Starting CommonConfigTestSuite")
+}
+
+func (suite *CommonConfigTestSuite) TearDownSuite() {
+	report.Send(suite.Result)
+	fmt.Println(">>>> Finished CommonConfigTestSuite")
+}
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+func getTestRunners(env *environment.MetaData) []*test_runner.TestRunner {
+	factory := dimension.GetDimensionFactory(*env)
+	testRunners := make([]*test_runner.TestRunner, len(commonConfigCases))
+	for i, c := range commonConfigCases {
+		testRunners[i] = &test_runner.TestRunner{TestRunner: &CommonConfigTestRunner{
+			BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory},
+			testCase:       c,
+		}}
+	}
+	return testRunners
+}
+
+func (suite *CommonConfigTestSuite) TestAllInSuite() {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	for _, testRunner := range getTestRunners(env) {
+		suite.AddToSuiteResult(testRunner.Run())
+	}
+	suite.Assert().Equal(status.SUCCESSFUL, suite.Result.GetStatus(), "Common Config Test Suite Failed")
+}
+
+func (suite *CommonConfigTestSuite) AddToSuiteResult(r status.TestGroupResult) {
+	suite.Result.TestGroupResults = append(suite.Result.TestGroupResults, r)
+}
+
+func TestCommonConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(CommonConfigTestSuite))
+}
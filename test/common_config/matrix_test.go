@@ -0,0 +1,215 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package common_config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	namespace    = "CommonConfigTest"
+	measuredName = "mem_used_percent"
+
+	credentialProfile = "cwagent"
+	// unreachableProxy refuses every connection, the agent can only publish when it bypasses the proxy
+	unreachableProxy = "http://127.0.0.1:9"
+	// imdsHost must bypass the proxy in every case, the agent looks up the instance id and region with it
+	imdsHost = "169.254.169.254"
+)
+
+// commonConfigCase is a permutation of common-config.toml and where the agent is expected to publish with it
+type commonConfigCase struct {
+	name string
+	// config returns the common config of the case, a shared credential file it uses is written to the directory
+	config func(dir string) (common.CommonConfig, error)
+	// published is false when the agent must not be able to publish
+	published bool
+	// regionOverride is true when the agent must publish to overrideRegion instead of the region of the instance
+	regionOverride bool
+}
+
+var commonConfigCases = []commonConfigCase{
+	{
+		name:      "Default",
+		config:    func(string) (common.CommonConfig, error) { return common.CommonConfig{}, nil },
+		published: true,
+	},
+	{
+		name: "SharedCredentialProfile",
+		config: func(dir string) (common.CommonConfig, error) {
+			file, err := writeCredentials(dir, "")
+			return common.CommonConfig{SharedCredentialProfile: credentialProfile, SharedCredentialFile: file}, err
+		},
+		published: true,
+	},
+	{
+		// the agent must not fall back to the instance role when the profile it was given does not exist
+		name: "MissingSharedCredentialProfile",
+		config: func(dir string) (common.CommonConfig, error) {
+			file, err := writeCredentials(dir, "")
+			return common.CommonConfig{SharedCredentialProfile: credentialProfile + "-missing", SharedCredentialFile: file}, err
+		},
+		published: false,
+	},
+	{
+		// the region of the shared credential profile takes precedence over the one of the instance
+		name: "RegionOverride",
+		config: func(dir string) (common.CommonConfig, error) {
+			file, err := writeCredentials(dir, overrideRegion())
+			return common.CommonConfig{SharedCredentialProfile: credentialProfile, SharedCredentialFile: file}, err
+		},
+		published:      true,
+		regionOverride: true,
+	},
+	{
+		name: "UnreachableProxy",
+		config: func(string) (common.CommonConfig, error) {
+			return common.CommonConfig{HttpProxy: unreachableProxy, HttpsProxy: unreachableProxy, NoProxy: imdsHost}, nil
+		},
+		published: false,
+	},
+	{
+		name: "NoProxyBypass",
+		config: func(string) (common.CommonConfig, error) {
+			return common.CommonConfig{HttpProxy: unreachableProxy, HttpsProxy: unreachableProxy, NoProxy: imdsHost + ",amazonaws.com"}, nil
+		},
+		published: true,
+	},
+}
+
+// CommonConfigTestRunner starts the agent with the common config of its case and validates whether and where the agent
+// publishes, which is the effective behavior of the common config
+type CommonConfigTestRunner struct {
+	test_runner.BaseTestRunner
+	testCase      commonConfigCase
+	credentialDir string
+	started       time.Time
+}
+
+var _ test_runner.ITestRunner = (*CommonConfigTestRunner)(nil)
+
+func (t *CommonConfigTestRunner) Validate() status.TestGroupResult {
+	fetcher := metric.MetricValueFetcher{}
+	testResults := []status.TestResult{t.validatePublished(fetcher, awsservice.GetImdsMetadata().Region, t.testCase.published && !t.testCase.regionOverride)}
+	if t.testCase.regionOverride {
+		fetcher.Client = awsservice.NewCloudWatchClient(overrideRegion())
+		testResults = append(testResults, t.validatePublished(fetcher, overrideRegion(), true))
+	}
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (t *CommonConfigTestRunner) GetTestName() string {
+	return "CommonConfig" + t.testCase.name
+}
+
+func (t *CommonConfigTestRunner) GetAgentConfigFileName() string {
+	return "config.json"
+}
+
+func (t *CommonConfigTestRunner) GetAgentRunDuration() time.Duration {
+	return 2 * time.Minute
+}
+
+func (t *CommonConfigTestRunner) GetMeasuredMetrics() []string {
+	return []string{measuredName}
+}
+
+func (t *CommonConfigTestRunner) SetupBeforeAgentRun() error {
+	var err error
+	if t.credentialDir, err = os.MkdirTemp("", "cwagent-credentials"); err != nil {
+		return err
+	}
+	commonConfig, err := t.testCase.config(t.credentialDir)
+	if err != nil {
+		return fmt.Errorf("failed to create the common config of %s: %w", t.testCase.name, err)
+	}
+	logger.Infof("Starting the agent with common config\n%s", commonConfig)
+	if err = common.WriteCommonConfig(commonConfig); err != nil {
+		return err
+	}
+	t.started = time.Now()
+	return t.BaseTestRunner.SetupBeforeAgentRun()
+}
+
+func (t *CommonConfigTestRunner) Cleanup() {
+	if err := common.RestoreCommonConfig(); err != nil {
+		logger.Errorf("%v", err)
+	}
+	if t.credentialDir != "" {
+		os.RemoveAll(t.credentialDir)
+	}
+}
+
+// validatePublished checks whether the agent published to the region since it started
+func (t *CommonConfigTestRunner) validatePublished(fetcher metric.MetricValueFetcher, region string, published bool) status.TestResult {
+	testResult := status.TestResult{
+		Name:     fmt.Sprintf("%s in %s", measuredName, region),
+		Status:   status.FAILED,
+		Expected: "no datapoints",
+	}
+	if published {
+		testResult.Expected = "datapoints"
+	}
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+	})
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	if published {
+		_, err = fetcher.FirstDatapoint(namespace, measuredName, dims, t.started)
+	} else {
+		err = fetcher.AssertNotPublished(namespace, measuredName, dims, t.started)
+	}
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// writeCredentials writes the credentials of the instance role to the profile of a shared credential file, with the
+// region when it is not empty
+func writeCredentials(dir, region string) (string, error) {
+	creds, err := awsservice.GetCredentials()
+	if err != nil {
+		return "", fmt.Errorf("failed to retrieve the credentials of the instance: %w", err)
+	}
+	content := fmt.Sprintf("[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\naws_session_token = %s\n",
+		credentialProfile, creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken)
+	if region != "" {
+		content += fmt.Sprintf("region = %s\n", region)
+	}
+	file := filepath.Join(dir, "credentials")
+	return file, os.WriteFile(file, []byte(content), 0600)
+}
+
+// overrideRegion is a region other than the one of the instance
+func overrideRegion() string {
+	if awsservice.GetImdsMetadata().Region == "us-east-1" {
+		return "us-west-2"
+	}
+	return "us-east-1"
+}
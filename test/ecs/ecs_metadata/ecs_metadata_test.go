@@ -4,10 +4,10 @@
 package ecs_metadata
 
 import (
+	"context"
 	_ "embed"
 	"flag"
 	"fmt"
-	"log"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +15,7 @@ import (
 	"github.com/qri-io/jsonschema"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 )
 
@@ -42,45 +43,34 @@ func TestValidatingCloudWatchLogs(t *testing.T) {
 
 	logGroupName := fmt.Sprintf(ECSLogGroupNameFormat, *clusterName)
 
-	var logGroupFound bool
-	for currentRetry := 1; ; currentRetry++ {
+	err := await.WaitUntil(context.Background(), 20*time.Second, RetryTime*20*time.Second, "log group "+logGroupName, func() (bool, error) {
+		return awsservice.IsLogGroupExists(logGroupName), nil
+	})
+	if err != nil {
+		t.Fatalf("Test metadata has exhausted retries: %v", err)
+	}
 
-		if currentRetry == RetryTime {
-			t.Fatalf("Test metadata has exhausted %v retry time", RetryTime)
-		}
+	end := time.Now()
 
-		if !awsservice.IsLogGroupExists(logGroupName) {
-			log.Printf("Current retry: %v/%v and begin to sleep for 20s \n", currentRetry, RetryTime)
-			time.Sleep(20 * time.Second)
-			continue
+	ok, err := awsservice.ValidateLogs(logGroupName, LogStreamName, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		if len(logs) < 1 {
+			return false
 		}
-
-		end := time.Now()
-
-		ok, err := awsservice.ValidateLogs(logGroupName, LogStreamName, &start, &end, func(logs []string) bool {
-			if len(logs) < 1 {
-				return false
-			}
-			for _, l := range logs {
-				if !awsservice.MatchEMFLogWithSchema(l, rs, func(s string) bool {
-					ok := true
-					if strings.Contains(l, "CloudWatchMetrics") {
-						ok = ok && strings.Contains(l, "\"Namespace\":\"ECS/ContainerInsights/Prometheus\"")
-					}
-					return ok && strings.Contains(l, "\"job\":\"prometheus-redis\"")
-				}) {
-					return false
+		for _, l := range logs {
+			if !awsservice.MatchEMFLogWithSchema(l, rs, func(s string) bool {
+				ok := true
+				if strings.Contains(l, "CloudWatchMetrics") {
+					ok = ok && strings.Contains(l, "\"Namespace\":\"ECS/ContainerInsights/Prometheus\"")
 				}
+				return ok && strings.Contains(l, "\"job\":\"prometheus-redis\"")
+			}) {
+				return false
 			}
-			return true
-		})
-		assert.NoError(t, err)
-		assert.True(t, ok)
-
-		break
-	}
+		}
+		return true
+	}))
+	assert.NoError(t, err)
+	assert.True(t, ok)
 
-	if logGroupFound {
-		awsservice.DeleteLogGroupAndStream(logGroupName, LogStreamName)
-	}
+	awsservice.DeleteLogGroupAndStream(logGroupName, LogStreamName)
 }
@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package ecs_sd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+// Purpose: validate the ECS service discovery of the agent finds the redis exporter task by its docker labels, writes
+// it to the sd result file and scrapes it. The agent config ships the sd result file to CloudWatch Logs, since the
+// file only exists inside the agent container.
+
+const (
+	prometheusNamespace = "ECS/ContainerInsights/Prometheus"
+	// jobName and exporterPort match the docker labels of resources/extra_apps.tpl
+	jobName      = "redis-sd"
+	exporterPort = "9121"
+	// the prometheus logs are streamed by job name, the sd result file by resources/config.json
+	emfLogGroupFormat = "/aws/ecs/containerinsights/%s/prometheus"
+	sdLogGroupFormat  = "/aws/ecs/containerinsights/%s/sd_result"
+	sdLogStream       = "sd_result"
+	logGroupTimeout   = 5 * time.Minute
+)
+
+var clusterName = flag.String("clusterName", "", "Name of the ECS cluster the agent and the redis exporter run in")
+
+func TestECSServiceDiscovery(t *testing.T) {
+	require.NotEmpty(t, *clusterName, "clusterName is required")
+	emfLogGroup := fmt.Sprintf(emfLogGroupFormat, *clusterName)
+	sdLogGroup := fmt.Sprintf(sdLogGroupFormat, *clusterName)
+	defer awsservice.DeleteLogGroup(emfLogGroup)
+	defer awsservice.DeleteLogGroup(sdLogGroup)
+
+	for _, group := range []string{sdLogGroup, emfLogGroup} {
+		group := group
+		err := await.WaitUntil(context.Background(), 20*time.Second, logGroupTimeout, "log group "+group, func() (bool, error) {
+			return awsservice.IsLogGroupExists(group), nil
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("sd result file", func(t *testing.T) {
+		now := time.Now()
+		// the file is yaml, shipped one line per event
+		ok, err := awsservice.ValidateLogs(sdLogGroup, sdLogStream, nil, &now, awsservice.StringValidator(func(lines []string) bool {
+			content := strings.Join(lines, "\n")
+			return strings.Contains(content, "targets:") &&
+				strings.Contains(content, ":"+exporterPort) &&
+				strings.Contains(content, "job: "+jobName) &&
+				strings.Contains(content, "__metrics_path__: /metrics")
+		}))
+		assert.NoError(t, err)
+		assert.True(t, ok, "the sd result file does not list the redis exporter target")
+	})
+
+	t.Run("prometheus logs", func(t *testing.T) {
+		now := time.Now()
+		ok, err := awsservice.ValidateLogs(emfLogGroup, jobName, nil, &now, awsservice.StringValidator(func(logs []string) bool {
+			if len(logs) < 1 {
+				return false
+			}
+			for _, l := range logs {
+				if !strings.Contains(l, fmt.Sprintf("\"job\":%q", jobName)) || !strings.Contains(l, "\"TaskDefinitionFamily\"") {
+					return false
+				}
+			}
+			return true
+		}))
+		assert.NoError(t, err)
+		assert.True(t, ok, "the scraped logs are missing the labels of the discovered task")
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		dims := []types.Dimension{
+			{Name: aws.String("ClusterName"), Value: clusterName},
+			{Name: aws.String("job"), Value: aws.String(jobName)},
+		}
+		fetcher := metric.MetricValueFetcher{}
+		for _, name := range []string{"redis_connected_clients", "redis_memory_used_bytes"} {
+			values, err := fetcher.Fetch(prometheusNamespace, name, dims, metric.AVERAGE, 60)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, values, "no datapoints for %s", name)
+		}
+	})
+}
@@ -0,0 +1,8 @@
+global:
+  scrape_interval: 1m
+  scrape_timeout: 10s
+scrape_configs:
+  - job_name: cwagent-ecs-file-sd-config
+    sample_limit: 10000
+    file_sd_configs:
+      - files: [ "/tmp/cwagent_ecs_auto_sd.yaml" ]
\ No newline at end of file
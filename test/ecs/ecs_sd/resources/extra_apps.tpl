@@ -0,0 +1,56 @@
+[
+  {
+    "name": "redis-0",
+    "image": "redis:6.0.8-alpine3.12",
+    "essential": true,
+    "portMappings": [
+      {
+        "protocol": "tcp",
+        "containerPort": 6379
+      }
+    ],
+    "dockerLabels": {
+      "app": "redis"
+    },
+    "logConfiguration": {
+      "logDriver": "awslogs",
+      "options": {
+        "awslogs-region": "${region}",
+        "awslogs-stream-prefix": "redis-sd",
+        "awslogs-group": "${log_group}"
+      }
+    },
+    "cpu": 128,
+    "mountPoints": [ ],
+    "memory": 512,
+    "volumesFrom": [ ]
+  },
+  {
+    "name": "redis-exporter-0",
+    "image": "oliver006/redis_exporter:v1.11.1-alpine",
+    "essential": true,
+    "portMappings": [
+      {
+        "protocol": "tcp",
+        "containerPort": 9121
+      }
+    ],
+    "dockerLabels": {
+      "ECS_PROMETHEUS_EXPORTER_PORT": "9121",
+      "ECS_PROMETHEUS_JOB_NAME": "redis-sd",
+      "ECS_PROMETHEUS_METRICS_PATH": "/metrics"
+    },
+    "logConfiguration": {
+      "logDriver": "awslogs",
+      "options": {
+        "awslogs-region": "${region}",
+        "awslogs-stream-prefix": "redis-exporter-sd",
+        "awslogs-group": "${log_group}"
+      }
+    },
+    "cpu": 128,
+    "mountPoints": [ ],
+    "memory": 512,
+    "volumesFrom": [ ]
+  }
+]
\ No newline at end of file
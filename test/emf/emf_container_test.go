@@ -17,19 +17,10 @@ type EMFTestRunner struct {
 	testName string
 }
 
-var _ test_runner.ITestRunner = (*EMFTestRunner)(nil)
+var _ test_runner.MetricRunner = (*EMFTestRunner)(nil)
 
 func (t *EMFTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateEMFMetrics(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *EMFTestRunner) GetTestName() string {
@@ -49,13 +40,13 @@ func (t *EMFTestRunner) GetMeasuredMetrics() []string {
 	return []string{"EMFCounter"}
 }
 
-func (t *EMFTestRunner) validateEMFMetrics(metricName string) status.TestResult {
+func (t *EMFTestRunner) ValidateMetric(metricName string) status.TestResult {
 	namespace := ""
 	var dims []types.Dimension
-	var failed []dimension.Instruction
+	var err error
 	if t.testName == "EMF_ECS" {
 		namespace = "EMFECSNameSpace"
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "InstanceId",
 				Value: dimension.UnknownDimensionValue(),
@@ -68,14 +59,15 @@ func (t *EMFTestRunner) validateEMFMetrics(metricName string) status.TestResult
 	}
 	if t.testName == "EMF_EKS" {
 		namespace = "EMFEKSNameSpace"
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{})
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{})
 	}
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
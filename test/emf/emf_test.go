@@ -12,6 +12,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 	"github.com/stretchr/testify/suite"
 	"log"
 	"testing"
@@ -27,7 +28,7 @@ func (suite *MetricBenchmarkTestSuite) SetupSuite() {
 }
 
 func (suite *MetricBenchmarkTestSuite) TearDownSuite() {
-	suite.Result.Print()
+	report.Send(suite.Result)
 	fmt.Println(">>>> Finished EMF Container TestSuite")
 }
 
@@ -0,0 +1,116 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package entity
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+const (
+	namespace             = "EntityTest"
+	configPath            = "resources/config.json"
+	configOutputPath      = "/opt/aws/amazon-cloudwatch-agent/bin/config.json"
+	logFilePath           = "/tmp/test_entity.log"
+	serviceName           = "cwagent-entity-test"
+	deploymentEnvironment = "cwagent-entity-test-env"
+	agentRuntime          = 2 * time.Minute
+	logLines              = 10
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestEntity validates the agent associates its logs with the service and environment of the config and its metrics
+// with the instance, as read back with the entity APIs
+func TestEntity(t *testing.T) {
+	environment.GetEnvironmentMetaData(envMetaDataStrings)
+	instanceId := awsservice.GetInstanceId()
+	logger.Infof("Found instance id %s", instanceId)
+	logGroup := instanceId + "Entity"
+	logStream := instanceId
+
+	defer awsservice.DeleteLogGroupAndStream(logGroup, logStream)
+
+	f, err := os.Create(logFilePath)
+	require.NoError(t, err)
+	defer f.Close()
+	defer os.Remove(logFilePath)
+
+	start := time.Now()
+	common.CopyFile(configPath, configOutputPath)
+	require.NoError(t, common.ScopeConfigNamespace(configOutputPath))
+	require.NoError(t, common.StartAgent(configOutputPath, true, false))
+
+	for i := 0; i < logLines; i++ {
+		_, err = f.WriteString(fmt.Sprintf("entity test line %d\n", i))
+		require.NoError(t, err)
+	}
+	time.Sleep(agentRuntime)
+	common.StopAgent()
+	end := time.Now()
+
+	awsservice.TagLogGroupForRun(logGroup)
+	ok, err := awsservice.ValidateLogs(logGroup, logStream, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		return len(logs) == logLines
+	}))
+	require.NoError(t, err)
+	require.True(t, ok, "the log lines were not delivered")
+
+	t.Run("log group", func(t *testing.T) {
+		entities, err := awsservice.ListEntitiesForLogGroup(logGroup)
+		require.NoError(t, err)
+		assertEntity(t, entities, map[string]string{
+			"Type":        "Service",
+			"Name":        serviceName,
+			"Environment": deploymentEnvironment,
+		}, instanceId)
+	})
+
+	t.Run("metric", func(t *testing.T) {
+		entities, err := awsservice.ListEntitiesForMetric(ns.Resolve(namespace), "cpu_usage_idle", []types.Dimension{
+			{Name: aws.String("InstanceId"), Value: aws.String(instanceId)},
+			{Name: aws.String("cpu"), Value: aws.String("cpu-total")},
+		})
+		require.NoError(t, err)
+		assertEntity(t, entities, map[string]string{"Type": "Service"}, instanceId)
+	})
+}
+
+// assertEntity asserts one of the entities has the key attributes and the instance id attribute of the host
+func assertEntity(t *testing.T, entities []awsservice.Entity, keyAttributes map[string]string, instanceId string) {
+	for _, e := range entities {
+		if e.Attributes["EC2.InstanceId"] != instanceId {
+			continue
+		}
+		matches := true
+		for k, v := range keyAttributes {
+			if e.KeyAttributes[k] != v {
+				matches = false
+			}
+		}
+		if matches {
+			return
+		}
+	}
+	assert.Failf(t, "entity not found", "expected key attributes %v and EC2.InstanceId %s in %+v", keyAttributes, instanceId, entities)
+}
@@ -56,7 +56,7 @@ func validateCpuMetric(metricName string, factory dimension.Factory) status.Test
 		Status: status.FAILED,
 	}
 
-	dims, failed := factory.GetDimensions([]dimension.Instruction{
+	dims, err := factory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -67,7 +67,8 @@ func validateCpuMetric(metricName string, factory dimension.Factory) status.Test
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -6,18 +6,22 @@
 package fluent
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 )
 
 const logStreamRetry = 20
 
-// fluent log group with expected log message fields
+// fluent log group with expected log message fields. Every log event must have all the fields of one of the sets,
+// nested fields are dotted, e.g. kubernetes.pod_name is the pod_name of the kubernetes metadata.
 var logGroupToKey = map[string][][]string{
 	"dataplane": {
 		{"dataplane", "host", "application"},
@@ -28,8 +32,11 @@ var logGroupToKey = map[string][][]string{
 		{"host", "ident", "message"},
 	},
 	"application": {
-		{"container_name", "namespace_name", "pod_name", "container_image", "pod_id", "host"},
-		{"log", "stream"},
+		{
+			"log", "stream",
+			"kubernetes.container_name", "kubernetes.namespace_name", "kubernetes.pod_name",
+			"kubernetes.container_image", "kubernetes.pod_id", "kubernetes.host",
+		},
 	},
 }
 
@@ -45,6 +52,7 @@ func TestFluentLogs(t *testing.T) {
 
 	now := time.Now()
 	for group, fieldsArr := range logGroupToKey {
+		logType := group
 		group := fmt.Sprintf("/aws/containerinsights/%s/%s", env.EKSClusterName, group)
 		if !awsservice.IsLogGroupExists(group) {
 			t.Fatalf("fluent log group doesn't exsit: %s", group)
@@ -55,33 +63,82 @@ func TestFluentLogs(t *testing.T) {
 			t.Fatalf("fluent log streams are empty for log group: %s", group)
 		}
 
-		ok, err := awsservice.ValidateLogs(group, *(streams[0].LogStreamName), nil, &now, func(logs []string) bool {
-			if len(logs) < 1 {
-				return false
+		// the most recently written streams are validated, every event of them must have the expected fields
+		for _, s := range streams {
+			stream := *s.LogStreamName
+			count := 0
+			var invalid error
+			err := awsservice.ForEachLogEvent(group, stream, nil, &now, func(e types.OutputLogEvent) bool {
+				count++
+				invalid = validateLogEvent(logType, stream, *e.Message, fieldsArr)
+				return invalid == nil
+			})
+			if err != nil {
+				t.Fatalf("failed to read the fluent logs of %s/%s: %v", group, stream, err)
 			}
-
-			// only 1 log message gets validate
-			// log message must include expected fields, and there could be more than 1 set of expected fields  per log group
-			var found = false
-			for _, fields := range fieldsArr {
-				match := 0
-				for _, field := range fields {
-					if strings.Contains(logs[0], "\""+field+"\"") {
-						match += 1
-					}
-				}
-				if match == len(fields) {
-					found = true
-					break
-				}
+			if invalid != nil {
+				t.Fatalf("fluent log entry of %s/%s is invalid: %v", group, stream, invalid)
+			}
+			if count < 1 {
+				t.Fatalf("no fluent log entries in %s/%s", group, stream)
 			}
-			return found
-		})
-
-		if err != nil || !ok {
-			t.Fatalf("fluent log entry doesn't include expected message fields for logGroup: %s", group)
 		}
 	}
 
 	t.Log("finishing EKS fluent log validation...")
 }
+
+// validateLogEvent checks the log message has every field of one of the sets. The kubernetes metadata of application
+// logs must also be about the container the log stream is named after.
+func validateLogEvent(logType, stream, message string, fieldsArr [][]string) error {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(message), &fields); err != nil {
+		return fmt.Errorf("log is not json: %w", err)
+	}
+
+	var found []string
+	for _, set := range fieldsArr {
+		if missing := missingFields(fields, set); len(missing) == 0 {
+			found = set
+			break
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("log %s has none of the field sets %v", message, fieldsArr)
+	}
+
+	if logType == "application" {
+		// the stream is named after the container log file, <pod>_<namespace>_<container>-<container id>.log
+		podName, _ := lookupField(fields, "kubernetes.pod_name")
+		namespace, _ := lookupField(fields, "kubernetes.namespace_name")
+		if !strings.Contains(stream, fmt.Sprintf("%v_%v_", podName, namespace)) {
+			return fmt.Errorf("kubernetes metadata of pod %v in namespace %v does not match the stream", podName, namespace)
+		}
+	}
+	return nil
+}
+
+func missingFields(fields map[string]interface{}, set []string) []string {
+	var missing []string
+	for _, field := range set {
+		if v, ok := lookupField(fields, field); !ok || v == "" {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// lookupField returns the value of the dotted field, descending into nested objects
+func lookupField(fields map[string]interface{}, field string) (interface{}, bool) {
+	var current interface{} = fields
+	for _, key := range strings.Split(field, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+// +build windows
+
+package ground_truth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// agent config json file in Temp dir gets written by terraform
+	configWindowsJSON       = "C:\\Users\\Administrator\\AppData\\Local\\Temp\\agent_config.json"
+	configWindowsOutputPath = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\config.json"
+	metricWindowsNamespace  = "GroundTruthWindowsTest"
+
+	agentWindowsRuntime = 3 * time.Minute
+	// sampleInterval matches the collection interval of the agent config
+	sampleInterval = 10 * time.Second
+	// ingestionDelay lets CloudWatch aggregate the last datapoints before they are compared
+	ingestionDelay = time.Minute
+)
+
+type hostMetric struct {
+	objectName string
+	// instance is empty for the counters of objects without instances
+	instance string
+	counter  string
+	metric.HostReading
+}
+
+func (m hostMetric) name() string {
+	return m.objectName + " " + m.counter
+}
+
+func (m hostMetric) dimensions() []types.Dimension {
+	dims := []types.Dimension{
+		{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())},
+		{Name: aws.String("objectname"), Value: aws.String(m.objectName)},
+	}
+	if m.instance != "" {
+		dims = append(dims, types.Dimension{Name: aws.String("instance"), Value: aws.String(m.instance)})
+	}
+	return dims
+}
+
+// hostMetrics are compared with the PDH counters read by the test, the memory in use and the processor time change
+// between the reads of the agent and the ones of the test
+var hostMetrics = []hostMetric{
+	{objectName: "Memory", counter: "Available Bytes", HostReading: metric.HostReading{Relative: 0.1}},
+	{objectName: "Memory", counter: "% Committed Bytes In Use", HostReading: metric.HostReading{Absolute: 5}},
+	{
+		objectName:  "Processor",
+		instance:    "_Total",
+		counter:     "% Processor Time",
+		HostReading: metric.HostReading{Key: groundtruth.DeviceKey("Processor % Processor Time", "_Total"), Absolute: 10},
+	},
+	{
+		objectName:  "LogicalDisk",
+		instance:    "C:",
+		counter:     "% Free Space",
+		HostReading: metric.HostReading{Key: groundtruth.DeviceKey("LogicalDisk % Free Space", "C:"), Absolute: 1},
+	},
+}
+
+// Validate checks the Memory, Processor and LogicalDisk metrics the agent publishes agree with the PDH counters the
+// test reads itself while the agent runs
+func Validate() error {
+	err := common.CopyFile(configWindowsJSON, configWindowsOutputPath)
+	if err != nil {
+		logger.Errorf("Copying agent config file failed: %v", err)
+		return err
+	}
+
+	err = common.StartAgent(configWindowsOutputPath, true, false)
+	if err != nil {
+		logger.Errorf("Starting agent failed: %v", err)
+		return err
+	}
+
+	host := groundtruth.Start(sampleInterval)
+	time.Sleep(agentWindowsRuntime)
+	series := host.Stop()
+	err = common.StopAgent()
+	if err != nil {
+		logger.Errorf("Stopping agent failed: %v", err)
+		return err
+	}
+	time.Sleep(ingestionDelay)
+
+	fetcher := metric.MetricValueFetcher{}
+	for _, m := range hostMetrics {
+		comparison, err := fetcher.CompareWithHost(metricWindowsNamespace, m.name(), m.dimensions(), series, m.HostReading)
+		if err != nil {
+			return fmt.Errorf("comparing %s with the host failed: %w", m.name(), err)
+		}
+		if !comparison.Agrees() {
+			return fmt.Errorf("%s is %v, want %s", m.name(), comparison.Published, comparison)
+		}
+		logger.Infof("%s is %v, %s", m.name(), comparison.Published, comparison)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package iam_permission
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
+)
+
+type IAMPermissionTestSuite struct {
+	suite.Suite
+	test_runner.TestSuite
+}
+
+func (suite *IAMPermissionTestSuite) SetupSuite() {
+	fmt.Println(">>>> Starting IAMPermissionTestSuite")
+}
+
+func (suite *IAMPermissionTestSuite) TearDownSuite() {
+	report.Send(suite.Result)
+	fmt.Println(">>>> Finished IAMPermissionTestSuite")
+}
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// getTestRunners returns a runner for each case whose role was passed, the roles are created by terraform/ec2/creds
+func getTestRunners(env *environment.MetaData) []*test_runner.TestRunner {
+	factory := dimension.GetDimensionFactory(*env)
+	var testRunners []*test_runner.TestRunner
+	for _, c := range iamPermissionCases {
+		roleArn := c.roleArn(env)
+		if roleArn == "" {
+			continue
+		}
+		testRunners = append(testRunners, &test_runner.TestRunner{TestRunner: &IAMPermissionTestRunner{
+			BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory},
+			testCase:       c,
+			roleArn:        roleArn,
+		}})
+	}
+	return testRunners
+}
+
+func (suite *IAMPermissionTestSuite) TestAllInSuite() {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	testRunners := getTestRunners(env)
+	if len(testRunners) == 0 {
+		suite.T().Skip("-minimalPolicyRoleArn and -missingPermissionRoleArn are not set")
+	}
+	for _, testRunner := range testRunners {
+		suite.AddToSuiteResult(testRunner.Run())
+	}
+	suite.Assert().Equal(status.SUCCESSFUL, suite.Result.GetStatus(), "IAM Permission Test Suite Failed")
+}
+
+func (suite *IAMPermissionTestSuite) AddToSuiteResult(r status.TestGroupResult) {
+	suite.Result.TestGroupResults = append(suite.Result.TestGroupResults, r)
+}
+
+func TestIAMPermissionTestSuite(t *testing.T) {
+	suite.Run(t, new(IAMPermissionTestSuite))
+}
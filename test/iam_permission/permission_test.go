@@ -0,0 +1,237 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package iam_permission
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	namespace    = "IAMPermissionTest"
+	measuredName = "mem_used_percent"
+
+	// Must match the file_path of agent_configs/config.json
+	logFile  = "/tmp/cwagent_iam_permission.log"
+	logLines = 50
+
+	credentialProfile = "cwagent"
+	// deniedAction is the action the permissions boundary of the missing permission role leaves out
+	deniedAction = "logs:PutLogEvents"
+)
+
+// iamPermissionCase is a role the agent runs with and what it may deliver with it
+type iamPermissionCase struct {
+	name    string
+	roleArn func(env *environment.MetaData) string
+	// logsDenied is true when the role can not put log events, the metrics are allowed in every case
+	logsDenied bool
+}
+
+var iamPermissionCases = []iamPermissionCase{
+	{
+		name:    "MinimalPolicy",
+		roleArn: func(env *environment.MetaData) string { return env.MinimalPolicyRoleArn },
+	},
+	{
+		name:       "MissingPutLogEvents",
+		roleArn:    func(env *environment.MetaData) string { return env.MissingPermissionRoleArn },
+		logsDenied: true,
+	},
+}
+
+// IAMPermissionTestRunner runs the agent with the credentials of the role of its case, through a shared credential
+// profile of the common config, and validates what it delivers and the AccessDenied it logs
+type IAMPermissionTestRunner struct {
+	test_runner.BaseTestRunner
+	testCase      iamPermissionCase
+	roleArn       string
+	credentialDir string
+	logGroup      string
+	started       time.Time
+}
+
+var _ test_runner.ITestRunner = (*IAMPermissionTestRunner)(nil)
+
+func (t *IAMPermissionTestRunner) Validate() status.TestGroupResult {
+	agentOutput := common.ReadAgentOutput(time.Since(t.started) + time.Minute)
+	return status.TestGroupResult{
+		Name: t.GetTestName(),
+		TestResults: []status.TestResult{
+			t.validateMetric(),
+			t.validateLogs(),
+			t.validateAccessDenied(agentOutput),
+		},
+	}
+}
+
+func (t *IAMPermissionTestRunner) GetTestName() string {
+	return "IAMPermission" + t.testCase.name
+}
+
+func (t *IAMPermissionTestRunner) GetAgentConfigFileName() string {
+	return "config.json"
+}
+
+func (t *IAMPermissionTestRunner) GetAgentRunDuration() time.Duration {
+	return time.Minute
+}
+
+func (t *IAMPermissionTestRunner) GetMeasuredMetrics() []string {
+	return []string{measuredName}
+}
+
+func (t *IAMPermissionTestRunner) SetupBeforeAgentRun() error {
+	var err error
+	t.logGroup = awsservice.GetInstanceId() + "IAMPermission"
+	if t.credentialDir, err = os.MkdirTemp("", "cwagent-credentials"); err != nil {
+		return err
+	}
+	file, err := writeRoleCredentials(t.credentialDir, t.roleArn)
+	if err != nil {
+		return err
+	}
+	if err = common.WriteCommonConfig(common.CommonConfig{SharedCredentialProfile: credentialProfile, SharedCredentialFile: file}); err != nil {
+		return err
+	}
+	if err = os.WriteFile(logFile, nil, 0644); err != nil {
+		return err
+	}
+	t.started = time.Now()
+	return t.BaseTestRunner.SetupBeforeAgentRun()
+}
+
+// SetupAfterAgentRun writes the lines the agent must, or must not be allowed to, deliver
+func (t *IAMPermissionTestRunner) SetupAfterAgentRun() error {
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for i := 0; i < logLines; i++ {
+		if _, err = fmt.Fprintf(f, "%s iam permission line %d\n", t.testCase.name, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *IAMPermissionTestRunner) Cleanup() {
+	if err := common.RestoreCommonConfig(); err != nil {
+		logger.Errorf("%v", err)
+	}
+	if t.credentialDir != "" {
+		os.RemoveAll(t.credentialDir)
+	}
+	if t.logGroup != "" {
+		awsservice.DeleteLogGroup(t.logGroup)
+	}
+	os.Remove(logFile)
+}
+
+// validateMetric expects the metrics are delivered with every role, CloudWatchAgentServerPolicy allows them
+func (t *IAMPermissionTestRunner) validateMetric() status.TestResult {
+	testResult := status.TestResult{Name: measuredName, Status: status.FAILED, Expected: "datapoints"}
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+	})
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+	fetcher := metric.MetricValueFetcher{}
+	if _, err = fetcher.FirstDatapoint(namespace, measuredName, dims, t.started); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateLogs expects every line is delivered, or none when the role can not put log events
+func (t *IAMPermissionTestRunner) validateLogs() status.TestResult {
+	testResult := status.TestResult{Name: "Log lines", Status: status.FAILED, Expected: fmt.Sprintf("%d lines", logLines)}
+	if t.testCase.logsDenied {
+		testResult.Expected = "no lines"
+	}
+	events, err := awsservice.GetLogEvents(t.logGroup, awsservice.GetInstanceId(), &t.started, nil)
+	if err != nil && (!t.testCase.logsDenied || errclass.Classify(err) != errclass.NotFoundYet) {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Actual = fmt.Sprintf("%d lines", len(events))
+	if t.testCase.logsDenied && len(events) > 0 {
+		testResult.SetError(errclass.Validationf("%d lines were delivered without %s", len(events), deniedAction))
+		return testResult
+	}
+	if !t.testCase.logsDenied && len(events) != logLines {
+		testResult.SetError(errclass.NotFoundYetf("%d of %d lines were delivered", len(events), logLines))
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateAccessDenied expects the agent logged the denied action, and nothing was denied with the minimal policy.
+// An unexpected AccessDenied is recorded as a permission failure, so it is not mistaken for a late delivery.
+func (t *IAMPermissionTestRunner) validateAccessDenied(agentOutput string) status.TestResult {
+	testResult := status.TestResult{Name: "AccessDenied", Status: status.FAILED, Expected: "no AccessDenied"}
+	denied := common.AgentAccessDenied(agentOutput)
+	if !t.testCase.logsDenied {
+		if denied != nil {
+			testResult.SetError(denied)
+			return testResult
+		}
+		testResult.Status = status.SUCCESSFUL
+		return testResult
+	}
+
+	testResult.Expected = "AccessDenied for " + deniedAction
+	if denied == nil {
+		testResult.SetError(errclass.Validationf("the agent did not log the AccessDenied for %s", deniedAction))
+		return testResult
+	}
+	testResult.Actual = denied.Error()
+	if !strings.Contains(denied.Error(), deniedAction) {
+		testResult.SetError(errclass.Validationf("the agent logged an AccessDenied for another action than %s: %v", deniedAction, denied))
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// writeRoleCredentials assumes the role with the credentials of the instance and writes its temporary credentials
+// to the profile of a shared credential file
+func writeRoleCredentials(dir, roleArn string) (string, error) {
+	out, err := exec.Command("aws", "sts", "assume-role", "--role-arn", roleArn, "--role-session-name", "cwagent-iam-permission",
+		"--query", "Credentials.[AccessKeyId,SecretAccessKey,SessionToken]", "--output", "text").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to assume %s: %w", roleArn, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) != 3 {
+		return "", fmt.Errorf("unexpected credentials of %s: %d fields", roleArn, len(fields))
+	}
+	content := fmt.Sprintf("[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\naws_session_token = %s\n",
+		credentialProfile, fields[0], fields[1], fields[2])
+	file := filepath.Join(dir, "credentials")
+	return file, os.WriteFile(file, []byte(content), 0600)
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package iis
+
+import (
+	"errors"
+)
+
+// Validate fails since IIS and its performance counters only exist on Windows. It is defined so the validator, which
+// dispatches to this package, builds on every platform.
+func Validate() error {
+	return errors.New("the IIS test only runs on Windows")
+}
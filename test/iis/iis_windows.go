@@ -0,0 +1,119 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+// +build windows
+
+package iis
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// agent config json file in Temp dir gets written by terraform
+	configWindowsJSON        = "C:\\Users\\Administrator\\AppData\\Local\\Temp\\agent_config.json"
+	configWindowsOutputPath  = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\config.json"
+	metricWindowsNamespace   = "IISWindowsTest"
+	agentWindowsRuntime      = 3 * time.Minute
+	numberOfAppendDimensions = 1
+
+	testPagePath = "C:\\inetpub\\wwwroot\\cwagent-test.aspx"
+	testPageUrl  = "http://localhost/cwagent-test.aspx"
+	// testPage runs managed code and throws an exception on every request, so the .NET CLR counters have values
+	testPage = `<%@ Page Language="C#" %>
+<% try { throw new System.InvalidOperationException("cwagent test"); } catch (System.InvalidOperationException) { } %>
+<html><body>CloudWatch agent IIS test</body></html>
+`
+	trafficInterval = 100 * time.Millisecond
+)
+
+var expectedIISMetrics = []string{
+	"Web Service Current Connections",
+	"Web Service Total Method Requests/sec",
+	"Web Service Get Requests/sec",
+	"Web Service Bytes Sent/sec",
+	"ASP.NET Applications Requests/Sec",
+	".NET CLR Memory # Bytes in all Heaps",
+	".NET CLR Memory # Gen 0 Collections",
+	".NET CLR Exceptions # of Exceps Thrown / sec",
+}
+
+// Validate installs IIS with ASP.NET, serves a page running managed code while the agent runs and validates the IIS
+// and .NET CLR performance counters the agent collected
+func Validate() error {
+	err := common.RunCommands([]string{
+		"Install-WindowsFeature -Name Web-Server,Web-Asp-Net45 -IncludeManagementTools",
+		"Start-Service W3SVC",
+	})
+	if err != nil {
+		logger.Errorf("Installing IIS failed: %v", err)
+		return err
+	}
+	if err = os.WriteFile(testPagePath, []byte(testPage), 0644); err != nil {
+		logger.Errorf("Writing the test page failed: %v", err)
+		return err
+	}
+
+	err = common.CopyFile(configWindowsJSON, configWindowsOutputPath)
+	if err != nil {
+		logger.Errorf("Copying agent config file failed: %v", err)
+		return err
+	}
+
+	err = common.StartAgent(configWindowsOutputPath, true, false)
+	if err != nil {
+		logger.Errorf("Starting agent failed: %v", err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentWindowsRuntime)
+	defer cancel()
+	sendTraffic(ctx)
+	logger.Infof("Agent has been running for : %s", agentWindowsRuntime.String())
+
+	err = common.StopAgent()
+	if err != nil {
+		logger.Errorf("Stopping agent failed: %v", err)
+		return err
+	}
+
+	dimensionFilter := awsservice.BuildDimensionFilterList(numberOfAppendDimensions)
+	for _, metricName := range expectedIISMetrics {
+		err = awsservice.ValidateMetric(metricName, metricWindowsNamespace, dimensionFilter)
+		if err != nil {
+			logger.Errorf("IIS metric %s was not found: %v", metricName, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// sendTraffic requests the test page until the context is done. Failed requests are only logged, since a missing
+// counter is reported by the validation anyway.
+func sendTraffic(ctx context.Context) {
+	ticker := time.NewTicker(trafficInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		resp, err := http.Get(testPageUrl)
+		if err != nil {
+			logger.Warnf("Request to %s failed: %v", testPageUrl, err)
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package ipv6
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	namespace            = "IPv6OnlyTest"
+	agentRuntime         = 3 * time.Minute
+	agentConfigLocalPath = "agent_configs/config.json"
+	agentConfigPath      = "/opt/aws/amazon-cloudwatch-agent/bin/config.json"
+	logFilePath          = "/tmp/ipv6_only.log"
+	logStreamName        = "ipv6_only"
+	logLine              = "ipv6 only log line"
+	logLines             = 10
+)
+
+// nat64Prefix is the well-known prefix DNS64 synthesizes addresses in for hosts without an AAAA record. An endpoint
+// resolving to it would be reached through the NAT64 of the VPC, over IPv4, rather than natively.
+var _, nat64Prefix, _ = net.ParseCIDR("64:ff9b::/96")
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestIPv6Only checks the host has no IPv4 route and the agent delivers metrics and logs to the dual-stack endpoints.
+// The framework clients validating the delivery use the dual-stack endpoints too, see -ipv6Only.
+func TestIPv6Only(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	if !env.IPv6Only {
+		t.Skip("the host is not in an IPv6-only subnet, set -ipv6Only")
+	}
+
+	if err := checkNoIPv4DefaultRoute(); err != nil {
+		t.Fatal(err)
+	}
+	region := os.Getenv("AWS_REGION")
+	for _, service := range []string{"monitoring", "logs"} {
+		host := fmt.Sprintf("%s.%s.api.aws", service, region)
+		if err := checkNativeIPv6(host); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	instanceId := awsservice.GetInstanceId()
+	defer awsservice.DeleteLogGroupAndStream(instanceId, logStreamName)
+
+	common.CopyFile(agentConfigLocalPath, agentConfigPath)
+	start := time.Now()
+	if err := common.StartAgent(agentConfigPath, false, false); err != nil {
+		t.Fatalf("agent failed to start: %v", err)
+	}
+	if err := writeLogLines(); err != nil {
+		t.Fatalf("failed to write %s: %v", logFilePath, err)
+	}
+	time.Sleep(agentRuntime)
+	common.StopAgent()
+	end := time.Now()
+
+	factory := dimension.GetDimensionFactory(*env)
+	dims, err := factory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+		{Key: "cpu", Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := metric.MetricValueFetcher{}
+	for _, name := range []string{"cpu_usage_active", "cpu_usage_idle", "cpu_usage_system", "cpu_usage_user"} {
+		values, err := fetcher.Fetch(namespace, name, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+		if err != nil {
+			t.Fatalf("failed to fetch %s: %v", name, err)
+		}
+		if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, 0) {
+			t.Fatalf("%s was not delivered over IPv6", name)
+		}
+	}
+
+	ok, err := awsservice.ValidateLogs(instanceId, logStreamName, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		count := 0
+		for _, l := range logs {
+			if strings.Contains(l, logLine) {
+				count++
+			}
+		}
+		logger.Infof("found %d of the %d log lines", count, logLines)
+		return count == logLines
+	}))
+	if err != nil {
+		t.Fatalf("failed to get the logs: %v", err)
+	}
+	if !ok {
+		t.Fatal("the log lines were not delivered over IPv6")
+	}
+}
+
+// checkNoIPv4DefaultRoute fails if the kernel has an IPv4 default route, so nothing the agent sends can leave over IPv4
+func checkNoIPv4DefaultRoute() error {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 1 && fields[1] == "00000000" {
+			return fmt.Errorf("the host has an IPv4 default route on %s", fields[0])
+		}
+	}
+	return scanner.Err()
+}
+
+// checkNativeIPv6 fails unless the host resolves to an IPv6 address outside of the NAT64 prefix
+func checkNativeIPv6(host string) error {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if ip.To4() == nil && !nat64Prefix.Contains(ip) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no native IPv6 address, got %v", host, ips)
+}
+
+func writeLogLines() error {
+	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for i := 0; i < logLines; i++ {
+		if _, err = fmt.Fprintf(f, "%s %d\n", logLine, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package large_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/leak"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/readiness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	// fileCount and processCount make the large config, every file is an entry of the collect_list and every process
+	// an entry of procstat, which the agent runs as a plugin of its own
+	fileCount    = 300
+	processCount = 24
+	linesPerFile = 5
+	// readyTimeout is how long the agent may take to start with the large config before the suite gives up
+	readyTimeout = 5 * time.Minute
+	// startupAllowance is how much longer than the baseline the agent may take to start with the large config
+	startupAllowance = 30 * time.Second
+	// rssPerEntry is how much more memory than the baseline the agent may use for every file and process it collects
+	rssPerEntry  = 256 * 1024
+	agentRuntime = 2 * time.Minute
+	// deliveryTimeout lets CloudWatch make the last of the lines and datapoints available
+	deliveryTimeout = 5 * time.Minute
+	pollInterval    = 15 * time.Second
+)
+
+// plugins are the metric plugins of the config besides procstat, with a metric each publishes
+var plugins = map[string]string{
+	"cpu":       "cpu_usage_active",
+	"disk":      "disk_used_percent",
+	"diskio":    "diskio_io_time",
+	"mem":       "mem_used_percent",
+	"net":       "net_bytes_sent",
+	"netstat":   "netstat_tcp_established",
+	"processes": "processes_running",
+	"swap":      "swap_used_percent",
+}
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// footprint is how long the agent took to start and the most memory it used while it ran
+type footprint struct {
+	startup time.Duration
+	peakRSS uint64
+}
+
+// TestLargeConfig starts the agent with a small config, then with hundreds of files and dozens of plugins, and
+// validates the large config starts and runs within the baseline plus an allowance per entry, so handling the config
+// does not grow faster than linearly with its size, and that every file and plugin delivers.
+func TestLargeConfig(t *testing.T) {
+	environment.GetEnvironmentMetaData(envMetaDataStrings)
+	dir := t.TempDir()
+	logGroup := runid.Name("large-config")
+	ns := namespace.Scope("LargeConfigTest")
+	defer awsservice.DeleteLogGroup(logGroup)
+
+	files := make([]string, fileCount)
+	for i := range files {
+		files[i] = filepath.Join(dir, fmt.Sprintf("large-config-%03d.log", i))
+		require.NoError(t, os.WriteFile(files[i], nil, 0644))
+	}
+	patterns, stop, err := startProcesses(processCount)
+	defer stop()
+	require.NoError(t, err)
+
+	baseline, err := run(dir, files[:1], patterns[:1], logGroup, ns+"/Baseline", nil)
+	require.NoError(t, err)
+	logger.Infof("Baseline with 1 file and 1 process started in %s, peak rss %d B", baseline.startup, baseline.peakRSS)
+
+	large, err := run(dir, files, patterns, logGroup, ns, func() {
+		for _, file := range files {
+			if err := appendLines(file, linesPerFile); err != nil {
+				logger.Errorf("Failed to write %s: %v", file, err)
+			}
+		}
+	})
+	require.NoError(t, err)
+	logger.Infof("%d files and %d processes started in %s, peak rss %d B", fileCount, processCount, large.startup, large.peakRSS)
+
+	assert.LessOrEqual(t, large.startup, baseline.startup+startupAllowance, "the agent started %s slower than the baseline", large.startup-baseline.startup)
+	rssLimit := baseline.peakRSS + uint64(fileCount+processCount)*rssPerEntry
+	assert.LessOrEqual(t, large.peakRSS, rssLimit, "the agent used %d B, more than the %d B of the baseline and the allowance of its entries", large.peakRSS, baseline.peakRSS)
+
+	assert.NoError(t, validateLogs(logGroup, files))
+	assert.NoError(t, validateMetrics(ns, patterns))
+}
+
+// run starts the agent with the config of the files and processes, times its startup and samples its memory while
+// it runs, write runs once the agent is ready
+func run(dir string, files, patterns []string, logGroup, ns string, write func()) (footprint, error) {
+	var f footprint
+	path := filepath.Join(dir, "config.json")
+	if err := writeAgentConfig(path, files, patterns, logGroup, ns); err != nil {
+		return f, err
+	}
+	common.CopyFile(path, common.ConfigOutputPath)
+
+	probe := readiness.Begin()
+	start := time.Now()
+	if err := common.StartAgent(common.ConfigOutputPath, false, false); err != nil {
+		return f, err
+	}
+	defer common.StopAgent()
+	if err := probe.WaitFor(readyTimeout); err != nil {
+		return f, err
+	}
+	f.startup = time.Since(start)
+
+	monitor := leak.StartAgentMonitorEvery(5 * time.Second)
+	if write != nil {
+		write()
+	}
+	time.Sleep(agentRuntime)
+	f.peakRSS = monitor.Stop().PeakRSS()
+	if f.peakRSS == 0 {
+		return f, fmt.Errorf("the memory of the agent could not be sampled")
+	}
+	return f, nil
+}
+
+// validateLogs checks the lines of every file were delivered to its own stream
+func validateLogs(logGroup string, files []string) error {
+	pending := map[string]bool{}
+	for _, file := range files {
+		pending[streamName(file)] = true
+	}
+	deadline := time.Now().Add(deliveryTimeout)
+	for {
+		for stream := range pending {
+			events, err := awsservice.GetLogEvents(logGroup, stream, nil, nil)
+			if err == nil && len(events) >= linesPerFile {
+				delete(pending, stream)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of the %d files were not delivered, ex %s", len(pending), len(files), anyKey(pending))
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// validateMetrics checks every plugin published and every process has its own procstat metrics
+func validateMetrics(ns string, patterns []string) error {
+	deadline := time.Now().Add(deliveryTimeout)
+	for {
+		missing, err := missingMetrics(ns, patterns)
+		if err == nil && len(missing) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%d of the plugins and processes published nothing: %s", len(missing), strings.Join(missing, ", "))
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func missingMetrics(ns string, patterns []string) ([]string, error) {
+	var missing []string
+	for plugin, metricName := range plugins {
+		metrics, err := awsservice.ListMetrics(&cloudwatch.ListMetricsInput{Namespace: aws.String(ns), MetricName: aws.String(metricName)})
+		if err != nil {
+			return nil, err
+		}
+		if len(metrics) == 0 {
+			missing = append(missing, plugin)
+		}
+	}
+	metrics, err := awsservice.ListMetrics(&cloudwatch.ListMetricsInput{Namespace: aws.String(ns), MetricName: aws.String("procstat_memory_rss")})
+	if err != nil {
+		return nil, err
+	}
+	published := map[string]bool{}
+	for _, m := range metrics {
+		for _, d := range m.Dimensions {
+			if aws.ToString(d.Name) == "pattern" {
+				published[aws.ToString(d.Value)] = true
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		if !published[pattern] {
+			missing = append(missing, "procstat "+pattern)
+		}
+	}
+	return missing, nil
+}
+
+// startProcesses starts the processes procstat monitors, each matched by a pattern of its own
+func startProcesses(n int) ([]string, func(), error) {
+	var patterns []string
+	var cmds []*exec.Cmd
+	stop := func() {
+		for _, cmd := range cmds {
+			cmd.Process.Kill()
+			cmd.Wait()
+		}
+	}
+	for i := 0; i < n; i++ {
+		// the fraction makes the command line of every process unique
+		duration := fmt.Sprintf("3600.%03d", i)
+		cmd := exec.Command("sleep", duration)
+		if err := cmd.Start(); err != nil {
+			return nil, stop, err
+		}
+		cmds = append(cmds, cmd)
+		patterns = append(patterns, "sleep "+strings.ReplaceAll(duration, ".", "\\."))
+	}
+	return patterns, stop, nil
+}
+
+func appendLines(path string, n int) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for i := 0; i < n; i++ {
+		if _, err = fmt.Fprintf(f, "%s %s line %d\n", time.Now().Format(time.RFC3339Nano), filepath.Base(path), i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func streamName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), ".log")
+}
+
+func anyKey(m map[string]bool) string {
+	for k := range m {
+		return k
+	}
+	return ""
+}
+
+// writeAgentConfig writes a config with an entry of the collect_list for every file, an entry of procstat for every
+// pattern and every other plugin of the agent
+func writeAgentConfig(path string, files, patterns []string, logGroup, ns string) error {
+	var collectList []map[string]interface{}
+	for _, file := range files {
+		collectList = append(collectList, map[string]interface{}{
+			"file_path":       file,
+			"log_group_name":  logGroup,
+			"log_stream_name": streamName(file),
+			"timezone":        "UTC",
+		})
+	}
+	var procstat []map[string]interface{}
+	for _, pattern := range patterns {
+		procstat = append(procstat, map[string]interface{}{
+			"pattern":     pattern,
+			"measurement": []string{"memory_rss"},
+		})
+	}
+	metricsCollected := map[string]interface{}{
+		"cpu":       map[string]interface{}{"measurement": []string{"usage_active"}, "totalcpu": true},
+		"disk":      map[string]interface{}{"measurement": []string{"used_percent"}, "resources": []string{"/"}},
+		"diskio":    map[string]interface{}{"measurement": []string{"io_time"}},
+		"mem":       map[string]interface{}{"measurement": []string{"used_percent"}},
+		"net":       map[string]interface{}{"measurement": []string{"bytes_sent"}},
+		"netstat":   map[string]interface{}{"measurement": []string{"tcp_established"}},
+		"processes": map[string]interface{}{"measurement": []string{"running"}},
+		"swap":      map[string]interface{}{"measurement": []string{"used_percent"}},
+		"procstat":  procstat,
+	}
+	config := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"metrics_collection_interval": 60,
+			"run_as_user":                 "root",
+			// the readiness probe waits for the debug line of the first batch written
+			"debug": true,
+		},
+		"metrics": map[string]interface{}{
+			"namespace":            ns,
+			"force_flush_interval": 15,
+			"metrics_collected":    metricsCollected,
+		},
+		"logs": map[string]interface{}{
+			"force_flush_interval": 5,
+			"logs_collected": map[string]interface{}{
+				"files": map[string]interface{}{"collect_list": collectList},
+			},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
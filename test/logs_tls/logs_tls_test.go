@@ -0,0 +1,158 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package logs_tls
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	logLines = 100
+	// agentRuntime is long enough for a few flushes, the default flush interval is 5 seconds
+	agentRuntime = 30 * time.Second
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+type tlsCase struct {
+	name string
+	// trusted puts the CA of the proxy in the ca_bundle_path of the common config
+	trusted bool
+}
+
+var tlsCases = []tlsCase{
+	{name: "TrustedCABundle", trusted: true},
+	{name: "UntrustedProxy", trusted: false},
+}
+
+// TestLogsEndpointTLS points the CloudWatch Logs endpoint of the agent at an intercepting proxy. The agent delivers
+// through it when the CA bundle of the common config trusts the proxy, and fails the handshake without reaching the
+// endpoint otherwise, which must be reported as a TLS failure rather than a delivery that is late.
+func TestLogsEndpointTLS(t *testing.T) {
+	environment.GetEnvironmentMetaData(envMetaDataStrings)
+	dir := t.TempDir()
+	target, err := url.Parse(fmt.Sprintf("https://logs.%s.amazonaws.com", awsservice.GetImdsMetadata().Region))
+	require.NoError(t, err)
+
+	logGroup := runid.Name("logs-tls")
+	defer awsservice.DeleteLogGroup(logGroup)
+	defer func() { assert.NoError(t, common.RestoreCommonConfig()) }()
+
+	for _, c := range tlsCases {
+		t.Run(c.name, func(t *testing.T) {
+			proxy, err := startInterceptingProxy(target, dir)
+			require.NoError(t, err)
+			defer proxy.Close()
+
+			commonConfig := common.CommonConfig{}
+			if c.trusted {
+				commonConfig.CABundlePath = proxy.CAPath
+			}
+			require.NoError(t, common.WriteCommonConfig(commonConfig))
+			logFile := filepath.Join(dir, c.name+".log")
+			require.NoError(t, writeAgentConfig(dir, proxy.URL(), logFile, logGroup, c.name))
+
+			start := time.Now()
+			common.StartAgent(common.ConfigOutputPath, true, false)
+			time.Sleep(agentRuntime / 2)
+			require.NoError(t, writeLogLines(logFile, logLines))
+			time.Sleep(agentRuntime)
+			common.StopAgent()
+			end := time.Now()
+
+			delivered, _ := awsservice.ValidateLogs(logGroup, c.name, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+				return len(logs) == logLines
+			}))
+			err = deliveryFailure(common.ReadAgentOutput(end.Sub(start)+time.Minute), delivered)
+			if c.trusted {
+				assert.NoError(t, err)
+				assert.Positive(t, proxy.Forwarded(), "the agent never passed the handshake with the proxy")
+				return
+			}
+			assert.Equal(t, errclass.TLS, errclass.Classify(err), "want a TLS failure, got %v", err)
+			assert.Zero(t, proxy.Forwarded(), "the agent sent requests through a proxy it must not trust")
+			assert.False(t, delivered)
+		})
+	}
+}
+
+// deliveryFailure is the TLS failure the agent logged, otherwise a NotFoundYet error when the logs were not delivered
+func deliveryFailure(agentOutput string, delivered bool) error {
+	if err := common.AgentTLSFailure(agentOutput); err != nil {
+		return err
+	}
+	if !delivered {
+		return errclass.NotFoundYetf("the agent logged no TLS failure but the %d log lines were not delivered", logLines)
+	}
+	return nil
+}
+
+// writeAgentConfig installs a config collecting the log file through the endpoint, with agent logs in the journal
+func writeAgentConfig(dir, endpoint, logFile, logGroup, logStream string) error {
+	config := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"run_as_user": "root",
+			"debug":       true,
+			"logfile":     "",
+		},
+		"logs": map[string]interface{}{
+			"endpoint_override": endpoint,
+			"logs_collected": map[string]interface{}{
+				"files": map[string]interface{}{
+					"collect_list": []map[string]interface{}{{
+						"file_path":       logFile,
+						"log_group_name":  logGroup,
+						"log_stream_name": logStream,
+						"timezone":        "UTC",
+					}},
+				},
+			},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.json")
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	common.CopyFile(path, common.ConfigOutputPath)
+	return nil
+}
+
+func writeLogLines(path string, n int) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	for i := 0; i < n; i++ {
+		if _, err = fmt.Fprintf(f, "%s logs tls line %d\n", time.Now().Format(time.RFC3339Nano), i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
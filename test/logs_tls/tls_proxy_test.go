@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package logs_tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// interceptingProxy terminates TLS with a certificate of its own CA and forwards the requests to the real endpoint,
+// like a corporate proxy inspecting the traffic. The agent only reaches the endpoint when it trusts the CA.
+type interceptingProxy struct {
+	server *httptest.Server
+	// CAPath is the PEM file of the CA which signed the certificate of the proxy
+	CAPath    string
+	forwarded int64
+}
+
+// startInterceptingProxy listens on 127.0.0.1 and forwards to the target, the CA is written to the directory
+func startInterceptingProxy(target *url.URL, dir string) (*interceptingProxy, error) {
+	caCert, caKey, caPEM, err := newCA()
+	if err != nil {
+		return nil, err
+	}
+	serverCert, err := newServerCertificate(caCert, caKey)
+	if err != nil {
+		return nil, err
+	}
+	p := &interceptingProxy{CAPath: filepath.Join(dir, "intercepting-ca.pem")}
+	if err = os.WriteFile(p.CAPath, caPEM, 0644); err != nil {
+		return nil, err
+	}
+
+	// the Host header the agent signed is kept, only the connection goes to the target
+	forward := httputil.NewSingleHostReverseProxy(target)
+	p.server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&p.forwarded, 1)
+		forward.ServeHTTP(w, r)
+	}))
+	p.server.TLS = &tls.Config{Certificates: []tls.Certificate{serverCert}}
+	p.server.StartTLS()
+	return p, nil
+}
+
+// URL is the endpoint override pointing the agent at the proxy
+func (p *interceptingProxy) URL() string {
+	return p.server.URL
+}
+
+// Forwarded is how many requests passed the TLS handshake and were forwarded
+func (p *interceptingProxy) Forwarded() int64 {
+	return atomic.LoadInt64(&p.forwarded)
+}
+
+func (p *interceptingProxy) Close() {
+	p.server.Close()
+}
+
+func newCA() (*x509.Certificate, *ecdsa.PrivateKey, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "cwagent-integ-test intercepting CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+func newServerCertificate(ca *x509.Certificate, caKey *ecdsa.PrivateKey) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
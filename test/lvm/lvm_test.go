@@ -29,32 +29,24 @@ type LVMTestRunner struct {
 }
 
 func (t LVMTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateDiskMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(&t)
 }
 
-func (t *LVMTestRunner) validateDiskMetric(metricName string) status.TestResult {
+func (t *LVMTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -104,7 +96,7 @@ func (t *LVMTestRunner) SetupBeforeAgentRun() error {
 	return t.SetUpConfig()
 }
 
-var _ test_runner.ITestRunner = (*LVMTestRunner)(nil)
+var _ test_runner.MetricRunner = (*LVMTestRunner)(nil)
 
 func TestLVM(t *testing.T) {
 	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
@@ -0,0 +1,25 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package dimension
+
+// AppendDimensionInstructions returns the instructions for the EC2 dimensions the agent adds for append_dimensions,
+// followed by the extra instructions. ImageId, InstanceId and InstanceType are resolved by the local providers from
+// IMDS; pass the result through GetDimensions to get the full expected dimension set of the metric.
+func AppendDimensionInstructions(extra ...Instruction) []Instruction {
+	instructions := []Instruction{
+		{
+			Key:   "ImageId",
+			Value: UnknownDimensionValue(),
+		},
+		{
+			Key:   "InstanceId",
+			Value: UnknownDimensionValue(),
+		},
+		{
+			Key:   "InstanceType",
+			Value: UnknownDimensionValue(),
+		},
+	}
+	return append(instructions, extra...)
+}
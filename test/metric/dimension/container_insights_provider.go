@@ -1,16 +1,14 @@
 // Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
 // SPDX-License-Identifier: MIT
 
-//go:build !windows
-
 package dimension
 
 import (
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
-	"log"
 )
 
 type ContainerInsightsDimensionProvider struct {
@@ -35,7 +33,7 @@ func (p *ContainerInsightsDimensionProvider) GetDimension(instruction Instructio
 		//TODO currently assuming there's only one container
 		containerInstances, err := awsservice.GetContainerInstances(p.Provider.env.EcsClusterArn)
 		if err != nil {
-			log.Print(err)
+			logger.Warnf("%v", err)
 			return types.Dimension{}
 		}
 
@@ -0,0 +1,89 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package dimension
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// ebsVolumes is the device to VolumeId mapping of the instance. It is looked up once per run, since the volumes of
+// the test instance do not change while the tests run.
+var ebsVolumes struct {
+	once       sync.Once
+	rootDevice string
+	volumeIds  map[string]string
+	err        error
+}
+
+func loadEBSVolumes() error {
+	ebsVolumes.once.Do(func() {
+		instanceId := awsservice.GetInstanceId()
+		ebsVolumes.rootDevice, ebsVolumes.err = awsservice.GetRootDeviceName(instanceId)
+		if ebsVolumes.err != nil {
+			return
+		}
+		ebsVolumes.volumeIds, ebsVolumes.err = awsservice.GetVolumeIdsByDevice(instanceId)
+	})
+	return ebsVolumes.err
+}
+
+// VolumeIdForDevice returns the VolumeId of the EBS volume attached as the device, e.g. xvdf or /dev/nvme1n1, as a
+// known value for VolumeId instructions of volumes other than the root volume
+func VolumeIdForDevice(device string) (ExpectedDimensionValue, error) {
+	if err := loadEBSVolumes(); err != nil {
+		return UnknownDimensionValue(), err
+	}
+	volumeId, ok := ebsVolumes.volumeIds[strings.TrimPrefix(device, "/dev/")]
+	if !ok {
+		return UnknownDimensionValue(), fmt.Errorf("no EBS volume attached as %s", device)
+	}
+	return ExpectedDimensionValue{Value: aws.String(volumeId)}, nil
+}
+
+// EBSVolumeIdDimensionProvider resolves an unknown VolumeId to the EBS volume attached as the root device, which is
+// the volume the disk and diskio tests write to. Instructions for other volumes pass the value of VolumeIdForDevice.
+type EBSVolumeIdDimensionProvider struct {
+	Provider
+}
+
+var _ IProvider = (*EBSVolumeIdDimensionProvider)(nil)
+
+func (p *EBSVolumeIdDimensionProvider) IsApplicable() bool {
+	return p.env.ComputeType == computetype.EC2
+}
+
+func (p *EBSVolumeIdDimensionProvider) GetDimension(instruction Instruction) types.Dimension {
+	if instruction.Key != "VolumeId" || instruction.Value.IsKnown() {
+		return types.Dimension{}
+	}
+
+	if err := loadEBSVolumes(); err != nil {
+		logger.Warnf("%v", err)
+		return types.Dimension{}
+	}
+	volumeId, ok := ebsVolumes.volumeIds[ebsVolumes.rootDevice]
+	if !ok {
+		logger.Warnf("No EBS volume attached as the root device %s", ebsVolumes.rootDevice)
+		return types.Dimension{}
+	}
+
+	return types.Dimension{
+		Name:  aws.String("VolumeId"),
+		Value: aws.String(volumeId),
+	}
+}
+
+func (p *EBSVolumeIdDimensionProvider) Name() string {
+	return "EBSVolumeIdDimensionProvider"
+}
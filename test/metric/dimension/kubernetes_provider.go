@@ -0,0 +1,88 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package dimension
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/containerostype"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/k8s"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// kubernetesNode is the first ready node of the cluster running the container OS of the test, which the per node
+// metrics are validated for. It is looked up once per run with the kubeconfig the validator runs with.
+var kubernetesNode struct {
+	once       sync.Once
+	name       string
+	instanceId string
+	err        error
+}
+
+func loadKubernetesNode(containerOs containerostype.ContainerOsType) error {
+	kubernetesNode.once.Do(func() {
+		client, err := k8s.Default()
+		if err != nil {
+			kubernetesNode.err = err
+			return
+		}
+		nodes, err := client.ListNodes(context.Background())
+		if err != nil {
+			kubernetesNode.err = err
+			return
+		}
+		for _, node := range nodes {
+			if !strings.EqualFold(node.Labels[corev1.LabelOSStable], string(containerOs)) || !k8s.IsNodeReady(node) {
+				continue
+			}
+			kubernetesNode.name = node.Name
+			kubernetesNode.instanceId = k8s.NodeInstanceId(node)
+			return
+		}
+		kubernetesNode.err = fmt.Errorf("no ready kubernetes node running %s", containerOs)
+	})
+	return kubernetesNode.err
+}
+
+// KubernetesNodeDimensionProvider resolves the NodeName and InstanceId of the node level metrics, e.g. the node
+// conditions of kube-state, from the Kubernetes API
+type KubernetesNodeDimensionProvider struct {
+	Provider
+}
+
+var _ IProvider = (*KubernetesNodeDimensionProvider)(nil)
+
+func (p *KubernetesNodeDimensionProvider) IsApplicable() bool {
+	return p.env.ComputeType == computetype.EKS
+}
+
+func (p *KubernetesNodeDimensionProvider) GetDimension(instruction Instruction) types.Dimension {
+	if (instruction.Key != "NodeName" && instruction.Key != "InstanceId") || instruction.Value.IsKnown() {
+		return types.Dimension{}
+	}
+	if err := loadKubernetesNode(p.env.ContainerOs); err != nil {
+		logger.Warnf("failed to get the kubernetes nodes: %v", err)
+		return types.Dimension{}
+	}
+	value := kubernetesNode.name
+	if instruction.Key == "InstanceId" {
+		value = kubernetesNode.instanceId
+	}
+	return types.Dimension{
+		Name:  aws.String(instruction.Key),
+		Value: aws.String(value),
+	}
+}
+
+func (p *KubernetesNodeDimensionProvider) Name() string {
+	return "KubernetesNodeDimensionProvider"
+}
@@ -1,14 +1,14 @@
 // Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
 // SPDX-License-Identifier: MIT
 
-//go:build !windows
-
 package dimension
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 )
 
@@ -28,6 +28,7 @@ func GetDimensionFactory(env environment.MetaData) Factory {
 	allDimensionProviders := []IProvider{
 		&EMFECSDimensionProvider{Provider: Provider{env: env}},
 		&EKSClusterNameProvider{Provider: Provider{env: env}},
+		&KubernetesNodeDimensionProvider{Provider: Provider{env: env}},
 		&ContainerInsightsDimensionProvider{Provider: Provider{env: env}},
 		&HostDimensionProvider{Provider: Provider{env: env}},
 		&LocalInstanceIdDimensionProvider{Provider: Provider{env: env}},
@@ -36,6 +37,7 @@ func GetDimensionFactory(env environment.MetaData) Factory {
 		&ECSInstanceIdDimensionProvider{Provider: Provider{env: env}},
 		&CustomDimensionProvider{Provider: Provider{env: env}},
 	}
+	allDimensionProviders = append(allDimensionProviders, platformDimensionProviders(env)...)
 
 	applicableDimensionProviders := []IProvider{}
 
@@ -57,29 +59,46 @@ type Factory struct {
 	Providers []IProvider
 }
 
-func (f *Factory) GetDimensions(instructions []Instruction) ([]types.Dimension, []Instruction) {
+// UnfulfilledDimensionsError lists the instructions none of the applicable providers could resolve
+type UnfulfilledDimensionsError struct {
+	Keys      []string
+	Providers []string
+}
+
+func (e *UnfulfilledDimensionsError) Error() string {
+	return fmt.Sprintf("failed to resolve dimensions %v, providers consulted: %v", e.Keys, e.Providers)
+}
+
+// GetDimensions resolves every instruction and returns an *UnfulfilledDimensionsError if any of them could not be
+// resolved
+func (f *Factory) GetDimensions(instructions []Instruction) ([]types.Dimension, error) {
 	resultDimensions := []types.Dimension{}
-	unfulfilledInstructions := []Instruction{}
+	var unfulfilledKeys []string
 	for _, instruction := range instructions {
 		dim := f.executeInstruction(instruction)
 		if (dim != types.Dimension{}) {
 			resultDimensions = append(resultDimensions, dim)
-			log.Printf("Result dim is : %s, %s", *dim.Name, *dim.Value)
+			logger.Debugf("Result dim is : %s, %s", aws.ToString(dim.Name), aws.ToString(dim.Value))
 		} else {
-			unfulfilledInstructions = append(unfulfilledInstructions, instruction)
-			if dim.Name != nil && dim.Value != nil {
-				log.Printf("unfulfilled dim is : %s, %s", *dim.Name, *dim.Value)
-			}
+			unfulfilledKeys = append(unfulfilledKeys, instruction.Key)
+			logger.Debugf("unfulfilled dim is : %s", instruction.Key)
 		}
 	}
 
-	return resultDimensions, unfulfilledInstructions
+	if len(unfulfilledKeys) > 0 {
+		providers := make([]string, len(f.Providers))
+		for i, provider := range f.Providers {
+			providers[i] = provider.Name()
+		}
+		return resultDimensions, &UnfulfilledDimensionsError{Keys: unfulfilledKeys, Providers: providers}
+	}
+	return resultDimensions, nil
 }
 
 func (f *Factory) executeInstruction(instruction Instruction) types.Dimension {
 	for _, provider := range f.Providers {
 		dim := provider.GetDimension(instruction)
-		log.Printf("instruction %v provider %s returned dimension %v", instruction, provider.Name(), dim)
+		logger.Debugf("instruction %v provider %s returned dimension %v", instruction, provider.Name(), dim)
 		if (dim != types.Dimension{}) {
 			return dim
 		}
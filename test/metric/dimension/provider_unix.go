@@ -0,0 +1,18 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package dimension
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+)
+
+// platformDimensionProviders are the providers that only resolve on this OS, the EBS provider maps the linux device
+// names the agent reports to volume ids
+func platformDimensionProviders(env environment.MetaData) []IProvider {
+	return []IProvider{
+		&EBSVolumeIdDimensionProvider{Provider: Provider{env: env}},
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package dimension
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+)
+
+// platformDimensionProviders are the providers that only resolve on this OS. Windows disks are reported by drive
+// letter in the instance dimension, which the runners know up front, so there are none yet.
+func platformDimensionProviders(env environment.MetaData) []IProvider {
+	return nil
+}
@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+)
+
+// AssertNotPublished returns an error if the metric with exactly the given dimensions has any datapoint between
+// since and now, e.g. for metrics removed by drop_original, metric filters or a config change. The window should start
+// after the agent picked up the config, otherwise datapoints published before the change fail the assertion. Unlike
+// FetchWindow the start is moved forward by the skew tolerance, so a drifting clock can not pull them into the window.
+func (n *MetricValueFetcher) AssertNotPublished(namespace, metricName string, dims []types.Dimension, since time.Time) error {
+	values, err := n.fetchExactWindow(namespace, metricName, dims, SAMPLE_COUNT, HighResolutionStatPeriod, clock.Until(since), clock.Now())
+	if err != nil {
+		return err
+	}
+
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	if total > 0 {
+		return fmt.Errorf("expected no datapoints for %s since %s but found %v across %d periods",
+			metricName, since.Format(time.RFC3339), total, len(values))
+	}
+	return nil
+}
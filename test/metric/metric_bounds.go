@@ -0,0 +1,45 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds are the values a metric may have, between Min and Max, or within TolerancePercent of Expected when
+// TolerancePercent is set, e.g. mem_total within 1% of the memory of the host
+type Bounds struct {
+	Min              float64
+	Max              float64
+	Expected         float64
+	TolerancePercent float64
+}
+
+// Within is the bounds of the values within tolerancePercent of expected
+func Within(expected, tolerancePercent float64) Bounds {
+	return Bounds{Expected: expected, TolerancePercent: tolerancePercent}
+}
+
+// Range returns the smallest and the largest value within the bounds
+func (b Bounds) Range() (float64, float64) {
+	if b.TolerancePercent <= 0 {
+		return b.Min, b.Max
+	}
+	delta := math.Abs(b.Expected) * b.TolerancePercent / 100
+	return b.Expected - delta, b.Expected + delta
+}
+
+// Contains is true when the value is within the bounds, the limits are inclusive
+func (b Bounds) Contains(v float64) bool {
+	min, max := b.Range()
+	return v >= min && v <= max
+}
+
+func (b Bounds) String() string {
+	if b.TolerancePercent > 0 {
+		return fmt.Sprintf("all values within %v%% of %v", b.TolerancePercent, b.Expected)
+	}
+	return fmt.Sprintf("all values within [%v, %v]", b.Min, b.Max)
+}
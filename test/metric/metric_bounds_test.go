@@ -0,0 +1,37 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBounds(t *testing.T) {
+	percent := Bounds{Min: 0, Max: 100}
+	assert.True(t, percent.Contains(0))
+	assert.True(t, percent.Contains(100))
+	assert.False(t, percent.Contains(100.1))
+	assert.Equal(t, "all values within [0, 100]", percent.String())
+
+	atLeast := Bounds{Min: 1, Max: math.Inf(1)}
+	assert.True(t, atLeast.Contains(1e12))
+	assert.False(t, atLeast.Contains(0))
+
+	// 16 GiB of memory within 1%
+	memTotal := Within(16*1024, 1)
+	min, max := memTotal.Range()
+	assert.InDelta(t, 16220.16, min, 1e-9)
+	assert.InDelta(t, 16547.84, max, 1e-9)
+	assert.True(t, memTotal.Contains(16*1024*1.009))
+	assert.False(t, memTotal.Contains(16*1024*0.98))
+	assert.Equal(t, "all values within 1% of 16384", memTotal.String())
+
+	negative := Within(-10, 10)
+	assert.True(t, negative.Contains(-9))
+	assert.True(t, negative.Contains(-11))
+	assert.False(t, negative.Contains(-12))
+}
@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// AnyMetric is the key of the ceiling of the metrics without a ceiling of their own
+const AnyMetric = "*"
+
+// CardinalityCeilings caps the distinct dimension combinations of a metric by its name, e.g. a regression that adds
+// a dimension with a value per request multiplies the series and their cost. A metric without a ceiling of its own
+// and without an AnyMetric ceiling is not capped.
+type CardinalityCeilings map[string]int
+
+// Cardinality counts the distinct dimension combinations of every metric by its name
+func Cardinality(metrics []types.Metric) map[string]int {
+	combinations := map[string]map[string]struct{}{}
+	for _, m := range metrics {
+		name := aws.ToString(m.MetricName)
+		if combinations[name] == nil {
+			combinations[name] = map[string]struct{}{}
+		}
+		combinations[name][dimensionsKey(m.Dimensions)] = struct{}{}
+	}
+	cardinality := make(map[string]int, len(combinations))
+	for name, c := range combinations {
+		cardinality[name] = len(c)
+	}
+	return cardinality
+}
+
+// Exceeded describes every metric whose cardinality is over its ceiling, sorted by name
+func (c CardinalityCeilings) Exceeded(cardinality map[string]int) []string {
+	names := make([]string, 0, len(cardinality))
+	for name := range cardinality {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var exceeded []string
+	for _, name := range names {
+		ceiling, ok := c[name]
+		if !ok {
+			ceiling, ok = c[AnyMetric]
+		}
+		if ok && cardinality[name] > ceiling {
+			exceeded = append(exceeded, fmt.Sprintf("%s with %d dimension combinations, over the ceiling of %d", name, cardinality[name], ceiling))
+		}
+	}
+	return exceeded
+}
+
+// dimensionsKey identifies the combination regardless of the order of the dimensions
+func dimensionsKey(dims []types.Dimension) string {
+	pairs := make([]string, len(dims))
+	for i, d := range dims {
+		pairs[i] = aws.ToString(d.Name) + "=" + aws.ToString(d.Value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
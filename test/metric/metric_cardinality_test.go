@@ -0,0 +1,39 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCardinality(t *testing.T) {
+	dim := func(name, value string) types.Dimension {
+		return types.Dimension{Name: aws.String(name), Value: aws.String(value)}
+	}
+	metrics := []types.Metric{
+		{MetricName: aws.String("disk_used"), Dimensions: []types.Dimension{dim("path", "/"), dim("device", "xvda1")}},
+		// the same combination in another order
+		{MetricName: aws.String("disk_used"), Dimensions: []types.Dimension{dim("device", "xvda1"), dim("path", "/")}},
+		{MetricName: aws.String("disk_used"), Dimensions: []types.Dimension{dim("path", "/data"), dim("device", "xvdb")}},
+		{MetricName: aws.String("mem_used_percent")},
+		{MetricName: aws.String("procstat_cpu_usage"), Dimensions: []types.Dimension{dim("pid", "1")}},
+		{MetricName: aws.String("procstat_cpu_usage"), Dimensions: []types.Dimension{dim("pid", "2")}},
+		{MetricName: aws.String("procstat_cpu_usage"), Dimensions: []types.Dimension{dim("pid", "3")}},
+	}
+	cardinality := Cardinality(metrics)
+	assert.Equal(t, map[string]int{"disk_used": 2, "mem_used_percent": 1, "procstat_cpu_usage": 3}, cardinality)
+
+	assert.Empty(t, CardinalityCeilings{}.Exceeded(cardinality))
+	assert.Equal(t, []string{
+		"disk_used with 2 dimension combinations, over the ceiling of 1",
+		"procstat_cpu_usage with 3 dimension combinations, over the ceiling of 1",
+	}, CardinalityCeilings{AnyMetric: 1}.Exceeded(cardinality))
+	assert.Equal(t, []string{
+		"procstat_cpu_usage with 3 dimension combinations, over the ceiling of 2",
+	}, CardinalityCeilings{AnyMetric: 2, "disk_used": 1, "procstat_cpu_usage": 2}.Exceeded(map[string]int{"procstat_cpu_usage": 3, "disk_used": 1}))
+}
@@ -0,0 +1,35 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+// CounterIncrease returns how much a counter increased over its values, oldest first. Cumulative values are the
+// running total of the counter, which must never decrease between successive datapoints. Otherwise each value is
+// the increase since the previous collection, the way the agent publishes counters such as diskio_reads, which must
+// never be negative.
+func CounterIncrease(values []float64, cumulative bool) (float64, error) {
+	if len(values) == 0 {
+		return 0, errclass.NotFoundYetf("no values found")
+	}
+	if cumulative {
+		for i := 1; i < len(values); i++ {
+			if values[i] < values[i-1] {
+				return 0, errclass.Validationf("the counter went back from %v to %v at datapoint %d", values[i-1], values[i], i)
+			}
+		}
+		return values[len(values)-1] - values[0], nil
+	}
+
+	increase := 0.0
+	for i, value := range values {
+		if value < 0 {
+			return 0, errclass.Validationf("the counter went back by %v at datapoint %d", -value, i)
+		}
+		increase += value
+	}
+	return increase, nil
+}
@@ -0,0 +1,32 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestCounterIncrease(t *testing.T) {
+	increase, err := CounterIncrease([]float64{5, 0, 10}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 15.0, increase)
+
+	increase, err = CounterIncrease([]float64{100, 100, 130}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, increase)
+
+	_, err = CounterIncrease([]float64{5, -1}, false)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+
+	_, err = CounterIncrease([]float64{100, 90, 130}, true)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+
+	_, err = CounterIncrease(nil, true)
+	assert.Equal(t, errclass.NotFoundYet, errclass.Classify(err))
+}
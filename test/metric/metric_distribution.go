@@ -0,0 +1,182 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+// DistributionValueTolerance is the relative error of the min, max and percentiles of a distribution the agent
+// published, the agent and CloudWatch bucket the values
+const DistributionValueTolerance = 0.05
+
+// Distribution is the statistics of a metric published with many values per period, e.g. a StatsD timing or an EMF
+// metric with an array of values. The average alone can't tell a bad aggregation apart, a distribution with the
+// wrong min, max or percentiles can have the right average.
+type Distribution struct {
+	SampleCount float64
+	Sum         float64
+	Min         float64
+	Max         float64
+	// Percentiles maps a percentile to its value, e.g. 99 to the p99
+	Percentiles map[float64]float64
+}
+
+// NewDistribution computes the distribution CloudWatch should report for the values that were sent, with the
+// percentiles reconstructed by nearest rank
+func NewDistribution(values []float64, percentiles ...float64) Distribution {
+	d := Distribution{Percentiles: make(map[float64]float64, len(percentiles))}
+	if len(values) == 0 {
+		return d
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	d.SampleCount = float64(len(sorted))
+	d.Min, d.Max = sorted[0], sorted[len(sorted)-1]
+	for _, v := range sorted {
+		d.Sum += v
+	}
+	for _, p := range percentiles {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		d.Percentiles[p] = sorted[rank-1]
+	}
+	return d
+}
+
+// FetchDistribution fetches the distribution of the metric over the window in one period, so the percentiles are
+// computed over the whole window by CloudWatch. The window must be whole minutes.
+func (n *MetricValueFetcher) FetchDistribution(namespace, metricName string, dims []types.Dimension, start, end time.Time, percentiles ...float64) (Distribution, error) {
+	window := end.Sub(start)
+	if window <= 0 || window%time.Minute != 0 {
+		return Distribution{}, fmt.Errorf("the window of the %s distribution must be whole minutes, got %s", metricName, window)
+	}
+	period := int32(window / time.Second)
+	metricToFetch := types.Metric{
+		Namespace:  aws.String(ns.Resolve(namespace)),
+		MetricName: aws.String(metricName),
+		Dimensions: dims,
+	}
+	stats := []string{string(SAMPLE_COUNT), string(SUM), string(MINIMUM), string(MAXIMUM)}
+	for _, p := range percentiles {
+		stats = append(stats, percentileStat(p))
+	}
+	queries := make([]types.MetricDataQuery, len(stats))
+	for i, stat := range stats {
+		queries[i] = types.MetricDataQuery{
+			Id:         aws.String(fmt.Sprint("q", i)),
+			MetricStat: &types.MetricStat{Metric: &metricToFetch, Period: aws.Int32(period), Stat: aws.String(stat)},
+		}
+	}
+
+	logger.Infof("Fetching the distribution of %s with %v", metricName, stats)
+	output, err := n.client().GetMetricData(context.Background(), &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(start),
+		EndTime:           aws.Time(end),
+		MetricDataQueries: queries,
+	})
+	if err != nil {
+		return Distribution{}, fmt.Errorf("Error getting metric data %w", err)
+	}
+	values := make(map[string]float64, len(stats))
+	for _, result := range output.MetricDataResults {
+		id := aws.ToString(result.Id)
+		i, err := strconv.Atoi(strings.TrimPrefix(id, "q"))
+		if err != nil || i >= len(stats) || len(result.Values) == 0 {
+			continue
+		}
+		values[stats[i]] = result.Values[0]
+	}
+	for _, stat := range stats {
+		if _, ok := values[stat]; !ok {
+			return Distribution{}, errclass.NotFoundYetf("no %s of %s between %s and %s", stat, metricName, start.Format(time.RFC3339), end.Format(time.RFC3339))
+		}
+	}
+
+	d := Distribution{
+		SampleCount: values[string(SAMPLE_COUNT)],
+		Sum:         values[string(SUM)],
+		Min:         values[string(MINIMUM)],
+		Max:         values[string(MAXIMUM)],
+		Percentiles: make(map[float64]float64, len(percentiles)),
+	}
+	for _, p := range percentiles {
+		d.Percentiles[p] = values[percentileStat(p)]
+	}
+	logger.Infof("Distribution of %s is %s", metricName, d)
+	return d, nil
+}
+
+// Validate compares the distribution with the expected one. countTolerance is the relative tolerance of the sample
+// count and the sum, which depend on which samples fall in the window. valueTolerance is the relative tolerance of
+// the min, max and percentiles, CloudWatch and the agent approximate the values of a distribution.
+func (d Distribution) Validate(expected Distribution, countTolerance, valueTolerance float64) error {
+	check := func(stat string, expected, actual, tolerance float64) error {
+		if math.Abs(actual-expected) > math.Abs(expected)*tolerance {
+			return errclass.Validationf("%s is %v, expected %v ± %v%%", stat, actual, expected, tolerance*100)
+		}
+		return nil
+	}
+	if err := check(string(SAMPLE_COUNT), expected.SampleCount, d.SampleCount, countTolerance); err != nil {
+		return err
+	}
+	if err := check(string(SUM), expected.Sum, d.Sum, countTolerance); err != nil {
+		return err
+	}
+	if err := check(string(MINIMUM), expected.Min, d.Min, valueTolerance); err != nil {
+		return err
+	}
+	if err := check(string(MAXIMUM), expected.Max, d.Max, valueTolerance); err != nil {
+		return err
+	}
+	for _, p := range sortedPercentiles(expected.Percentiles) {
+		actual, ok := d.Percentiles[p]
+		if !ok {
+			return errclass.Validationf("%s was not fetched", percentileStat(p))
+		}
+		if err := check(percentileStat(p), expected.Percentiles[p], actual, valueTolerance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d Distribution) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SampleCount=%v Sum=%v Min=%v Max=%v", d.SampleCount, d.Sum, d.Min, d.Max)
+	for _, p := range sortedPercentiles(d.Percentiles) {
+		fmt.Fprintf(&b, " %s=%v", percentileStat(p), d.Percentiles[p])
+	}
+	return b.String()
+}
+
+func sortedPercentiles(percentiles map[float64]float64) []float64 {
+	sorted := make([]float64, 0, len(percentiles))
+	for p := range percentiles {
+		sorted = append(sorted, p)
+	}
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// percentileStat is the extended statistic of the percentile, e.g. p99 or p99.9
+func percentileStat(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
@@ -0,0 +1,92 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestNewDistribution(t *testing.T) {
+	values := []float64{10, 1, 9, 2, 8, 3, 7, 4, 6, 5}
+	d := NewDistribution(values, 50, 90, 99.9)
+	assert.Equal(t, Distribution{
+		SampleCount: 10,
+		Sum:         55,
+		Min:         1,
+		Max:         10,
+		Percentiles: map[float64]float64{50: 5, 90: 9, 99.9: 10},
+	}, d)
+	assert.Equal(t, "SampleCount=10 Sum=55 Min=1 Max=10 p50=5 p90=9 p99.9=10", d.String())
+
+	assert.Equal(t, Distribution{Percentiles: map[float64]float64{}}, NewDistribution(nil, 50))
+}
+
+func TestDistributionValidate(t *testing.T) {
+	expected := NewDistribution([]float64{100, 200, 300, 400}, 50)
+	actual := Distribution{SampleCount: 4, Sum: 1000, Min: 101, Max: 399, Percentiles: map[float64]float64{50: 202}}
+	assert.NoError(t, actual.Validate(expected, 0, 0.02))
+
+	// an aggregation bug which keeps the average but loses the spread
+	flat := Distribution{SampleCount: 4, Sum: 1000, Min: 250, Max: 250, Percentiles: map[float64]float64{50: 250}}
+	err := flat.Validate(expected, 0, 0.02)
+	assert.ErrorContains(t, err, "Minimum is 250, expected 100")
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+
+	dropped := Distribution{SampleCount: 3, Sum: 750, Min: 100, Max: 400, Percentiles: map[float64]float64{50: 200}}
+	assert.ErrorContains(t, dropped.Validate(expected, 0.1, 0.02), "SampleCount is 3, expected 4")
+
+	noPercentiles := Distribution{SampleCount: 4, Sum: 1000, Min: 100, Max: 400}
+	assert.ErrorContains(t, noPercentiles.Validate(expected, 0, 0), "p50 was not fetched")
+}
+
+func TestMetricValueFetcherFetchDistribution(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Minute)
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		var stats []string
+		for _, q := range in.MetricDataQueries {
+			if aws.ToInt32(q.MetricStat.Period) != 120 {
+				return false
+			}
+			stats = append(stats, aws.ToString(q.MetricStat.Stat))
+		}
+		return assert.ObjectsAreEqual([]string{"SampleCount", "Sum", "Minimum", "Maximum", "p50", "p99.9"}, stats)
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{
+			{Id: aws.String("q0"), Values: []float64{4}},
+			{Id: aws.String("q1"), Values: []float64{1000}},
+			{Id: aws.String("q2"), Values: []float64{100}},
+			{Id: aws.String("q3"), Values: []float64{400}},
+			{Id: aws.String("q4"), Values: []float64{200}},
+			{Id: aws.String("q5"), Values: []float64{400}},
+		},
+	}, nil).Once()
+	client.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String("q0"), Values: []float64{4}}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	d, err := fetcher.FetchDistribution("Test", "statsd_timing_3", nil, start, end, 50, 99.9)
+	require.NoError(t, err)
+	assert.Equal(t, Distribution{SampleCount: 4, Sum: 1000, Min: 100, Max: 400, Percentiles: map[float64]float64{50: 200, 99.9: 400}}, d)
+
+	_, err = fetcher.FetchDistribution("Test", "statsd_timing_3", nil, start, end, 50)
+	assert.Equal(t, errclass.NotFoundYet, errclass.Classify(err))
+
+	_, err = fetcher.FetchDistribution("Test", "statsd_timing_3", nil, start, start.Add(90*time.Second))
+	assert.ErrorContains(t, err, "must be whole minutes")
+	client.AssertExpectations(t)
+}
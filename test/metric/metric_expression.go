@@ -0,0 +1,118 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+// expressionId is the id of the expression among the queries, the ids of the metrics must differ from it
+const expressionId = "expression"
+
+// MetricQuery is a metric a math expression refers to by its id, which must start with a lowercase letter
+type MetricQuery struct {
+	Id         string
+	MetricName string
+	Dimensions []types.Dimension
+	Stat       Statistics
+}
+
+// FetchExpression evaluates the metric math expression over the last 10 minutes, newest first. The expression refers
+// to the metrics of the namespace by the ids of the queries, e.g. SUM([sent, recv]) or RATE(sent). An expression
+// with a SEARCH, see Search, finds its metrics itself and needs no queries. The error is a NotFoundYetError when the
+// expression has no values yet.
+func (n *MetricValueFetcher) FetchExpression(namespace, expression string, queries []MetricQuery, period int32) (MetricValues, error) {
+	endTime := clock.Now()
+	return n.FetchExpressionWindow(namespace, expression, queries, period, subtractMinutes(endTime, 10), endTime)
+}
+
+// FetchExpressionWindow is FetchExpression over the given window, which is widened by the skew tolerance like
+// FetchWindow
+func (n *MetricValueFetcher) FetchExpressionWindow(namespace, expression string, queries []MetricQuery, period int32, startTime, endTime time.Time) (MetricValues, error) {
+	namespace = ns.Resolve(namespace)
+	l := logger.With(logger.Fields{"namespace": namespace, "expression": expression})
+
+	metricDataQueries := make([]types.MetricDataQuery, 0, len(queries)+1)
+	for _, q := range queries {
+		l.Debugf("Metric %s is %s", q.Id, q.MetricName)
+		logDimensions(l, q.Dimensions)
+		metricDataQueries = append(metricDataQueries, types.MetricDataQuery{
+			Id: aws.String(q.Id),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String(namespace),
+					MetricName: aws.String(q.MetricName),
+					Dimensions: q.Dimensions,
+				},
+				Period: aws.Int32(period),
+				Stat:   aws.String(string(q.Stat)),
+			},
+			// only the expression is returned, the metrics are its inputs
+			ReturnData: aws.Bool(false),
+		})
+	}
+	metricDataQueries = append(metricDataQueries, types.MetricDataQuery{
+		Id:         aws.String(expressionId),
+		Expression: aws.String(expression),
+		Period:     aws.Int32(period),
+		ReturnData: aws.Bool(true),
+	})
+
+	l.Infof("Fetching metric math expression with period %v", period)
+	output, err := n.client().GetMetricData(context.Background(), &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(clock.Since(startTime)),
+		EndTime:           aws.Time(clock.Until(endTime)),
+		MetricDataQueries: metricDataQueries,
+		ScanBy:            types.ScanByTimestampDescending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error getting metric data of expression %s %w", expression, err)
+	}
+
+	// a SEARCH returns a result per metric it found, which the expression must aggregate to be validated as one
+	var values MetricValues
+	for _, result := range output.MetricDataResults {
+		if aws.ToString(result.Id) != expressionId {
+			continue
+		}
+		if values != nil {
+			return nil, errclass.Validationf("expression %s returned more than one time series, aggregate it e.g. with SUM", expression)
+		}
+		if result.StatusCode == types.StatusCodeInternalError {
+			return nil, fmt.Errorf("expression %s failed: %v", expression, result.Messages)
+		}
+		values = result.Values
+	}
+	l.Infof("Expression values are : %s", fmt.Sprint(values))
+	if len(values) == 0 {
+		return nil, errclass.NotFoundYetf("no values for expression %s", expression)
+	}
+	return values, nil
+}
+
+// Search returns a SEARCH expression for the metric of the namespace across every value of the keys, with the given
+// dimensions fixed, e.g. the cpu_usage_active of every core of the instance with the key cpu. Aggregate it, e.g.
+// SUM(Search(...)), to fetch it with FetchExpression.
+func Search(namespace, metricName string, dims []types.Dimension, keys []string, stat Statistics, period int32) string {
+	schema := []string{fmt.Sprintf("%q", ns.Resolve(namespace))}
+	terms := []string{fmt.Sprintf("MetricName=%q", metricName)}
+	for _, d := range dims {
+		schema = append(schema, aws.ToString(d.Name))
+		terms = append(terms, fmt.Sprintf("%s=%q", aws.ToString(d.Name), aws.ToString(d.Value)))
+	}
+	schema = append(schema, keys...)
+	return fmt.Sprintf("SEARCH('{%s} %s', '%s', %d)", strings.Join(schema, ","), strings.Join(terms, " "), stat, period)
+}
@@ -0,0 +1,73 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestMetricValueFetcherFetchExpression(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	dims := []types.Dimension{{Name: aws.String("interface"), Value: aws.String("eth0")}}
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		if len(in.MetricDataQueries) != 3 {
+			return false
+		}
+		sent, expression := in.MetricDataQueries[0], in.MetricDataQueries[2]
+		return aws.ToString(sent.Id) == "sent" && !aws.ToBool(sent.ReturnData) &&
+			aws.ToString(sent.MetricStat.Metric.Namespace) == "Test" &&
+			aws.ToString(sent.MetricStat.Stat) == string(SUM) &&
+			aws.ToString(expression.Expression) == "sent + recv" && aws.ToBool(expression.ReturnData)
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String(expressionId), Values: []float64{30, 20}}},
+	}, nil).Once()
+	client.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Id: aws.String(expressionId)}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	queries := []MetricQuery{
+		{Id: "sent", MetricName: "net_bytes_sent", Dimensions: dims, Stat: SUM},
+		{Id: "recv", MetricName: "net_bytes_recv", Dimensions: dims, Stat: SUM},
+	}
+	values, err := fetcher.FetchExpression("Test", "sent + recv", queries, HighResolutionStatPeriod)
+	require.NoError(t, err)
+	assert.Equal(t, MetricValues{30, 20}, values)
+
+	_, err = fetcher.FetchExpression("Test", "sent + recv", queries, HighResolutionStatPeriod)
+	assert.Equal(t, errclass.NotFoundYet, errclass.Classify(err))
+	client.AssertExpectations(t)
+}
+
+func TestMetricValueFetcherFetchExpressionNotAggregated(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	client.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{
+			{Id: aws.String(expressionId), Values: []float64{1}},
+			{Id: aws.String(expressionId), Values: []float64{2}},
+		},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	_, err := fetcher.FetchExpression("Test", Search("Test", "cpu_usage_active", nil, []string{"cpu"}, AVERAGE, 60), nil, 60)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+	client.AssertExpectations(t)
+}
+
+func TestSearch(t *testing.T) {
+	dims := []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-0123")}}
+	assert.Equal(t,
+		`SEARCH('{"Test",InstanceId,cpu} MetricName="cpu_usage_active" InstanceId="i-0123"', 'Average', 10)`,
+		Search("Test", "cpu_usage_active", dims, []string{"cpu"}, AVERAGE, HighResolutionStatPeriod))
+}
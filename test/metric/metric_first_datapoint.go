@@ -0,0 +1,65 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+// FirstDatapoint returns the timestamp of the first high resolution period since the time in which the metric with
+// exactly the given dimensions has a datapoint, e.g. to measure how long a config change took to take effect. The
+// start is moved forward by the skew tolerance like AssertNotPublished, so a datapoint from before the time is never
+// returned. The error is a NotFoundYetError when there is no datapoint yet.
+func (n *MetricValueFetcher) FirstDatapoint(namespace, metricName string, dims []types.Dimension, since time.Time) (time.Time, error) {
+	result, err := n.fetchDatapoints(namespace, metricName, dims, SAMPLE_COUNT, HighResolutionStatPeriod, clock.Until(since), clock.Now(), types.ScanByTimestampAscending)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i, value := range result.Values {
+		if value > 0 && i < len(result.Timestamps) {
+			return result.Timestamps[i], nil
+		}
+	}
+	return time.Time{}, errclass.NotFoundYetf("no datapoint for %s since %s", metricName, since.Format(time.RFC3339))
+}
+
+// AnyVisible reports whether any of the metrics of the namespace has a datapoint since the time, e.g. to measure how
+// long the agent takes to publish its first datapoint. Unlike FirstDatapoint the metrics may have dimensions besides
+// the given ones, which only narrow the search.
+func (n *MetricValueFetcher) AnyVisible(namespace string, metricNames []string, dims []types.Dimension, since time.Time) (bool, error) {
+	if len(metricNames) == 0 {
+		return false, nil
+	}
+	names := make([]string, len(metricNames))
+	for i, name := range metricNames {
+		names[i] = fmt.Sprintf("MetricName=%q", name)
+	}
+	terms := []string{"(" + strings.Join(names, " OR ") + ")"}
+	for _, d := range dims {
+		terms = append(terms, fmt.Sprintf("%s=%q", aws.ToString(d.Name), aws.ToString(d.Value)))
+	}
+	expression := fmt.Sprintf("SUM(SEARCH('{%q} %s', '%s', %d))", ns.Resolve(namespace), strings.Join(terms, " "), SAMPLE_COUNT, HighResolutionStatPeriod)
+	values, err := n.FetchExpressionWindow(namespace, expression, nil, HighResolutionStatPeriod, clock.Until(since), clock.Now())
+	if errclass.Classify(err) == errclass.NotFoundYet {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		if value > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
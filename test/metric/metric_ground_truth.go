@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
+)
+
+// HostReading is how a metric is compared with what the host read itself, see groundtruth
+type HostReading struct {
+	// Key is the key of the local reading, the name of the metric when empty
+	Key string
+	// Counter compares the increase of a counter, which the agent publishes as the increase since the previous
+	// collection, instead of the average
+	Counter bool
+	// The metric agrees with the host within max(Absolute, Relative * the local reading)
+	Absolute float64
+	Relative float64
+}
+
+// HostComparison is a metric and what the host read over the same window
+type HostComparison struct {
+	Published float64
+	Local     float64
+	Tolerance float64
+}
+
+// Agrees returns whether the published value is within the tolerance of the local reading
+func (c HostComparison) Agrees() bool {
+	return math.Abs(c.Published-c.Local) <= c.Tolerance
+}
+
+func (c HostComparison) String() string {
+	return fmt.Sprintf("%v ± %v read from the host", c.Local, c.Tolerance)
+}
+
+// CompareWithHost fetches the metric over the window of the series and compares it with the local reading. The error
+// is a NotFoundYetError when the metric has no values in the window.
+func (n *MetricValueFetcher) CompareWithHost(namespace, metricName string, dims []types.Dimension, series groundtruth.Series, reading HostReading) (HostComparison, error) {
+	key := reading.Key
+	if key == "" {
+		key = metricName
+	}
+	local, ok := series.Average(key)
+	stat := AVERAGE
+	if reading.Counter {
+		local, ok = series.Increase(key)
+		stat = SUM
+	}
+	if !ok {
+		return HostComparison{}, fmt.Errorf("the host has no reading of %s to compare with", key)
+	}
+
+	start, end := series.Window()
+	values, err := n.FetchWindow(namespace, metricName, dims, stat, HighResolutionStatPeriod, start, end)
+	if err != nil {
+		return HostComparison{}, err
+	}
+	if len(values) == 0 {
+		return HostComparison{}, errclass.NotFoundYetf("no values found between %s and %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	var published float64
+	for _, v := range values {
+		published += v
+	}
+	if !reading.Counter {
+		published /= float64(len(values))
+	}
+	return HostComparison{
+		Published: published,
+		Local:     local,
+		Tolerance: math.Max(reading.Absolute, reading.Relative*math.Abs(local)),
+	}, nil
+}
@@ -8,19 +8,23 @@ package metric
 import (
 	"context"
 	"fmt"
-	"log"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
 )
 
 type MetricListFetcher struct {
+	// Client defaults to awsservice.CwmClient when nil
+	Client awsservice.CloudWatchAPI
 }
 
 func (n *MetricListFetcher) Fetch(namespace, metricName string, dimensions []types.Dimension) ([]types.Metric, error) {
+	namespace = ns.Resolve(namespace)
 	var dims []types.DimensionFilter
 	for _, dim := range dimensions {
 		dims = append(dims, types.DimensionFilter{
@@ -35,14 +39,60 @@ func (n *MetricListFetcher) Fetch(namespace, metricName string, dimensions []typ
 		Dimensions: dims,
 	}
 
-	log.Printf("Metric data input: namespace %v, name %v", namespace, metricName)
+	l := logger.With(logger.Fields{"namespace": namespace, "metric": metricName})
+	l.Debugf("Listing metrics")
 
-	output, err := awsservice.CwmClient.ListMetrics(context.Background(), &listMetricInput)
+	metrics, err := n.listMetrics(&listMetricInput)
 	if err != nil {
 		return nil, fmt.Errorf("Error getting metric data %v", err)
 	}
 
-	log.Printf("Metrics fetched : %s", fmt.Sprint(output))
+	l.Debugf("Metrics fetched : %s", fmt.Sprint(metrics))
 
-	return output.Metrics, nil
+	return metrics, nil
+}
+
+// listMetrics goes through the awsservice cache unless a client was injected. Both return every page.
+func (n *MetricListFetcher) listMetrics(input *cloudwatch.ListMetricsInput) ([]types.Metric, error) {
+	if n.Client == nil {
+		return awsservice.ListMetrics(input)
+	}
+	var metrics []types.Metric
+	paginator := cloudwatch.NewListMetricsPaginator(n.Client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, output.Metrics...)
+	}
+	return metrics, nil
+}
+
+// FetchNamespace lists every metric recently active in the namespace with the dimensions. It bypasses the awsservice
+// cache, the namespace gains metrics while a suite runs.
+func (n *MetricListFetcher) FetchNamespace(namespace string, dimensions []types.Dimension) ([]types.Metric, error) {
+	var dims []types.DimensionFilter
+	for _, dim := range dimensions {
+		dims = append(dims, types.DimensionFilter{Name: dim.Name, Value: dim.Value})
+	}
+	input := cloudwatch.ListMetricsInput{
+		Namespace:      aws.String(ns.Resolve(namespace)),
+		Dimensions:     dims,
+		RecentlyActive: types.RecentlyActivePt3h,
+	}
+	client := n.Client
+	if client == nil {
+		client = awsservice.CwmClient
+	}
+	var metrics []types.Metric
+	paginator := cloudwatch.NewListMetricsPaginator(client, &input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("Error listing metrics of namespace %s %w", aws.ToString(input.Namespace), err)
+		}
+		metrics = append(metrics, output.Metrics...)
+	}
+	return metrics, nil
 }
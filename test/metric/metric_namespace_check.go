@@ -0,0 +1,70 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+// NamespaceCheck lists the metrics in the namespace of a suite and fails on the ones no runner declared, e.g. the
+// metrics of a plugin a config enabled by accident, and on the ones with more dimension combinations than their
+// cardinality ceiling
+type NamespaceCheck struct {
+	Fetcher   MetricListFetcher
+	Namespace string
+	// Dimensions restricts the check to the metrics with them, e.g. the InstanceId of the host, so the metrics other
+	// hosts and older runs published to the namespace are left out
+	Dimensions []types.Dimension
+	Ceilings   CardinalityCeilings
+
+	expected map[string]struct{}
+}
+
+// Expect declares the metrics. The runners of a suite share the namespace, so they stay expected for every later
+// Validate.
+func (c *NamespaceCheck) Expect(names ...string) {
+	if c.expected == nil {
+		c.expected = map[string]struct{}{}
+	}
+	for _, name := range names {
+		c.expected[name] = struct{}{}
+	}
+}
+
+// Validate returns a Validation error naming every unexpected metric and every metric over its ceiling
+func (c *NamespaceCheck) Validate() error {
+	metrics, err := c.Fetcher.FetchNamespace(c.Namespace, c.Dimensions)
+	if err != nil {
+		return err
+	}
+	cardinality := Cardinality(metrics)
+	names := make([]string, 0, len(cardinality))
+	for name := range cardinality {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var unexpected []string
+	for _, name := range names {
+		if _, ok := c.expected[name]; !ok {
+			unexpected = append(unexpected, name)
+		}
+	}
+	var problems []string
+	if len(unexpected) > 0 {
+		problems = append(problems, "unexpected metrics "+strings.Join(unexpected, ", "))
+	}
+	problems = append(problems, c.Ceilings.Exceeded(cardinality)...)
+	if len(problems) > 0 {
+		return errclass.Validationf("namespace %s has %s", c.Namespace, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func listed(name string, sets int) []types.Metric {
+	metrics := make([]types.Metric, sets)
+	for i := range metrics {
+		metrics[i] = types.Metric{
+			MetricName: aws.String(name),
+			Dimensions: []types.Dimension{{Name: aws.String("cpu"), Value: aws.String(fmt.Sprint("cpu", i))}},
+		}
+	}
+	return metrics
+}
+
+func TestNamespaceCheck(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	instance := []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-0123")}}
+	metrics := append(listed("cpu_usage_idle", 2), listed("mem_used_percent", 1)...)
+	client.On("ListMetrics", mock.Anything, mock.MatchedBy(func(in *cloudwatch.ListMetricsInput) bool {
+		return aws.ToString(in.Namespace) == "Test" && in.MetricName == nil && in.RecentlyActive == types.RecentlyActivePt3h &&
+			aws.ToString(in.Dimensions[0].Value) == "i-0123"
+	})).Return(&cloudwatch.ListMetricsOutput{Metrics: metrics}, nil).Once()
+	client.On("ListMetrics", mock.Anything, mock.Anything).Return(&cloudwatch.ListMetricsOutput{
+		Metrics: append(metrics, append(listed("swap_used", 1), listed("procstat_cpu_usage", 3)...)...),
+	}, nil).Once()
+
+	check := NamespaceCheck{Fetcher: MetricListFetcher{Client: client}, Namespace: "Test", Dimensions: instance, Ceilings: CardinalityCeilings{AnyMetric: 2}}
+	check.Expect("cpu_usage_idle")
+	check.Expect("mem_used_percent", "procstat_cpu_usage")
+	assert.NoError(t, check.Validate())
+
+	err := check.Validate()
+	assert.EqualError(t, err, "namespace Test has unexpected metrics swap_used; procstat_cpu_usage with 3 dimension combinations, over the ceiling of 2")
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+	client.AssertExpectations(t)
+}
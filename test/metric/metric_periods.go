@@ -0,0 +1,27 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// FetchPeriods returns the values of the metric by the start of their period. Unlike FetchWindow the window is not
+// widened by the skew tolerance, so the periods line up with intervals computed locally, e.g. by a load.Expectation.
+// A period without a datapoint has no entry.
+func (n *MetricValueFetcher) FetchPeriods(namespace, metricName string, dims []types.Dimension, stat Statistics, period int32, start, end time.Time) (map[time.Time]float64, error) {
+	result, err := n.fetchDatapoints(namespace, metricName, dims, stat, period, start, end, types.ScanByTimestampAscending)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[time.Time]float64, len(result.Values))
+	for i, value := range result.Values {
+		if i < len(result.Timestamps) {
+			values[result.Timestamps[i].UTC()] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,134 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+// ExpectedUnits is the unit the agent reports for the metric when the config does not override it
+var ExpectedUnits = map[string]types.StandardUnit{
+	"cpu_time_active":     types.StandardUnitNone,
+	"cpu_time_guest":      types.StandardUnitNone,
+	"cpu_time_guest_nice": types.StandardUnitNone,
+	"cpu_time_idle":       types.StandardUnitNone,
+	"cpu_time_iowait":     types.StandardUnitNone,
+	"cpu_time_irq":        types.StandardUnitNone,
+	"cpu_time_nice":       types.StandardUnitNone,
+	"cpu_time_softirq":    types.StandardUnitNone,
+	"cpu_time_steal":      types.StandardUnitNone,
+	"cpu_time_system":     types.StandardUnitNone,
+	"cpu_time_user":       types.StandardUnitNone,
+
+	"cpu_usage_active":     types.StandardUnitPercent,
+	"cpu_usage_guest":      types.StandardUnitPercent,
+	"cpu_usage_guest_nice": types.StandardUnitPercent,
+	"cpu_usage_idle":       types.StandardUnitPercent,
+	"cpu_usage_iowait":     types.StandardUnitPercent,
+	"cpu_usage_irq":        types.StandardUnitPercent,
+	"cpu_usage_nice":       types.StandardUnitPercent,
+	"cpu_usage_softirq":    types.StandardUnitPercent,
+	"cpu_usage_steal":      types.StandardUnitPercent,
+	"cpu_usage_system":     types.StandardUnitPercent,
+	"cpu_usage_user":       types.StandardUnitPercent,
+
+	"mem_active":            types.StandardUnitBytes,
+	"mem_available":         types.StandardUnitBytes,
+	"mem_available_percent": types.StandardUnitPercent,
+	"mem_buffered":          types.StandardUnitBytes,
+	"mem_cached":            types.StandardUnitBytes,
+	"mem_free":              types.StandardUnitBytes,
+	"mem_inactive":          types.StandardUnitBytes,
+	"mem_total":             types.StandardUnitBytes,
+	"mem_used":              types.StandardUnitBytes,
+	"mem_used_percent":      types.StandardUnitPercent,
+
+	"disk_free":         types.StandardUnitBytes,
+	"disk_inodes_free":  types.StandardUnitCount,
+	"disk_inodes_total": types.StandardUnitCount,
+	"disk_inodes_used":  types.StandardUnitCount,
+	"disk_total":        types.StandardUnitBytes,
+	"disk_used":         types.StandardUnitBytes,
+	"disk_used_percent": types.StandardUnitPercent,
+
+	"diskio_iops_in_progress": types.StandardUnitCount,
+	"diskio_io_time":          types.StandardUnitMilliseconds,
+	"diskio_reads":            types.StandardUnitCount,
+	"diskio_read_bytes":       types.StandardUnitBytes,
+	"diskio_read_time":        types.StandardUnitMilliseconds,
+	"diskio_writes":           types.StandardUnitCount,
+	"diskio_write_bytes":      types.StandardUnitBytes,
+	"diskio_write_time":       types.StandardUnitMilliseconds,
+
+	"net_bytes_recv":   types.StandardUnitBytes,
+	"net_bytes_sent":   types.StandardUnitBytes,
+	"net_drop_in":      types.StandardUnitCount,
+	"net_drop_out":     types.StandardUnitCount,
+	"net_err_in":       types.StandardUnitCount,
+	"net_err_out":      types.StandardUnitCount,
+	"net_packets_recv": types.StandardUnitCount,
+	"net_packets_sent": types.StandardUnitCount,
+
+	"swap_free":         types.StandardUnitBytes,
+	"swap_used":         types.StandardUnitBytes,
+	"swap_used_percent": types.StandardUnitPercent,
+}
+
+// FetchUnit returns the unit of the datapoints the metric has in the last 10 minutes. GetMetricData cannot be used
+// for this, since it only filters by unit and does not report it.
+func (n *MetricValueFetcher) FetchUnit(namespace, metricName string, dims []types.Dimension) (types.StandardUnit, error) {
+	endTime := time.Now()
+	output, err := n.client().GetMetricStatistics(context.Background(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(ns.Resolve(namespace)),
+		MetricName: aws.String(metricName),
+		Dimensions: dims,
+		StartTime:  aws.Time(subtractMinutes(endTime, 10)),
+		EndTime:    aws.Time(endTime),
+		Period:     aws.Int32(60),
+		Statistics: []types.Statistic{types.StatisticSampleCount},
+	})
+	if err != nil {
+		return "", fmt.Errorf("Error getting metric statistics %v", err)
+	}
+	if len(output.Datapoints) == 0 {
+		return "", fmt.Errorf("no datapoints found for %s to check the unit of", metricName)
+	}
+
+	unit := output.Datapoints[0].Unit
+	for _, d := range output.Datapoints[1:] {
+		if d.Unit != unit {
+			return "", fmt.Errorf("metric %s is reported with units %s and %s", metricName, unit, d.Unit)
+		}
+	}
+	return unit, nil
+}
+
+// ValidateUnit returns an error if the metric is not reported with the expected unit, or with the unit in
+// ExpectedUnits when expected is empty. A metric without an expected unit is an error, so a runner cannot silently
+// check only part of what it measures.
+func (n *MetricValueFetcher) ValidateUnit(namespace, metricName string, dims []types.Dimension, expected types.StandardUnit) error {
+	if expected == "" {
+		var ok bool
+		if expected, ok = ExpectedUnits[metricName]; !ok {
+			return fmt.Errorf("no expected unit for %s", metricName)
+		}
+	}
+
+	unit, err := n.FetchUnit(namespace, metricName, dims)
+	if err != nil {
+		return err
+	}
+	if unit != expected {
+		return fmt.Errorf("expected unit %s for %s but found %s", expected, metricName, unit)
+	}
+	return nil
+}
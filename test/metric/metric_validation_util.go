@@ -1,6 +1,10 @@
 package metric
 
-import "log"
+import (
+	"fmt"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
 
 var CpuMetrics = []string{"cpu_time_active", "cpu_time_guest", "cpu_time_guest_nice", "cpu_time_idle", "cpu_time_iowait", "cpu_time_irq",
 	"cpu_time_nice", "cpu_time_softirq", "cpu_time_steal", "cpu_time_system", "cpu_time_user",
@@ -12,14 +16,14 @@ var CpuMetrics = []string{"cpu_time_active", "cpu_time_guest", "cpu_time_guest_n
 // https://github.com/aws/amazon-cloudwatch-agent-test/pull/162
 func IsAllValuesGreaterThanOrEqualToExpectedValue(metricName string, values []float64, expectedValue float64) bool {
 	if len(values) == 0 {
-		log.Printf("No values found %v", metricName)
+		logger.Warnf("No values found %v", metricName)
 		return false
 	}
 
 	totalSum := 0.0
 	for _, value := range values {
 		if value < 0 {
-			log.Printf("Values are not all greater than or equal to zero for %s", metricName)
+			logger.Warnf("Values are not all greater than or equal to zero for %s", metricName)
 			return false
 		}
 		totalSum += value
@@ -29,12 +33,20 @@ func IsAllValuesGreaterThanOrEqualToExpectedValue(metricName string, values []fl
 	upperBoundValue := expectedValue * (1 + metricErrorBound)
 	lowerBoundValue := expectedValue * (1 - metricErrorBound)
 	if expectedValue > 0 && (metricAverageValue > upperBoundValue || metricAverageValue < lowerBoundValue) {
-		log.Printf("The average value %f for metric %s are not within bound [%f, %f]",
+		logger.Warnf("The average value %f for metric %s are not within bound [%f, %f]",
 			metricAverageValue, metricName, lowerBoundValue, upperBoundValue)
 		return false
 	}
 
-	log.Printf("The average value %f for metric %s are within bound [%f, %f]",
+	logger.Infof("The average value %f for metric %s are within bound [%f, %f]",
 		metricAverageValue, metricName, lowerBoundValue, upperBoundValue)
 	return true
 }
+
+// DescribeExpectedValue describes the bounds IsAllValuesGreaterThanOrEqualToExpectedValue checks for the expected value
+func DescribeExpectedValue(expectedValue float64) string {
+	if expectedValue > 0 {
+		return fmt.Sprintf("all values >= 0 with an average within 10%% of %v", expectedValue)
+	}
+	return "all values >= 0"
+}
@@ -6,8 +6,6 @@ package metric
 import (
 	"context"
 	"fmt"
-	"log"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,24 +13,54 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
 )
 
+// valueQueryId is the id of the only query of the GetMetricData requests of a MetricValueFetcher
+const valueQueryId = "m0"
+
 type MetricValueFetcher struct {
+	// Client defaults to awsservice.CwmClient when nil
+	Client awsservice.CloudWatchAPI
 }
 
-func logDimensions(dims []types.Dimension) {
-	log.Printf("\tDimensions:\n")
+func logDimensions(l *logger.Logger, dims []types.Dimension) {
 	for _, d := range dims {
 		if d.Name != nil && d.Value != nil {
-			log.Printf("\t\tDim(name=%q, val=%q)\n", *d.Name, *d.Value)
+			l.Debugf("Dim(name=%q, val=%q)", *d.Name, *d.Value)
 		}
 	}
 }
 
 func (n *MetricValueFetcher) Fetch(namespace, metricName string, metricSpecificDimensions []types.Dimension, stat Statistics, metricQueryPeriod int32) (MetricValues, error) {
+	endTime := clock.Now()
+	return n.FetchWindow(namespace, metricName, metricSpecificDimensions, stat, metricQueryPeriod, subtractMinutes(endTime, 10), endTime)
+}
+
+// FetchWindow is Fetch over the given window instead of the last 10 minutes. The window is widened by the skew
+// tolerance, the datapoints may be timestamped by a drifting host clock.
+func (n *MetricValueFetcher) FetchWindow(namespace, metricName string, metricSpecificDimensions []types.Dimension, stat Statistics, metricQueryPeriod int32, startTime, endTime time.Time) (MetricValues, error) {
+	return n.fetchExactWindow(namespace, metricName, metricSpecificDimensions, stat, metricQueryPeriod, clock.Since(startTime), clock.Until(endTime))
+}
+
+func (n *MetricValueFetcher) fetchExactWindow(namespace, metricName string, metricSpecificDimensions []types.Dimension, stat Statistics, metricQueryPeriod int32, startTime, endTime time.Time) (MetricValues, error) {
+	result, err := n.fetchDatapoints(namespace, metricName, metricSpecificDimensions, stat, metricQueryPeriod, startTime, endTime, types.ScanByTimestampDescending)
+	if err != nil {
+		return nil, err
+	}
+	return result.Values, nil
+}
+
+// fetchDatapoints queries the metric over the exact window and returns the values with their timestamps, in the
+// order of scanBy
+func (n *MetricValueFetcher) fetchDatapoints(namespace, metricName string, metricSpecificDimensions []types.Dimension, stat Statistics, metricQueryPeriod int32, startTime, endTime time.Time, scanBy types.ScanBy) (types.MetricDataResult, error) {
+	namespace = ns.Resolve(namespace)
 	dimensions := metricSpecificDimensions
-	log.Println("Metric query input dimensions")
-	logDimensions(dimensions)
+	l := logger.With(logger.Fields{"namespace": namespace, "metric": metricName})
+	l.Debugf("Metric query input dimensions")
+	logDimensions(l, dimensions)
 	metricToFetch := types.Metric{
 		Namespace:  aws.String(namespace),
 		MetricName: aws.String(metricName),
@@ -46,32 +74,38 @@ func (n *MetricValueFetcher) Fetch(namespace, metricName string, metricSpecificD
 				Period: &metricQueryPeriod,
 				Stat:   aws.String(string(stat)),
 			},
-			Id: aws.String(strings.ToLower(metricName)),
+			// a fixed id, metric names such as jvm.classes.loaded are not valid ids
+			Id: aws.String(valueQueryId),
 		},
 	}
 
-	endTime := time.Now()
-	startTime := subtractMinutes(endTime, 10)
 	getMetricDataInput := cloudwatch.GetMetricDataInput{
 		StartTime:         &startTime,
 		EndTime:           &endTime,
 		MetricDataQueries: metricDataQueries,
+		ScanBy:            scanBy,
 	}
 
-	log.Printf("Metric data input: namespace %v, name %v, stat %v, period %v",
-		namespace, metricName, stat, metricQueryPeriod)
+	l.Infof("Fetching metric data with stat %v, period %v", stat, metricQueryPeriod)
 
-	output, err := awsservice.CwmClient.GetMetricData(context.Background(), &getMetricDataInput)
+	output, err := n.client().GetMetricData(context.Background(), &getMetricDataInput)
 	if err != nil {
-		return nil, fmt.Errorf("Error getting metric data %v", err)
+		return types.MetricDataResult{}, fmt.Errorf("Error getting metric data %w", err)
 	}
 
-	result := output.MetricDataResults[0].Values
-	log.Printf("Metric values are : %s", fmt.Sprint(result))
+	result := output.MetricDataResults[0]
+	l.Infof("Metric values are : %s", fmt.Sprint(result.Values))
 
 	return result, nil
 }
 
+func (n *MetricValueFetcher) client() awsservice.CloudWatchAPI {
+	if n.Client == nil {
+		return awsservice.CwmClient
+	}
+	return n.Client
+}
+
 func subtractMinutes(fromTime time.Time, minutes int) time.Time {
 	tenMinutes := time.Duration(-1*minutes) * time.Minute
 	return fromTime.Add(tenMinutes)
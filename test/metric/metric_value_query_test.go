@@ -0,0 +1,115 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package metric
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestMetricValueFetcherFetch(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	dims := []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String("i-0123")}}
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		stat := in.MetricDataQueries[0].MetricStat
+		return aws.ToString(stat.Metric.Namespace) == "Test" &&
+			aws.ToString(stat.Metric.MetricName) == "mem_used_percent" &&
+			assert.ObjectsAreEqual(dims, stat.Metric.Dimensions) &&
+			aws.ToString(stat.Stat) == string(AVERAGE) &&
+			aws.ToInt32(stat.Period) == HighResolutionStatPeriod
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Values: []float64{1, 2, 3}}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	values, err := fetcher.Fetch("Test", "mem_used_percent", dims, AVERAGE, HighResolutionStatPeriod)
+	require.NoError(t, err)
+	assert.Equal(t, MetricValues{1, 2, 3}, values)
+	client.AssertExpectations(t)
+}
+
+func TestMetricValueFetcherFetchDottedMetricName(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	validId := regexp.MustCompile(`^[a-z][a-zA-Z0-9_]*$`)
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		return aws.ToString(in.MetricDataQueries[0].MetricStat.Metric.MetricName) == "jvm.classes.loaded" &&
+			validId.MatchString(aws.ToString(in.MetricDataQueries[0].Id))
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{Values: []float64{4096}}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	values, err := fetcher.Fetch("Test", "jvm.classes.loaded", nil, AVERAGE, HighResolutionStatPeriod)
+	require.NoError(t, err)
+	assert.Equal(t, MetricValues{4096}, values)
+	client.AssertExpectations(t)
+}
+
+func TestMetricValueFetcherFetchError(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	client.On("GetMetricData", mock.Anything, mock.Anything).Return(nil, errors.New("throttled")).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	_, err := fetcher.Fetch("Test", "mem_used_percent", nil, AVERAGE, HighResolutionStatPeriod)
+	assert.ErrorContains(t, err, "throttled")
+	client.AssertExpectations(t)
+}
+
+func TestMetricValueFetcherFirstDatapoint(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	since := time.Now().Add(-time.Minute)
+	first := since.Add(20 * time.Second)
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		return in.ScanBy == types.ScanByTimestampAscending && !in.StartTime.Before(since)
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{
+			Values:     []float64{0, 1, 1},
+			Timestamps: []time.Time{since.Add(10 * time.Second), first, first.Add(10 * time.Second)},
+		}},
+	}, nil).Once()
+	client.On("GetMetricData", mock.Anything, mock.Anything).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	timestamp, err := fetcher.FirstDatapoint("Test", "mem_used_percent", nil, since)
+	require.NoError(t, err)
+	assert.Equal(t, first, timestamp)
+
+	_, err = fetcher.FirstDatapoint("Test", "mem_used_percent", nil, since)
+	assert.Equal(t, errclass.NotFoundYet, errclass.Classify(err))
+	client.AssertExpectations(t)
+}
+
+func TestMetricValueFetcherFetchPeriods(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(3 * time.Minute)
+	client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+		return in.StartTime.Equal(start) && in.EndTime.Equal(end) && aws.ToInt32(in.MetricDataQueries[0].MetricStat.Period) == 60
+	})).Return(&cloudwatch.GetMetricDataOutput{
+		MetricDataResults: []types.MetricDataResult{{
+			Values:     []float64{6, 5},
+			Timestamps: []time.Time{start, start.Add(2 * time.Minute)},
+		}},
+	}, nil).Once()
+
+	fetcher := MetricValueFetcher{Client: client}
+	values, err := fetcher.FetchPeriods("Test", "statsd.counter_1", nil, SAMPLE_COUNT, 60, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]float64{start: 6, start.Add(2 * time.Minute): 5}, values)
+	client.AssertExpectations(t)
+}
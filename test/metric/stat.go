@@ -10,6 +10,7 @@ const (
 	AVERAGE                  Statistics = "Average"
 	SAMPLE_COUNT             Statistics = "SampleCount"
 	MINIMUM                  Statistics = "Minimum"
-	MAXUMUM                  Statistics = "Maxmimum"
+	MAXIMUM                  Statistics = "Maximum"
+	SUM                      Statistics = "Sum"
 	HighResolutionStatPeriod            = 10
 )
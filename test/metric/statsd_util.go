@@ -8,8 +8,9 @@ package metric
 import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"log"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"strings"
 	"time"
 )
@@ -38,28 +39,9 @@ func ValidateStatsdMetric(dimFactory dimension.Factory, namespace string, dimens
 		Name:   metricName,
 		Status: status.FAILED,
 	}
-	instructions := []dimension.Instruction{
-		{
-			Key:   dimensionKey,
-			Value: dimension.UnknownDimensionValue(),
-		},
-		{
-			Key:   "key",
-			Value: dimension.ExpectedDimensionValue{Value: aws.String("value")},
-		},
-	}
-	split := strings.Split(metricName, "_")
-	if len(split) != 3 {
-		log.Printf("unexpected metric name format, %s", metricName)
-	}
-	metricType := split[1]
-	instructions = append(instructions, dimension.Instruction{
-		// CWA adds this metric_type dimension.
-		Key:   "metric_type",
-		Value: dimension.ExpectedDimensionValue{Value: aws.String(metricType)},
-	})
-	dims, failed := dimFactory.GetDimensions(instructions)
-	if len(failed) > 0 {
+	metricType, dims, err := statsdDimensions(dimFactory, dimensionKey, metricName)
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 	fetcher := MetricValueFetcher{}
@@ -71,7 +53,7 @@ func ValidateStatsdMetric(dimFactory dimension.Factory, namespace string, dimens
 
 	lowerBound := int(runDuration/statsdMetricsAggregationInterval) - 4
 	if len(values) < lowerBound {
-		log.Printf("fail: lowerBound %v, actual %v", lowerBound, len(values))
+		logger.Errorf("fail: lowerBound %v, actual %v", lowerBound, len(values))
 		return testResult
 	}
 	// Counters get summed up over the metrics_collection_interval.
@@ -101,3 +83,81 @@ func ValidateStatsdMetric(dimFactory dimension.Factory, namespace string, dimens
 	testResult.Status = status.SUCCESSFUL
 	return testResult
 }
+
+// FetchStatsdSampleCount returns the number of samples CloudWatch received for the statsd metric between start and end
+func FetchStatsdSampleCount(dimFactory dimension.Factory, namespace string, dimensionKey string, metricName string, start, end time.Time) (int64, error) {
+	_, dims, err := statsdDimensions(dimFactory, dimensionKey, metricName)
+	if err != nil {
+		return 0, err
+	}
+	fetcher := MetricValueFetcher{}
+	values, err := fetcher.FetchWindow(namespace, metricName, dims, SAMPLE_COUNT, HighResolutionStatPeriod, start, end)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, v := range values {
+		count += int64(v)
+	}
+	return count, nil
+}
+
+// StatsdTimingPercentiles are the percentiles ValidateStatsdDistribution reconstructs from the timings sent
+var StatsdTimingPercentiles = []float64{50, 90, 99}
+
+// ValidateStatsdDistribution compares the distribution CloudWatch aggregated for the statsd timing over the window with
+// the distribution of the values that were sent in it. The agent attributes a timing to the collection that follows
+// it, so the sample count may be off by what is sent in one collection interval.
+func ValidateStatsdDistribution(dimFactory dimension.Factory, namespace string, dimensionKey string, metricName string, expected Distribution, start, end time.Time) status.TestResult {
+	testResult := status.TestResult{
+		Name:     metricName + " distribution",
+		Status:   status.FAILED,
+		Expected: expected.String(),
+	}
+	_, dims, err := statsdDimensions(dimFactory, dimensionKey, metricName)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+	fetcher := MetricValueFetcher{}
+	actual, err := fetcher.FetchDistribution(namespace, metricName, dims, start, end, StatsdTimingPercentiles...)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Actual = actual.String()
+	countTolerance := float64(statsdMetricsCollectionInterval) / float64(end.Sub(start))
+	if err = actual.Validate(expected, countTolerance, DistributionValueTolerance); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// statsdDimensions returns the metric type in the name of the statsd metric and the dimensions the agent adds to it
+func statsdDimensions(dimFactory dimension.Factory, dimensionKey string, metricName string) (string, []types.Dimension, error) {
+	instructions := []dimension.Instruction{
+		{
+			Key:   dimensionKey,
+			Value: dimension.UnknownDimensionValue(),
+		},
+		{
+			Key:   "key",
+			Value: dimension.ExpectedDimensionValue{Value: aws.String("value")},
+		},
+	}
+	split := strings.Split(metricName, "_")
+	if len(split) != 3 {
+		logger.Warnf("unexpected metric name format, %s", metricName)
+	}
+	metricType := split[1]
+	instructions = append(instructions, dimension.Instruction{
+		// CWA adds this metric_type dimension.
+		Key:   "metric_type",
+		Value: dimension.ExpectedDimensionValue{Value: aws.String(metricType)},
+	})
+	dims, err := dimFactory.GetDimensions(instructions)
+	return metricType, dims, err
+}
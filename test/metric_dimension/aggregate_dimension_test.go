@@ -16,19 +16,10 @@ type OneAggregateDimensionTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*OneAggregateDimensionTestRunner)(nil)
+var _ test_runner.MetricRunner = (*OneAggregateDimensionTestRunner)(nil)
 
 func (t *OneAggregateDimensionTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateNoAppendDimensionMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *OneAggregateDimensionTestRunner) GetTestName() string {
@@ -43,15 +34,16 @@ func (t *OneAggregateDimensionTestRunner) GetMeasuredMetrics() []string {
 	return []string{"cpu_time_active", "cpu_time_guest"}
 }
 
-func (t *OneAggregateDimensionTestRunner) validateNoAppendDimensionMetric(metricName string) status.TestResult {
+func (t *OneAggregateDimensionTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{})
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -12,25 +12,17 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"log"
+	"time"
 )
 
 type GlobalAppendDimensionsTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*GlobalAppendDimensionsTestRunner)(nil)
+var _ test_runner.MetricRunner = (*GlobalAppendDimensionsTestRunner)(nil)
 
 func (t *GlobalAppendDimensionsTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateGlobalAppendDimensionMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *GlobalAppendDimensionsTestRunner) GetTestName() string {
@@ -45,32 +37,19 @@ func (t *GlobalAppendDimensionsTestRunner) GetMeasuredMetrics() []string {
 	return []string{"cpu_time_active"}
 }
 
-func (t *GlobalAppendDimensionsTestRunner) validateGlobalAppendDimensionMetric(metricName string) status.TestResult {
+func (t *GlobalAppendDimensionsTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	expDims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
-		{
-			Key:   "ImageId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-		{
-			Key:   "InstanceId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-		{
-			Key:   "InstanceType",
-			Value: dimension.UnknownDimensionValue(),
-		},
-		{
-			Key:   "cpu",
-			Value: dimension.ExpectedDimensionValue{aws.String("cpu-total")},
-		},
-	})
+	expDims, err := t.DimensionFactory.GetDimensions(dimension.AppendDimensionInstructions(dimension.Instruction{
+		Key:   "cpu",
+		Value: dimension.ExpectedDimensionValue{aws.String("cpu-total")},
+	}))
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -87,7 +66,7 @@ func (t *GlobalAppendDimensionsTestRunner) validateGlobalAppendDimensionMetric(m
 
 	// this is making sure once dimensions in "append_dimensions" are tagged, the agent does drop the
 	// host dimension. We should not see the same metrics with host dimension anymore
-	dropDims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dropDims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "host",
 			Value: dimension.UnknownDimensionValue(),
@@ -98,12 +77,13 @@ func (t *GlobalAppendDimensionsTestRunner) validateGlobalAppendDimensionMetric(m
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	values, err = fetcher.Fetch("MetricGlobalAppendDimensionTest", metricName, dropDims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	if err != nil || len(values) != 0 {
+	if err = fetcher.AssertNotPublished("MetricGlobalAppendDimensionTest", metricName, dropDims, time.Now().Add(-10*time.Minute)); err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
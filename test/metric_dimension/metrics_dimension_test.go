@@ -14,6 +14,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -27,7 +28,7 @@ func (suite *MetricsAppendDimensionTestSuite) SetupSuite() {
 }
 
 func (suite *MetricsAppendDimensionTestSuite) TearDownSuite() {
-	suite.Result.Print()
+	report.Send(suite.Result)
 	fmt.Println(">>>> Finished MetricAppendDimensionTestSuite")
 }
 
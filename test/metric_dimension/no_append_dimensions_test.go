@@ -20,19 +20,10 @@ type NoAppendDimensionTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*NoAppendDimensionTestRunner)(nil)
+var _ test_runner.MetricRunner = (*NoAppendDimensionTestRunner)(nil)
 
 func (t *NoAppendDimensionTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateNoAppendDimensionMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *NoAppendDimensionTestRunner) GetTestName() string {
@@ -47,13 +38,13 @@ func (t *NoAppendDimensionTestRunner) GetMeasuredMetrics() []string {
 	return []string{"cpu_time_active"}
 }
 
-func (t *NoAppendDimensionTestRunner) validateNoAppendDimensionMetric(metricName string) status.TestResult {
+func (t *NoAppendDimensionTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "host",
 			Value: dimension.UnknownDimensionValue(),
@@ -64,7 +55,8 @@ func (t *NoAppendDimensionTestRunner) validateNoAppendDimensionMetric(metricName
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
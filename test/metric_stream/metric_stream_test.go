@@ -0,0 +1,143 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_stream
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/suitelock"
+)
+
+const (
+	namespace = "MetricStreamTest"
+	// streamTimeout covers the delay of the metric stream and the firehose buffer interval
+	streamTimeout      = 10 * time.Minute
+	streamPollInterval = 30 * time.Second
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// MetricStreamTestRunner streams the namespace of the agent to a firehose delivery stream writing to s3 and validates
+// the agent metrics are in the stream output, which covers customers exporting the agent metrics downstream
+type MetricStreamTestRunner struct {
+	test_runner.BaseTestRunner
+	firehoseArn string
+	roleArn     string
+	bucket      string
+	streamName  string
+	start       time.Time
+}
+
+var _ test_runner.MetricRunner = (*MetricStreamTestRunner)(nil)
+
+func (t *MetricStreamTestRunner) Validate() status.TestGroupResult {
+	return test_runner.ValidateEachMetric(t)
+}
+
+func (t *MetricStreamTestRunner) GetTestName() string {
+	return namespace
+}
+
+func (t *MetricStreamTestRunner) GetAgentConfigFileName() string {
+	return "config.json"
+}
+
+func (t *MetricStreamTestRunner) GetMeasuredMetrics() []string {
+	return []string{"cpu_usage_idle", "mem_used_percent"}
+}
+
+// SetupBeforeAgentRun creates the metric stream before the agent starts, so the first metrics of the agent are streamed
+func (t *MetricStreamTestRunner) SetupBeforeAgentRun() error {
+	t.start = time.Now()
+	t.streamName = runid.Name("metric-stream")
+	if err := awsservice.PutMetricStream(t.streamName, t.firehoseArn, t.roleArn, []string{ns.Scope(namespace)}); err != nil {
+		t.streamName = ""
+		return err
+	}
+	return t.SetUpConfig()
+}
+
+// Cleanup deletes the metric stream if SetupBeforeAgentRun created it
+func (t *MetricStreamTestRunner) Cleanup() {
+	if t.streamName == "" {
+		return
+	}
+	if err := awsservice.DeleteMetricStream(t.streamName); err != nil {
+		logger.Errorf("Failed to delete metric stream %s: %v", t.streamName, err)
+	}
+	t.streamName = ""
+}
+
+func (t *MetricStreamTestRunner) ValidateMetric(metricName string) status.TestResult {
+	testResult := status.TestResult{
+		Name:     metricName,
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("record of %s in s3://%s", metricName, t.bucket),
+	}
+
+	instanceId := awsservice.GetInstanceId()
+	err := await.WaitUntil(context.Background(), streamPollInterval, streamTimeout, testResult.Expected, func() (bool, error) {
+		records, err := awsservice.GetMetricStreamRecords(t.bucket, t.streamName, t.start)
+		if err != nil {
+			return false, err
+		}
+		for _, record := range records {
+			if record.Namespace == ns.Resolve(namespace) && record.MetricName == metricName && record.Dimensions["InstanceId"] == instanceId {
+				testResult.Actual = fmt.Sprintf("%v", record.Value)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func TestMetricStream(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	if env.MetricStreamFirehoseArn == "" || env.MetricStreamRoleArn == "" || env.MetricStreamBucket == "" {
+		t.Skip("-metricStreamFirehoseArn, -metricStreamRoleArn and -metricStreamBucket are not set")
+	}
+	factory := dimension.GetDimensionFactory(*env)
+	// concurrent runs deliver to the same firehose and bucket
+	release, err := suitelock.Acquire("MetricStream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	runner := test_runner.TestRunner{TestRunner: &MetricStreamTestRunner{
+		BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory},
+		firehoseArn:    env.MetricStreamFirehoseArn,
+		roleArn:        env.MetricStreamRoleArn,
+		bucket:         env.MetricStreamBucket,
+	}}
+	result := runner.Run()
+	if result.GetStatus() != status.SUCCESSFUL {
+		result.Print()
+		t.Fatal("Metric stream test failed")
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+import java.lang.management.ManagementFactory;
+import java.util.concurrent.atomic.AtomicLong;
+import javax.management.MBeanServer;
+import javax.management.ObjectName;
+
+/**
+ * JmxTestApp registers MBeans shaped like the ones of Tomcat, so the tomcat target system of the agent has something
+ * to collect without installing Tomcat, and then sleeps. The JVM MBeans are registered by the platform.
+ * It is run with the single-file source launcher of Java 11+, e.g. java JmxTestApp.java
+ */
+public class JmxTestApp {
+    public interface ManagerMBean {
+        int getActiveSessions();
+    }
+
+    public static class Manager implements ManagerMBean {
+        public int getActiveSessions() {
+            return 3;
+        }
+    }
+
+    public interface GlobalRequestProcessorMBean {
+        long getRequestCount();
+
+        long getErrorCount();
+
+        long getBytesSent();
+
+        long getBytesReceived();
+
+        long getProcessingTime();
+
+        long getMaxTime();
+    }
+
+    public static class GlobalRequestProcessor implements GlobalRequestProcessorMBean {
+        private final AtomicLong requests = new AtomicLong();
+
+        public long getRequestCount() {
+            return requests.addAndGet(10);
+        }
+
+        public long getErrorCount() {
+            return 0;
+        }
+
+        public long getBytesSent() {
+            return requests.get() * 100;
+        }
+
+        public long getBytesReceived() {
+            return requests.get() * 10;
+        }
+
+        public long getProcessingTime() {
+            return requests.get();
+        }
+
+        public long getMaxTime() {
+            return 1;
+        }
+    }
+
+    public static void main(String[] args) throws Exception {
+        MBeanServer server = ManagementFactory.getPlatformMBeanServer();
+        server.registerMBean(new Manager(), new ObjectName("Catalina:type=Manager,host=localhost,context=/jmx-test"));
+        server.registerMBean(new GlobalRequestProcessor(), new ObjectName("Catalina:type=GlobalRequestProcessor,name=http-jmx-test"));
+        Thread.sleep(Long.MAX_VALUE);
+    }
+}
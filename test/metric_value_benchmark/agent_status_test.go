@@ -0,0 +1,125 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// AgentStatusTestRunner cycles the agent through a reload, a stop and a start, and validates after each of them that
+// amazon-cloudwatch-agent-ctl -a status reports what was done to the agent
+type AgentStatusTestRunner struct {
+	test_runner.BaseTestRunner
+	started time.Time
+	results []status.TestResult
+}
+
+var _ test_runner.ITestRunner = (*AgentStatusTestRunner)(nil)
+
+func (t *AgentStatusTestRunner) Validate() status.TestGroupResult {
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: t.results,
+	}
+}
+
+func (t *AgentStatusTestRunner) GetTestName() string {
+	return "AgentStatus"
+}
+
+func (t *AgentStatusTestRunner) GetAgentConfigFileName() string {
+	return "agent_status_config.json"
+}
+
+func (t *AgentStatusTestRunner) GetMeasuredMetrics() []string {
+	return []string{"mem_used_percent"}
+}
+
+func (t *AgentStatusTestRunner) SetupBeforeAgentRun() error {
+	t.results = nil
+	err := t.BaseTestRunner.SetupBeforeAgentRun()
+	t.started = time.Now()
+	return err
+}
+
+// SetupAfterAgentRun runs the cycles while the agent runs, it is running again once they are done
+func (t *AgentStatusTestRunner) SetupAfterAgentRun() error {
+	t.results = append(t.results, t.validateStatus("started", true, t.started))
+
+	reloaded := time.Now()
+	if err := common.StartAgent(common.ConfigOutputPath, false, false); err != nil {
+		return fmt.Errorf("failed to reload the agent: %w", err)
+	}
+	t.results = append(t.results, t.validateStatus("reloaded", true, reloaded))
+
+	common.StopAgent()
+	t.results = append(t.results, t.validateStatus("stopped", false, time.Time{}))
+
+	restarted := time.Now()
+	if err := common.StartAgent(common.ConfigOutputPath, false, false); err != nil {
+		return fmt.Errorf("failed to start the stopped agent: %w", err)
+	}
+	t.results = append(t.results, t.validateStatus("restarted", true, restarted))
+	return nil
+}
+
+// validateStatus expects the agent is running since the time it was (re)started, or is stopped. The config stays
+// configured either way, stopping the agent keeps its config.
+func (t *AgentStatusTestRunner) validateStatus(step string, running bool, since time.Time) status.TestResult {
+	testResult := status.TestResult{
+		Name:   "Agent Status " + step,
+		Status: status.FAILED,
+	}
+	s, err := common.GetAgentStatus()
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	logger.Infof("Agent status after it was %s: %+v", step, s)
+	testResult.Actual = fmt.Sprintf("%s since %s, %s, version %s", s.Status, s.StartTime.Format(time.RFC3339), s.ConfigStatus, s.Version)
+
+	if err = t.checkStatus(s, running, since); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (t *AgentStatusTestRunner) checkStatus(s common.AgentStatus, running bool, since time.Time) error {
+	if s.ConfigStatus != common.AgentConfigStatusConfigured {
+		return errclass.Validationf("config status is %q, expected %q", s.ConfigStatus, common.AgentConfigStatusConfigured)
+	}
+	version, err := common.GetInstalledAgentVersion()
+	if err != nil {
+		return err
+	}
+	if s.Version != version {
+		return errclass.Validationf("version is %q, expected the installed %q", s.Version, version)
+	}
+	if !running {
+		if s.Status != common.AgentStatusStopped {
+			return errclass.Validationf("status is %q, expected %q", s.Status, common.AgentStatusStopped)
+		}
+		return nil
+	}
+	if !s.Running() {
+		return errclass.Validationf("status is %q, expected %q", s.Status, common.AgentStatusRunning)
+	}
+	// the start time is reported to the second
+	if s.StartTime.Before(since.Truncate(time.Second)) || s.StartTime.After(time.Now()) {
+		return errclass.Validationf("start time is %s, expected the agent to have started since %s", s.StartTime.Format(time.RFC3339), since.Format(time.RFC3339))
+	}
+	return nil
+}
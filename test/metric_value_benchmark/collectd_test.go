@@ -22,19 +22,10 @@ type CollectDTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*CollectDTestRunner)(nil)
+var _ test_runner.MetricRunner = (*CollectDTestRunner)(nil)
 
 func (t *CollectDTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateCollectDMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *CollectDTestRunner) GetTestName() string {
@@ -53,7 +44,7 @@ func (t *CollectDTestRunner) GetMeasuredMetrics() []string {
 	return []string{"collectd_gauge_1_value", "collectd_counter_1_value"}
 }
 
-func (t *CollectDTestRunner) validateCollectDMetric(metricName string) status.TestResult {
+func (t *CollectDTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
@@ -80,14 +71,17 @@ func (t *CollectDTestRunner) validateCollectDMetric(metricName string) status.Te
 		})
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions(instructions)
-	if len(failed) > 0 {
+	dims, err := t.DimensionFactory.GetDimensions(instructions)
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
+	testResult.SetDimensions(dims)
 	fetcher := metric.MetricValueFetcher{}
 	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -107,6 +101,7 @@ func (t *CollectDTestRunner) validateCollectDMetric(metricName string) status.Te
 	}
 
 	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 1) {
+		testResult.SetValueMismatch(metric.DescribeExpectedValue(1), values)
 		return testResult
 	}
 
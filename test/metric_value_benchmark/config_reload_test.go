@@ -0,0 +1,175 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	configReloadNewConfig = "config_reload_new_config.json"
+	// configReloadBefore is how long the agent runs with the original config before it is reloaded
+	configReloadBefore = time.Minute
+	// configReloadTimeout is how long a reload may take until the added metric is published
+	configReloadTimeout = 2 * time.Minute
+
+	configReloadKept    = "mem_used_percent"
+	configReloadAdded   = "mem_available_percent"
+	configReloadRemoved = "mem_cached"
+)
+
+// ConfigReloadTestRunner applies a modified config with fetch-config while the agent runs, and validates the metric
+// it adds is published, the one it removes is not anymore and the one both configs collect keeps flowing
+type ConfigReloadTestRunner struct {
+	test_runner.BaseTestRunner
+	reloadStart time.Time
+	reloadDone  time.Time
+}
+
+var _ test_runner.ITestRunner = (*ConfigReloadTestRunner)(nil)
+
+func (t *ConfigReloadTestRunner) Validate() status.TestGroupResult {
+	return status.TestGroupResult{
+		Name: t.GetTestName(),
+		TestResults: []status.TestResult{
+			t.validateAdded(),
+			t.validateRemoved(),
+			t.validateKept(),
+		},
+	}
+}
+
+func (t *ConfigReloadTestRunner) GetTestName() string {
+	return "ConfigReload"
+}
+
+func (t *ConfigReloadTestRunner) GetAgentConfigFileName() string {
+	return "config_reload_config.json"
+}
+
+func (t *ConfigReloadTestRunner) GetAgentRunDuration() time.Duration {
+	return configReloadTimeout + time.Minute
+}
+
+func (t *ConfigReloadTestRunner) GetMeasuredMetrics() []string {
+	return []string{configReloadKept, configReloadAdded, configReloadRemoved}
+}
+
+// SetupAfterAgentRun lets the agent publish with the original config, then applies the new one the same way users
+// do, fetch-config on the running agent
+func (t *ConfigReloadTestRunner) SetupAfterAgentRun() error {
+	time.Sleep(configReloadBefore)
+
+	common.CopyFile(filepath.Join("agent_configs", configReloadNewConfig), common.ConfigOutputPath)
+	if err := common.ScopeConfigNamespace(common.ConfigOutputPath); err != nil {
+		return fmt.Errorf("failed to scope the namespace of config %s: %w", configReloadNewConfig, err)
+	}
+	t.reloadStart = time.Now()
+	if err := common.StartAgent(common.ConfigOutputPath, false, false); err != nil {
+		return fmt.Errorf("failed to reload the agent with config %s: %w", configReloadNewConfig, err)
+	}
+	t.reloadDone = time.Now()
+	logger.Infof("fetch-config with %s took %s", configReloadNewConfig, t.reloadDone.Sub(t.reloadStart).Round(time.Millisecond))
+	return nil
+}
+
+// validateAdded records how long the reload took to take effect as the duration of the result, from fetch-config to
+// the first datapoint of the added metric
+func (t *ConfigReloadTestRunner) validateAdded() status.TestResult {
+	testResult := status.TestResult{
+		Name:     configReloadAdded + " added",
+		Status:   status.FAILED,
+		Expected: fmt.Sprintf("first datapoint within %s of the reload", configReloadTimeout),
+	}
+	dims, err := t.instanceDimensions()
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	fetcher := metric.MetricValueFetcher{}
+	first, err := fetcher.FirstDatapoint(namespace, configReloadAdded, dims, t.reloadStart)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Duration = first.Sub(t.reloadStart)
+	testResult.Actual = fmt.Sprintf("first datapoint %s after the reload", testResult.Duration.Round(time.Second))
+	logger.Infof("Config reload took effect after %s", testResult.Duration.Round(time.Second))
+	if testResult.Duration > configReloadTimeout {
+		testResult.Reason = "the reload took too long to take effect"
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateRemoved only looks at datapoints after the reload finished, the agent flushes what it collected with the
+// original config when it stops
+func (t *ConfigReloadTestRunner) validateRemoved() status.TestResult {
+	testResult := status.TestResult{
+		Name:     configReloadRemoved + " removed",
+		Status:   status.FAILED,
+		Expected: "no datapoints after the reload",
+	}
+	dims, err := t.instanceDimensions()
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	fetcher := metric.MetricValueFetcher{}
+	if err = fetcher.AssertNotPublished(namespace, configReloadRemoved, dims, t.reloadDone); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (t *ConfigReloadTestRunner) validateKept() status.TestResult {
+	testResult := status.TestResult{
+		Name:   configReloadKept + " kept",
+		Status: status.FAILED,
+	}
+	dims, err := t.instanceDimensions()
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	fetcher := metric.MetricValueFetcher{}
+	if _, err = fetcher.FirstDatapoint(namespace, configReloadKept, dims, t.reloadDone); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	if !test_runner.ValidateMetricValues(&testResult, namespace, configReloadKept, dims, 0) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (t *ConfigReloadTestRunner) instanceDimensions() ([]types.Dimension, error) {
+	return t.DimensionFactory.GetDimensions([]dimension.Instruction{instanceIdInstruction})
+}
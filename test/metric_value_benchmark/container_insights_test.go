@@ -14,7 +14,6 @@ import (
 	"github.com/qri-io/jsonschema"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -54,6 +53,24 @@ func (t *ContainerInsightsTestRunner) GetAgentConfigFileName() string {
 	return "./agent_configs/container_insights.json"
 }
 
+// WindowsContainerInsightsTestRunner validates the same container instance metrics and performance logs as
+// ContainerInsightsTestRunner for a cluster of Windows container instances
+type WindowsContainerInsightsTestRunner struct {
+	ContainerInsightsTestRunner
+}
+
+var _ test_runner.ITestRunner = (*WindowsContainerInsightsTestRunner)(nil)
+
+func (t *WindowsContainerInsightsTestRunner) Validate() status.TestGroupResult {
+	result := t.ContainerInsightsTestRunner.Validate()
+	result.Name = t.GetTestName()
+	return result
+}
+
+func (t *WindowsContainerInsightsTestRunner) GetTestName() string {
+	return "WindowsContainerInstance"
+}
+
 func (t *ContainerInsightsTestRunner) getAgentRunDuration() time.Duration {
 	return time.Minute
 }
@@ -71,7 +88,7 @@ func (t *ContainerInsightsTestRunner) validateContainerInsightsMetrics(metricNam
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "ClusterName",
 			Value: dimension.UnknownDimensionValue(),
@@ -86,17 +103,12 @@ func (t *ContainerInsightsTestRunner) validateContainerInsightsMetrics(metricNam
 		},
 	})
 
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch("ECS/ContainerInsights", metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, "ECS/ContainerInsights", metricName, dims, 0) {
 		return testResult
 	}
 
@@ -131,7 +143,7 @@ func validateLogsForContainerInsights(e *environment.MetaData) status.TestResult
 
 		var ok bool
 		stream := fmt.Sprintf("NodeTelemetry-%s", container.ContainerInstanceId)
-		ok, err = awsservice.ValidateLogs(group, stream, nil, &now, func(logs []string) bool {
+		ok, err = awsservice.ValidateLogs(group, stream, nil, &now, awsservice.StringValidator(func(logs []string) bool {
 			if len(logs) < 1 {
 				return false
 			}
@@ -142,7 +154,7 @@ func validateLogsForContainerInsights(e *environment.MetaData) status.TestResult
 				}
 			}
 			return true
-		})
+		}))
 
 		if err != nil || !ok {
 			return testResult
@@ -6,33 +6,43 @@
 package metric_value_benchmark
 
 import (
-	"log"
-
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
 )
 
+// cpuGroundTruth are the metrics compared with /proc/stat, the host is read at other times than the agent collects
+var cpuGroundTruth = map[string]metric.HostReading{
+	"cpu_usage_active": {Absolute: 10},
+	"cpu_usage_idle":   {Absolute: 10},
+}
+
 type CPUTestRunner struct {
 	test_runner.BaseTestRunner
+	host   *groundtruth.Collector
+	series groundtruth.Series
 }
 
-var _ test_runner.ITestRunner = (*CPUTestRunner)(nil)
+var _ test_runner.MetricRunner = (*CPUTestRunner)(nil)
 
 func (t *CPUTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateCpuMetric(metricName)
-	}
+	t.series = t.host.Stop()
+	return test_runner.ValidateEachMetric(t)
+}
 
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+// SetupAfterAgentRun reads the host while the agent runs
+func (t *CPUTestRunner) SetupAfterAgentRun() error {
+	t.host = groundtruth.Start(groundTruthInterval)
+	return nil
+}
+
+func (t *CPUTestRunner) Cleanup() {
+	t.host.Stop()
 }
 
 func (t *CPUTestRunner) GetTestName() string {
@@ -48,13 +58,13 @@ func (t *CPUTestRunner) GetMeasuredMetrics() []string {
 	return append(metric.CpuMetrics[1:], "cpu_time_active_renamed")
 }
 
-func (t *CPUTestRunner) validateCpuMetric(metricName string) status.TestResult {
+func (t *CPUTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -65,25 +75,35 @@ func (t *CPUTestRunner) validateCpuMetric(metricName string) status.TestResult {
 		},
 	})
 
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	log.Printf("metric values are %v", values)
 	if err != nil {
-		log.Printf("err: %v\n", err)
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
 	// TODO: Range test with >0 and <100
 	// TODO: Range test: which metric to get? api reference check. should I get average or test every single datapoint for 10 minutes? (and if 90%> of them are in range, we are good)
 
+	// the rename keeps the unit of the original measurement
+	var unit types.StandardUnit
+	if metricName == "cpu_time_active_renamed" {
+		unit = metric.ExpectedUnits["cpu_time_active"]
+	}
+	fetcher := metric.MetricValueFetcher{}
+	if err = fetcher.ValidateUnit(namespace, metricName, dims, unit); err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if truth, ok := cpuGroundTruth[metricName]; ok {
+		if !(test_runner.GroundTruth{Series: t.series, HostReading: truth}).Validate(&testResult, namespace, metricName, dims) {
+			return testResult
+		}
+	}
+
 	testResult.Status = status.SUCCESSFUL
 	return testResult
 }
@@ -6,8 +6,6 @@
 package metric_value_benchmark
 
 import (
-	"log"
-
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
@@ -18,19 +16,10 @@ type DiskTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*DiskTestRunner)(nil)
+var _ test_runner.MetricRunner = (*DiskTestRunner)(nil)
 
 func (t *DiskTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.ValidateDiskMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *DiskTestRunner) GetTestName() string {
@@ -53,32 +42,31 @@ func (t *DiskTestRunner) GetMeasuredMetrics() []string {
 	}
 }
 
-func (t *DiskTestRunner) ValidateDiskMetric(metricName string) status.TestResult {
+func (t *DiskTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-
-	log.Printf("metric values are %v", values)
-	if err != nil {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	fetcher := metric.MetricValueFetcher{}
+	if err = fetcher.ValidateUnit(namespace, metricName, dims, ""); err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
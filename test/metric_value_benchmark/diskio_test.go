@@ -6,31 +6,56 @@
 package metric_value_benchmark
 
 import (
+	"os"
+
 	"github.com/aws/aws-sdk-go-v2/aws"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
 )
 
+// diskioLoadBytes are written to the root volume while the agent runs
+const diskioLoadBytes = 64 * 1024 * 1024
+
+// diskioCounters are the counters of diskio by how much the load of SetupAfterAgentRun increases them at least
+var diskioCounters = map[string]float64{
+	"diskio_io_time":     0,
+	"diskio_reads":       0,
+	"diskio_read_bytes":  0,
+	"diskio_read_time":   0,
+	"diskio_writes":      1,
+	"diskio_write_bytes": diskioLoadBytes,
+	"diskio_write_time":  0,
+}
+
+// diskioDevice is the root volume, which the load is written to
+const diskioDevice = "nvme0n1"
+
+// diskioGroundTruth are the counters compared with /proc/diskstats, the window of the host reads is not aligned with
+// the periods of the datapoints so a little of the activity before and after it is counted differently
+var diskioGroundTruth = map[string]metric.HostReading{
+	"diskio_writes":      {Counter: true, Key: groundtruth.DeviceKey("diskio_writes", diskioDevice), Absolute: 100, Relative: 0.25},
+	"diskio_write_bytes": {Counter: true, Key: groundtruth.DeviceKey("diskio_write_bytes", diskioDevice), Absolute: 8 * 1024 * 1024, Relative: 0.25},
+}
+
 type DiskIOTestRunner struct {
 	test_runner.BaseTestRunner
+	host   *groundtruth.Collector
+	series groundtruth.Series
 }
 
-var _ test_runner.ITestRunner = (*DiskIOTestRunner)(nil)
+var _ test_runner.MetricRunner = (*DiskIOTestRunner)(nil)
 
 func (m *DiskIOTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateDiskMetric(name)
-	}
+	m.series = m.host.Stop()
+	return test_runner.ValidateEachMetric(m)
+}
 
-	return status.TestGroupResult{
-		Name:        m.GetTestName(),
-		TestResults: testResults,
-	}
+func (m *DiskIOTestRunner) Cleanup() {
+	m.host.Stop()
 }
 
 func (m *DiskIOTestRunner) GetTestName() string {
@@ -41,22 +66,42 @@ func (m *DiskIOTestRunner) GetAgentConfigFileName() string {
 	return "diskio_config.json"
 }
 
+// SetupAfterAgentRun writes diskioLoadBytes to the root volume and syncs them, so the write counters have a known load.
+// The host is read from before the load until Validate.
+func (m *DiskIOTestRunner) SetupAfterAgentRun() error {
+	m.host = groundtruth.Start(groundTruthInterval)
+	f, err := os.CreateTemp("/var/tmp", "cwagent-diskio")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	chunk := make([]byte, 1024*1024)
+	for written := 0; written < diskioLoadBytes; written += len(chunk) {
+		if _, err = f.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return f.Sync()
+}
+
 func (m *DiskIOTestRunner) GetMeasuredMetrics() []string {
 	return []string{
 		"diskio_iops_in_progress", "diskio_io_time", "diskio_reads", "diskio_read_bytes", "diskio_read_time",
 		"diskio_writes", "diskio_write_bytes", "diskio_write_time"}
 }
 
-func (m *DiskIOTestRunner) validateDiskMetric(metricName string) status.TestResult {
+func (m *DiskIOTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := m.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "name",
-			Value: dimension.ExpectedDimensionValue{aws.String("nvme0n1")},
+			Value: dimension.ExpectedDimensionValue{aws.String(diskioDevice)},
 		},
 		{
 			Key:   "InstanceId",
@@ -64,20 +109,33 @@ func (m *DiskIOTestRunner) validateDiskMetric(metricName string) status.TestResu
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	if err != nil {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	fetcher := metric.MetricValueFetcher{}
+	if err = fetcher.ValidateUnit(namespace, metricName, dims, ""); err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
+	if minDelta, ok := diskioCounters[metricName]; ok {
+		if !(test_runner.Counter{MinDelta: minDelta}).Validate(&testResult, namespace, metricName, dims) {
+			return testResult
+		}
+	}
+
+	if truth, ok := diskioGroundTruth[metricName]; ok {
+		if !(test_runner.GroundTruth{Series: m.series, HostReading: truth}).Validate(&testResult, namespace, metricName, dims) {
+			return testResult
+		}
+	}
+
 	testResult.Status = status.SUCCESSFUL
 	return testResult
 }
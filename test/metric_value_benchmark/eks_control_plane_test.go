@@ -0,0 +1,158 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+)
+
+// controlPlaneMetrics are scraped from the apiserver by enhanced Container Insights. Most are published per resource,
+// verb or code as well, so they are validated for the first dimension set listed with the cluster.
+var controlPlaneMetrics = []string{
+	"apiserver_storage_objects",
+	"apiserver_request_total",
+	"apiserver_storage_size_bytes",
+	"etcd_request_duration_seconds",
+	"rest_client_requests_total",
+}
+
+// nodeConditionMetrics are the kube-state node metrics and the minimum value expected of a healthy node
+var nodeConditionMetrics = []struct {
+	name     string
+	expected float64
+}{
+	{"node_status_condition_ready", 1},
+	{"node_status_condition_memory_pressure", 0},
+	{"node_status_condition_disk_pressure", 0},
+	{"node_status_condition_pid_pressure", 0},
+	{"node_status_capacity_pods", 1},
+	{"node_status_allocatable_pods", 1},
+}
+
+// EKSControlPlaneTestRunner validates the control plane and kube-state metrics of enhanced Container Insights. The
+// node of the node metrics is resolved from the Kubernetes API by dimension.KubernetesNodeDimensionProvider.
+type EKSControlPlaneTestRunner struct {
+	test_runner.BaseTestRunner
+	env *environment.MetaData
+}
+
+var _ test_runner.ITestRunner = (*EKSControlPlaneTestRunner)(nil)
+
+func (e *EKSControlPlaneTestRunner) Validate() status.TestGroupResult {
+	testResults := make([]status.TestResult, 0, len(controlPlaneMetrics)+len(nodeConditionMetrics))
+	for _, name := range controlPlaneMetrics {
+		testResults = append(testResults, e.validateControlPlaneMetric(name))
+	}
+	for _, m := range nodeConditionMetrics {
+		testResults = append(testResults, e.validateNodeConditionMetric(m.name, m.expected))
+	}
+	return status.TestGroupResult{
+		Name:        e.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (e *EKSControlPlaneTestRunner) validateControlPlaneMetric(name string) status.TestResult {
+	testResult := status.TestResult{
+		Name:   name,
+		Status: status.FAILED,
+	}
+
+	dims, err := e.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "ClusterName",
+			Value: dimension.UnknownDimensionValue(),
+		},
+	})
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	listFetcher := metric.MetricListFetcher{}
+	metrics, err := listFetcher.Fetch(containerInsightsNamespace, name, dims)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+	if len(metrics) < 1 {
+		testResult.Reason = "metric is not published for the cluster"
+		return testResult
+	}
+
+	// apiserver metrics such as request counts can be 0 for a dimension set, so only their presence is required
+	if !test_runner.ValidateMetricValues(&testResult, containerInsightsNamespace, name, metrics[0].Dimensions, 0) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (e *EKSControlPlaneTestRunner) validateNodeConditionMetric(name string, expected float64) status.TestResult {
+	testResult := status.TestResult{
+		Name:   name,
+		Status: status.FAILED,
+	}
+
+	dims, err := e.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "ClusterName",
+			Value: dimension.UnknownDimensionValue(),
+		},
+		{
+			Key:   "NodeName",
+			Value: dimension.UnknownDimensionValue(),
+		},
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+	})
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, containerInsightsNamespace, name, dims, expected) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (e *EKSControlPlaneTestRunner) GetTestName() string {
+	return "EKSControlPlane"
+}
+
+func (e *EKSControlPlaneTestRunner) GetAgentConfigFileName() string {
+	return ""
+}
+
+func (e *EKSControlPlaneTestRunner) GetAgentRunDuration() time.Duration {
+	return time.Minute * 3
+}
+
+func (e *EKSControlPlaneTestRunner) GetMeasuredMetrics() []string {
+	metrics := append([]string{}, controlPlaneMetrics...)
+	for _, m := range nodeConditionMetrics {
+		metrics = append(metrics, m.name)
+	}
+	return metrics
+}
+
+func (e *EKSControlPlaneTestRunner) SetAgentConfig(config test_runner.AgentConfig) {}
+
+func (e *EKSControlPlaneTestRunner) SetupAfterAgentRun() error {
+	return nil
+}
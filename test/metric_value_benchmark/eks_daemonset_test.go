@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/golden"
 )
 
 const containerInsightsNamespace = "ContainerInsights"
@@ -30,6 +32,9 @@ const containerInsightsNamespace = "ContainerInsights"
 // list of metrics with more dimensions e.g. PodName and Namespace
 var metricsWithMoreDimensions = []string{"pod_number_of_container_restarts"}
 
+// containerInsightsNormalizer reduces the performance logs to their structure, the labels are the ones of the pods
+var containerInsightsNormalizer = golden.Normalizer{Keep: []string{"Type"}, Strip: []string{"Timestamp", "labels"}}
+
 type EKSDaemonTestRunner struct {
 	test_runner.BaseTestRunner
 	env *environment.MetaData
@@ -42,7 +47,7 @@ func (e *EKSDaemonTestRunner) Validate() status.TestGroupResult {
 		testResults = append(testResults, e.validateInstanceMetrics(name))
 	}
 
-	testResults = append(testResults, e.validateLogs(e.env))
+	testResults = append(testResults, e.validateLogs(e.env), e.validateGoldenLogs(e.env))
 	return status.TestGroupResult{
 		Name:        e.GetTestName(),
 		TestResults: testResults,
@@ -55,14 +60,15 @@ func (e *EKSDaemonTestRunner) validateInstanceMetrics(name string) status.TestRe
 		Status: status.FAILED,
 	}
 
-	dims, failed := e.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := e.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "ClusterName",
 			Value: dimension.UnknownDimensionValue(),
 		},
 	})
-	if len(failed) > 0 {
-		log.Println("failed to get dimensions")
+	if err != nil {
+		log.Printf("failed to get dimensions: %v", err)
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -95,14 +101,7 @@ func (e *EKSDaemonTestRunner) validateInstanceMetrics(name string) status.TestRe
 		}
 	}
 
-	valueFetcher := metric.MetricValueFetcher{}
-	values, err := valueFetcher.Fetch(containerInsightsNamespace, name, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	if err != nil {
-		log.Println("failed to fetch metrics", err)
-		return testResult
-	}
-
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, containerInsightsNamespace, name, dims, 0) {
 		return testResult
 	}
 
@@ -133,7 +132,7 @@ func (e *EKSDaemonTestRunner) validateLogs(env *environment.MetaData) status.Tes
 
 		var ok bool
 		stream := *instance.InstanceName
-		ok, err = awsservice.ValidateLogs(group, stream, nil, &now, func(logs []string) bool {
+		ok, err = awsservice.ValidateLogs(group, stream, nil, &now, awsservice.StringValidator(func(logs []string) bool {
 			if len(logs) < 1 {
 				log.Println(fmt.Sprintf("failed to get logs for instance: %s", stream))
 				return false
@@ -160,7 +159,7 @@ func (e *EKSDaemonTestRunner) validateLogs(env *environment.MetaData) status.Tes
 				}
 			}
 			return true
-		})
+		}))
 
 		if err != nil || !ok {
 			return testResult
@@ -171,6 +170,81 @@ func (e *EKSDaemonTestRunner) validateLogs(env *environment.MetaData) status.Tes
 	return testResult
 }
 
+// validateGoldenLogs merges the structure of the performance logs of every instance by their type and diffs it
+// against the golden documents, so the fields the agent adds, removes or renames are reported by name
+func (e *EKSDaemonTestRunner) validateGoldenLogs(env *environment.MetaData) status.TestResult {
+	testResult := status.TestResult{
+		Name:   "emf-golden",
+		Status: status.FAILED,
+	}
+
+	now := time.Now()
+	group := fmt.Sprintf("/aws/containerinsights/%s/performance", env.EKSClusterName)
+	instances, err := awsservice.GetEKSInstances(env.EKSClusterName)
+	if err != nil {
+		testResult.SetError(fmt.Errorf("failed to get EKS instances: %w", err))
+		return testResult
+	}
+
+	structures := map[string]interface{}{}
+	for _, instance := range instances {
+		events, err := awsservice.GetLogEvents(group, *instance.InstanceName, nil, &now)
+		if err != nil {
+			testResult.SetError(err)
+			return testResult
+		}
+		for _, event := range events {
+			var eksClusterType awsservice.EKSClusterType
+			if err = json.Unmarshal([]byte(*event.Message), &eksClusterType); err != nil {
+				testResult.SetError(fmt.Errorf("failed to parse performance log %s: %w", *event.Message, err))
+				return testResult
+			}
+			structure, err := containerInsightsNormalizer.Normalize([]byte(*event.Message))
+			if err != nil {
+				testResult.SetError(err)
+				return testResult
+			}
+			if merged, ok := structures[eksClusterType.Type]; ok {
+				structure = golden.Merge(merged, structure)
+			}
+			structures[eksClusterType.Type] = structure
+		}
+	}
+	if len(structures) == 0 {
+		testResult.SetError(fmt.Errorf("no performance logs in %s", group))
+		return testResult
+	}
+
+	logTypes := make([]string, 0, len(structures))
+	for t := range structures {
+		logTypes = append(logTypes, t)
+	}
+	sort.Strings(logTypes)
+	var problems []string
+	for _, t := range logTypes {
+		path, ok := eks_resources.EksClusterGoldenFiles[t]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("no golden document for type %s", t))
+			continue
+		}
+		diffs, err := golden.Compare(path, structures[t])
+		if err != nil {
+			testResult.SetError(err)
+			return testResult
+		}
+		for _, d := range diffs {
+			problems = append(problems, fmt.Sprintf("%s: %s", t, d))
+		}
+	}
+	if len(problems) > 0 {
+		testResult.SetError(fmt.Errorf("the performance logs differ from the golden documents: %s", strings.Join(problems, "; ")))
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
 func (e *EKSDaemonTestRunner) GetTestName() string {
 	return "EKSContainerInstance"
 }
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -41,7 +40,7 @@ func (e *EKSDeploymentTestRunner) validateInstanceMetrics(name string) status.Te
 		Status: status.FAILED,
 	}
 
-	dims, failed := e.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := e.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "ClusterName",
 			Value: dimension.ExpectedDimensionValue{Value: aws.String(e.env.EKSClusterName)},
@@ -52,19 +51,13 @@ func (e *EKSDeploymentTestRunner) validateInstanceMetrics(name string) status.Te
 		},
 	})
 
-	if len(failed) > 0 {
-		log.Println("failed to get dimensions")
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch("ContainerInsights/Prometheus", name, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 	if err != nil {
-		log.Println("failed to fetch metrics", err)
+		log.Printf("failed to get dimensions: %v", err)
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, "ContainerInsights/Prometheus", name, dims, 0) {
 		return testResult
 	}
 
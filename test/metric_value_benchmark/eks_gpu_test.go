@@ -0,0 +1,146 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/k8s"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// gpuPodName and gpuPodNamespace match the pod terraform/eks/daemon/gpu schedules on the GPU
+	gpuPodName      = "gpu-burn"
+	gpuPodNamespace = "default"
+	// gpuResource is the extended resource the NVIDIA device plugin advertises the GPUs of a node as
+	gpuResource = "nvidia.com/gpu"
+)
+
+// gpuNodeMetrics are published by the DCGM exporter path per node, with the minimum value expected
+var gpuNodeMetrics = []struct {
+	name     string
+	expected float64
+}{
+	{"node_gpu_utilization", 0},
+	{"node_gpu_memory_utilization", 0},
+	{"node_gpu_memory_used", 0},
+	{"node_gpu_memory_total", 1},
+	{"node_gpu_power_draw", 0},
+	{"node_gpu_temperature", 0},
+}
+
+// gpuPodMetrics are published for the pod the GPU is allocated to
+var gpuPodMetrics = []string{
+	"pod_gpu_utilization",
+	"pod_gpu_memory_utilization",
+	"pod_gpu_memory_used",
+}
+
+// EKSGPUTestRunner validates the GPU metrics of Container Insights for the node and the pod the GPU is allocated to.
+// It only applies to clusters with allocatable NVIDIA GPUs.
+type EKSGPUTestRunner struct {
+	test_runner.BaseTestRunner
+	env *environment.MetaData
+}
+
+var _ test_runner.ITestRunner = (*EKSGPUTestRunner)(nil)
+var _ test_runner.Applicable = (*EKSGPUTestRunner)(nil)
+
+// IsApplicable checks the nodes of the cluster for allocatable nvidia.com/gpu resources
+func (e *EKSGPUTestRunner) IsApplicable() bool {
+	client, err := k8s.Default()
+	if err != nil {
+		logger.Warnf("failed to create the kubernetes client: %v", err)
+		return false
+	}
+	nodes, err := client.ListNodes(context.Background())
+	if err != nil {
+		logger.Warnf("failed to get the allocatable GPUs of the nodes: %v", err)
+		return false
+	}
+	for _, node := range nodes {
+		if gpus, ok := node.Status.Allocatable[gpuResource]; ok && !gpus.IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *EKSGPUTestRunner) Validate() status.TestGroupResult {
+	testResults := make([]status.TestResult, 0, len(gpuNodeMetrics)+len(gpuPodMetrics))
+	for _, m := range gpuNodeMetrics {
+		testResults = append(testResults, e.validateGPUMetric(m.name, m.expected, []dimension.Instruction{
+			{Key: "ClusterName", Value: dimension.UnknownDimensionValue()},
+			{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+			{Key: "NodeName", Value: dimension.UnknownDimensionValue()},
+		}))
+	}
+	for _, name := range gpuPodMetrics {
+		testResults = append(testResults, e.validateGPUMetric(name, 0, []dimension.Instruction{
+			{Key: "ClusterName", Value: dimension.UnknownDimensionValue()},
+			{Key: "Namespace", Value: dimension.ExpectedDimensionValue{Value: aws.String(gpuPodNamespace)}},
+			{Key: "PodName", Value: dimension.ExpectedDimensionValue{Value: aws.String(gpuPodName)}},
+		}))
+	}
+	return status.TestGroupResult{
+		Name:        e.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (e *EKSGPUTestRunner) validateGPUMetric(name string, expected float64, instructions []dimension.Instruction) status.TestResult {
+	testResult := status.TestResult{
+		Name:   name,
+		Status: status.FAILED,
+	}
+
+	dims, err := e.DimensionFactory.GetDimensions(instructions)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, containerInsightsNamespace, name, dims, expected) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (e *EKSGPUTestRunner) GetTestName() string {
+	return "EKSGPU"
+}
+
+func (e *EKSGPUTestRunner) GetAgentConfigFileName() string {
+	return ""
+}
+
+func (e *EKSGPUTestRunner) GetAgentRunDuration() time.Duration {
+	return time.Minute * 3
+}
+
+func (e *EKSGPUTestRunner) GetMeasuredMetrics() []string {
+	metrics := make([]string, 0, len(gpuNodeMetrics)+len(gpuPodMetrics))
+	for _, m := range gpuNodeMetrics {
+		metrics = append(metrics, m.name)
+	}
+	return append(metrics, gpuPodMetrics...)
+}
+
+func (e *EKSGPUTestRunner) SetAgentConfig(config test_runner.AgentConfig) {}
+
+func (e *EKSGPUTestRunner) SetupAfterAgentRun() error {
+	return nil
+}
@@ -39,4 +39,20 @@ var (
 		"Pod":              eksPodSchema,
 		"PodNet":           eksPodNetSchema,
 	}
+
+	// EksClusterGoldenFiles are the golden documents of the structure of the performance logs by their type, relative
+	// to the directory of the suite
+	EksClusterGoldenFiles = map[string]string{
+		"Cluster":          "eks_resources/golden/cluster.json",
+		"ClusterNamespace": "eks_resources/golden/cluster_namespace.json",
+		"ClusterService":   "eks_resources/golden/cluster_service.json",
+		"Container":        "eks_resources/golden/container.json",
+		"ContainerFS":      "eks_resources/golden/container_fs.json",
+		"Node":             "eks_resources/golden/node.json",
+		"NodeDiskIO":       "eks_resources/golden/node_disk_io.json",
+		"NodeFS":           "eks_resources/golden/node_fs.json",
+		"NodeNet":          "eks_resources/golden/node_net.json",
+		"Pod":              "eks_resources/golden/pod.json",
+		"PodNet":           "eks_resources/golden/pod_net.json",
+	}
 )
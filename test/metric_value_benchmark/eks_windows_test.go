@@ -0,0 +1,168 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// windowsPodName and windowsPodNamespace match the pod terraform/eks/daemon/windows schedules on the Windows node
+	windowsPodName      = "windows-logger"
+	windowsPodNamespace = "default"
+	// windowsPodLogLine is written by the pod on every iteration of its loop
+	windowsPodLogLine = "windows-logger heartbeat"
+)
+
+var windowsNodeMetrics = []string{
+	"node_cpu_utilization",
+	"node_cpu_usage_total",
+	"node_memory_utilization",
+	"node_memory_working_set",
+	"node_network_total_bytes",
+	"node_filesystem_utilization",
+	"node_number_of_running_pods",
+}
+
+var windowsPodMetrics = []string{
+	"pod_cpu_utilization",
+	"pod_memory_utilization",
+	"pod_memory_working_set",
+}
+
+// EKSWindowsTestRunner validates the Container Insights metrics of a Windows node group and the logs of the pod
+// running on it
+type EKSWindowsTestRunner struct {
+	test_runner.BaseTestRunner
+	env *environment.MetaData
+}
+
+var _ test_runner.ITestRunner = (*EKSWindowsTestRunner)(nil)
+
+func (e *EKSWindowsTestRunner) Validate() status.TestGroupResult {
+	testResults := make([]status.TestResult, 0, len(windowsNodeMetrics)+len(windowsPodMetrics)+1)
+	for _, name := range windowsNodeMetrics {
+		testResults = append(testResults, e.validateWindowsMetric(name, []dimension.Instruction{
+			{Key: "ClusterName", Value: dimension.UnknownDimensionValue()},
+			{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+			{Key: "NodeName", Value: dimension.UnknownDimensionValue()},
+		}))
+	}
+	for _, name := range windowsPodMetrics {
+		testResults = append(testResults, e.validateWindowsMetric(name, []dimension.Instruction{
+			{Key: "ClusterName", Value: dimension.UnknownDimensionValue()},
+			{Key: "Namespace", Value: dimension.ExpectedDimensionValue{Value: aws.String(windowsPodNamespace)}},
+			{Key: "PodName", Value: dimension.ExpectedDimensionValue{Value: aws.String(windowsPodName)}},
+		}))
+	}
+	testResults = append(testResults, e.validatePodLogs())
+	return status.TestGroupResult{
+		Name:        e.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (e *EKSWindowsTestRunner) validateWindowsMetric(name string, instructions []dimension.Instruction) status.TestResult {
+	testResult := status.TestResult{
+		Name:   name,
+		Status: status.FAILED,
+	}
+
+	dims, err := e.DimensionFactory.GetDimensions(instructions)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, containerInsightsNamespace, name, dims, 0) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validatePodLogs checks the application logs of the Windows pod were collected with the kubernetes metadata of it
+func (e *EKSWindowsTestRunner) validatePodLogs() status.TestResult {
+	testResult := status.TestResult{
+		Name:   "windows-pod-logs",
+		Status: status.FAILED,
+	}
+
+	now := time.Now()
+	group := fmt.Sprintf("/aws/containerinsights/%s/application", e.env.EKSClusterName)
+	ok, err := awsservice.ValidateLogsInGroup(group, windowsPodName, nil, &now, awsservice.StringValidator(func(logs []string) bool {
+		for _, l := range logs {
+			var event struct {
+				Kubernetes struct {
+					PodName       string `json:"pod_name"`
+					NamespaceName string `json:"namespace_name"`
+				} `json:"kubernetes"`
+			}
+			if err := json.Unmarshal([]byte(l), &event); err != nil {
+				logger.Warnf("failed to unmarshal the windows pod log event: %v", err)
+				return false
+			}
+			if event.Kubernetes.PodName != windowsPodName || event.Kubernetes.NamespaceName != windowsPodNamespace {
+				logger.Errorf("windows pod log event has kubernetes metadata of pod %s/%s", event.Kubernetes.NamespaceName, event.Kubernetes.PodName)
+				return false
+			}
+		}
+		for _, l := range logs {
+			if strings.Contains(l, windowsPodLogLine) {
+				return true
+			}
+		}
+		logger.Errorf("none of the %d windows pod log events has %q", len(logs), windowsPodLogLine)
+		return false
+	}))
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+	if !ok {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+func (e *EKSWindowsTestRunner) GetTestName() string {
+	return "EKSWindowsContainerInstance"
+}
+
+func (e *EKSWindowsTestRunner) GetAgentConfigFileName() string {
+	return ""
+}
+
+func (e *EKSWindowsTestRunner) GetAgentRunDuration() time.Duration {
+	return time.Minute * 3
+}
+
+func (e *EKSWindowsTestRunner) GetMeasuredMetrics() []string {
+	metrics := make([]string, 0, len(windowsNodeMetrics)+len(windowsPodMetrics))
+	metrics = append(metrics, windowsNodeMetrics...)
+	return append(metrics, windowsPodMetrics...)
+}
+
+func (e *EKSWindowsTestRunner) SetAgentConfig(config test_runner.AgentConfig) {}
+
+func (e *EKSWindowsTestRunner) SetupAfterAgentRun() error {
+	return nil
+}
@@ -7,6 +7,8 @@ package metric_value_benchmark
 
 import (
 	_ "embed"
+	"encoding/json"
+	"net"
 	"strings"
 	"time"
 
@@ -19,12 +21,37 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/completeness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	ns "github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
 )
 
 type EMFTestRunner struct {
 	test_runner.BaseTestRunner
+	start time.Time
+	// distributionValues are the values of the EMFDistribution payloads sent between distributionStart and
+	// distributionEnd
+	distributionValues []float64
+	distributionStart  time.Time
+	distributionEnd    time.Time
 }
 
+const (
+	// emfPayloads is the number of payloads /etc/emf.sh sends
+	emfPayloads = 3
+
+	emfAddress = "127.0.0.1:25888"
+	// emfDistributionLogGroup is apart from the group of /etc/emf.sh, validateEMFLogs expects every log in it to be an
+	// EMFCounter
+	emfDistributionLogGroup = "MetricValueBenchmarkTestDistribution"
+	emfDistribution         = "EMFDistribution"
+	// emfDistributionPayloads send emfDistributionValues values each, EMF allows up to 100 values per metric
+	emfDistributionPayloads = 5
+	emfDistributionValues   = 100
+)
+
+var emfDistributionPercentiles = []float64{50, 90, 99}
+
 //go:embed agent_resources/emf_counter.json
 var emfMetricValueBenchmarkSchema string
 
@@ -38,11 +65,30 @@ func (t *EMFTestRunner) Validate() status.TestGroupResult {
 	}
 
 	testResults = append(testResults, validateEMFLogs("MetricValueBenchmarkTest", awsservice.GetInstanceId()))
+	testResults = append(testResults, t.validateEMFDistribution())
+	awsservice.DeleteLogGroup(emfDistributionLogGroup)
 
 	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
+		Name:         t.GetTestName(),
+		TestResults:  testResults,
+		Completeness: t.reconcilePayloads("MetricValueBenchmarkTest", awsservice.GetInstanceId()),
+	}
+}
+
+// reconcilePayloads compares the payloads sent with the EMF log events delivered since the script started
+func (t *EMFTestRunner) reconcilePayloads(group, stream string) []completeness.Report {
+	events, err := awsservice.GetLogEvents(group, stream, &t.start, nil)
+	if err != nil {
+		logger.Warnf("Could not get the EMF log events: %v", err)
+		return nil
+	}
+	var observed int64
+	for _, e := range events {
+		if e.Message != nil && strings.Contains(*e.Message, "EMFCounter") {
+			observed++
+		}
 	}
+	return []completeness.Report{{Kind: completeness.EMFPayloads, Name: group, Sent: emfPayloads, Observed: observed}}
 }
 
 func (t *EMFTestRunner) GetTestName() string {
@@ -64,24 +110,75 @@ func (t *EMFTestRunner) SetupAfterAgentRun() error {
 	//   echo '{"_aws":{"Timestamp":'"${CURRENT_TIME}"',"LogGroupName":"MetricValueBenchmarkTest","CloudWatchMetrics":[{"Namespace":"MetricValueBenchmarkTest","Dimensions":[["Type","InstanceId"]],"Metrics":[{"Name":"EMFCounter","Unit":"Count","InstanceId":"'"${INSTANCEID}"'"}]}]},"Type":"Counter","EMFCounter":5}' \ > /dev/udp/0.0.0.0/25888
 	//   sleep 5
 	// done
+	t.start = time.Now()
 	startEMFCommands := []string{
 		"sudo bash /etc/emf.sh",
 	}
 
-	return common.RunCommands(startEMFCommands)
+	if err := common.RunCommands(startEMFCommands); err != nil {
+		return err
+	}
+	return t.sendEMFDistribution()
+}
+
+// sendEMFDistribution sends EMF payloads with an array of values, which CloudWatch aggregates as a distribution.
+// Every payload has a different spread, so a distribution that loses values or keeps only one per payload fails.
+func (t *EMFTestRunner) sendEMFDistribution() error {
+	conn, err := net.Dial("udp", emfAddress)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	t.distributionValues = nil
+	t.distributionStart = time.Now()
+	for p := 1; p <= emfDistributionPayloads; p++ {
+		values := make([]float64, emfDistributionValues)
+		for i := range values {
+			values[i] = float64(p * (i + 1))
+		}
+		payload, err := json.Marshal(map[string]interface{}{
+			"_aws": map[string]interface{}{
+				"Timestamp":    time.Now().UnixMilli(),
+				"LogGroupName": emfDistributionLogGroup,
+				"CloudWatchMetrics": []map[string]interface{}{{
+					"Namespace":  ns.Scope(namespace),
+					"Dimensions": [][]string{{"Type", "InstanceId"}},
+					"Metrics":    []map[string]string{{"Name": emfDistribution, "Unit": "Milliseconds"}},
+				}},
+			},
+			"Type":          "Distribution",
+			"InstanceId":    awsservice.GetInstanceId(),
+			emfDistribution: values,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = conn.Write(append(payload, '\n')); err != nil {
+			return err
+		}
+		t.distributionValues = append(t.distributionValues, values...)
+	}
+	t.distributionEnd = time.Now()
+	return nil
 }
 
 func (t *EMFTestRunner) GetMeasuredMetrics() []string {
 	return []string{"EMFCounter"}
 }
 
+// GetExpectedMetrics declares the distribution, it is validated on its own instead of as a measured metric
+func (t *EMFTestRunner) GetExpectedMetrics() []string {
+	return []string{emfDistribution}
+}
+
 func (t *EMFTestRunner) validateEMFMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -92,17 +189,55 @@ func (t *EMFTestRunner) validateEMFMetric(metricName string) status.TestResult {
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 5) {
 		return testResult
 	}
 
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateEMFDistribution compares the distribution CloudWatch aggregated over the minutes the payloads were sent in
+// with the distribution of their values. Every value is in the window, so the sample count must be exact.
+func (t *EMFTestRunner) validateEMFDistribution() status.TestResult {
+	expected := metric.NewDistribution(t.distributionValues, emfDistributionPercentiles...)
+	testResult := status.TestResult{
+		Name:     emfDistribution,
+		Status:   status.FAILED,
+		Expected: expected.String(),
+	}
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+		{
+			Key:   "Type",
+			Value: dimension.ExpectedDimensionValue{Value: aws.String("Distribution")},
+		},
+	})
 	if err != nil {
+		testResult.SetError(err)
 		return testResult
 	}
+	testResult.SetDimensions(dims)
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 5) {
+	start := t.distributionStart.Truncate(time.Minute)
+	end := t.distributionEnd.Truncate(time.Minute).Add(time.Minute)
+	fetcher := metric.MetricValueFetcher{}
+	actual, err := fetcher.FetchDistribution(namespace, emfDistribution, dims, start, end, emfDistributionPercentiles...)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Actual = actual.String()
+	if err = actual.Validate(expected, 0, metric.DistributionValueTolerance); err != nil {
+		testResult.SetError(err)
 		return testResult
 	}
 
@@ -123,7 +258,7 @@ func validateEMFLogs(group, stream string) status.TestResult {
 	}
 
 	now := time.Now()
-	ok, err := awsservice.ValidateLogs(group, stream, nil, &now, func(logs []string) bool {
+	ok, err := awsservice.ValidateLogs(group, stream, nil, &now, awsservice.StringValidator(func(logs []string) bool {
 		if len(logs) < 1 {
 			return false
 		}
@@ -134,7 +269,7 @@ func validateEMFLogs(group, stream string) status.TestResult {
 			}
 		}
 		return true
-	})
+	}))
 
 	if err != nil || !ok {
 		return testResult
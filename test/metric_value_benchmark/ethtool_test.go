@@ -11,7 +11,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -21,19 +20,10 @@ type EthtoolTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*EthtoolTestRunner)(nil)
+var _ test_runner.MetricRunner = (*EthtoolTestRunner)(nil)
 
 func (m *EthtoolTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateEthtoolMetric(name)
-	}
-
-	return status.TestGroupResult{
-		Name:        m.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(m)
 }
 
 func (m *EthtoolTestRunner) GetTestName() string {
@@ -50,7 +40,7 @@ func (m *EthtoolTestRunner) GetMeasuredMetrics() []string {
 	}
 }
 
-func (m *EthtoolTestRunner) validateEthtoolMetric(metricName string) status.TestResult {
+func (m *EthtoolTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
@@ -62,13 +52,13 @@ func (m *EthtoolTestRunner) validateEthtoolMetric(metricName string) status.Test
 	}
 
 	var (
-		dims   []types.Dimension
-		failed []dimension.Instruction
+		dims []types.Dimension
+		err  error
 	)
 
 	for _, iface := range ifaces {
 		if iface.Name == "eth0" || iface.Name == "ens5" {
-			dims, failed = m.DimensionFactory.GetDimensions([]dimension.Instruction{
+			dims, err = m.DimensionFactory.GetDimensions([]dimension.Instruction{
 				{
 					Key:   "InstanceId",
 					Value: dimension.UnknownDimensionValue(),
@@ -86,18 +76,12 @@ func (m *EthtoolTestRunner) validateEthtoolMetric(metricName string) status.Test
 		}
 	}
 
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
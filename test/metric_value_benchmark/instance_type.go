@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// memReservedPercent is the most of the memory of an instance type the firmware and the kernel keep from MemTotal.
+// The xen instances reserve more than the nitro ones and graviton reserves the most, around 10% of the small types.
+const memReservedPercent = 15
+
+// instanceTypeBounds returns the bounds of the metrics the instance type of the host fixes, so the suite checks them
+// on every instance type of the ec2_instance_type matrix. It returns no bounds when the type cannot be described,
+// the ground truth of the host still checks the values then.
+func instanceTypeBounds() map[string]test_runner.MetricValidator {
+	instanceType := awsservice.GetInstanceType()
+	info, err := awsservice.DescribeInstanceType(instanceType)
+	if err != nil {
+		logger.Warnf("Not checking the metrics against instance type %s: %v", instanceType, err)
+		return nil
+	}
+	if info.MemoryInfo == nil {
+		logger.Warnf("Not checking the metrics against instance type %s, its memory is unknown", instanceType)
+		return nil
+	}
+	var archs []string
+	if info.ProcessorInfo != nil {
+		for _, arch := range info.ProcessorInfo.SupportedArchitectures {
+			archs = append(archs, string(arch))
+		}
+	}
+	logger.Infof("Checking the metrics against instance type %s, %d MiB, %s, %s hypervisor, burstable: %v",
+		instanceType, aws.ToInt64(info.MemoryInfo.SizeInMiB), strings.Join(archs, "/"), info.Hypervisor,
+		aws.ToBool(info.BurstablePerformanceSupported))
+
+	memBytes := float64(aws.ToInt64(info.MemoryInfo.SizeInMiB)) * 1024 * 1024
+	return map[string]test_runner.MetricValidator{
+		"mem_total": test_runner.Bounds{Bounds: metric.Bounds{Min: memBytes * (100 - memReservedPercent) / 100, Max: memBytes}},
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"context"
+	"net"
+	"os/exec"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	jmxPort    = "2030"
+	jmxTestApp = "agent_resources/jmx/JmxTestApp.java"
+)
+
+// JMXTestRunner runs a small Java app exposing JMX with JVM and Tomcat shaped MBeans and validates the metrics the
+// agent collects with its jmx plugin. It only applies to hosts with a JVM installed.
+type JMXTestRunner struct {
+	test_runner.BaseTestRunner
+	app *exec.Cmd
+}
+
+var _ test_runner.MetricRunner = (*JMXTestRunner)(nil)
+var _ test_runner.Applicable = (*JMXTestRunner)(nil)
+
+func (t *JMXTestRunner) Validate() status.TestGroupResult {
+	return test_runner.ValidateEachMetric(t)
+}
+
+func (t *JMXTestRunner) GetTestName() string {
+	return "JMX"
+}
+
+func (t *JMXTestRunner) GetAgentConfigFileName() string {
+	return "jmx_config.json"
+}
+
+func (t *JMXTestRunner) GetMeasuredMetrics() []string {
+	return []string{"jvm.classes.loaded", "jvm.memory.heap.used", "jvm.threads.count", "tomcat.sessions", "tomcat.request_count"}
+}
+
+// IsApplicable is false when there is no java on the path, the single-file source launcher needs Java 11+
+func (t *JMXTestRunner) IsApplicable() bool {
+	_, err := exec.LookPath("java")
+	return err == nil
+}
+
+// SetupBeforeAgentRun starts the Java app and waits for its JMX port, so the agent connects on its first collection
+func (t *JMXTestRunner) SetupBeforeAgentRun() error {
+	t.app = exec.Command("java",
+		"-Dcom.sun.management.jmxremote.port="+jmxPort,
+		"-Dcom.sun.management.jmxremote.rmi.port="+jmxPort,
+		"-Dcom.sun.management.jmxremote.authenticate=false",
+		"-Dcom.sun.management.jmxremote.ssl=false",
+		"-Djava.rmi.server.hostname=localhost",
+		jmxTestApp)
+	if err := t.app.Start(); err != nil {
+		t.app = nil
+		return err
+	}
+
+	err := await.WaitUntil(context.Background(), time.Second, time.Minute, "jmx port "+jmxPort, func() (bool, error) {
+		conn, err := net.Dial("tcp", net.JoinHostPort("localhost", jmxPort))
+		if err != nil {
+			return false, nil
+		}
+		conn.Close()
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	return t.SetUpConfig()
+}
+
+// Cleanup stops the Java app if SetupBeforeAgentRun started it
+func (t *JMXTestRunner) Cleanup() {
+	if t.app == nil {
+		return
+	}
+	if err := t.app.Process.Kill(); err != nil {
+		logger.Errorf("Failed to stop the jmx test app: %v", err)
+	}
+	_ = t.app.Wait()
+	t.app = nil
+}
+
+func (t *JMXTestRunner) ValidateMetric(metricName string) status.TestResult {
+	testResult := status.TestResult{
+		Name:   metricName,
+		Status: status.FAILED,
+	}
+
+	instructions := []dimension.Instruction{
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+	}
+	if metricName == "tomcat.request_count" {
+		// the tomcat target system labels the request metrics with the name of the request processor
+		instructions = append(instructions, dimension.Instruction{
+			Key:   "proto_handler",
+			Value: dimension.ExpectedDimensionValue{Value: aws.String("http-jmx-test")},
+		})
+	}
+
+	dims, err := t.DimensionFactory.GetDimensions(instructions)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
@@ -10,27 +10,61 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
 )
 
 type MemTestRunner struct {
 	test_runner.BaseTestRunner
+	host *groundtruth.Collector
 }
 
 var _ test_runner.ITestRunner = (*MemTestRunner)(nil)
 
+var memMetrics = test_runner.NewMetricSpecs(
+	[]dimension.Instruction{instanceIdInstruction},
+	[]test_runner.MetricValidator{test_runner.NonNegative{}, test_runner.Unit{}},
+	"mem_active", "mem_available", "mem_available_percent", "mem_buffered", "mem_cached",
+	"mem_free", "mem_inactive", "mem_total", "mem_used", "mem_used_percent")
+
+// memGroundTruth are the metrics compared with /proc/meminfo, the memory in use changes between the reads of the agent
+// and the host so only the total is expected to match closely
+var memGroundTruth = map[string]metric.HostReading{
+	"mem_total":             {Relative: 0.01},
+	"mem_available":         {Relative: 0.1},
+	"mem_available_percent": {Absolute: 5},
+}
+
 func (m *MemTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateMemMetric(name)
+	series := m.host.Stop()
+	bounds := instanceTypeBounds()
+	specs := make(test_runner.MetricSpecs, len(memMetrics))
+	for i, spec := range memMetrics {
+		validators := append([]test_runner.MetricValidator(nil), spec.Validators...)
+		if truth, ok := memGroundTruth[spec.Name]; ok {
+			validators = append(validators, test_runner.GroundTruth{Series: series, HostReading: truth})
+		}
+		if b, ok := bounds[spec.Name]; ok {
+			validators = append(validators, b)
+		}
+		spec.Validators = validators
+		specs[i] = spec
 	}
-
 	return status.TestGroupResult{
 		Name:        m.GetTestName(),
-		TestResults: testResults,
+		TestResults: test_runner.ValidateMetrics(m.DimensionFactory, namespace, specs),
 	}
 }
 
+// SetupAfterAgentRun reads the host while the agent runs
+func (m *MemTestRunner) SetupAfterAgentRun() error {
+	m.host = groundtruth.Start(groundTruthInterval)
+	return nil
+}
+
+func (m *MemTestRunner) Cleanup() {
+	m.host.Stop()
+}
+
 func (m *MemTestRunner) GetTestName() string {
 	return "Mem"
 }
@@ -40,38 +74,5 @@ func (m *MemTestRunner) GetAgentConfigFileName() string {
 }
 
 func (m *MemTestRunner) GetMeasuredMetrics() []string {
-	return []string{
-		"mem_active", "mem_available", "mem_available_percent", "mem_buffered", "mem_cached",
-		"mem_free", "mem_inactive", "mem_total", "mem_used", "mem_used_percent"}
-}
-
-func (m *MemTestRunner) validateMemMetric(metricName string) status.TestResult {
-	testResult := status.TestResult{
-		Name:   metricName,
-		Status: status.FAILED,
-	}
-
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
-		{
-			Key:   "InstanceId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-	})
-
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	if err != nil {
-		return testResult
-	}
-
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
-		return testResult
-	}
-
-	testResult.Status = status.SUCCESSFUL
-	return testResult
+	return memMetrics.Names()
 }
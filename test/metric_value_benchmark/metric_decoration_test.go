@@ -0,0 +1,151 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+)
+
+// decorationCheck expects the metric with exactly the dimensions to be published with the unit, or not at all
+type decorationCheck struct {
+	name         string
+	metricName   string
+	instructions []dimension.Instruction
+	unit         types.StandardUnit
+	absent       bool
+}
+
+var instanceIdInstruction = dimension.Instruction{Key: "InstanceId", Value: dimension.UnknownDimensionValue()}
+
+var decorationChecks = []decorationCheck{
+	{
+		name:         "MemoryUsedPercent",
+		metricName:   "MemoryUsedPercent",
+		instructions: []dimension.Instruction{instanceIdInstruction},
+		unit:         types.StandardUnitPercent,
+	},
+	{
+		name:         "MemoryFree",
+		metricName:   "MemoryFree",
+		instructions: []dimension.Instruction{instanceIdInstruction},
+		unit:         types.StandardUnitMegabytes,
+	},
+	{
+		name:         "mem_used_percent renamed",
+		metricName:   "mem_used_percent",
+		instructions: []dimension.Instruction{instanceIdInstruction},
+		absent:       true,
+	},
+	{
+		name:         "mem_free renamed",
+		metricName:   "mem_free",
+		instructions: []dimension.Instruction{instanceIdInstruction},
+		absent:       true,
+	},
+	{
+		name:         "cpu_usage_idle aggregated",
+		metricName:   "cpu_usage_idle",
+		instructions: []dimension.Instruction{instanceIdInstruction},
+		unit:         types.StandardUnitPercent,
+	},
+	{
+		name:       "cpu_usage_idle original dropped",
+		metricName: "cpu_usage_idle",
+		instructions: []dimension.Instruction{
+			instanceIdInstruction,
+			{Key: "cpu", Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")}},
+		},
+		absent: true,
+	},
+}
+
+// MetricDecorationTestRunner covers measurement rename and unit overrides and drop_original_metrics
+type MetricDecorationTestRunner struct {
+	test_runner.BaseTestRunner
+	agentStart time.Time
+}
+
+var _ test_runner.ITestRunner = (*MetricDecorationTestRunner)(nil)
+
+func (t *MetricDecorationTestRunner) Validate() status.TestGroupResult {
+	testResults := make([]status.TestResult, len(decorationChecks))
+	for i, check := range decorationChecks {
+		testResults[i] = t.validateDecoration(check)
+	}
+
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (t *MetricDecorationTestRunner) GetTestName() string {
+	return "MetricDecoration"
+}
+
+func (t *MetricDecorationTestRunner) GetAgentConfigFileName() string {
+	return "metric_decoration_config.json"
+}
+
+func (t *MetricDecorationTestRunner) GetMeasuredMetrics() []string {
+	metrics := make([]string, len(decorationChecks))
+	for i, check := range decorationChecks {
+		metrics[i] = check.metricName
+	}
+	return metrics
+}
+
+// SetupAfterAgentRun records when the agent started, since other runners publish the original metrics to the same
+// namespace and the absence checks must only look at datapoints published with this config
+func (t *MetricDecorationTestRunner) SetupAfterAgentRun() error {
+	t.agentStart = time.Now()
+	return nil
+}
+
+func (t *MetricDecorationTestRunner) validateDecoration(check decorationCheck) status.TestResult {
+	testResult := status.TestResult{
+		Name:   check.name,
+		Status: status.FAILED,
+	}
+
+	dims, err := t.DimensionFactory.GetDimensions(check.instructions)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	fetcher := metric.MetricValueFetcher{}
+	if check.absent {
+		testResult.Expected = "no datapoints"
+		if err = fetcher.AssertNotPublished(namespace, check.metricName, dims, t.agentStart); err != nil {
+			testResult.Reason = err.Error()
+			return testResult
+		}
+		testResult.Status = status.SUCCESSFUL
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, namespace, check.metricName, dims, 0) {
+		return testResult
+	}
+
+	if err = fetcher.ValidateUnit(namespace, check.metricName, dims, check.unit); err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
@@ -10,19 +10,33 @@ import (
 	"log"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/computetype"
+	"github.com/aws/amazon-cloudwatch-agent-test/environment/containerostype"
 	"github.com/aws/amazon-cloudwatch-agent-test/environment/eksdeploymenttype"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentversion"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/notify"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 )
 
 const namespace = "MetricValueBenchmarkTest"
 
+// groundTruthInterval reads the host as often as the configs collect the metrics
+const groundTruthInterval = 10 * time.Second
+
 type MetricBenchmarkTestSuite struct {
 	suite.Suite
 	test_runner.TestSuite
@@ -33,7 +47,11 @@ func (suite *MetricBenchmarkTestSuite) SetupSuite() {
 }
 
 func (suite *MetricBenchmarkTestSuite) TearDownSuite() {
-	suite.Result.Print()
+	suite.Result.Name = "MetricBenchmarkTestSuite"
+	suite.Result.AgentVersion = agentversion.Get()
+	report.Send(suite.Result)
+	notify.Send(suite.Result.Summary())
+	health.Publish(suite.Result.Name)
 	fmt.Println(">>>> Finished MetricBenchmarkTestSuite")
 }
 
@@ -52,13 +70,18 @@ var (
 func getEcsTestRunners(env *environment.MetaData) []*test_runner.ECSTestRunner {
 	if ecsTestRunners == nil {
 		factory := dimension.GetDimensionFactory(*env)
+		containerInsightsTestRunner := ContainerInsightsTestRunner{
+			BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory},
+			env:            env,
+		}
 
+		var runner test_runner.ITestRunner = &containerInsightsTestRunner
+		if env.ContainerOs == containerostype.WINDOWS {
+			runner = &WindowsContainerInsightsTestRunner{containerInsightsTestRunner}
+		}
 		ecsTestRunners = []*test_runner.ECSTestRunner{
 			{
-				Runner: &ContainerInsightsTestRunner{
-					BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory},
-					env:            env,
-				},
+				Runner:      runner,
 				RunStrategy: &test_runner.ECSAgentRunStrategy{},
 				Env:         *env,
 			},
@@ -80,7 +103,34 @@ func getEksTestRunners(env *environment.MetaData) []*test_runner.EKSTestRunner {
 				},
 				Env: *env,
 			}
-			eksTestRunners = append(eksTestRunners, &eksDaemonTestRunner)
+			eksControlPlaneTestRunner := test_runner.EKSTestRunner{
+				Runner: &EKSControlPlaneTestRunner{BaseTestRunner: test_runner.BaseTestRunner{
+					DimensionFactory: factory,
+				},
+					env: env,
+				},
+				Env: *env,
+			}
+			eksGPUTestRunner := test_runner.EKSTestRunner{
+				Runner: &EKSGPUTestRunner{BaseTestRunner: test_runner.BaseTestRunner{
+					DimensionFactory: factory,
+				},
+					env: env,
+				},
+				Env: *env,
+			}
+			eksTestRunners = append(eksTestRunners, &eksDaemonTestRunner, &eksControlPlaneTestRunner, &eksGPUTestRunner)
+			if env.ContainerOs == containerostype.WINDOWS {
+				eksWindowsTestRunner := test_runner.EKSTestRunner{
+					Runner: &EKSWindowsTestRunner{BaseTestRunner: test_runner.BaseTestRunner{
+						DimensionFactory: factory,
+					},
+						env: env,
+					},
+					Env: *env,
+				}
+				eksTestRunners = append(eksTestRunners, &eksWindowsTestRunner)
+			}
 		case eksdeploymenttype.REPLICA:
 			eksDeploymentTestRunner := test_runner.EKSTestRunner{
 				Runner: &EKSDeploymentTestRunner{BaseTestRunner: test_runner.BaseTestRunner{
@@ -96,17 +146,21 @@ func getEksTestRunners(env *environment.MetaData) []*test_runner.EKSTestRunner {
 	return eksTestRunners
 }
 
-func getEc2TestRunners(env *environment.MetaData) []*test_runner.TestRunner {
+// getEc2TestRunners returns the built in runners and the runners of the suite definitions. A definition that does
+// not load fails the suite, rather than silently running without its runners.
+func getEc2TestRunners(env *environment.MetaData) ([]*test_runner.TestRunner, error) {
 	if ec2TestRunners == nil {
 		factory := dimension.GetDimensionFactory(*env)
 		ec2TestRunners = []*test_runner.TestRunner{
 			{TestRunner: &StatsdTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &StatsdAggregationTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &DiskTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &NetStatTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &PrometheusTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &CPUTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &MemTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &ProcStatTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &ProcStatMultiTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &DiskIOTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &NetTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &EthtoolTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
@@ -115,9 +169,24 @@ func getEc2TestRunners(env *environment.MetaData) []*test_runner.TestRunner {
 			{TestRunner: &ProcessesTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &CollectDTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
 			{TestRunner: &RenameSSMTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &MetricDecorationTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &SelfTelemetryTestRunner{test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &JMXTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &ConfigReloadTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &AgentStatusTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
+			{TestRunner: &SmokeTestRunner{BaseTestRunner: test_runner.BaseTestRunner{DimensionFactory: factory}}},
+		}
+
+		defs, err := test_runner.LoadSuiteDefinitions()
+		if err != nil {
+			ec2TestRunners = nil
+			return nil, fmt.Errorf("failed to load suite definitions: %w", err)
+		}
+		for _, def := range defs {
+			ec2TestRunners = append(ec2TestRunners, def.GetTestRunners(env, factory)...)
 		}
 	}
-	return ec2TestRunners
+	return ec2TestRunners, nil
 }
 
 func (suite *MetricBenchmarkTestSuite) TestAllInSuite() {
@@ -135,7 +204,17 @@ func (suite *MetricBenchmarkTestSuite) TestAllInSuite() {
 		}
 	default: // EC2 tests
 		log.Println("Environment compute type is EC2")
-		for _, testRunner := range getEc2TestRunners(env) {
+		ec2Runners, err := getEc2TestRunners(env)
+		suite.Require().NoError(err)
+		suite.Require().NoError(test_runner.ValidateConfigs(ec2Runners))
+		suite.Require().NoError(test_runner.CheckPorts(env.KillStaleListeners))
+		check := &metric.NamespaceCheck{
+			Namespace:  namespace,
+			Dimensions: []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())}},
+			Ceilings:   env.CardinalityCeilings,
+		}
+		for _, testRunner := range ec2Runners {
+			testRunner.NamespaceCheck = check
 			if shouldRunEC2Test(env, testRunner) {
 				suite.AddToSuiteResult(testRunner.Run())
 			}
@@ -154,8 +233,14 @@ func TestMetricValueBenchmarkSuite(t *testing.T) {
 }
 
 func shouldRunEC2Test(env *environment.MetaData, t *test_runner.TestRunner) bool {
+	if a, ok := t.TestRunner.(test_runner.Applicable); ok && !a.IsApplicable() {
+		logger.Infof("Skipping %s since it does not apply to this host", t.TestRunner.GetTestName())
+		return false
+	}
 	if env.EC2PluginTests == nil {
-		return true // default behavior is to run all tests
+		// default behavior is to run all tests but the explicit ones
+		e, ok := t.TestRunner.(test_runner.Explicit)
+		return !ok || !e.IsExplicit()
 	}
 	_, ok := env.EC2PluginTests[strings.ToLower(t.TestRunner.GetTestName())]
 	return ok
@@ -7,6 +7,7 @@ package metric_value_benchmark
 
 import (
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
@@ -18,19 +19,12 @@ type NetTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*NetTestRunner)(nil)
+var _ test_runner.MetricRunner = (*NetTestRunner)(nil)
 
 func (m *NetTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateNetMetric(name)
-	}
-
-	return status.TestGroupResult{
-		Name:        m.GetTestName(),
-		TestResults: testResults,
-	}
+	result := test_runner.ValidateEachMetric(m)
+	result.TestResults = append(result.TestResults, m.validateThroughput())
+	return result
 }
 
 func (m *NetTestRunner) GetTestName() string {
@@ -47,38 +41,78 @@ func (m *NetTestRunner) GetMeasuredMetrics() []string {
 		"net_err_out", "net_packets_sent", "net_packets_recv"}
 }
 
-func (m *NetTestRunner) validateNetMetric(metricName string) status.TestResult {
+func (m *NetTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
-		{
-			Key:   "interface",
-			Value: dimension.ExpectedDimensionValue{aws.String("docker0")},
-		},
-		{
-			Key:   "InstanceId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-	})
+	dims, err := m.dimensions()
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
 
-	if len(failed) > 0 {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
 	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+	if err = fetcher.ValidateUnit(namespace, metricName, dims, ""); err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
 
+	// every net metric is a counter
+	if !(test_runner.Counter{}).Validate(&testResult, namespace, metricName, dims) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateThroughput validates the bytes sent and received per second add up, which the agent reports as two metrics
+func (m *NetTestRunner) validateThroughput() status.TestResult {
+	testResult := status.TestResult{
+		Name:   "net_throughput",
+		Status: status.FAILED,
+	}
+
+	dims, err := m.dimensions()
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
+	testResult.SetDimensions(dims)
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.FetchExpression(namespace, "(sent + recv) / PERIOD(sent)", []metric.MetricQuery{
+		{Id: "sent", MetricName: "net_bytes_sent", Dimensions: dims, Stat: metric.SUM},
+		{Id: "recv", MetricName: "net_bytes_recv", Dimensions: dims, Stat: metric.SUM},
+	}, metric.HighResolutionStatPeriod)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(testResult.Name, values, 0) {
+		testResult.SetValueMismatch(metric.DescribeExpectedValue(0), values)
 		return testResult
 	}
 
 	testResult.Status = status.SUCCESSFUL
 	return testResult
 }
+
+func (m *NetTestRunner) dimensions() ([]types.Dimension, error) {
+	return m.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "interface",
+			Value: dimension.ExpectedDimensionValue{aws.String("docker0")},
+		},
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+	})
+}
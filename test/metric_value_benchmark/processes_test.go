@@ -6,7 +6,6 @@
 package metric_value_benchmark
 
 import (
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -16,19 +15,10 @@ type ProcessesTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*ProcessesTestRunner)(nil)
+var _ test_runner.MetricRunner = (*ProcessesTestRunner)(nil)
 
 func (m *ProcessesTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateProcessesMetric(name)
-	}
-
-	return status.TestGroupResult{
-		Name:        m.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(m)
 }
 
 func (m *ProcessesTestRunner) GetTestName() string {
@@ -45,30 +35,25 @@ func (m *ProcessesTestRunner) GetMeasuredMetrics() []string {
 		"processes_total", "processes_total_threads", "processes_zombies"}
 }
 
-func (m *ProcessesTestRunner) validateProcessesMetric(metricName string) status.TestResult {
+func (m *ProcessesTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := m.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
 		},
 	})
 
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
@@ -0,0 +1,212 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+)
+
+const (
+	// procstatExe is the exe regex of agent_configs/procstat_multi_config.json, the workers are copies of sleep named
+	// after the user they run as, short enough for the 15 characters of the process name
+	procstatExe       = "^cwa-pstat-(root|nobody)$"
+	procstatWorkerDir = "/var/tmp/cwagent-procstat"
+	procstatRuntime   = 2 * time.Minute
+	// procstatExitAfter is when the short lived worker exits, half way through the run
+	procstatExitAfter = procstatRuntime / 2
+	// procstatExitGrace covers the collection that was in flight when the worker exited
+	procstatExitGrace = 20 * time.Second
+)
+
+var procstatMultiMetrics = []string{"procstat_cpu_usage", "procstat_memory_rss", "procstat_memory_vms"}
+
+type procstatWorker struct {
+	name string
+	cmd  *exec.Cmd
+	// exited is closed once the process exited, exitedAt is set before
+	exited   chan struct{}
+	exitedAt time.Time
+}
+
+func (w *procstatWorker) pid() string {
+	return strconv.Itoa(w.cmd.Process.Pid)
+}
+
+func (w *procstatWorker) hasExited() bool {
+	select {
+	case <-w.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProcStatMultiTestRunner monitors several processes selected by one exe regex, which run as root and as nobody, and
+// validates each gets its own series with its pid. One of the workers exits mid-run and its series must stop.
+type ProcStatMultiTestRunner struct {
+	test_runner.BaseTestRunner
+	workers []*procstatWorker
+	stop    sync.Once
+}
+
+var _ test_runner.ITestRunner = (*ProcStatMultiTestRunner)(nil)
+
+func (m *ProcStatMultiTestRunner) GetTestName() string {
+	return "ProcStatMulti"
+}
+
+func (m *ProcStatMultiTestRunner) GetAgentConfigFileName() string {
+	return "procstat_multi_config.json"
+}
+
+func (m *ProcStatMultiTestRunner) GetAgentRunDuration() time.Duration {
+	return procstatRuntime
+}
+
+func (m *ProcStatMultiTestRunner) GetMeasuredMetrics() []string {
+	return procstatMultiMetrics
+}
+
+// SetupAfterAgentRun starts two long lived workers as root, one as nobody and a root worker which exits half way
+// through the run
+func (m *ProcStatMultiTestRunner) SetupAfterAgentRun() error {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		return err
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(procstatWorkerDir, 0755); err != nil {
+		return err
+	}
+	longLived := strconv.Itoa(int((procstatRuntime + time.Hour).Seconds()))
+	shortLived := strconv.Itoa(int(procstatExitAfter.Seconds()))
+	for _, w := range []struct {
+		user     *user.User
+		duration string
+	}{{nil, longLived}, {nil, longLived}, {nobody, longLived}, {nil, shortLived}} {
+		name := "cwa-pstat-root"
+		if w.user != nil {
+			name = "cwa-pstat-" + w.user.Username
+		}
+		if err = m.startWorker(sleep, name, w.user, w.duration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *ProcStatMultiTestRunner) startWorker(sleep, name string, as *user.User, duration string) error {
+	path := filepath.Join(procstatWorkerDir, name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if out, err := exec.Command("cp", sleep, path).CombinedOutput(); err != nil {
+			return fmt.Errorf("copying %s to %s failed: %w: %s", sleep, path, err, out)
+		}
+	}
+	cmd := exec.Command(path, duration)
+	if as != nil {
+		uid, _ := strconv.ParseUint(as.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(as.Gid, 10, 32)
+		cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}}
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting %s failed: %w", name, err)
+	}
+	w := &procstatWorker{name: name, cmd: cmd, exited: make(chan struct{})}
+	go func() {
+		_ = cmd.Wait()
+		w.exitedAt = time.Now()
+		close(w.exited)
+	}()
+	m.workers = append(m.workers, w)
+	return nil
+}
+
+// Cleanup kills the workers which are still running and removes their executables
+func (m *ProcStatMultiTestRunner) Cleanup() {
+	m.stop.Do(func() {
+		for _, w := range m.workers {
+			if !w.hasExited() {
+				_ = w.cmd.Process.Kill()
+				<-w.exited
+			}
+		}
+		_ = os.RemoveAll(procstatWorkerDir)
+	})
+}
+
+func (m *ProcStatMultiTestRunner) Validate() status.TestGroupResult {
+	testGroupResult := status.TestGroupResult{Name: m.GetTestName()}
+	for _, w := range m.workers {
+		for _, metricName := range procstatMultiMetrics {
+			testGroupResult.TestResults = append(testGroupResult.TestResults, m.validateWorker(w, metricName))
+		}
+	}
+	return testGroupResult
+}
+
+func (m *ProcStatMultiTestRunner) validateWorker(w *procstatWorker, metricName string) status.TestResult {
+	testResult := status.TestResult{
+		Name:   fmt.Sprintf("%s %s pid %s", metricName, w.name, w.pid()),
+		Status: status.FAILED,
+	}
+
+	dims, err := m.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "exe",
+			Value: dimension.ExpectedDimensionValue{aws.String(procstatExe)},
+		},
+		{
+			Key:   "process_name",
+			Value: dimension.ExpectedDimensionValue{aws.String(w.name)},
+		},
+		{
+			Key:   "pid",
+			Value: dimension.ExpectedDimensionValue{aws.String(w.pid())},
+		},
+		{
+			Key:   "InstanceId",
+			Value: dimension.UnknownDimensionValue(),
+		},
+	})
+
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
+		return testResult
+	}
+
+	if w.hasExited() {
+		fetcher := metric.MetricValueFetcher{}
+		if err = fetcher.AssertNotPublished(namespace, metricName, dims, w.exitedAt.Add(procstatExitGrace)); err != nil {
+			testResult.SetError(fmt.Errorf("%s exited at %s: %w", w.name, w.exitedAt.Format(time.RFC3339), err))
+			return testResult
+		}
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
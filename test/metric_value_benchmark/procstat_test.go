@@ -8,7 +8,6 @@ package metric_value_benchmark
 import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -18,19 +17,10 @@ type ProcStatTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*ProcStatTestRunner)(nil)
+var _ test_runner.MetricRunner = (*ProcStatTestRunner)(nil)
 
 func (m *ProcStatTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateProcStatMetric(name)
-	}
-
-	return status.TestGroupResult{
-		Name:        m.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(m)
 }
 
 func (m *ProcStatTestRunner) GetTestName() string {
@@ -51,13 +41,13 @@ func (m *ProcStatTestRunner) GetMeasuredMetrics() []string {
 		"procstat_memory_rss", "procstat_memory_stack", "procstat_memory_swap", "procstat_memory_vms"}
 }
 
-func (m *ProcStatTestRunner) validateProcStatMetric(metricName string) status.TestResult {
+func (m *ProcStatTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := m.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "exe",
 			Value: dimension.ExpectedDimensionValue{aws.String("cloudwatch-agent")},
@@ -72,17 +62,12 @@ func (m *ProcStatTestRunner) validateProcStatMetric(metricName string) status.Te
 		},
 	})
 
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
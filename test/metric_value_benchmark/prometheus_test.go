@@ -12,7 +12,6 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -23,7 +22,7 @@ type PrometheusTestRunner struct {
 	test_runner.BaseTestRunner
 }
 
-var _ test_runner.ITestRunner = (*PrometheusTestRunner)(nil)
+var _ test_runner.MetricRunner = (*PrometheusTestRunner)(nil)
 
 //go:embed agent_configs/prometheus.yaml
 var prometheusConfig string
@@ -52,16 +51,7 @@ prometheus_test_histogram_bucket{include="yes",le="+Inf",prom_type="histogram"}
 `
 
 func (t *PrometheusTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validatePrometheusMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(t)
 }
 
 func (t *PrometheusTestRunner) GetTestName() string {
@@ -100,46 +90,46 @@ func (t *PrometheusTestRunner) GetMeasuredMetrics() []string {
 	}
 }
 
-func (t *PrometheusTestRunner) validatePrometheusMetric(metricName string) status.TestResult {
+func (t *PrometheusTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
 	var dims []types.Dimension
-	var failed []dimension.Instruction
+	var err error
 
 	switch metricName {
 	case "prometheus_test_counter":
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "prom_type",
 				Value: dimension.ExpectedDimensionValue{aws.String("counter")},
 			},
 		})
 	case "prometheus_test_gauge":
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "prom_type",
 				Value: dimension.ExpectedDimensionValue{aws.String("gauge")},
 			},
 		})
 	case "prometheus_test_summary_count":
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "prom_type",
 				Value: dimension.ExpectedDimensionValue{aws.String("summary")},
 			},
 		})
 	case "prometheus_test_summary_sum":
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "prom_type",
 				Value: dimension.ExpectedDimensionValue{aws.String("summary")},
 			},
 		})
 	case "prometheus_test_summary":
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{
 			{
 				Key:   "prom_type",
 				Value: dimension.ExpectedDimensionValue{aws.String("summary")},
@@ -150,20 +140,15 @@ func (t *PrometheusTestRunner) validatePrometheusMetric(metricName string) statu
 			},
 		})
 	default:
-		dims, failed = t.DimensionFactory.GetDimensions([]dimension.Instruction{})
-	}
-
-	if len(failed) > 0 {
-		return testResult
+		dims, err = t.DimensionFactory.GetDimensions([]dimension.Instruction{})
 	}
 
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
 	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
 		return testResult
 	}
 
@@ -6,7 +6,6 @@
 package metric_value_benchmark
 
 import (
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -18,16 +17,16 @@ type RenameSSMTestRunner struct {
 
 var _ test_runner.ITestRunner = (*RenameSSMTestRunner)(nil)
 
-func (m *RenameSSMTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := m.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, name := range metricsToFetch {
-		testResults[i] = m.validateMemMetric(name)
-	}
+var renameSSMMetrics = test_runner.NewMetricSpecs(
+	[]dimension.Instruction{instanceIdInstruction},
+	[]test_runner.MetricValidator{test_runner.NonNegative{}},
+	"ssm_cpu_utilization",
+)
 
+func (m *RenameSSMTestRunner) Validate() status.TestGroupResult {
 	return status.TestGroupResult{
 		Name:        m.GetTestName(),
-		TestResults: testResults,
+		TestResults: test_runner.ValidateMetrics(m.DimensionFactory, namespace, renameSSMMetrics),
 	}
 }
 
@@ -40,40 +39,7 @@ func (m *RenameSSMTestRunner) GetAgentConfigFileName() string {
 }
 
 func (m *RenameSSMTestRunner) GetMeasuredMetrics() []string {
-	return []string{
-		"ssm_cpu_utilization",
-	}
-}
-
-func (m *RenameSSMTestRunner) validateMemMetric(metricName string) status.TestResult {
-	testResult := status.TestResult{
-		Name:   metricName,
-		Status: status.FAILED,
-	}
-
-	dims, failed := m.DimensionFactory.GetDimensions([]dimension.Instruction{
-		{
-			Key:   "InstanceId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-	})
-
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	if err != nil {
-		return testResult
-	}
-
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
-		return testResult
-	}
-
-	testResult.Status = status.SUCCESSFUL
-	return testResult
+	return renameSSMMetrics.Names()
 }
 
 func (m *RenameSSMTestRunner) UseSSM() bool {
@@ -0,0 +1,100 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	selfTelemetryJob            = "cwagent_self_telemetry"
+	selfTelemetryOtelConfigPath = "/tmp/self_telemetry_otel.yaml"
+)
+
+//go:embed agent_configs/self_telemetry_otel.yaml
+var selfTelemetryOtelConfig string
+
+//go:embed agent_configs/self_telemetry_prometheus.yaml
+var selfTelemetryPrometheusConfig string
+
+// SelfTelemetryTestRunner enables the telemetry of the agent's collector pipeline, scrapes it with the agent's own
+// prometheus input and validates the agent health metrics reach CloudWatch
+type SelfTelemetryTestRunner struct {
+	test_runner.BaseTestRunner
+}
+
+var _ test_runner.MetricRunner = (*SelfTelemetryTestRunner)(nil)
+
+func (t *SelfTelemetryTestRunner) Validate() status.TestGroupResult {
+	return test_runner.ValidateEachMetric(t)
+}
+
+func (t *SelfTelemetryTestRunner) GetTestName() string {
+	return "SelfTelemetry"
+}
+
+func (t *SelfTelemetryTestRunner) GetAgentConfigFileName() string {
+	return "self_telemetry_config.json"
+}
+
+// SetupBeforeAgentRun writes the scrape config of the telemetry endpoint referenced by the agent config
+func (t *SelfTelemetryTestRunner) SetupBeforeAgentRun() error {
+	if err := t.BaseTestRunner.SetupBeforeAgentRun(); err != nil {
+		return err
+	}
+	return common.RunCommands([]string{
+		fmt.Sprintf("cat <<EOF | sudo tee /tmp/self_telemetry_prometheus.yaml\n%s\nEOF", selfTelemetryPrometheusConfig),
+		fmt.Sprintf("cat <<EOF | sudo tee %s\n%s\nEOF", selfTelemetryOtelConfigPath, selfTelemetryOtelConfig),
+	})
+}
+
+// SetupAfterAgentRun appends the telemetry settings, which only exist in the OTel YAML of the agent, so the agent
+// restarts with its telemetry served on the endpoint the prometheus input scrapes
+func (t *SelfTelemetryTestRunner) SetupAfterAgentRun() error {
+	return common.AppendAgentConfig(selfTelemetryOtelConfigPath)
+}
+
+// GetMeasuredMetrics are the gauges of the basic telemetry level, which are reported as long as the agent runs
+func (t *SelfTelemetryTestRunner) GetMeasuredMetrics() []string {
+	return []string{
+		"otelcol_process_memory_rss",
+		"otelcol_process_runtime_heap_alloc_bytes",
+		"otelcol_process_runtime_total_sys_memory_bytes",
+	}
+}
+
+func (t *SelfTelemetryTestRunner) ValidateMetric(metricName string) status.TestResult {
+	testResult := status.TestResult{
+		Name:   metricName,
+		Status: status.FAILED,
+	}
+
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{
+			Key:   "job",
+			Value: dimension.ExpectedDimensionValue{Value: aws.String(selfTelemetryJob)},
+		},
+	})
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	if !test_runner.ValidateMetricValues(&testResult, namespace, metricName, dims, 0) {
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
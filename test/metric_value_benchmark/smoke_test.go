@@ -0,0 +1,136 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+)
+
+const (
+	smokeLogFile   = "/tmp/cwagent_smoke.log"
+	smokeLogStream = "smoke"
+	// smokeDeliveryTimeout bounds the wait for the log line, the whole runner has to fit the 5 minutes of the gate
+	smokeDeliveryTimeout = 2 * time.Minute
+)
+
+// SmokeTestRunner is the fast gate of pull requests: the agent starts, one metric validates, one log line is
+// delivered and the agent stops cleanly. It is explicit, the suite only runs it with -plugins=smoke.
+type SmokeTestRunner struct {
+	test_runner.BaseTestRunner
+	start time.Time
+}
+
+var _ test_runner.ITestRunner = (*SmokeTestRunner)(nil)
+var _ test_runner.Explicit = (*SmokeTestRunner)(nil)
+var _ test_runner.StartupPipelines = (*SmokeTestRunner)(nil)
+
+var smokeMetrics = test_runner.NewMetricSpecs(
+	[]dimension.Instruction{instanceIdInstruction},
+	[]test_runner.MetricValidator{test_runner.NonNegative{}},
+	"mem_used_percent",
+)
+
+func (t *SmokeTestRunner) Validate() status.TestGroupResult {
+	testResults := test_runner.ValidateMetrics(t.DimensionFactory, namespace, smokeMetrics)
+	testResults = append(testResults, t.validateLogLine(), validateCleanStop())
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (t *SmokeTestRunner) GetTestName() string {
+	return "Smoke"
+}
+
+func (t *SmokeTestRunner) GetAgentConfigFileName() string {
+	return "smoke_config.json"
+}
+
+func (t *SmokeTestRunner) GetMeasuredMetrics() []string {
+	return smokeMetrics.Names()
+}
+
+func (t *SmokeTestRunner) IsExplicit() bool {
+	return true
+}
+
+func (t *SmokeTestRunner) GetStartupPipelines() []latency.Pipeline {
+	return []latency.Pipeline{test_runner.LogPipeline("logs", awsservice.GetInstanceId(), smokeLogStream)}
+}
+
+func (t *SmokeTestRunner) SetupAfterAgentRun() error {
+	t.start = time.Now()
+	f, err := os.OpenFile(smokeLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s smoke test line\n", t.start.Format(time.RFC3339Nano))
+	return err
+}
+
+func (t *SmokeTestRunner) Cleanup() {
+	os.Remove(smokeLogFile)
+	awsservice.DeleteLogStream(awsservice.GetInstanceId(), smokeLogStream)
+}
+
+// validateLogLine waits for the line written once the agent started
+func (t *SmokeTestRunner) validateLogLine() status.TestResult {
+	testResult := status.TestResult{Name: "Smoke Log Line", Status: status.FAILED}
+	logGroup := awsservice.GetInstanceId()
+	err := await.WaitUntil(context.Background(), 10*time.Second, smokeDeliveryTimeout, "the smoke log line", func() (bool, error) {
+		err := awsservice.AssertLogCount(logGroup, smokeLogStream, t.start, time.Now(), 1, 0)
+		if errclass.Classify(err) == errclass.NotFoundYet {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateCleanStop checks the agent is no longer running and systemd saw it exit successfully, not killed by the
+// stop timeout or crashed on the way down
+func validateCleanStop() status.TestResult {
+	testResult := status.TestResult{Name: "Agent Stopped Cleanly", Status: status.FAILED}
+	running, err := common.IsAgentRunning()
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	if running {
+		testResult.SetError(errclass.Validationf("the agent is still running after it was stopped"))
+		return testResult
+	}
+	properties, err := common.GetAgentServiceProperties("Result")
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	if properties["Result"] != "success" {
+		testResult.SetError(errclass.Validationf("the agent unit stopped with result %q", properties["Result"]))
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
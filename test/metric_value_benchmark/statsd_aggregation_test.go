@@ -0,0 +1,168 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package metric_value_benchmark
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/load"
+)
+
+const (
+	// Must match statsd_aggregation_config.json
+	statsdAggregationInterval = time.Minute
+	statsdCollectionInterval  = 10 * time.Second
+
+	// statsdAggregationRate sends statsd.counter_1, statsd.counter_2, statsd.gauge_1 and statsd.gauge_2 every second
+	statsdAggregationRate = 4
+	statsdSendInterval    = time.Second
+)
+
+// StatsdAggregationTestRunner sends StatsD metrics with the load generator, which computes what every aggregation
+// interval should contain, and validates the agent publishes one datapoint per interval with the sum of the counters
+// and the max of the gauges that were sent in it
+type StatsdAggregationTestRunner struct {
+	test_runner.BaseTestRunner
+	start       time.Time
+	generator   *load.StatsdGenerator
+	expectation *load.Expectation
+}
+
+var _ test_runner.ITestRunner = (*StatsdAggregationTestRunner)(nil)
+
+func (t *StatsdAggregationTestRunner) Validate() status.TestGroupResult {
+	if err := t.generator.Stop(); err != nil {
+		testResult := status.TestResult{Name: "statsd load", Status: status.FAILED}
+		testResult.SetError(err)
+		return status.TestGroupResult{Name: t.GetTestName(), TestResults: []status.TestResult{testResult}}
+	}
+	// the agent was stopped at the end of its run, what was sent afterwards was never published
+	intervals := t.expectation.Intervals(t.start, t.start.Add(t.GetAgentRunDuration()))
+	names := t.expectation.Names()
+	results := make([]status.TestResult, len(names))
+	for i, name := range names {
+		results[i] = t.validateAggregation(name, intervals)
+	}
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: results,
+	}
+}
+
+func (t *StatsdAggregationTestRunner) GetTestName() string {
+	return "StatsDAggregation"
+}
+
+func (t *StatsdAggregationTestRunner) GetAgentConfigFileName() string {
+	return "statsd_aggregation_config.json"
+}
+
+func (t *StatsdAggregationTestRunner) GetAgentRunDuration() time.Duration {
+	return 5 * time.Minute
+}
+
+func (t *StatsdAggregationTestRunner) GetMeasuredMetrics() []string {
+	return []string{"statsd.counter_1", "statsd.counter_2", "statsd.gauge_1", "statsd.gauge_2"}
+}
+
+func (t *StatsdAggregationTestRunner) SetupAfterAgentRun() error {
+	t.expectation = load.NewExpectation(statsdAggregationInterval)
+	t.generator = load.NewStatsdGenerator("", statsdAggregationRate, statsdSendInterval)
+	t.generator.SetExpectation(t.expectation)
+	t.start = time.Now()
+	return t.generator.Start()
+}
+
+// validateAggregation compares every interval fully within the run with what the generator sent in it. The agent
+// attributes what it receives to the collection that follows, so a counter may be off by what is sent in one
+// collection interval.
+func (t *StatsdAggregationTestRunner) validateAggregation(name string, intervals []time.Time) status.TestResult {
+	testResult := status.TestResult{
+		Name:   name,
+		Status: status.FAILED,
+	}
+	if len(intervals) == 0 {
+		testResult.SetError(errclass.Validationf("the run has no full aggregation interval"))
+		return testResult
+	}
+	metricType := "gauge"
+	if strings.Contains(name, "counter") {
+		metricType = "counter"
+	}
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+		{Key: "metric_type", Value: dimension.ExpectedDimensionValue{Value: aws.String(metricType)}},
+	})
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+
+	start, end := intervals[0], intervals[len(intervals)-1].Add(statsdAggregationInterval)
+	period := int32(statsdAggregationInterval / time.Second)
+	fetcher := metric.MetricValueFetcher{}
+	sampleCounts, err := fetcher.FetchPeriods(namespace, name, dims, metric.SAMPLE_COUNT, period, start, end)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	if err = validateCadence(sampleCounts, intervals); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+
+	stat, expectedValue := metric.MAXIMUM, func(a load.Aggregate) float64 { return a.Max }
+	tolerance := func(load.Aggregate) float64 { return 0 }
+	if metricType == "counter" {
+		stat, expectedValue = metric.SUM, func(a load.Aggregate) float64 { return a.Sum }
+		tolerance = func(a load.Aggregate) float64 {
+			return a.Sum * float64(statsdCollectionInterval) / float64(statsdAggregationInterval)
+		}
+	}
+	values, err := fetcher.FetchPeriods(namespace, name, dims, stat, period, start, end)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	for _, interval := range intervals {
+		sent := t.expectation.Bucket(name, interval)
+		expected, actual := expectedValue(sent), values[interval]
+		if math.Abs(actual-expected) > tolerance(sent) {
+			testResult.SetValueMismatch(fmt.Sprintf("%s of %v ± %v at %s", stat, expected, tolerance(sent), interval.Format(time.RFC3339)), []float64{actual})
+			return testResult
+		}
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// validateCadence expects one datapoint per aggregation interval, aggregated from one sample per collection interval
+func validateCadence(sampleCounts map[time.Time]float64, intervals []time.Time) error {
+	expected := float64(statsdAggregationInterval / statsdCollectionInterval)
+	for _, interval := range intervals {
+		count, ok := sampleCounts[interval]
+		if !ok {
+			return errclass.Validationf("no datapoint for the aggregation interval at %s", interval.Format(time.RFC3339))
+		}
+		// a collection may fall on either side of the boundary of the interval
+		if math.Abs(count-expected) > 1 {
+			return errclass.Validationf("the aggregation interval at %s has %v samples, expected %v", interval.Format(time.RFC3339), count, expected)
+		}
+	}
+	return nil
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/DataDog/datadog-go/statsd"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/completeness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/load"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 const (
@@ -25,6 +28,11 @@ var _ test_runner.ITestRunner = (*StatsdTestRunner)(nil)
 
 type StatsdTestRunner struct {
 	test_runner.BaseTestRunner
+	start time.Time
+	// timings counts the timing packets sent per timer, each one is a sample in CloudWatch
+	timings map[string]*completeness.Counter
+	// timingValues records the values of the timings sent, in milliseconds, to reconstruct their distribution
+	timingValues *load.Expectation
 }
 
 func (t *StatsdTestRunner) Validate() status.TestGroupResult {
@@ -35,12 +43,49 @@ func (t *StatsdTestRunner) Validate() status.TestGroupResult {
 	for i, metricName := range metricsToFetch {
 		results[i] = metric.ValidateStatsdMetric(t.DimensionFactory, namespace, "InstanceId", metricName, metric.StatsdMetricValues[i], t.GetAgentRunDuration(), send_interval)
 	}
+	results = append(results, t.validateTimingDistributions()...)
 	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: results,
+		Name:         t.GetTestName(),
+		TestResults:  results,
+		Completeness: t.reconcileTimings(),
 	}
 }
 
+// reconcileTimings compares the timing packets sent with the sample count of the timers. Counters and gauges are
+// aggregated by the agent, so only the timers can be reconciled packet for packet.
+func (t *StatsdTestRunner) reconcileTimings() []completeness.Report {
+	var reports []completeness.Report
+	for _, name := range metric.StatsdMetricNames {
+		counter, ok := t.timings[name]
+		if !ok {
+			continue
+		}
+		observed, err := metric.FetchStatsdSampleCount(t.DimensionFactory, namespace, "InstanceId", name, t.start, time.Now())
+		if err != nil {
+			logger.Warnf("Could not fetch the sample count of %s: %v", name, err)
+			continue
+		}
+		reports = append(reports, counter.Reconcile(completeness.StatsdPackets, name, observed))
+	}
+	return reports
+}
+
+// validateTimingDistributions compares the distribution of every timer over the minutes fully within the run with the
+// distribution of the values sent, the average alone can't tell a bad aggregation apart
+func (t *StatsdTestRunner) validateTimingDistributions() []status.TestResult {
+	intervals := t.timingValues.Intervals(t.start, t.start.Add(t.GetAgentRunDuration()))
+	if len(intervals) == 0 {
+		return nil
+	}
+	start, end := intervals[0], intervals[len(intervals)-1].Add(time.Minute)
+	var results []status.TestResult
+	for _, name := range t.timingValues.Names() {
+		expected := metric.NewDistribution(t.timingValues.Values(name, start, end), metric.StatsdTimingPercentiles...)
+		results = append(results, metric.ValidateStatsdDistribution(t.DimensionFactory, namespace, "InstanceId", name, expected, start, end))
+	}
+	return results
+}
+
 func (t *StatsdTestRunner) GetTestName() string {
 	return "EC2StatsD"
 }
@@ -54,6 +99,14 @@ func (t *StatsdTestRunner) GetAgentRunDuration() time.Duration {
 }
 
 func (t *StatsdTestRunner) SetupAfterAgentRun() error {
+	t.start = time.Now()
+	t.timings = map[string]*completeness.Counter{}
+	t.timingValues = load.NewExpectation(time.Minute)
+	for _, name := range metric.StatsdMetricNames {
+		if strings.Contains(name, "timing") {
+			t.timings[name] = &completeness.Counter{}
+		}
+	}
 	// Send each metric once a second.
 	go t.sender()
 	return nil
@@ -88,8 +141,11 @@ func (t *StatsdTestRunner) sender() {
 					v := time.Millisecond * time.Duration(metric.StatsdMetricValues[i])
 					v -= 100 * time.Millisecond
 					client.Timing(name, v, tags, 1.0)
+					t.timingValues.Add(name, float64(v/time.Millisecond), time.Now())
 					v += 200 * time.Millisecond
 					client.Timing(name, v, tags, 1.0)
+					t.timingValues.Add(name, float64(v/time.Millisecond), time.Now())
+					t.timings[name].Add(2)
 				}
 			}
 		}
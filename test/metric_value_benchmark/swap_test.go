@@ -6,9 +6,6 @@
 package metric_value_benchmark
 
 import (
-	"log"
-
-	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
@@ -20,16 +17,18 @@ type SwapTestRunner struct {
 
 var _ test_runner.ITestRunner = (*SwapTestRunner)(nil)
 
-func (t *SwapTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateSwapMetric(metricName)
-	}
+var swapMetrics = test_runner.NewMetricSpecs(
+	[]dimension.Instruction{instanceIdInstruction},
+	[]test_runner.MetricValidator{test_runner.NonNegative{}, test_runner.Unit{}},
+	"swap_free",
+	"swap_used",
+	"swap_used_percent",
+)
 
+func (t *SwapTestRunner) Validate() status.TestGroupResult {
 	return status.TestGroupResult{
 		Name:        t.GetTestName(),
-		TestResults: testResults,
+		TestResults: test_runner.ValidateMetrics(t.DimensionFactory, namespace, swapMetrics),
 	}
 }
 
@@ -42,41 +41,5 @@ func (t *SwapTestRunner) GetAgentConfigFileName() string {
 }
 
 func (t *SwapTestRunner) GetMeasuredMetrics() []string {
-	return []string{
-		"swap_free",
-		"swap_used",
-		"swap_used_percent",
-	}
-}
-
-func (t *SwapTestRunner) validateSwapMetric(metricName string) status.TestResult {
-	testResult := status.TestResult{
-		Name:   metricName,
-		Status: status.FAILED,
-	}
-
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
-		{
-			Key:   "InstanceId",
-			Value: dimension.UnknownDimensionValue(),
-		},
-	})
-
-	if len(failed) > 0 {
-		return testResult
-	}
-
-	fetcher := metric.MetricValueFetcher{}
-	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
-	log.Printf("metric values are %v", values)
-	if err != nil {
-		return testResult
-	}
-
-	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, 0) {
-		return testResult
-	}
-
-	testResult.Status = status.SUCCESSFUL
-	return testResult
+	return swapMetrics.Names()
 }
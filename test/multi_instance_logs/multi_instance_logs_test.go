@@ -0,0 +1,98 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package multi_instance_logs
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+const (
+	pollInterval = 30 * time.Second
+	// deliveryTimeout covers the writers installing the agent from userdata before they write their logs
+	deliveryTimeout = 20 * time.Minute
+)
+
+var (
+	envMetaDataStrings = &(environment.MetaDataStrings{})
+	logGroup           string
+	writerInstanceIds  string
+	eventsPerWriter    int
+)
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+	flag.StringVar(&logGroup, "logGroup", "", "Log group every writer instance sends its logs to")
+	flag.StringVar(&writerInstanceIds, "writerInstanceIds", "", "Comma-delimited list of the writer instance ids, which are also the log stream names")
+	flag.IntVar(&eventsPerWriter, "eventsPerWriter", 0, "Number of log lines every writer instance writes")
+}
+
+// TestMultiInstanceLogs validates that every writer instance delivered exactly its own lines to its own stream of the
+// shared log group. The writers are provisioned by terraform/ec2/multi_instance and prefix every line with their
+// instance id, so a line in the wrong stream shows up as a foreign instance id.
+func TestMultiInstanceLogs(t *testing.T) {
+	require.NotEmpty(t, logGroup, "-logGroup is required")
+	require.NotEmpty(t, writerInstanceIds, "-writerInstanceIds is required")
+	require.Greater(t, eventsPerWriter, 0, "-eventsPerWriter is required")
+	instanceIds := strings.Split(writerInstanceIds, ",")
+	defer awsservice.DeleteLogGroup(logGroup)
+
+	total := 0
+	for _, instanceId := range instanceIds {
+		lines, err := waitForStream(instanceId)
+		require.NoError(t, err)
+
+		for _, line := range lines {
+			assert.Truef(t, strings.HasPrefix(line, instanceId+" "), "stream %s contains a line of another instance: %s", instanceId, line)
+		}
+		assert.Lenf(t, lines, eventsPerWriter, "stream %s has an unexpected number of events", instanceId)
+		total += len(lines)
+	}
+	assert.Equal(t, eventsPerWriter*len(instanceIds), total, "unexpected number of events across the fleet")
+
+	for _, stream := range awsservice.GetLogStreams(logGroup) {
+		assert.Containsf(t, instanceIds, aws.ToString(stream.LogStreamName), "log group %s has a stream of an unknown instance", logGroup)
+	}
+}
+
+// waitForStream returns the lines of the stream once all the lines of the writer arrived, or what arrived so far
+// when the writer did not finish in time
+func waitForStream(instanceId string) ([]string, error) {
+	var lines []string
+	description := fmt.Sprintf("%d events in stream %s of log group %s", eventsPerWriter, instanceId, logGroup)
+	err := await.WaitUntil(context.Background(), pollInterval, deliveryTimeout, description, func() (bool, error) {
+		_, err := awsservice.ValidateLogs(logGroup, instanceId, nil, nil, awsservice.StringValidator(func(logs []string) bool {
+			lines = logs
+			return true
+		}))
+		if err != nil {
+			// the writer may not have created its stream yet
+			log.Printf("Failed to read stream %s: %v", instanceId, err)
+			return false, nil
+		}
+		return len(lines) >= eventsPerWriter, nil
+	})
+	var timeout *await.TimeoutError
+	if errors.As(err, &timeout) {
+		// report the counts below rather than the timeout
+		return lines, nil
+	}
+	return lines, err
+}
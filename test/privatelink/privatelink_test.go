@@ -0,0 +1,170 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package privatelink
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/scenario"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	namespace            = "PrivateLinkTest"
+	agentRuntime         = 3 * time.Minute
+	agentConfigLocalPath = "agent_configs/config.json"
+	agentConfigPath      = "/opt/aws/amazon-cloudwatch-agent/bin/config.json"
+	logFilePath          = "/tmp/privatelink.log"
+	logStreamName        = "privatelink"
+	logLine              = "privatelink log line"
+	logLines             = 10
+	// internetHost is dialed to check the subnet has no route to the internet left
+	internetHost = "github.com:443"
+)
+
+// endpointServices are the services terraform/ec2/privatelink creates interface endpoints with private DNS for, the
+// agent and the framework clients both resolve them to the endpoints
+var endpointServices = []string{"monitoring", "logs", "sts", "ec2"}
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestPrivateLink runs on an instance whose subnet has no NAT or internet gateway route, and validates the agent
+// delivers metrics and logs through the VPC interface endpoints
+func TestPrivateLink(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	instanceId := awsservice.GetInstanceId()
+	common.CopyFile(agentConfigLocalPath, agentConfigPath)
+
+	result := scenario.Scenario{
+		Name: "PrivateLink",
+		Steps: []scenario.Step{
+			{Name: "check the endpoints resolve to the VPC", Run: checkPrivateEndpoints},
+			{Name: "check there is no route to the internet", Run: checkNoInternetRoute},
+			scenario.Mark("start"),
+			scenario.StartAgent(agentConfigPath),
+			{Name: "write log lines", Run: func(scenario.State) error { return writeLogLines() }},
+			scenario.Wait(agentRuntime),
+			scenario.StopAgent(),
+			scenario.Mark("end"),
+			{Name: "validate metrics", Run: func(scenario.State) error { return validateMetrics(env) }},
+			{Name: "validate logs", Run: func(state scenario.State) error {
+				return validateLogs(instanceId, state.Time("start"), state.Time("end"))
+			}},
+		},
+		Cleanup: []scenario.Step{
+			{Name: "delete log group", Run: func(scenario.State) error {
+				awsservice.DeleteLogGroupAndStream(instanceId, logStreamName)
+				return nil
+			}},
+		},
+	}.Run()
+
+	for _, r := range result.TestResults {
+		if r.Status != status.SUCCESSFUL {
+			t.Errorf("step %s failed: %s", r.Name, r.Reason)
+		}
+	}
+}
+
+// checkPrivateEndpoints fails unless every endpoint resolves to private addresses only, i.e. the private DNS of the
+// interface endpoints
+func checkPrivateEndpoints(scenario.State) error {
+	region := os.Getenv("AWS_REGION")
+	for _, service := range endpointServices {
+		host := fmt.Sprintf("%s.%s.amazonaws.com", service, region)
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			return err
+		}
+		for _, ip := range ips {
+			if !ip.IsPrivate() {
+				return fmt.Errorf("%s resolves to the public address %s, it does not use the VPC endpoint", host, ip)
+			}
+		}
+	}
+	return nil
+}
+
+func checkNoInternetRoute(scenario.State) error {
+	conn, err := net.DialTimeout("tcp", internetHost, 10*time.Second)
+	if err != nil {
+		return nil
+	}
+	conn.Close()
+	return fmt.Errorf("connected to %s, the subnet still has a route to the internet", internetHost)
+}
+
+func validateMetrics(env *environment.MetaData) error {
+	factory := dimension.GetDimensionFactory(*env)
+	dims, err := factory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+		{Key: "cpu", Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")}},
+	})
+	if err != nil {
+		return err
+	}
+	fetcher := metric.MetricValueFetcher{}
+	for _, name := range []string{"cpu_usage_active", "cpu_usage_idle", "cpu_usage_system", "cpu_usage_user"} {
+		values, err := fetcher.Fetch(namespace, name, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+		if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(name, values, 0) {
+			return fmt.Errorf("%s was not delivered through the VPC endpoint", name)
+		}
+	}
+	return nil
+}
+
+func validateLogs(instanceId string, start, end time.Time) error {
+	count := 0
+	ok, err := awsservice.ValidateLogs(instanceId, logStreamName, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		for _, l := range logs {
+			if strings.Contains(l, logLine) {
+				count++
+			}
+		}
+		return count == logLines
+	}))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("found %d of the %d log lines", count, logLines)
+	}
+	return nil
+}
+
+func writeLogLines() error {
+	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for i := 0; i < logLines; i++ {
+		if _, err = fmt.Fprintf(f, "%s %d\n", logLine, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@
 package proxy
 
 import (
+	"log"
+
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -25,7 +27,7 @@ func GetCommandToCreateProxyConfig(proxyUrl string) []string {
 func getDimensions(instanceId string) []types.Dimension {
 	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
 	factory := dimension.GetDimensionFactory(*env)
-	dims, failed := factory.GetDimensions([]dimension.Instruction{
+	dims, err := factory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -36,7 +38,8 @@ func getDimensions(instanceId string) []types.Dimension {
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		log.Printf("failed to get dimensions: %v", err)
 		return []types.Dimension{}
 	}
 
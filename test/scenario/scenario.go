@@ -0,0 +1,144 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package scenario
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// State is shared by the steps of a scenario, e.g. a load step records when it started for a later validate step
+type State map[string]interface{}
+
+// Time returns the time stored under key, the zero time when there is none
+func (s State) Time(key string) time.Time {
+	t, _ := s[key].(time.Time)
+	return t
+}
+
+// Step is one stage of a scenario. A step that returns an error fails the scenario and the remaining steps do not run.
+type Step struct {
+	Name string
+	Run  func(state State) error
+}
+
+// Scenario runs ordered steps, e.g. generate load, wait, restart the agent and validate, and reports each of them as
+// a test result, so a resilience test reads as its steps instead of one long function
+type Scenario struct {
+	Name  string
+	Steps []Step
+	// Cleanup steps always run after the steps, in order, and only their errors are logged
+	Cleanup []Step
+}
+
+// Run runs the steps until one fails and returns the result of every step that ran
+func (s Scenario) Run() status.TestGroupResult {
+	result := status.TestGroupResult{Name: s.Name}
+	state := State{}
+	defer s.cleanup(state)
+
+	for i, step := range s.Steps {
+		name := fmt.Sprintf("%d. %s", i+1, step.Name)
+		logger.Infof("Scenario %s: running step %s", s.Name, name)
+		start := time.Now()
+		err := step.Run(state)
+		stepResult := status.TestResult{Name: name, Status: status.SUCCESSFUL, Duration: time.Since(start)}
+		if err != nil {
+			stepResult.Status = status.FAILED
+			stepResult.SetError(err)
+		}
+		result.TestResults = append(result.TestResults, stepResult)
+		if err != nil {
+			if skipped := len(s.Steps) - i - 1; skipped > 0 {
+				logger.Warnf("Scenario %s: step %s failed, skipping the remaining %d steps", s.Name, name, skipped)
+			}
+			break
+		}
+	}
+	return result
+}
+
+func (s Scenario) cleanup(state State) {
+	for _, step := range s.Cleanup {
+		if err := step.Run(state); err != nil {
+			logger.Warnf("Scenario %s: cleanup %s failed: %v", s.Name, step.Name, err)
+		}
+	}
+}
+
+// Mark records the current time under key, e.g. to validate only what was published after a restart
+func Mark(key string) Step {
+	return Step{Name: "mark " + key, Run: func(state State) error {
+		state[key] = time.Now()
+		return nil
+	}}
+}
+
+// Wait sleeps for the duration, e.g. to let the agent publish what was generated
+func Wait(d time.Duration) Step {
+	return Step{Name: fmt.Sprintf("wait %s", d), Run: func(State) error {
+		time.Sleep(d)
+		return nil
+	}}
+}
+
+// StartAgent starts the agent with the config, which is expected to be copied to configOutputPath already
+func StartAgent(configOutputPath string) Step {
+	return Step{Name: "start agent", Run: func(State) error {
+		return common.StartAgent(configOutputPath, false, false)
+	}}
+}
+
+// StopAgent stops the agent
+func StopAgent() Step {
+	return Step{Name: "stop agent", Run: func(State) error {
+		common.StopAgent()
+		return nil
+	}}
+}
+
+// RestartAgent stops the agent and starts it again with the config
+func RestartAgent(configOutputPath string) Step {
+	return Step{Name: "restart agent", Run: func(State) error {
+		common.StopAgent()
+		return common.StartAgent(configOutputPath, false, false)
+	}}
+}
+
+// RestartEcsTasks stops the running tasks of the service and waits for the scheduler to replace them, the agent
+// restarts without a new deployment
+func RestartEcsTasks(clusterArn, serviceName string, timeout time.Duration) Step {
+	return Step{Name: "restart ecs tasks of " + serviceName, Run: func(State) error {
+		taskArns, err := awsservice.ListServiceTasks(clusterArn, serviceName)
+		if err != nil {
+			return err
+		}
+		for _, taskArn := range taskArns {
+			if err = awsservice.StopTask(clusterArn, taskArn, "restarted by scenario"); err != nil {
+				return err
+			}
+		}
+		// the running count only drops once the tasks stopped, the service looks stable before that
+		for _, taskArn := range taskArns {
+			if err = awsservice.WaitForTaskStatus(clusterArn, taskArn, "STOPPED", timeout); err != nil {
+				return err
+			}
+		}
+		return awsservice.WaitForServiceStable(clusterArn, serviceName, timeout)
+	}}
+}
+
+// RollEcsDeployment forces a new deployment of the service and waits for it to roll out
+func RollEcsDeployment(clusterArn, serviceName string, timeout time.Duration) Step {
+	return Step{Name: "roll ecs deployment of " + serviceName, Run: func(State) error {
+		return awsservice.RollDeployment(clusterArn, serviceName, timeout)
+	}}
+}
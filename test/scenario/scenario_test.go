@@ -0,0 +1,67 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package scenario
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+)
+
+func TestRunSharesStateAndReportsEachStep(t *testing.T) {
+	var cleaned bool
+	s := Scenario{
+		Name: "scenario",
+		Steps: []Step{
+			Mark("start"),
+			{Name: "generate", Run: func(state State) error {
+				state["lines"] = 10
+				return nil
+			}},
+			{Name: "validate", Run: func(state State) error {
+				if state.Time("start").IsZero() || state["lines"] != 10 {
+					return errors.New("state was not shared")
+				}
+				return nil
+			}},
+		},
+		Cleanup: []Step{{Name: "cleanup", Run: func(State) error {
+			cleaned = true
+			return nil
+		}}},
+	}
+
+	result := s.Run()
+	assert.Equal(t, status.SUCCESSFUL, result.GetStatus())
+	assert.Len(t, result.TestResults, 3)
+	assert.Equal(t, "2. generate", result.TestResults[1].Name)
+	assert.True(t, cleaned)
+}
+
+func TestRunStopsAtFailedStep(t *testing.T) {
+	var ran bool
+	s := Scenario{
+		Name: "scenario",
+		Steps: []Step{
+			{Name: "fail", Run: func(State) error { return errors.New("boom") }},
+			Wait(time.Hour),
+			{Name: "never", Run: func(State) error {
+				ran = true
+				return nil
+			}},
+		},
+	}
+
+	result := s.Run()
+	assert.Equal(t, status.FAILED, result.GetStatus())
+	assert.Len(t, result.TestResults, 1)
+	assert.Equal(t, "boom", result.TestResults[0].Reason)
+	assert.False(t, ran)
+}
@@ -0,0 +1,16 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package service_recovery
+
+import (
+	"errors"
+)
+
+// Validate fails since the service recovery settings are a feature of the Windows service control manager. It is
+// defined so the validator, which dispatches to this package, builds on every platform.
+func Validate() error {
+	return errors.New("the service recovery test only runs on Windows")
+}
@@ -0,0 +1,169 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+// +build windows
+
+package service_recovery
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// agent config json file in Temp dir gets written by terraform
+	configWindowsJSON       = "C:\\Users\\Administrator\\AppData\\Local\\Temp\\agent_config.json"
+	configWindowsOutputPath = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\config.json"
+	metricWindowsNamespace  = "ServiceRecoveryWindowsTest"
+	metricName              = "Memory % Committed Bytes In Use"
+	serviceName             = "AmazonCloudWatchAgent"
+
+	agentWindowsRuntime = 3 * time.Minute
+	// recoveryTimeout covers the restart delay of the recovery actions and the service start
+	recoveryTimeout = 2 * time.Minute
+	pollInterval    = 5 * time.Second
+	// ingestionDelay lets CloudWatch aggregate the last datapoints before they are counted
+	ingestionDelay = time.Minute
+	// minSamplesPerWindow is half of what the 10s collection interval produces over the runtime, which leaves room for
+	// the partial minutes at the edges of the windows
+	minSamplesPerWindow = int(agentWindowsRuntime/(10*time.Second)) / 2
+)
+
+type service struct {
+	state     string
+	processId int
+}
+
+// Validate checks the service control manager restarts the agent when its process is killed, but not when the
+// service is stopped on purpose, and that the metrics keep flowing after the recovery
+func Validate() error {
+	err := common.CopyFile(configWindowsJSON, configWindowsOutputPath)
+	if err != nil {
+		logger.Errorf("Copying agent config file failed: %v", err)
+		return err
+	}
+
+	err = common.StartAgent(configWindowsOutputPath, true, false)
+	if err != nil {
+		logger.Errorf("Starting agent failed: %v", err)
+		return err
+	}
+	if err = checkRecoveryActions(); err != nil {
+		return err
+	}
+
+	if err = checkStopIsNotRecovered(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	time.Sleep(agentWindowsRuntime)
+	before, err := getService()
+	if err != nil {
+		return err
+	}
+	killedAt := time.Now()
+	if _, err = common.RunCommand(fmt.Sprintf("Stop-Process -Id %d -Force", before.processId)); err != nil {
+		logger.Errorf("Killing the agent process %d failed: %v", before.processId, err)
+		return err
+	}
+	recovered, err := waitForRecovery(before.processId)
+	if err != nil {
+		return err
+	}
+	recoveredAt := time.Now()
+	logger.Infof("Agent recovered as process %d after %s", recovered.processId, recoveredAt.Sub(killedAt).Round(time.Second))
+
+	time.Sleep(agentWindowsRuntime)
+	err = common.StopAgent()
+	if err != nil {
+		logger.Errorf("Stopping agent failed: %v", err)
+		return err
+	}
+	stoppedAt := time.Now()
+	time.Sleep(ingestionDelay)
+
+	dims := []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())}}
+	if !awsservice.ValidateSampleCount(metricName, metricWindowsNamespace, dims, start, killedAt, minSamplesPerWindow, math.MaxInt32, 60) {
+		return fmt.Errorf("%s has less than %d samples before the agent was killed", metricName, minSamplesPerWindow)
+	}
+	if !awsservice.ValidateSampleCount(metricName, metricWindowsNamespace, dims, recoveredAt, stoppedAt, minSamplesPerWindow, math.MaxInt32, 60) {
+		return fmt.Errorf("%s has less than %d samples after the agent recovered", metricName, minSamplesPerWindow)
+	}
+	return nil
+}
+
+// checkRecoveryActions validates the installer configured the service to restart on failure
+func checkRecoveryActions() error {
+	out, err := common.RunCommand("sc.exe qfailure " + serviceName)
+	if err != nil {
+		logger.Errorf("Querying the recovery actions of %s failed: %v", serviceName, err)
+		return err
+	}
+	if !strings.Contains(out, "RESTART") {
+		return fmt.Errorf("service %s has no restart recovery action: %s", serviceName, out)
+	}
+	return nil
+}
+
+// checkStopIsNotRecovered stops the service like an operator would and validates the recovery actions, which are
+// for failures, leave it stopped, then starts the agent again
+func checkStopIsNotRecovered() error {
+	if _, err := common.RunCommand("Stop-Service " + serviceName); err != nil {
+		logger.Errorf("Stopping the %s service failed: %v", serviceName, err)
+		return err
+	}
+	time.Sleep(recoveryTimeout)
+	s, err := getService()
+	if err != nil {
+		return err
+	}
+	if s.state != "Stopped" {
+		return fmt.Errorf("service %s is %s after it was stopped, recovery actions must only apply to failures", serviceName, s.state)
+	}
+	return common.StartAgent(configWindowsOutputPath, true, false)
+}
+
+// waitForRecovery polls the service until it runs as a process other than the killed one
+func waitForRecovery(killedProcessId int) (service, error) {
+	deadline := time.Now().Add(recoveryTimeout)
+	for {
+		s, err := getService()
+		if err == nil && s.state == "Running" && s.processId != killedProcessId {
+			return s, nil
+		}
+		if time.Now().After(deadline) {
+			return s, fmt.Errorf("service %s was not restarted %s after process %d was killed, it is %s: %v",
+				serviceName, recoveryTimeout, killedProcessId, s.state, err)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func getService() (service, error) {
+	out, err := common.RunCommand(fmt.Sprintf(
+		"$s = Get-CimInstance Win32_Service -Filter \"Name='%s'\"; Write-Output \"$($s.State) $($s.ProcessId)\"", serviceName))
+	if err != nil {
+		return service{}, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return service{}, fmt.Errorf("unexpected state of service %s: %q", serviceName, out)
+	}
+	processId, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return service{}, fmt.Errorf("unexpected process id of service %s: %q", serviceName, out)
+	}
+	return service{state: fields[0], processId: processId}, nil
+}
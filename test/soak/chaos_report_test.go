@@ -0,0 +1,71 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+package soak
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/chaos"
+)
+
+const (
+	// chaosReportPeriod is the collection interval of the soak configs
+	chaosReportPeriod = time.Minute
+	// chaosReportSlack is how long after a fault the data of a period may still be missing, e.g. while the agent
+	// retries the requests which failed during a network block
+	chaosReportSlack = 2 * time.Minute
+	// chaosReportIngestion leaves out the last periods, which may not be ingested yet
+	chaosReportIngestion = 5 * time.Minute
+)
+
+// TestSoakChaosReport correlates the periods the soak host published no memory metric with the faults the chaos
+// scheduler injected, and fails on the gaps none of them explains. It runs at the end of a soak run and is skipped
+// when no faults were recorded.
+func TestSoakChaosReport(t *testing.T) {
+	environment.GetEnvironmentMetaData(envMetaDataStrings)
+	f, err := os.Open(chaosEventsPath())
+	if os.IsNotExist(err) {
+		t.Skip("the chaos scheduler did not run, -soakChaosInterval is not set")
+	}
+	require.NoError(t, err)
+	defer f.Close()
+	events, err := chaos.ReadEvents(f)
+	require.NoError(t, err)
+	if len(events) == 0 {
+		t.Skip("the chaos scheduler did not inject any fault yet")
+	}
+
+	namespace, metricName, dims := chaosReportMetric()
+	start := events[0].Start.Add(-chaosReportSlack).Truncate(chaosReportPeriod)
+	end := time.Now().Add(-chaosReportIngestion).Truncate(chaosReportPeriod)
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.FetchPeriods(namespace, metricName, dims, metric.SAMPLE_COUNT, int32(chaosReportPeriod.Seconds()), start, end)
+	require.NoError(t, err)
+
+	gaps := chaos.MissingPeriods(values, start, end, chaosReportPeriod)
+	report := chaos.Report{Events: events, Correlations: chaos.Correlate(gaps, events, chaosReportSlack)}
+	t.Logf("%s between %s and %s:\n%s", metricName, start.Format(time.RFC3339), end.Format(time.RFC3339), report)
+	assert.Empty(t, report.Unexplained(), "%s has gaps no injected fault explains", metricName)
+}
+
+// chaosReportMetric is the memory metric of the soak config of the platform, the soak hosts are the only ones of
+// their instance type in the namespace
+func chaosReportMetric() (string, string, []types.Dimension) {
+	dims := []types.Dimension{{Name: aws.String("InstanceType"), Value: aws.String(awsservice.GetInstanceType())}}
+	if runtime.GOOS == "windows" {
+		dims = append(dims, types.Dimension{Name: aws.String("objectname"), Value: aws.String("Memory")})
+		return "CWAgent/SoakTestHighWindows", "Memory % Committed Bytes In Use", dims
+	}
+	return "CWAgent/SoakTestHighLinux", "mem_used_percent", dims
+}
@@ -5,12 +5,17 @@ package soak
 import (
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/chaos"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/shirou/gopsutil/v3/process"
 	"github.com/stretchr/testify/require"
 )
@@ -54,11 +59,13 @@ func TestSoakHigh(t *testing.T) {
 }
 
 func runTest(t *testing.T, configPath string, tc testConfig) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
 	common.CopyFile(configPath, common.ConfigOutputPath)
 	require.NoError(t, common.StartAgent(common.ConfigOutputPath, false, false))
 	require.NoError(t, startLogGen(tc.logFileCount, tc.linesPerSecond, tc.lineSizeBytes))
 	require.NoError(t, startEMFGen(tc.emfFileCount, tc.eventsPerSecond))
 	require.NoError(t, startStatsd(tc.statsdClientCount, tc.tps, tc.metricCount))
+	require.NoError(t, startChaos(env.SoakChaosInterval, env.SoakChaosSeed))
 }
 
 // startLogGen starts a long running process that writes lines to log files.
@@ -96,6 +103,51 @@ func startStatsd(clientNum int, tps int, metricNum int) error {
 	return common.RunAsyncCommand(cmd)
 }
 
+// startChaos starts a long running process that injects faults on a random schedule, when the interval is set. The
+// events of a previous run are removed, so TestSoakChaosReport only correlates the gaps with the faults of this run.
+func startChaos(interval time.Duration, seed int64) error {
+	err := stopExisting("chaos-scheduler")
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(chaosEventsPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if interval <= 0 {
+		return nil
+	}
+	cmd := fmt.Sprintf("go run ../../cmd/chaos-scheduler -interval=%s -seed=%d", interval, seed)
+	return common.RunAsyncCommand(cmd)
+}
+
+// stopExisting is like killExisting but lets the processes exit on their own, a fault in flight must be undone
+func stopExisting(name string) error {
+	procs, err := process.Processes()
+	if err != nil {
+		return err
+	}
+	for _, p := range procs {
+		c, _ := p.Cmdline()
+		if strings.Contains(c, name) {
+			n, _ := p.Name()
+			logger.Infof("stopping process, name %s, cmdline %s", n, c)
+			err = p.Terminate()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// chaosEventsPath is where the chaos scheduler records its events, next to the files of the log generator
+func chaosEventsPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join("C:\\tmp\\soakTest", chaos.EventsFileName)
+	}
+	return filepath.Join("/tmp/soakTest", chaos.EventsFileName)
+}
+
 // killExisting will search the command line of every process and kill any that match.
 // Return nil if no matches found, or if all matches killed successfuly.
 func killExisting(name string) error {
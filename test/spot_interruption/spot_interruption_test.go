@@ -0,0 +1,275 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package spot_interruption
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	metricNamespace = "SpotInterruptionTest"
+	metricName      = "mem_used_percent"
+	// collectionInterval is the interval of the metric, the final datapoint is at most one interval before the stop
+	collectionInterval = 10 * time.Second
+	// flushInterval is longer than the agent runs before the notice, so the lines and datapoints are still buffered
+	// when the agent stops and are only delivered if it flushes them on the way down
+	flushInterval = 120
+	bufferTime    = 45 * time.Second
+	// noticeTimeout is how long FIS takes at most to send the notice once the experiment starts
+	noticeTimeout = 5 * time.Minute
+	// simulatedNotice is the time to the interruption the notice gives, the same as the real one
+	simulatedNotice = 2 * time.Minute
+	// terminationMargin is kept before the interruption, the test must pass before the instance is gone
+	terminationMargin = 15 * time.Second
+	pollInterval      = 10 * time.Second
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestSpotInterruption runs the agent with logs and metrics buffered until the spot instance gets its interruption
+// notice, then stops the agent the way the shutdown of the interrupted instance does. The lines written and the
+// datapoints collected up to the stop must all be delivered before the instance is terminated.
+func TestSpotInterruption(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	dir := t.TempDir()
+	logGroup := runid.Name("spot-interruption")
+	logStream := awsservice.GetInstanceId()
+	ns := namespace.Scope(metricNamespace)
+	defer awsservice.DeleteLogGroup(logGroup)
+
+	logFile := filepath.Join(dir, "spot.log")
+	require.NoError(t, writeAgentConfig(dir, logFile, logGroup, logStream, ns))
+	start := time.Now()
+	require.NoError(t, common.StartAgent(common.ConfigOutputPath, false, false))
+
+	writer := startLogWriter(logFile)
+	time.Sleep(bufferTime)
+	interruptAt, err := waitForNotice(env.SpotInterruptionTemplate)
+	if err != nil {
+		writer.stop()
+		common.StopAgent()
+		require.NoError(t, err)
+	}
+	lines := writer.stop()
+	stoppedAt := time.Now()
+	common.StopAgent()
+	deadline := interruptAt.Add(-terminationMargin)
+	logger.Infof("Agent stopped %s before the interruption after %d lines, validating until %s", interruptAt.Sub(stoppedAt).Round(time.Second), lines, deadline.Format(time.RFC3339))
+
+	assert.NoError(t, retryUntil(deadline, func() error {
+		return validateLines(logGroup, logStream, start, lines)
+	}), "buffered log lines were not flushed before the interruption")
+	assert.NoError(t, retryUntil(deadline, func() error {
+		return validateFinalDatapoint(ns, stoppedAt)
+	}), "the final datapoints were not flushed before the interruption")
+}
+
+// waitForNotice starts the FIS experiment and returns the time of the interruption once IMDS serves the notice. The
+// notice is simulated when no experiment template is provided, e.g. on an on-demand instance.
+func waitForNotice(templateId string) (time.Time, error) {
+	if templateId == "" {
+		logger.Infof("No -spotInterruptionTemplateId, simulating the interruption notice")
+		return time.Now().Add(simulatedNotice), nil
+	}
+	lifeCycle, err := awsservice.GetInstanceLifeCycle()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lifeCycle != "spot" {
+		return time.Time{}, fmt.Errorf("the instance life cycle is %s, the interruption needs a spot instance", lifeCycle)
+	}
+	out, err := common.RunCommand(fmt.Sprintf("aws fis start-experiment --experiment-template-id %s --region %s", templateId, awsservice.GetImdsMetadata().Region))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to start the experiment of template %s: %w: %s", templateId, err, out)
+	}
+	logger.Infof("Started the experiment of template %s, waiting for the notice", templateId)
+
+	timeout := time.Now().Add(noticeTimeout)
+	for time.Now().Before(timeout) {
+		action, err := awsservice.GetSpotInstanceAction()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if action != nil {
+			logger.Infof("Received the spot interruption notice, %s at %s", action.Action, action.Time.Format(time.RFC3339))
+			return action.Time, nil
+		}
+		time.Sleep(5 * time.Second)
+	}
+	return time.Time{}, fmt.Errorf("no interruption notice within %s of starting the experiment", noticeTimeout)
+}
+
+func validateLines(logGroup, logStream string, since time.Time, lines int) error {
+	events, err := awsservice.GetLogEvents(logGroup, logStream, &since, nil)
+	if err != nil {
+		return err
+	}
+	if len(events) != lines {
+		return fmt.Errorf("%d of the %d lines written before the stop were delivered", len(events), lines)
+	}
+	return nil
+}
+
+// validateFinalDatapoint checks the datapoint collected in the last interval before the stop was delivered
+func validateFinalDatapoint(ns string, stoppedAt time.Time) error {
+	output, err := awsservice.GetMetricData([]types.MetricDataQuery{{
+		Id: aws.String("final"),
+		MetricStat: &types.MetricStat{
+			Metric: &types.Metric{
+				Namespace:  aws.String(ns),
+				MetricName: aws.String(metricName),
+				Dimensions: []types.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(awsservice.GetInstanceId())}},
+			},
+			Period: aws.Int32(int32(collectionInterval.Seconds())),
+			Stat:   aws.String("SampleCount"),
+		},
+	}}, stoppedAt.Add(-5*time.Minute), stoppedAt.Add(time.Minute))
+	if err != nil {
+		return err
+	}
+	var last time.Time
+	for _, result := range output.MetricDataResults {
+		for _, ts := range result.Timestamps {
+			if ts.After(last) {
+				last = ts
+			}
+		}
+	}
+	if last.IsZero() {
+		return fmt.Errorf("no datapoints of %s delivered", metricName)
+	}
+	// the datapoint of the period the agent stopped in is timestamped at the start of the period
+	if last.Before(stoppedAt.Add(-2 * collectionInterval)) {
+		return fmt.Errorf("the last datapoint of %s is at %s, %s before the agent stopped", metricName, last.Format(time.RFC3339), stoppedAt.Sub(last).Round(time.Second))
+	}
+	return nil
+}
+
+func retryUntil(deadline time.Time, fn func() error) error {
+	for {
+		err := fn()
+		if err == nil || time.Now().Add(pollInterval).After(deadline) {
+			return err
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// logWriter appends a line to the file every second until stopped
+type logWriter struct {
+	done  chan struct{}
+	wg    sync.WaitGroup
+	lines int
+}
+
+func startLogWriter(path string) *logWriter {
+	w := &logWriter{done: make(chan struct{})}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				if err := appendLine(path, w.lines); err != nil {
+					logger.Errorf("Failed to write line %d: %v", w.lines, err)
+					continue
+				}
+				w.lines++
+			}
+		}
+	}()
+	return w
+}
+
+// stop returns the number of lines written
+func (w *logWriter) stop() int {
+	close(w.done)
+	w.wg.Wait()
+	return w.lines
+}
+
+func appendLine(path string, i int) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s spot interruption line %d\n", time.Now().Format(time.RFC3339Nano), i)
+	return err
+}
+
+// writeAgentConfig installs a config collecting the log file and the memory of the host, both flushed less often than
+// the agent runs before the notice
+func writeAgentConfig(dir, logFile, logGroup, logStream, ns string) error {
+	config := map[string]interface{}{
+		"agent": map[string]interface{}{
+			"run_as_user": "root",
+			"debug":       true,
+		},
+		"metrics": map[string]interface{}{
+			"namespace":            ns,
+			"force_flush_interval": flushInterval,
+			"append_dimensions": map[string]interface{}{
+				"InstanceId": "${aws:InstanceId}",
+			},
+			"metrics_collected": map[string]interface{}{
+				"mem": map[string]interface{}{
+					"measurement":                 []string{"used_percent"},
+					"metrics_collection_interval": int(collectionInterval.Seconds()),
+				},
+			},
+		},
+		"logs": map[string]interface{}{
+			"force_flush_interval": flushInterval,
+			"logs_collected": map[string]interface{}{
+				"files": map[string]interface{}{
+					"collect_list": []map[string]interface{}{{
+						"file_path":       logFile,
+						"log_group_name":  logGroup,
+						"log_stream_name": logStream,
+						"timezone":        "UTC",
+					}},
+				},
+			},
+		},
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "config.json")
+	if err = os.WriteFile(path, content, 0644); err != nil {
+		return err
+	}
+	common.CopyFile(path, common.ConfigOutputPath)
+	return nil
+}
@@ -55,7 +55,7 @@ func (t *SslCertTestRunner) validateMetric(metricName string) status.TestResult
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -66,7 +66,8 @@ func (t *SslCertTestRunner) validateMetric(metricName string) status.TestResult
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -17,6 +17,7 @@ import (
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/test_runner"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 )
 
 const namespace = "StatsD"
@@ -31,7 +32,7 @@ func (suite *StatsDTestSuite) SetupSuite() {
 }
 
 func (suite *StatsDTestSuite) TearDownSuite() {
-	suite.Result.Print()
+	report.Send(suite.Result)
 	fmt.Println(">>>> Finished StatsDTestSuite")
 }
 
@@ -8,12 +8,27 @@ package status
 import (
 	"fmt"
 	"log"
+	"sort"
 	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/completeness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/leak"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/notify"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 )
 
 type TestSuiteResult struct {
 	Name             string
 	TestGroupResults []TestGroupResult
+	// AgentVersion is the version of the agent the suite ran against, empty when unknown
+	AgentVersion string
 }
 
 func (r TestSuiteResult) GetStatus() TestStatus {
@@ -26,40 +41,254 @@ func (r TestSuiteResult) GetStatus() TestStatus {
 }
 
 func (r TestSuiteResult) Print() {
-	log.Printf(">>>>>>>>>>>>>>%v<<<<<<<<<<<<<<", r.Name)
-	log.Printf(">>>>>>>>>>>>>>%v<<<<<<<<<<<<<<", string(r.GetStatus()))
+	logger.Infof(">>>>>>>>>>>>>>%v<<<<<<<<<<<<<<", r.Name)
+	logger.Infof(">>>>>>>>>>>>>>%v<<<<<<<<<<<<<<", string(r.GetStatus()))
+	if r.AgentVersion != "" {
+		logger.Infof("Agent version: %s", r.AgentVersion)
+	}
 	for _, result := range r.TestGroupResults {
 		result.Print()
 	}
-	log.Printf(">>>>>>>>>>>>>>><<<<<<<<<<<<<<<")
+	r.printTimings()
+	logger.Infof(">>>>>>>>>>>>>>><<<<<<<<<<<<<<<")
+}
+
+// printTimings lists the runners from the slowest, with the duration of their phases
+func (r TestSuiteResult) printTimings() {
+	groups := append([]TestGroupResult(nil), r.TestGroupResults...)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Duration > groups[j].Duration
+	})
+	logger.Infof("--------------Timings--------------")
+	w := tabwriter.NewWriter(log.Writer(), 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, "runner", "\t", "duration", "\t", "setup", "\t", "wait", "\t", "agent run", "\t", "validation", "\t")
+	for _, group := range groups {
+		t := group.Timings
+		fmt.Fprintln(w, group.Name, "\t", group.Duration.Round(time.Second), "\t", t.Setup.Round(time.Second), "\t",
+			t.Wait.Round(time.Second), "\t", t.AgentRun.Round(time.Second), "\t", t.Validation.Round(time.Second), "\t")
+	}
+	w.Flush()
+}
+
+// Summary converts the result for the notifiers
+func (r TestSuiteResult) Summary() notify.Summary {
+	s := notify.Summary{
+		Suite:        r.Name,
+		Status:       string(r.GetStatus()),
+		AgentVersion: r.AgentVersion,
+	}
+	for _, group := range r.TestGroupResults {
+		groupStatus := group.GetStatus()
+		if groupStatus == SUCCESSFUL || groupStatus == FLAKY {
+			s.Passed++
+			continue
+		}
+		failure := notify.Failure{
+			Runner:           group.Name,
+			Status:           string(groupStatus),
+			Category:         string(group.FailureCategory()),
+			ArtifactLocation: group.ArtifactLocation,
+		}
+		for _, result := range group.TestResults {
+			if result.Status == FAILED && result.Reason != "" {
+				failure.Tests = append(failure.Tests, fmt.Sprintf("%s (%s)", result.Name, result.Reason))
+			} else if result.Status == FAILED {
+				failure.Tests = append(failure.Tests, result.Name)
+			}
+		}
+		s.Failures = append(s.Failures, failure)
+	}
+	return s
+}
+
+var _ report.Result = TestSuiteResult{}
+
+// Document converts the result for the machine-readable report sinks
+func (r TestSuiteResult) Document() report.Document {
+	d := report.Document{
+		Suite:        r.Name,
+		Status:       string(r.GetStatus()),
+		AgentVersion: r.AgentVersion,
+	}
+	for _, group := range r.TestGroupResults {
+		runner := report.Runner{
+			Name:             group.Name,
+			Status:           string(group.GetStatus()),
+			Category:         string(group.FailureCategory()),
+			DurationSeconds:  group.Duration.Seconds(),
+			Attempts:         group.Attempts,
+			ArtifactLocation: group.ArtifactLocation,
+		}
+		for _, pipeline := range group.Startup {
+			if !pipeline.Visible {
+				continue
+			}
+			if runner.StartupSeconds == nil {
+				runner.StartupSeconds = map[string]float64{}
+			}
+			runner.StartupSeconds[pipeline.Name] = pipeline.Latency.Seconds()
+		}
+		for _, result := range group.TestResults {
+			runner.Tests = append(runner.Tests, report.Test{
+				Name:     result.Name,
+				Status:   string(result.Status),
+				Category: string(result.Category),
+				Reason:   result.Reason,
+			})
+		}
+		d.Runners = append(d.Runners, runner)
+	}
+	return d
 }
 
 type TestGroupResult struct {
 	Name        string
 	TestResults []TestResult
+	// Logs captured while the runner executed, printed only when the group fails
+	Logs []string
+	// ArtifactLocation is the S3 location of the failure artifacts, empty when nothing was uploaded
+	ArtifactLocation string
+	// Attempts is the number of times the runner ran, 0 when the runner does not support retries
+	Attempts    int
+	Quarantined bool
+	// ApiThrottles is the number of AWS API calls throttled while the runner executed
+	ApiThrottles int64
+	Duration     time.Duration
+	// Timings is the duration of each phase of the last attempt, zero for the phases the runner does not have
+	Timings Timings
+	// LogLatency is the log delivery latency of the runners that measure it, nil otherwise
+	LogLatency *latency.Stats
+	// Startup is the time from the agent start to the first visible datapoint or log event of each pipeline of the
+	// runner, nil when the runner does not start the agent
+	Startup latency.Startup
+	// Completeness reconciles the telemetry the runner generated with what was observed in CloudWatch
+	Completeness []completeness.Report
+	// ResourceUsage is the usage of the agent process sampled while it ran, nil when it was not monitored
+	ResourceUsage *leak.Series
 }
 
 func (r TestGroupResult) GetStatus() TestStatus {
 	for _, result := range r.TestResults {
 		if result.Status == FAILED {
+			if r.Quarantined {
+				return QUARANTINED
+			}
 			return FAILED
 		}
 	}
+	if r.Attempts > 1 {
+		return FLAKY
+	}
 	return SUCCESSFUL
 }
 
+// FailureCategory is the category of the failed results, Validation as soon as one of them is a validation failure so a
+// product regression is never hidden behind an infrastructure flake. TLS and permission failures, which are not
+// retryable either, come next. It is empty when nothing failed.
+func (r TestGroupResult) FailureCategory() errclass.Category {
+	var category errclass.Category
+	for _, result := range r.TestResults {
+		if result.Status != FAILED {
+			continue
+		}
+		resultCategory := result.Category
+		if resultCategory == "" {
+			resultCategory = errclass.Validation
+		}
+		if resultCategory == errclass.Validation {
+			return errclass.Validation
+		}
+		if category == "" || !resultCategory.IsRetryable() {
+			category = resultCategory
+		}
+	}
+	return category
+}
+
 func (r TestGroupResult) Print() {
-	log.Printf("==============%v==============", r.Name)
-	log.Printf("==============%v==============", string(r.GetStatus()))
+	logger.Infof("==============%v==============", r.Name)
+	logger.Infof("==============%v==============", string(r.GetStatus()))
+	if r.Duration > 0 {
+		logger.Infof("Duration: %s, attempts: %d", r.Duration.Round(time.Second), r.Attempts)
+	}
+	if !r.Timings.IsZero() {
+		logger.Infof("Phases: %s", r.Timings)
+	}
 	w := tabwriter.NewWriter(log.Writer(), 1, 1, 1, ' ', 0)
 	for _, result := range r.TestResults {
-		fmt.Fprintln(w, result.Name, "\t", result.Status, "\t")
+		fmt.Fprintln(w, result.Name, "\t", result.Status, "\t", result.Category, "\t", result.Reason, "\t")
 	}
 	w.Flush()
-	log.Printf("==============================")
+	for _, result := range r.TestResults {
+		if result.Status != FAILED {
+			continue
+		}
+		if result.Expected != "" || result.Actual != "" {
+			logger.Infof("%s: expected %s, actual %s", result.Name, result.Expected, result.Actual)
+		}
+		if len(result.Dimensions) > 0 {
+			logger.Infof("%s: dimensions %v", result.Name, result.Dimensions)
+		}
+	}
+	if category := r.FailureCategory(); category != "" {
+		logger.Infof("Failure category: %s, retryable: %t", category, category.IsRetryable())
+	}
+	if r.ApiThrottles > 0 {
+		logger.Infof("AWS API calls throttled: %d", r.ApiThrottles)
+	}
+	if r.LogLatency != nil {
+		logger.Infof("Log delivery latency: %s", r.LogLatency)
+	}
+	if len(r.Startup) > 0 {
+		logger.Infof("Startup latency: %s", r.Startup)
+	}
+	for _, report := range r.Completeness {
+		logger.Infof("Delivery of %s", report)
+	}
+	if r.ResourceUsage != nil {
+		logger.Infof("Agent resource usage: %s", r.ResourceUsage)
+	}
+	if r.GetStatus() == FAILED && len(r.Logs) > 0 {
+		logger.Infof("--------------Logs--------------")
+		for _, line := range r.Logs {
+			// the captured lines already carry their level and fields
+			log.Print(line)
+		}
+	}
+	logger.Infof("==============================")
 }
 
 type TestResult struct {
 	Name   string
 	Status TestStatus
+	// Reason explains a FAILED result, e.g. the fetch error, the unresolved dimensions or the bad values
+	Reason string
+	// Category classifies a FAILED result, see errclass.Classify
+	Category   errclass.Category
+	Expected   string
+	Actual     string
+	Dimensions []string
+	Duration   time.Duration
+}
+
+// SetDimensions records the dimensions the metric was queried with
+func (r *TestResult) SetDimensions(dims []types.Dimension) {
+	r.Dimensions = make([]string, 0, len(dims))
+	for _, d := range dims {
+		r.Dimensions = append(r.Dimensions, fmt.Sprintf("%s=%s", aws.ToString(d.Name), aws.ToString(d.Value)))
+	}
+}
+
+// SetError records the error as the reason of the result along with its category
+func (r *TestResult) SetError(err error) {
+	r.Reason = err.Error()
+	r.Category = errclass.Classify(err)
+}
+
+// SetValueMismatch records values that did not satisfy the expected bounds
+func (r *TestResult) SetValueMismatch(expected string, actual []float64) {
+	r.Reason = "values are not within the expected bounds"
+	r.Category = errclass.Validation
+	r.Expected = expected
+	r.Actual = fmt.Sprint(actual)
 }
@@ -10,4 +10,8 @@ type TestStatus string
 const (
 	SUCCESSFUL TestStatus = "Successful"
 	FAILED     TestStatus = "Failed"
+	// FLAKY is a group that failed at least once but passed on a retry
+	FLAKY TestStatus = "Flaky"
+	// QUARANTINED is a failed group of a known flaky runner, which does not fail the suite
+	QUARANTINED TestStatus = "Quarantined"
 )
@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package status
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timings breaks the duration of the last attempt of a runner down by phase, so the slowest suites can be optimized
+// where they spend their time
+type Timings struct {
+	// Setup is the setup before and after the agent started, including the translation and the start of the agent
+	Setup time.Duration
+	// Wait is how long the agent took to become ready
+	Wait time.Duration
+	// AgentRun is how long the agent ran for the runner to collect, until it was stopped
+	AgentRun time.Duration
+	// Validation is how long the validation of what the agent published took
+	Validation time.Duration
+}
+
+func (t Timings) IsZero() bool {
+	return t == Timings{}
+}
+
+func (t Timings) String() string {
+	return fmt.Sprintf("setup %s, wait %s, agent run %s, validation %s",
+		t.Setup.Round(time.Second), t.Wait.Round(time.Second), t.AgentRun.Round(time.Second), t.Validation.Round(time.Second))
+}
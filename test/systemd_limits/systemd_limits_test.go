@@ -0,0 +1,161 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package systemd_limits
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/scenario"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	namespace            = "SystemdLimitsTest"
+	agentRuntime         = 3 * time.Minute
+	agentConfigLocalPath = "agent_configs/config.json"
+	agentConfigPath      = "/opt/aws/amazon-cloudwatch-agent/bin/config.json"
+
+	cpuQuota = "CPUQuota=50%"
+	// cpuQuotaPerSec is how systemd reports the 50% quota
+	cpuQuotaPerSec = "500ms"
+	memoryMax      = 512 * 1024 * 1024
+	// oomMemoryMax is far below what the agent needs, it is OOM killed as soon as it allocates
+	oomMemoryMax = 16 * 1024 * 1024
+	oomTimeout   = 2 * time.Minute
+	// minValues is the number of 1 minute values expected over the runtime, the first and last minutes may be partial
+	minValues = int(agentRuntime/time.Minute) - 1
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestSystemdLimits runs the agent with a CPU quota and a memory limit on its systemd unit and validates it keeps
+// collecting within them, then lowers the limit under its usage and validates the OOM kill is detected
+func TestSystemdLimits(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	common.CopyFile(agentConfigLocalPath, agentConfigPath)
+
+	result := scenario.Scenario{
+		Name: "SystemdLimits",
+		Steps: []scenario.Step{
+			scenario.StartAgent(agentConfigPath),
+			{Name: "apply the cgroup limits", Run: func(scenario.State) error {
+				return common.SetAgentServiceProperties(cpuQuota, fmt.Sprintf("MemoryMax=%d", memoryMax))
+			}},
+			{Name: "check the cgroup limits are applied", Run: func(scenario.State) error { return checkLimits() }},
+			scenario.Mark("start"),
+			scenario.Wait(agentRuntime),
+			{Name: "check the agent ran within the limits", Run: func(state scenario.State) error {
+				return checkWithinLimits(state.Time("start"))
+			}},
+			scenario.Mark("end"),
+			{Name: "lower the memory limit below the agent usage", Run: func(scenario.State) error {
+				return common.SetAgentServiceProperties(fmt.Sprintf("MemoryMax=%d", oomMemoryMax), "MemorySwapMax=0")
+			}},
+			{Name: "check the OOM kill is detected", Run: func(state scenario.State) error {
+				return await.WaitUntil(context.Background(), 5*time.Second, oomTimeout, "the agent to be OOM killed", func() (bool, error) {
+					return common.AgentOomKilled(state.Time("end"))
+				})
+			}},
+			// lets CloudWatch aggregate the last minute collected within the limits
+			scenario.Wait(time.Minute),
+			{Name: "validate metrics", Run: func(state scenario.State) error {
+				return validateMetrics(env, state.Time("start"), state.Time("end"))
+			}},
+		},
+		Cleanup: []scenario.Step{
+			{Name: "reset the cgroup limits", Run: func(scenario.State) error { return common.ResetAgentServiceProperties() }},
+			scenario.StopAgent(),
+		},
+	}.Run()
+
+	for _, r := range result.TestResults {
+		if r.Status != status.SUCCESSFUL {
+			t.Errorf("step %s failed: %s", r.Name, r.Reason)
+		}
+	}
+}
+
+func checkLimits() error {
+	properties, err := common.GetAgentServiceProperties("CPUQuotaPerSecUSec", "MemoryMax")
+	if err != nil {
+		return err
+	}
+	if properties["CPUQuotaPerSecUSec"] != cpuQuotaPerSec {
+		return fmt.Errorf("CPUQuotaPerSecUSec is %q, expected %q", properties["CPUQuotaPerSecUSec"], cpuQuotaPerSec)
+	}
+	if properties["MemoryMax"] != strconv.Itoa(memoryMax) {
+		return fmt.Errorf("MemoryMax is %q, expected %d", properties["MemoryMax"], memoryMax)
+	}
+	return nil
+}
+
+// checkWithinLimits fails when systemd restarted the agent, its memory exceeds the limit or it was OOM killed
+func checkWithinLimits(start time.Time) error {
+	properties, err := common.GetAgentServiceProperties("NRestarts", "MemoryCurrent")
+	if err != nil {
+		return err
+	}
+	if properties["NRestarts"] != "0" {
+		return fmt.Errorf("the agent was restarted %s times under the limits", properties["NRestarts"])
+	}
+	memoryCurrent, err := strconv.Atoi(properties["MemoryCurrent"])
+	if err != nil {
+		return fmt.Errorf("unexpected MemoryCurrent %q: %w", properties["MemoryCurrent"], err)
+	}
+	if memoryCurrent > memoryMax {
+		return fmt.Errorf("the agent uses %d bytes, over the %d bytes limit", memoryCurrent, memoryMax)
+	}
+	killed, err := common.AgentOomKilled(start)
+	if err != nil {
+		return err
+	}
+	if killed {
+		return fmt.Errorf("the agent was OOM killed under the %d bytes limit", memoryMax)
+	}
+	return nil
+}
+
+func validateMetrics(env *environment.MetaData, start, end time.Time) error {
+	factory := dimension.GetDimensionFactory(*env)
+	fetcher := metric.MetricValueFetcher{}
+	for name, instructions := range map[string][]dimension.Instruction{
+		"cpu_usage_active": {
+			{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+			{Key: "cpu", Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")}},
+		},
+		"mem_used_percent": {
+			{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+		},
+	} {
+		dims, err := factory.GetDimensions(instructions)
+		if err != nil {
+			return err
+		}
+		values, err := fetcher.FetchWindow(namespace, name, dims, metric.AVERAGE, 60, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to fetch %s: %w", name, err)
+		}
+		if len(values) < minValues {
+			return fmt.Errorf("%s has %d values while the agent ran within the limits, expected at least %d", name, len(values), minValues)
+		}
+	}
+	return nil
+}
@@ -8,15 +8,27 @@ package test_runner
 import (
 	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentdebug"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentversion"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/artifact"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/flaky"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/leak"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/pprof"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/readiness"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/watchdog"
 )
 
 const (
@@ -25,6 +37,12 @@ const (
 	extraConfigDirectory = "extra_configs"
 )
 
+// runAgent and deleteAgentConfig are replaced by the tests of runOnce, which cannot start the agent
+var (
+	runAgent          = (*TestRunner).RunAgent
+	deleteAgentConfig = common.DeleteFile
+)
+
 type ITestRunner interface {
 	Validate() status.TestGroupResult
 	GetTestName() string
@@ -37,10 +55,31 @@ type ITestRunner interface {
 	SSMParameterName() string
 	SetUpConfig() error
 	SetAgentConfig(config AgentConfig)
+	GetRetries() int
+	Cleanup()
+}
+
+// Applicable is implemented by runners that only apply to some hosts, e.g. the ones with a JVM installed. Suites
+// skip the runners that are not applicable instead of failing them.
+type Applicable interface {
+	IsApplicable() bool
+}
+
+// Explicit is implemented by runners that only run when the -plugins of the suite names them, e.g. the smoke runner
+// which gates pull requests while the other runners already cover it
+type Explicit interface {
+	IsExplicit() bool
 }
 
 type TestRunner struct {
 	TestRunner ITestRunner
+	// NamespaceCheck fails the runner on metrics it and the runners before it did not declare, when set
+	NamespaceCheck *metric.NamespaceCheck
+	timings        status.Timings
+	// verbose is the agent log of the attempt, kept when the agent runs with debug logging
+	verbose *agentdebug.Capture
+	// startup watches the pipelines of the attempt for their first datapoint or log event
+	startup *latency.StartupWatch
 }
 
 type BaseTestRunner struct {
@@ -60,17 +99,23 @@ func (t *BaseTestRunner) SetupBeforeAgentRun() error {
 
 func (t *BaseTestRunner) SetUpConfig() error {
 	agentConfigPath := filepath.Join(agentConfigDirectory, t.AgentConfig.ConfigFileName)
-	log.Printf("Starting agent using agent config file %s", agentConfigPath)
+	logger.Infof("Starting agent using agent config file %s", agentConfigPath)
 	common.CopyFile(agentConfigPath, configOutputPath)
+	if err := common.ScopeConfigNamespace(configOutputPath); err != nil {
+		return fmt.Errorf("failed to scope the namespace of config %s: %w", agentConfigPath, err)
+	}
+	if err := common.ApplyAgentDebug(configOutputPath); err != nil {
+		return fmt.Errorf("failed to enable debug logging in config %s: %w", agentConfigPath, err)
+	}
 	if t.AgentConfig.UseSSM {
-		log.Printf("Starting agent from ssm parameter %s", agentConfigPath)
-		agentConfigByteArray, err := os.ReadFile(agentConfigPath)
+		logger.Infof("Starting agent from ssm parameter %s", agentConfigPath)
+		agentConfigByteArray, err := os.ReadFile(configOutputPath)
 		if err != nil {
 			return errors.New("failed while reading config file")
 		}
 		agentConfig := string(agentConfigByteArray)
 		if agentConfig != awsservice.GetStringParameter(t.AgentConfig.SSMParameterName) {
-			log.Printf("ssm agent config %s canged upload new config", t.AgentConfig.SSMParameterName)
+			logger.Infof("ssm agent config %s canged upload new config", t.AgentConfig.SSMParameterName)
 			err = awsservice.PutStringParameter(t.AgentConfig.SSMParameterName, agentConfig)
 			if err != nil {
 				return fmt.Errorf("failed to upload ssm parameter err %v", err)
@@ -100,21 +145,116 @@ func (t *BaseTestRunner) SetAgentConfig(agentConfig AgentConfig) {
 	t.AgentConfig = agentConfig
 }
 
+func (t *BaseTestRunner) GetRetries() int {
+	return flaky.DefaultRetries()
+}
+
+// Cleanup releases whatever the runner created during its setup. It runs after every failed attempt that is retried,
+// including the ones that failed before Validate was reached, and after the last attempt unless the cleanup policy of
+// the runner retains its resources
+func (t *BaseTestRunner) Cleanup() {
+}
+
+// MetricRunner is a runner which validates each of its measured metrics the same way, so its Validate only has to
+// call ValidateEachMetric
+type MetricRunner interface {
+	ITestRunner
+	ValidateMetric(metricName string) status.TestResult
+}
+
+// ValidateEachMetric validates every measured metric of the runner with its ValidateMetric
+func ValidateEachMetric(runner MetricRunner) status.TestGroupResult {
+	metricsToFetch := runner.GetMeasuredMetrics()
+	testResults := make([]status.TestResult, len(metricsToFetch))
+	for i, metricName := range metricsToFetch {
+		testResults[i] = runner.ValidateMetric(metricName)
+	}
+
+	return status.TestGroupResult{
+		Name:        runner.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
 func (t *TestRunner) Run() status.TestGroupResult {
+	return runWithRetries(t.TestRunner, t.runOnce)
+}
+
+func (t *TestRunner) runOnce() status.TestGroupResult {
 	testName := t.TestRunner.GetTestName()
-	log.Printf("Running %v", testName)
-	testGroupResult, err := t.RunAgent()
+	l := logger.With(logger.Fields{"runner": testName})
+	l.Infof("Running %v", testName)
+
+	logger.StartCapture()
+	start := time.Now()
+	testGroupResult, err := runAgent(t)
 	if err == nil {
-		testGroupResult = t.TestRunner.Validate()
+		agentRun := testGroupResult
+		validationStart := time.Now()
+		testGroupResult = mergeAgentRun(t.TestRunner.Validate(), agentRun)
+		if t.NamespaceCheck != nil {
+			testGroupResult.TestResults = append(testGroupResult.TestResults, t.checkNamespace())
+		}
+		t.timings.Validation = time.Since(validationStart)
+	}
+	// the pipelines are watched until the validation is done, a pipeline may only become visible while it runs
+	testGroupResult.Startup = t.startup.Stop()
+	t.startup = nil
+	if err == nil && len(testGroupResult.Startup) > 0 && latency.MaxStartup() > 0 {
+		testGroupResult.TestResults = append(testGroupResult.TestResults, checkStartup(testGroupResult.Startup))
 	}
+	testGroupResult.Timings = t.timings
 	if testGroupResult.GetStatus() != status.SUCCESSFUL {
-		log.Printf("%v test group failed due to %v", testName, err)
+		l.Errorf("%v test group failed due to %v", testName, err)
+	}
+	testGroupResult.Logs = logger.StopCapture()
+	health.RecordRunner(testName, time.Since(start), testGroupResult.GetStatus() == status.SUCCESSFUL)
+	if testGroupResult.GetStatus() != status.SUCCESSFUL {
+		extraFiles := []string{filepath.Join(agentConfigDirectory, t.TestRunner.GetAgentConfigFileName())}
+		if verboseLog, err := t.verbose.Save(testName); err != nil {
+			l.Errorf("Failed to keep the verbose agent log: %v", err)
+		} else if verboseLog != "" {
+			l.Infof("Verbose agent log of the failed attempt kept at %s", verboseLog)
+			extraFiles = append(extraFiles, verboseLog)
+		}
+		if testGroupResult.ArtifactLocation, err = artifact.UploadOnFailure(testName, extraFiles, testGroupResult.Logs); err != nil {
+			l.Errorf("Failed to upload failure artifacts: %v", err)
+		}
+	}
+
+	if !t.TestRunner.UseSSM() {
+		tagAgentLogGroups(configOutputPath)
+	}
+	// the config is deleted only after the failure artifacts are collected, since it is the most useful file of them
+	if err = deleteAgentConfig(configOutputPath); err != nil {
+		testGroupResult.TestResults = append(testGroupResult.TestResults, status.TestResult{
+			Name:   "Cleanup Agent Config",
+			Status: status.FAILED,
+			Reason: err.Error(),
+		})
 	}
 
 	return testGroupResult
 }
 
+// mergeAgentRun adds what was found while the agent ran, e.g. the growth of its resources, to the result of Validate.
+// The first result of the agent run, that the agent started, is left out.
+func mergeAgentRun(validated, agentRun status.TestGroupResult) status.TestGroupResult {
+	if len(agentRun.TestResults) > 1 {
+		validated.TestResults = append(validated.TestResults, agentRun.TestResults[1:]...)
+	}
+	if validated.ResourceUsage == nil {
+		validated.ResourceUsage = agentRun.ResourceUsage
+	}
+	return validated
+}
+
 func (t *TestRunner) RunAgent() (status.TestGroupResult, error) {
+	t.timings = status.Timings{}
+	t.verbose = nil
+	phases := startPhase(&t.timings.Setup)
+	defer phases.stop()
+
 	testGroupResult := status.TestGroupResult{
 		Name: t.TestRunner.GetTestName(),
 		TestResults: []status.TestResult{
@@ -137,6 +277,17 @@ func (t *TestRunner) RunAgent() (status.TestGroupResult, error) {
 		return testGroupResult, fmt.Errorf("Failed to complete setup before agent run due to: %w", err)
 	}
 
+	if !t.TestRunner.UseSSM() {
+		translation := validateTranslation(configOutputPath)
+		if translation.Status != status.SUCCESSFUL {
+			testGroupResult.TestResults = append(testGroupResult.TestResults, translation)
+			return testGroupResult, fmt.Errorf("Config translation failed: %s", translation.Reason)
+		}
+	}
+
+	probe := readiness.Begin()
+	t.verbose = agentdebug.Start(common.AgentLogFile)
+	startedAt := time.Now()
 	if t.TestRunner.UseSSM() {
 		err = common.StartAgent(t.TestRunner.SSMParameterName(), false, true)
 	} else {
@@ -147,22 +298,77 @@ func (t *TestRunner) RunAgent() (status.TestGroupResult, error) {
 		testGroupResult.TestResults[0].Status = status.FAILED
 		return testGroupResult, fmt.Errorf("Agent could not start due to: %w", err)
 	}
+	// the agent is stopped on every return from here on, so a failed attempt never leaves it running into the next
+	// runner with the ports of its listeners
+	stopped := false
+	stopAgent := func() {
+		if !stopped {
+			stopped = true
+			common.StopAgent()
+		}
+	}
+	defer stopAgent()
+	t.startup = latency.WatchStartup(startedAt, t.startupPipelines(), latency.DefaultPollInterval)
+
+	if err = agentversion.Check(); err != nil {
+		testGroupResult.TestResults[0].Status = status.FAILED
+		testGroupResult.TestResults[0].Reason = err.Error()
+		return testGroupResult, err
+	}
 
+	phases.enter(&t.timings.Wait)
+	if err = probe.Wait(); err != nil {
+		ready := status.TestResult{Name: "Agent Ready", Status: status.FAILED}
+		ready.SetError(err)
+		testGroupResult.TestResults = append(testGroupResult.TestResults, ready)
+		return testGroupResult, err
+	}
+
+	phases.enter(&t.timings.Setup)
 	err = t.TestRunner.SetupAfterAgentRun()
 	if err != nil {
 		testGroupResult.TestResults[0].Status = status.FAILED
 		return testGroupResult, fmt.Errorf("Failed to complete setup after agent run due to: %w", err)
 	}
 
+	phases.enter(&t.timings.AgentRun)
 	runningDuration := t.TestRunner.GetAgentRunDuration()
+	monitor := leak.StartAgentMonitor()
+	watch := watchdog.Start()
+	profiles := pprof.Start(t.TestRunner.GetTestName())
+	defer profiles.Cleanup()
 	time.Sleep(runningDuration)
-	log.Printf("Agent has been running for : %s", runningDuration.String())
-	common.StopAgent()
+	logger.Infof("Agent has been running for : %s", runningDuration.String())
+	usage := monitor.Stop()
+	restarts := watch.Stop()
+	profiles.Stop()
+	stopAgent()
 
-	err = common.DeleteFile(configOutputPath)
-	if err != nil {
-		testGroupResult.TestResults[0].Status = status.FAILED
-		return testGroupResult, fmt.Errorf("Failed to cleanup config file after agent run due to: %w", err)
+	// the values of a restarted agent may still validate, so the restart fails the runner by itself
+	if len(restarts) > 0 {
+		restarted := status.TestResult{Name: "Agent Restarts", Status: status.FAILED}
+		restarted.SetError(watchdog.Check(restarts, common.ReadAgentOutput(time.Since(startedAt))))
+		testGroupResult.TestResults = append(testGroupResult.TestResults, restarted)
+	}
+
+	if len(usage.Samples) > 0 {
+		testGroupResult.ResourceUsage = &usage
+		if err = usage.Check(leak.ConfiguredThresholds()); err != nil {
+			testGroupResult.TestResults = append(testGroupResult.TestResults, status.TestResult{
+				Name:   "Agent Resource Growth",
+				Status: status.FAILED,
+				Reason: err.Error(),
+			})
+		}
+	}
+
+	// an OOM killed agent is restarted by systemd, so only the kernel log shows the values have gaps
+	if killed, err := common.AgentOomKilled(startedAt); err != nil {
+		logger.Warnf("Could not check whether the agent was OOM killed: %v", err)
+	} else if killed {
+		oom := status.TestResult{Name: "Agent OOM Killed", Status: status.FAILED}
+		oom.SetError(errclass.Validationf("the agent was OOM killed while it ran, see the kernel log"))
+		testGroupResult.TestResults = append(testGroupResult.TestResults, oom)
 	}
 
 	return testGroupResult, nil
@@ -0,0 +1,117 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/leak"
+)
+
+// stubRunner validates successfully without querying CloudWatch
+type stubRunner struct {
+	BaseTestRunner
+}
+
+func (r *stubRunner) Validate() status.TestGroupResult {
+	return status.TestGroupResult{
+		Name:        r.GetTestName(),
+		TestResults: []status.TestResult{{Name: "cpu_usage_idle", Status: status.SUCCESSFUL}},
+	}
+}
+
+func (r *stubRunner) GetTestName() string {
+	return "Stub"
+}
+
+func (r *stubRunner) GetAgentConfigFileName() string {
+	return "stub_config.json"
+}
+
+func (r *stubRunner) GetMeasuredMetrics() []string {
+	return []string{"cpu_usage_idle"}
+}
+
+// stubAgentRun replaces the agent run of runOnce with the results, as if the agent started and ran
+func stubAgentRun(t *testing.T, results ...status.TestResult) *leak.Series {
+	usage := &leak.Series{Samples: []leak.Sample{{RSS: 1}}}
+	prevRunAgent, prevDeleteAgentConfig := runAgent, deleteAgentConfig
+	runAgent = func(*TestRunner) (status.TestGroupResult, error) {
+		return status.TestGroupResult{
+			Name:          "Stub",
+			TestResults:   append([]status.TestResult{{Name: "Starting Agent", Status: status.SUCCESSFUL}}, results...),
+			ResourceUsage: usage,
+		}, nil
+	}
+	deleteAgentConfig = func(string) error { return nil }
+	t.Cleanup(func() {
+		runAgent, deleteAgentConfig = prevRunAgent, prevDeleteAgentConfig
+	})
+	return usage
+}
+
+func TestRunOnceKeepsAgentRunResults(t *testing.T) {
+	usage := stubAgentRun(t)
+	runner := &TestRunner{TestRunner: &stubRunner{}}
+
+	result := runner.runOnce()
+	assert.Equal(t, status.SUCCESSFUL, result.GetStatus())
+	assert.Equal(t, []status.TestResult{{Name: "cpu_usage_idle", Status: status.SUCCESSFUL}}, result.TestResults)
+	assert.Same(t, usage, result.ResourceUsage)
+}
+
+func TestRunOnceFailsOnResourceGrowth(t *testing.T) {
+	usage := stubAgentRun(t, status.TestResult{
+		Name:   "Agent Resource Growth",
+		Status: status.FAILED,
+		Reason: "RSS grew 80 MB per hour, more than the 50 MB per hour allowed",
+	})
+	runner := &TestRunner{TestRunner: &stubRunner{}}
+
+	result := runner.runOnce()
+	assert.Equal(t, status.FAILED, result.GetStatus())
+	require.Len(t, result.TestResults, 2)
+	assert.Equal(t, "cpu_usage_idle", result.TestResults[0].Name)
+	assert.Equal(t, "Agent Resource Growth", result.TestResults[1].Name)
+	assert.Same(t, usage, result.ResourceUsage)
+}
+
+func TestRunOnceFailsOnAgentRestart(t *testing.T) {
+	stubAgentRun(t, status.TestResult{
+		Name:   "Agent Restarts",
+		Status: status.FAILED,
+		Reason: "the agent restarted 1 time while it ran",
+	})
+	runner := &TestRunner{TestRunner: &stubRunner{}}
+
+	// the metric of the restarted agent validated, the restart fails the runner by itself
+	result := runner.runOnce()
+	assert.Equal(t, status.FAILED, result.GetStatus())
+	require.Len(t, result.TestResults, 2)
+	assert.Equal(t, status.SUCCESSFUL, result.TestResults[0].Status)
+	assert.Equal(t, "Agent Restarts", result.TestResults[1].Name)
+}
+
+func TestRunOnceFailsOnOomKill(t *testing.T) {
+	stubAgentRun(t, status.TestResult{
+		Name:     "Agent OOM Killed",
+		Status:   status.FAILED,
+		Category: errclass.Validation,
+		Reason:   "the agent was OOM killed while it ran, see the kernel log",
+	})
+	runner := &TestRunner{TestRunner: &stubRunner{}}
+
+	result := runner.runOnce()
+	assert.Equal(t, status.FAILED, result.GetStatus())
+	assert.Equal(t, errclass.Validation, result.FailureCategory())
+	require.Len(t, result.TestResults, 2)
+	assert.Equal(t, "Agent OOM Killed", result.TestResults[1].Name)
+}
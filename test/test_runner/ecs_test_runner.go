@@ -8,7 +8,8 @@ package test_runner
 import (
 	"fmt"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
-	"log"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"os"
 	"time"
 
@@ -35,13 +36,13 @@ func (r *ECSAgentRunStrategy) RunAgentStrategy(e *environment.MetaData, configFi
 	if err != nil {
 		return fmt.Errorf("Failed while reading config file : %s", err.Error())
 	}
-	fmt.Print("Put parameter successful")
+	logger.Infof("Put parameter successful")
 
 	err = awsservice.RestartDaemonService(e.EcsClusterArn, e.EcsServiceName)
 	if err != nil {
-		fmt.Print(err)
+		logger.Errorf("Failed to restart CWAgent service: %v", err)
 	}
-	fmt.Print("CWAgent service is restarted")
+	logger.Infof("CWAgent service is restarted")
 
 	time.Sleep(5 * time.Minute)
 
@@ -55,16 +56,26 @@ type ECSTestRunner struct {
 }
 
 func (t *ECSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
+	s.AddToSuiteResult(runWithRetries(t.Runner, func() status.TestGroupResult {
+		return t.runOnce(e)
+	}))
+}
+
+func (t *ECSTestRunner) runOnce(e *environment.MetaData) status.TestGroupResult {
 	name := t.Runner.GetTestName()
-	log.Printf("Running %s", name)
+	l := logger.With(logger.Fields{"runner": name})
+	l.Infof("Running %s", name)
+	logger.StartCapture()
+	start := time.Now()
 
 	//runs agent restart with given config only when it's available
 	agentConfigFileName := t.Runner.GetAgentConfigFileName()
 	if len(agentConfigFileName) != 0 {
 		err := t.RunStrategy.RunAgentStrategy(e, t.Runner.GetAgentConfigFileName())
 		if err != nil {
-			log.Printf("Failed to run agent with config for the given testm err:%v", err)
-			s.AddToSuiteResult(status.TestGroupResult{
+			l.Errorf("Failed to run agent with config for the given testm err:%v", err)
+			health.RecordRunner(name, time.Since(start), false)
+			return status.TestGroupResult{
 				Name: t.Runner.GetTestName(),
 				TestResults: []status.TestResult{
 					{
@@ -72,15 +83,19 @@ func (t *ECSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
 						Status: status.FAILED,
 					},
 				},
-			})
-			return
+				Logs: logger.StopCapture(),
+			}
 		}
 	}
 
+	setupDone := time.Now()
 	testGroupResult := t.Runner.Validate()
-
-	s.AddToSuiteResult(testGroupResult)
+	testGroupResult.Timings = status.Timings{Setup: setupDone.Sub(start), Validation: time.Since(setupDone)}
 	if testGroupResult.GetStatus() != status.SUCCESSFUL {
-		log.Printf("%s test group failed", name)
+		l.Errorf("%s test group failed", name)
 	}
+	testGroupResult.Logs = logger.StopCapture()
+	health.RecordRunner(name, time.Since(start), testGroupResult.GetStatus() == status.SUCCESSFUL)
+
+	return testGroupResult
 }
@@ -8,7 +8,8 @@ package test_runner
 import (
 	"github.com/aws/amazon-cloudwatch-agent-test/environment"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
-	"log"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"time"
 )
 
@@ -18,14 +19,29 @@ type EKSTestRunner struct {
 }
 
 func (t *EKSTestRunner) Run(s ITestSuite, e *environment.MetaData) {
+	if a, ok := t.Runner.(Applicable); ok && !a.IsApplicable() {
+		logger.Infof("Skipping %s since it does not apply to this cluster", t.Runner.GetTestName())
+		return
+	}
+	s.AddToSuiteResult(runWithRetries(t.Runner, t.runOnce))
+}
+
+func (t *EKSTestRunner) runOnce() status.TestGroupResult {
 	name := t.Runner.GetTestName()
-	log.Printf("Running %s", name)
+	l := logger.With(logger.Fields{"runner": name})
+	l.Infof("Running %s", name)
+	start := time.Now()
 	dur := t.Runner.GetAgentRunDuration()
 	time.Sleep(dur)
 
+	logger.StartCapture()
+	validationStart := time.Now()
 	res := t.Runner.Validate()
-	s.AddToSuiteResult(res)
+	res.Timings = status.Timings{AgentRun: dur, Validation: time.Since(validationStart)}
 	if res.GetStatus() != status.SUCCESSFUL {
-		log.Printf("%s test group failed", name)
+		l.Errorf("%s test group failed", name)
 	}
+	res.Logs = logger.StopCapture()
+	health.RecordRunner(name, time.Since(start), res.GetStatus() == status.SUCCESSFUL)
+	return res
 }
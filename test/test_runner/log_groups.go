@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const instanceIdPlaceholder = "{instance_id}"
+
+type collectList struct {
+	CollectList []struct {
+		LogGroupName string `json:"log_group_name"`
+	} `json:"collect_list"`
+}
+
+// agentLogsConfig is the part of an agent config naming the log groups the agent creates
+type agentLogsConfig struct {
+	Logs struct {
+		LogsCollected struct {
+			Files         collectList `json:"files"`
+			WindowsEvents collectList `json:"windows_events"`
+		} `json:"logs_collected"`
+	} `json:"logs"`
+}
+
+// agentLogGroups returns the log groups the agent config at the path collects logs to which are unique to the
+// instance or the run. The groups named after the test only are shared with the runs on other instances, so they are
+// left alone. A missing config, e.g. of a runner using SSM, has none.
+func agentLogGroups(configPath string, instanceId func() string) ([]string, error) {
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var config agentLogsConfig
+	if err = json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	var logGroups []string
+	seen := map[string]struct{}{}
+	collected := config.Logs.LogsCollected
+	for _, entry := range append(collected.Files.CollectList, collected.WindowsEvents.CollectList...) {
+		name := entry.LogGroupName
+		unique := strings.Contains(name, runid.Get())
+		if strings.Contains(name, instanceIdPlaceholder) {
+			name = strings.ReplaceAll(name, instanceIdPlaceholder, instanceId())
+			unique = true
+		}
+		// the other placeholders are resolved by the agent only
+		if !unique || strings.Contains(name, "{") {
+			continue
+		}
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			logGroups = append(logGroups, name)
+		}
+	}
+	return logGroups, nil
+}
+
+// tagAgentLogGroups tags the log groups the agent created from the config at the path for the run, so the retry of
+// the runner deletes them and the next attempt starts with fresh log groups
+func tagAgentLogGroups(configPath string) {
+	logGroups, err := agentLogGroups(configPath, awsservice.GetInstanceId)
+	if err != nil {
+		logger.Errorf("Failed to read the log groups of the agent config %s: %v", configPath, err)
+		return
+	}
+	for _, logGroup := range logGroups {
+		awsservice.TagLogGroupForRun(logGroup)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+func TestAgentLogGroups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+  "logs": {
+    "logs_collected": {
+      "files": {
+        "collect_list": [
+          {"file_path": "/tmp/a.log", "log_group_name": "{instance_id}Glob"},
+          {"file_path": "/tmp/b.log", "log_group_name": "{instance_id}Glob"},
+          {"file_path": "/tmp/c.log", "log_group_name": "prometheus_test"},
+          {"file_path": "/tmp/d.log", "log_group_name": "{hostname}"},
+          {"file_path": "/tmp/e.log", "log_group_name": "` + runid.Name("logs") + `"}
+        ]
+      },
+      "windows_events": {
+        "collect_list": [{"event_name": "System", "log_group_name": "{instance_id}Events"}]
+      }
+    }
+  }
+}`
+	require.NoError(t, os.WriteFile(path, []byte(config), 0644))
+
+	logGroups, err := agentLogGroups(path, func() string { return "i-0123" })
+	require.NoError(t, err)
+	// the group shared with the runs on other instances and the ones the agent names are left alone
+	assert.Equal(t, []string{"i-0123Glob", runid.Name("logs"), "i-0123Events"}, logGroups)
+}
+
+func TestAgentLogGroupsWithoutConfig(t *testing.T) {
+	logGroups, err := agentLogGroups(filepath.Join(t.TempDir(), "missing.json"), func() string {
+		t.Fatal("the instance id is only looked up for a config naming a group after it")
+		return ""
+	})
+	require.NoError(t, err)
+	assert.Empty(t, logGroups)
+}
@@ -0,0 +1,31 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+)
+
+// ValidateMetricValues records the dimensions on the test result, fetches the average of the metric at the high
+// resolution period and checks every value is >= expectedValue. It returns false once the failure is recorded on the
+// result, so runners only add their own checks before marking the result SUCCESSFUL.
+func ValidateMetricValues(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension, expectedValue float64) bool {
+	testResult.SetDimensions(dims)
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	if !metric.IsAllValuesGreaterThanOrEqualToExpectedValue(metricName, values, expectedValue) {
+		testResult.SetValueMismatch(metric.DescribeExpectedValue(expectedValue), values)
+		return false
+	}
+	return true
+}
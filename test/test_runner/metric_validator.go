@@ -0,0 +1,239 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
+)
+
+// MetricValidator checks one expectation of a metric, e.g. its values are within bounds or it has the expected unit.
+// It returns false once the failure is recorded on the result, like ValidateMetricValues.
+type MetricValidator interface {
+	Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool
+}
+
+// MetricSpec declares a measured metric, the dimensions it is published with and the validators it must pass, so a
+// runner declares its expectations instead of implementing the validation
+type MetricSpec struct {
+	Name       string
+	Dimensions []dimension.Instruction
+	Validators []MetricValidator
+}
+
+type MetricSpecs []MetricSpec
+
+// NewMetricSpecs declares the metrics with the same dimensions and validators, e.g. every metric of a plugin
+func NewMetricSpecs(dims []dimension.Instruction, validators []MetricValidator, names ...string) MetricSpecs {
+	specs := make(MetricSpecs, len(names))
+	for i, name := range names {
+		specs[i] = MetricSpec{Name: name, Dimensions: dims, Validators: validators}
+	}
+	return specs
+}
+
+// Names returns the names of the metrics, for GetMeasuredMetrics
+func (s MetricSpecs) Names() []string {
+	names := make([]string, len(s))
+	for i, spec := range s {
+		names[i] = spec.Name
+	}
+	return names
+}
+
+// ValidateMetric resolves the dimensions of the spec and runs its validators in order, the first failure is the result
+func ValidateMetric(factory dimension.Factory, namespace string, spec MetricSpec) status.TestResult {
+	testResult := status.TestResult{
+		Name:   spec.Name,
+		Status: status.FAILED,
+	}
+	dims, err := factory.GetDimensions(spec.Dimensions)
+	if err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.SetDimensions(dims)
+	for _, v := range spec.Validators {
+		if !v.Validate(&testResult, namespace, spec.Name, dims) {
+			return testResult
+		}
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
+
+// ValidateMetrics validates every spec in order
+func ValidateMetrics(factory dimension.Factory, namespace string, specs MetricSpecs) []status.TestResult {
+	results := make([]status.TestResult, len(specs))
+	for i, spec := range specs {
+		results[i] = ValidateMetric(factory, namespace, spec)
+	}
+	return results
+}
+
+// NonNegative expects the metric has values and none of them is negative, the check most runners need
+type NonNegative struct{}
+
+func (NonNegative) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	return ValidateMetricValues(testResult, namespace, metricName, dims, 0)
+}
+
+// AverageNear expects the metric has non-negative values with an average within 10% of Value, e.g. a metric the
+// runner sends with a known value
+type AverageNear struct {
+	Value float64
+}
+
+func (a AverageNear) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	return ValidateMetricValues(testResult, namespace, metricName, dims, a.Value)
+}
+
+// Bounds expects the metric has values and every one of them is within the bounds, e.g. between 0 and 100 for a
+// percent or within 1% of the memory of the host for mem_total
+type Bounds struct {
+	metric.Bounds
+}
+
+// AtLeast is Bounds without an upper bound
+func AtLeast(min float64) Bounds {
+	return Bounds{metric.Bounds{Min: min, Max: math.Inf(1)}}
+}
+
+// Near is Bounds of the values within tolerancePercent of expected
+func Near(expected, tolerancePercent float64) Bounds {
+	return Bounds{metric.Within(expected, tolerancePercent)}
+}
+
+func (b Bounds) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.Fetch(namespace, metricName, dims, metric.AVERAGE, metric.HighResolutionStatPeriod)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	if len(values) == 0 {
+		testResult.SetError(errclass.NotFoundYetf("no values found"))
+		return false
+	}
+	for _, v := range values {
+		if !b.Contains(v) {
+			testResult.SetValueMismatch(b.String(), values)
+			return false
+		}
+	}
+	return true
+}
+
+// SampleCount expects every high resolution period of the metric has at least Min samples, e.g. the number of
+// collections per period
+type SampleCount struct {
+	Min float64
+}
+
+func (s SampleCount) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.Fetch(namespace, metricName, dims, metric.SAMPLE_COUNT, metric.HighResolutionStatPeriod)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	if len(values) == 0 {
+		testResult.SetError(errclass.NotFoundYetf("no values found"))
+		return false
+	}
+	for _, v := range values {
+		if v < s.Min {
+			testResult.SetValueMismatch(fmt.Sprintf("at least %v samples per period", s.Min), values)
+			return false
+		}
+	}
+	return true
+}
+
+// Unit expects the metric is published with the unit, the unit of metric.ExpectedUnits when Expected is empty
+type Unit struct {
+	Expected types.StandardUnit
+}
+
+func (u Unit) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	fetcher := metric.MetricValueFetcher{}
+	if err := fetcher.ValidateUnit(namespace, metricName, dims, u.Expected); err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	return true
+}
+
+// Counter expects the metric is a counter that never goes backwards, and that it increased by at least MinDelta over
+// the last 10 minutes, e.g. the load the runner generated, and by at most MaxDelta when it is set. The agent publishes
+// the increase since the previous collection unless Cumulative is set, which validates the running total instead.
+type Counter struct {
+	Cumulative bool
+	MinDelta   float64
+	MaxDelta   float64
+}
+
+func (c Counter) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	// the sum of the increases of a period is its increase, the maximum of the running totals of a period is its last
+	stat := metric.SUM
+	if c.Cumulative {
+		stat = metric.MAXIMUM
+	}
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.Fetch(namespace, metricName, dims, stat, metric.HighResolutionStatPeriod)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	// Fetch returns the newest datapoint first
+	oldestFirst := make([]float64, len(values))
+	for i, v := range values {
+		oldestFirst[len(values)-1-i] = v
+	}
+	increase, err := metric.CounterIncrease(oldestFirst, c.Cumulative)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	if increase < c.MinDelta || (c.MaxDelta > 0 && increase > c.MaxDelta) {
+		expected := fmt.Sprintf("an increase of at least %v", c.MinDelta)
+		if c.MaxDelta > 0 {
+			expected = fmt.Sprintf("an increase within [%v, %v]", c.MinDelta, c.MaxDelta)
+		}
+		testResult.SetValueMismatch(expected, []float64{increase})
+		return false
+	}
+	return true
+}
+
+// GroundTruth expects the metric matches what the host read itself over the same window, e.g. from /proc, which
+// catches calculation bugs a range check lets through
+type GroundTruth struct {
+	Series groundtruth.Series
+	metric.HostReading
+}
+
+func (g GroundTruth) Validate(testResult *status.TestResult, namespace, metricName string, dims []types.Dimension) bool {
+	fetcher := metric.MetricValueFetcher{}
+	comparison, err := fetcher.CompareWithHost(namespace, metricName, dims, g.Series, g.HostReading)
+	if err != nil {
+		testResult.SetError(err)
+		return false
+	}
+	if !comparison.Agrees() {
+		testResult.SetValueMismatch(comparison.String(), []float64{comparison.Published})
+		return false
+	}
+	return true
+}
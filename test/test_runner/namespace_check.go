@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+)
+
+// ExpectedMetrics is implemented by runners whose config publishes metrics they don't measure, the namespace check
+// expects them along with the measured ones
+type ExpectedMetrics interface {
+	GetExpectedMetrics() []string
+}
+
+// checkNamespace declares the metrics of the runner and fails when the namespace has metrics no runner so far declared
+func (t *TestRunner) checkNamespace() status.TestResult {
+	t.NamespaceCheck.Expect(t.TestRunner.GetMeasuredMetrics()...)
+	if e, ok := t.TestRunner.(ExpectedMetrics); ok {
+		t.NamespaceCheck.Expect(e.GetExpectedMetrics()...)
+	}
+	testResult := status.TestResult{
+		Name:   "Namespace Sanity",
+		Status: status.FAILED,
+	}
+	if err := t.NamespaceCheck.Validate(); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
@@ -0,0 +1,33 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"time"
+)
+
+// phaseTimer attributes the time that passes to the phase the runner is in, so a runner that returns early still
+// accounts for the time of the phase it returned from
+type phaseTimer struct {
+	current *time.Duration
+	since   time.Time
+}
+
+func startPhase(phase *time.Duration) *phaseTimer {
+	return &phaseTimer{current: phase, since: time.Now()}
+}
+
+// enter ends the current phase and starts the given one, a phase entered again adds to its previous time
+func (p *phaseTimer) enter(phase *time.Duration) {
+	p.stop()
+	p.current = phase
+}
+
+func (p *phaseTimer) stop() {
+	now := time.Now()
+	*p.current += now.Sub(p.since)
+	p.since = now
+}
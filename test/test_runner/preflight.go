@@ -0,0 +1,110 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentconfig"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/ports"
+)
+
+const configTranslatorPath = "/opt/aws/amazon-cloudwatch-agent/bin/config-translator"
+
+// portReleaseChecks is how many times the ports are checked, a second apart, once their listeners are killed
+const portReleaseChecks = 5
+
+// agentCommand is the command of the agent process as /proc reports it, truncated to 15 characters
+const agentCommand = "amazon-cloudwat"
+
+// ValidateConfigs checks the agent config of every runner before any of them runs, so a malformed config fails the
+// suite at once instead of after the runners before it. The configs are checked against the agent schema and, when
+// the agent is installed, by its config translator, which rejects what the schema does.
+func ValidateConfigs(runners []*TestRunner) error {
+	_, err := os.Stat(configTranslatorPath)
+	translate := err == nil
+	if !translate {
+		logger.Infof("%s is not installed, the configs are only checked against the schema", configTranslatorPath)
+	}
+
+	var failures []string
+	checked := map[string]bool{}
+	for _, runner := range runners {
+		name := runner.TestRunner.GetAgentConfigFileName()
+		if name == "" || checked[name] {
+			continue
+		}
+		checked[name] = true
+		path := filepath.Join(agentConfigDirectory, name)
+		if err = agentconfig.ValidateFile(path); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", runner.TestRunner.GetTestName(), err))
+			continue
+		}
+		if !translate {
+			continue
+		}
+		if _, err = common.TranslateConfig(path); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s: %v", runner.TestRunner.GetTestName(), path, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errclass.Validationf("invalid agent configs:\n%s", strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// CheckPorts checks the ports the agent listens on are free before any runner starts it. An agent a previous run left
+// running is stopped, the runners start it again with their own configs. The other listeners are killed when
+// killStale is set, otherwise they fail the suite with an Infra error, which the agent is not to blame for.
+func CheckPorts(killStale bool) error {
+	conflicts := ports.Check(ports.AgentPorts)
+	if hasAgentListener(conflicts) {
+		logger.Infof("Stopping the agent a previous run left running")
+		common.StopAgent()
+		conflicts = ports.Check(ports.AgentPorts)
+	}
+	if len(conflicts) > 0 && killStale {
+		for _, c := range conflicts {
+			logger.Warnf("Killing the listeners of port %s", c)
+			if err := ports.Kill(c); err != nil {
+				return errclass.Infraf("%w", err)
+			}
+		}
+		// the sockets are closed once the killed processes exit
+		for i := 0; i < portReleaseChecks; i++ {
+			time.Sleep(time.Second)
+			if conflicts = ports.Check(ports.AgentPorts); len(conflicts) == 0 {
+				break
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	failures := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		failures[i] = c.String()
+	}
+	return errclass.Infraf("ports the agent listens on are in use, -killStaleListeners kills their listeners:\n%s", strings.Join(failures, "\n"))
+}
+
+func hasAgentListener(conflicts []ports.Conflict) bool {
+	for _, c := range conflicts {
+		for _, l := range c.Listeners {
+			if l.Command == agentCommand {
+				return true
+			}
+		}
+	}
+	return false
+}
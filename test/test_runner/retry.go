@@ -0,0 +1,76 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/cleanup"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/flaky"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// runWithRetries runs the attempt until it succeeds or the retries of the runner are exhausted. The resources and
+// log groups used by a failed attempt are deleted so the next attempt does not validate stale logs, the ones of the
+// last attempt are deleted as the cleanup policy of the runner says. A group that only passes on a
+// retry is reported as FLAKY, and a failed group of a quarantined runner as QUARANTINED.
+func runWithRetries(runner ITestRunner, attempt func() status.TestGroupResult) status.TestGroupResult {
+	name := runner.GetTestName()
+	l := logger.With(logger.Fields{"runner": name})
+	maxAttempts := runner.GetRetries() + 1
+
+	start := time.Now()
+	throttles := awsservice.GetApiThrottleCount()
+	var result status.TestGroupResult
+	var logGroupCount int
+	for i := 1; i <= maxAttempts; i++ {
+		logGroupCount = awsservice.TaggedLogGroupCount()
+		result = attempt()
+		result.Attempts = i
+		if result.GetStatus() != status.FAILED || i == maxAttempts {
+			break
+		}
+		l.Warnf("Attempt %d of %d failed, retrying", i, maxAttempts)
+		health.RecordRetry()
+		runner.Cleanup()
+		awsservice.DeleteLogGroupsTaggedAfter(logGroupCount)
+	}
+	tearDown(runner, result, logGroupCount)
+
+	result.ApiThrottles = awsservice.GetApiThrottleCount() - throttles
+	result.Duration = time.Since(start)
+
+	if flaky.IsQuarantined(name) {
+		result.Quarantined = true
+		if result.GetStatus() == status.QUARANTINED {
+			l.Warnf("%s failed but is quarantined", name)
+		}
+	}
+	return result
+}
+
+// tearDown cleans up after the last attempt as the cleanup policy of the runner says. A retained runner skips its
+// Cleanup and its log groups are tagged, so the sweeper keeps them for the retention.
+func tearDown(runner ITestRunner, result status.TestGroupResult, logGroupCount int) {
+	name := runner.GetTestName()
+	if cleanup.ShouldClean(name, result.GetStatus() != status.FAILED) {
+		runner.Cleanup()
+		return
+	}
+	l := logger.With(logger.Fields{"runner": name})
+	until := cleanup.RetainUntil()
+	logGroups := awsservice.LogGroupsTaggedAfter(logGroupCount)
+	for _, logGroup := range logGroups {
+		if err := awsservice.RetainLogGroup(logGroup, until); err != nil {
+			l.Errorf("Failed to retain log group %s: %v", logGroup, err)
+		}
+	}
+	l.Infof("Retaining the resources of %s for investigation until %s, cleanup policy %s, log groups %v",
+		name, until.Format(time.RFC3339), cleanup.PolicyOf(name), logGroups)
+}
@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/latency"
+)
+
+// StartupPipelines is implemented by runners whose config has pipelines besides their measured metrics, e.g. a log
+// stream, so the startup latency of those is measured as well
+type StartupPipelines interface {
+	GetStartupPipelines() []latency.Pipeline
+}
+
+// LogPipeline is visible once the log stream has an event since the agent start
+func LogPipeline(name, logGroup, logStream string) latency.Pipeline {
+	return latency.Pipeline{
+		Name: name,
+		Visible: func(since time.Time) (bool, error) {
+			events, err := awsservice.GetLogEvents(logGroup, logStream, &since, nil)
+			return len(events) > 0, err
+		},
+	}
+}
+
+// startupPipelines are the measured metrics of the runner, when the namespace check tells where they are published,
+// and the pipelines the runner adds
+func (t *TestRunner) startupPipelines() []latency.Pipeline {
+	var pipelines []latency.Pipeline
+	if measured := t.TestRunner.GetMeasuredMetrics(); t.NamespaceCheck != nil && len(measured) > 0 {
+		check := t.NamespaceCheck
+		pipelines = append(pipelines, latency.Pipeline{
+			Name: "metrics",
+			Visible: func(since time.Time) (bool, error) {
+				fetcher := metric.MetricValueFetcher{}
+				return fetcher.AnyVisible(check.Namespace, measured, check.Dimensions, since)
+			},
+		})
+	}
+	if s, ok := t.TestRunner.(StartupPipelines); ok {
+		pipelines = append(pipelines, s.GetStartupPipelines()...)
+	}
+	return pipelines
+}
+
+// checkStartup fails when a pipeline was not visible within the configured bound of the startup latency
+func checkStartup(startup latency.Startup) status.TestResult {
+	testResult := status.TestResult{
+		Name:   "Startup Latency",
+		Status: status.FAILED,
+	}
+	if err := startup.Check(latency.MaxStartup()); err != nil {
+		testResult.SetError(err)
+		return testResult
+	}
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
@@ -0,0 +1,254 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const suiteDefinitionDirectory = "suite_definitions"
+
+// SuiteDefinition describes a set of runners that only differ by agent config, duration and expected values.
+// Since JSON is a subset of YAML, definitions can be written in either format.
+type SuiteDefinition struct {
+	Runners []RunnerDefinition `yaml:"runners"`
+}
+
+type RunnerDefinition struct {
+	Name        string        `yaml:"name"`
+	AgentConfig string        `yaml:"agent_config"`
+	Duration    time.Duration `yaml:"duration"`
+	// Retries overrides the -retries flag for this runner when set
+	Retries     *int                  `yaml:"retries"`
+	Namespace   string                `yaml:"namespace"`
+	Metrics     []MetricDefinition    `yaml:"metrics"`
+	Environment EnvironmentConstraint `yaml:"environment"`
+}
+
+type MetricDefinition struct {
+	Name       string                `yaml:"name"`
+	Dimensions []DimensionDefinition `yaml:"dimensions"`
+	// Min and Max are inclusive, a nil bound is not checked
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// Expected and TolerancePercent replace Min and Max with the values within TolerancePercent of Expected, they are
+	// set together
+	Expected         *float64 `yaml:"expected"`
+	TolerancePercent float64  `yaml:"tolerance_percent"`
+	// Unit overrides the unit in metric.ExpectedUnits, e.g. for configs that set a unit on the measurement
+	Unit string `yaml:"unit"`
+}
+
+// DimensionDefinition leaves Value empty when the value should be resolved by the dimension providers
+type DimensionDefinition struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// EnvironmentConstraint limits a runner to the listed compute types. Empty means no constraint.
+type EnvironmentConstraint struct {
+	ComputeTypes []string `yaml:"compute_types"`
+}
+
+// LoadSuiteDefinition parses a single YAML or JSON suite definition file
+func LoadSuiteDefinition(path string) (*SuiteDefinition, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite definition %s: %w", path, err)
+	}
+
+	var def SuiteDefinition
+	if err = yaml.Unmarshal(b, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse suite definition %s: %w", path, err)
+	}
+
+	for _, r := range def.Runners {
+		if r.Name == "" || r.AgentConfig == "" {
+			return nil, fmt.Errorf("runner in %s is missing a name or agent_config", path)
+		}
+		for _, m := range r.Metrics {
+			if err = m.validate(); err != nil {
+				return nil, fmt.Errorf("runner %s in %s: %w", r.Name, path, err)
+			}
+		}
+	}
+	return &def, nil
+}
+
+// LoadSuiteDefinitions parses every .yaml, .yml and .json file in the suite_definitions directory.
+// A missing directory is not an error since most suites do not use definitions.
+func LoadSuiteDefinitions() ([]SuiteDefinition, error) {
+	entries, err := os.ReadDir(suiteDefinitionDirectory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var defs []SuiteDefinition
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		def, err := LoadSuiteDefinition(filepath.Join(suiteDefinitionDirectory, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, *def)
+	}
+	return defs, nil
+}
+
+// GetTestRunners builds a TestRunner for every runner definition applicable to the environment
+func (d SuiteDefinition) GetTestRunners(env *environment.MetaData, factory dimension.Factory) []*TestRunner {
+	var runners []*TestRunner
+	for _, r := range d.Runners {
+		if !r.Environment.isSatisfiedBy(env) {
+			logger.Infof("Skipping defined runner %s since it does not apply to compute type %s", r.Name, env.ComputeType)
+			continue
+		}
+		runners = append(runners, &TestRunner{TestRunner: &DefinedTestRunner{
+			BaseTestRunner: BaseTestRunner{DimensionFactory: factory},
+			Definition:     r,
+		}})
+	}
+	return runners
+}
+
+func (c EnvironmentConstraint) isSatisfiedBy(env *environment.MetaData) bool {
+	if len(c.ComputeTypes) == 0 {
+		return true
+	}
+	for _, computeType := range c.ComputeTypes {
+		if strings.EqualFold(computeType, string(env.ComputeType)) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefinedTestRunner is a generic runner driven entirely by a RunnerDefinition
+type DefinedTestRunner struct {
+	BaseTestRunner
+	Definition RunnerDefinition
+}
+
+var _ ITestRunner = (*DefinedTestRunner)(nil)
+
+func (t *DefinedTestRunner) Validate() status.TestGroupResult {
+	testResults := make([]status.TestResult, len(t.Definition.Metrics))
+	for i, m := range t.Definition.Metrics {
+		start := time.Now()
+		testResults[i] = ValidateMetric(t.DimensionFactory, t.Definition.Namespace, m.spec())
+		testResults[i].Duration = time.Since(start)
+	}
+
+	return status.TestGroupResult{
+		Name:        t.GetTestName(),
+		TestResults: testResults,
+	}
+}
+
+func (t *DefinedTestRunner) GetTestName() string {
+	return t.Definition.Name
+}
+
+func (t *DefinedTestRunner) GetAgentConfigFileName() string {
+	return t.Definition.AgentConfig
+}
+
+func (t *DefinedTestRunner) GetAgentRunDuration() time.Duration {
+	if t.Definition.Duration == 0 {
+		return t.BaseTestRunner.GetAgentRunDuration()
+	}
+	return t.Definition.Duration
+}
+
+func (t *DefinedTestRunner) GetRetries() int {
+	if t.Definition.Retries == nil {
+		return t.BaseTestRunner.GetRetries()
+	}
+	return *t.Definition.Retries
+}
+
+func (t *DefinedTestRunner) GetMeasuredMetrics() []string {
+	names := make([]string, len(t.Definition.Metrics))
+	for i, m := range t.Definition.Metrics {
+		names[i] = m.Name
+	}
+	return names
+}
+
+// validate rejects the expectations which would silently check less than the definition says
+func (m MetricDefinition) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("metric is missing a name")
+	}
+	if m.Expected != nil {
+		if m.TolerancePercent <= 0 {
+			return fmt.Errorf("metric %s sets expected without a positive tolerance_percent", m.Name)
+		}
+		if m.Min != nil || m.Max != nil {
+			return fmt.Errorf("metric %s sets expected together with min or max", m.Name)
+		}
+	} else if m.TolerancePercent != 0 {
+		return fmt.Errorf("metric %s sets tolerance_percent without expected", m.Name)
+	}
+	if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+		return fmt.Errorf("metric %s sets min %v above max %v", m.Name, *m.Min, *m.Max)
+	}
+	return nil
+}
+
+// spec declares the metric with the Bounds and Unit validators of the definition. The unit is only checked when the
+// definition sets one or the metric has a known unit.
+func (m MetricDefinition) spec() MetricSpec {
+	instructions := make([]dimension.Instruction, len(m.Dimensions))
+	for i, d := range m.Dimensions {
+		value := dimension.UnknownDimensionValue()
+		if d.Value != "" {
+			v := d.Value
+			value = dimension.ExpectedDimensionValue{Value: &v}
+		}
+		instructions[i] = dimension.Instruction{Key: d.Key, Value: value}
+	}
+
+	validators := []MetricValidator{Bounds{m.bounds()}}
+	if _, known := metric.ExpectedUnits[m.Name]; m.Unit != "" || known {
+		validators = append(validators, Unit{Expected: types.StandardUnit(m.Unit)})
+	}
+	return MetricSpec{Name: m.Name, Dimensions: instructions, Validators: validators}
+}
+
+// bounds returns the bounds of the definition, a nil Min or Max is unbounded
+func (m MetricDefinition) bounds() metric.Bounds {
+	if m.Expected != nil {
+		return metric.Within(*m.Expected, m.TolerancePercent)
+	}
+	b := metric.Bounds{Min: math.Inf(-1), Max: math.Inf(1)}
+	if m.Min != nil {
+		b.Min = *m.Min
+	}
+	if m.Max != nil {
+		b.Max = *m.Max
+	}
+	return b
+}
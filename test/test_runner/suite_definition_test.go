@@ -0,0 +1,135 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func writeSuiteDefinition(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "suite.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadSuiteDefinition(t *testing.T) {
+	path := writeSuiteDefinition(t, `
+runners:
+  - name: CpuPerCore
+    agent_config: cpu_per_core_config.json
+    namespace: Test
+    metrics:
+      - name: cpu_usage_active
+        dimensions:
+          - key: cpu
+            value: cpu0
+        min: 0
+        max: 100
+        unit: Percent
+      - name: mem_total
+        expected: 1024
+        tolerance_percent: 1
+`)
+	def, err := LoadSuiteDefinition(path)
+	require.NoError(t, err)
+	require.Len(t, def.Runners, 1)
+	r := def.Runners[0]
+	assert.Equal(t, "CpuPerCore", r.Name)
+	require.Len(t, r.Metrics, 2)
+	assert.Equal(t, metric.Bounds{Min: 0, Max: 100}, r.Metrics[0].bounds())
+	assert.Equal(t, metric.Within(1024, 1), r.Metrics[1].bounds())
+}
+
+func TestLoadSuiteDefinitionRejectsInvalidMetrics(t *testing.T) {
+	testCases := map[string]string{
+		"ExpectedWithoutTolerance": "expected: 10",
+		"ToleranceWithoutExpected": "tolerance_percent: 5",
+		"NegativeTolerance":        "expected: 10\n        tolerance_percent: -5",
+		"ExpectedWithMin":          "expected: 10\n        tolerance_percent: 5\n        min: 0",
+		"MinAboveMax":              "min: 10\n        max: 1",
+	}
+	for name, fields := range testCases {
+		t.Run(name, func(t *testing.T) {
+			path := writeSuiteDefinition(t, `
+runners:
+  - name: Invalid
+    agent_config: config.json
+    metrics:
+      - name: cpu_usage_active
+        `+fields+`
+`)
+			_, err := LoadSuiteDefinition(path)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMetricDefinitionSpec(t *testing.T) {
+	min, max := 0.0, 100.0
+	spec := MetricDefinition{
+		Name:       "cpu_usage_active",
+		Dimensions: []DimensionDefinition{{Key: "cpu", Value: "cpu0"}, {Key: "InstanceId"}},
+		Min:        &min,
+		Max:        &max,
+		Unit:       "Percent",
+	}.spec()
+	assert.Equal(t, "cpu_usage_active", spec.Name)
+	require.Len(t, spec.Dimensions, 2)
+	assert.True(t, spec.Dimensions[0].Value.IsKnown())
+	assert.False(t, spec.Dimensions[1].Value.IsKnown())
+	assert.Equal(t, []MetricValidator{Bounds{metric.Bounds{Min: 0, Max: 100}}, Unit{Expected: types.StandardUnitPercent}}, spec.Validators)
+
+	spec = MetricDefinition{Name: "custom_metric", Min: &min}.spec()
+	require.Len(t, spec.Validators, 1, "the unit of an unknown metric is not checked unless it is set")
+}
+
+func TestDefinedTestRunnerValidate(t *testing.T) {
+	client := &mocks.CloudWatchMock{}
+	original := awsservice.CwmClient
+	awsservice.CwmClient = client
+	t.Cleanup(func() { awsservice.CwmClient = original })
+
+	for name, values := range map[string][]float64{"within_bounds": {40, 60}, "out_of_bounds": {40, 160}} {
+		name, values := name, values
+		client.On("GetMetricData", mock.Anything, mock.MatchedBy(func(in *cloudwatch.GetMetricDataInput) bool {
+			return aws.ToString(in.MetricDataQueries[0].MetricStat.Metric.MetricName) == name
+		})).Return(&cloudwatch.GetMetricDataOutput{
+			MetricDataResults: []types.MetricDataResult{{Values: values}},
+		}, nil)
+	}
+
+	min, max := 0.0, 100.0
+	runner := &DefinedTestRunner{
+		BaseTestRunner: BaseTestRunner{DimensionFactory: dimension.Factory{Providers: []dimension.IProvider{&dimension.CustomDimensionProvider{}}}},
+		Definition: RunnerDefinition{
+			Name:      "Bounds",
+			Namespace: "Test",
+			Metrics: []MetricDefinition{
+				{Name: "within_bounds", Dimensions: []DimensionDefinition{{Key: "cpu", Value: "cpu0"}}, Min: &min, Max: &max},
+				{Name: "out_of_bounds", Dimensions: []DimensionDefinition{{Key: "cpu", Value: "cpu0"}}, Min: &min, Max: &max},
+			},
+		},
+	}
+	result := runner.Validate()
+	require.Len(t, result.TestResults, 2)
+	assert.Equal(t, status.SUCCESSFUL, result.TestResults[0].Status)
+	assert.Equal(t, status.FAILED, result.TestResults[1].Status)
+}
@@ -7,7 +7,12 @@ package test_runner
 
 import (
 	"fmt"
+
 	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentversion"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/health"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/notify"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/report"
 )
 
 type ITestSuite interface {
@@ -28,7 +33,10 @@ func (suite *TestSuite) SetupSuite() {
 }
 
 func (suite *TestSuite) TearDownSuite() {
-	suite.Result.Print()
+	suite.Result.AgentVersion = agentversion.Get()
+	report.Send(suite.Result)
+	notify.Send(suite.Result.Summary())
+	health.Publish(suite.GetSuiteName())
 	fmt.Printf(">>>> Finished %s TestSuite", suite.GetSuiteName())
 }
 
@@ -0,0 +1,105 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package test_runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const translationTestName = "Translating Config"
+
+// translatedInputs maps the metrics_collected keys of the JSON config to the telegraf inputs the translator generates
+// for them when they differ. Keys translated to the OTel YAML instead of the TOML, e.g. prometheus, map to "".
+var translatedInputs = map[string]string{
+	"collectd":   "socket_listener",
+	"nvidia_gpu": "nvidia_smi",
+	"prometheus": "",
+	"jmx":        "",
+	"otlp":       "",
+}
+
+// expectedTranslatorSections returns the TOML input sections the translator must generate for the JSON config. Outputs
+// are not checked, since newer agents generate them in the OTel YAML.
+func expectedTranslatorSections(configPath string) ([]string, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Metrics *struct {
+			MetricsCollected map[string]json.RawMessage `json:"metrics_collected"`
+		} `json:"metrics"`
+		Logs *struct {
+			LogsCollected struct {
+				Files *json.RawMessage `json:"files"`
+			} `json:"logs_collected"`
+		} `json:"logs"`
+	}
+	if err = json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	var sections []string
+	if config.Metrics != nil {
+		for key := range config.Metrics.MetricsCollected {
+			input, ok := translatedInputs[key]
+			if !ok {
+				input = key
+			}
+			if input != "" {
+				sections = append(sections, fmt.Sprintf("[[inputs.%s]]", input))
+			}
+		}
+	}
+	if config.Logs != nil && config.Logs.LogsCollected.Files != nil {
+		sections = append(sections, "[[inputs.logfile]]")
+	}
+	sort.Strings(sections)
+	return sections, nil
+}
+
+// validateTranslation runs the translator on the config and checks every expected pipeline section is generated, so
+// translation regressions fail with the missing sections instead of as missing metrics later on
+func validateTranslation(configPath string) status.TestResult {
+	testResult := status.TestResult{
+		Name:   translationTestName,
+		Status: status.FAILED,
+	}
+
+	expected, err := expectedTranslatorSections(configPath)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+	testResult.Expected = strings.Join(expected, ", ")
+
+	toml, err := common.TranslateConfig(configPath)
+	if err != nil {
+		testResult.Reason = err.Error()
+		return testResult
+	}
+
+	var missing []string
+	for _, section := range expected {
+		if !strings.Contains(toml, section) {
+			missing = append(missing, section)
+		}
+	}
+	if len(missing) > 0 {
+		testResult.Reason = fmt.Sprintf("translated config is missing %s", strings.Join(missing, ", "))
+		return testResult
+	}
+
+	testResult.Status = status.SUCCESSFUL
+	return testResult
+}
@@ -0,0 +1,266 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/environment"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric/dimension"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/scenario"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/status"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	namespace            = "UpgradeTest"
+	agentRuntime         = 2 * time.Minute
+	agentConfigLocalPath = "agent_configs/config.json"
+	agentConfigPath      = "/opt/aws/amazon-cloudwatch-agent/bin/config.json"
+	logFilePath          = "/tmp/upgrade.log"
+	logStreamName        = "upgrade"
+	logLinePrefix        = "upgrade log line "
+	logLineInterval      = 100 * time.Millisecond
+	// flushWait lets the agent send what it tailed before it is stopped
+	flushWait = 30 * time.Second
+
+	// releaseUrl serves the released packages, e.g. amazon_linux/amd64/1.300032.2/amazon-cloudwatch-agent.rpm
+	releaseUrl     = "https://amazoncloudwatch-agent.s3.amazonaws.com"
+	latestRelease  = "latest"
+	releasePackage = "./release-amazon-cloudwatch-agent"
+	// buildPackage is the package under test, terraform downloads it to the root of the repository
+	buildPackage = "../../amazon-cloudwatch-agent"
+)
+
+var envMetaDataStrings = &(environment.MetaDataStrings{})
+
+func init() {
+	environment.RegisterEnvironmentMetaDataFlags(envMetaDataStrings)
+}
+
+// TestUpgrade runs a released agent, upgrades it in place to the build under test while a log file is written, and
+// validates no log line is lost and the metrics have no gap across the upgrade
+func TestUpgrade(t *testing.T) {
+	env := environment.GetEnvironmentMetaData(envMetaDataStrings)
+	instanceId := awsservice.GetInstanceId()
+	pm, extension := packageManager()
+	fromVersion := env.UpgradeFromVersion
+	if fromVersion == "" {
+		fromVersion = latestRelease
+	}
+	var releasedVersion string
+	var written int64
+
+	result := scenario.Scenario{
+		Name: "Upgrade",
+		Steps: []scenario.Step{
+			{Name: "install the released agent " + fromVersion, Run: func(scenario.State) error {
+				if err := downloadRelease(pm, fromVersion, releasePackage+extension); err != nil {
+					return err
+				}
+				// the build under test is installed by terraform, it is downgraded to the release
+				_ = common.UninstallAgent(pm)
+				if err := common.InstallAgent(releasePackage + extension); err != nil {
+					return err
+				}
+				var err error
+				releasedVersion, err = common.GetInstalledAgentVersion()
+				return err
+			}},
+			{Name: "start the released agent", Run: func(scenario.State) error {
+				common.CopyFile(agentConfigLocalPath, agentConfigPath)
+				return common.StartAgent(agentConfigPath, false, false)
+			}},
+			scenario.Mark("start"),
+			{Name: "write log lines", Run: func(state scenario.State) error {
+				ctx, cancel := context.WithCancel(context.Background())
+				state["stopWriting"] = cancel
+				go writeLogLines(ctx, &written)
+				return nil
+			}},
+			scenario.Wait(agentRuntime),
+			{Name: "upgrade to the build under test", Run: func(scenario.State) error {
+				return upgrade(buildPackage+extension, releasedVersion)
+			}},
+			scenario.Wait(agentRuntime),
+			{Name: "stop writing log lines", Run: func(state scenario.State) error {
+				state["stopWriting"].(context.CancelFunc)()
+				time.Sleep(flushWait)
+				return nil
+			}},
+			scenario.StopAgent(),
+			scenario.Mark("end"),
+			{Name: "validate no log line was lost", Run: func(state scenario.State) error {
+				return validateLogs(instanceId, state.Time("start"), state.Time("end"), int(atomic.LoadInt64(&written)))
+			}},
+			{Name: "validate the metrics have no gap", Run: func(state scenario.State) error {
+				return validateMetrics(env, state.Time("start"), state.Time("end"))
+			}},
+		},
+		Cleanup: []scenario.Step{
+			{Name: "stop writing log lines", Run: func(state scenario.State) error {
+				if cancel, ok := state["stopWriting"].(context.CancelFunc); ok {
+					cancel()
+				}
+				return nil
+			}},
+			{Name: "delete log group", Run: func(scenario.State) error {
+				awsservice.DeleteLogGroupAndStream(instanceId, logStreamName)
+				return nil
+			}},
+		},
+	}.Run()
+
+	for _, r := range result.TestResults {
+		if r.Status != status.SUCCESSFUL {
+			t.Errorf("step %s failed: %s", r.Name, r.Reason)
+		}
+	}
+}
+
+// packageManager picks the package format of the build terraform downloaded
+func packageManager() (common.PackageManager, string) {
+	if _, err := os.Stat(buildPackage + ".deb"); err == nil {
+		return common.DEB, ".deb"
+	}
+	return common.RPM, ".rpm"
+}
+
+func downloadRelease(pm common.PackageManager, version, path string) error {
+	platform, extension := "amazon_linux", "rpm"
+	if pm == common.DEB {
+		platform, extension = "ubuntu", "deb"
+	}
+	url := fmt.Sprintf("%s/%s/%s/%s/amazon-cloudwatch-agent.%s", releaseUrl, platform, runtime.GOARCH, version, extension)
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// upgrade installs the package over the running agent, the package restarts the agent it replaced
+func upgrade(path, releasedVersion string) error {
+	if err := common.InstallAgent(path); err != nil {
+		return err
+	}
+	version, err := common.GetInstalledAgentVersion()
+	if err != nil {
+		return err
+	}
+	if version == releasedVersion {
+		return fmt.Errorf("the agent is still %s after the upgrade", version)
+	}
+	running, err := common.IsAgentRunning()
+	if err != nil {
+		return err
+	}
+	if !running {
+		return fmt.Errorf("the agent is not running after the upgrade from %s to %s", releasedVersion, version)
+	}
+	return nil
+}
+
+// writeLogLines appends numbered lines until the context is done, written is the number of lines written
+func writeLogLines(ctx context.Context, written *int64) {
+	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	ticker := time.NewTicker(logLineInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err = fmt.Fprintf(f, "%s%d\n", logLinePrefix, atomic.LoadInt64(written)); err != nil {
+				return
+			}
+			atomic.AddInt64(written, 1)
+		}
+	}
+}
+
+// validateLogs fails unless every line number was delivered. The agent may send lines again after the restart, so
+// duplicates are not a failure.
+func validateLogs(instanceId string, start, end time.Time, written int) error {
+	if written == 0 {
+		return fmt.Errorf("no log line was written")
+	}
+	delivered := map[int]struct{}{}
+	ok, err := awsservice.ValidateLogs(instanceId, logStreamName, &start, &end, awsservice.StringValidator(func(logs []string) bool {
+		for _, l := range logs {
+			if !strings.HasPrefix(l, logLinePrefix) {
+				continue
+			}
+			if n, err := strconv.Atoi(strings.TrimPrefix(l, logLinePrefix)); err == nil {
+				delivered[n] = struct{}{}
+			}
+		}
+		return len(delivered) == written
+	}))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		for n := 0; n < written; n++ {
+			if _, found := delivered[n]; !found {
+				return fmt.Errorf("%d of the %d log lines were delivered, line %d is the first missing", len(delivered), written, n)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMetrics expects a value for every minute of the window but the partial first and last minutes
+func validateMetrics(env *environment.MetaData, start, end time.Time) error {
+	factory := dimension.GetDimensionFactory(*env)
+	dims, err := factory.GetDimensions([]dimension.Instruction{
+		{Key: "InstanceId", Value: dimension.UnknownDimensionValue()},
+		{Key: "cpu", Value: dimension.ExpectedDimensionValue{Value: aws.String("cpu-total")}},
+	})
+	if err != nil {
+		return err
+	}
+	fetcher := metric.MetricValueFetcher{}
+	values, err := fetcher.FetchWindow(namespace, "cpu_usage_active", dims, metric.AVERAGE, 60, start, end)
+	if err != nil {
+		return err
+	}
+	expected := int(end.Sub(start)/time.Minute) - 2
+	if len(values) < expected {
+		return fmt.Errorf("cpu_usage_active has %d values across the upgrade, expected at least %d", len(values), expected)
+	}
+	return nil
+}
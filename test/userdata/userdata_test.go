@@ -30,25 +30,16 @@ func init() {
 }
 
 func (t UserdataTestRunner) Validate() status.TestGroupResult {
-	metricsToFetch := t.GetMeasuredMetrics()
-	testResults := make([]status.TestResult, len(metricsToFetch))
-	for i, metricName := range metricsToFetch {
-		testResults[i] = t.validateCpuMetric(metricName)
-	}
-
-	return status.TestGroupResult{
-		Name:        t.GetTestName(),
-		TestResults: testResults,
-	}
+	return test_runner.ValidateEachMetric(&t)
 }
 
-func (t *UserdataTestRunner) validateCpuMetric(metricName string) status.TestResult {
+func (t *UserdataTestRunner) ValidateMetric(metricName string) status.TestResult {
 	testResult := status.TestResult{
 		Name:   metricName,
 		Status: status.FAILED,
 	}
 
-	dims, failed := t.DimensionFactory.GetDimensions([]dimension.Instruction{
+	dims, err := t.DimensionFactory.GetDimensions([]dimension.Instruction{
 		{
 			Key:   "InstanceId",
 			Value: dimension.UnknownDimensionValue(),
@@ -59,7 +50,8 @@ func (t *UserdataTestRunner) validateCpuMetric(metricName string) status.TestRes
 		},
 	})
 
-	if len(failed) > 0 {
+	if err != nil {
+		testResult.Reason = err.Error()
 		return testResult
 	}
 
@@ -126,4 +118,4 @@ func TestUserdata(t *testing.T) {
 	}
 }
 
-var _ test_runner.ITestRunner = (*UserdataTestRunner)(nil)
+var _ test_runner.MetricRunner = (*UserdataTestRunner)(nil)
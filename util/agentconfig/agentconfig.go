@@ -0,0 +1,230 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package agentconfig checks the JSON configs the runners start the agent with against the parts of the agent schema
+// the agent rejects a config for, so a malformed test config fails before anything is provisioned instead of as
+// missing telemetry once the agent ran.
+package agentconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// sections are the top level keys of the agent schema
+var sections = map[string]bool{"agent": true, "metrics": true, "logs": true, "traces": true}
+
+// ValidateFile validates the config at the path, the errors name the file
+func ValidateFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = Validate(content); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ValidateDir validates every JSON config under the directory, the ones under agent_configs directories. It returns
+// an error listing every invalid config, nil when they are all valid.
+func ValidateDir(dir string) error {
+	var failures []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" || !strings.Contains(filepath.ToSlash(path), "/agent_configs/") {
+			return nil
+		}
+		if err = ValidateFile(path); err != nil {
+			failures = append(failures, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d invalid agent configs:\n%s", len(failures), strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+// Validate checks the config is a JSON object of the sections of the agent schema, with the types and required
+// fields the agent checks at startup
+func Validate(content []byte) error {
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(content, &config); err != nil {
+		return syntaxError(content, err)
+	}
+
+	var problems []string
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !sections[key] {
+			problems = append(problems, fmt.Sprintf("unknown section %q", key))
+			continue
+		}
+		if !isObject(config[key]) {
+			problems = append(problems, fmt.Sprintf("%s must be an object", key))
+		}
+	}
+	if len(problems) == 0 {
+		problems = append(problems, validateAgent(config["agent"])...)
+		problems = append(problems, validateMetrics(config["metrics"])...)
+		problems = append(problems, validateLogs(config["logs"])...)
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func validateAgent(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var agent struct {
+		Interval json.RawMessage `json:"metrics_collection_interval"`
+	}
+	if err := json.Unmarshal(raw, &agent); err != nil {
+		return []string{fmt.Sprintf("agent: %v", err)}
+	}
+	return checkInterval("agent.metrics_collection_interval", agent.Interval)
+}
+
+func validateMetrics(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var metrics struct {
+		Collected        json.RawMessage `json:"metrics_collected"`
+		AppendDimensions json.RawMessage `json:"append_dimensions"`
+	}
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return []string{fmt.Sprintf("metrics: %v", err)}
+	}
+	var problems []string
+	if metrics.AppendDimensions != nil && !isObject(metrics.AppendDimensions) {
+		problems = append(problems, "metrics.append_dimensions must be an object")
+	}
+	if metrics.Collected == nil {
+		return append(problems, "metrics.metrics_collected is required")
+	}
+	var collected map[string]json.RawMessage
+	if err := json.Unmarshal(metrics.Collected, &collected); err != nil {
+		return append(problems, "metrics.metrics_collected must be an object")
+	}
+	plugins := make([]string, 0, len(collected))
+	for plugin := range collected {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+	for _, plugin := range plugins {
+		type pluginConfig struct {
+			Interval json.RawMessage `json:"metrics_collection_interval"`
+		}
+		// procstat is a list of the processes to monitor, every other plugin is an object
+		var list []pluginConfig
+		if err := json.Unmarshal(collected[plugin], &list); err == nil {
+			for i, p := range list {
+				problems = append(problems, checkInterval(fmt.Sprintf("metrics.metrics_collected.%s[%d].metrics_collection_interval", plugin, i), p.Interval)...)
+			}
+			continue
+		}
+		var p pluginConfig
+		if err := json.Unmarshal(collected[plugin], &p); err != nil {
+			problems = append(problems, fmt.Sprintf("metrics.metrics_collected.%s must be an object or a list", plugin))
+			continue
+		}
+		problems = append(problems, checkInterval(fmt.Sprintf("metrics.metrics_collected.%s.metrics_collection_interval", plugin), p.Interval)...)
+	}
+	return problems
+}
+
+func validateLogs(raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var logs struct {
+		Collected *struct {
+			Files *struct {
+				CollectList []map[string]json.RawMessage `json:"collect_list"`
+			} `json:"files"`
+			WindowsEvents *struct {
+				CollectList []map[string]json.RawMessage `json:"collect_list"`
+			} `json:"windows_events"`
+		} `json:"logs_collected"`
+		MetricsCollected json.RawMessage `json:"metrics_collected"`
+	}
+	if err := json.Unmarshal(raw, &logs); err != nil {
+		return []string{fmt.Sprintf("logs: %v", err)}
+	}
+	if logs.Collected == nil {
+		// the EMF and Container Insights configs only have logs.metrics_collected
+		if logs.MetricsCollected == nil {
+			return []string{"logs needs logs_collected or metrics_collected"}
+		}
+		return nil
+	}
+	var problems []string
+	if logs.Collected.Files != nil {
+		problems = append(problems, checkRequired("logs.logs_collected.files.collect_list", logs.Collected.Files.CollectList, "file_path")...)
+	}
+	if logs.Collected.WindowsEvents != nil {
+		problems = append(problems, checkRequired("logs.logs_collected.windows_events.collect_list", logs.Collected.WindowsEvents.CollectList, "event_name")...)
+	}
+	return problems
+}
+
+// checkRequired returns a problem for every entry of the list without a non empty string for the key
+func checkRequired(list string, entries []map[string]json.RawMessage, key string) []string {
+	var problems []string
+	for i, e := range entries {
+		var value string
+		if err := json.Unmarshal(e[key], &value); err != nil || value == "" {
+			problems = append(problems, fmt.Sprintf("%s[%d].%s is required", list, i, key))
+		}
+	}
+	return problems
+}
+
+// checkInterval returns a problem when the interval is set and is not a positive number of seconds
+func checkInterval(name string, raw json.RawMessage) []string {
+	if raw == nil {
+		return nil
+	}
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err != nil || seconds < 1 {
+		return []string{fmt.Sprintf("%s must be a number of seconds of at least 1, not %s", name, raw)}
+	}
+	return nil
+}
+
+func isObject(raw json.RawMessage) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{"))
+}
+
+// syntaxError adds the line and column of a JSON syntax error, the offset alone is hard to find in a config
+func syntaxError(content []byte, err error) error {
+	var syntax *json.SyntaxError
+	if !errors.As(err, &syntax) {
+		return fmt.Errorf("config is not a JSON object: %w", err)
+	}
+	// the offset is just past the invalid character
+	before := content[:syntax.Offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n') - 1
+	return fmt.Errorf("invalid JSON at line %d, column %d: %w", line, column, err)
+}
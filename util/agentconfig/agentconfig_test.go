@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agentconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		config string
+		want   string
+	}{
+		"Valid": {config: `{
+			"agent": {"metrics_collection_interval": 10, "run_as_user": "root"},
+			"metrics": {"metrics_collected": {"mem": {"measurement": ["used_percent"]}, "procstat": [{"exe": "cwagent", "metrics_collection_interval": 15}]}},
+			"logs": {"logs_collected": {"files": {"collect_list": [{"file_path": "/tmp/test.log"}]}}}
+		}`},
+		"EMFOnly":          {config: `{"logs": {"metrics_collected": {"emf": {}}}}`},
+		"Syntax":           {config: "{\n  \"agent\": {\n    \"debug\": true,\n  }\n}", want: "invalid JSON at line 4, column 3"},
+		"NotObject":        {config: `[]`, want: "config is not a JSON object"},
+		"UnknownSection":   {config: `{"agents": {}}`, want: `unknown section "agents"`},
+		"SectionType":      {config: `{"metrics": []}`, want: "metrics must be an object"},
+		"NoMetrics":        {config: `{"metrics": {"namespace": "Test"}}`, want: "metrics.metrics_collected is required"},
+		"PluginType":       {config: `{"metrics": {"metrics_collected": {"cpu": "all"}}}`, want: "metrics.metrics_collected.cpu must be an object or a list"},
+		"IntervalString":   {config: `{"agent": {"metrics_collection_interval": "10"}}`, want: `agent.metrics_collection_interval must be a number of seconds of at least 1, not "10"`},
+		"IntervalZero":     {config: `{"metrics": {"metrics_collected": {"disk": {"metrics_collection_interval": 0}}}}`, want: "metrics.metrics_collected.disk.metrics_collection_interval"},
+		"MissingFilePath":  {config: `{"logs": {"logs_collected": {"files": {"collect_list": [{"file_path": "/a"}, {"log_group_name": "b"}]}}}}`, want: "logs.logs_collected.files.collect_list[1].file_path is required"},
+		"MissingEventName": {config: `{"logs": {"logs_collected": {"windows_events": {"collect_list": [{"levels": ["ERROR"]}]}}}}`, want: "windows_events.collect_list[0].event_name is required"},
+		"EmptyLogs":        {config: `{"logs": {"force_flush_interval": 5}}`, want: "logs needs logs_collected or metrics_collected"},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := Validate([]byte(testCase.config))
+			if testCase.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.want)
+		})
+	}
+}
+
+func TestValidateDir(t *testing.T) {
+	dir := t.TempDir()
+	configs := filepath.Join(dir, "suite", "agent_configs")
+	require.NoError(t, os.MkdirAll(configs, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configs, "good.json"), []byte(`{"agent": {}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(configs, "bad.json"), []byte(`{"agent": `), 0644))
+	// only the agent configs are validated, not the other JSON resources of the suites
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "suite", "schema.json"), []byte(`[]`), 0644))
+
+	err := ValidateDir(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 invalid agent configs")
+	assert.Contains(t, err.Error(), "bad.json")
+	assert.NotContains(t, err.Error(), "good.json")
+
+	require.NoError(t, os.Remove(filepath.Join(configs, "bad.json")))
+	assert.NoError(t, ValidateDir(dir))
+}
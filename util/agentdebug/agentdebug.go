@@ -0,0 +1,121 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agentdebug
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+var (
+	// debug and awsSdkLogLevel are unset unless -agentDebug or -agentAwsSdkLogLevel are provided
+	debug          bool
+	awsSdkLogLevel string
+)
+
+// Configure sets whether the runners start the agent with debug logging and the aws_sdk_log_level they start it
+// with. An empty level keeps the level of the config.
+func Configure(debugLogging bool, sdkLogLevel string) {
+	debug = debugLogging
+	awsSdkLogLevel = sdkLogLevel
+}
+
+// ParseAwsSdkLogLevel checks the level is a | delimited list of the levels of the agent, ex LogDebug|LogDebugWithHTTPBody
+func ParseAwsSdkLogLevel(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, level := range strings.Split(s, "|") {
+		if !strings.HasPrefix(level, "LogDebug") {
+			return "", fmt.Errorf("invalid aws_sdk_log_level %q, the levels start with LogDebug", level)
+		}
+	}
+	return s, nil
+}
+
+// Enabled is true when the runners start the agent with verbose logging
+func Enabled() bool {
+	return debug || awsSdkLogLevel != ""
+}
+
+// Apply sets agent.debug and agent.aws_sdk_log_level of the parsed agent config, the other fields are left as is
+func Apply(config map[string]interface{}) {
+	if !Enabled() {
+		return
+	}
+	agent, ok := config["agent"].(map[string]interface{})
+	if !ok {
+		agent = map[string]interface{}{}
+		config["agent"] = agent
+	}
+	if debug {
+		agent["debug"] = true
+	}
+	if awsSdkLogLevel != "" {
+		agent["aws_sdk_log_level"] = awsSdkLogLevel
+	}
+}
+
+// Capture keeps the part of the agent log written while a runner ran, so the verbose logs of a failed runner are not
+// buried in the logs of the runners before it
+type Capture struct {
+	logFile string
+	offset  int64
+}
+
+// Start remembers where the agent log ends. It returns nil when verbose logging is disabled, which captures nothing.
+func Start(logFile string) *Capture {
+	if !Enabled() {
+		return nil
+	}
+	c := &Capture{logFile: logFile}
+	if info, err := os.Stat(logFile); err == nil {
+		c.offset = info.Size()
+	}
+	return c
+}
+
+// Save writes the agent log written since Start to a file named after the runner and returns its path
+func (c *Capture) Save(name string) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	in, err := os.Open(c.logFile)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return "", err
+	}
+	// a log smaller than at the start was rotated, so all of it was written since
+	offset := c.offset
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err = in.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s-agent-debug.log", runid.Get(), sanitize(name)))
+	out, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+	if _, err = io.Copy(out, in); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func sanitize(name string) string {
+	return strings.NewReplacer("/", "-", " ", "-", "\\", "-").Replace(name)
+}
@@ -0,0 +1,79 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agentdebug
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAwsSdkLogLevel(t *testing.T) {
+	level, err := ParseAwsSdkLogLevel("LogDebug|LogDebugWithHTTPBody")
+	assert.NoError(t, err)
+	assert.Equal(t, "LogDebug|LogDebugWithHTTPBody", level)
+	level, err = ParseAwsSdkLogLevel("")
+	assert.NoError(t, err)
+	assert.Empty(t, level)
+	_, err = ParseAwsSdkLogLevel("LogDebug|Verbose")
+	assert.ErrorContains(t, err, `"Verbose"`)
+}
+
+func TestApply(t *testing.T) {
+	defer Configure(false, "")
+
+	config := map[string]interface{}{"metrics": map[string]interface{}{}}
+	Configure(false, "")
+	Apply(config)
+	assert.NotContains(t, config, "agent", "the config is left as is when verbose logging is disabled")
+
+	Configure(true, "LogDebugWithSigning")
+	Apply(config)
+	assert.Equal(t, map[string]interface{}{"debug": true, "aws_sdk_log_level": "LogDebugWithSigning"}, config["agent"])
+
+	config = map[string]interface{}{"agent": map[string]interface{}{"run_as_user": "root"}}
+	Configure(false, "LogDebug")
+	Apply(config)
+	assert.Equal(t, map[string]interface{}{"run_as_user": "root", "aws_sdk_log_level": "LogDebug"}, config["agent"])
+}
+
+func TestCapture(t *testing.T) {
+	defer Configure(false, "")
+	logFile := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("I! earlier runner\n"), 0644))
+
+	Configure(false, "")
+	assert.Nil(t, Start(logFile), "nothing is captured when verbose logging is disabled")
+	var disabled *Capture
+	path, err := disabled.Save("Disabled")
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+
+	Configure(true, "")
+	c := Start(logFile)
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("D! [outputs.cloudwatch] Buffer fullness\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	path, err = c.Save("Mem Runner")
+	require.NoError(t, err)
+	defer os.Remove(path)
+	assert.Contains(t, filepath.Base(path), "Mem-Runner")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "D! [outputs.cloudwatch] Buffer fullness\n", string(content))
+
+	// the rotated log is all written after the start
+	require.NoError(t, os.WriteFile(logFile, []byte("D! new\n"), 0644))
+	path, err = c.Save("Mem Runner")
+	require.NoError(t, err)
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "D! new\n", string(content))
+}
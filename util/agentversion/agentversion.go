@@ -0,0 +1,49 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package agentversion
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+var (
+	expected string
+
+	installedOnce sync.Once
+	installed     string
+)
+
+// SetExpected sets the version the installed agent must match. An empty version disables the check.
+func SetExpected(version string) {
+	expected = strings.TrimSpace(version)
+}
+
+// Get returns the version of the installed agent, or an empty string when it cannot be read, e.g. on ECS and EKS
+// where the agent does not run on the test host
+func Get() string {
+	installedOnce.Do(func() {
+		var err error
+		if installed, err = common.GetInstalledAgentVersion(); err != nil {
+			logger.Errorf("Failed to read the installed agent version: %v", err)
+		}
+	})
+	return installed
+}
+
+// Check returns an error if an expected version is set and the installed agent does not match it, so a run never
+// reports results for the wrong build
+func Check() error {
+	if expected == "" {
+		return nil
+	}
+	if actual := Get(); actual != expected {
+		return fmt.Errorf("installed agent version %q does not match the expected version %q", actual, expected)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package artifact
+
+import (
+	"archive/zip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const keyPrefix = "artifacts"
+
+// bucket is empty unless -artifactBucket is provided, which disables the upload
+var bucket string
+
+// SetBucket configures the bucket artifacts are uploaded to
+func SetBucket(b string) {
+	bucket = b
+}
+
+// UploadOnFailure zips the agent log, the agent config, the translated TOML and the captured test logs and uploads
+// them to artifacts/<run id>/<name>.zip so failures can be diagnosed without access to the host.
+// extraFiles are added as is, e.g. the source agent config of the runner.
+func UploadOnFailure(name string, extraFiles []string, testLogs []string) (string, error) {
+	files := append([]string{common.AgentLogFile, common.ConfigOutputPath, common.AgentTomlFile}, extraFiles...)
+	return upload(name, files, testLogs)
+}
+
+// Upload zips the files and uploads them to artifacts/<run id>/<name>.zip whatever the outcome of the run, e.g. the
+// profiles captured from the agent.
+func Upload(name string, files []string) (string, error) {
+	return upload(name, files, nil)
+}
+
+func upload(name string, files []string, testLogs []string) (string, error) {
+	if bucket == "" {
+		return "", nil
+	}
+
+	zipPath := filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s.zip", runid.Get(), sanitize(name)))
+	defer os.Remove(zipPath)
+
+	if err := writeZip(zipPath, files, testLogs); err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s/%s/%s.zip", keyPrefix, runid.Get(), sanitize(name))
+	if err := awsservice.UploadFile(bucket, key, zipPath); err != nil {
+		return "", err
+	}
+
+	location := fmt.Sprintf("s3://%s/%s", bucket, key)
+	logger.Infof("Uploaded artifacts for %s to %s", name, location)
+	return location, nil
+}
+
+func writeZip(zipPath string, files []string, testLogs []string) error {
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			// missing files are expected, e.g. the TOML when the config never translated
+			logger.Warnf("Skipping artifact %s: %v", f, err)
+			continue
+		}
+		if err = addToZip(w, filepath.Base(f), content); err != nil {
+			return err
+		}
+	}
+
+	if len(testLogs) > 0 {
+		if err = addToZip(w, "test.log", []byte(strings.Join(testLogs, "\n"))); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func addToZip(w *zip.Writer, name string, content []byte) error {
+	entry, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(content)
+	return err
+}
+
+func sanitize(name string) string {
+	return strings.NewReplacer("/", "-", " ", "-", "\\", "-").Replace(name)
+}
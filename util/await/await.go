@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package await
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Predicate reports whether the awaited condition holds. A non-nil error stops the wait immediately, so conditions
+// that are expected to resolve, e.g. a log group that is not created yet, should return false and no error.
+type Predicate func() (bool, error)
+
+// TimeoutError is returned when the condition did not hold before the timeout
+type TimeoutError struct {
+	Description string
+	Waited      time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("waited %s for %s", e.Waited.Round(time.Second), e.Description)
+}
+
+// WaitUntil evaluates the predicate immediately and then every interval until it holds, it returns an error or the
+// timeout expires. The description names what is awaited, e.g. "log group X", and is used in the timeout error.
+func WaitUntil(ctx context.Context, interval, timeout time.Duration, description string, predicate Predicate) error {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		done, err := predicate()
+		if err != nil {
+			return fmt.Errorf("failed while waiting for %s: %w", description, err)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &TimeoutError{Description: description, Waited: time.Since(start)}
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const alarmStatePollInterval = 15 * time.Second
+
+// PutMetricAlarm creates or updates the alarm and tags it with the run tags, so SweepAlarms cleans it up if the test
+// aborts. The tags of the input are only applied on creation, so an updated alarm is tagged separately.
+func PutMetricAlarm(input *cloudwatch.PutMetricAlarmInput) error {
+	if _, err := CwmClient.PutMetricAlarm(ctx, input); err != nil {
+		return err
+	}
+	alarm, err := DescribeAlarm(aws.ToString(input.AlarmName))
+	if err == nil {
+		err = TagAlarmForRun(aws.ToString(alarm.AlarmArn))
+	}
+	if err != nil {
+		logger.Errorf("Failed to tag alarm %s: %v", aws.ToString(input.AlarmName), err)
+	}
+	return nil
+}
+
+// DescribeAlarm returns the metric alarm with the name
+func DescribeAlarm(alarmName string) (*types.MetricAlarm, error) {
+	output, err := CwmClient.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{
+		AlarmNames: []string{alarmName},
+		AlarmTypes: []types.AlarmType{types.AlarmTypeMetricAlarm},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.MetricAlarms) == 0 {
+		return nil, fmt.Errorf("alarm %s not found", alarmName)
+	}
+	return &output.MetricAlarms[0], nil
+}
+
+// WaitForAlarmState polls the alarm until it is in the state. On timeout the returned error wraps an
+// await.TimeoutError and includes the last seen state.
+func WaitForAlarmState(alarmName string, state types.StateValue, timeout time.Duration) error {
+	lastState := types.StateValue("")
+	err := await.WaitUntil(ctx, alarmStatePollInterval, timeout, fmt.Sprintf("alarm %s to be in state %s", alarmName, state), func() (bool, error) {
+		alarm, err := DescribeAlarm(alarmName)
+		if err != nil {
+			return false, err
+		}
+		lastState = alarm.StateValue
+		return alarm.StateValue == state, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%w, last state %s", err, lastState)
+	}
+	return nil
+}
+
+// DeleteAlarm deletes the alarm by name
+func DeleteAlarm(alarmName string) error {
+	_, err := CwmClient.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{AlarmNames: []string{alarmName}})
+	return err
+}
@@ -0,0 +1,56 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func withCwmMock(t *testing.T) *mocks.CloudWatchMock {
+	client := &mocks.CloudWatchMock{}
+	original := awsservice.CwmClient
+	awsservice.CwmClient = client
+	t.Cleanup(func() { awsservice.CwmClient = original })
+	return client
+}
+
+func TestPutMetricAlarmTagsTheAlarm(t *testing.T) {
+	client := withCwmMock(t)
+	arn := "arn:aws:cloudwatch:us-west-2:123456789012:alarm:cwagent-test-alarm"
+	client.On("PutMetricAlarm", mock.Anything, mock.Anything).Return(&cloudwatch.PutMetricAlarmOutput{}, nil).Once()
+	client.On("DescribeAlarms", mock.Anything, mock.Anything).Return(&cloudwatch.DescribeAlarmsOutput{
+		MetricAlarms: []types.MetricAlarm{{AlarmName: aws.String("cwagent-test-alarm"), AlarmArn: aws.String(arn)}},
+	}, nil).Once()
+	client.On("TagResource", mock.Anything, mock.MatchedBy(func(in *cloudwatch.TagResourceInput) bool {
+		for _, tag := range in.Tags {
+			if aws.ToString(tag.Key) == awsservice.RunIdTagKey {
+				return aws.ToString(in.ResourceARN) == arn
+			}
+		}
+		return false
+	})).Return(&cloudwatch.TagResourceOutput{}, nil).Once()
+
+	assert.NoError(t, awsservice.PutMetricAlarm(&cloudwatch.PutMetricAlarmInput{AlarmName: aws.String("cwagent-test-alarm")}))
+	client.AssertExpectations(t)
+}
+
+// TestPutMetricAlarmIgnoresTaggingErrors is an alarm the sweeper finds by the run id in its name instead
+func TestPutMetricAlarmIgnoresTaggingErrors(t *testing.T) {
+	client := withCwmMock(t)
+	client.On("PutMetricAlarm", mock.Anything, mock.Anything).Return(&cloudwatch.PutMetricAlarmOutput{}, nil).Once()
+	client.On("DescribeAlarms", mock.Anything, mock.Anything).Return(nil, errors.New("throttled")).Once()
+
+	assert.NoError(t, awsservice.PutMetricAlarm(&cloudwatch.PutMetricAlarmInput{AlarmName: aws.String("cwagent-test-alarm")}))
+	client.AssertExpectations(t)
+}
@@ -0,0 +1,114 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// CacheTTL is how long DescribeLogGroups and ListMetrics results are reused. Only positive results are cached,
+// since callers poll until a log group or metric shows up.
+var CacheTTL = time.Minute
+
+type cacheEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// ttlCache is keyed by strings starting with the log group name or namespace, so entries can be invalidated by prefix
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry[V]
+}
+
+func newTTLCache[V any]() *ttlCache[V] {
+	return &ttlCache[V]{entries: map[string]cacheEntry[V]{}}
+}
+
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry[V]{value: value, expires: time.Now().Add(CacheTTL)}
+}
+
+func (c *ttlCache[V]) invalidate(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+var (
+	logGroupCache    = newTTLCache[bool]()
+	listMetricsCache = newTTLCache[[]types.Metric]()
+)
+
+// InvalidateLogGroup drops the cached existence of the log group, e.g. after it was deleted
+func InvalidateLogGroup(logGroupName string) {
+	logGroupCache.invalidate(logGroupName)
+}
+
+// InvalidateNamespace drops every cached ListMetrics result of the namespace
+func InvalidateNamespace(namespace string) {
+	listMetricsCache.invalidate(namespace + "|")
+}
+
+// InvalidateCaches drops every cached result
+func InvalidateCaches() {
+	logGroupCache.invalidate("")
+	listMetricsCache.invalidate("")
+}
+
+// ListMetrics returns every page of ListMetrics for the input. Non-empty results are cached for CacheTTL.
+func ListMetrics(input *cloudwatch.ListMetricsInput) ([]types.Metric, error) {
+	key := listMetricsKey(input)
+	if metrics, ok := listMetricsCache.get(key); ok {
+		return metrics, nil
+	}
+
+	var metrics []types.Metric
+	paginator := cloudwatch.NewListMetricsPaginator(CwmClient, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, output.Metrics...)
+	}
+	if len(metrics) > 0 {
+		listMetricsCache.set(key, metrics)
+	}
+	return metrics, nil
+}
+
+func listMetricsKey(input *cloudwatch.ListMetricsInput) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s|%s|%s", aws.ToString(input.Namespace), aws.ToString(input.MetricName), input.RecentlyActive))
+	for _, d := range input.Dimensions {
+		sb.WriteString(fmt.Sprintf("|%s=%s", aws.ToString(d.Name), aws.ToString(d.Value)))
+	}
+	return sb.String()
+}
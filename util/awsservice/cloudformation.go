@@ -8,6 +8,8 @@ import (
 	"github.com/google/uuid"
 	"log"
 	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 const instanceIdKey = "InstanceId"
@@ -24,7 +26,7 @@ func CreateStackName(stackPrefix string) string {
 }
 
 func StartStack(ctx context.Context, stackName string, client *cloudformation.Client, templateText string, timeOutInMinutes int32, parameters []types.Parameter) {
-	log.Printf("Template text : %s", templateText)
+	logger.Infof("Template text : %s", templateText)
 
 	// Create cf stack config
 	createStackInput := cloudformation.CreateStackInput{
@@ -62,16 +64,16 @@ func FindStackInstanceId(ctx context.Context, stackName string, client *cloudfor
 		}
 		stacks, err := client.DescribeStacks(ctx, &cfStackInput)
 		if err != nil || len(stacks.Stacks) != 1 || stacks.Stacks[0].StackStatus != types.StackStatusCreateComplete {
-			log.Printf("Stack %s not ready in minute %d continue to next minute", stackName, i)
+			logger.Warnf("Stack %s not ready in minute %d continue to next minute", stackName, i)
 		} else {
 			for output := range stacks.Stacks[0].Outputs {
 				if *stacks.Stacks[0].Outputs[output].OutputKey == instanceIdKey {
-					log.Printf("Found instance id %s from stack %s", *stacks.Stacks[0].Outputs[output].OutputValue, stackName)
+					logger.Infof("Found instance id %s from stack %s", *stacks.Stacks[0].Outputs[output].OutputValue, stackName)
 					return *stacks.Stacks[0].Outputs[output].OutputValue
 				}
 			}
 		}
-		log.Printf("Sleep for one minute to wait for stack to start")
+		logger.Infof("Sleep for one minute to wait for stack to start")
 		time.Sleep(time.Minute)
 	}
 	log.Fatalf("Stack not created within timeout %s", stackName)
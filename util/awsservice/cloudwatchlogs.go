@@ -6,16 +6,25 @@ package awsservice
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/qri-io/jsonschema"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/clock"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
-const logStreamRetry = 20
+const (
+	logStreamRetry = 20
+	// logEventsNotFoundTimeout is how long GetLogEvents is retried while the log group or stream does not exist yet
+	logEventsNotFoundTimeout = StandardRetries * 30 * time.Second
+)
 
 // catch ResourceNotFoundException when deleting the log group and log stream, as these
 // are not useful exceptions to log errors on during cleanup
@@ -35,38 +44,288 @@ func DeleteLogStream(logGroupName, logStreamName string) {
 		LogStreamName: aws.String(logStreamName),
 	})
 	if err != nil && !errors.As(err, &rnf) {
-		log.Printf("Error occurred while deleting log stream %s: %v", logStreamName, err)
+		logger.Errorf("Error occurred while deleting log stream %s: %v", logStreamName, err)
 	}
 }
 
 // DeleteLogGroup cleans up log group by name
 func DeleteLogGroup(logGroupName string) {
+	InvalidateLogGroup(logGroupName)
 	_, err := CwlClient.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{
 		LogGroupName: aws.String(logGroupName),
 	})
 	if err != nil && !errors.As(err, &rnf) {
-		log.Printf("Error occurred while deleting log group %s: %v", logGroupName, err)
+		logger.Errorf("Error occurred while deleting log group %s: %v", logGroupName, err)
+	}
+}
+
+// CreateLogGroup creates the log group, encrypted with the KMS key when kmsKeyArn is not empty, and tags it for the run
+func CreateLogGroup(logGroupName, kmsKeyArn string) error {
+	input := &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+		Tags:         GetRunTags(),
+	}
+	if kmsKeyArn != "" {
+		input.KmsKeyId = aws.String(kmsKeyArn)
+	}
+	if _, err := CwlClient.CreateLogGroup(ctx, input); err != nil {
+		return err
 	}
+	taggedLogGroupsMu.Lock()
+	defer taggedLogGroupsMu.Unlock()
+	recordTaggedLogGroup(logGroupName)
+	return nil
+}
+
+// PutLogEvent puts the message to the log stream, creating the log group and stream when they do not exist. Unlike
+// CreateLogGroup, the log group is not tagged for the run so the sweeper keeps it.
+func PutLogEvent(logGroupName, logStreamName, message string, timestamp time.Time) error {
+	var exists *types.ResourceAlreadyExistsException
+	if _, err := CwlClient.CreateLogGroup(ctx, &cloudwatchlogs.CreateLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+	}); err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("failed to create log group %s: %w", logGroupName, err)
+	}
+	if _, err := CwlClient.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(logGroupName),
+		LogStreamName: aws.String(logStreamName),
+	}); err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("failed to create log stream %s of %s: %w", logStreamName, logGroupName, err)
+	}
+	_, err := CwlClient.PutLogEvents(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(logGroupName),
+		LogStreamName: aws.String(logStreamName),
+		LogEvents: []types.InputLogEvent{{
+			Message:   aws.String(message),
+			Timestamp: aws.Int64(timestamp.UnixMilli()),
+		}},
+	})
+	return err
+}
+
+// AssociateKmsKey encrypts the data the log group receives from now on with the KMS key
+func AssociateKmsKey(logGroupName, kmsKeyArn string) error {
+	_, err := CwlClient.AssociateKmsKey(ctx, &cloudwatchlogs.AssociateKmsKeyInput{
+		LogGroupName: aws.String(logGroupName),
+		KmsKeyId:     aws.String(kmsKeyArn),
+	})
+	return err
+}
+
+// GetLogGroupKmsKeyId returns the ARN of the KMS key the log group is encrypted with, or an empty string when the
+// group is not encrypted
+func GetLogGroupKmsKeyId(logGroupName string) (string, error) {
+	output, err := CwlClient.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(logGroupName),
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, group := range output.LogGroups {
+		if aws.ToString(group.LogGroupName) == logGroupName {
+			return aws.ToString(group.KmsKeyId), nil
+		}
+	}
+	return "", errclass.NotFoundYetf("log group %s not found", logGroupName)
+}
+
+// LogEvent is an event of a log stream as a validator sees it
+type LogEvent struct {
+	Message string
+	// Timestamp is the time the agent stamped the event with, IngestionTime the time CloudWatch Logs received it
+	Timestamp     time.Time
+	IngestionTime time.Time
+	// LogStream is the stream the event was read from, the streams of ValidateLogsInGroup differ
+	LogStream string
+}
+
+// LogValidator checks the events found in the time frame, in the order of the streams
+type LogValidator func(events []LogEvent) bool
+
+// StringValidator adapts a validator of the messages alone, for the checks which need no timestamps
+func StringValidator(validator func(logs []string) bool) LogValidator {
+	return func(events []LogEvent) bool {
+		return validator(Messages(events))
+	}
+}
+
+// Messages returns the messages of the events
+func Messages(events []LogEvent) []string {
+	messages := make([]string, len(events))
+	for i, e := range events {
+		messages[i] = e.Message
+	}
+	return messages
 }
 
 // ValidateLogs queries a given LogGroup/LogStream combination given the start and end times, and executes an
 // arbitrary validator function on the found logs.
-func ValidateLogs(logGroup, logStream string, since, until *time.Time, validator func(logs []string) bool) (bool, error) {
-	log.Printf("Checking %s/%s\n", logGroup, logStream)
+func ValidateLogs(logGroup, logStream string, since, until *time.Time, validator LogValidator) (bool, error) {
+	return ValidateLogsWithClient(CwlClient, logGroup, logStream, since, until, validator)
+}
+
+// ValidateLogsWithClient is ValidateLogs with an injected client, e.g. a mock from util/awsservice/mocks
+func ValidateLogsWithClient(client CloudWatchLogsAPI, logGroup, logStream string, since, until *time.Time, validator LogValidator) (bool, error) {
+	logger.With(logger.Fields{"log_group": logGroup, "log_stream": logStream}).Infof("Checking logs")
+
+	foundLogs, err := getLogsSince(client, logGroup, logStream, since, until)
+	if err != nil {
+		return false, err
+	}
+	return validator(foundLogs), nil
+}
 
-	foundLogs, err := getLogsSince(logGroup, logStream, since, until)
+// ValidateLogsInGroup is ValidateLogs across every log stream of the group whose name starts with streamPrefix, for
+// stream names that are only known by prefix, e.g. one stream per pod or per date. The validator runs once on the logs
+// of all the matching streams.
+func ValidateLogsInGroup(logGroup, streamPrefix string, since, until *time.Time, validator LogValidator) (bool, error) {
+	return ValidateLogsInGroupWithClient(CwlClient, logGroup, streamPrefix, since, until, validator)
+}
+
+// ValidateLogsInGroupWithClient is ValidateLogsInGroup with an injected client, e.g. a mock from util/awsservice/mocks
+func ValidateLogsInGroupWithClient(client CloudWatchLogsAPI, logGroup, streamPrefix string, since, until *time.Time, validator LogValidator) (bool, error) {
+	streams, err := getLogStreamNames(client, logGroup, streamPrefix)
 	if err != nil {
 		return false, err
 	}
+	logger.With(logger.Fields{"log_group": logGroup, "log_stream_prefix": streamPrefix}).Infof("Checking logs of %d streams", len(streams))
 
+	var foundLogs []LogEvent
+	for _, stream := range streams {
+		logs, err := getLogsSince(client, logGroup, stream, since, until)
+		if err != nil {
+			return false, err
+		}
+		foundLogs = append(foundLogs, logs...)
+	}
 	return validator(foundLogs), nil
 }
 
-// getLogsSince makes GetLogEvents API calls, paginates through the results for the given time frame, and returns
-// the raw log strings
-func getLogsSince(logGroup, logStream string, since, until *time.Time) ([]string, error) {
-	foundLogs := make([]string, 0)
+// AssertLogCount fails unless the log stream has the expected number of events in the time frame, within the relative
+// tolerance, e.g. 0.05 for 5%. Too few events is a NotFoundYetError, the rest may still be delivered.
+func AssertLogCount(logGroup, logStream string, since, until time.Time, expected int, tolerance float64) error {
+	return AssertLogCountWithClient(CwlClient, logGroup, logStream, since, until, expected, tolerance)
+}
+
+// AssertLogCountWithClient is AssertLogCount with an injected client, e.g. a mock from util/awsservice/mocks
+func AssertLogCountWithClient(client CloudWatchLogsAPI, logGroup, logStream string, since, until time.Time, expected int, tolerance float64) error {
+	count, err := countLogEvents(client, logGroup, logStream, since, until)
+	if err != nil {
+		return err
+	}
+	return checkLogVolume(fmt.Sprintf("log stream %s of log group %s has %d events", logStream, logGroup, count), float64(count), float64(expected), tolerance)
+}
 
+// AssertLogRate fails unless the log stream has the expected events per second over the time frame, within the
+// relative tolerance. Too low a rate is a NotFoundYetError, the rest may still be delivered.
+func AssertLogRate(logGroup, logStream string, since, until time.Time, perSecond, tolerance float64) error {
+	return AssertLogRateWithClient(CwlClient, logGroup, logStream, since, until, perSecond, tolerance)
+}
+
+// AssertLogRateWithClient is AssertLogRate with an injected client, e.g. a mock from util/awsservice/mocks
+func AssertLogRateWithClient(client CloudWatchLogsAPI, logGroup, logStream string, since, until time.Time, perSecond, tolerance float64) error {
+	window := until.Sub(since).Seconds()
+	if window <= 0 {
+		return fmt.Errorf("the time frame from %s to %s is empty", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+	count, err := countLogEvents(client, logGroup, logStream, since, until)
+	if err != nil {
+		return err
+	}
+	rate := float64(count) / window
+	return checkLogVolume(fmt.Sprintf("log stream %s of log group %s has %.2f events/s", logStream, logGroup, rate), rate, perSecond, tolerance)
+}
+
+// countLogEvents counts the events one page at a time, without holding them
+func countLogEvents(client CloudWatchLogsAPI, logGroup, logStream string, since, until time.Time) (int, error) {
+	count := 0
+	err := ForEachLogEventWithClient(client, logGroup, logStream, &since, &until, func(types.OutputLogEvent) bool {
+		count++
+		return true
+	})
+	return count, err
+}
+
+func checkLogVolume(found string, actual, expected, tolerance float64) error {
+	low, high := expected*(1-tolerance), expected*(1+tolerance)
+	if actual < low {
+		return errclass.NotFoundYetf("%s, expected at least %.2f", found, low)
+	}
+	if actual > high {
+		return errclass.Validationf("%s, expected at most %.2f", found, high)
+	}
+	return nil
+}
+
+// getLogStreamNames pages through the log streams of the group with the prefix, waiting up to
+// logEventsNotFoundTimeout for the group and at least one matching stream to be created
+func getLogStreamNames(client CloudWatchLogsAPI, logGroup, streamPrefix string) ([]string, error) {
+	var names []string
+	description := fmt.Sprintf("log streams with prefix %s in log group %s", streamPrefix, logGroup)
+	err := await.WaitUntil(ctx, 30*time.Second, logEventsNotFoundTimeout, description, func() (bool, error) {
+		names = nil
+		params := &cloudwatchlogs.DescribeLogStreamsInput{LogGroupName: aws.String(logGroup)}
+		if streamPrefix != "" {
+			params.LogStreamNamePrefix = aws.String(streamPrefix)
+		}
+		for {
+			output, err := client.DescribeLogStreams(ctx, params)
+			if errors.As(err, &rnf) {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			for _, s := range output.LogStreams {
+				names = append(names, aws.ToString(s.LogStreamName))
+			}
+			if output.NextToken == nil {
+				return len(names) > 0, nil
+			}
+			params.NextToken = output.NextToken
+		}
+	})
+	return names, err
+}
+
+// GetLogEvents returns the events of the log stream in the time frame, with their timestamp and ingestion time
+func GetLogEvents(logGroup, logStream string, since, until *time.Time) ([]types.OutputLogEvent, error) {
+	return getLogEventsSince(CwlClient, logGroup, logStream, since, until)
+}
+
+// getLogsSince returns the events of getLogEventsSince for the validators
+func getLogsSince(client CloudWatchLogsAPI, logGroup, logStream string, since, until *time.Time) ([]LogEvent, error) {
+	events, err := getLogEventsSince(client, logGroup, logStream, since, until)
+	foundLogs := make([]LogEvent, 0, len(events))
+	for _, e := range events {
+		foundLogs = append(foundLogs, LogEvent{
+			Message:       aws.ToString(e.Message),
+			Timestamp:     time.UnixMilli(aws.ToInt64(e.Timestamp)),
+			IngestionTime: time.UnixMilli(aws.ToInt64(e.IngestionTime)),
+			LogStream:     logStream,
+		})
+	}
+	return foundLogs, err
+}
+
+// getLogEventsSince collects the events of ForEachLogEventWithClient
+func getLogEventsSince(client CloudWatchLogsAPI, logGroup, logStream string, since, until *time.Time) ([]types.OutputLogEvent, error) {
+	foundEvents := make([]types.OutputLogEvent, 0)
+	err := ForEachLogEventWithClient(client, logGroup, logStream, since, until, func(e types.OutputLogEvent) bool {
+		foundEvents = append(foundEvents, e)
+		return true
+	})
+	return foundEvents, err
+}
+
+// ForEachLogEvent calls fn with each event of the log stream in the time frame, one page at a time so high-volume
+// streams are never held in memory. Paging stops as soon as fn returns false.
+func ForEachLogEvent(logGroup, logStream string, since, until *time.Time, fn func(types.OutputLogEvent) bool) error {
+	return ForEachLogEventWithClient(CwlClient, logGroup, logStream, since, until, fn)
+}
+
+// ForEachLogEventWithClient is ForEachLogEvent with an injected client, e.g. a mock from util/awsservice/mocks
+func ForEachLogEventWithClient(client CloudWatchLogsAPI, logGroup, logStream string, since, until *time.Time, fn func(types.OutputLogEvent) bool) error {
 	// https://docs.aws.amazon.com/AmazonCloudWatchLogs/latest/APIReference/API_GetLogEvents.html
 	// GetLogEvents can return an empty result while still having more log events on a subsequent page,
 	// so rather than expecting all the events to show up in one GetLogEvents API call, we need to paginate.
@@ -76,53 +335,71 @@ func getLogsSince(logGroup, logStream string, since, until *time.Time) ([]string
 		StartFromHead: aws.Bool(true), // read from the beginning
 	}
 
+	// the window is widened by the skew tolerance, the timestamps of the events may come from a drifting host clock
 	if since != nil {
-		params.StartTime = aws.Int64(since.UnixMilli())
+		params.StartTime = aws.Int64(clock.Since(*since).UnixMilli())
 	}
 
 	if until != nil {
-		params.EndTime = aws.Int64(until.UnixMilli())
+		params.EndTime = aws.Int64(clock.Until(*until).UnixMilli())
 	}
 
-	var nextToken *string
-	attempts := 0
+	l := logger.With(logger.Fields{"log_group": logGroup, "log_stream": logStream})
+	output, err := getFirstLogEventsPage(client, params)
+	if err != nil {
+		return err
+	}
 
+	count := 0
 	for {
-		if nextToken != nil {
-			params.NextToken = nextToken
-		}
-		output, err := CwlClient.GetLogEvents(ctx, params)
-
-		attempts += 1
-
-		if err != nil {
-			if errors.As(err, &rnf) && attempts <= StandardRetries {
-				// The log group/stream hasn't been created yet, so wait and retry
-				time.Sleep(30 * time.Second)
-				continue
-			}
-
-			// if the error is not a ResourceNotFoundException, we should fail here.
-			return foundLogs, err
-		}
-
 		for _, e := range output.Events {
-			foundLogs = append(foundLogs, *e.Message)
+			count++
+			if !fn(e) {
+				l.Debugf("Stopped paginating log events after %d logs", count)
+				return nil
+			}
 		}
 
+		nextToken := params.NextToken
 		if nextToken != nil && output.NextForwardToken != nil && *output.NextForwardToken == *nextToken {
 			// From the docs: If you have reached the end of the stream, it returns the same token you passed in.
-			log.Printf("Done paginating log events for %s/%s and found %d logs", logGroup, logStream, len(foundLogs))
-			break
+			l.Debugf("Done paginating log events and found %d logs", count)
+			return nil
 		}
+		params.NextToken = output.NextForwardToken
 
-		nextToken = output.NextForwardToken
+		// The stream exists once the first page was read, so any error of a later page fails the read rather than
+		// being retried as a stream that was not created yet.
+		if output, err = client.GetLogEvents(ctx, params); err != nil {
+			return err
+		}
 	}
-	return foundLogs, nil
 }
 
-// IsLogGroupExists confirms whether the logGroupName exists or not
+// getFirstLogEventsPage reads the first page, waiting up to logEventsNotFoundTimeout for the log group and stream to
+// be created
+func getFirstLogEventsPage(client CloudWatchLogsAPI, params *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	var output *cloudwatchlogs.GetLogEventsOutput
+	description := fmt.Sprintf("log stream %s in log group %s", *params.LogStreamName, *params.LogGroupName)
+	err := await.WaitUntil(ctx, 30*time.Second, logEventsNotFoundTimeout, description, func() (bool, error) {
+		var err error
+		output, err = client.GetLogEvents(ctx, params)
+		if errors.As(err, &rnf) {
+			// The log group/stream hasn't been created yet, so wait and retry
+			return false, nil
+		}
+		// if the error is not a ResourceNotFoundException, we should fail here.
+		return err == nil, err
+	})
+	return output, err
+}
+
+// IsLogGroupExists confirms whether the logGroupName exists or not. An existing log group is cached for CacheTTL.
 func IsLogGroupExists(logGroupName string) bool {
+	if exists, ok := logGroupCache.get(logGroupName); ok {
+		return exists
+	}
+
 	describeLogGroupInput := cloudwatchlogs.DescribeLogGroupsInput{
 		LogGroupNamePrefix: aws.String(logGroupName),
 	}
@@ -130,15 +407,20 @@ func IsLogGroupExists(logGroupName string) bool {
 	describeLogGroupOutput, err := CwlClient.DescribeLogGroups(ctx, &describeLogGroupInput)
 
 	if err != nil {
-		log.Println("error occurred while calling DescribeLogGroups", err)
+		logger.Errorf("error occurred while calling DescribeLogGroups: %v", err)
 		return false
 	}
 
-	return len(describeLogGroupOutput.LogGroups) > 0
+	exists := len(describeLogGroupOutput.LogGroups) > 0
+	if exists {
+		logGroupCache.set(logGroupName, true)
+	}
+	return exists
 }
 
 func GetLogStreams(logGroupName string) []types.LogStream {
-	for i := 0; i < logStreamRetry; i++ {
+	streams := []types.LogStream{}
+	err := await.WaitUntil(ctx, 10*time.Second, logStreamRetry*10*time.Second, "log streams in log group "+logGroupName, func() (bool, error) {
 		describeLogStreamsOutput, err := CwlClient.DescribeLogStreams(ctx, &cloudwatchlogs.DescribeLogStreamsInput{
 			LogGroupName: aws.String(logGroupName),
 			OrderBy:      types.OrderByLastEventTime,
@@ -147,27 +429,30 @@ func GetLogStreams(logGroupName string) []types.LogStream {
 		})
 
 		if err != nil {
-			log.Printf("failed to get log streams for log group: %v - err: %v", logGroupName, err)
-			continue
+			logger.Errorf("failed to get log streams for log group: %v - err: %v", logGroupName, err)
+			return false, nil
 		}
 
 		if len(describeLogStreamsOutput.LogStreams) > 0 {
-			return describeLogStreamsOutput.LogStreams
+			streams = describeLogStreamsOutput.LogStreams
+			return true, nil
 		}
-
-		time.Sleep(10 * time.Second)
+		return false, nil
+	})
+	if err != nil {
+		logger.Warnf("%v", err)
 	}
 
-	return []types.LogStream{}
+	return streams
 }
 
 func MatchEMFLogWithSchema(logEntry string, s *jsonschema.Schema, logValidator func(string) bool) bool {
 	keyErrors, e := s.ValidateBytes(context.Background(), []byte(logEntry))
 	if e != nil {
-		log.Println("failed to execute schema validator:", e)
+		logger.Errorf("failed to execute schema validator: %v", e)
 		return false
 	} else if len(keyErrors) > 0 {
-		log.Printf("failed schema validation: %v\n", keyErrors)
+		logger.Errorf("failed schema validation: %v\n", keyErrors)
 		return false
 	}
 
@@ -0,0 +1,233 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func withNextToken(token *string) interface{} {
+	return mock.MatchedBy(func(in *cloudwatchlogs.GetLogEventsInput) bool {
+		return aws.ToString(in.NextToken) == aws.ToString(token)
+	})
+}
+
+func events(messages ...string) []types.OutputLogEvent {
+	out := make([]types.OutputLogEvent, len(messages))
+	for i, m := range messages {
+		out[i] = types.OutputLogEvent{Message: aws.String(m)}
+	}
+	return out
+}
+
+func TestValidateLogsWithClientPaginates(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, withNextToken(nil)).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("a", "b"),
+		NextForwardToken: aws.String("page-2"),
+	}, nil).Once()
+	// an empty page does not end the stream, only the same token being returned does
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("page-2"))).Return(&cloudwatchlogs.GetLogEventsOutput{
+		NextForwardToken: aws.String("page-3"),
+	}, nil).Once()
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("page-3"))).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("c"),
+		NextForwardToken: aws.String("page-3"),
+	}, nil).Once()
+
+	var found []string
+	ok, err := awsservice.ValidateLogsWithClient(client, "group", "stream", nil, nil, awsservice.StringValidator(func(logs []string) bool {
+		found = logs
+		return len(logs) == 3
+	}))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b", "c"}, found)
+	client.AssertExpectations(t)
+}
+
+func TestValidateLogsWithClientPassesEventMetadata(t *testing.T) {
+	stamped := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, withNextToken(nil)).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events: []types.OutputLogEvent{{
+			Message:       aws.String("a"),
+			Timestamp:     aws.Int64(stamped.UnixMilli()),
+			IngestionTime: aws.Int64(stamped.Add(1500 * time.Millisecond).UnixMilli()),
+		}},
+		NextForwardToken: aws.String("end"),
+	}, nil).Once()
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("end"))).Return(&cloudwatchlogs.GetLogEventsOutput{
+		NextForwardToken: aws.String("end"),
+	}, nil).Once()
+
+	var found []awsservice.LogEvent
+	ok, err := awsservice.ValidateLogsWithClient(client, "group", "stream", nil, nil, func(events []awsservice.LogEvent) bool {
+		found = events
+		return true
+	})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.Len(t, found, 1)
+	assert.Equal(t, "a", found[0].Message)
+	assert.Equal(t, "stream", found[0].LogStream)
+	assert.True(t, stamped.Equal(found[0].Timestamp))
+	assert.Equal(t, 1500*time.Millisecond, found[0].IngestionTime.Sub(found[0].Timestamp))
+	assert.Equal(t, []string{"a"}, awsservice.Messages(found))
+	client.AssertExpectations(t)
+}
+
+func TestValidateLogsWithClientReturnsApiError(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, mock.Anything).Return(nil, &types.InvalidParameterException{}).Once()
+
+	ok, err := awsservice.ValidateLogsWithClient(client, "group", "stream", nil, nil, awsservice.StringValidator(func(logs []string) bool {
+		t.Fatal("validator must not run when the logs could not be read")
+		return true
+	}))
+	assert.Error(t, err)
+	assert.False(t, ok)
+	client.AssertExpectations(t)
+}
+
+func TestForEachLogEventWithClientStopsEarly(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, withNextToken(nil)).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("a", "b"),
+		NextForwardToken: aws.String("page-2"),
+	}, nil).Once()
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("page-2"))).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("c", "d"),
+		NextForwardToken: aws.String("page-3"),
+	}, nil).Once()
+
+	var found []string
+	err := awsservice.ForEachLogEventWithClient(client, "group", "stream", nil, nil, func(e types.OutputLogEvent) bool {
+		found = append(found, *e.Message)
+		return *e.Message != "c"
+	})
+	require.NoError(t, err)
+	// the page after the one with "c" is never requested
+	assert.Equal(t, []string{"a", "b", "c"}, found)
+	client.AssertExpectations(t)
+}
+
+func TestForEachLogEventWithClientFailsOnLaterPageError(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, withNextToken(nil)).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("a"),
+		NextForwardToken: aws.String("page-2"),
+	}, nil).Once()
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("page-2"))).Return(nil, &types.ResourceNotFoundException{}).Once()
+
+	var found []string
+	err := awsservice.ForEachLogEventWithClient(client, "group", "stream", nil, nil, func(e types.OutputLogEvent) bool {
+		found = append(found, *e.Message)
+		return true
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a"}, found)
+	client.AssertExpectations(t)
+}
+
+func TestAssertLogCountWithClient(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("GetLogEvents", mock.Anything, withNextToken(nil)).Return(&cloudwatchlogs.GetLogEventsOutput{
+		Events:           events("a", "b", "c", "d"),
+		NextForwardToken: aws.String("end"),
+	}, nil)
+	client.On("GetLogEvents", mock.Anything, withNextToken(aws.String("end"))).Return(&cloudwatchlogs.GetLogEventsOutput{
+		NextForwardToken: aws.String("end"),
+	}, nil)
+	until := time.Now()
+	since := until.Add(-2 * time.Second)
+
+	assert.NoError(t, awsservice.AssertLogCountWithClient(client, "group", "stream", since, until, 4, 0))
+	assert.NoError(t, awsservice.AssertLogCountWithClient(client, "group", "stream", since, until, 5, 0.2))
+	err := awsservice.AssertLogCountWithClient(client, "group", "stream", since, until, 5, 0.1)
+	assert.Equal(t, errclass.NotFoundYet, errclass.Classify(err), "fewer events may still be delivered: %v", err)
+	err = awsservice.AssertLogCountWithClient(client, "group", "stream", since, until, 3, 0.1)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err), "more events are never fixed by waiting: %v", err)
+
+	assert.NoError(t, awsservice.AssertLogRateWithClient(client, "group", "stream", since, until, 2, 0))
+	assert.Error(t, awsservice.AssertLogRateWithClient(client, "group", "stream", since, until, 1, 0.5))
+	assert.Error(t, awsservice.AssertLogRateWithClient(client, "group", "stream", until, until, 1, 0.5))
+}
+
+func TestValidateLogsInGroupWithClientReadsEveryMatchingStream(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	client.On("DescribeLogStreams", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.DescribeLogStreamsInput) bool {
+		return aws.ToString(in.LogStreamNamePrefix) == "pod-" && in.NextToken == nil
+	})).Return(&cloudwatchlogs.DescribeLogStreamsOutput{
+		LogStreams: []types.LogStream{{LogStreamName: aws.String("pod-a")}},
+		NextToken:  aws.String("streams-2"),
+	}, nil).Once()
+	client.On("DescribeLogStreams", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.DescribeLogStreamsInput) bool {
+		return aws.ToString(in.NextToken) == "streams-2"
+	})).Return(&cloudwatchlogs.DescribeLogStreamsOutput{
+		LogStreams: []types.LogStream{{LogStreamName: aws.String("pod-b")}},
+	}, nil).Once()
+	for stream, messages := range map[string][]string{"pod-a": {"a"}, "pod-b": {"b", "c"}} {
+		stream := stream
+		client.On("GetLogEvents", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.GetLogEventsInput) bool {
+			return aws.ToString(in.LogStreamName) == stream && in.NextToken == nil
+		})).Return(&cloudwatchlogs.GetLogEventsOutput{
+			Events:           events(messages...),
+			NextForwardToken: aws.String("end"),
+		}, nil).Once()
+		client.On("GetLogEvents", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.GetLogEventsInput) bool {
+			return aws.ToString(in.LogStreamName) == stream && aws.ToString(in.NextToken) == "end"
+		})).Return(&cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("end")}, nil).Once()
+	}
+
+	var found []string
+	ok, err := awsservice.ValidateLogsInGroupWithClient(client, "group", "pod-", nil, nil, awsservice.StringValidator(func(logs []string) bool {
+		found = logs
+		return len(logs) == 3
+	}))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"a", "b", "c"}, found)
+	client.AssertExpectations(t)
+}
+
+// TestDeleteLogGroupsTaggedAfterDeletesAgentCreatedGroups is the retry of a runner whose failed attempt wrote to a
+// group the agent created, and to one it never wrote to
+func TestDeleteLogGroupsTaggedAfterDeletesAgentCreatedGroups(t *testing.T) {
+	client := &mocks.CloudWatchLogsMock{}
+	original := awsservice.CwlClient
+	awsservice.CwlClient = client
+	defer func() { awsservice.CwlClient = original }()
+
+	client.On("TagLogGroup", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.TagLogGroupInput) bool {
+		return aws.ToString(in.LogGroupName) == "i-0123Glob" && in.Tags[awsservice.RunIdTagKey] != ""
+	})).Return(&cloudwatchlogs.TagLogGroupOutput{}, nil).Once()
+	client.On("TagLogGroup", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.TagLogGroupInput) bool {
+		return aws.ToString(in.LogGroupName) == "i-0123Unused"
+	})).Return(nil, &types.ResourceNotFoundException{}).Once()
+	client.On("DeleteLogGroup", mock.Anything, mock.MatchedBy(func(in *cloudwatchlogs.DeleteLogGroupInput) bool {
+		return aws.ToString(in.LogGroupName) == "i-0123Glob"
+	})).Return(&cloudwatchlogs.DeleteLogGroupOutput{}, nil).Once()
+
+	count := awsservice.TaggedLogGroupCount()
+	awsservice.TagLogGroupForRun("i-0123Glob")
+	awsservice.TagLogGroupForRun("i-0123Unused")
+	assert.Equal(t, []string{"i-0123Glob"}, awsservice.LogGroupsTaggedAfter(count))
+
+	awsservice.DeleteLogGroupsTaggedAfter(count)
+	assert.Empty(t, awsservice.LogGroupsTaggedAfter(count))
+	client.AssertExpectations(t)
+}
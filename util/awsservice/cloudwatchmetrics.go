@@ -4,15 +4,16 @@
 package awsservice
 
 import (
-	"errors"
 	"fmt"
-	"log"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 const (
@@ -34,14 +35,14 @@ func ValidateMetric(metricName, namespace string, dimensionsFilter []types.Dimen
 		RecentlyActive: "PT3H",
 		Dimensions:     dimensionsFilter,
 	}
-	data, err := CwmClient.ListMetrics(ctx, &listMetricsInput)
+	metrics, err := ListMetrics(&listMetricsInput)
 	if err != nil {
-		return errors.New(fmt.Sprintf("Error getting metric data %v", err))
+		return fmt.Errorf("Error getting metric data %w", err)
 	}
 
 	// Only validate if certain metrics are published by CloudWatchAgent in corresponding namespace
 	// Since the metric value can be unpredictive.
-	if len(data.Metrics) == 0 {
+	if len(metrics) == 0 {
 		dims := make([]metric, len(dimensionsFilter))
 		for i, filter := range dimensionsFilter {
 			dims[i] = metric{
@@ -49,8 +50,8 @@ func ValidateMetric(metricName, namespace string, dimensionsFilter []types.Dimen
 				value: *filter.Value,
 			}
 		}
-		return errors.New(fmt.Sprintf("No metrics found for dimension %v metric name %v namespace %v",
-			dims, metricName, namespace))
+		return errclass.NotFoundYetf("No metrics found for dimension %v metric name %v namespace %v",
+			dims, metricName, namespace)
 	}
 
 	return nil
@@ -88,7 +89,7 @@ func ValidateSampleCount(metricName, namespace string, dimensions []types.Dimens
 		dataPoints = dataPoints + int(*datapoint.SampleCount)
 	}
 
-	log.Printf("Number of datapoints for start time %v with endtime %v and period %d is %d is inclusive between %d and %d", startTime, endTime, periodInSeconds, dataPoints, lowerBoundInclusive, upperBoundInclusive)
+	logger.Infof("Number of datapoints for start time %v with endtime %v and period %d is %d is inclusive between %d and %d", startTime, endTime, periodInSeconds, dataPoints, lowerBoundInclusive, upperBoundInclusive)
 
 	if lowerBoundInclusive <= dataPoints && dataPoints <= upperBoundInclusive {
 		return true
@@ -132,6 +133,25 @@ func BuildDimensionFilterList(appendDimension int) []types.DimensionFilter {
 	return dimensionFilter
 }
 
+// PutMetricData sends the metrics to CloudWatch, in batches of the maximum number of metrics per request
+func PutMetricData(namespace string, data []types.MetricDatum) error {
+	const maxMetricsPerRequest = 20
+	for start := 0; start < len(data); start += maxMetricsPerRequest {
+		end := start + maxMetricsPerRequest
+		if end > len(data) {
+			end = len(data)
+		}
+		_, err := CwmClient.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(namespace),
+			MetricData: data[start:end],
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReportMetric sends a single metric to CloudWatch.
 // Does not support sending dimensions.
 func ReportMetric(namespace string,
@@ -8,15 +8,18 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	backoff "github.com/cenkalti/backoff/v4"
 )
 
@@ -37,15 +40,31 @@ var (
 )
 
 var (
-	ctx                  = context.Background()
-	awsCfg, _            = config.LoadDefaultConfig(ctx)
-	Ec2Client            = ec2.NewFromConfig(awsCfg)
-	EcsClient            = ecs.NewFromConfig(awsCfg)
-	SsmClient            = ssm.NewFromConfig(awsCfg)
-	ImdsClient           = imds.NewFromConfig(awsCfg)
-	CwmClient            = cloudwatch.NewFromConfig(awsCfg)
-	CwlClient            = cloudwatchlogs.NewFromConfig(awsCfg)
-	DynamodbClient       = dynamodb.NewFromConfig(awsCfg)
-	S3Client             = s3.NewFromConfig(awsCfg)
-	CloudformationClient = cloudformation.NewFromConfig(awsCfg)
+	ctx                                    = context.Background()
+	awsCfg, _                              = loadConfig()
+	Ec2Client            EC2API            = ec2.NewFromConfig(awsCfg)
+	EcsClient            ECSAPI            = ecs.NewFromConfig(awsCfg)
+	SsmClient            SSMAPI            = ssm.NewFromConfig(awsCfg)
+	ImdsClient           IMDSAPI           = imds.NewFromConfig(awsCfg)
+	CwmClient            CloudWatchAPI     = cloudwatch.NewFromConfig(awsCfg)
+	CwlClient            CloudWatchLogsAPI = cloudwatchlogs.NewFromConfig(awsCfg)
+	DynamodbClient       DynamoDBAPI       = dynamodb.NewFromConfig(awsCfg)
+	FirehoseClient       FirehoseAPI       = firehose.NewFromConfig(awsCfg)
+	S3Client             S3API             = s3.NewFromConfig(awsCfg, withPathStyle)
+	SnsClient            SNSAPI            = sns.NewFromConfig(awsCfg)
+	StsClient            STSAPI            = sts.NewFromConfig(awsCfg)
+	CloudformationClient                   = cloudformation.NewFromConfig(awsCfg)
 )
+
+// NewCloudWatchClient is a CloudWatch client for another region than the one of the tests, e.g. to validate the agent
+// publishes to the region it was configured with
+func NewCloudWatchClient(region string) CloudWatchAPI {
+	return cloudwatch.NewFromConfig(awsCfg, func(o *cloudwatch.Options) {
+		o.Region = region
+	})
+}
+
+// GetCredentials retrieves the credentials the tests run with, the instance role on EC2
+func GetCredentials() (aws.Credentials, error) {
+	return awsCfg.Credentials.Retrieve(ctx)
+}
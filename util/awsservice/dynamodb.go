@@ -5,6 +5,8 @@ package awsservice
 
 import (
 	"errors"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
@@ -93,3 +95,155 @@ func GetItemInDatabase(databaseName, indexName string, checkingAttribute, checki
 
 	return packets[0], nil
 }
+
+// ResultHistoryTable is the table the result of every suite run is recorded in, see terraform/setup
+const ResultHistoryTable = "CWATestResultHistory"
+
+// RunResult is a run of a suite in the result history table. The table is partitioned by the agent version and sorted
+// by the time the run started, so the runs of a version can be queried in order for trend reporting.
+type RunResult struct {
+	AgentVersion string `dynamodbav:"AgentVersion"`
+	// Timestamp is the start of the run in unix milliseconds
+	Timestamp       int64              `dynamodbav:"Timestamp"`
+	RunId           string             `dynamodbav:"RunId"`
+	Suite           string             `dynamodbav:"Suite"`
+	Passed          bool               `dynamodbav:"Passed"`
+	DurationSeconds float64            `dynamodbav:"DurationSeconds"`
+	Failures        []string           `dynamodbav:"Failures,omitempty"`
+	Metrics         map[string]float64 `dynamodbav:"Metrics,omitempty"`
+}
+
+// Time returns the start of the run
+func (r RunResult) Time() time.Time {
+	return time.UnixMilli(r.Timestamp)
+}
+
+// PutRunResult records the result in the history table, replacing the one of the same version started at the same time
+func PutRunResult(tableName string, result RunResult) error {
+	if result.AgentVersion == "" {
+		return errors.New("the agent version of a run result is required")
+	}
+	item, err := attributevalue.MarshalMap(result)
+	if err != nil {
+		return err
+	}
+	_, err = DynamodbClient.PutItem(ctx, &dynamodb.PutItemInput{
+		Item:      item,
+		TableName: aws.String(tableName),
+	})
+	return err
+}
+
+// QueryRunResults returns the results of the agent version started in [since, until], oldest first
+func QueryRunResults(tableName, agentVersion string, since, until time.Time) ([]RunResult, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("#version = :version and #timestamp between :since and :until"),
+		ExpressionAttributeNames: map[string]string{
+			"#version":   "AgentVersion",
+			"#timestamp": "Timestamp",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":version": &types.AttributeValueMemberS{Value: agentVersion},
+			":since":   &types.AttributeValueMemberN{Value: strconv.FormatInt(since.UnixMilli(), 10)},
+			":until":   &types.AttributeValueMemberN{Value: strconv.FormatInt(until.UnixMilli(), 10)},
+		},
+		ScanIndexForward: aws.Bool(true),
+	}
+
+	var results []RunResult
+	for {
+		output, err := DynamodbClient.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		var page []RunResult
+		if err = attributevalue.UnmarshalListOfMaps(output.Items, &page); err != nil {
+			return nil, err
+		}
+		results = append(results, page...)
+		if len(output.LastEvaluatedKey) == 0 {
+			return results, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+}
+
+// SuiteLockTable is the table the exclusive suites lock, see terraform/setup
+const SuiteLockTable = "CWATestSuiteLock"
+
+// ErrSuiteLockHeld is returned when another owner holds a lock that has not expired
+var ErrSuiteLockHeld = errors.New("the suite lock is held by another owner")
+
+// SuiteLock is a lock in the suite lock table. A lock is held until it is released or it expires, so a run that
+// aborted without releasing its locks only blocks the others until ExpiresAt.
+type SuiteLock struct {
+	LockName string `dynamodbav:"LockName"`
+	Owner    string `dynamodbav:"Owner"`
+	// ExpiresAt is in unix seconds, the table deletes expired locks with it as the time to live attribute
+	ExpiresAt int64 `dynamodbav:"ExpiresAt"`
+}
+
+// AcquireSuiteLock writes the lock unless another owner holds it and it has not expired, which returns
+// ErrSuiteLockHeld. The owner of the lock extends its expiry by acquiring it again.
+func AcquireSuiteLock(tableName string, lock SuiteLock) error {
+	item, err := attributevalue.MarshalMap(lock)
+	if err != nil {
+		return err
+	}
+	_, err = DynamodbClient.PutItem(ctx, &dynamodb.PutItemInput{
+		Item:                item,
+		TableName:           aws.String(tableName),
+		ConditionExpression: aws.String("attribute_not_exists(#name) or #owner = :owner or #expires < :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#name":    "LockName",
+			"#owner":   "Owner",
+			"#expires": "ExpiresAt",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: lock.Owner},
+			":now":   &types.AttributeValueMemberN{Value: strconv.FormatInt(time.Now().Unix(), 10)},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrSuiteLockHeld
+	}
+	return err
+}
+
+// GetSuiteLock returns the lock, false when nobody holds it
+func GetSuiteLock(tableName, lockName string) (SuiteLock, bool, error) {
+	var lock SuiteLock
+	output, err := DynamodbClient.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            map[string]types.AttributeValue{"LockName": &types.AttributeValueMemberS{Value: lockName}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || len(output.Item) == 0 {
+		return lock, false, err
+	}
+	err = attributevalue.UnmarshalMap(output.Item, &lock)
+	return lock, err == nil, err
+}
+
+// ReleaseSuiteLock deletes the lock if the owner still holds it. A lock another owner took over after it expired is
+// left alone.
+func ReleaseSuiteLock(tableName, lockName, owner string) error {
+	_, err := DynamodbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(tableName),
+		Key:                 map[string]types.AttributeValue{"LockName": &types.AttributeValueMemberS{Value: lockName}},
+		ConditionExpression: aws.String("#owner = :owner"),
+		ExpressionAttributeNames: map[string]string{
+			"#owner": "Owner",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: owner},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
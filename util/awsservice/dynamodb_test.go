@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func TestPutRunResult(t *testing.T) {
+	client := &mocks.DynamoDBMock{}
+	original := awsservice.DynamodbClient
+	awsservice.DynamodbClient = client
+	defer func() { awsservice.DynamodbClient = original }()
+
+	result := awsservice.RunResult{AgentVersion: "1.300032.0", Timestamp: 1700000000000, Suite: "ca_bundle", Passed: true}
+	client.On("PutItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.PutItemInput) bool {
+		var got awsservice.RunResult
+		require.NoError(t, attributevalue.UnmarshalMap(in.Item, &got))
+		return *in.TableName == "history" && assert.ObjectsAreEqual(result, got)
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+
+	require.NoError(t, awsservice.PutRunResult("history", result))
+	assert.Error(t, awsservice.PutRunResult("history", awsservice.RunResult{Suite: "ca_bundle"}))
+	client.AssertExpectations(t)
+}
+
+func TestQueryRunResultsPages(t *testing.T) {
+	client := &mocks.DynamoDBMock{}
+	original := awsservice.DynamodbClient
+	awsservice.DynamodbClient = client
+	defer func() { awsservice.DynamodbClient = original }()
+
+	item := func(r awsservice.RunResult) map[string]types.AttributeValue {
+		m, err := attributevalue.MarshalMap(r)
+		require.NoError(t, err)
+		return m
+	}
+	first := awsservice.RunResult{AgentVersion: "v", Timestamp: 1, Suite: "a", Passed: true}
+	second := awsservice.RunResult{AgentVersion: "v", Timestamp: 2, Suite: "b", Failures: []string{"--- FAIL: TestB"}}
+	lastKey := map[string]types.AttributeValue{"AgentVersion": &types.AttributeValueMemberS{Value: "v"}}
+
+	client.On("Query", mock.Anything, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{
+		Items:            []map[string]types.AttributeValue{item(first)},
+		LastEvaluatedKey: lastKey,
+	}, nil).Once()
+	client.On("Query", mock.Anything, mock.MatchedBy(func(in *dynamodb.QueryInput) bool {
+		return in.ExclusiveStartKey != nil
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{item(second)},
+	}, nil).Once()
+
+	results, err := awsservice.QueryRunResults("history", "v", time.UnixMilli(0), time.UnixMilli(10))
+	require.NoError(t, err)
+	assert.Equal(t, []awsservice.RunResult{first, second}, results)
+	client.AssertExpectations(t)
+}
+
+func TestAcquireSuiteLock(t *testing.T) {
+	client := &mocks.DynamoDBMock{}
+	original := awsservice.DynamodbClient
+	awsservice.DynamodbClient = client
+	defer func() { awsservice.DynamodbClient = original }()
+
+	lock := awsservice.SuiteLock{LockName: "AlarmTest", Owner: "run/host/1", ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	client.On("PutItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.PutItemInput) bool {
+		var got awsservice.SuiteLock
+		require.NoError(t, attributevalue.UnmarshalMap(in.Item, &got))
+		return *in.TableName == "locks" && got == lock && in.ConditionExpression != nil
+	})).Return(&dynamodb.PutItemOutput{}, nil).Once()
+	require.NoError(t, awsservice.AcquireSuiteLock("locks", lock))
+
+	client.On("PutItem", mock.Anything, mock.Anything).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+	assert.ErrorIs(t, awsservice.AcquireSuiteLock("locks", lock), awsservice.ErrSuiteLockHeld)
+	client.AssertExpectations(t)
+}
+
+func TestReleaseSuiteLockTakenOver(t *testing.T) {
+	client := &mocks.DynamoDBMock{}
+	original := awsservice.DynamodbClient
+	awsservice.DynamodbClient = client
+	defer func() { awsservice.DynamodbClient = original }()
+
+	client.On("DeleteItem", mock.Anything, mock.MatchedBy(func(in *dynamodb.DeleteItemInput) bool {
+		owner, ok := in.ExpressionAttributeValues[":owner"].(*types.AttributeValueMemberS)
+		return ok && owner.Value == "run/host/1"
+	})).Return(nil, &types.ConditionalCheckFailedException{}).Once()
+	assert.NoError(t, awsservice.ReleaseSuiteLock("locks", "AlarmTest", "run/host/1"))
+	client.AssertExpectations(t)
+}
@@ -4,7 +4,12 @@
 package awsservice
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 func GetInstancePrivateIpDns(instanceId string) (*string, error) {
@@ -21,3 +26,129 @@ func DescribeInstances(instanceIds []string) (*ec2.DescribeInstancesOutput, erro
 		InstanceIds: instanceIds,
 	})
 }
+
+// DescribeInstancesByFilters returns every instance matching the filters, across reservations and pages
+func DescribeInstancesByFilters(filters []types.Filter) ([]types.Instance, error) {
+	var instances []types.Instance
+	paginator := ec2.NewDescribeInstancesPaginator(Ec2Client, &ec2.DescribeInstancesInput{Filters: filters})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, reservation := range output.Reservations {
+			instances = append(instances, reservation.Instances...)
+		}
+	}
+	return instances, nil
+}
+
+// DescribeInstanceType returns the vCPUs, memory, architectures and hypervisor of the instance type
+func DescribeInstanceType(instanceType string) (types.InstanceTypeInfo, error) {
+	output, err := Ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []types.InstanceType{types.InstanceType(instanceType)},
+	})
+	if err != nil {
+		return types.InstanceTypeInfo{}, err
+	}
+	if len(output.InstanceTypes) == 0 {
+		return types.InstanceTypeInfo{}, fmt.Errorf("instance type %s not found", instanceType)
+	}
+	return output.InstanceTypes[0], nil
+}
+
+// DescribeTags returns the tags of the resource, e.g. an instance or a volume, keyed by the tag key
+func DescribeTags(resourceId string) (map[string]string, error) {
+	tags := map[string]string{}
+	paginator := ec2.NewDescribeTagsPaginator(Ec2Client, &ec2.DescribeTagsInput{
+		Filters: []types.Filter{
+			{Name: aws.String("resource-id"), Values: []string{resourceId}},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range output.Tags {
+			tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+	return tags, nil
+}
+
+// GetInstanceTag returns the value of the tag with the given key on the instance
+func GetInstanceTag(instanceId, key string) (string, error) {
+	tags, err := DescribeTags(instanceId)
+	if err != nil {
+		return "", err
+	}
+	value, ok := tags[key]
+	if !ok {
+		return "", fmt.Errorf("tag %s not found on instance %s", key, instanceId)
+	}
+	return value, nil
+}
+
+// GetRootDeviceName returns the root device name of the instance without the /dev/ prefix, e.g. xvda
+func GetRootDeviceName(instanceId string) (string, error) {
+	instanceData, err := DescribeInstances([]string{instanceId})
+	if err != nil {
+		return "", err
+	}
+	if len(instanceData.Reservations) == 0 || len(instanceData.Reservations[0].Instances) == 0 {
+		return "", fmt.Errorf("instance %s not found", instanceId)
+	}
+
+	return strings.TrimPrefix(aws.ToString(instanceData.Reservations[0].Instances[0].RootDeviceName), "/dev/"), nil
+}
+
+// DescribeVolumes returns the EBS volumes attached to the instance
+func DescribeVolumes(instanceId string) ([]types.Volume, error) {
+	var volumes []types.Volume
+	paginator := ec2.NewDescribeVolumesPaginator(Ec2Client, &ec2.DescribeVolumesInput{
+		Filters: []types.Filter{
+			{Name: aws.String("attachment.instance-id"), Values: []string{instanceId}},
+		},
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, output.Volumes...)
+	}
+	return volumes, nil
+}
+
+// GetVolumeIdsByDevice maps the device names of the EBS volumes attached to the instance, without the /dev/ prefix,
+// to their VolumeId. This is the same mapping the agent uses for the VolumeId dimension.
+func GetVolumeIdsByDevice(instanceId string) (map[string]string, error) {
+	volumes, err := DescribeVolumes(instanceId)
+	if err != nil {
+		return nil, err
+	}
+	volumeIds := map[string]string{}
+	for _, volume := range volumes {
+		for _, attachment := range volume.Attachments {
+			if aws.ToString(attachment.InstanceId) == instanceId {
+				volumeIds[strings.TrimPrefix(aws.ToString(attachment.Device), "/dev/")] = aws.ToString(volume.VolumeId)
+			}
+		}
+	}
+	return volumeIds, nil
+}
+
+// GetVolumeIdForDevice returns the VolumeId of the EBS volume attached to the instance as the given device, with or
+// without the /dev/ prefix
+func GetVolumeIdForDevice(instanceId, device string) (string, error) {
+	volumeIds, err := GetVolumeIdsByDevice(instanceId)
+	if err != nil {
+		return "", err
+	}
+	volumeId, ok := volumeIds[strings.TrimPrefix(device, "/dev/")]
+	if !ok {
+		return "", fmt.Errorf("no EBS volume attached to instance %s as %s", instanceId, device)
+	}
+	return volumeId, nil
+}
@@ -0,0 +1,141 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+func withEc2Mock(t *testing.T) *mocks.EC2Mock {
+	client := &mocks.EC2Mock{}
+	original := awsservice.Ec2Client
+	awsservice.Ec2Client = client
+	t.Cleanup(func() { awsservice.Ec2Client = original })
+	return client
+}
+
+func TestDescribeInstancesByFiltersFlattensReservationsAndPages(t *testing.T) {
+	client := withEc2Mock(t)
+	client.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(in *ec2.DescribeInstancesInput) bool {
+		return in.NextToken == nil
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{Instances: []types.Instance{{InstanceId: aws.String("i-1")}}},
+			{Instances: []types.Instance{{InstanceId: aws.String("i-2")}}},
+		},
+		NextToken: aws.String("next"),
+	}, nil).Once()
+	client.On("DescribeInstances", mock.Anything, mock.MatchedBy(func(in *ec2.DescribeInstancesInput) bool {
+		return aws.ToString(in.NextToken) == "next"
+	})).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{
+			{Instances: []types.Instance{{InstanceId: aws.String("i-3")}}},
+		},
+	}, nil).Once()
+
+	instances, err := awsservice.DescribeInstancesByFilters(nil)
+	require.NoError(t, err)
+	ids := make([]string, len(instances))
+	for i, instance := range instances {
+		ids[i] = aws.ToString(instance.InstanceId)
+	}
+	assert.Equal(t, []string{"i-1", "i-2", "i-3"}, ids)
+	client.AssertExpectations(t)
+}
+
+func TestGetInstanceTag(t *testing.T) {
+	client := withEc2Mock(t)
+	client.On("DescribeTags", mock.Anything, mock.MatchedBy(func(in *ec2.DescribeTagsInput) bool {
+		return len(in.Filters) == 1 && in.Filters[0].Values[0] == "i-1"
+	})).Return(&ec2.DescribeTagsOutput{
+		Tags: []types.TagDescription{
+			{Key: aws.String("Name"), Value: aws.String("host")},
+			{Key: aws.String("eks:cluster-name"), Value: aws.String("cluster")},
+		},
+	}, nil)
+
+	value, err := awsservice.GetInstanceTag("i-1", "eks:cluster-name")
+	require.NoError(t, err)
+	assert.Equal(t, "cluster", value)
+
+	_, err = awsservice.GetInstanceTag("i-1", "missing")
+	assert.ErrorContains(t, err, "tag missing not found")
+}
+
+func TestDescribeInstanceType(t *testing.T) {
+	client := withEc2Mock(t)
+	client.On("DescribeInstanceTypes", mock.Anything, mock.MatchedBy(func(in *ec2.DescribeInstanceTypesInput) bool {
+		return len(in.InstanceTypes) == 1 && in.InstanceTypes[0] == types.InstanceTypeM6gLarge
+	})).Return(&ec2.DescribeInstanceTypesOutput{
+		InstanceTypes: []types.InstanceTypeInfo{{
+			InstanceType: types.InstanceTypeM6gLarge,
+			MemoryInfo:   &types.MemoryInfo{SizeInMiB: aws.Int64(8192)},
+		}},
+	}, nil).Once()
+	client.On("DescribeInstanceTypes", mock.Anything, mock.Anything).Return(&ec2.DescribeInstanceTypesOutput{}, nil).Once()
+
+	info, err := awsservice.DescribeInstanceType("m6g.large")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8192), aws.ToInt64(info.MemoryInfo.SizeInMiB))
+
+	_, err = awsservice.DescribeInstanceType("x9.huge")
+	assert.ErrorContains(t, err, "instance type x9.huge not found")
+}
+
+func TestGetVolumeIdsByDevice(t *testing.T) {
+	client := withEc2Mock(t)
+	client.On("DescribeVolumes", mock.Anything, mock.Anything).Return(&ec2.DescribeVolumesOutput{
+		Volumes: []types.Volume{
+			{
+				VolumeId: aws.String("vol-1"),
+				Attachments: []types.VolumeAttachment{
+					{InstanceId: aws.String("i-1"), Device: aws.String("/dev/xvda")},
+				},
+			},
+			{
+				VolumeId: aws.String("vol-2"),
+				Attachments: []types.VolumeAttachment{
+					{InstanceId: aws.String("i-2"), Device: aws.String("/dev/xvdb")},
+				},
+			},
+		},
+	}, nil).Once()
+
+	volumeIds, err := awsservice.GetVolumeIdsByDevice("i-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"xvda": "vol-1"}, volumeIds)
+}
+
+func TestSweepInstancesOnlyTerminatesInstancesOfOldRuns(t *testing.T) {
+	client := withEc2Mock(t)
+	now := time.Now()
+	client.On("DescribeInstances", mock.Anything, mock.Anything).Return(&ec2.DescribeInstancesOutput{
+		Reservations: []types.Reservation{{Instances: []types.Instance{
+			{InstanceId: aws.String("i-old"), Tags: []types.Tag{{Key: aws.String(awsservice.RunIdTagKey), Value: aws.String(runid.New(now.Add(-48 * time.Hour)))}}},
+			// launched long ago but tagged again by a recent run
+			{InstanceId: aws.String("i-reused"), LaunchTime: aws.Time(now.Add(-48 * time.Hour)), Tags: []types.Tag{{Key: aws.String(awsservice.RunIdTagKey), Value: aws.String(runid.New(now))}}},
+			{InstanceId: aws.String("i-foreign"), Tags: []types.Tag{{Key: aws.String(awsservice.RunIdTagKey), Value: aws.String("not-a-run-id")}}},
+		}}},
+	}, nil).Once()
+	client.On("TerminateInstances", mock.Anything, mock.MatchedBy(func(in *ec2.TerminateInstancesInput) bool {
+		return assert.ObjectsAreEqual([]string{"i-old"}, in.InstanceIds)
+	})).Return(&ec2.TerminateInstancesOutput{}, nil).Once()
+
+	swept, err := awsservice.SweepInstances(6*time.Hour, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-old"}, swept)
+	client.AssertExpectations(t)
+}
@@ -4,10 +4,16 @@
 package awsservice
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
 )
 
 func RestartDaemonService(clusterArn, serviceName string) error {
@@ -89,3 +95,198 @@ func describeContainerInstances(clusterArn string, containerInstanceArns []strin
 		ContainerInstances: containerInstanceArns,
 	})
 }
+
+// EcsMetadataUriV4Env and EcsMetadataUriV3Env are set by the ECS agent in every container of a task
+const (
+	EcsMetadataUriV4Env = "ECS_CONTAINER_METADATA_URI_V4"
+	EcsMetadataUriV3Env = "ECS_CONTAINER_METADATA_URI"
+)
+
+// TaskMetadata is the task of the container the tests run in, as returned by the task metadata endpoint
+type TaskMetadata struct {
+	Cluster          string              `json:"Cluster"`
+	TaskARN          string              `json:"TaskARN"`
+	Family           string              `json:"Family"`
+	Revision         string              `json:"Revision"`
+	LaunchType       string              `json:"LaunchType"`
+	AvailabilityZone string              `json:"AvailabilityZone"`
+	Containers       []ContainerMetadata `json:"Containers"`
+}
+
+type ContainerMetadata struct {
+	Name     string `json:"Name"`
+	DockerId string `json:"DockerId"`
+	Image    string `json:"Image"`
+}
+
+// GetTaskMetadataUri returns the task metadata endpoint of the container, empty when it does not run on ECS
+func GetTaskMetadataUri() string {
+	if uri := os.Getenv(EcsMetadataUriV4Env); uri != "" {
+		return uri
+	}
+	return os.Getenv(EcsMetadataUriV3Env)
+}
+
+// GetTaskMetadata reads the metadata of the task the tests run in. The v3 endpoint only returns the name of the
+// cluster in Cluster, the v4 one its arn.
+func GetTaskMetadata(timeout time.Duration) (*TaskMetadata, error) {
+	uri := GetTaskMetadataUri()
+	if uri == "" {
+		return nil, fmt.Errorf("%s is not set, the tests do not run in an ECS task", EcsMetadataUriV4Env)
+	}
+	client := http.Client{Timeout: timeout}
+	resp, err := client.Get(uri + "/task")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d from ECS task metadata endpoint", resp.StatusCode)
+	}
+
+	var task TaskMetadata
+	if err = json.NewDecoder(resp.Body).Decode(&task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// RunTask starts a task of the task definition on the cluster with the run tags and returns its arn
+func RunTask(clusterArn, taskDefinition string, launchType types.LaunchType) (string, error) {
+	var tags []types.Tag
+	for k, v := range GetRunTags() {
+		tags = append(tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	output, err := EcsClient.RunTask(ctx, &ecs.RunTaskInput{
+		Cluster:        aws.String(clusterArn),
+		TaskDefinition: aws.String(taskDefinition),
+		LaunchType:     launchType,
+		Count:          aws.Int32(1),
+		Tags:           tags,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(output.Failures) > 0 {
+		f := output.Failures[0]
+		return "", fmt.Errorf("failed to run task %s: %s %s", taskDefinition, aws.ToString(f.Reason), aws.ToString(f.Detail))
+	}
+	if len(output.Tasks) == 0 {
+		return "", fmt.Errorf("no task of %s was started", taskDefinition)
+	}
+	return aws.ToString(output.Tasks[0].TaskArn), nil
+}
+
+// StopTask stops the task, the service scheduler replaces the tasks of a service that are stopped
+func StopTask(clusterArn, taskArn, reason string) error {
+	_, err := EcsClient.StopTask(ctx, &ecs.StopTaskInput{
+		Cluster: aws.String(clusterArn),
+		Task:    aws.String(taskArn),
+		Reason:  aws.String(reason),
+	})
+	return err
+}
+
+// ListServiceTasks returns the arns of the running tasks of the service
+func ListServiceTasks(clusterArn, serviceName string) ([]string, error) {
+	var taskArns []string
+	paginator := ecs.NewListTasksPaginator(EcsClient, &ecs.ListTasksInput{
+		Cluster:       aws.String(clusterArn),
+		ServiceName:   aws.String(serviceName),
+		DesiredStatus: types.DesiredStatusRunning,
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		taskArns = append(taskArns, output.TaskArns...)
+	}
+	return taskArns, nil
+}
+
+// DescribeTask returns the task, its LastStatus is where it is in its lifecycle
+func DescribeTask(clusterArn, taskArn string) (*types.Task, error) {
+	output, err := EcsClient.DescribeTasks(ctx, &ecs.DescribeTasksInput{
+		Cluster: aws.String(clusterArn),
+		Tasks:   []string{taskArn},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Tasks) == 0 {
+		return nil, fmt.Errorf("task %s not found in cluster %s", taskArn, clusterArn)
+	}
+	return &output.Tasks[0], nil
+}
+
+// WaitForTaskStatus polls the task until its LastStatus is the status, e.g. RUNNING or STOPPED
+func WaitForTaskStatus(clusterArn, taskArn, status string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		task, err := DescribeTask(clusterArn, taskArn)
+		if err != nil {
+			return err
+		}
+		lastStatus := aws.ToString(task.LastStatus)
+		if lastStatus == status {
+			return nil
+		}
+		if lastStatus == "STOPPED" {
+			return fmt.Errorf("task %s stopped while waiting for %s: %s", taskArn, status, aws.ToString(task.StoppedReason))
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("task %s is still %s after %s", taskArn, lastStatus, timeout)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// DescribeService returns the service of the cluster
+func DescribeService(clusterArn, serviceName string) (*types.Service, error) {
+	output, err := EcsClient.DescribeServices(ctx, &ecs.DescribeServicesInput{
+		Cluster:  aws.String(clusterArn),
+		Services: []string{serviceName},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.Services) == 0 {
+		return nil, fmt.Errorf("service %s not found in cluster %s", serviceName, clusterArn)
+	}
+	return &output.Services[0], nil
+}
+
+// isServiceStable is true once the service has a single deployment running all of its desired tasks
+func isServiceStable(service *types.Service) bool {
+	return len(service.Deployments) == 1 && service.RunningCount == service.DesiredCount
+}
+
+// WaitForServiceStable polls the service until the deployment rolled out, see isServiceStable
+func WaitForServiceStable(clusterArn, serviceName string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		service, err := DescribeService(clusterArn, serviceName)
+		if err != nil {
+			return err
+		}
+		if isServiceStable(service) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s is not stable after %s, %d deployments, %d of %d tasks running",
+				serviceName, timeout, len(service.Deployments), service.RunningCount, service.DesiredCount)
+		}
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// RollDeployment forces a new deployment of the service and waits for it to roll out, so every task of the service
+// is replaced, e.g. to pick up a new agent config
+func RollDeployment(clusterArn, serviceName string, timeout time.Duration) error {
+	if err := RestartService(clusterArn, nil, serviceName); err != nil {
+		return err
+	}
+	return WaitForServiceStable(clusterArn, serviceName, timeout)
+}
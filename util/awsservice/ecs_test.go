@@ -0,0 +1,118 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func withEcsMock(t *testing.T) *mocks.ECSMock {
+	client := &mocks.ECSMock{}
+	original := awsservice.EcsClient
+	awsservice.EcsClient = client
+	t.Cleanup(func() { awsservice.EcsClient = original })
+	return client
+}
+
+func TestRunTask(t *testing.T) {
+	client := withEcsMock(t)
+	client.On("RunTask", mock.Anything, mock.MatchedBy(func(in *ecs.RunTaskInput) bool {
+		return aws.ToString(in.TaskDefinition) == "agent:1" && len(in.Tags) > 0
+	})).Return(&ecs.RunTaskOutput{
+		Tasks: []types.Task{{TaskArn: aws.String("arn:aws:ecs:us-west-2:123456789012:task/cluster/1")}},
+	}, nil).Once()
+	client.On("RunTask", mock.Anything, mock.MatchedBy(func(in *ecs.RunTaskInput) bool {
+		return aws.ToString(in.TaskDefinition) == "agent:2"
+	})).Return(&ecs.RunTaskOutput{
+		Failures: []types.Failure{{Reason: aws.String("RESOURCE:MEMORY")}},
+	}, nil).Once()
+
+	taskArn, err := awsservice.RunTask("cluster", "agent:1", types.LaunchTypeEc2)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:task/cluster/1", taskArn)
+
+	_, err = awsservice.RunTask("cluster", "agent:2", types.LaunchTypeEc2)
+	assert.ErrorContains(t, err, "RESOURCE:MEMORY")
+	client.AssertExpectations(t)
+}
+
+func TestRollDeploymentWaitsForSingleDeployment(t *testing.T) {
+	client := withEcsMock(t)
+	client.On("UpdateService", mock.Anything, mock.MatchedBy(func(in *ecs.UpdateServiceInput) bool {
+		return in.ForceNewDeployment && aws.ToString(in.Service) == "agent"
+	})).Return(&ecs.UpdateServiceOutput{}, nil).Once()
+	client.On("DescribeServices", mock.Anything, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{{
+			ServiceName:  aws.String("agent"),
+			Deployments:  []types.Deployment{{Status: aws.String("PRIMARY")}},
+			DesiredCount: 2,
+			RunningCount: 2,
+		}},
+	}, nil).Once()
+
+	require.NoError(t, awsservice.RollDeployment("cluster", "agent", time.Minute))
+	client.AssertExpectations(t)
+}
+
+func TestWaitForServiceStableTimesOut(t *testing.T) {
+	client := withEcsMock(t)
+	client.On("DescribeServices", mock.Anything, mock.Anything).Return(&ecs.DescribeServicesOutput{
+		Services: []types.Service{{
+			ServiceName:  aws.String("agent"),
+			Deployments:  []types.Deployment{{Status: aws.String("PRIMARY")}, {Status: aws.String("ACTIVE")}},
+			DesiredCount: 2,
+			RunningCount: 1,
+		}},
+	}, nil).Once()
+
+	err := awsservice.WaitForServiceStable("cluster", "agent", 0)
+	assert.ErrorContains(t, err, "2 deployments, 1 of 2 tasks running")
+}
+
+func TestWaitForTaskStatusFailsOnUnexpectedStop(t *testing.T) {
+	client := withEcsMock(t)
+	client.On("DescribeTasks", mock.Anything, mock.Anything).Return(&ecs.DescribeTasksOutput{
+		Tasks: []types.Task{{LastStatus: aws.String("STOPPED"), StoppedReason: aws.String("Essential container exited")}},
+	}, nil)
+
+	err := awsservice.WaitForTaskStatus("cluster", "task", "RUNNING", time.Minute)
+	assert.ErrorContains(t, err, "Essential container exited")
+	assert.NoError(t, awsservice.WaitForTaskStatus("cluster", "task", "STOPPED", time.Minute))
+}
+
+func TestGetTaskMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/task", r.URL.Path)
+		w.Write([]byte(`{"Cluster":"arn:aws:ecs:us-west-2:123456789012:cluster/cluster","TaskARN":"task","Family":"agent","Revision":"3","LaunchType":"EC2","Containers":[{"Name":"cloudwatch-agent","DockerId":"abc"}]}`))
+	}))
+	defer server.Close()
+	t.Setenv(awsservice.EcsMetadataUriV4Env, server.URL)
+
+	task, err := awsservice.GetTaskMetadata(time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:ecs:us-west-2:123456789012:cluster/cluster", task.Cluster)
+	assert.Equal(t, "EC2", task.LaunchType)
+	assert.Equal(t, []awsservice.ContainerMetadata{{Name: "cloudwatch-agent", DockerId: "abc"}}, task.Containers)
+}
+
+func TestGetTaskMetadataOutsideEcs(t *testing.T) {
+	t.Setenv(awsservice.EcsMetadataUriV4Env, "")
+	t.Setenv(awsservice.EcsMetadataUriV3Env, "")
+
+	_, err := awsservice.GetTaskMetadata(time.Second)
+	assert.Error(t, err)
+}
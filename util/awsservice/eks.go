@@ -5,7 +5,6 @@ package awsservice
 
 import (
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
@@ -18,14 +17,15 @@ type EKSClusterType struct {
 }
 
 func GetEKSInstances(clusterName string) ([]EKSInstance, error) {
-
-	describeEksInstancesOutput, err := describeEksInstances(clusterName)
+	instances, err := DescribeInstancesByFilters([]types.Filter{
+		{Name: aws.String("tag:aws:eks:cluster-name"), Values: []string{clusterName}},
+	})
 	if err != nil {
 		return []EKSInstance{}, err
 	}
 
 	var results []EKSInstance
-	for _, instance := range describeEksInstancesOutput.Reservations[0].Instances {
+	for _, instance := range instances {
 		results = append(results, EKSInstance{
 			InstanceName: instance.PrivateDnsName,
 		})
@@ -33,16 +33,3 @@ func GetEKSInstances(clusterName string) ([]EKSInstance, error) {
 
 	return results, nil
 }
-
-func describeEksInstances(clusterName string) (*ec2.DescribeInstancesOutput, error) {
-	return Ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{
-		Filters: []types.Filter{
-			{
-				Name: aws.String("tag:aws:eks:cluster-name"),
-				Values: []string{
-					clusterName,
-				},
-			},
-		},
-	})
-}
@@ -0,0 +1,190 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// EndpointOverrideEnv points every client except IMDS at a single endpoint, e.g. LocalStack or moto at
+// http://localhost:4566, so runners and validators can be developed without an AWS account.
+// See docs/local-development.md.
+const EndpointOverrideEnv = "CWA_TEST_ENDPOINT_URL"
+
+var endpointOverride = os.Getenv(EndpointOverrideEnv)
+
+var (
+	// regionOverride replaces the region of the default config, e.g. of AWS_REGION or the shared config
+	regionOverride string
+	// partitionOverride replaces the partition inferred from the region, for regions PartitionOf does not know
+	partitionOverride string
+)
+
+// dualStack is set once the clients use the dual-stack endpoints
+var dualStack bool
+
+// dnsSuffixes are the domains of the endpoints of each partition, the regular and the dual-stack ones
+var dnsSuffixes = map[string][2]string{
+	"aws":        {"amazonaws.com", "api.aws"},
+	"aws-cn":     {"amazonaws.com.cn", "api.amazonwebservices.com.cn"},
+	"aws-us-gov": {"amazonaws.com", "api.aws"},
+	"aws-iso":    {"c2s.ic.gov", "c2s.ic.gov"},
+	"aws-iso-b":  {"sc2s.sgov.gov", "sc2s.sgov.gov"},
+}
+
+// loadConfig loads the default config with the region and endpoint overrides, the counting retryer and the rate
+// limiter
+func loadConfig(extraOpts ...func(*config.LoadOptions) error) (aws.Config, error) {
+	opts := append([]func(*config.LoadOptions) error{config.WithRetryer(newCountingRetryer)}, extraOpts...)
+	if regionOverride != "" {
+		opts = append(opts, config.WithRegion(regionOverride))
+	}
+	if endpointOverride != "" {
+		opts = append(opts, config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
+			func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
+				return aws.Endpoint{
+					URL:               endpointOverride,
+					HostnameImmutable: true,
+					SigningRegion:     region,
+				}, nil
+			})))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	cfg.APIOptions = append(cfg.APIOptions, addRateLimitMiddleware)
+	return cfg, err
+}
+
+// withPathStyle is required by LocalStack and moto since they cannot resolve bucket subdomains
+func withPathStyle(o *s3.Options) {
+	o.UsePathStyle = endpointOverride != ""
+}
+
+// IsEndpointOverridden reports whether the clients talk to a local endpoint instead of AWS
+func IsEndpointOverridden() bool {
+	return endpointOverride != ""
+}
+
+// ConfigureEndpoints recreates the clients with the region, partition and endpoint overrides, so a suite can target
+// another region than the one of the host. An empty override keeps the default resolution, the endpoint of
+// EndpointOverrideEnv included.
+func ConfigureEndpoints(region, partition, endpoint string) error {
+	if region == "" && partition == "" && endpoint == "" {
+		return nil
+	}
+	if partition != "" {
+		if _, ok := dnsSuffixes[partition]; !ok {
+			return fmt.Errorf("unknown partition %s", partition)
+		}
+	}
+	regionOverride = region
+	partitionOverride = partition
+	if endpoint != "" {
+		endpointOverride = endpoint
+	}
+	var opts []func(*config.LoadOptions) error
+	if dualStack {
+		opts = append(opts, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	cfg, err := loadConfig(opts...)
+	if err != nil {
+		return err
+	}
+	awsCfg = cfg
+	newClients()
+	return nil
+}
+
+// GetRegion is the region the clients call
+func GetRegion() string {
+	return awsCfg.Region
+}
+
+// GetPartition is the partition of the region the clients call, e.g. to build the arns of the resources of a test
+func GetPartition() string {
+	if partitionOverride != "" {
+		return partitionOverride
+	}
+	return PartitionOf(awsCfg.Region)
+}
+
+// PartitionOf infers the partition of the region from its prefix
+func PartitionOf(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	case strings.HasPrefix(region, "us-isob-"):
+		return "aws-iso-b"
+	case strings.HasPrefix(region, "us-iso-"):
+		return "aws-iso"
+	default:
+		return "aws"
+	}
+}
+
+// ServiceEndpoint is the url of the endpoint of the service, e.g. monitoring or logs, in the region the clients call
+func ServiceEndpoint(service string) string {
+	if endpointOverride != "" {
+		return endpointOverride
+	}
+	suffixes, ok := dnsSuffixes[GetPartition()]
+	if !ok {
+		suffixes = dnsSuffixes["aws"]
+	}
+	suffix := suffixes[0]
+	if dualStack {
+		suffix = suffixes[1]
+	}
+	return fmt.Sprintf("https://%s.%s.%s/", service, awsCfg.Region, suffix)
+}
+
+// UseDualStackEndpoints recreates the clients with the dual-stack endpoints of the services and IMDS over IPv6, for
+// hosts in IPv6-only subnets which cannot reach the IPv4 only endpoints
+func UseDualStackEndpoints() error {
+	cfg, err := loadConfig(
+		config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled),
+		config.WithEC2IMDSEndpointMode(imds.EndpointModeStateIPv6),
+	)
+	if err != nil {
+		return err
+	}
+	awsCfg = cfg
+	newClients()
+	dualStack = true
+	return nil
+}
+
+// newClients recreates every client from the config
+func newClients() {
+	Ec2Client = ec2.NewFromConfig(awsCfg)
+	EcsClient = ecs.NewFromConfig(awsCfg)
+	SsmClient = ssm.NewFromConfig(awsCfg)
+	ImdsClient = imds.NewFromConfig(awsCfg)
+	CwmClient = cloudwatch.NewFromConfig(awsCfg)
+	CwlClient = cloudwatchlogs.NewFromConfig(awsCfg)
+	DynamodbClient = dynamodb.NewFromConfig(awsCfg)
+	FirehoseClient = firehose.NewFromConfig(awsCfg)
+	S3Client = s3.NewFromConfig(awsCfg, withPathStyle)
+	SnsClient = sns.NewFromConfig(awsCfg)
+	CloudformationClient = cloudformation.NewFromConfig(awsCfg)
+	StsClient = sts.NewFromConfig(awsCfg)
+}
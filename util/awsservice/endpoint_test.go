@@ -0,0 +1,38 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+func TestPartitionOf(t *testing.T) {
+	assert.Equal(t, "aws", awsservice.PartitionOf("us-west-2"))
+	assert.Equal(t, "aws", awsservice.PartitionOf(""))
+	assert.Equal(t, "aws-cn", awsservice.PartitionOf("cn-north-1"))
+	assert.Equal(t, "aws-us-gov", awsservice.PartitionOf("us-gov-west-1"))
+	assert.Equal(t, "aws-iso", awsservice.PartitionOf("us-iso-east-1"))
+	assert.Equal(t, "aws-iso-b", awsservice.PartitionOf("us-isob-east-1"))
+}
+
+func TestConfigureEndpoints(t *testing.T) {
+	assert.Error(t, awsservice.ConfigureEndpoints("", "aws-unknown", ""))
+
+	if awsservice.IsEndpointOverridden() {
+		t.Skip("the endpoint is overridden by the environment")
+	}
+	assert.NoError(t, awsservice.ConfigureEndpoints("cn-northwest-1", "", ""))
+	assert.Equal(t, "cn-northwest-1", awsservice.GetRegion())
+	assert.Equal(t, "aws-cn", awsservice.GetPartition())
+	assert.Equal(t, "https://logs.cn-northwest-1.amazonaws.com.cn/", awsservice.ServiceEndpoint("logs"))
+
+	assert.NoError(t, awsservice.ConfigureEndpoints("eu-west-1", "", "http://localhost:4566"))
+	assert.Equal(t, "eu-west-1", awsservice.GetRegion())
+	assert.True(t, awsservice.IsEndpointOverridden())
+	assert.Equal(t, "http://localhost:4566", awsservice.ServiceEndpoint("monitoring"))
+}
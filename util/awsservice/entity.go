@@ -0,0 +1,123 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// The pinned SDK predates the entity APIs, so they are called with signed JSON requests until the SDK is upgraded.
+
+// Entity is the entity, e.g. the service and environment, the agent associated with a log group or metric
+type Entity struct {
+	KeyAttributes map[string]string
+	Attributes    map[string]string
+}
+
+type listEntitiesForLogGroupOutput struct {
+	Entities []struct {
+		KeyAttributes map[string]string `json:"keyAttributes"`
+		Attributes    map[string]string `json:"attributes"`
+	} `json:"entities"`
+}
+
+type listEntitiesForMetricOutput struct {
+	Entities []struct {
+		KeyAttributes map[string]string `json:"KeyAttributes"`
+		Attributes    map[string]string `json:"Attributes"`
+	} `json:"Entities"`
+}
+
+// ListEntitiesForLogGroup returns the entities associated with the log group by the PutLogEvents calls of the agent
+func ListEntitiesForLogGroup(logGroup string) ([]Entity, error) {
+	var output listEntitiesForLogGroupOutput
+	err := callJsonApi("logs", "application/x-amz-json-1.1", "Logs_20140328.ListEntitiesForLogGroup",
+		map[string]string{"logGroupIdentifier": logGroup}, &output)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]Entity, len(output.Entities))
+	for i, e := range output.Entities {
+		entities[i] = Entity{KeyAttributes: e.KeyAttributes, Attributes: e.Attributes}
+	}
+	return entities, nil
+}
+
+// ListEntitiesForMetric returns the entities associated with the metric by the PutMetricData calls of the agent
+func ListEntitiesForMetric(namespace, metricName string, dims []types.Dimension) ([]Entity, error) {
+	type dimension struct {
+		Name  string
+		Value string
+	}
+	input := struct {
+		Namespace  string
+		MetricName string
+		Dimensions []dimension
+	}{Namespace: namespace, MetricName: metricName}
+	for _, d := range dims {
+		input.Dimensions = append(input.Dimensions, dimension{Name: *d.Name, Value: *d.Value})
+	}
+
+	var output listEntitiesForMetricOutput
+	err := callJsonApi("monitoring", "application/x-amz-json-1.0", "GraniteServiceVersion20100801.ListEntitiesForMetric", input, &output)
+	if err != nil {
+		return nil, err
+	}
+	entities := make([]Entity, len(output.Entities))
+	for i, e := range output.Entities {
+		entities[i] = Entity{KeyAttributes: e.KeyAttributes, Attributes: e.Attributes}
+	}
+	return entities, nil
+}
+
+// callJsonApi signs the json protocol request with the credentials of the clients and decodes the response into output
+func callJsonApi(service, contentType, target string, input, output interface{}) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ServiceEndpoint(service), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Amz-Target", target)
+
+	credentials, err := awsCfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(body)
+	// the signing name of CloudWatch is monitoring, the same as its endpoint prefix
+	if err = v4.NewSigner().SignHTTP(ctx, credentials, req, hex.EncodeToString(hash[:]), service, awsCfg.Region, time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		operation := target[strings.LastIndex(target, ".")+1:]
+		return fmt.Errorf("%s failed with status %d: %s", operation, resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, output)
+}
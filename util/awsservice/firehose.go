@@ -0,0 +1,111 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/firehose/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// S3Destination is where a firehose delivery stream delivers its records to
+type S3Destination struct {
+	Bucket string
+	// Prefix is the static part of the prefix of the delivered objects, up to the first !{...} expression
+	Prefix            string
+	ErrorOutputPrefix string
+	CompressionFormat types.CompressionFormat
+}
+
+// DescribeDeliveryStream returns the description of the firehose delivery stream
+func DescribeDeliveryStream(name string) (*types.DeliveryStreamDescription, error) {
+	output, err := FirehoseClient.DescribeDeliveryStream(ctx, &firehose.DescribeDeliveryStreamInput{
+		DeliveryStreamName: aws.String(name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.DeliveryStreamDescription, nil
+}
+
+// WaitForDeliveryStreamActive polls the delivery stream until it is active, which takes minutes after it is created
+func WaitForDeliveryStreamActive(name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		description, err := DescribeDeliveryStream(name)
+		if err != nil {
+			return err
+		}
+		if description.DeliveryStreamStatus == types.DeliveryStreamStatusActive {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("delivery stream %s is still %s after %s", name, description.DeliveryStreamStatus, timeout)
+		}
+		logger.Infof("Delivery stream %s is %s, waiting for it to be active", name, description.DeliveryStreamStatus)
+		time.Sleep(10 * time.Second)
+	}
+}
+
+// GetDeliveryStreamS3Destination returns the s3 destination of the delivery stream, an error when it delivers elsewhere
+func GetDeliveryStreamS3Destination(name string) (S3Destination, error) {
+	description, err := DescribeDeliveryStream(name)
+	if err != nil {
+		return S3Destination{}, err
+	}
+	for _, destination := range description.Destinations {
+		if d := destination.ExtendedS3DestinationDescription; d != nil {
+			return newS3Destination(aws.ToString(d.BucketARN), aws.ToString(d.Prefix), aws.ToString(d.ErrorOutputPrefix), d.CompressionFormat)
+		}
+		if d := destination.S3DestinationDescription; d != nil {
+			return newS3Destination(aws.ToString(d.BucketARN), aws.ToString(d.Prefix), aws.ToString(d.ErrorOutputPrefix), d.CompressionFormat)
+		}
+	}
+	return S3Destination{}, fmt.Errorf("delivery stream %s has no s3 destination", name)
+}
+
+func newS3Destination(bucketArn, prefix, errorOutputPrefix string, compression types.CompressionFormat) (S3Destination, error) {
+	// arn:<partition>:s3:::<bucket>
+	i := strings.LastIndex(bucketArn, ":")
+	if i < 0 || i == len(bucketArn)-1 {
+		return S3Destination{}, fmt.Errorf("invalid bucket arn %q", bucketArn)
+	}
+	if j := strings.Index(prefix, "!{"); j >= 0 {
+		prefix = prefix[:j]
+	}
+	return S3Destination{
+		Bucket:            bucketArn[i+1:],
+		Prefix:            prefix,
+		ErrorOutputPrefix: errorOutputPrefix,
+		CompressionFormat: compression,
+	}, nil
+}
+
+// GetDeliveredLines returns the lines of the objects the delivery stream delivered to its s3 destination since the
+// time. Delivery is buffered, so the lines of records put at the end of a test show up once the buffer interval passed.
+func GetDeliveredLines(deliveryStreamName string, since time.Time) ([]string, error) {
+	destination, err := GetDeliveryStreamS3Destination(deliveryStreamName)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := ListObjects(destination.Bucket, destination.Prefix, since)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, object := range objects {
+		objectLines, err := ReadObjectLines(destination.Bucket, aws.ToString(object.Key))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, objectLines...)
+	}
+	return lines, nil
+}
@@ -0,0 +1,116 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	firehosetypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func deliveryStream(destination firehosetypes.DestinationDescription) *firehose.DescribeDeliveryStreamOutput {
+	return &firehose.DescribeDeliveryStreamOutput{
+		DeliveryStreamDescription: &firehosetypes.DeliveryStreamDescription{
+			DeliveryStreamName:   aws.String("stream"),
+			DeliveryStreamStatus: firehosetypes.DeliveryStreamStatusActive,
+			Destinations:         []firehosetypes.DestinationDescription{destination},
+		},
+	}
+}
+
+func TestGetDeliveryStreamS3Destination(t *testing.T) {
+	client := &mocks.FirehoseMock{}
+	original := awsservice.FirehoseClient
+	awsservice.FirehoseClient = client
+	defer func() { awsservice.FirehoseClient = original }()
+
+	client.On("DescribeDeliveryStream", mock.Anything, mock.Anything).Return(deliveryStream(firehosetypes.DestinationDescription{
+		ExtendedS3DestinationDescription: &firehosetypes.ExtendedS3DestinationDescription{
+			BucketARN:         aws.String("arn:aws:s3:::destination-bucket"),
+			Prefix:            aws.String("logs/!{timestamp:yyyy}/"),
+			CompressionFormat: firehosetypes.CompressionFormatGzip,
+		},
+	}), nil).Once()
+
+	destination, err := awsservice.GetDeliveryStreamS3Destination("stream")
+	require.NoError(t, err)
+	assert.Equal(t, "destination-bucket", destination.Bucket)
+	assert.Equal(t, "logs/", destination.Prefix)
+	assert.Equal(t, firehosetypes.CompressionFormatGzip, destination.CompressionFormat)
+	client.AssertExpectations(t)
+}
+
+func TestGetDeliveryStreamS3DestinationWithoutS3(t *testing.T) {
+	client := &mocks.FirehoseMock{}
+	original := awsservice.FirehoseClient
+	awsservice.FirehoseClient = client
+	defer func() { awsservice.FirehoseClient = original }()
+
+	client.On("DescribeDeliveryStream", mock.Anything, mock.Anything).Return(deliveryStream(firehosetypes.DestinationDescription{
+		HttpEndpointDestinationDescription: &firehosetypes.HttpEndpointDestinationDescription{},
+	}), nil).Once()
+
+	_, err := awsservice.GetDeliveryStreamS3Destination("stream")
+	assert.ErrorContains(t, err, "has no s3 destination")
+}
+
+func TestGetDeliveredLines(t *testing.T) {
+	firehoseClient := &mocks.FirehoseMock{}
+	s3Client := &mocks.S3Mock{}
+	originalFirehose, originalS3 := awsservice.FirehoseClient, awsservice.S3Client
+	awsservice.FirehoseClient, awsservice.S3Client = firehoseClient, s3Client
+	defer func() { awsservice.FirehoseClient, awsservice.S3Client = originalFirehose, originalS3 }()
+
+	firehoseClient.On("DescribeDeliveryStream", mock.Anything, mock.Anything).Return(deliveryStream(firehosetypes.DestinationDescription{
+		S3DestinationDescription: &firehosetypes.S3DestinationDescription{
+			BucketARN: aws.String("arn:aws:s3:::bucket"),
+			Prefix:    aws.String("agent/"),
+		},
+	}), nil).Once()
+
+	since := time.Now()
+	s3Client.On("ListObjectsV2", mock.Anything, mock.MatchedBy(func(in *s3.ListObjectsV2Input) bool {
+		return aws.ToString(in.Bucket) == "bucket" && aws.ToString(in.Prefix) == "agent/"
+	})).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("agent/old"), LastModified: aws.Time(since.Add(-time.Hour))},
+			{Key: aws.String("agent/plain"), LastModified: aws.Time(since)},
+			{Key: aws.String("agent/compressed.gz"), LastModified: aws.Time(since.Add(time.Minute))},
+		},
+	}, nil).Once()
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte("third\nfourth\n"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	s3Client.On("GetObject", mock.Anything, withKey("agent/plain")).Return(&s3.GetObjectOutput{
+		Body: io.NopCloser(strings.NewReader("first\r\n\nsecond\n")),
+	}, nil).Once()
+	s3Client.On("GetObject", mock.Anything, withKey("agent/compressed.gz")).Return(&s3.GetObjectOutput{
+		Body: io.NopCloser(&compressed),
+	}, nil).Once()
+
+	lines, err := awsservice.GetDeliveredLines("stream", since)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "third", "fourth"}, lines)
+	firehoseClient.AssertExpectations(t)
+	s3Client.AssertExpectations(t)
+}
@@ -4,7 +4,15 @@
 package awsservice
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 )
@@ -37,3 +45,63 @@ func GetImdsMetadata() *imds.GetInstanceIdentityDocumentOutput {
 	}
 	return identityDoc
 }
+
+// TryGetImdsMetadata is the non-fatal variant of GetImdsMetadata. It gives up after the timeout so it can be used
+// to probe whether the tests are running on EC2 at all.
+func TryGetImdsMetadata(timeout time.Duration) (*imds.GetInstanceIdentityDocumentOutput, error) {
+	if identityDoc != nil {
+		return identityDoc, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	doc, err := ImdsClient.GetInstanceIdentityDocument(probeCtx, &imds.GetInstanceIdentityDocumentInput{})
+	if err != nil {
+		return nil, err
+	}
+	identityDoc = doc
+	return identityDoc, nil
+}
+
+// SpotInstanceAction is the interruption notice IMDS serves for a spot instance two minutes before it is interrupted
+type SpotInstanceAction struct {
+	Action string    `json:"action"`
+	Time   time.Time `json:"time"`
+}
+
+// GetInstanceLifeCycle returns spot for a spot instance and on-demand otherwise
+func GetInstanceLifeCycle() (string, error) {
+	return getMetadata("instance-life-cycle")
+}
+
+// GetSpotInstanceAction returns the pending interruption of the spot instance, nil while none is pending
+func GetSpotInstanceAction() (*SpotInstanceAction, error) {
+	content, err := getMetadata("spot/instance-action")
+	if err != nil {
+		// IMDS only serves the path once the notice is sent
+		var responseErr interface{ HTTPStatusCode() int }
+		if errors.As(err, &responseErr) && responseErr.HTTPStatusCode() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var action SpotInstanceAction
+	if err = json.Unmarshal([]byte(content), &action); err != nil {
+		return nil, fmt.Errorf("invalid spot instance action %q: %w", content, err)
+	}
+	return &action, nil
+}
+
+func getMetadata(path string) (string, error) {
+	output, err := ImdsClient.GetMetadata(ctx, &imds.GetMetadataInput{Path: path})
+	if err != nil {
+		return "", err
+	}
+	defer output.Content.Close()
+	content, err := io.ReadAll(output.Content)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func withImdsMock(t *testing.T) *mocks.IMDSMock {
+	client := &mocks.IMDSMock{}
+	original := awsservice.ImdsClient
+	awsservice.ImdsClient = client
+	t.Cleanup(func() { awsservice.ImdsClient = original })
+	return client
+}
+
+func metadata(content string) *imds.GetMetadataOutput {
+	return &imds.GetMetadataOutput{Content: io.NopCloser(strings.NewReader(content))}
+}
+
+func TestGetSpotInstanceAction(t *testing.T) {
+	client := withImdsMock(t)
+	instanceAction := mock.MatchedBy(func(in *imds.GetMetadataInput) bool {
+		return in.Path == "spot/instance-action"
+	})
+	notFound := &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusNotFound}},
+		Err:      io.EOF,
+	}
+	client.On("GetMetadata", mock.Anything, instanceAction).Return(nil, notFound).Once()
+	client.On("GetMetadata", mock.Anything, instanceAction).
+		Return(metadata(`{"action": "terminate", "time": "2023-05-17T08:22:00Z"}`), nil).Once()
+
+	action, err := awsservice.GetSpotInstanceAction()
+	require.NoError(t, err)
+	assert.Nil(t, action, "no notice is pending until IMDS serves the path")
+
+	action, err = awsservice.GetSpotInstanceAction()
+	require.NoError(t, err)
+	require.NotNil(t, action)
+	assert.Equal(t, "terminate", action.Action)
+	assert.Equal(t, time.Date(2023, 5, 17, 8, 22, 0, 0, time.UTC), action.Time)
+}
+
+func TestGetInstanceLifeCycle(t *testing.T) {
+	client := withImdsMock(t)
+	client.On("GetMetadata", mock.Anything, mock.MatchedBy(func(in *imds.GetMetadataInput) bool {
+		return in.Path == "instance-life-cycle"
+	})).Return(metadata("spot\n"), nil).Once()
+
+	lifeCycle, err := awsservice.GetInstanceLifeCycle()
+	require.NoError(t, err)
+	assert.Equal(t, "spot", lifeCycle)
+}
@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+)
+
+const insightRuleReportPollInterval = 30 * time.Second
+
+// insightRule is the Contributor Insights rule syntax for log groups
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/ContributorInsights-RuleSyntax.html
+type insightRule struct {
+	Schema        insightRuleSchema       `json:"Schema"`
+	LogGroupNames []string                `json:"LogGroupNames"`
+	LogFormat     string                  `json:"LogFormat"`
+	Contribution  insightRuleContribution `json:"Contribution"`
+	AggregateOn   string                  `json:"AggregateOn"`
+}
+
+type insightRuleSchema struct {
+	Name    string `json:"Name"`
+	Version int    `json:"Version"`
+}
+
+type insightRuleContribution struct {
+	Keys []string `json:"Keys"`
+}
+
+// PutLogInsightRule creates an enabled Contributor Insights rule counting the JSON log events of the log group by
+// the keys, e.g. $.client, and tags it for the run
+func PutLogInsightRule(ruleName, logGroupName string, keys []string) error {
+	definition, err := json.Marshal(insightRule{
+		Schema:        insightRuleSchema{Name: "CloudWatchLogRule", Version: 1},
+		LogGroupNames: []string{logGroupName},
+		LogFormat:     "JSON",
+		Contribution:  insightRuleContribution{Keys: keys},
+		AggregateOn:   "Count",
+	})
+	if err != nil {
+		return err
+	}
+
+	input := &cloudwatch.PutInsightRuleInput{
+		RuleName:       aws.String(ruleName),
+		RuleDefinition: aws.String(string(definition)),
+		RuleState:      aws.String("ENABLED"),
+	}
+	for k, v := range GetRunTags() {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	_, err = CwmClient.PutInsightRule(ctx, input)
+	return err
+}
+
+// GetInsightRuleReport returns the report of the rule over the time range, with up to the top 100 contributors
+func GetInsightRuleReport(ruleName string, startTime, endTime time.Time, period int32) (*cloudwatch.GetInsightRuleReportOutput, error) {
+	return CwmClient.GetInsightRuleReport(ctx, &cloudwatch.GetInsightRuleReportInput{
+		RuleName:            aws.String(ruleName),
+		StartTime:           aws.Time(startTime),
+		EndTime:             aws.Time(endTime),
+		Period:              aws.Int32(period),
+		MaxContributorCount: aws.Int32(100),
+	})
+}
+
+// WaitForInsightRuleReport polls the report of the rule since startTime until the predicate accepts it, since
+// Contributor Insights takes a few minutes to evaluate new log events. On timeout the returned error wraps an
+// await.TimeoutError and the last report is returned.
+func WaitForInsightRuleReport(ruleName string, startTime time.Time, period int32, timeout time.Duration, predicate func(report *cloudwatch.GetInsightRuleReportOutput) bool) (*cloudwatch.GetInsightRuleReportOutput, error) {
+	var report *cloudwatch.GetInsightRuleReportOutput
+	err := await.WaitUntil(ctx, insightRuleReportPollInterval, timeout, fmt.Sprintf("report of insight rule %s", ruleName), func() (bool, error) {
+		var err error
+		report, err = GetInsightRuleReport(ruleName, startTime, time.Now(), period)
+		if err != nil {
+			return false, err
+		}
+		return predicate(report), nil
+	})
+	return report, err
+}
+
+// DeleteInsightRule deletes the rule by name
+func DeleteInsightRule(ruleName string) error {
+	output, err := CwmClient.DeleteInsightRules(ctx, &cloudwatch.DeleteInsightRulesInput{RuleNames: []string{ruleName}})
+	if err != nil {
+		return err
+	}
+	if len(output.Failures) > 0 {
+		return fmt.Errorf("failed to delete insight rule %s: %s", ruleName, aws.ToString(output.Failures[0].FailureDescription))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// MetricStreamRecord is a metric of the json output format of a metric stream
+type MetricStreamRecord struct {
+	MetricStreamName string             `json:"metric_stream_name"`
+	Namespace        string             `json:"namespace"`
+	MetricName       string             `json:"metric_name"`
+	Dimensions       map[string]string  `json:"dimensions"`
+	Timestamp        int64              `json:"timestamp"`
+	Value            map[string]float64 `json:"value"`
+	Unit             string             `json:"unit"`
+}
+
+// PutMetricStream creates or updates the metric stream of the namespaces to the firehose delivery stream, in the json
+// output format and with the run tags
+func PutMetricStream(name, firehoseArn, roleArn string, namespaces []string) error {
+	input := &cloudwatch.PutMetricStreamInput{
+		Name:         aws.String(name),
+		FirehoseArn:  aws.String(firehoseArn),
+		RoleArn:      aws.String(roleArn),
+		OutputFormat: types.MetricStreamOutputFormatJson,
+	}
+	for _, namespace := range namespaces {
+		input.IncludeFilters = append(input.IncludeFilters, types.MetricStreamFilter{Namespace: aws.String(namespace)})
+	}
+	for k, v := range GetRunTags() {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	_, err := CwmClient.PutMetricStream(ctx, input)
+	return err
+}
+
+// DeleteMetricStream deletes the metric stream by name
+func DeleteMetricStream(name string) error {
+	_, err := CwmClient.DeleteMetricStream(ctx, &cloudwatch.DeleteMetricStreamInput{Name: aws.String(name)})
+	return err
+}
+
+// GetMetricStreamRecords returns the records of the metric stream in the objects the firehose delivery stream wrote
+// to the bucket since the time. Firehose concatenates the records of a batch, one json document per line.
+func GetMetricStreamRecords(bucket, streamName string, since time.Time) ([]MetricStreamRecord, error) {
+	objects, err := ListObjects(bucket, "", since)
+	if err != nil {
+		return nil, err
+	}
+	var records []MetricStreamRecord
+	for _, object := range objects {
+		objectRecords, err := getMetricStreamObjectRecords(bucket, aws.ToString(object.Key))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range objectRecords {
+			if record.MetricStreamName == streamName {
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+func getMetricStreamObjectRecords(bucket, key string) ([]MetricStreamRecord, error) {
+	lines, err := ReadObjectLines(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]MetricStreamRecord, 0, len(lines))
+	for _, line := range lines {
+		var record MetricStreamRecord
+		if err = json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse metric stream record in s3://%s/%s: %w", bucket, key, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
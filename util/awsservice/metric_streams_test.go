@@ -0,0 +1,74 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice/mocks"
+)
+
+func withKey(key string) interface{} {
+	return mock.MatchedBy(func(in *s3.GetObjectInput) bool {
+		return aws.ToString(in.Key) == key
+	})
+}
+
+func TestGetMetricStreamRecords(t *testing.T) {
+	client := &mocks.S3Mock{}
+	original := awsservice.S3Client
+	awsservice.S3Client = client
+	defer func() { awsservice.S3Client = original }()
+
+	since := time.Now()
+	client.On("ListObjectsV2", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{
+			{Key: aws.String("old"), LastModified: aws.Time(since.Add(-time.Hour))},
+			{Key: aws.String("new"), LastModified: aws.Time(since.Add(time.Minute))},
+		},
+	}, nil).Once()
+	body := `{"metric_stream_name":"stream","namespace":"ns","metric_name":"cpu_usage_idle","dimensions":{"cpu":"cpu-total"},"value":{"sum":99}}
+{"metric_stream_name":"other","namespace":"ns","metric_name":"cpu_usage_idle"}
+`
+	client.On("GetObject", mock.Anything, withKey("new")).Return(&s3.GetObjectOutput{
+		Body: io.NopCloser(strings.NewReader(body)),
+	}, nil).Once()
+
+	records, err := awsservice.GetMetricStreamRecords("bucket", "stream", since)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "cpu_usage_idle", records[0].MetricName)
+	assert.Equal(t, map[string]string{"cpu": "cpu-total"}, records[0].Dimensions)
+	assert.Equal(t, 99.0, records[0].Value["sum"])
+	client.AssertExpectations(t)
+}
+
+func TestGetMetricStreamRecordsReturnsParseError(t *testing.T) {
+	client := &mocks.S3Mock{}
+	original := awsservice.S3Client
+	awsservice.S3Client = client
+	defer func() { awsservice.S3Client = original }()
+
+	since := time.Now()
+	client.On("ListObjectsV2", mock.Anything, mock.Anything).Return(&s3.ListObjectsV2Output{
+		Contents: []types.Object{{Key: aws.String("new"), LastModified: aws.Time(since)}},
+	}, nil).Once()
+	client.On("GetObject", mock.Anything, withKey("new")).Return(&s3.GetObjectOutput{
+		Body: io.NopCloser(strings.NewReader("not json\n")),
+	}, nil).Once()
+
+	_, err := awsservice.GetMetricStreamRecords("bucket", "stream", since)
+	assert.ErrorContains(t, err, "s3://bucket/new")
+}
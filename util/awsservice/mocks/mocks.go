@@ -0,0 +1,443 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+)
+
+// CloudWatchMock is a testify mock of awsservice.CloudWatchAPI
+type CloudWatchMock struct {
+	mock.Mock
+}
+
+var _ awsservice.CloudWatchAPI = (*CloudWatchMock)(nil)
+
+func (m *CloudWatchMock) DeleteAlarms(ctx context.Context, params *cloudwatch.DeleteAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteAlarmsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.DeleteAlarmsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) DeleteInsightRules(ctx context.Context, params *cloudwatch.DeleteInsightRulesInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteInsightRulesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.DeleteInsightRulesOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) DeleteMetricStream(ctx context.Context, params *cloudwatch.DeleteMetricStreamInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DeleteMetricStreamOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.DeleteMetricStreamOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) DescribeAlarms(ctx context.Context, params *cloudwatch.DescribeAlarmsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.DescribeAlarmsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.DescribeAlarmsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) GetInsightRuleReport(ctx context.Context, params *cloudwatch.GetInsightRuleReportInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetInsightRuleReportOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.GetInsightRuleReportOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.GetMetricDataOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.GetMetricStatisticsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) ListMetrics(ctx context.Context, params *cloudwatch.ListMetricsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.ListMetricsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.ListMetricsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) ListTagsForResource(ctx context.Context, params *cloudwatch.ListTagsForResourceInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.ListTagsForResourceOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.ListTagsForResourceOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) PutInsightRule(ctx context.Context, params *cloudwatch.PutInsightRuleInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutInsightRuleOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.PutInsightRuleOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) PutMetricAlarm(ctx context.Context, params *cloudwatch.PutMetricAlarmInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricAlarmOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.PutMetricAlarmOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.PutMetricDataOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) PutMetricStream(ctx context.Context, params *cloudwatch.PutMetricStreamInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricStreamOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.PutMetricStreamOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchMock) TagResource(ctx context.Context, params *cloudwatch.TagResourceInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.TagResourceOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatch.TagResourceOutput)
+	return output, args.Error(1)
+}
+
+// CloudWatchLogsMock is a testify mock of awsservice.CloudWatchLogsAPI
+type CloudWatchLogsMock struct {
+	mock.Mock
+}
+
+var _ awsservice.CloudWatchLogsAPI = (*CloudWatchLogsMock)(nil)
+
+func (m *CloudWatchLogsMock) AssociateKmsKey(ctx context.Context, params *cloudwatchlogs.AssociateKmsKeyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.AssociateKmsKeyOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.AssociateKmsKeyOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.CreateLogGroupOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.CreateLogStreamOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.DeleteLogGroupOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) DeleteLogStream(ctx context.Context, params *cloudwatchlogs.DeleteLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogStreamOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.DeleteLogStreamOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.DescribeLogGroupsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) DescribeLogStreams(ctx context.Context, params *cloudwatchlogs.DescribeLogStreamsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogStreamsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.DescribeLogStreamsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.GetLogEventsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) ListTagsLogGroup(ctx context.Context, params *cloudwatchlogs.ListTagsLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsLogGroupOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.ListTagsLogGroupOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.PutLogEventsOutput)
+	return output, args.Error(1)
+}
+
+func (m *CloudWatchLogsMock) TagLogGroup(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*cloudwatchlogs.TagLogGroupOutput)
+	return output, args.Error(1)
+}
+
+// EC2Mock is a testify mock of awsservice.EC2API
+type EC2Mock struct {
+	mock.Mock
+}
+
+var _ awsservice.EC2API = (*EC2Mock)(nil)
+
+func (m *EC2Mock) CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.CreateTagsOutput)
+	return output, args.Error(1)
+}
+
+func (m *EC2Mock) DescribeInstances(ctx context.Context, params *ec2.DescribeInstancesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.DescribeInstancesOutput)
+	return output, args.Error(1)
+}
+
+func (m *EC2Mock) DescribeInstanceTypes(ctx context.Context, params *ec2.DescribeInstanceTypesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.DescribeInstanceTypesOutput)
+	return output, args.Error(1)
+}
+
+func (m *EC2Mock) DescribeTags(ctx context.Context, params *ec2.DescribeTagsInput, optFns ...func(*ec2.Options)) (*ec2.DescribeTagsOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.DescribeTagsOutput)
+	return output, args.Error(1)
+}
+
+func (m *EC2Mock) DescribeVolumes(ctx context.Context, params *ec2.DescribeVolumesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeVolumesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.DescribeVolumesOutput)
+	return output, args.Error(1)
+}
+
+func (m *EC2Mock) TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ec2.TerminateInstancesOutput)
+	return output, args.Error(1)
+}
+
+// ECSMock is a testify mock of awsservice.ECSAPI
+type ECSMock struct {
+	mock.Mock
+}
+
+var _ awsservice.ECSAPI = (*ECSMock)(nil)
+
+func (m *ECSMock) DescribeContainerInstances(ctx context.Context, params *ecs.DescribeContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeContainerInstancesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.DescribeContainerInstancesOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) ListContainerInstances(ctx context.Context, params *ecs.ListContainerInstancesInput, optFns ...func(*ecs.Options)) (*ecs.ListContainerInstancesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.ListContainerInstancesOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) DescribeServices(ctx context.Context, params *ecs.DescribeServicesInput, optFns ...func(*ecs.Options)) (*ecs.DescribeServicesOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.DescribeServicesOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) DescribeTasks(ctx context.Context, params *ecs.DescribeTasksInput, optFns ...func(*ecs.Options)) (*ecs.DescribeTasksOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.DescribeTasksOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) ListTasks(ctx context.Context, params *ecs.ListTasksInput, optFns ...func(*ecs.Options)) (*ecs.ListTasksOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.ListTasksOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) RunTask(ctx context.Context, params *ecs.RunTaskInput, optFns ...func(*ecs.Options)) (*ecs.RunTaskOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.RunTaskOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) StopTask(ctx context.Context, params *ecs.StopTaskInput, optFns ...func(*ecs.Options)) (*ecs.StopTaskOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.StopTaskOutput)
+	return output, args.Error(1)
+}
+
+func (m *ECSMock) UpdateService(ctx context.Context, params *ecs.UpdateServiceInput, optFns ...func(*ecs.Options)) (*ecs.UpdateServiceOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ecs.UpdateServiceOutput)
+	return output, args.Error(1)
+}
+
+// SSMMock is a testify mock of awsservice.SSMAPI
+type SSMMock struct {
+	mock.Mock
+}
+
+var _ awsservice.SSMAPI = (*SSMMock)(nil)
+
+func (m *SSMMock) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ssm.GetParameterOutput)
+	return output, args.Error(1)
+}
+
+func (m *SSMMock) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*ssm.PutParameterOutput)
+	return output, args.Error(1)
+}
+
+// DynamoDBMock is a testify mock of awsservice.DynamoDBAPI
+type DynamoDBMock struct {
+	mock.Mock
+}
+
+var _ awsservice.DynamoDBAPI = (*DynamoDBMock)(nil)
+
+func (m *DynamoDBMock) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*dynamodb.DeleteItemOutput)
+	return output, args.Error(1)
+}
+
+func (m *DynamoDBMock) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*dynamodb.GetItemOutput)
+	return output, args.Error(1)
+}
+
+func (m *DynamoDBMock) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*dynamodb.PutItemOutput)
+	return output, args.Error(1)
+}
+
+func (m *DynamoDBMock) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*dynamodb.QueryOutput)
+	return output, args.Error(1)
+}
+
+// FirehoseMock is a testify mock of awsservice.FirehoseAPI
+type FirehoseMock struct {
+	mock.Mock
+}
+
+var _ awsservice.FirehoseAPI = (*FirehoseMock)(nil)
+
+func (m *FirehoseMock) DescribeDeliveryStream(ctx context.Context, params *firehose.DescribeDeliveryStreamInput, optFns ...func(*firehose.Options)) (*firehose.DescribeDeliveryStreamOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*firehose.DescribeDeliveryStreamOutput)
+	return output, args.Error(1)
+}
+
+// IMDSMock is a testify mock of awsservice.IMDSAPI
+type IMDSMock struct {
+	mock.Mock
+}
+
+var _ awsservice.IMDSAPI = (*IMDSMock)(nil)
+
+func (m *IMDSMock) GetInstanceIdentityDocument(ctx context.Context, params *imds.GetInstanceIdentityDocumentInput, optFns ...func(*imds.Options)) (*imds.GetInstanceIdentityDocumentOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*imds.GetInstanceIdentityDocumentOutput)
+	return output, args.Error(1)
+}
+
+func (m *IMDSMock) GetMetadata(ctx context.Context, params *imds.GetMetadataInput, optFns ...func(*imds.Options)) (*imds.GetMetadataOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*imds.GetMetadataOutput)
+	return output, args.Error(1)
+}
+
+// S3Mock is a testify mock of awsservice.S3API
+type S3Mock struct {
+	mock.Mock
+}
+
+var _ awsservice.S3API = (*S3Mock)(nil)
+
+func (m *S3Mock) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.GetObjectOutput)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.ListObjectsV2Output)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.PutObjectOutput)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.CreateMultipartUploadOutput)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.UploadPartOutput)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.CompleteMultipartUploadOutput)
+	return output, args.Error(1)
+}
+
+func (m *S3Mock) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*s3.AbortMultipartUploadOutput)
+	return output, args.Error(1)
+}
+
+// SNSMock is a testify mock of awsservice.SNSAPI
+type SNSMock struct {
+	mock.Mock
+}
+
+var _ awsservice.SNSAPI = (*SNSMock)(nil)
+
+func (m *SNSMock) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*sns.PublishOutput)
+	return output, args.Error(1)
+}
+
+// STSMock is a testify mock of awsservice.STSAPI
+type STSMock struct {
+	mock.Mock
+}
+
+var _ awsservice.STSAPI = (*STSMock)(nil)
+
+func (m *STSMock) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	args := m.Called(ctx, params)
+	output, _ := args.Get(0).(*sts.AssumeRoleOutput)
+	return output, args.Error(1)
+}
@@ -0,0 +1,110 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+const defaultRateLimitKey = "*"
+
+// tokenBucket allows rate calls per second with bursts of up to rate calls
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.rate {
+			b.tokens = b.rate
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+var (
+	rateLimitsMu sync.RWMutex
+	// rateLimits is keyed by operation name, e.g. GetMetricData, or * for every other operation
+	rateLimits = map[string]*tokenBucket{}
+)
+
+// SetRateLimits configures the client side rate limits shared by every client, so parallel runners do not trip the
+// account limits. The spec is a comma-delimited list of operation=tps, where * applies to every operation without
+// its own limit, e.g. "GetMetricData=5,ListMetrics=10,*=25". An empty spec disables rate limiting.
+func SetRateLimits(spec string) error {
+	limits := map[string]*tokenBucket{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		operation, tps, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid rate limit %q, expected operation=tps", entry)
+		}
+		rate, err := strconv.ParseFloat(tps, 64)
+		if err != nil || rate <= 0 {
+			return fmt.Errorf("invalid tps in rate limit %q", entry)
+		}
+		limits[strings.TrimSpace(operation)] = newTokenBucket(rate)
+	}
+
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	rateLimits = limits
+	return nil
+}
+
+func getRateLimit(operation string) *tokenBucket {
+	rateLimitsMu.RLock()
+	defer rateLimitsMu.RUnlock()
+	if b, ok := rateLimits[operation]; ok {
+		return b
+	}
+	return rateLimits[defaultRateLimitKey]
+}
+
+// addRateLimitMiddleware waits for a token before the retry middleware, so retries of a call are not limited again
+func addRateLimitMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("RateLimit",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if b := getRateLimit(awsmiddleware.GetOperationName(ctx)); b != nil {
+				if err := b.wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+			}
+			return next.HandleFinalize(ctx, in)
+		}), middleware.Before)
+}
@@ -0,0 +1,44 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+var (
+	apiRetryCount    int64
+	apiThrottleCount int64
+)
+
+// countingRetryer is the standard SDK retryer, which also counts retries and throttles for the framework health metrics
+type countingRetryer struct {
+	aws.Retryer
+}
+
+func newCountingRetryer() aws.Retryer {
+	return &countingRetryer{Retryer: retry.NewStandard()}
+}
+
+func (r *countingRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	atomic.AddInt64(&apiRetryCount, 1)
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(opErr) == aws.TrueTernary {
+		atomic.AddInt64(&apiThrottleCount, 1)
+	}
+	return r.Retryer.GetRetryToken(ctx, opErr)
+}
+
+// GetApiRetryCount returns the number of AWS API calls retried by the SDK since the process started
+func GetApiRetryCount() int64 {
+	return atomic.LoadInt64(&apiRetryCount)
+}
+
+// GetApiThrottleCount returns the number of AWS API calls throttled since the process started
+func GetApiThrottleCount() int64 {
+	return atomic.LoadInt64(&apiThrottleCount)
+}
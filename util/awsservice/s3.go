@@ -4,19 +4,28 @@
 package awsservice
 
 import (
-	"log"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 func DownloadFile(bucket, key, outFilename string) error {
-	log.Printf("downloading, %s, %s, to %s...", bucket, key, outFilename)
+	logger.Infof("downloading, %s, %s, to %s...", bucket, key, outFilename)
 	file, err := os.Create(outFilename)
 	if err != nil {
-		log.Printf("error: creating file %s err %v", outFilename, err)
+		logger.Errorf("error: creating file %s err %v", outFilename, err)
 		return err
 	}
 	defer file.Close()
@@ -27,7 +36,101 @@ func DownloadFile(bucket, key, outFilename string) error {
 	downloader := manager.NewDownloader(S3Client)
 	_, err = downloader.Download(ctx, file, &s3GetObjectInput)
 	if err != nil {
-		log.Printf("error: downloading, %v", err)
+		logger.Errorf("error: downloading, %v", err)
 	}
 	return err
 }
+
+// UploadFile uploads the local file to the bucket under the given key
+func UploadFile(bucket, key, inFilename string) error {
+	logger.Infof("uploading, %s, to %s, %s...", inFilename, bucket, key)
+	file, err := os.Open(inFilename)
+	if err != nil {
+		logger.Errorf("error: opening file %s err %v", inFilename, err)
+		return err
+	}
+	defer file.Close()
+
+	uploader := manager.NewUploader(S3Client)
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   file,
+	})
+	if err != nil {
+		logger.Errorf("error: uploading, %v", err)
+	}
+	return err
+}
+
+// PutObject uploads the body to the bucket under the given key
+func PutObject(bucket, key string, body []byte) error {
+	_, err := S3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	})
+	return err
+}
+
+// ListObjects returns the objects under the prefix of the bucket modified at or after since, e.g. the batches a
+// firehose delivery stream delivered during a test
+func ListObjects(bucket, prefix string, since time.Time) ([]types.Object, error) {
+	var objects []types.Object
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(bucket)}
+	if prefix != "" {
+		input.Prefix = aws.String(prefix)
+	}
+	paginator := s3.NewListObjectsV2Paginator(S3Client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			if object.LastModified == nil || object.LastModified.Before(since) {
+				continue
+			}
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// ReadObject returns the content of the object, decompressed when it is gzipped as delivered by a firehose delivery
+// stream with GZIP compression
+func ReadObject(bucket, key string) ([]byte, error) {
+	output, err := S3Client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	body := bufio.NewReader(output.Body)
+	// sniff the gzip magic number rather than trusting the key, firehose only appends .gz when it names the object
+	if magic, err := body.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress s3://%s/%s: %w", bucket, key, err)
+		}
+		defer gz.Close()
+		return io.ReadAll(gz)
+	}
+	return io.ReadAll(body)
+}
+
+// ReadObjectLines returns the non empty lines of the object. Firehose concatenates the records of a batch, records
+// ending with a newline are one per line.
+func ReadObjectLines(bucket, key string) ([]string, error) {
+	content, err := ReadObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,36 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// snsSubjectLimit is the longest subject SNS accepts
+const snsSubjectLimit = 100
+
+// PublishToTopic publishes the message to the SNS topic. The topic region is taken from the ARN so a single topic
+// can collect results from every test region.
+func PublishToTopic(topicArn, subject, message string) error {
+	parsed, err := arn.Parse(topicArn)
+	if err != nil {
+		return fmt.Errorf("invalid sns topic arn %s: %w", topicArn, err)
+	}
+	if len(subject) > snsSubjectLimit {
+		subject = subject[:snsSubjectLimit]
+	}
+
+	_, err = SnsClient.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(topicArn),
+		Subject:  aws.String(subject),
+		Message:  aws.String(message),
+	}, func(o *sns.Options) {
+		o.Region = parsed.Region
+	})
+	return err
+}
@@ -0,0 +1,38 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AssumeRole returns temporary credentials of the role, from the STS endpoint of the region so roles of other
+// partitions can be assumed with credentials of that partition
+func AssumeRole(roleArn, sessionName, region string, duration time.Duration) (aws.Credentials, error) {
+	output, err := StsClient.AssumeRole(ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(roleArn),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int32(int32(duration.Seconds())),
+	}, func(o *sts.Options) {
+		if region != "" {
+			o.Region = region
+		}
+	})
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to assume role %s: %w", roleArn, err)
+	}
+	creds := output.Credentials
+	return aws.Credentials{
+		AccessKeyID:     aws.ToString(creds.AccessKeyId),
+		SecretAccessKey: aws.ToString(creds.SecretAccessKey),
+		SessionToken:    aws.ToString(creds.SessionToken),
+		Source:          roleArn,
+		CanExpire:       true,
+		Expires:         aws.ToTime(creds.Expiration),
+	}, nil
+}
@@ -0,0 +1,318 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package awsservice
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	// RunIdTagKey is set on every resource the tests create, so the sweeper can find resources left by aborted runs
+	RunIdTagKey = "cwagent-test-run-id"
+	// CreatedAtTagKey records the unix time the resource was tagged, for resources without a creation time
+	CreatedAtTagKey = "cwagent-test-created-at"
+	// RetainUntilTagKey records the unix time until which the sweeper keeps a resource a suite retained for
+	// investigation
+	RetainUntilTagKey = "cwagent-test-retain-until"
+)
+
+var (
+	taggedLogGroups   = map[string]struct{}{}
+	taggedLogGroupsMu sync.Mutex
+	// taggedLogGroupOrder lets a retried runner find the log groups its failed attempt used
+	taggedLogGroupOrder []string
+)
+
+// GetRunTags returns the tags every test created resource should carry
+func GetRunTags() map[string]string {
+	return map[string]string{
+		RunIdTagKey:     runid.Get(),
+		CreatedAtTagKey: strconv.FormatInt(time.Now().Unix(), 10),
+	}
+}
+
+// TagLogGroupForRun tags a log group the agent created for the test with the run tags, so the sweeper and the retry
+// of a runner delete it. Only call it for groups unique to the test, never for shared or pre-existing groups.
+// Subsequent calls for the same group are no-ops.
+func TagLogGroupForRun(logGroupName string) {
+	taggedLogGroupsMu.Lock()
+	defer taggedLogGroupsMu.Unlock()
+	if _, ok := taggedLogGroups[logGroupName]; ok {
+		return
+	}
+
+	_, err := CwlClient.TagLogGroup(ctx, &cloudwatchlogs.TagLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+		Tags:         GetRunTags(),
+	})
+	if errors.As(err, &rnf) {
+		// the agent has not written to the group, there is nothing to delete
+		logger.Debugf("Not tagging log group %s, it does not exist", logGroupName)
+		return
+	} else if err != nil {
+		logger.Errorf("Failed to tag log group %s: %v", logGroupName, err)
+		return
+	}
+	recordTaggedLogGroup(logGroupName)
+}
+
+// recordTaggedLogGroup tracks a log group carrying the run tags for DeleteLogGroupsTaggedAfter. The caller holds
+// taggedLogGroupsMu.
+func recordTaggedLogGroup(logGroupName string) {
+	taggedLogGroups[logGroupName] = struct{}{}
+	taggedLogGroupOrder = append(taggedLogGroupOrder, logGroupName)
+}
+
+// TaggedLogGroupCount returns the number of log groups tagged so far, to be passed to DeleteLogGroupsTaggedAfter
+func TaggedLogGroupCount() int {
+	taggedLogGroupsMu.Lock()
+	defer taggedLogGroupsMu.Unlock()
+	return len(taggedLogGroupOrder)
+}
+
+// DeleteLogGroupsTaggedAfter deletes the log groups tagged after the count returned by TaggedLogGroupCount, so the
+// next attempt of a runner starts with fresh log groups
+func DeleteLogGroupsTaggedAfter(count int) {
+	taggedLogGroupsMu.Lock()
+	defer taggedLogGroupsMu.Unlock()
+	if count >= len(taggedLogGroupOrder) {
+		return
+	}
+	for _, logGroupName := range taggedLogGroupOrder[count:] {
+		DeleteLogGroup(logGroupName)
+		delete(taggedLogGroups, logGroupName)
+	}
+	taggedLogGroupOrder = taggedLogGroupOrder[:count]
+}
+
+// LogGroupsTaggedAfter returns the log groups tagged after the count returned by TaggedLogGroupCount, e.g. to
+// retain the log groups of a failed runner
+func LogGroupsTaggedAfter(count int) []string {
+	taggedLogGroupsMu.Lock()
+	defer taggedLogGroupsMu.Unlock()
+	if count >= len(taggedLogGroupOrder) {
+		return nil
+	}
+	return append([]string(nil), taggedLogGroupOrder[count:]...)
+}
+
+// RetainLogGroup tags the log group so the sweeper keeps it until the time, even once it is older than the sweep
+func RetainLogGroup(logGroupName string, until time.Time) error {
+	_, err := CwlClient.TagLogGroup(ctx, &cloudwatchlogs.TagLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+		Tags:         map[string]string{RetainUntilTagKey: strconv.FormatInt(until.Unix(), 10)},
+	})
+	return err
+}
+
+// isRetained reports whether the tags retain the resource past now
+func isRetained(tags map[string]string, now time.Time) (time.Time, bool) {
+	value, ok := tags[RetainUntilTagKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	until := time.Unix(seconds, 0)
+	return until, until.After(now)
+}
+
+// TagAlarmForRun tags the alarm with the run tags
+func TagAlarmForRun(alarmArn string) error {
+	var tags []cwtypes.Tag
+	for k, v := range GetRunTags() {
+		tags = append(tags, cwtypes.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	_, err := CwmClient.TagResource(ctx, &cloudwatch.TagResourceInput{
+		ResourceARN: aws.String(alarmArn),
+		Tags:        tags,
+	})
+	return err
+}
+
+// TagInstancesForRun tags the instances with the run tags
+func TagInstancesForRun(instanceIds []string) error {
+	var tags []ec2types.Tag
+	for k, v := range GetRunTags() {
+		tags = append(tags, ec2types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	_, err := Ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: instanceIds,
+		Tags:      tags,
+	})
+	return err
+}
+
+// SweepLogGroups deletes the log groups tagged by a test run that were created before now - olderThan, except the
+// ones retained for investigation until later. It returns the names of the swept log groups. With dryRun set,
+// nothing is deleted.
+func SweepLogGroups(olderThan time.Duration, dryRun bool) ([]string, error) {
+	return sweepLogGroups(time.Now().Add(-olderThan).UnixMilli(), dryRun, func(string) bool {
+		return true
+	})
+}
+
+// SweepLogGroupsForRun deletes the log groups tagged by the given run, regardless of their age, except the ones
+// retained for investigation
+func SweepLogGroupsForRun(runId string, dryRun bool) ([]string, error) {
+	return sweepLogGroups(math.MaxInt64, dryRun, func(id string) bool {
+		return id == runId
+	})
+}
+
+// sweepLogGroups deletes the log groups created before the cutoff (unix millis) whose run id tag matches
+func sweepLogGroups(cutoff int64, dryRun bool, matchRunId func(string) bool) ([]string, error) {
+	var swept []string
+
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(CwlClient, &cloudwatchlogs.DescribeLogGroupsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return swept, err
+		}
+
+		for _, group := range output.LogGroups {
+			if group.CreationTime == nil || *group.CreationTime > cutoff {
+				continue
+			}
+			tags, err := CwlClient.ListTagsLogGroup(ctx, &cloudwatchlogs.ListTagsLogGroupInput{LogGroupName: group.LogGroupName})
+			if err != nil {
+				logger.Errorf("Failed to list tags of log group %s: %v", *group.LogGroupName, err)
+				continue
+			}
+			if id, ok := tags.Tags[RunIdTagKey]; !ok || !matchRunId(id) {
+				continue
+			}
+			if until, retained := isRetained(tags.Tags, time.Now()); retained {
+				logger.Infof("Keeping log group %s, it is retained for investigation until %s", *group.LogGroupName, until.Format(time.RFC3339))
+				continue
+			}
+
+			swept = append(swept, *group.LogGroupName)
+			if !dryRun {
+				DeleteLogGroup(*group.LogGroupName)
+			}
+		}
+	}
+	return swept, nil
+}
+
+// SweepAlarms deletes the alarms of a test run that were last updated before now - olderThan. The alarms are found
+// by their run id tag, or by the run id in their name when tagging them failed.
+func SweepAlarms(olderThan time.Duration, dryRun bool) ([]string, error) {
+	cutoff := time.Now().Add(-olderThan)
+	var swept []string
+
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(CwmClient, &cloudwatch.DescribeAlarmsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return swept, err
+		}
+
+		for _, alarm := range output.MetricAlarms {
+			if alarm.AlarmConfigurationUpdatedTimestamp == nil || alarm.AlarmConfigurationUpdatedTimestamp.After(cutoff) {
+				continue
+			}
+			if namedForRunBefore(*alarm.AlarmName, cutoff) {
+				swept = append(swept, *alarm.AlarmName)
+				continue
+			}
+			tags, err := CwmClient.ListTagsForResource(ctx, &cloudwatch.ListTagsForResourceInput{ResourceARN: alarm.AlarmArn})
+			if err != nil {
+				logger.Errorf("Failed to list tags of alarm %s: %v", *alarm.AlarmName, err)
+				continue
+			}
+			for _, tag := range tags.Tags {
+				if tag.Key != nil && *tag.Key == RunIdTagKey {
+					swept = append(swept, *alarm.AlarmName)
+					break
+				}
+			}
+		}
+	}
+
+	if !dryRun && len(swept) > 0 {
+		// DeleteAlarms accepts at most 100 names per call
+		for start := 0; start < len(swept); start += 100 {
+			end := start + 100
+			if end > len(swept) {
+				end = len(swept)
+			}
+			if _, err := CwmClient.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{AlarmNames: swept[start:end]}); err != nil {
+				return swept, err
+			}
+		}
+	}
+	return swept, nil
+}
+
+// namedForRunBefore returns whether the resource was named by runid.Name for a run started before the cutoff
+func namedForRunBefore(name string, cutoff time.Time) bool {
+	if !strings.HasPrefix(name, runid.NamePrefix) {
+		return false
+	}
+	runId, ok := runid.FromName(name)
+	if !ok {
+		return false
+	}
+	started, err := runid.Parse(runId)
+	return err == nil && started.Before(cutoff)
+}
+
+// SweepInstances terminates the instances tagged by a test run which started before now - olderThan. An instance
+// reused by several runs is tagged again by each of them, so it is only swept once it was left unused.
+func SweepInstances(olderThan time.Duration, dryRun bool) ([]string, error) {
+	cutoff := time.Now().Add(-olderThan)
+	var swept []string
+
+	instances, err := DescribeInstancesByFilters([]ec2types.Filter{
+		{Name: aws.String("tag-key"), Values: []string{RunIdTagKey}},
+		{Name: aws.String("instance-state-name"), Values: []string{"pending", "running", "stopped"}},
+	})
+	if err != nil {
+		return swept, err
+	}
+	for _, instance := range instances {
+		if instanceRunStartedBefore(instance.Tags, cutoff) {
+			swept = append(swept, *instance.InstanceId)
+		}
+	}
+
+	if !dryRun && len(swept) > 0 {
+		if _, err := Ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{InstanceIds: swept}); err != nil {
+			return swept, err
+		}
+	}
+	return swept, nil
+}
+
+// instanceRunStartedBefore returns whether the run the instance was last tagged by started before the cutoff
+func instanceRunStartedBefore(tags []ec2types.Tag, cutoff time.Time) bool {
+	for _, tag := range tags {
+		if aws.ToString(tag.Key) != RunIdTagKey {
+			continue
+		}
+		started, err := runid.Parse(aws.ToString(tag.Value))
+		return err == nil && started.Before(cutoff)
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package cgroup reads the cgroup limits a process runs under, e.g. the agent in a systemd unit or a container, from
+// the hierarchy of the host, whether it is cgroup v1 or v2.
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// unlimitedV1 is the smallest memory.limit_in_bytes v1 reports for no limit, the page counter max rounded down to
+// the page size
+const unlimitedV1 = 1 << 62
+
+var (
+	root     = "/sys/fs/cgroup"
+	procRoot = "/proc"
+)
+
+// Limits are the limits of a cgroup, zero when unlimited
+type Limits struct {
+	// MemoryMax is in bytes
+	MemoryMax int64
+	// CPUs is the quota in CPUs, e.g. 0.5 for 50ms every 100ms
+	CPUs float64
+}
+
+// IsV2 returns whether the host mounts the unified cgroup v2 hierarchy
+func IsV2() bool {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	return err == nil
+}
+
+// ProcessLimits returns the limits of the cgroup of the process
+func ProcessLimits(pid int) (Limits, error) {
+	paths, err := processCgroups(pid)
+	if err != nil {
+		return Limits{}, err
+	}
+	if IsV2() {
+		return readV2(filepath.Join(root, paths[""]))
+	}
+	return readV1(filepath.Join(root, "memory", paths["memory"]), filepath.Join(root, "cpu", paths["cpu"]))
+}
+
+// processCgroups returns the cgroup of the process by controller, the unified hierarchy of v2 has no controller
+func processCgroups(pid int) (map[string]string, error) {
+	f, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	paths := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path, e.g. 4:cpu,cpuacct:/docker/<id> or 0::/system.slice/x.service
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[1] == "" {
+			paths[""] = fields[2]
+			continue
+		}
+		for _, controller := range strings.Split(fields[1], ",") {
+			paths[controller] = fields[2]
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("process %d is in no cgroup", pid)
+	}
+	return paths, nil
+}
+
+// readV2 reads memory.max, e.g. max or 268435456, and cpu.max, e.g. max 100000 or 50000 100000
+func readV2(dir string) (Limits, error) {
+	var limits Limits
+	memoryMax, err := readFile(filepath.Join(dir, "memory.max"))
+	if err != nil {
+		return limits, err
+	}
+	if memoryMax != "max" {
+		if limits.MemoryMax, err = strconv.ParseInt(memoryMax, 10, 64); err != nil {
+			return limits, fmt.Errorf("unexpected memory.max %q: %w", memoryMax, err)
+		}
+	}
+	cpuMax, err := readFile(filepath.Join(dir, "cpu.max"))
+	if err != nil {
+		return limits, err
+	}
+	fields := strings.Fields(cpuMax)
+	if len(fields) != 2 {
+		return limits, fmt.Errorf("unexpected cpu.max %q", cpuMax)
+	}
+	if fields[0] != "max" {
+		if limits.CPUs, err = quota(fields[0], fields[1]); err != nil {
+			return limits, fmt.Errorf("unexpected cpu.max %q: %w", cpuMax, err)
+		}
+	}
+	return limits, nil
+}
+
+// readV1 reads memory.limit_in_bytes, cpu.cfs_quota_us and cpu.cfs_period_us, the quota is -1 when unlimited
+func readV1(memoryDir, cpuDir string) (Limits, error) {
+	var limits Limits
+	limit, err := readFile(filepath.Join(memoryDir, "memory.limit_in_bytes"))
+	if err != nil {
+		return limits, err
+	}
+	if limits.MemoryMax, err = strconv.ParseInt(limit, 10, 64); err != nil {
+		return limits, fmt.Errorf("unexpected memory.limit_in_bytes %q: %w", limit, err)
+	}
+	if limits.MemoryMax >= unlimitedV1 {
+		limits.MemoryMax = 0
+	}
+	cfsQuota, err := readFile(filepath.Join(cpuDir, "cpu.cfs_quota_us"))
+	if err != nil {
+		return limits, err
+	}
+	if cfsQuota == "-1" {
+		return limits, nil
+	}
+	period, err := readFile(filepath.Join(cpuDir, "cpu.cfs_period_us"))
+	if err != nil {
+		return limits, err
+	}
+	if limits.CPUs, err = quota(cfsQuota, period); err != nil {
+		return limits, fmt.Errorf("unexpected cpu.cfs_quota_us %q or cpu.cfs_period_us %q: %w", cfsQuota, period, err)
+	}
+	return limits, nil
+}
+
+func quota(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, err
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p <= 0 {
+		return 0, fmt.Errorf("the period is %s", period)
+	}
+	return q / p, nil
+}
+
+func readFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
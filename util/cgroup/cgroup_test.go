@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cgroup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHost points the package at a temporary hierarchy and /proc, with the files relative to it
+func fakeHost(t *testing.T, files map[string]string) {
+	dir := t.TempDir()
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	oldRoot, oldProcRoot := root, procRoot
+	root, procRoot = filepath.Join(dir, "cgroup"), filepath.Join(dir, "proc")
+	t.Cleanup(func() { root, procRoot = oldRoot, oldProcRoot })
+}
+
+func TestProcessLimitsV2(t *testing.T) {
+	fakeHost(t, map[string]string{
+		"cgroup/cgroup.controllers": "cpu memory",
+		"proc/42/cgroup":            "0::/system.slice/amazon-cloudwatch-agent.service\n",
+		"cgroup/system.slice/amazon-cloudwatch-agent.service/memory.max": "268435456\n",
+		"cgroup/system.slice/amazon-cloudwatch-agent.service/cpu.max":    "25000 100000\n",
+		"proc/43/cgroup":               "0::/user.slice\n",
+		"cgroup/user.slice/memory.max": "max\n",
+		"cgroup/user.slice/cpu.max":    "max 100000\n",
+		"proc/44/cgroup":               "0::/broken\n",
+		"cgroup/broken/memory.max":     "max\n",
+		"cgroup/broken/cpu.max":        "100000\n",
+	})
+	assert.True(t, IsV2())
+
+	limits, err := ProcessLimits(42)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{MemoryMax: 256 * 1024 * 1024, CPUs: 0.25}, limits)
+
+	limits, err = ProcessLimits(43)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, limits)
+
+	_, err = ProcessLimits(44)
+	assert.ErrorContains(t, err, "cpu.max")
+
+	_, err = ProcessLimits(45)
+	assert.Error(t, err)
+}
+
+func TestProcessLimitsV1(t *testing.T) {
+	fakeHost(t, map[string]string{
+		"proc/42/cgroup": "12:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n",
+		"cgroup/memory/docker/abc/memory.limit_in_bytes": "268435456\n",
+		"cgroup/cpu/docker/abc/cpu.cfs_quota_us":         "50000\n",
+		"cgroup/cpu/docker/abc/cpu.cfs_period_us":        "100000\n",
+		"proc/43/cgroup":                      "12:memory:/\n4:cpu,cpuacct:/\n",
+		"cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n",
+		"cgroup/cpu/cpu.cfs_quota_us":         "-1\n",
+	})
+	assert.False(t, IsV2())
+
+	limits, err := ProcessLimits(42)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{MemoryMax: 256 * 1024 * 1024, CPUs: 0.5}, limits)
+
+	limits, err = ProcessLimits(43)
+	require.NoError(t, err)
+	assert.Equal(t, Limits{}, limits)
+}
@@ -0,0 +1,234 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package chaos
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// EventsFileName is the file the chaos-scheduler command records the events in, in the directory of the soak logs
+const EventsFileName = "chaos_events.jsonl"
+
+// Fault disturbs the host the agent runs on, e.g. restarts the agent or blocks the network for a while. Inject only
+// returns once the host is back to normal.
+type Fault struct {
+	Name   string
+	Inject func() error
+}
+
+// Event is a fault the scheduler injected, the window it affected the host and whether injecting it failed
+type Event struct {
+	Fault string    `json:"fault"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Error string    `json:"error,omitempty"`
+}
+
+func (e Event) String() string {
+	s := fmt.Sprintf("%s from %s to %s", e.Fault, e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339))
+	if e.Error != "" {
+		s += " failed: " + e.Error
+	}
+	return s
+}
+
+// Scheduler injects a random fault at random times, on average every interval, until it is stopped. The events are
+// written as JSON lines to the record as they happen, so they survive a scheduler that is killed with the soak host.
+type Scheduler struct {
+	faults   []Fault
+	interval time.Duration
+	random   *rand.Rand
+	record   io.Writer
+
+	mu     sync.Mutex
+	events []Event
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Start schedules the faults, the same seed picks the same faults after the same delays
+func Start(faults []Fault, interval time.Duration, seed int64, record io.Writer) *Scheduler {
+	s := &Scheduler{
+		faults:   faults,
+		interval: interval,
+		random:   rand.New(rand.NewSource(seed)),
+		record:   record,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	logger.Infof("Injecting %d faults every %s on average with seed %d", len(faults), interval, seed)
+	go s.run()
+	return s
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	if len(s.faults) == 0 {
+		return
+	}
+	for {
+		// uniform between half and one and a half interval, so the faults do not line up with the collection
+		delay := s.interval/2 + time.Duration(s.random.Int63n(int64(s.interval)+1))
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(delay):
+		}
+		s.inject(s.faults[s.random.Intn(len(s.faults))])
+	}
+}
+
+func (s *Scheduler) inject(f Fault) {
+	event := Event{Fault: f.Name, Start: time.Now()}
+	logger.Infof("Injecting fault %s", f.Name)
+	if err := f.Inject(); err != nil {
+		logger.Errorf("Injecting fault %s failed: %v", f.Name, err)
+		event.Error = err.Error()
+	}
+	event.End = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	if s.record != nil {
+		if err := json.NewEncoder(s.record).Encode(event); err != nil {
+			logger.Errorf("Recording fault %s failed: %v", f.Name, err)
+		}
+	}
+}
+
+// Stop waits for the fault being injected and returns the events. It is safe to call more than once.
+func (s *Scheduler) Stop() []Event {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// ReadEvents reads the JSON lines the scheduler recorded
+func ReadEvents(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("invalid event %q: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// Gap is a window without data, e.g. consecutive periods of a metric without a datapoint
+type Gap struct {
+	Start time.Time
+	End   time.Time
+}
+
+// MissingPeriods merges the periods between start and end which have no value into gaps, values are keyed by the
+// start of their period like metric.FetchPeriods returns them
+func MissingPeriods(values map[time.Time]float64, start, end time.Time, period time.Duration) []Gap {
+	var gaps []Gap
+	for t := start.Truncate(period); t.Add(period).Before(end) || t.Add(period).Equal(end); t = t.Add(period) {
+		if _, ok := values[t.UTC()]; ok {
+			continue
+		}
+		if n := len(gaps); n > 0 && gaps[n-1].End.Equal(t) {
+			gaps[n-1].End = t.Add(period)
+		} else {
+			gaps = append(gaps, Gap{Start: t, End: t.Add(period)})
+		}
+	}
+	return gaps
+}
+
+// Correlation is a gap and the injected faults which overlap it
+type Correlation struct {
+	Gap    Gap
+	Events []Event
+}
+
+// Explained is true when a fault was injected during the gap, a gap without one is data the agent lost on its own
+func (c Correlation) Explained() bool {
+	return len(c.Events) > 0
+}
+
+// Correlate matches each gap with the events that overlap it. The slack widens the events, the data of a fault that
+// ended shortly before a period can still be missing from it.
+func Correlate(gaps []Gap, events []Event, slack time.Duration) []Correlation {
+	correlations := make([]Correlation, len(gaps))
+	for i, gap := range gaps {
+		correlations[i].Gap = gap
+		for _, event := range events {
+			if event.Start.Add(-slack).Before(gap.End) && event.End.Add(slack).After(gap.Start) {
+				correlations[i].Events = append(correlations[i].Events, event)
+			}
+		}
+	}
+	return correlations
+}
+
+// Report summarizes the faults injected during a run and the gaps they explain
+type Report struct {
+	Events       []Event
+	Correlations []Correlation
+}
+
+// Unexplained are the gaps no injected fault overlaps
+func (r Report) Unexplained() []Gap {
+	var gaps []Gap
+	for _, c := range r.Correlations {
+		if !c.Explained() {
+			gaps = append(gaps, c.Gap)
+		}
+	}
+	return gaps
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	counts := map[string]int{}
+	for _, event := range r.Events {
+		counts[event.Fault]++
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "%d faults injected", len(r.Events))
+	for _, name := range names {
+		fmt.Fprintf(&b, ", %d %s", counts[name], name)
+	}
+	fmt.Fprintf(&b, "\n%d gaps, %d not explained by a fault\n", len(r.Correlations), len(r.Unexplained()))
+	for _, c := range r.Correlations {
+		fmt.Fprintf(&b, "  gap from %s to %s: ", c.Gap.Start.Format(time.RFC3339), c.Gap.End.Format(time.RFC3339))
+		if !c.Explained() {
+			b.WriteString("no injected fault\n")
+			continue
+		}
+		faults := make([]string, len(c.Events))
+		for i, event := range c.Events {
+			faults[i] = event.Fault
+		}
+		b.WriteString(strings.Join(faults, ", ") + "\n")
+	}
+	return b.String()
+}
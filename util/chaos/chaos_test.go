@@ -0,0 +1,82 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package chaos
+
+import (
+	"bytes"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRecordsEvents(t *testing.T) {
+	var injected int64
+	faults := []Fault{
+		{Name: "ok", Inject: func() error { atomic.AddInt64(&injected, 1); return nil }},
+		{Name: "failing", Inject: func() error { atomic.AddInt64(&injected, 1); return errors.New("iptables not found") }},
+	}
+	var record bytes.Buffer
+	s := Start(faults, 2*time.Millisecond, 1, &record)
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&injected) >= 5 }, time.Second, time.Millisecond)
+	events := s.Stop()
+	assert.Equal(t, events, s.Stop())
+
+	recorded, err := ReadEvents(&record)
+	require.NoError(t, err)
+	assert.Len(t, recorded, len(events))
+	for i, event := range recorded {
+		assert.Equal(t, events[i].Fault, event.Fault)
+		assert.True(t, event.Start.Equal(events[i].Start))
+		assert.False(t, event.End.Before(event.Start))
+		if event.Fault == "failing" {
+			assert.Equal(t, "iptables not found", event.Error)
+		} else {
+			assert.Empty(t, event.Error)
+		}
+	}
+}
+
+func TestSchedulerWithoutFaults(t *testing.T) {
+	assert.Empty(t, Start(nil, time.Millisecond, 1, nil).Stop())
+}
+
+func TestMissingPeriods(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	values := map[time.Time]float64{
+		start:                      1,
+		start.Add(time.Minute):     1,
+		start.Add(4 * time.Minute): 1,
+	}
+	assert.Equal(t, []Gap{
+		{Start: start.Add(2 * time.Minute), End: start.Add(4 * time.Minute)},
+		{Start: start.Add(5 * time.Minute), End: start.Add(6 * time.Minute)},
+	}, MissingPeriods(values, start, start.Add(6*time.Minute), time.Minute))
+	assert.Empty(t, MissingPeriods(values, start, start.Add(2*time.Minute), time.Minute))
+}
+
+func TestCorrelate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	restart := Event{Fault: "agent_restart", Start: start.Add(50 * time.Second), End: start.Add(55 * time.Second)}
+	block := Event{Fault: "network_block", Start: start.Add(10 * time.Minute), End: start.Add(10*time.Minute + 30*time.Second)}
+	gaps := []Gap{
+		{Start: start.Add(time.Minute), End: start.Add(2 * time.Minute)},
+		{Start: start.Add(5 * time.Minute), End: start.Add(6 * time.Minute)},
+	}
+
+	report := Report{Events: []Event{restart, block}, Correlations: Correlate(gaps, []Event{restart, block}, 10*time.Second)}
+	assert.Equal(t, []Event{restart}, report.Correlations[0].Events)
+	assert.False(t, report.Correlations[1].Explained())
+	assert.Equal(t, []Gap{gaps[1]}, report.Unexplained())
+	assert.Equal(t, `2 faults injected, 1 agent_restart, 1 network_block
+2 gaps, 1 not explained by a fault
+  gap from 2024-01-01T00:01:00Z to 2024-01-01T00:02:00Z: agent_restart
+  gap from 2024-01-01T00:05:00Z to 2024-01-01T00:06:00Z: no injected fault
+`, report.String())
+
+	assert.Empty(t, Correlate(gaps, []Event{restart}, 0)[0].Events)
+}
@@ -0,0 +1,78 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package chaos
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	// networkBlock is how long the outbound HTTPS traffic is dropped, shorter than the retries of the agent
+	networkBlock = 30 * time.Second
+	// clockJump is how far the clock is moved forward until it is synced again
+	clockJump     = 5 * time.Minute
+	clockJumpHold = time.Minute
+)
+
+// DefaultFaults restarts the agent, blocks the outbound HTTPS traffic for a while, rotates the log files matching the
+// glob and moves the clock forward for a while
+func DefaultFaults(logGlob string) []Fault {
+	return []Fault{
+		{Name: "agent_restart", Inject: restartAgent},
+		{Name: "network_block", Inject: blockNetwork},
+		{Name: "log_rotation", Inject: func() error { return rotateLogs(logGlob) }},
+		{Name: "clock_jump", Inject: jumpClock},
+	}
+}
+
+func restartAgent() error {
+	_, err := common.RunCommand("sudo systemctl restart " + common.AgentServiceName)
+	return err
+}
+
+func blockNetwork() error {
+	const rule = "OUTPUT -p tcp --dport 443 -j DROP"
+	if _, err := common.RunCommand("sudo iptables -I " + rule); err != nil {
+		return err
+	}
+	time.Sleep(networkBlock)
+	_, err := common.RunCommand("sudo iptables -D " + rule)
+	return err
+}
+
+// rotateLogs copies and truncates the files like logrotate with copytruncate, the generators keep their file open
+func rotateLogs(glob string) error {
+	files, err := filepath.Glob(glob)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no log files match %s", glob)
+	}
+	for _, f := range files {
+		if _, err = common.RunCommand(fmt.Sprintf("sudo cp %[1]s %[1]s.1 && sudo truncate -s 0 %[1]s", f)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jumpClock moves the clock forward and syncs it again after a while, with chrony when it runs
+func jumpClock() error {
+	if _, err := common.RunCommand(fmt.Sprintf("sudo date -s '+%d seconds'", int(clockJump.Seconds()))); err != nil {
+		return err
+	}
+	time.Sleep(clockJumpHold)
+	if _, err := common.RunCommand("sudo chronyc makestep"); err == nil {
+		return nil
+	}
+	_, err := common.RunCommand(fmt.Sprintf("sudo date -s '-%d seconds'", int(clockJump.Seconds())))
+	return err
+}
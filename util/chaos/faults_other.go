@@ -0,0 +1,11 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !linux && !windows
+
+package chaos
+
+// DefaultFaults is empty, the scheduler only runs on the soak hosts of Linux and Windows
+func DefaultFaults(string) []Fault {
+	return nil
+}
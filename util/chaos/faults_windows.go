@@ -0,0 +1,43 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package chaos
+
+import (
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+const (
+	// networkBlock is how long the outbound HTTPS traffic is dropped, shorter than the retries of the agent
+	networkBlock     = 30 * time.Second
+	networkBlockRule = "cwagent-chaos-network-block"
+)
+
+// DefaultFaults restarts the agent and blocks the outbound HTTPS traffic for a while. The log rotation and clock jump
+// of Linux are left out, the Windows agent reads event logs and the time service resyncs on its own schedule.
+func DefaultFaults(string) []Fault {
+	return []Fault{
+		{Name: "agent_restart", Inject: restartAgent},
+		{Name: "network_block", Inject: blockNetwork},
+	}
+}
+
+func restartAgent() error {
+	_, err := common.RunCommand("Restart-Service AmazonCloudWatchAgent")
+	return err
+}
+
+func blockNetwork() error {
+	_, err := common.RunCommand("New-NetFirewallRule -DisplayName " + networkBlockRule +
+		" -Direction Outbound -Protocol TCP -RemotePort 443 -Action Block")
+	if err != nil {
+		return err
+	}
+	time.Sleep(networkBlock)
+	_, err = common.RunCommand("Remove-NetFirewallRule -DisplayName " + networkBlockRule)
+	return err
+}
@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package cleanup decides whether a suite deletes its resources when it tears down, so the log groups of a failed
+// run can be kept for investigation while a successful run cleans up right away.
+package cleanup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Policy is when a suite deletes its resources
+type Policy string
+
+const (
+	// Always deletes the resources whether the suite passed or failed
+	Always Policy = "always"
+	// OnSuccess deletes the resources of a passed suite and retains the ones of a failed suite
+	OnSuccess Policy = "on-success"
+	// Never retains the resources, e.g. to debug a suite which passes
+	Never Policy = "never"
+)
+
+// DefaultRetention is how long the sweeper keeps retained resources
+const DefaultRetention = 24 * time.Hour
+
+var (
+	defaultPolicy = OnSuccess
+	policies      = map[string]Policy{}
+	retention     = DefaultRetention
+)
+
+// ParsePolicy parses always, on-success or never
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(strings.ToLower(strings.TrimSpace(s))); p {
+	case Always, OnSuccess, Never:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown cleanup policy %q, expected %s, %s or %s", s, Always, OnSuccess, Never)
+	}
+}
+
+// ParsePolicies parses the comma-delimited suite=policy of the suites which override the default policy, ex
+// LogGlob=never,Smoke=always
+func ParsePolicies(s string) (map[string]Policy, error) {
+	out := map[string]Policy{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		suite, policy, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(suite) == "" {
+			return nil, fmt.Errorf("%q is not suite=policy", pair)
+		}
+		p, err := ParsePolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		out[strings.ToLower(strings.TrimSpace(suite))] = p
+	}
+	return out, nil
+}
+
+// Configure sets the policy of the suites, the overrides of some of them by name and how long retained resources
+// are kept. A zero retention keeps the DefaultRetention.
+func Configure(policy Policy, overrides map[string]Policy, retainFor time.Duration) {
+	defaultPolicy = policy
+	policies = map[string]Policy{}
+	for suite, p := range overrides {
+		policies[strings.ToLower(suite)] = p
+	}
+	retention = retainFor
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+}
+
+// PolicyOf is the policy of the suite, it matches the name case-insensitively, the same way the plugins flag does
+func PolicyOf(suite string) Policy {
+	if p, ok := policies[strings.ToLower(suite)]; ok {
+		return p
+	}
+	return defaultPolicy
+}
+
+// ShouldClean reports whether the suite deletes its resources as it tears down
+func ShouldClean(suite string, passed bool) bool {
+	switch PolicyOf(suite) {
+	case Always:
+		return true
+	case Never:
+		return false
+	default:
+		return passed
+	}
+}
+
+// RetainUntil is the time the sweeper keeps the resources retained now until
+func RetainUntil() time.Time {
+	return time.Now().Add(retention)
+}
@@ -0,0 +1,44 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package cleanup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicies(t *testing.T) {
+	p, err := ParsePolicy(" On-Success ")
+	require.NoError(t, err)
+	assert.Equal(t, OnSuccess, p)
+	_, err = ParsePolicy("sometimes")
+	assert.ErrorContains(t, err, "unknown cleanup policy")
+
+	overrides, err := ParsePolicies("LogGlob=never, Smoke=always,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Policy{"logglob": Never, "smoke": Always}, overrides)
+	_, err = ParsePolicies("LogGlob")
+	assert.Error(t, err)
+	_, err = ParsePolicies("LogGlob=sometimes")
+	assert.Error(t, err)
+}
+
+func TestShouldClean(t *testing.T) {
+	defer Configure(OnSuccess, nil, 0)
+
+	Configure(OnSuccess, map[string]Policy{"LogGlob": Never, "smoke": Always}, time.Hour)
+	assert.True(t, ShouldClean("Mem", true))
+	assert.False(t, ShouldClean("Mem", false), "a failed suite is retained on-success")
+	assert.False(t, ShouldClean("logglob", true))
+	assert.True(t, ShouldClean("Smoke", false))
+	assert.Equal(t, Never, PolicyOf("LOGGLOB"))
+	assert.WithinDuration(t, time.Now().Add(time.Hour), RetainUntil(), time.Second)
+
+	Configure(Always, nil, 0)
+	assert.True(t, ShouldClean("Mem", false))
+	assert.WithinDuration(t, time.Now().Add(DefaultRetention), RetainUntil(), time.Second)
+}
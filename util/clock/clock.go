@@ -0,0 +1,129 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package clock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// AmazonTimeSyncServer is the NTP server of the Amazon Time Sync Service, reachable from every EC2 instance
+const AmazonTimeSyncServer = "169.254.169.123:123"
+
+// ntpEpochOffset is the number of seconds between the NTP epoch, 1900, and the unix epoch
+const ntpEpochOffset = 2208988800
+
+var (
+	mu            sync.RWMutex
+	skewTolerance time.Duration
+	now           = time.Now
+)
+
+// Configure sets how far the host clock may drift from the clock CloudWatch stamps ingested data with. Every query
+// window of the framework is widened by the tolerance on both ends, so a drifting host does not miss its own events.
+func Configure(tolerance time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tolerance < 0 {
+		tolerance = -tolerance
+	}
+	skewTolerance = tolerance
+}
+
+func SkewTolerance() time.Duration {
+	mu.RLock()
+	defer mu.RUnlock()
+	return skewTolerance
+}
+
+// Now is the time source of the framework, time.Now unless replaced in tests with SetNow
+func Now() time.Time {
+	mu.RLock()
+	defer mu.RUnlock()
+	return now()
+}
+
+// SetNow replaces the time source and returns a func restoring the previous one
+func SetNow(f func() time.Time) func() {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := now
+	now = f
+	return func() {
+		mu.Lock()
+		defer mu.Unlock()
+		now = previous
+	}
+}
+
+// Since is the start of a query window beginning at t, moved back by the skew tolerance
+func Since(t time.Time) time.Time {
+	return t.Add(-SkewTolerance())
+}
+
+// Until is the end of a query window ending at t, moved forward by the skew tolerance
+func Until(t time.Time) time.Time {
+	return t.Add(SkewTolerance())
+}
+
+// Offset queries the SNTP server, ex AmazonTimeSyncServer, and returns how far the host clock is ahead of it
+func Offset(server string, timeout time.Duration) (time.Duration, error) {
+	conn, err := net.DialTimeout("udp", server, timeout)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, err
+	}
+
+	// a client request is a 48 byte packet with leap indicator 0, version 3 and mode 3 (client) in the first byte
+	request := make([]byte, 48)
+	request[0] = 0x1B
+	sent := time.Now()
+	if _, err = conn.Write(request); err != nil {
+		return 0, err
+	}
+	response := make([]byte, 48)
+	n, err := conn.Read(response)
+	if err != nil {
+		return 0, err
+	}
+	received := time.Now()
+	if n < 48 {
+		return 0, fmt.Errorf("short ntp response of %d bytes from %s", n, server)
+	}
+
+	// the transmit timestamp of the server is at byte 40, seconds then the fraction of a second
+	seconds := binary.BigEndian.Uint32(response[40:44])
+	fraction := binary.BigEndian.Uint32(response[44:48])
+	serverTime := time.Unix(int64(seconds)-ntpEpochOffset, int64(fraction)*int64(time.Second)>>32)
+	// the server time is assumed to be taken half way through the round trip
+	midpoint := sent.Add(received.Sub(sent) / 2)
+	return midpoint.Sub(serverTime), nil
+}
+
+// CheckOffset warns when the host clock is further from the SNTP server than the skew tolerance, since validations of
+// time windows are then likely to miss events. Failing to reach the server is only logged, it is a sanity check.
+func CheckOffset(server string) {
+	offset, err := Offset(server, 5*time.Second)
+	if err != nil {
+		logger.Warnf("Could not check the clock offset against %s: %v", server, err)
+		return
+	}
+	abs := offset
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs > SkewTolerance() {
+		logger.Warnf("The host clock is %s off from %s, more than the skew tolerance of %s", offset.Round(time.Millisecond), server, SkewTolerance())
+		return
+	}
+	logger.Debugf("The host clock is %s off from %s", offset.Round(time.Millisecond), server)
+}
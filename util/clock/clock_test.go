@@ -0,0 +1,59 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package clock
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowIsWidenedByTolerance(t *testing.T) {
+	defer Configure(0)
+	start := time.Unix(1000, 0)
+
+	assert.Equal(t, start, Since(start))
+	assert.Equal(t, start, Until(start))
+
+	Configure(-5 * time.Second)
+	assert.Equal(t, 5*time.Second, SkewTolerance())
+	assert.Equal(t, start.Add(-5*time.Second), Since(start))
+	assert.Equal(t, start.Add(5*time.Second), Until(start))
+}
+
+func TestSetNow(t *testing.T) {
+	fixed := time.Unix(1000, 0)
+	restore := SetNow(func() time.Time { return fixed })
+	assert.Equal(t, fixed, Now())
+	restore()
+	assert.NotEqual(t, fixed, Now())
+}
+
+func TestOffset(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// a server whose clock is a minute behind the host
+	go func() {
+		request := make([]byte, 48)
+		_, addr, err := conn.ReadFrom(request)
+		if err != nil {
+			return
+		}
+		serverTime := time.Now().Add(-time.Minute)
+		response := make([]byte, 48)
+		binary.BigEndian.PutUint32(response[40:44], uint32(serverTime.Unix()+ntpEpochOffset))
+		binary.BigEndian.PutUint32(response[44:48], uint32((int64(serverTime.Nanosecond())<<32)/int64(time.Second)))
+		conn.WriteTo(response, addr)
+	}()
+
+	offset, err := Offset(conn.LocalAddr().String(), time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, offset, float64(100*time.Millisecond))
+}
@@ -0,0 +1,35 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/agentdebug"
+)
+
+// ApplyAgentDebug sets agent.debug and agent.aws_sdk_log_level of the agent config at the path as configured with
+// agentdebug.Configure. The config is left as is when verbose logging is disabled.
+func ApplyAgentDebug(configPath string) error {
+	if !agentdebug.Enabled() {
+		return nil
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var config map[string]interface{}
+	if err = json.Unmarshal(content, &config); err != nil {
+		return err
+	}
+	agentdebug.Apply(config)
+
+	content, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, content, 0644)
+}
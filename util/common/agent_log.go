@@ -0,0 +1,53 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+// tlsFailureMarkers are what the agent logs when a connection fails the TLS handshake or the certificate verification
+var tlsFailureMarkers = []string{
+	"x509: ",
+	"tls: ",
+	"remote error: tls",
+}
+
+// accessDeniedMarkers are what the agent logs when IAM denies one of its requests
+var accessDeniedMarkers = []string{
+	"AccessDenied",
+	"is not authorized to perform",
+}
+
+// AgentTLSFailure returns a TLS error with the first line of the agent output that reports a failed TLS handshake, or
+// nil when there is none. It tells a connection the agent could not trust apart from a delivery that is late.
+func AgentTLSFailure(output string) error {
+	if line, ok := findLine(output, tlsFailureMarkers); ok {
+		return errclass.TLSf("the agent failed to establish a TLS connection: %s", line)
+	}
+	return nil
+}
+
+// AgentAccessDenied returns a permission error with the first line of the agent output that reports a request IAM
+// denied, or nil when there is none
+func AgentAccessDenied(output string) error {
+	if line, ok := findLine(output, accessDeniedMarkers); ok {
+		return errclass.Permissionf("IAM denied a request of the agent: %s", line)
+	}
+	return nil
+}
+
+// findLine returns the first line containing one of the markers, trimmed
+func findLine(output string, markers []string) (string, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		for _, marker := range markers {
+			if strings.Contains(line, marker) {
+				return strings.TrimSpace(line), true
+			}
+		}
+	}
+	return "", false
+}
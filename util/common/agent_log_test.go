@@ -0,0 +1,31 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestAgentTLSFailure(t *testing.T) {
+	assert.NoError(t, AgentTLSFailure("2024-01-02T03:04:05Z I! [outputs.cloudwatchlogs] First time sending logs\n"))
+
+	err := AgentTLSFailure(`2024-01-02T03:04:05Z I! [outputs.cloudwatchlogs] First time sending logs
+2024-01-02T03:04:06Z E! cloudwatchlogs: code: RequestError, message: send request failed, original error: Post "https://127.0.0.1:4443/": x509: certificate signed by unknown authority
+`)
+	assert.Equal(t, errclass.TLS, errclass.Classify(err))
+	assert.Contains(t, err.Error(), "x509: certificate signed by unknown authority")
+}
+
+func TestAgentAccessDenied(t *testing.T) {
+	assert.NoError(t, AgentAccessDenied("2024-01-02T03:04:05Z I! [outputs.cloudwatch] Wrote batch of 20 metrics\n"))
+
+	err := AgentAccessDenied(`2024-01-02T03:04:06Z E! cloudwatchlogs: code: AccessDeniedException, message: User: arn:aws:sts::123456789012:assumed-role/missing/test is not authorized to perform: logs:PutLogEvents
+`)
+	assert.Equal(t, errclass.Permission, errclass.Classify(err))
+	assert.Contains(t, err.Error(), "logs:PutLogEvents")
+}
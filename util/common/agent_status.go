@@ -0,0 +1,77 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// AgentStatusRunning is the status amazon-cloudwatch-agent-ctl -a status reports for a running agent
+	AgentStatusRunning = "running"
+	// AgentStatusStopped is the status amazon-cloudwatch-agent-ctl -a status reports for a stopped agent
+	AgentStatusStopped = "stopped"
+	// AgentConfigStatusConfigured is the config status once fetch-config or append-config applied a config
+	AgentConfigStatusConfigured = "configured"
+
+	// agentStartTimeLayout is how amazon-cloudwatch-agent-ctl formats the start time, date +%FT%T%z
+	agentStartTimeLayout = "2006-01-02T15:04:05-0700"
+)
+
+// AgentStatus is what amazon-cloudwatch-agent-ctl -a status reports
+type AgentStatus struct {
+	Status       string
+	ConfigStatus string
+	Version      string
+	// StartTime is when the agent process started, to the second. It is zero when the agent is not running.
+	StartTime time.Time
+}
+
+// Running is true when the agent process is running
+func (s AgentStatus) Running() bool {
+	return s.Status == AgentStatusRunning
+}
+
+// ParseAgentStatus reads the JSON amazon-cloudwatch-agent-ctl -a status prints, skipping anything the shell wrote
+// before it
+func ParseAgentStatus(out []byte) (AgentStatus, error) {
+	var agentStatus struct {
+		Status       string `json:"status"`
+		StartTime    string `json:"starttime"`
+		ConfigStatus string `json:"configstatus"`
+		Version      string `json:"version"`
+	}
+	start := bytes.IndexByte(out, '{')
+	if start < 0 {
+		return AgentStatus{}, fmt.Errorf("agent status %q is not JSON", string(out))
+	}
+	if err := json.Unmarshal(out[start:], &agentStatus); err != nil {
+		return AgentStatus{}, fmt.Errorf("failed to parse agent status %s: %w", string(out), err)
+	}
+	s := AgentStatus{
+		Status:       agentStatus.Status,
+		ConfigStatus: agentStatus.ConfigStatus,
+		Version:      agentStatus.Version,
+	}
+	if agentStatus.StartTime != "" {
+		startTime, err := time.Parse(agentStartTimeLayout, agentStatus.StartTime)
+		if err != nil {
+			return AgentStatus{}, fmt.Errorf("failed to parse the start time of agent status %s: %w", string(out), err)
+		}
+		s.StartTime = startTime
+	}
+	return s, nil
+}
+
+// IsAgentRunning is true when the agent status reports the agent as running
+func IsAgentRunning() (bool, error) {
+	s, err := GetAgentStatus()
+	if err != nil {
+		return false, err
+	}
+	return s.Running(), nil
+}
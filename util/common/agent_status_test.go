@@ -0,0 +1,39 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAgentStatus(t *testing.T) {
+	running := []byte(`sudo: unable to resolve host ip-10-0-0-1
+{
+  "status": "running",
+  "starttime": "2023-01-24T21:34:45+0000",
+  "configstatus": "configured",
+  "version": "1.300025.0b251"
+}`)
+	s, err := ParseAgentStatus(running)
+	require.NoError(t, err)
+	assert.True(t, s.Running())
+	assert.Equal(t, AgentConfigStatusConfigured, s.ConfigStatus)
+	assert.Equal(t, "1.300025.0b251", s.Version)
+	assert.True(t, s.StartTime.Equal(time.Date(2023, 1, 24, 21, 34, 45, 0, time.UTC)))
+
+	s, err = ParseAgentStatus([]byte(`{"status": "stopped", "starttime": "", "configstatus": "not configured", "version": "1.300025.0b251"}`))
+	require.NoError(t, err)
+	assert.False(t, s.Running())
+	assert.Equal(t, AgentStatusStopped, s.Status)
+	assert.True(t, s.StartTime.IsZero())
+
+	_, err = ParseAgentStatus([]byte("amazon-cloudwatch-agent-ctl: command not found"))
+	assert.ErrorContains(t, err, "is not JSON")
+	_, err = ParseAgentStatus([]byte(`{"status": "running", "starttime": "yesterday"}`))
+	assert.ErrorContains(t, err, "start time")
+}
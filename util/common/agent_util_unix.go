@@ -8,10 +8,13 @@ package common
 import (
 	"fmt"
 	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 const (
@@ -21,7 +24,12 @@ const (
 	Namespace               = "CWAgent"
 	Host                    = "host"
 	AgentLogFile            = "/opt/aws/amazon-cloudwatch-agent/logs/amazon-cloudwatch-agent.log"
+	AgentTomlFile           = "/opt/aws/amazon-cloudwatch-agent/etc/amazon-cloudwatch-agent.toml"
 	InstallAgentVersionPath = "/opt/aws/amazon-cloudwatch-agent/bin/CWAGENT_VERSION"
+	// AgentServiceName is the systemd unit the agent runs as
+	AgentServiceName = "amazon-cloudwatch-agent"
+	// agentProcessComm is the process name the kernel logs, truncated to 15 characters
+	agentProcessComm = "amazon-cloudwat"
 )
 
 type PackageManager int
@@ -32,46 +40,46 @@ const (
 )
 
 func CopyFile(pathIn string, pathOut string) {
-	log.Printf("Copy File %s to %s", pathIn, pathOut)
+	logger.Infof("Copy File %s to %s", pathIn, pathOut)
 	pathInAbs, err := filepath.Abs(pathIn)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Printf("File %s abs path %s", pathIn, pathInAbs)
+	logger.Infof("File %s abs path %s", pathIn, pathInAbs)
 	out, err := exec.Command("bash", "-c", "sudo cp "+pathInAbs+" "+pathOut).Output()
 
 	if err != nil {
 		log.Fatal(fmt.Sprint(err) + string(out))
 	}
 
-	log.Printf("File : %s copied to : %s", pathIn, pathOut)
+	logger.Infof("File : %s copied to : %s", pathIn, pathOut)
 }
 
 func DeleteFile(filePathAbsolute string) error {
-	log.Printf("Delete file %s", filePathAbsolute)
+	logger.Infof("Delete file %s", filePathAbsolute)
 	out, err := exec.Command("bash", "-c", "sudo rm "+filePathAbsolute).Output()
 
 	if err != nil {
-		log.Printf(fmt.Sprint(err) + string(out))
+		logger.Errorf("%v%s", err, out)
 		return err
 	}
 
-	log.Printf("Removed file: %s", filePathAbsolute)
+	logger.Infof("Removed file: %s", filePathAbsolute)
 	return nil
 }
 
 func TouchFile(filePathAbsolute string) error {
-	log.Printf("Touch file %s", filePathAbsolute)
+	logger.Infof("Touch file %s", filePathAbsolute)
 	out, err := exec.Command("bash", "-c", "sudo touch "+filePathAbsolute).Output()
 
 	if err != nil {
-		log.Printf(fmt.Sprint(err) + string(out))
+		logger.Errorf("%v%s", err, out)
 		return err
 	}
 
-	log.Printf("Touched file: %s", filePathAbsolute)
+	logger.Infof("Touched file: %s", filePathAbsolute)
 	return nil
 }
 
@@ -86,11 +94,11 @@ func printOutputAndError(stdout []byte, err error) {
 	if ok {
 		stderr = string(ee.Stderr)
 	}
-	log.Printf("failed\n\tstdout:\n%s\n\tstderr:\n%s\n", string(stdout), stderr)
+	logger.Errorf("failed\n\tstdout:\n%s\n\tstderr:\n%s\n", string(stdout), stderr)
 }
 
 func UninstallAgent(pm PackageManager) error {
-	log.Printf("Uninstalling Agent...")
+	logger.Infof("Uninstalling Agent...")
 	var c *exec.Cmd
 	switch pm {
 	case RPM:
@@ -107,7 +115,7 @@ func UninstallAgent(pm PackageManager) error {
 
 // InstallAgent can determine the package manager based on the installer suffix.
 func InstallAgent(installerFilePath string) error {
-	log.Printf("Installing Agent...")
+	logger.Infof("Installing Agent...")
 	var c *exec.Cmd
 	// Assuming lower case
 	if strings.HasSuffix(installerFilePath, ".rpm") {
@@ -132,14 +140,78 @@ func StartAgent(configOutputPath string, fatalOnFailure bool, ssm bool) error {
 	if err != nil && fatalOnFailure {
 		log.Fatal(fmt.Sprint(err) + string(out))
 	} else if err != nil {
-		log.Printf(fmt.Sprint(err) + string(out))
+		logger.Errorf("%v%s", err, out)
 	} else {
-		log.Printf("Agent has started")
+		logger.Infof("Agent has started")
 	}
 
 	return err
 }
 
+// AppendAgentConfig appends the config at configPath, JSON or OTel YAML, to the config of the running agent and
+// restarts it with the merged config
+func AppendAgentConfig(configPath string) error {
+	out, err := exec.
+		Command("bash", "-c", "sudo /opt/aws/amazon-cloudwatch-agent/bin/amazon-cloudwatch-agent-ctl -a append-config -m ec2 -s -c file:"+configPath).
+		Output()
+	if err != nil {
+		return fmt.Errorf("failed to append config %s: %w: %s", configPath, err, out)
+	}
+	logger.Infof("Appended config %s and restarted the agent", configPath)
+	return nil
+}
+
+// TranslateConfig runs the agent's config translator on the JSON config at configPath the same way fetch-config does
+// on EC2, and returns the generated TOML without starting the agent
+func TranslateConfig(configPath string) (string, error) {
+	outputDir, err := os.MkdirTemp("", "cwagent-translate")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(outputDir)
+
+	inputDir := filepath.Join(outputDir, "amazon-cloudwatch-agent.d")
+	if err = os.Mkdir(inputDir, 0755); err != nil {
+		return "", err
+	}
+	output := filepath.Join(outputDir, "amazon-cloudwatch-agent.toml")
+	out, err := exec.Command("bash", "-c", fmt.Sprintf(
+		"sudo /opt/aws/amazon-cloudwatch-agent/bin/config-translator --input %s --input-dir %s --output %s --mode ec2 --config /opt/aws/amazon-cloudwatch-agent/etc/common-config.toml --multi-config default",
+		configPath, inputDir, output)).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("config-translator failed: %w: %s", err, string(out))
+	}
+
+	toml, err := os.ReadFile(output)
+	if err != nil {
+		return "", err
+	}
+	return string(toml), nil
+}
+
+// GetInstalledAgentVersion reads the version of the installed agent from CWAGENT_VERSION, falling back to the version
+// reported by the agent status for packages that do not ship the file
+func GetInstalledAgentVersion() (string, error) {
+	if version, err := os.ReadFile(InstallAgentVersionPath); err == nil {
+		return strings.TrimSpace(string(version)), nil
+	}
+
+	s, err := GetAgentStatus()
+	if err != nil {
+		return "", err
+	}
+	return s.Version, nil
+}
+
+// GetAgentStatus runs amazon-cloudwatch-agent-ctl -a status
+func GetAgentStatus() (AgentStatus, error) {
+	out, err := exec.Command("bash", "-c", "sudo /opt/aws/amazon-cloudwatch-agent/bin/amazon-cloudwatch-agent-ctl -a status").Output()
+	if err != nil {
+		return AgentStatus{}, fmt.Errorf("failed to get agent status: %w", err)
+	}
+	return ParseAgentStatus(out)
+}
+
 func StopAgent() {
 	out, err := exec.
 		Command("bash", "-c", "sudo /opt/aws/amazon-cloudwatch-agent/bin/amazon-cloudwatch-agent-ctl -a stop").
@@ -149,7 +221,63 @@ func StopAgent() {
 		log.Fatal(fmt.Sprint(err) + string(out))
 	}
 
-	log.Printf("Agent is stopped")
+	logger.Infof("Agent is stopped")
+}
+
+// SetAgentServiceProperties sets resource control properties, e.g. CPUQuota=50% or MemoryMax=512M, on the running
+// agent unit. They are not persisted and apply until ResetAgentServiceProperties or a reboot.
+func SetAgentServiceProperties(properties ...string) error {
+	out, err := exec.Command("bash", "-c",
+		fmt.Sprintf("sudo systemctl set-property --runtime %s %s", AgentServiceName, strings.Join(properties, " "))).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to set %v on %s: %w: %s", properties, AgentServiceName, err, out)
+	}
+	logger.Infof("Set %v on %s", properties, AgentServiceName)
+	return nil
+}
+
+// ResetAgentServiceProperties removes the properties set by SetAgentServiceProperties
+func ResetAgentServiceProperties() error {
+	out, err := exec.Command("bash", "-c",
+		fmt.Sprintf("sudo systemctl revert %s && sudo systemctl daemon-reload", AgentServiceName)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to revert %s: %w: %s", AgentServiceName, err, out)
+	}
+	return nil
+}
+
+// GetAgentServiceProperties returns the values systemd reports for the properties of the agent unit, e.g. MemoryMax
+// or NRestarts
+func GetAgentServiceProperties(names ...string) (map[string]string, error) {
+	out, err := exec.Command("bash", "-c",
+		fmt.Sprintf("systemctl show %s -p %s", AgentServiceName, strings.Join(names, ","))).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to show %s: %w", AgentServiceName, err)
+	}
+	properties := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if name, value, ok := strings.Cut(line, "="); ok {
+			properties[name] = value
+		}
+	}
+	return properties, nil
+}
+
+// AgentOomKilled is true when the kernel killed the agent for running out of memory since the time, either when it
+// exceeded the MemoryMax of its unit or the host ran out of memory
+func AgentOomKilled(since time.Time) (bool, error) {
+	out, err := exec.Command("bash", "-c",
+		fmt.Sprintf("sudo journalctl -k --since %q --no-pager -q", since.Format("2006-01-02 15:04:05"))).Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to read the kernel log: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.Contains(line, "Killed process") && strings.Contains(line, "("+agentProcessComm) {
+			logger.Errorf("Agent was OOM killed: %s", line)
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func ReadAgentOutput(d time.Duration) string {
@@ -168,7 +296,7 @@ func RunShellScript(path string, args ...string) (string, error) {
 	out, err := exec.Command("bash", "-c", "sudo chmod +x "+path).Output()
 
 	if err != nil {
-		log.Printf("Error occurred when attempting to chmod %s: %s | %s", path, err.Error(), string(out))
+		logger.Errorf("Error occurred when attempting to chmod %s: %s | %s", path, err.Error(), string(out))
 		return "", err
 	}
 
@@ -179,7 +307,7 @@ func RunShellScript(path string, args ...string) (string, error) {
 	out, err = exec.Command("bash", bashArgs...).Output()
 
 	if err != nil {
-		log.Printf("Error occurred when executing %s: %s | %s", path, err.Error(), string(out))
+		logger.Errorf("Error occurred when executing %s: %s | %s", path, err.Error(), string(out))
 		return "", err
 	}
 
@@ -187,14 +315,14 @@ func RunShellScript(path string, args ...string) (string, error) {
 }
 
 func RunCommand(cmd string) (string, error) {
-	log.Printf("running cmd, %s", cmd)
+	logger.Infof("running cmd, %s", cmd)
 	out, err := exec.Command("bash", "-c", cmd).Output()
 	printOutputAndError(out, err)
 	return string(out), err
 }
 
 func RunAsyncCommand(cmd string) error {
-	log.Printf("running async cmd, %s", cmd)
+	logger.Infof("running async cmd, %s", cmd)
 	return exec.Command("nohup", "bash", "-c", cmd).Start()
 }
 
@@ -7,15 +7,20 @@ package common
 
 import (
 	"fmt"
-	"log"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 )
 
 const (
 	ConfigOutputPath = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\amazon-cloudwatch-agent.json"
 	AgentLogFile     = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\Logs\\amazon-cloudwatch-agent.log"
+	AgentTomlFile    = "C:\\ProgramData\\Amazon\\AmazonCloudWatchAgent\\amazon-cloudwatch-agent.toml"
+	// InstallAgentVersionPath is the CWAGENT_VERSION file of the installed agent
+	InstallAgentVersionPath = "C:\\Program Files\\Amazon\\AmazonCloudWatchAgent\\CWAGENT_VERSION"
 )
 
 func CopyFile(pathIn string, pathOut string) error {
@@ -25,23 +30,23 @@ func CopyFile(pathIn string, pathOut string) error {
 		return err
 	}
 
-	log.Printf("Copy File %s to %s", pathIn, pathOut)
+	logger.Infof("Copy File %s to %s", pathIn, pathOut)
 	pathInAbs, err := filepath.Abs(pathIn)
 
 	if err != nil {
 		return err
 	}
 
-	log.Printf("File %s abs path %s", pathIn, pathInAbs)
+	logger.Infof("File %s abs path %s", pathIn, pathInAbs)
 	bashArgs := append([]string{"-NoProfile", "-NonInteractive", "-NoExit", "cp " + pathInAbs + " " + pathOut})
 	out, err := exec.Command(ps, bashArgs...).Output()
 
 	if err != nil {
-		log.Printf("Copy file failed: %v; the output is: %s", err, string(out))
+		logger.Errorf("Copy file failed: %v; the output is: %s", err, string(out))
 		return err
 	}
 
-	log.Printf("File : %s copied to : %s", pathIn, pathOut)
+	logger.Infof("File : %s copied to : %s", pathIn, pathOut)
 	return nil
 
 }
@@ -59,17 +64,41 @@ func StartAgent(configOutputPath string, fatalOnFailure bool, ssm bool) error {
 	out, err := exec.Command(ps, bashArgs...).Output()
 
 	if err != nil && fatalOnFailure {
-		log.Printf("Start agent failed: %v; the output is: %s", err, string(out))
+		logger.Errorf("Start agent failed: %v; the output is: %s", err, string(out))
 		return err
 	} else if err != nil {
-		log.Printf(fmt.Sprint(err) + string(out))
+		logger.Errorf("%v%s", err, out)
 	} else {
-		log.Printf("Agent has started")
+		logger.Infof("Agent has started")
 	}
 
 	return err
 }
 
+// GetInstalledAgentVersion reads the version of the installed agent from CWAGENT_VERSION
+func GetInstalledAgentVersion() (string, error) {
+	version, err := os.ReadFile(InstallAgentVersionPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(version)), nil
+}
+
+// GetAgentStatus runs amazon-cloudwatch-agent-ctl.ps1 -a status
+func GetAgentStatus() (AgentStatus, error) {
+	ps, err := exec.LookPath("powershell.exe")
+	if err != nil {
+		return AgentStatus{}, err
+	}
+
+	bashArgs := []string{"-NoProfile", "-NonInteractive", "-NoExit", "& \"C:\\Program Files\\Amazon\\AmazonCloudWatchAgent\\amazon-cloudwatch-agent-ctl.ps1\" -a status"}
+	out, err := exec.Command(ps, bashArgs...).Output()
+	if err != nil {
+		return AgentStatus{}, fmt.Errorf("failed to get agent status: %w", err)
+	}
+	return ParseAgentStatus(out)
+}
+
 func StopAgent() error {
 	ps, err := exec.LookPath("powershell.exe")
 
@@ -81,11 +110,11 @@ func StopAgent() error {
 	out, err := exec.Command(ps, bashArgs...).Output()
 
 	if err != nil {
-		log.Printf("Stop agent failed: %v; the output is: %s", err, string(out))
+		logger.Errorf("Stop agent failed: %v; the output is: %s", err, string(out))
 		return err
 	}
 
-	log.Printf("Agent is stopped")
+	logger.Infof("Agent is stopped")
 	return nil
 }
 
@@ -102,12 +131,12 @@ func RunShellScript(path string, args ...string) (string, error) {
 	shellArgs = append(shellArgs, path)
 	shellArgs = append(shellArgs, args...)
 
-	log.Printf("running %v", shellArgs)
+	logger.Infof("running %v", shellArgs)
 
 	out, err := exec.Command(ps, shellArgs...).Output()
 
 	if err != nil {
-		log.Printf("Error occurred when executing %s: %s | %s", path, err.Error(), string(out))
+		logger.Errorf("Error occurred when executing %s: %s | %s", path, err.Error(), string(out))
 		return "", err
 	}
 
@@ -125,11 +154,11 @@ func printOutputAndError(stdout []byte, err error) {
 	if ok {
 		stderr = string(ee.Stderr)
 	}
-	log.Printf("failed\n\tstdout:\n%s\n\tstderr:\n%s\n", string(stdout), stderr)
+	logger.Errorf("failed\n\tstdout:\n%s\n\tstderr:\n%s\n", string(stdout), stderr)
 }
 
 func RunCommand(cmd string) (string, error) {
-	log.Printf("running cmd, %s", cmd)
+	logger.Infof("running cmd, %s", cmd)
 	out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-NoExit", cmd).Output()
 	printOutputAndError(out, err)
 	return string(out), err
@@ -147,6 +176,6 @@ func RunCommands(commands []string) error {
 }
 
 func RunAsyncCommand(cmd string) error {
-	log.Printf("running async cmd, %s", cmd)
+	logger.Infof("running async cmd, %s", cmd)
 	return exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-NoExit", cmd).Start()
 }
@@ -0,0 +1,47 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommonConfig is the common-config.toml of the agent, the settings it applies to every AWS client it creates. The
+// sections without any value set are left out, so the agent falls back to its defaults for them.
+type CommonConfig struct {
+	SharedCredentialProfile string
+	SharedCredentialFile    string
+	HttpProxy               string
+	HttpsProxy              string
+	NoProxy                 string
+	// CABundlePath is the PEM file of the CAs the agent trusts instead of the ones of the system
+	CABundlePath string
+}
+
+func (c CommonConfig) String() string {
+	var b strings.Builder
+	writeSection := func(name string, keyValues ...string) {
+		var values []string
+		for i := 0; i < len(keyValues); i += 2 {
+			if keyValues[i+1] != "" {
+				values = append(values, fmt.Sprintf("  %s = %q\n", keyValues[i], keyValues[i+1]))
+			}
+		}
+		if len(values) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "[%s]\n%s", name, strings.Join(values, ""))
+	}
+	writeSection("credentials",
+		"shared_credential_profile", c.SharedCredentialProfile,
+		"shared_credential_file", c.SharedCredentialFile)
+	writeSection("proxy",
+		"http_proxy", c.HttpProxy,
+		"https_proxy", c.HttpsProxy,
+		"no_proxy", c.NoProxy)
+	writeSection("ssl",
+		"ca_bundle_path", c.CABundlePath)
+	return b.String()
+}
@@ -0,0 +1,28 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonConfigString(t *testing.T) {
+	assert.Equal(t, "", CommonConfig{}.String())
+	assert.Equal(t, `[credentials]
+  shared_credential_profile = "cwagent"
+  shared_credential_file = "/tmp/credentials"
+[proxy]
+  https_proxy = "http://127.0.0.1:9"
+  no_proxy = "169.254.169.254,amazonaws.com"
+`, CommonConfig{
+		SharedCredentialProfile: "cwagent",
+		SharedCredentialFile:    "/tmp/credentials",
+		HttpsProxy:              "http://127.0.0.1:9",
+		NoProxy:                 "169.254.169.254,amazonaws.com",
+	}.String())
+	assert.Equal(t, "[proxy]\n  http_proxy = \"http://proxy:3128\"\n", CommonConfig{HttpProxy: "http://proxy:3128"}.String())
+	assert.Equal(t, "[ssl]\n  ca_bundle_path = \"/tmp/ca.pem\"\n", CommonConfig{CABundlePath: "/tmp/ca.pem"}.String())
+}
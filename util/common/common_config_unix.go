@@ -0,0 +1,52 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+const (
+	// CommonConfigPath is where the agent reads its common config from
+	CommonConfigPath       = "/opt/aws/amazon-cloudwatch-agent/etc/common-config.toml"
+	commonConfigBackupPath = CommonConfigPath + ".bak"
+)
+
+// WriteCommonConfig replaces the common config of the agent, it applies from the next time the agent starts. The
+// config installed with the agent is backed up the first time, for RestoreCommonConfig.
+func WriteCommonConfig(c CommonConfig) error {
+	f, err := os.CreateTemp("", "common-config-*.toml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.WriteString(c.String()); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("sudo cp -n %[1]s %[2]s; sudo cp %[3]s %[1]s && sudo chmod 644 %[1]s",
+		CommonConfigPath, commonConfigBackupPath, f.Name())).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w: %s", CommonConfigPath, err, out)
+	}
+	return nil
+}
+
+// RestoreCommonConfig restores the common config WriteCommonConfig backed up, if it replaced it
+func RestoreCommonConfig() error {
+	out, err := exec.Command("bash", "-c", fmt.Sprintf("if [ -f %[2]s ]; then sudo mv %[2]s %[1]s; fi",
+		CommonConfigPath, commonConfigBackupPath)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to restore %s: %w: %s", CommonConfigPath, err, out)
+	}
+	return nil
+}
@@ -8,12 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"runtime"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 	"go.uber.org/multierr"
 )
@@ -48,11 +48,11 @@ func CreateWindowsEvent(eventLogName string, eventLogLevel string, msg string) e
 	out, err := exec.Command("eventcreate", "/ID", "1", "/L", eventLogName, "/T", eventLogLevel, "/SO", "MYEVENTSOURCE"+eventLogName, "/D", msg).Output()
 
 	if err != nil {
-		log.Printf("Windows event creation failed: %v; the output is: %s", err, string(out))
+		logger.Errorf("Windows event creation failed: %v; the output is: %s", err, string(out))
 		return err
 	}
 
-	log.Printf("Windows Event is successfully created for logname: %s, loglevel: %s, logmsg: %s", eventLogName, eventLogLevel, msg)
+	logger.Infof("Windows Event is successfully created for logname: %s, loglevel: %s, logmsg: %s", eventLogName, eventLogLevel, msg)
 	return nil
 }
 
@@ -184,7 +184,7 @@ func GenerateLogConfig(numberMonitoredLogs int, filePath string) error {
 		})
 	}
 
-	log.Printf("Writing config file with %d logs to %v", numberMonitoredLogs, filePath)
+	logger.Infof("Writing config file with %d logs to %v", numberMonitoredLogs, filePath)
 
 	cfgFileData["logs"].(map[string]interface{})["logs_collected"].(map[string]interface{})["files"].(map[string]interface{})["collect_list"] = logFiles
 
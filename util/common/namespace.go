@@ -0,0 +1,43 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package common
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/namespace"
+)
+
+// ScopeConfigNamespace rewrites metrics.namespace of the agent config at the path with namespace.Scope, so the
+// agent publishes to the same namespace the validators query. Configs without a metrics namespace are left as is.
+func ScopeConfigNamespace(configPath string) error {
+	if !namespace.IsScoped() {
+		return nil
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var config map[string]interface{}
+	if err = json.Unmarshal(content, &config); err != nil {
+		return err
+	}
+	metrics, ok := config["metrics"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	ns, ok := metrics["namespace"].(string)
+	if !ok {
+		return nil
+	}
+	metrics["namespace"] = namespace.Scope(ns)
+
+	content, err = json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, content, 0644)
+}
@@ -0,0 +1,59 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package completeness
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Kind is the kind of telemetry a runner generated, named the way the report prints it
+type Kind string
+
+const (
+	LogLines      Kind = "log lines"
+	StatsdPackets Kind = "statsd packets"
+	EMFPayloads   Kind = "EMF payloads"
+)
+
+// Report reconciles the telemetry a runner generated with what was observed in CloudWatch, so a partial loss is
+// visible as a percentage instead of only failing the runner
+type Report struct {
+	Kind Kind
+	// Name is what was generated, e.g. the metric name or the log stream
+	Name     string
+	Sent     int64
+	Observed int64
+}
+
+// LossPercent is the percentage of the sent telemetry that was not observed. Observing more than was sent, e.g. a
+// duplicate delivery, is not a loss.
+func (r Report) LossPercent() float64 {
+	if r.Sent <= 0 || r.Observed >= r.Sent {
+		return 0
+	}
+	return 100 * float64(r.Sent-r.Observed) / float64(r.Sent)
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("%s %s: observed %d of %d sent, %.2f%% lost", r.Kind, r.Name, r.Observed, r.Sent, r.LossPercent())
+}
+
+// Counter counts the telemetry a runner sends. It is safe to use from the sender goroutines.
+type Counter struct {
+	sent int64
+}
+
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.sent, n)
+}
+
+func (c *Counter) Sent() int64 {
+	return atomic.LoadInt64(&c.sent)
+}
+
+// Reconcile builds the report of the counted telemetry and the observed count
+func (c *Counter) Reconcile(kind Kind, name string, observed int64) Report {
+	return Report{Kind: kind, Name: name, Sent: c.Sent(), Observed: observed}
+}
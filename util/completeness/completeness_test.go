@@ -0,0 +1,37 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package completeness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLossPercent(t *testing.T) {
+	testCases := map[string]struct {
+		sent, observed int64
+		want           float64
+	}{
+		"NoLoss":      {sent: 100, observed: 100, want: 0},
+		"PartialLoss": {sent: 200, observed: 150, want: 25},
+		"TotalLoss":   {sent: 10, observed: 0, want: 100},
+		"Duplicates":  {sent: 10, observed: 12, want: 0},
+		"NothingSent": {sent: 0, observed: 0, want: 0},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, Report{Sent: testCase.sent, Observed: testCase.observed}.LossPercent())
+		})
+	}
+}
+
+func TestCounterReconcile(t *testing.T) {
+	var c Counter
+	c.Add(3)
+	c.Add(2)
+	r := c.Reconcile(StatsdPackets, "statsd_timing_1", 4)
+	assert.Equal(t, Report{Kind: StatsdPackets, Name: "statsd_timing_1", Sent: 5, Observed: 4}, r)
+	assert.Equal(t, "statsd packets statsd_timing_1: observed 4 of 5 sent, 20.00% lost", r.String())
+}
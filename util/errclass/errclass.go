@@ -0,0 +1,173 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package errclass
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// Category tells whether a failure is caused by the test infrastructure or by the agent, so CI can rerun the
+// former and page on the latter
+type Category string
+
+const (
+	// Throttled failures exhausted the retries of a throttled AWS API
+	Throttled Category = "throttled"
+	// NotFoundYet failures did not find telemetry or resources that may still be on their way, e.g. metrics that
+	// were not ingested yet
+	NotFoundYet Category = "not_found_yet"
+	// Validation failures found data that does not match what the agent should have produced
+	Validation Category = "validation"
+	// Infra failures come from the hosts, network or AWS services the tests depend on
+	Infra Category = "infra"
+	// TLS failures could not establish a trusted connection, e.g. a certificate the CA bundle does not trust, which a
+	// rerun does not fix unlike a delivery that is late
+	TLS Category = "tls"
+	// Permission failures were denied by IAM, e.g. a policy or permissions boundary without an action the agent needs
+	Permission Category = "permission"
+)
+
+// IsRetryable is true for the categories of failures which are expected to go away when the test is rerun
+func (c Category) IsRetryable() bool {
+	return c == Throttled || c == NotFoundYet || c == Infra
+}
+
+// ThrottledError wraps an error caused by API throttling
+type ThrottledError struct{ Err error }
+
+func (e *ThrottledError) Error() string { return e.Err.Error() }
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// NotFoundYetError wraps an error caused by telemetry or resources which are not visible yet
+type NotFoundYetError struct{ Err error }
+
+func (e *NotFoundYetError) Error() string { return e.Err.Error() }
+func (e *NotFoundYetError) Unwrap() error { return e.Err }
+
+// ValidationError wraps an error caused by data which does not match the expectations
+type ValidationError struct{ Err error }
+
+func (e *ValidationError) Error() string { return e.Err.Error() }
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// InfraError wraps an error caused by the test infrastructure
+type InfraError struct{ Err error }
+
+func (e *InfraError) Error() string { return e.Err.Error() }
+func (e *InfraError) Unwrap() error { return e.Err }
+
+// TLSError wraps an error caused by a failed TLS handshake or certificate verification
+type TLSError struct{ Err error }
+
+func (e *TLSError) Error() string { return e.Err.Error() }
+func (e *TLSError) Unwrap() error { return e.Err }
+
+// PermissionError wraps an error caused by a request IAM denied
+type PermissionError struct{ Err error }
+
+func (e *PermissionError) Error() string { return e.Err.Error() }
+func (e *PermissionError) Unwrap() error { return e.Err }
+
+// NotFoundYetf formats a NotFoundYetError, like fmt.Errorf
+func NotFoundYetf(format string, args ...interface{}) error {
+	return &NotFoundYetError{Err: fmt.Errorf(format, args...)}
+}
+
+// Validationf formats a ValidationError, like fmt.Errorf
+func Validationf(format string, args ...interface{}) error {
+	return &ValidationError{Err: fmt.Errorf(format, args...)}
+}
+
+// Infraf formats an InfraError, like fmt.Errorf
+func Infraf(format string, args ...interface{}) error {
+	return &InfraError{Err: fmt.Errorf(format, args...)}
+}
+
+// TLSf formats a TLSError, like fmt.Errorf
+func TLSf(format string, args ...interface{}) error {
+	return &TLSError{Err: fmt.Errorf(format, args...)}
+}
+
+// Permissionf formats a PermissionError, like fmt.Errorf
+func Permissionf(format string, args ...interface{}) error {
+	return &PermissionError{Err: fmt.Errorf(format, args...)}
+}
+
+var throttles = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// Classify returns the category of the error. The explicit wrappers win, then SDK throttling, missing AWS resources,
+// denied requests, certificate and network errors are recognized. Anything else is a Validation failure, so an unknown error is never mistaken
+// for a flake.
+func Classify(err error) Category {
+	var (
+		throttled   *ThrottledError
+		notFoundYet *NotFoundYetError
+		validation  *ValidationError
+		infra       *InfraError
+		tlsErr      *TLSError
+		permission  *PermissionError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &throttled):
+		return Throttled
+	case errors.As(err, &notFoundYet):
+		return NotFoundYet
+	case errors.As(err, &validation):
+		return Validation
+	case errors.As(err, &infra):
+		return Infra
+	case errors.As(err, &tlsErr):
+		return TLS
+	case errors.As(err, &permission):
+		return Permission
+	case throttles.IsErrorThrottle(err) == aws.TrueTernary:
+		return Throttled
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ResourceNotFoundException":
+			return NotFoundYet
+		case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+			return Permission
+		}
+	}
+	// checked before the network errors, the clients wrap failed handshakes in a *url.Error which is a net.Error
+	if isTLSError(err) {
+		return TLS
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return Infra
+	}
+	return Validation
+}
+
+// IsRetryable is true when the error is expected to go away when the test is rerun
+func IsRetryable(err error) bool {
+	return Classify(err).IsRetryable()
+}
+
+func isTLSError(err error) bool {
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		invalid          x509.CertificateInvalidError
+		hostname         x509.HostnameError
+		recordHeader     tls.RecordHeaderError
+	)
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) ||
+		errors.As(err, &recordHeader)
+}
@@ -0,0 +1,51 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package errclass
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := map[string]struct {
+		err  error
+		want Category
+	}{
+		"Nil":          {err: nil, want: ""},
+		"Unknown":      {err: errors.New("unexpected value"), want: Validation},
+		"NotFoundYet":  {err: NotFoundYetf("no metrics found"), want: NotFoundYet},
+		"Wrapped":      {err: fmt.Errorf("fetching: %w", &InfraError{Err: errors.New("host unreachable")}), want: Infra},
+		"Explicit":     {err: Validationf("bad value %d", 1), want: Validation},
+		"SdkThrottle":  {err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: Throttled},
+		"SdkNotFound":  {err: fmt.Errorf("describe: %w", &smithy.GenericAPIError{Code: "ResourceNotFoundException"}), want: NotFoundYet},
+		"SdkDenied":    {err: fmt.Errorf("put: %w", &smithy.GenericAPIError{Code: "AccessDeniedException"}), want: Permission},
+		"SdkOther":     {err: &smithy.GenericAPIError{Code: "InvalidParameterValue"}, want: Validation},
+		"DeadlineHits": {err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: Infra},
+		"ExplicitTLS":  {err: TLSf("x509: certificate signed by unknown authority"), want: TLS},
+		"UnknownCA":    {err: &url.Error{Op: "Post", URL: "https://127.0.0.1", Err: x509.UnknownAuthorityError{}}, want: TLS},
+		"Hostname":     {err: fmt.Errorf("put: %w", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "logs"}), want: TLS},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, Classify(testCase.err))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(NotFoundYetf("log group %s not found", "group")))
+	assert.True(t, IsRetryable(&ThrottledError{Err: errors.New("rate exceeded")}))
+	assert.False(t, IsRetryable(Validationf("values are not within the expected bounds")))
+	assert.False(t, IsRetryable(errors.New("unexpected value")))
+	assert.False(t, IsRetryable(TLSf("certificate is not trusted")))
+	assert.False(t, IsRetryable(Permissionf("not authorized to perform logs:PutLogEvents")))
+}
@@ -0,0 +1,247 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package faultproxy sits between the agent and a CloudWatch endpoint, the agent is pointed at it with the
+// endpoint_override of its config. It records the latency and the errors of the real responses as a trace, or replays
+// a recorded trace, so the retries and the queueing of the agent are tested against a realistic degradation.
+package faultproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// maxErrorBody is the most of an error response read for its code
+const maxErrorBody = 64 * 1024
+
+var xmlErrorCode = regexp.MustCompile(`<Code>([^<]+)</Code>`)
+
+// Stats are the requests the proxy forwarded to the endpoint and those it failed itself
+type Stats struct {
+	Forwarded int
+	Injected  int
+	// Delayed are the requests the proxy held for the latency of the trace, whether forwarded or failed
+	Delayed int
+}
+
+// Proxy is an HTTP server on 127.0.0.1 forwarding to the endpoint. The Host header the agent signed is kept, only the
+// connection goes to the endpoint.
+type Proxy struct {
+	server   *http.Server
+	listener net.Listener
+	forward  *httputil.ReverseProxy
+	start    time.Time
+
+	replay Trace
+	record io.Writer
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// Record starts a proxy forwarding every request and writing its response to the record as a JSON line
+func Record(target *url.URL, record io.Writer) (*Proxy, error) {
+	return start(target, nil, record)
+}
+
+// Replay starts a proxy replaying the trace from now on, a request is delayed by the latency of the entry of its
+// operation in effect and failed with its status when the entry failed. Requests after the trace are forwarded.
+func Replay(target *url.URL, trace Trace) (*Proxy, error) {
+	return start(target, trace, nil)
+}
+
+func start(target *url.URL, replay Trace, record io.Writer) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		listener: listener,
+		forward:  httputil.NewSingleHostReverseProxy(target),
+		start:    time.Now(),
+		replay:   replay,
+		record:   record,
+	}
+	p.server = &http.Server{Handler: http.HandlerFunc(p.serve)}
+	go p.server.Serve(listener)
+	logger.Infof("Proxying %s on %s", target, p.URL())
+	return p, nil
+}
+
+// URL is the endpoint override pointing the agent at the proxy
+func (p *Proxy) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// Stats returns what the proxy did to the requests so far
+func (p *Proxy) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+func (p *Proxy) Close() error {
+	return p.server.Close()
+}
+
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request) {
+	received := time.Now()
+	operation := operationOf(r)
+	if p.record != nil {
+		p.forwardAndRecord(w, r, operation, received)
+		return
+	}
+
+	entry, ok := p.replay.At(operation, received.Sub(p.start))
+	if ok && entry.Latency > 0 {
+		p.count(func(s *Stats) { s.Delayed++ })
+		select {
+		case <-time.After(entry.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if ok && entry.Failed() {
+		p.count(func(s *Stats) { s.Injected++ })
+		writeError(w, r, entry.Status, entry.ErrorCode)
+		return
+	}
+	p.count(func(s *Stats) { s.Forwarded++ })
+	p.forward.ServeHTTP(w, r)
+}
+
+func (p *Proxy) forwardAndRecord(w http.ResponseWriter, r *http.Request, operation string, received time.Time) {
+	rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+	p.count(func(s *Stats) { s.Forwarded++ })
+	p.forward.ServeHTTP(rw, r)
+
+	entry := Entry{
+		Offset:    received.Sub(p.start).Round(time.Millisecond),
+		Operation: operation,
+		Latency:   rw.firstWrite.Sub(received).Round(time.Millisecond),
+		Status:    rw.status,
+	}
+	if entry.Failed() {
+		entry.ErrorCode = errorCode(rw.body.Bytes())
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := json.NewEncoder(p.record).Encode(entry); err != nil {
+		logger.Errorf("Recording the response of %s failed: %v", operation, err)
+	}
+}
+
+func (p *Proxy) count(update func(*Stats)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.stats)
+}
+
+// recordingWriter keeps the status, the time the response started and the start of the body of an error
+type recordingWriter struct {
+	http.ResponseWriter
+	status     int
+	firstWrite time.Time
+	body       bytes.Buffer
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.firstWrite.IsZero() {
+		w.firstWrite = time.Now()
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.firstWrite.IsZero() {
+		w.firstWrite = time.Now()
+	}
+	if w.status >= 400 && w.body.Len() < maxErrorBody {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// operationOf returns the API of the request from the X-Amz-Target of the JSON protocols, e.g.
+// Logs_20140328.PutLogEvents, the path of the CBOR protocol or the Action of the query protocol
+func operationOf(r *http.Request) string {
+	if target := r.Header.Get("X-Amz-Target"); target != "" {
+		return target[strings.LastIndex(target, ".")+1:]
+	}
+	if i := strings.LastIndex(r.URL.Path, "/operation/"); i >= 0 {
+		return r.URL.Path[i+len("/operation/"):]
+	}
+	if action := r.URL.Query().Get("Action"); action != "" {
+		return action
+	}
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return ""
+	}
+	// the body is put back for the endpoint, the signature covers it
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	return values.Get("Action")
+}
+
+// errorCode returns the code of an error response of the JSON protocols, e.g. {"__type":"ThrottlingException"}, or of
+// the query protocol, e.g. <Code>Throttling</Code>
+func errorCode(body []byte) string {
+	var jsonError struct {
+		Type string `json:"__type"`
+	}
+	if json.Unmarshal(body, &jsonError) == nil && jsonError.Type != "" {
+		return jsonError.Type[strings.LastIndex(jsonError.Type, "#")+1:]
+	}
+	if m := xmlErrorCode.FindSubmatch(body); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// writeError writes an error the SDK of the agent parses like the one of the endpoint, in the protocol of the request
+func writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	if code == "" {
+		code = http.StatusText(status)
+	}
+	message := fmt.Sprintf("injected by the fault proxy: %s", code)
+	w.Header().Set("X-Amzn-ErrorType", code)
+	w.Header().Set("X-Amzn-RequestId", fmt.Sprintf("faultproxy-%d", time.Now().UnixNano()))
+	if r.Header.Get("X-Amz-Target") != "" {
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"__type": code, "message": message})
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<ErrorResponse><Error><Type>%s</Type><Code>%s</Code><Message>%s</Message></Error><RequestId>%s</RequestId></ErrorResponse>",
+		errorType(status), code, message, w.Header().Get("X-Amzn-RequestId"))
+}
+
+func errorType(status int) string {
+	if status >= 500 {
+		return "Receiver"
+	}
+	return "Sender"
+}
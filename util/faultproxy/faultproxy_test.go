@@ -0,0 +1,140 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package faultproxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// endpoint throttles PutLogEvents and accepts everything else, like CloudWatch while Logs is degraded
+func endpoint(t *testing.T) *url.URL {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") == "Logs_20140328.PutLogEvents" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"__type":"com.amazonaws.logs#ThrottlingException","message":"Rate exceeded"}`)
+			return
+		}
+		io.WriteString(w, "<PutMetricDataResponse/>")
+	}))
+	t.Cleanup(server.Close)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return u
+}
+
+func putLogEvents(t *testing.T, p *Proxy) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, p.URL(), strings.NewReader("{}"))
+	require.NoError(t, err)
+	req.Header.Set("X-Amz-Target", "Logs_20140328.PutLogEvents")
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func putMetricData(t *testing.T, p *Proxy) *http.Response {
+	resp, err := http.Post(p.URL(), "application/x-www-form-urlencoded; charset=utf-8", strings.NewReader("Action=PutMetricData&Version=2010-08-01"))
+	require.NoError(t, err)
+	return resp
+}
+
+func TestRecord(t *testing.T) {
+	var record bytes.Buffer
+	p, err := Record(endpoint(t), &record)
+	require.NoError(t, err)
+	defer p.Close()
+
+	putLogEvents(t, p).Body.Close()
+	resp := putMetricData(t, p)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "<PutMetricDataResponse/>", string(body), "the body is forwarded to the endpoint and back")
+
+	trace, err := ReadTrace(&record)
+	require.NoError(t, err)
+	require.Len(t, trace, 2)
+	assert.Equal(t, "PutLogEvents", trace[0].Operation)
+	assert.Equal(t, http.StatusBadRequest, trace[0].Status)
+	assert.Equal(t, "ThrottlingException", trace[0].ErrorCode)
+	assert.Equal(t, "PutMetricData", trace[1].Operation)
+	assert.Equal(t, http.StatusOK, trace[1].Status)
+	assert.Empty(t, trace[1].ErrorCode)
+	assert.Equal(t, Stats{Forwarded: 2}, p.Stats())
+}
+
+func TestReplay(t *testing.T) {
+	trace, err := ReadTrace(strings.NewReader(`# PutMetricData is throttled, then slow, then recovers
+{"offset":"0s","operation":"PutMetricData","status":400,"error_code":"Throttling"}
+{"offset":"300ms","operation":"PutMetricData","status":200,"latency":"200ms"}
+{"offset":"5s","operation":"PutMetricData","status":200}
+`))
+	require.NoError(t, err)
+	p, err := Replay(endpoint(t), trace)
+	require.NoError(t, err)
+	defer p.Close()
+
+	resp := putMetricData(t, p)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Throttling", errorCode(body), "the error is written in the query protocol of the request")
+
+	// the operations without entries are forwarded as is, the endpoint throttles PutLogEvents itself
+	resp = putLogEvents(t, p)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	time.Sleep(300 * time.Millisecond)
+	start := time.Now()
+	resp = putMetricData(t, p)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	assert.Equal(t, Stats{Forwarded: 2, Injected: 1, Delayed: 1}, p.Stats())
+}
+
+func TestTrace(t *testing.T) {
+	trace := Trace{
+		{Offset: 0, Operation: "PutLogEvents", Status: 200},
+		{Offset: time.Second, Operation: "PutMetricData", Status: 503, ErrorCode: "ServiceUnavailable", Latency: 2 * time.Second},
+		{Offset: 2 * time.Second, Operation: "PutLogEvents", Status: 400, ErrorCode: "ThrottlingException"},
+	}
+	e, ok := trace.At("PutLogEvents", 1500*time.Millisecond)
+	assert.True(t, ok)
+	assert.Equal(t, 200, e.Status)
+	e, ok = trace.At("PutMetricData", 2*time.Second)
+	assert.True(t, ok)
+	assert.True(t, e.Failed())
+	_, ok = trace.At("PutMetricData", 500*time.Millisecond)
+	assert.False(t, ok, "the operation has no entry before its first one")
+	_, ok = trace.At("PutLogEvents", 3*time.Second)
+	assert.False(t, ok, "the trace is over")
+	assert.Equal(t, "3 responses over 2s, max latency 2s, failures: [1 ServiceUnavailable, 1 ThrottlingException]", trace.String())
+
+	var b bytes.Buffer
+	for _, e := range trace {
+		line, err := e.MarshalJSON()
+		require.NoError(t, err)
+		b.Write(append(line, '\n'))
+	}
+	read, err := ReadTrace(&b)
+	require.NoError(t, err)
+	assert.Equal(t, trace, read)
+
+	_, err = ReadTrace(strings.NewReader(`{"offset":"soon","operation":"PutLogEvents"}`))
+	assert.Error(t, err)
+}
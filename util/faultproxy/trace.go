@@ -0,0 +1,138 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package faultproxy
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry is a response of CloudWatch the proxy recorded, or replays in place of the real one
+type Entry struct {
+	// Offset is the time since the start of the trace the request was made at
+	Offset time.Duration
+	// Operation is the API of the request, e.g. PutMetricData or PutLogEvents
+	Operation string
+	Latency   time.Duration
+	Status    int
+	// ErrorCode is the code of a failed response, e.g. Throttling or ServiceUnavailableException
+	ErrorCode string
+}
+
+// jsonEntry is an Entry with the durations written like 1m30s, so a trace can be written by hand
+type jsonEntry struct {
+	Offset    string `json:"offset"`
+	Operation string `json:"operation"`
+	Latency   string `json:"latency,omitempty"`
+	Status    int    `json:"status"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+func (e Entry) MarshalJSON() ([]byte, error) {
+	j := jsonEntry{Offset: e.Offset.String(), Operation: e.Operation, Status: e.Status, ErrorCode: e.ErrorCode}
+	if e.Latency > 0 {
+		j.Latency = e.Latency.String()
+	}
+	return json.Marshal(j)
+}
+
+func (e *Entry) UnmarshalJSON(b []byte) error {
+	var j jsonEntry
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	offset, err := time.ParseDuration(j.Offset)
+	if err != nil {
+		return fmt.Errorf("invalid offset: %w", err)
+	}
+	var latency time.Duration
+	if j.Latency != "" {
+		if latency, err = time.ParseDuration(j.Latency); err != nil {
+			return fmt.Errorf("invalid latency: %w", err)
+		}
+	}
+	*e = Entry{Offset: offset, Operation: j.Operation, Latency: latency, Status: j.Status, ErrorCode: j.ErrorCode}
+	return nil
+}
+
+// Failed is true when the response was an error, which the proxy replays without forwarding the request
+func (e Entry) Failed() bool {
+	return e.Status >= 400
+}
+
+// Trace is the responses of a degradation, in the order of their offset
+type Trace []Entry
+
+// Duration is the offset of the last entry, after which the proxy forwards every request untouched
+func (t Trace) Duration() time.Duration {
+	if len(t) == 0 {
+		return 0
+	}
+	return t[len(t)-1].Offset
+}
+
+// At returns the last entry of the operation at or before the offset, false when the operation has none yet or the
+// trace is over
+func (t Trace) At(operation string, offset time.Duration) (Entry, bool) {
+	if offset > t.Duration() {
+		return Entry{}, false
+	}
+	var found Entry
+	var ok bool
+	for _, e := range t {
+		if e.Offset > offset {
+			break
+		}
+		if e.Operation == operation {
+			found, ok = e, true
+		}
+	}
+	return found, ok
+}
+
+func (t Trace) String() string {
+	failed := map[string]int{}
+	var maxLatency time.Duration
+	for _, e := range t {
+		if e.Failed() {
+			failed[e.ErrorCode]++
+		}
+		if e.Latency > maxLatency {
+			maxLatency = e.Latency
+		}
+	}
+	codes := make([]string, 0, len(failed))
+	for code, n := range failed {
+		codes = append(codes, fmt.Sprintf("%d %s", n, code))
+	}
+	sort.Strings(codes)
+	return fmt.Sprintf("%d responses over %s, max latency %s, failures: [%s]", len(t), t.Duration(), maxLatency, strings.Join(codes, ", "))
+}
+
+// ReadTrace reads the JSON lines the proxy recorded, sorted by offset
+func ReadTrace(r io.Reader) (Trace, error) {
+	var t Trace
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return nil, fmt.Errorf("invalid entry %q: %w", line, err)
+		}
+		if e.Operation == "" {
+			return nil, fmt.Errorf("entry %q has no operation", line)
+		}
+		t = append(t, e)
+	}
+	sort.SliceStable(t, func(i, j int) bool { return t[i].Offset < t[j].Offset })
+	return t, scanner.Err()
+}
@@ -0,0 +1,34 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package flaky
+
+import "strings"
+
+var (
+	defaultRetries int
+	quarantined    = map[string]struct{}{}
+)
+
+// Configure sets the number of times a failed runner is retried unless the runner overrides it, and the runners
+// that are known to be flaky. Quarantined runners are still run and reported but never fail the suite.
+func Configure(retries int, quarantinedRunners []string) {
+	defaultRetries = retries
+	quarantined = map[string]struct{}{}
+	for _, name := range quarantinedRunners {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			quarantined[name] = struct{}{}
+		}
+	}
+}
+
+func DefaultRetries() int {
+	return defaultRetries
+}
+
+// IsQuarantined matches the runner name case-insensitively, the same way the plugins flag does
+func IsQuarantined(runnerName string) bool {
+	_, ok := quarantined[strings.ToLower(runnerName)]
+	return ok
+}
@@ -0,0 +1,332 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package golden compares the structure of JSON documents, e.g. the EMF performance logs of Container Insights,
+// against checked-in golden documents, so fields the agent adds, removes or renames from one version to the next
+// fail the suites instead of going unnoticed.
+//
+// A document is reduced to its structure before the comparison: the values become their types, e.g. "<number>",
+// the elements of an array are merged into one, and the values that change from one document to the next, e.g.
+// timestamps and ids, are stripped. A key of the golden document ending with "?" is optional.
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// Stripped replaces the values of the stripped keys, only their presence is compared
+	Stripped = "<stripped>"
+	// OptionalSuffix marks the keys which may be missing from a document
+	OptionalSuffix = "?"
+)
+
+// update rewrites the golden documents with the structure of the documents instead of comparing them
+var update bool
+
+// Configure sets whether Compare rewrites the golden documents, e.g. once a change of the agent was reviewed
+func Configure(updateGolden bool) {
+	update = updateGolden
+}
+
+// Normalizer reduces documents to their structure
+type Normalizer struct {
+	// Keep are the keys whose values are part of the structure and compared as they are, e.g. the type of a document
+	Keep []string
+	// Strip are the keys whose values change from one document to the next, e.g. timestamps, ids and labels
+	Strip []string
+}
+
+// Normalize parses the document and reduces it to its structure. The keys are matched at any depth.
+func (n Normalizer) Normalize(doc []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return nil, fmt.Errorf("failed to parse the document: %w", err)
+	}
+	return n.normalize(v), nil
+}
+
+func (n Normalizer) normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, value := range v {
+			switch {
+			case contains(n.Strip, k):
+				out[k] = Stripped
+			case contains(n.Keep, k):
+				out[k] = value
+			default:
+				out[k] = n.normalize(value)
+			}
+		}
+		return out
+	case []interface{}:
+		var merged interface{}
+		for i, e := range v {
+			if i == 0 {
+				merged = n.normalize(e)
+				continue
+			}
+			merged = Merge(merged, n.normalize(e))
+		}
+		if merged == nil {
+			return []interface{}{}
+		}
+		return []interface{}{merged}
+	default:
+		return typeOf(v)
+	}
+}
+
+// Merge merges the structures of two documents, the keys missing from either become optional and the values that
+// differ are joined, e.g. "<number|string>"
+func Merge(a, b interface{}) interface{} {
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if aok && bok {
+		out := map[string]interface{}{}
+		for _, k := range baseKeys(am, bm) {
+			av, aOptional, aFound := lookup(am, k)
+			bv, bOptional, bFound := lookup(bm, k)
+			switch {
+			case aFound && bFound:
+				key := k
+				if aOptional || bOptional {
+					key += OptionalSuffix
+				}
+				out[key] = Merge(av, bv)
+			case aFound:
+				out[k+OptionalSuffix] = av
+			default:
+				out[k+OptionalSuffix] = bv
+			}
+		}
+		return out
+	}
+	as, aok := a.([]interface{})
+	bs, bok := b.([]interface{})
+	if aok && bok {
+		switch {
+		case len(as) == 0:
+			return bs
+		case len(bs) == 0:
+			return as
+		}
+		return []interface{}{Merge(as[0], bs[0])}
+	}
+	if reflect.DeepEqual(a, b) {
+		return a
+	}
+	return alternatives(describe(a), describe(b))
+}
+
+// Kind is how a field of a document differs from the golden document
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Difference is a field of a document which differs from the golden document, a renamed field is both removed and
+// added
+type Difference struct {
+	// Path is the path of the field, e.g. kubernetes.pod_id or CloudWatchMetrics[].Metrics[].Name
+	Path   string
+	Kind   Kind
+	Golden interface{}
+	Actual interface{}
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case Added:
+		return fmt.Sprintf("%s added", d.Path)
+	case Removed:
+		return fmt.Sprintf("%s removed", d.Path)
+	default:
+		return fmt.Sprintf("%s changed from %s to %s", d.Path, describe(d.Golden), describe(d.Actual))
+	}
+}
+
+// Diff returns the fields of the document which differ from the golden document, sorted by path. A key the document
+// only has in some of the documents merged into it is present.
+func Diff(golden, actual interface{}) []Difference {
+	var diffs []Difference
+	diff("", golden, actual, &diffs)
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].Path != diffs[j].Path {
+			return diffs[i].Path < diffs[j].Path
+		}
+		return diffs[i].Kind < diffs[j].Kind
+	})
+	return diffs
+}
+
+func diff(path string, golden, actual interface{}, diffs *[]Difference) {
+	if golden == Stripped {
+		return
+	}
+	if gm, ok := golden.(map[string]interface{}); ok {
+		am, ok := actual.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: Changed, Golden: golden, Actual: actual})
+			return
+		}
+		for _, k := range baseKeys(gm, am) {
+			gv, optional, inGolden := lookup(gm, k)
+			av, _, inActual := lookup(am, k)
+			switch {
+			case inGolden && inActual:
+				diff(joinPath(path, k), gv, av, diffs)
+			case inGolden && !optional:
+				*diffs = append(*diffs, Difference{Path: joinPath(path, k), Kind: Removed, Golden: gv})
+			case inActual:
+				*diffs = append(*diffs, Difference{Path: joinPath(path, k), Kind: Added, Actual: av})
+			}
+		}
+		return
+	}
+	if gs, ok := golden.([]interface{}); ok {
+		as, ok := actual.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: Changed, Golden: golden, Actual: actual})
+			return
+		}
+		// an empty array has no structure to compare
+		if len(gs) > 0 && len(as) > 0 {
+			diff(path+"[]", gs[0], as[0], diffs)
+		}
+		return
+	}
+	if !reflect.DeepEqual(golden, actual) {
+		*diffs = append(*diffs, Difference{Path: path, Kind: Changed, Golden: golden, Actual: actual})
+	}
+}
+
+// Compare diffs the structure against the golden document at the path. The golden document is rewritten with the
+// structure instead when Configure enabled the update, which only marks the keys missing from some of the documents
+// merged into the structure as optional.
+func Compare(path string, actual interface{}) ([]Difference, error) {
+	if update {
+		content, err := json.MarshalIndent(actual, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, err
+		}
+		logger.Infof("Updating golden document %s", path)
+		return nil, os.WriteFile(path, append(content, '\n'), 0644)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read golden document %s: %w", path, err)
+	}
+	var golden interface{}
+	if err = json.Unmarshal(content, &golden); err != nil {
+		return nil, fmt.Errorf("failed to parse golden document %s: %w", path, err)
+	}
+	return Diff(golden, actual), nil
+}
+
+// lookup finds the key with or without the optional suffix
+func lookup(m map[string]interface{}, key string) (value interface{}, optional, found bool) {
+	if v, ok := m[key]; ok {
+		return v, false, true
+	}
+	if v, ok := m[key+OptionalSuffix]; ok {
+		return v, true, true
+	}
+	return nil, false, false
+}
+
+// baseKeys are the sorted keys of the maps without the optional suffix
+func baseKeys(maps ...map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			k = strings.TrimSuffix(k, OptionalSuffix)
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "<string>"
+	case float64:
+		return "<number>"
+	case bool:
+		return "<bool>"
+	case nil:
+		return "<null>"
+	default:
+		return fmt.Sprintf("<%T>", v)
+	}
+}
+
+// describe is the value as it is written in a golden document
+func describe(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(content)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// alternatives joins the values a field has in different documents, e.g. <number> and <string> into <number|string>
+func alternatives(a, b string) string {
+	placeholders := isPlaceholder(a) && isPlaceholder(b)
+	values := strings.Split(strings.Trim(a, "<>"), "|")
+	for _, v := range strings.Split(strings.Trim(b, "<>"), "|") {
+		if !contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+	if placeholders {
+		return "<" + strings.Join(values, "|") + ">"
+	}
+	return strings.Join(values, "|")
+}
+
+func isPlaceholder(s string) bool {
+	return strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
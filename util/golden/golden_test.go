@@ -0,0 +1,116 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package golden
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var containerInsights = Normalizer{Keep: []string{"Type"}, Strip: []string{"Timestamp", "labels"}}
+
+const podLog = `{
+  "Type": "Pod",
+  "Timestamp": "1700000000000",
+  "PodName": "cloudwatch-agent",
+  "pod_cpu_utilization": 1.5,
+  "Sources": ["cadvisor", "pod", "calculated"],
+  "kubernetes": {"host": "ip-10-0-0-1", "labels": {"app": "cwagent"}, "pod_owners": [{"owner_kind": "DaemonSet", "owner_name": "cwagent"}]},
+  "CloudWatchMetrics": [{"Namespace": "ContainerInsights", "Dimensions": [["ClusterName"], ["ClusterName", "Namespace"]], "Metrics": [{"Name": "pod_cpu_utilization", "Unit": "Percent"}, {"Name": "pod_number_of_containers"}]}]
+}`
+
+func TestNormalize(t *testing.T) {
+	actual, err := containerInsights.Normalize([]byte(podLog))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Type":                "Pod",
+		"Timestamp":           Stripped,
+		"PodName":             "<string>",
+		"pod_cpu_utilization": "<number>",
+		"Sources":             []interface{}{"<string>"},
+		"kubernetes": map[string]interface{}{
+			"host":       "<string>",
+			"labels":     Stripped,
+			"pod_owners": []interface{}{map[string]interface{}{"owner_kind": "<string>", "owner_name": "<string>"}},
+		},
+		"CloudWatchMetrics": []interface{}{map[string]interface{}{
+			"Namespace":  "<string>",
+			"Dimensions": []interface{}{[]interface{}{"<string>"}},
+			"Metrics":    []interface{}{map[string]interface{}{"Name": "<string>", "Unit?": "<string>"}},
+		}},
+	}, actual)
+
+	_, err = containerInsights.Normalize([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	a := map[string]interface{}{"Type": "Pod", "shared": "<number>", "only_a": "<number>", "mixed": "<number>"}
+	b := map[string]interface{}{"Type": "Pod", "shared": "<number>", "only_b?": "<string>", "mixed": "<string>"}
+	assert.Equal(t, map[string]interface{}{
+		"Type":    "Pod",
+		"shared":  "<number>",
+		"only_a?": "<number>",
+		"only_b?": "<string>",
+		"mixed":   "<number|string>",
+	}, Merge(a, b))
+	assert.Equal(t, "Node|Pod", Merge("Pod", "Node"))
+}
+
+func TestDiff(t *testing.T) {
+	golden := map[string]interface{}{
+		"Type":                   "Pod",
+		"Timestamp":              Stripped,
+		"pod_cpu_utilization":    "<number>",
+		"pod_memory_rss?":        "<number>",
+		"pod_number_of_restarts": "<number>",
+		"kubernetes":             map[string]interface{}{"host": "<string>"},
+		"Sources":                []interface{}{"<string>"},
+	}
+	actual := map[string]interface{}{
+		"Type":                   "Pod",
+		"Timestamp":              Stripped,
+		"pod_cpu_utilization":    "<string>",
+		"pod_container_restarts": "<number>",
+		"kubernetes":             map[string]interface{}{"host": "<string>", "pod_id?": "<string>"},
+		"Sources":                []interface{}{},
+	}
+	diffs := Diff(golden, actual)
+	assert.Equal(t, []Difference{
+		{Path: "kubernetes.pod_id", Kind: Added, Actual: "<string>"},
+		{Path: "pod_container_restarts", Kind: Added, Actual: "<number>"},
+		{Path: "pod_cpu_utilization", Kind: Changed, Golden: "<number>", Actual: "<string>"},
+		{Path: "pod_number_of_restarts", Kind: Removed, Golden: "<number>"},
+	}, diffs)
+	assert.Equal(t, "pod_cpu_utilization changed from <number> to <string>", diffs[2].String())
+	assert.Empty(t, Diff(golden, golden))
+}
+
+func TestCompare(t *testing.T) {
+	defer Configure(false)
+	path := filepath.Join(t.TempDir(), "golden", "pod.json")
+	actual, err := containerInsights.Normalize([]byte(podLog))
+	require.NoError(t, err)
+
+	_, err = Compare(path, actual)
+	assert.Error(t, err, "the golden document does not exist yet")
+
+	Configure(true)
+	diffs, err := Compare(path, actual)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	Configure(false)
+	diffs, err = Compare(path, actual)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	delete(actual.(map[string]interface{}), "PodName")
+	diffs, err = Compare(path, actual)
+	require.NoError(t, err)
+	assert.Equal(t, []Difference{{Path: "PodName", Kind: Removed, Golden: "<string>"}}, diffs)
+}
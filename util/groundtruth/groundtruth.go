@@ -0,0 +1,145 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package groundtruth reads what the host itself reports, e.g. /proc/meminfo, while the agent runs, so the values
+// the agent published can be compared with an independent reading instead of only a range.
+package groundtruth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// ErrUnsupported is returned by the sampler on the platforms it cannot read the host on
+var ErrUnsupported = errors.New("reading the host is not supported on this platform")
+
+// Sample is a reading of the host at one point in time
+type Sample struct {
+	Time time.Time
+	// Values are keyed by the metric the agent publishes them as, e.g. mem_available_percent, or by DeviceKey for
+	// the metrics of a device
+	Values map[string]float64
+}
+
+// DeviceKey is the key of the value of a device's metric, e.g. DeviceKey("diskio_write_bytes", "nvme0n1")
+func DeviceKey(metricName, device string) string {
+	return metricName + "/" + device
+}
+
+// Series is the host read at an interval while a runner ran
+type Series struct {
+	Samples []Sample
+}
+
+// Window returns the time of the first and the last sample
+func (s Series) Window() (time.Time, time.Time) {
+	if len(s.Samples) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	return s.Samples[0].Time, s.Samples[len(s.Samples)-1].Time
+}
+
+// Average returns the average of the samples with the key, false when none has it
+func (s Series) Average(key string) (float64, bool) {
+	var sum float64
+	var n int
+	for _, sample := range s.Samples {
+		if v, ok := sample.Values[key]; ok {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+// Increase returns how much a counter grew from the first to the last sample with the key, false when fewer than two
+// samples have it
+func (s Series) Increase(key string) (float64, bool) {
+	var first, last float64
+	var n int
+	for _, sample := range s.Samples {
+		if v, ok := sample.Values[key]; ok {
+			if n == 0 {
+				first = v
+			}
+			last = v
+			n++
+		}
+	}
+	if n < 2 {
+		return 0, false
+	}
+	return last - first, true
+}
+
+// Read reads the host once, for the values which do not change while the agent runs, e.g. mem_total
+func Read() (Sample, error) {
+	from := newSampler()
+	defer from.close()
+	return from.sample()
+}
+
+// sampler reads the host, it is closed once the collector stops
+type sampler interface {
+	sample() (Sample, error)
+	close()
+}
+
+// Collector reads the host in the background
+type Collector struct {
+	mu     sync.Mutex
+	series Series
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+// Start reads the host every interval until the collector is stopped, the interval should be the collection interval
+// of the agent
+func Start(interval time.Duration) *Collector {
+	return start(newSampler(), interval)
+}
+
+func start(from sampler, every time.Duration) *Collector {
+	c := &Collector{done: make(chan struct{})}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer from.close()
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			if s, err := from.sample(); err != nil {
+				logger.Warnf("failed to read the host: %v", err)
+			} else {
+				c.mu.Lock()
+				c.series.Samples = append(c.series.Samples, s)
+				c.mu.Unlock()
+			}
+			select {
+			case <-ticker.C:
+			case <-c.done:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// Stop stops reading and returns the series. It can be called more than once, and on a nil Collector.
+func (c *Collector) Stop() Series {
+	if c == nil {
+		return Series{}
+	}
+	c.once.Do(func() { close(c.done) })
+	c.wg.Wait()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.series
+}
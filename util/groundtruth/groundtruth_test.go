@@ -0,0 +1,66 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package groundtruth
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeries(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := Series{Samples: []Sample{
+		{Time: start, Values: map[string]float64{"mem_total": 10, "disk": 100}},
+		{Time: start.Add(10 * time.Second), Values: map[string]float64{"mem_total": 20, "cpu_usage_active": 5}},
+		{Time: start.Add(20 * time.Second), Values: map[string]float64{"mem_total": 30, "disk": 160}},
+	}}
+
+	first, last := s.Window()
+	assert.Equal(t, start, first)
+	assert.Equal(t, start.Add(20*time.Second), last)
+
+	average, ok := s.Average("mem_total")
+	assert.True(t, ok)
+	assert.Equal(t, 20.0, average)
+	average, ok = s.Average("cpu_usage_active")
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, average)
+	_, ok = s.Average("missing")
+	assert.False(t, ok)
+
+	increase, ok := s.Increase("disk")
+	assert.True(t, ok)
+	assert.Equal(t, 60.0, increase)
+	_, ok = s.Increase("cpu_usage_active")
+	assert.False(t, ok)
+}
+
+type countingSampler struct {
+	n      int64
+	closed int64
+}
+
+func (s *countingSampler) sample() (Sample, error) {
+	return Sample{Time: time.Now(), Values: map[string]float64{"n": float64(atomic.AddInt64(&s.n, 1))}}, nil
+}
+
+func (s *countingSampler) close() {
+	atomic.AddInt64(&s.closed, 1)
+}
+
+func TestCollector(t *testing.T) {
+	s := &countingSampler{}
+	c := start(s, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	series := c.Stop()
+	assert.GreaterOrEqual(t, len(series.Samples), 2)
+	assert.Equal(t, series, c.Stop())
+	assert.Equal(t, int64(1), atomic.LoadInt64(&s.closed))
+
+	var nilCollector *Collector
+	assert.Empty(t, nilCollector.Stop().Samples)
+}
@@ -0,0 +1,107 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package groundtruth
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	pdhFmtDouble = 0x00000200
+	// pdhCstatusNewData is the highest status of a valid value, PDH_CSTATUS_VALID_DATA is 0
+	pdhCstatusNewData = 0x00000001
+)
+
+var (
+	pdh                         = windows.NewLazySystemDLL("pdh.dll")
+	pdhOpenQuery                = pdh.NewProc("PdhOpenQueryW")
+	pdhAddEnglishCounter        = pdh.NewProc("PdhAddEnglishCounterW")
+	pdhCollectQueryData         = pdh.NewProc("PdhCollectQueryData")
+	pdhGetFormattedCounterValue = pdh.NewProc("PdhGetFormattedCounterValue")
+	pdhCloseQuery               = pdh.NewProc("PdhCloseQuery")
+)
+
+// pdhFmtCounterValueDouble is PDH_FMT_COUNTERVALUE with the double of its union, the padding aligns it like the C
+// union on 32 bit platforms too
+type pdhFmtCounterValueDouble struct {
+	CStatus     uint32
+	_           uint32
+	DoubleValue float64
+}
+
+// pdhCounter is a counter the agent collects as the metric of the key, which is named <object> <counter> by default
+type pdhCounter struct {
+	path string
+	key  string
+}
+
+var pdhCounters = []pdhCounter{
+	{path: `\Memory\Available Bytes`, key: "Memory Available Bytes"},
+	{path: `\Memory\% Committed Bytes In Use`, key: "Memory % Committed Bytes In Use"},
+	{path: `\Processor(_Total)\% Processor Time`, key: DeviceKey("Processor % Processor Time", "_Total")},
+	{path: `\LogicalDisk(C:)\% Free Space`, key: DeviceKey("LogicalDisk % Free Space", "C:")},
+}
+
+// pdhSampler reads the counters with a PDH query, the way the agent reads them. Rate counters such as
+// % Processor Time are computed from the previous collection, so the first sample has none of them.
+type pdhSampler struct {
+	query    uintptr
+	counters []uintptr
+	err      error
+}
+
+func newSampler() sampler {
+	p := &pdhSampler{}
+	if r, _, _ := pdhOpenQuery.Call(0, 0, uintptr(unsafe.Pointer(&p.query))); r != 0 {
+		p.err = fmt.Errorf("PdhOpenQuery failed with 0x%x", r)
+		return p
+	}
+	for _, c := range pdhCounters {
+		path, err := windows.UTF16PtrFromString(c.path)
+		if err != nil {
+			p.err = err
+			return p
+		}
+		var counter uintptr
+		if r, _, _ := pdhAddEnglishCounter.Call(p.query, uintptr(unsafe.Pointer(path)), 0, uintptr(unsafe.Pointer(&counter))); r != 0 {
+			p.err = fmt.Errorf("PdhAddEnglishCounter %s failed with 0x%x", c.path, r)
+			return p
+		}
+		p.counters = append(p.counters, counter)
+	}
+	return p
+}
+
+func (p *pdhSampler) sample() (Sample, error) {
+	s := Sample{Time: time.Now(), Values: map[string]float64{}}
+	if p.err != nil {
+		return s, p.err
+	}
+	if r, _, _ := pdhCollectQueryData.Call(p.query); r != 0 {
+		return s, fmt.Errorf("PdhCollectQueryData failed with 0x%x", r)
+	}
+	for i, counter := range p.counters {
+		var value pdhFmtCounterValueDouble
+		r, _, _ := pdhGetFormattedCounterValue.Call(counter, pdhFmtDouble, 0, uintptr(unsafe.Pointer(&value)))
+		// a rate counter has no value until the second collection
+		if r != 0 || value.CStatus > pdhCstatusNewData {
+			continue
+		}
+		s.Values[pdhCounters[i].key] = value.DoubleValue
+	}
+	return s, nil
+}
+
+func (p *pdhSampler) close() {
+	if p.query != 0 {
+		pdhCloseQuery.Call(p.query)
+		p.query = 0
+	}
+}
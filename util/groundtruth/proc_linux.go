@@ -0,0 +1,169 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package groundtruth
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sectorSize is the unit of the sectors in /proc/diskstats, whatever the sector size of the device
+const sectorSize = 512
+
+// cpuTimes are the jiffies of the cpu line of /proc/stat
+type cpuTimes struct {
+	total  float64
+	idle   float64
+	iowait float64
+}
+
+// procSampler reads /proc/meminfo, /proc/stat and /proc/diskstats. The cpu usage is computed like the agent does,
+// from the difference to the previous sample, so the first sample has none.
+type procSampler struct {
+	previous *cpuTimes
+}
+
+func newSampler() sampler {
+	return &procSampler{}
+}
+
+func (p *procSampler) sample() (Sample, error) {
+	s := Sample{Time: time.Now(), Values: map[string]float64{}}
+
+	if err := readFile("/proc/meminfo", func(r io.Reader) error { return parseMeminfo(r, s.Values) }); err != nil {
+		return s, err
+	}
+
+	var current cpuTimes
+	if err := readFile("/proc/stat", func(r io.Reader) (err error) {
+		current, err = parseStat(r)
+		return err
+	}); err != nil {
+		return s, err
+	}
+	if p.previous != nil && current.total > p.previous.total {
+		total := current.total - p.previous.total
+		idle := current.idle - p.previous.idle
+		s.Values["cpu_usage_idle"] = 100 * idle / total
+		s.Values["cpu_usage_iowait"] = 100 * (current.iowait - p.previous.iowait) / total
+		s.Values["cpu_usage_active"] = 100 * (total - idle) / total
+	}
+	p.previous = &current
+
+	if err := readFile("/proc/diskstats", func(r io.Reader) error { return parseDiskstats(r, s.Values) }); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func (p *procSampler) close() {}
+
+func readFile(path string, parse func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return parse(f)
+}
+
+// parseMeminfo reads the memory the way the mem plugin of the agent reports it
+func parseMeminfo(r io.Reader, values map[string]float64) error {
+	kb := map[string]float64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			continue
+		}
+		kb[strings.TrimSuffix(fields[0], ":")] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	total, available := kb["MemTotal"], kb["MemAvailable"]
+	if total == 0 {
+		return errors.New("/proc/meminfo has no MemTotal")
+	}
+	values["mem_total"] = total * 1024
+	values["mem_available"] = available * 1024
+	values["mem_available_percent"] = 100 * available / total
+	return nil
+}
+
+// parseStat reads the cpu line of /proc/stat, guest time is already counted in user time so it is left out of the
+// total
+func parseStat(r io.Reader) (cpuTimes, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 9 || fields[0] != "cpu" {
+			continue
+		}
+		var t cpuTimes
+		// user nice system idle iowait irq softirq steal
+		for i, field := range fields[1:9] {
+			value, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return t, err
+			}
+			t.total += value
+			switch i {
+			case 3:
+				t.idle = value
+			case 4:
+				t.iowait = value
+			}
+		}
+		return t, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return cpuTimes{}, err
+	}
+	return cpuTimes{}, errors.New("/proc/stat has no cpu line")
+}
+
+// parseDiskstats reads the counters of every device the way the diskio plugin of the agent reports them, keyed by
+// DeviceKey
+func parseDiskstats(r io.Reader, values map[string]float64) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 14 {
+			continue
+		}
+		device := fields[2]
+		for _, counter := range []struct {
+			metricName string
+			field      int
+			scale      float64
+		}{
+			{"diskio_reads", 3, 1},
+			{"diskio_read_bytes", 5, sectorSize},
+			{"diskio_read_time", 6, 1},
+			{"diskio_writes", 7, 1},
+			{"diskio_write_bytes", 9, sectorSize},
+			{"diskio_write_time", 10, 1},
+			{"diskio_io_time", 12, 1},
+		} {
+			value, err := strconv.ParseFloat(fields[counter.field], 64)
+			if err != nil {
+				return err
+			}
+			values[DeviceKey(counter.metricName, device)] = value * counter.scale
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,50 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package groundtruth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	values := map[string]float64{}
+	require.NoError(t, parseMeminfo(strings.NewReader("MemTotal:        8000000 kB\nMemFree:         1000000 kB\nMemAvailable:    6000000 kB\n"), values))
+	assert.Equal(t, 8000000.0*1024, values["mem_total"])
+	assert.Equal(t, 6000000.0*1024, values["mem_available"])
+	assert.Equal(t, 75.0, values["mem_available_percent"])
+
+	assert.Error(t, parseMeminfo(strings.NewReader("MemFree: 1 kB\n"), values))
+}
+
+func TestParseStat(t *testing.T) {
+	times, err := parseStat(strings.NewReader("cpu  100 10 50 800 40 0 0 0 20 0\ncpu0 100 10 50 800 40 0 0 0 20 0\n"))
+	require.NoError(t, err)
+	assert.Equal(t, cpuTimes{total: 1000, idle: 800, iowait: 40}, times)
+
+	_, err = parseStat(strings.NewReader("intr 1 2 3\n"))
+	assert.Error(t, err)
+}
+
+func TestParseDiskstats(t *testing.T) {
+	values := map[string]float64{}
+	require.NoError(t, parseDiskstats(strings.NewReader(" 259       0 nvme0n1 100 0 2000 30 50 0 4000 60 0 70 90 0 0 0 0\n"), values))
+	assert.Equal(t, 100.0, values[DeviceKey("diskio_reads", "nvme0n1")])
+	assert.Equal(t, 2000.0*512, values[DeviceKey("diskio_read_bytes", "nvme0n1")])
+	assert.Equal(t, 50.0, values[DeviceKey("diskio_writes", "nvme0n1")])
+	assert.Equal(t, 4000.0*512, values[DeviceKey("diskio_write_bytes", "nvme0n1")])
+	assert.Equal(t, 70.0, values[DeviceKey("diskio_io_time", "nvme0n1")])
+}
+
+func TestSampler(t *testing.T) {
+	first, err := newSampler().sample()
+	require.NoError(t, err)
+	assert.Positive(t, first.Values["mem_total"])
+	assert.NotContains(t, first.Values, "cpu_usage_active")
+}
@@ -0,0 +1,18 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !linux && !windows
+
+package groundtruth
+
+type unsupportedSampler struct{}
+
+func newSampler() sampler {
+	return unsupportedSampler{}
+}
+
+func (unsupportedSampler) sample() (Sample, error) {
+	return Sample{}, ErrUnsupported
+}
+
+func (unsupportedSampler) close() {}
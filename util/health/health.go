@@ -0,0 +1,123 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	suiteDimension  = "Suite"
+	runnerDimension = "Runner"
+
+	RunnerDuration    = "RunnerDuration"
+	RunnersPassed     = "RunnersPassed"
+	RunnersFailed     = "RunnersFailed"
+	RunnerRetries     = "RunnerRetries"
+	ApiRetries        = "ApiRetries"
+	ApiThrottles      = "ApiThrottles"
+	SuiteFailed       = "SuiteFailed"
+	healthMetricCount = 6
+)
+
+type runnerRecord struct {
+	name     string
+	duration time.Duration
+	passed   bool
+}
+
+var (
+	// namespace is empty unless -healthNamespace is provided, which disables publishing
+	namespace string
+
+	mu            sync.Mutex
+	runners       []runnerRecord
+	runnerRetries int
+	// the SDK counters are process wide, so only the increase since the last publish is sent
+	lastApiRetries   int64
+	lastApiThrottles int64
+)
+
+// SetNamespace configures the namespace the framework publishes its own metrics to
+func SetNamespace(ns string) {
+	namespace = ns
+}
+
+// RecordRunner records the outcome of a runner. Records are kept in memory until Publish is called.
+func RecordRunner(name string, duration time.Duration, passed bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	runners = append(runners, runnerRecord{name: name, duration: duration, passed: passed})
+}
+
+// RecordRetry records that a runner is being retried
+func RecordRetry() {
+	mu.Lock()
+	defer mu.Unlock()
+	runnerRetries++
+}
+
+// Publish sends the recorded runner metrics and the AWS API retry and throttle counts to CloudWatch and resets the
+// records, so a long-running process can publish once per suite execution.
+func Publish(suite string) {
+	mu.Lock()
+	records, retries := runners, runnerRetries
+	runners, runnerRetries = nil, 0
+	apiRetries, apiThrottles := awsservice.GetApiRetryCount(), awsservice.GetApiThrottleCount()
+	apiRetriesDelta, apiThrottlesDelta := apiRetries-lastApiRetries, apiThrottles-lastApiThrottles
+	lastApiRetries, lastApiThrottles = apiRetries, apiThrottles
+	mu.Unlock()
+
+	if namespace == "" {
+		return
+	}
+
+	suiteDims := []types.Dimension{{Name: aws.String(suiteDimension), Value: aws.String(suite)}}
+	var passed, failed float64
+	data := make([]types.MetricDatum, 0, len(records)+healthMetricCount)
+	for _, r := range records {
+		if r.passed {
+			passed++
+		} else {
+			failed++
+		}
+		data = append(data, types.MetricDatum{
+			MetricName: aws.String(RunnerDuration),
+			Dimensions: append(suiteDims, types.Dimension{Name: aws.String(runnerDimension), Value: aws.String(r.name)}),
+			Value:      aws.Float64(r.duration.Seconds()),
+			Unit:       types.StandardUnitSeconds,
+		})
+	}
+
+	suiteFailed := 0.0
+	if failed > 0 {
+		suiteFailed = 1
+	}
+	for name, value := range map[string]float64{
+		RunnersPassed: passed,
+		RunnersFailed: failed,
+		RunnerRetries: float64(retries),
+		ApiRetries:    float64(apiRetriesDelta),
+		ApiThrottles:  float64(apiThrottlesDelta),
+		SuiteFailed:   suiteFailed,
+	} {
+		data = append(data, types.MetricDatum{
+			MetricName: aws.String(name),
+			Dimensions: suiteDims,
+			Value:      aws.Float64(value),
+			Unit:       types.StandardUnitCount,
+		})
+	}
+
+	if err := awsservice.PutMetricData(namespace, data); err != nil {
+		logger.Errorf("Failed to publish framework health metrics to %s: %v", namespace, err)
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const (
+	// fieldManager owns the fields of the objects applied by the tests
+	fieldManager = "cwagent-test"
+	pollInterval = 5 * time.Second
+	// restartedAtAnnotation is the annotation kubectl rollout restart sets on the pod template
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+// Client manages the objects of the cluster the tests run against, with the kubeconfig kubectl would use
+type Client struct {
+	Clientset kubernetes.Interface
+	dynamic   dynamic.Interface
+	mapper    meta.RESTMapper
+	config    *rest.Config
+}
+
+var (
+	defaultClient     *Client
+	defaultClientErr  error
+	defaultClientOnce sync.Once
+)
+
+// Default returns the client of the in-cluster config when the tests run in a pod, of $KUBECONFIG or ~/.kube/config
+// otherwise. It is created once per run.
+func Default() (*Client, error) {
+	defaultClientOnce.Do(func() {
+		defaultClient, defaultClientErr = NewClient("")
+	})
+	return defaultClient, defaultClientErr
+}
+
+// NewClient creates a client from the kubeconfig, see Default for the config used when it is empty
+func NewClient(kubeconfig string) (*Client, error) {
+	config, err := loadConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Clientset: clientset,
+		dynamic:   dynamicClient,
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(clientset.Discovery())),
+		config:    config,
+	}, nil
+}
+
+func loadConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			return config, nil
+		}
+		kubeconfig = os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
+	}
+	if kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		kubeconfig = filepath.Join(home, clientcmd.RecommendedHomeDir, clientcmd.RecommendedFileName)
+	}
+	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+}
+
+// ApplyFile applies the manifest file, see Apply
+func (c *Client) ApplyFile(ctx context.Context, path string) error {
+	manifest, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.Apply(ctx, manifest)
+}
+
+// Apply server side applies every object of the multi document yaml or json manifest, creating the ones that do not
+// exist, like kubectl apply --server-side --force-conflicts
+func (c *Client) Apply(ctx context.Context, manifest []byte) error {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	for {
+		var object unstructured.Unstructured
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode the manifest: %w", err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		if err := c.applyObject(ctx, &object); err != nil {
+			return fmt.Errorf("failed to apply %s %s: %w", object.GetKind(), object.GetName(), err)
+		}
+	}
+}
+
+func (c *Client) applyObject(ctx context.Context, object *unstructured.Unstructured) error {
+	gvk := object.GroupVersionKind()
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	var resource dynamic.ResourceInterface = c.dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace := object.GetNamespace()
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		resource = c.dynamic.Resource(mapping.Resource).Namespace(namespace)
+	}
+
+	data, err := object.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	force := true
+	_, err = resource.Patch(ctx, object.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &force,
+	})
+	if err == nil {
+		logger.Infof("Applied %s %s", gvk.Kind, object.GetName())
+	}
+	return err
+}
+
+// isDaemonSetRolledOut is true once the controller observed the latest spec and every scheduled pod is updated and
+// available, the same condition kubectl rollout status waits for
+func isDaemonSetRolledOut(ds *appsv1.DaemonSet) bool {
+	status := ds.Status
+	return status.ObservedGeneration >= ds.Generation &&
+		status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+		status.NumberAvailable == status.DesiredNumberScheduled
+}
+
+// WaitForDaemonSetRollout polls the DaemonSet until it rolled out to every node it is scheduled on
+func (c *Client) WaitForDaemonSetRollout(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ds, err := c.Clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if isDaemonSetRolledOut(ds) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("daemonset %s/%s did not roll out after %s, %d of %d pods updated and %d available",
+				namespace, name, timeout, ds.Status.UpdatedNumberScheduled, ds.Status.DesiredNumberScheduled, ds.Status.NumberAvailable)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// RestartDaemonSet replaces the pods of the DaemonSet, like kubectl rollout restart, and waits for the rollout
+func (c *Client) RestartDaemonSet(ctx context.Context, namespace, name string, timeout time.Duration) error {
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339))
+	_, err := c.Clientset.AppsV1().DaemonSets(namespace).Patch(ctx, name, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{
+		FieldManager: fieldManager,
+	})
+	if err != nil {
+		return err
+	}
+	return c.WaitForDaemonSetRollout(ctx, namespace, name, timeout)
+}
+
+// ListPods returns the pods of the namespace matching the label selector, e.g. app=cloudwatch-agent
+func (c *Client) ListPods(ctx context.Context, namespace, labelSelector string) ([]corev1.Pod, error) {
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// PodLogs returns the logs the container of the pod wrote since the time, all of them for the zero time
+func (c *Client) PodLogs(ctx context.Context, namespace, pod, container string, since time.Time) (string, error) {
+	options := &corev1.PodLogOptions{Container: container}
+	if !since.IsZero() {
+		sinceTime := metav1.NewTime(since)
+		options.SinceTime = &sinceTime
+	}
+	stream, err := c.Clientset.CoreV1().Pods(namespace).GetLogs(pod, options).Stream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+	logs, err := io.ReadAll(stream)
+	return string(logs), err
+}
+
+// Exec runs the command in the container of the pod and returns what it wrote to stdout and stderr. The error is set
+// when the command exits with a non zero code.
+func (c *Client) Exec(ctx context.Context, namespace, pod, container string, command []string) (string, string, error) {
+	request := c.Clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.config, "POST", request.URL())
+	if err != nil {
+		return "", "", err
+	}
+	var stdout, stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		err = fmt.Errorf("failed to exec %q in %s/%s: %w", strings.Join(command, " "), namespace, pod, err)
+	}
+	return stdout.String(), stderr.String(), err
+}
+
+// ListNodes returns the nodes of the cluster
+func (c *Client) ListNodes(ctx context.Context) ([]corev1.Node, error) {
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+// IsNodeReady is true when the Ready condition of the node is True
+func IsNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// NodeInstanceId returns the EC2 instance id of the node, from its provider id aws:///<availability zone>/<instance id>
+func NodeInstanceId(node corev1.Node) string {
+	providerId := node.Spec.ProviderID
+	return providerId[strings.LastIndex(providerId, "/")+1:]
+}
@@ -0,0 +1,90 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func daemonSet(generation, observedGeneration int64, desired, updated, available int32) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloudwatch-agent", Namespace: "amazon-cloudwatch", Generation: generation},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     observedGeneration,
+			DesiredNumberScheduled: desired,
+			UpdatedNumberScheduled: updated,
+			NumberAvailable:        available,
+		},
+	}
+}
+
+func TestIsDaemonSetRolledOut(t *testing.T) {
+	assert.True(t, isDaemonSetRolledOut(daemonSet(2, 2, 3, 3, 3)))
+	// the controller has not seen the new spec yet
+	assert.False(t, isDaemonSetRolledOut(daemonSet(3, 2, 3, 3, 3)))
+	assert.False(t, isDaemonSetRolledOut(daemonSet(2, 2, 3, 2, 3)))
+	assert.False(t, isDaemonSetRolledOut(daemonSet(2, 2, 3, 3, 2)))
+}
+
+func TestWaitForDaemonSetRollout(t *testing.T) {
+	client := &Client{Clientset: fake.NewSimpleClientset(daemonSet(1, 1, 2, 2, 2))}
+	require.NoError(t, client.WaitForDaemonSetRollout(context.Background(), "amazon-cloudwatch", "cloudwatch-agent", time.Minute))
+
+	client = &Client{Clientset: fake.NewSimpleClientset(daemonSet(1, 1, 2, 1, 2))}
+	err := client.WaitForDaemonSetRollout(context.Background(), "amazon-cloudwatch", "cloudwatch-agent", 0)
+	assert.ErrorContains(t, err, "1 of 2 pods updated")
+}
+
+func TestRestartDaemonSetSetsRestartedAt(t *testing.T) {
+	client := &Client{Clientset: fake.NewSimpleClientset(daemonSet(1, 1, 1, 1, 1))}
+	require.NoError(t, client.RestartDaemonSet(context.Background(), "amazon-cloudwatch", "cloudwatch-agent", time.Minute))
+
+	ds, err := client.Clientset.AppsV1().DaemonSets("amazon-cloudwatch").Get(context.Background(), "cloudwatch-agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ds.Spec.Template.Annotations[restartedAtAnnotation])
+}
+
+func TestPodLogs(t *testing.T) {
+	client := &Client{Clientset: fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "cloudwatch-agent-abcde", Namespace: "amazon-cloudwatch"},
+	})}
+	logs, err := client.PodLogs(context.Background(), "amazon-cloudwatch", "cloudwatch-agent-abcde", "", time.Now())
+	require.NoError(t, err)
+	// the fake clientset returns a fixed body for every log request
+	assert.Equal(t, "fake logs", logs)
+}
+
+func TestNodes(t *testing.T) {
+	ready := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-1.ec2.internal"},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-west-2a/i-0123456789abcdef0"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	notReady := corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-2.ec2.internal"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionUnknown},
+		}},
+	}
+	client := &Client{Clientset: fake.NewSimpleClientset(&ready, &notReady)}
+
+	nodes, err := client.ListNodes(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	assert.True(t, IsNodeReady(ready))
+	assert.False(t, IsNodeReady(notReady))
+	assert.Equal(t, "i-0123456789abcdef0", NodeInstanceId(ready))
+}
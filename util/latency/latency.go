@@ -0,0 +1,93 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package latency
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// Stats is the distribution of the delivery latency, the time from a line being written to CloudWatch Logs
+// ingesting it, of the lines a Recorder recorded
+type Stats struct {
+	// Count is the number of recorded lines found in CloudWatch Logs, Missing the number that were not
+	Count   int
+	Missing int
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("p50 %s, p90 %s, p99 %s, max %s over %d lines, %d missing",
+		s.P50.Round(time.Millisecond), s.P90.Round(time.Millisecond), s.P99.Round(time.Millisecond),
+		s.Max.Round(time.Millisecond), s.Count, s.Missing)
+}
+
+// Recorder records the wall-clock time each log line is written. Lines are matched to log events by their message,
+// so every recorded line has to be unique.
+type Recorder struct {
+	mu      sync.Mutex
+	written map[string]time.Time
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{written: map[string]time.Time{}}
+}
+
+// Record records the line was written now. It is called right after the line is written to the file.
+func (r *Recorder) Record(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.written[line] = time.Now()
+}
+
+// Measure computes the latency of the recorded lines from the ingestion time of the events. Events of lines that
+// were not recorded are ignored, recorded lines without an event are counted as missing.
+func (r *Recorder) Measure(events []types.OutputLogEvent) Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	latencies := make([]time.Duration, 0, len(r.written))
+	found := map[string]struct{}{}
+	for _, e := range events {
+		if e.Message == nil || e.IngestionTime == nil {
+			continue
+		}
+		writeTime, ok := r.written[*e.Message]
+		if !ok {
+			continue
+		}
+		if _, ok = found[*e.Message]; ok {
+			continue
+		}
+		found[*e.Message] = struct{}{}
+		latencies = append(latencies, time.UnixMilli(*e.IngestionTime).Sub(writeTime))
+	}
+
+	stats := Stats{Count: len(latencies), Missing: len(r.written) - len(latencies)}
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	stats.P50 = percentile(latencies, 50)
+	stats.P90 = percentile(latencies, 90)
+	stats.P99 = percentile(latencies, 99)
+	stats.Max = latencies[len(latencies)-1]
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,48 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package latency
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeasure(t *testing.T) {
+	r := NewRecorder()
+	written := time.Now()
+	var events []types.OutputLogEvent
+	for i := 1; i <= 100; i++ {
+		line := fmt.Sprintf("line %d", i)
+		r.written[line] = written
+		events = append(events, types.OutputLogEvent{
+			Message:       aws.String(line),
+			IngestionTime: aws.Int64(written.Add(time.Duration(i) * time.Second).UnixMilli()),
+		})
+	}
+	// a line that was not recorded and a duplicate delivery are both ignored
+	events = append(events,
+		types.OutputLogEvent{Message: aws.String("not recorded"), IngestionTime: aws.Int64(written.UnixMilli())},
+		types.OutputLogEvent{Message: aws.String("line 1"), IngestionTime: aws.Int64(written.Add(time.Hour).UnixMilli())},
+	)
+	r.written["never delivered"] = written
+
+	stats := r.Measure(events)
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 1, stats.Missing)
+	assert.Equal(t, 50*time.Second, stats.P50)
+	assert.Equal(t, 90*time.Second, stats.P90)
+	assert.Equal(t, 99*time.Second, stats.P99)
+	assert.Equal(t, 100*time.Second, stats.Max)
+}
+
+func TestMeasureWithoutEvents(t *testing.T) {
+	r := NewRecorder()
+	r.Record("line")
+	assert.Equal(t, Stats{Missing: 1}, r.Measure(nil))
+}
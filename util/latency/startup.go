@@ -0,0 +1,172 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package latency
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// DefaultPollInterval is how often a StartupWatch checks the pipelines which are not visible yet
+const DefaultPollInterval = 5 * time.Second
+
+// maxStartup bounds the startup latency of every pipeline, 0 only reports it
+var maxStartup time.Duration
+
+// Configure sets the bound of the startup latency of the pipelines, 0 only reports it
+func Configure(maxStartupLatency time.Duration) {
+	maxStartup = maxStartupLatency
+}
+
+// MaxStartup is the configured bound of the startup latency, 0 when it is only reported
+func MaxStartup() time.Duration {
+	return maxStartup
+}
+
+// Pipeline is a pipeline of the agent whose first datapoint or log event is watched for, e.g. the metrics of a
+// namespace or the events of a log stream
+type Pipeline struct {
+	Name string
+	// Visible reports whether the pipeline published anything since the agent started. A NotFoundYet error is the
+	// same as not visible, other errors are logged and the pipeline is checked again.
+	Visible func(since time.Time) (bool, error)
+}
+
+// PipelineStartup is the time from the agent start to the first datapoint or log event of a pipeline being visible
+type PipelineStartup struct {
+	Name    string
+	Latency time.Duration
+	// Visible is false when nothing of the pipeline was visible by the time the watch stopped
+	Visible bool
+}
+
+func (p PipelineStartup) String() string {
+	if !p.Visible {
+		return fmt.Sprintf("%s not visible", p.Name)
+	}
+	return fmt.Sprintf("%s %s", p.Name, p.Latency.Round(time.Second))
+}
+
+// Startup is the startup latency of each pipeline of a runner
+type Startup []PipelineStartup
+
+func (s Startup) String() string {
+	pipelines := make([]string, len(s))
+	for i, p := range s {
+		pipelines[i] = p.String()
+	}
+	return strings.Join(pipelines, ", ")
+}
+
+// Check returns a Validation error naming the pipelines which were not visible within the bound, nil when the bound
+// is 0
+func (s Startup) Check(bound time.Duration) error {
+	if bound <= 0 {
+		return nil
+	}
+	var slow []string
+	for _, p := range s {
+		if !p.Visible || p.Latency > bound {
+			slow = append(slow, p.String())
+		}
+	}
+	if len(slow) > 0 {
+		return errclass.Validationf("pipelines were not visible within %s of the agent start: %s", bound, strings.Join(slow, ", "))
+	}
+	return nil
+}
+
+// StartupWatch checks the pipelines in the background from the agent start until each of them is visible
+type StartupWatch struct {
+	start    time.Time
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	startup Startup
+}
+
+// WatchStartup starts watching the pipelines of an agent started at the time, nil when there are no pipelines
+func WatchStartup(start time.Time, pipelines []Pipeline, interval time.Duration) *StartupWatch {
+	if len(pipelines) == 0 {
+		return nil
+	}
+	w := &StartupWatch{
+		start:    start,
+		interval: interval,
+		done:     make(chan struct{}),
+		startup:  make(Startup, len(pipelines)),
+	}
+	for i, p := range pipelines {
+		w.startup[i].Name = p.Name
+	}
+	w.wg.Add(1)
+	go w.run(pipelines)
+	return w
+}
+
+func (w *StartupWatch) run(pipelines []Pipeline) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if w.poll(pipelines) {
+			return
+		}
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll checks the pipelines which are not visible yet and returns whether all of them are
+func (w *StartupWatch) poll(pipelines []Pipeline) bool {
+	all := true
+	for i, p := range pipelines {
+		w.mu.Lock()
+		visible := w.startup[i].Visible
+		w.mu.Unlock()
+		if visible {
+			continue
+		}
+		ok, err := p.Visible(w.start)
+		if err != nil && errclass.Classify(err) != errclass.NotFoundYet {
+			logger.Warnf("Failed to check whether pipeline %s is visible: %v", p.Name, err)
+		}
+		if !ok {
+			all = false
+			continue
+		}
+		latency := time.Since(w.start)
+		logger.Infof("Pipeline %s is visible %s after the agent start", p.Name, latency.Round(time.Second))
+		w.mu.Lock()
+		w.startup[i] = PipelineStartup{Name: p.Name, Latency: latency, Visible: true}
+		w.mu.Unlock()
+	}
+	return all
+}
+
+// Stop stops watching and returns the startup latency of the pipelines, it is safe to call on a nil watch
+func (w *StartupWatch) Stop() Startup {
+	if w == nil {
+		return nil
+	}
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append(Startup(nil), w.startup...)
+}
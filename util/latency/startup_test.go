@@ -0,0 +1,76 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package latency
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestWatchStartup(t *testing.T) {
+	start := time.Now()
+	var polls int32
+	metrics := Pipeline{Name: "metrics", Visible: func(since time.Time) (bool, error) {
+		assert.Equal(t, start, since)
+		return atomic.AddInt32(&polls, 1) >= 3, nil
+	}}
+	logs := Pipeline{Name: "logs", Visible: func(time.Time) (bool, error) {
+		return false, errclass.NotFoundYetf("no events yet")
+	}}
+	failing := Pipeline{Name: "failing", Visible: func(time.Time) (bool, error) {
+		return false, errors.New("access denied")
+	}}
+
+	w := WatchStartup(start, []Pipeline{metrics, logs, failing}, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&polls) >= 3 }, time.Second, 5*time.Millisecond)
+	startup := w.Stop()
+
+	require.Len(t, startup, 3)
+	assert.Equal(t, "metrics", startup[0].Name)
+	assert.True(t, startup[0].Visible)
+	assert.Greater(t, startup[0].Latency, time.Duration(0))
+	assert.Equal(t, PipelineStartup{Name: "logs"}, startup[1])
+	assert.Equal(t, PipelineStartup{Name: "failing"}, startup[2])
+	// stopping twice returns the same latency
+	assert.Equal(t, startup, w.Stop())
+}
+
+func TestWatchStartupStopsOnceVisible(t *testing.T) {
+	var polls int32
+	w := WatchStartup(time.Now(), []Pipeline{{Name: "metrics", Visible: func(time.Time) (bool, error) {
+		atomic.AddInt32(&polls, 1)
+		return true, nil
+	}}}, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, w.Stop()[0].Visible)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&polls))
+}
+
+func TestWatchStartupWithoutPipelines(t *testing.T) {
+	w := WatchStartup(time.Now(), nil, time.Second)
+	assert.Nil(t, w)
+	assert.Nil(t, w.Stop())
+}
+
+func TestStartupCheck(t *testing.T) {
+	startup := Startup{
+		{Name: "metrics", Latency: 20 * time.Second, Visible: true},
+		{Name: "logs", Latency: 90 * time.Second, Visible: true},
+		{Name: "emf"},
+	}
+	assert.Equal(t, "metrics 20s, logs 1m30s, emf not visible", startup.String())
+	assert.NoError(t, startup.Check(0))
+
+	err := startup.Check(time.Minute)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+	assert.EqualError(t, err, "pipelines were not visible within 1m0s of the agent start: logs 1m30s, emf not visible")
+	assert.NoError(t, startup[:1].Check(time.Minute))
+}
@@ -0,0 +1,220 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package leak
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// minSamplesForSlope is the fewest samples a growth slope is fitted to, fewer are not checked
+const minSamplesForSlope = 3
+
+// ErrUnsupported is returned by the sampler on the platforms it cannot read the usage of a process on
+var ErrUnsupported = errors.New("sampling process usage is not supported on this platform")
+
+// Sample is the resource usage of the agent process at one point in time
+type Sample struct {
+	Time time.Time
+	// RSS and VSZ are in bytes
+	RSS     uint64
+	VSZ     uint64
+	FDs     int
+	Threads int
+}
+
+// Series is the usage of the agent sampled at an interval while a runner ran
+type Series struct {
+	Samples []Sample
+}
+
+// Growth is the slope of each usage of a series, fitted with least squares, per hour
+type Growth struct {
+	RSS     float64
+	VSZ     float64
+	FDs     float64
+	Threads float64
+}
+
+func (g Growth) String() string {
+	return fmt.Sprintf("rss %+.0f B/h, vsz %+.0f B/h, fds %+.1f/h, threads %+.1f/h", g.RSS, g.VSZ, g.FDs, g.Threads)
+}
+
+// Growth fits the slopes of the samples, it is the zero Growth for fewer than minSamplesForSlope samples
+func (s Series) Growth() Growth {
+	if len(s.Samples) < minSamplesForSlope {
+		return Growth{}
+	}
+	return Growth{
+		RSS:     s.slope(func(x Sample) float64 { return float64(x.RSS) }),
+		VSZ:     s.slope(func(x Sample) float64 { return float64(x.VSZ) }),
+		FDs:     s.slope(func(x Sample) float64 { return float64(x.FDs) }),
+		Threads: s.slope(func(x Sample) float64 { return float64(x.Threads) }),
+	}
+}
+
+// slope is the least squares slope of value over the hours since the first sample
+func (s Series) slope(value func(Sample) float64) float64 {
+	start := s.Samples[0].Time
+	n := float64(len(s.Samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range s.Samples {
+		x := sample.Time.Sub(start).Hours()
+		y := value(sample)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+func (s Series) String() string {
+	if len(s.Samples) == 0 {
+		return "no samples"
+	}
+	first, last := s.Samples[0], s.Samples[len(s.Samples)-1]
+	return fmt.Sprintf("%d samples over %s, rss %d -> %d B, fds %d -> %d, threads %d -> %d, growth %s",
+		len(s.Samples), last.Time.Sub(first.Time).Round(time.Second), first.RSS, last.RSS, first.FDs, last.FDs,
+		first.Threads, last.Threads, s.Growth())
+}
+
+// PeakRSS returns the largest rss of the samples, 0 when there are none
+func (s Series) PeakRSS() uint64 {
+	var peak uint64
+	for _, sample := range s.Samples {
+		if sample.RSS > peak {
+			peak = sample.RSS
+		}
+	}
+	return peak
+}
+
+// Thresholds are the largest growths per hour a series may have, zero does not check that usage
+type Thresholds struct {
+	RSS     float64
+	VSZ     float64
+	FDs     float64
+	Threads float64
+}
+
+// Check returns an error naming every usage that grew faster than its threshold
+func (s Series) Check(t Thresholds) error {
+	if len(s.Samples) < minSamplesForSlope {
+		return nil
+	}
+	g := s.Growth()
+	var exceeded []string
+	for _, c := range []struct {
+		name      string
+		growth    float64
+		threshold float64
+	}{
+		{"rss", g.RSS, t.RSS},
+		{"vsz", g.VSZ, t.VSZ},
+		{"fds", g.FDs, t.FDs},
+		{"threads", g.Threads, t.Threads},
+	} {
+		if c.threshold > 0 && c.growth > c.threshold {
+			exceeded = append(exceeded, fmt.Sprintf("%s grew %.1f/h, more than %.1f/h", c.name, c.growth, c.threshold))
+		}
+	}
+	if len(exceeded) > 0 {
+		return fmt.Errorf("agent resource usage grew too fast: %s", strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+var (
+	interval   = 10 * time.Second
+	thresholds Thresholds
+)
+
+// Configure sets the sampling interval of the monitors and the thresholds the suites check the series against.
+// An interval of zero disables the monitors.
+func Configure(sampleInterval time.Duration, t Thresholds) {
+	interval = sampleInterval
+	thresholds = t
+}
+
+// ConfiguredThresholds returns the thresholds set by Configure
+func ConfiguredThresholds() Thresholds {
+	return thresholds
+}
+
+// Monitor samples the usage of the agent process in the background
+type Monitor struct {
+	sample func() (Sample, error)
+
+	mu     sync.Mutex
+	series Series
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// StartAgentMonitor starts sampling the agent process at the configured interval. It returns nil when monitoring is
+// disabled, a nil Monitor can still be stopped.
+func StartAgentMonitor() *Monitor {
+	if interval <= 0 {
+		return nil
+	}
+	return start(sampleAgent, interval)
+}
+
+// StartAgentMonitorEvery starts sampling the agent process at the interval whether monitoring is configured or not,
+// for the suites which validate the usage themselves, e.g. the footprint of a large config
+func StartAgentMonitorEvery(every time.Duration) *Monitor {
+	return start(sampleAgent, every)
+}
+
+func start(sample func() (Sample, error), every time.Duration) *Monitor {
+	m := &Monitor{sample: sample, done: make(chan struct{})}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			m.record()
+			select {
+			case <-ticker.C:
+			case <-m.done:
+				return
+			}
+		}
+	}()
+	return m
+}
+
+func (m *Monitor) record() {
+	s, err := m.sample()
+	if err != nil {
+		logger.Warnf("failed to sample the agent resource usage: %v", err)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series.Samples = append(m.series.Samples, s)
+}
+
+// Stop stops sampling and returns the series
+func (m *Monitor) Stop() Series {
+	if m == nil {
+		return Series{}
+	}
+	close(m.done)
+	m.wg.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.series
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package leak
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// series returns a sample every 10 minutes with the rss and fds growing by the given amounts per sample
+func series(n int, rssStep uint64, fdStep int) Series {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	var s Series
+	for i := 0; i < n; i++ {
+		s.Samples = append(s.Samples, Sample{
+			Time:    start.Add(time.Duration(i) * 10 * time.Minute),
+			RSS:     100<<20 + uint64(i)*rssStep,
+			VSZ:     1 << 30,
+			FDs:     20 + i*fdStep,
+			Threads: 12,
+		})
+	}
+	return s
+}
+
+func TestGrowth(t *testing.T) {
+	g := series(7, 1<<20, 1).Growth()
+	// one step every 10 minutes is six steps an hour
+	assert.InDelta(t, 6<<20, g.RSS, 1)
+	assert.InDelta(t, 6, g.FDs, 1e-9)
+	assert.Zero(t, g.VSZ)
+	assert.Zero(t, g.Threads)
+
+	assert.Equal(t, Growth{}, series(2, 1<<20, 1).Growth())
+}
+
+func TestPeakRSS(t *testing.T) {
+	s := series(3, 1<<20, 0)
+	s.Samples[1].RSS = 500 << 20
+	assert.Equal(t, uint64(500<<20), s.PeakRSS())
+	assert.Zero(t, Series{}.PeakRSS())
+}
+
+func TestCheck(t *testing.T) {
+	s := series(7, 1<<20, 1)
+	assert.NoError(t, s.Check(Thresholds{}))
+	assert.NoError(t, s.Check(Thresholds{RSS: 10 << 20, FDs: 10}))
+
+	err := s.Check(Thresholds{RSS: 1 << 20, FDs: 10, Threads: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rss grew")
+	assert.NotContains(t, err.Error(), "fds grew")
+	assert.NotContains(t, err.Error(), "threads grew")
+
+	// too few samples to fit a slope are never a leak
+	assert.NoError(t, series(2, 100<<20, 100).Check(Thresholds{RSS: 1, FDs: 1}))
+}
+
+func TestMonitor(t *testing.T) {
+	var calls int64
+	m := start(func() (Sample, error) {
+		n := atomic.AddInt64(&calls, 1)
+		if n == 2 {
+			return Sample{}, errors.New("agent process is not running")
+		}
+		return Sample{Time: time.Now(), FDs: int(n)}, nil
+	}, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	s := m.Stop()
+
+	assert.GreaterOrEqual(t, len(s.Samples), 3)
+	assert.EqualValues(t, atomic.LoadInt64(&calls)-1, len(s.Samples), "the failed sample is skipped")
+	assert.Equal(t, 1, s.Samples[0].FDs)
+	assert.Equal(t, 3, s.Samples[1].FDs)
+
+	var stopped *Monitor
+	assert.Empty(t, stopped.Stop().Samples)
+}
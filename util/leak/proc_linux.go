@@ -0,0 +1,90 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package leak
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const agentBinary = "amazon-cloudwatch-agent"
+
+// sampleAgent finds the agent process and reads its usage from procfs. The process is looked up on every sample
+// since a restart in the middle of a runner changes the pid.
+func sampleAgent() (Sample, error) {
+	pid, err := findAgentPid()
+	if err != nil {
+		return Sample{}, err
+	}
+	return sampleProcess(pid)
+}
+
+func findAgentPid() (int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+		if err != nil || len(cmdline) == 0 {
+			continue
+		}
+		if filepath.Base(string(bytes.SplitN(cmdline, []byte{0}, 2)[0])) == agentBinary {
+			return pid, nil
+		}
+	}
+	return 0, errors.New("agent process is not running")
+}
+
+func sampleProcess(pid int) (Sample, error) {
+	s := Sample{Time: time.Now()}
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "VmRSS:":
+			s.RSS = value * 1024
+		case "VmSize:":
+			s.VSZ = value * 1024
+		case "Threads:":
+			s.Threads = int(value)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return s, err
+	}
+
+	fds, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return s, err
+	}
+	s.FDs = len(fds)
+	return s, nil
+}
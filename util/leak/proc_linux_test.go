@@ -0,0 +1,23 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package leak
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleProcess(t *testing.T) {
+	s, err := sampleProcess(os.Getpid())
+	require.NoError(t, err)
+	assert.NotZero(t, s.RSS)
+	assert.GreaterOrEqual(t, s.VSZ, s.RSS)
+	assert.Positive(t, s.FDs)
+	assert.Positive(t, s.Threads)
+}
@@ -0,0 +1,87 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"collectd.org/api"
+	"collectd.org/exec"
+	"collectd.org/network"
+)
+
+// CollectdGenerator sends gauges and counters to the collectd network receiver of the agent, half of each
+type CollectdGenerator struct {
+	ticker
+	address string
+	client  *network.Client
+}
+
+var _ LoadGenerator = (*CollectdGenerator)(nil)
+
+// NewCollectdGenerator sends rate metrics to address every interval
+func NewCollectdGenerator(address string, rate int, interval time.Duration) *CollectdGenerator {
+	if address == "" {
+		address = net.JoinHostPort("127.0.0.1", network.DefaultService)
+	}
+	g := &CollectdGenerator{address: address}
+	g.ticker = ticker{rate: rate, interval: interval, send: g.send}
+	return g
+}
+
+func (g *CollectdGenerator) Name() string {
+	return "collectd"
+}
+
+func (g *CollectdGenerator) Start() error {
+	client, err := network.Dial(g.address, network.ClientOptions{SecurityLevel: network.None})
+	if err != nil {
+		return err
+	}
+	g.client = client
+	g.start()
+	return nil
+}
+
+func (g *CollectdGenerator) Stop() error {
+	g.stop()
+	if g.client == nil {
+		return nil
+	}
+	err := g.client.Close()
+	g.client = nil
+	return err
+}
+
+func (g *CollectdGenerator) send(rate int) (int, error) {
+	ctx := context.Background()
+	sent := 0
+	for i := 1; i <= rate/2; i++ {
+		for _, vl := range []*api.ValueList{
+			{
+				Identifier: api.Identifier{Host: exec.Hostname(), Plugin: fmt.Sprint("gauge_", i), Type: "gauge"},
+				Time:       time.Now(),
+				Interval:   g.interval,
+				Values:     []api.Value{api.Gauge(i)},
+			},
+			{
+				Identifier: api.Identifier{Host: exec.Hostname(), Plugin: fmt.Sprint("counter_", i), Type: "counter"},
+				Time:       time.Now(),
+				Interval:   g.interval,
+				Values:     []api.Value{api.Counter(i)},
+			},
+		} {
+			// the buffer is flushed below once it is full, so running out of space is not a failure
+			if err := g.client.Write(ctx, vl); err != nil && !errors.Is(err, network.ErrNotEnoughSpace) {
+				return sent, err
+			}
+			sent++
+		}
+	}
+	return sent, g.client.Flush()
+}
@@ -0,0 +1,72 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/prozz/aws-embedded-metrics-golang/emf"
+)
+
+const defaultEMFAddress = "127.0.0.1:25888"
+
+// EMFGenerator sends embedded metric format logs to the TCP emf receiver of the agent, one metric per log
+type EMFGenerator struct {
+	ticker
+	address   string
+	logGroup  string
+	namespace string
+	conn      net.Conn
+}
+
+var _ LoadGenerator = (*EMFGenerator)(nil)
+
+// NewEMFGenerator sends rate emf logs to address every interval
+func NewEMFGenerator(address, logGroup, namespace string, rate int, interval time.Duration) *EMFGenerator {
+	if address == "" {
+		address = defaultEMFAddress
+	}
+	g := &EMFGenerator{address: address, logGroup: logGroup, namespace: namespace}
+	g.ticker = ticker{rate: rate, interval: interval, send: g.send}
+	return g
+}
+
+func (g *EMFGenerator) Name() string {
+	return "emf"
+}
+
+func (g *EMFGenerator) Start() error {
+	conn, err := net.DialTimeout("tcp", g.address, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	g.conn = conn
+	g.start()
+	return nil
+}
+
+func (g *EMFGenerator) Stop() error {
+	g.stop()
+	if g.conn == nil {
+		return nil
+	}
+	err := g.conn.Close()
+	g.conn = nil
+	return err
+}
+
+func (g *EMFGenerator) send(rate int) (int, error) {
+	for i := 1; i <= rate; i++ {
+		name := fmt.Sprint("emf_time_", i)
+		emf.New(emf.WithWriter(g.conn), emf.WithLogGroup(g.logGroup)).
+			Namespace(g.namespace).
+			DimensionSet(emf.NewDimension("InstanceId", g.logGroup)).
+			MetricAs(name, i, emf.Milliseconds).
+			Log()
+		g.expect(name, float64(i))
+	}
+	return rate, nil
+}
@@ -0,0 +1,137 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Aggregate is what a generator sent for one metric, the statistics CloudWatch should report for it
+type Aggregate struct {
+	SampleCount int
+	Sum         float64
+	Min         float64
+	Max         float64
+}
+
+func (a *Aggregate) add(value float64) {
+	if a.SampleCount == 0 || value < a.Min {
+		a.Min = value
+	}
+	if a.SampleCount == 0 || value > a.Max {
+		a.Max = value
+	}
+	a.SampleCount++
+	a.Sum += value
+}
+
+func (a *Aggregate) merge(o Aggregate) {
+	if o.SampleCount == 0 {
+		return
+	}
+	if a.SampleCount == 0 || o.Min < a.Min {
+		a.Min = o.Min
+	}
+	if a.SampleCount == 0 || o.Max > a.Max {
+		a.Max = o.Max
+	}
+	a.SampleCount += o.SampleCount
+	a.Sum += o.Sum
+}
+
+// Expectation records the values a generator sends, bucketed by the aggregation interval of the agent, so a runner
+// can compare what CloudWatch aggregated with what was really sent instead of with a constant. The buckets are in UTC
+// and aligned to the interval like the periods of CloudWatch.
+type Expectation struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]map[time.Time]*Aggregate
+	// values keeps what was sent, the percentiles of a distribution can't be computed from the aggregates
+	values map[string]map[time.Time][]float64
+}
+
+func NewExpectation(interval time.Duration) *Expectation {
+	return &Expectation{
+		interval: interval,
+		buckets:  map[string]map[time.Time]*Aggregate{},
+		values:   map[string]map[time.Time][]float64{},
+	}
+}
+
+// Add records a value of the metric sent at the time
+func (e *Expectation) Add(name string, value float64, at time.Time) {
+	bucket := at.UTC().Truncate(e.interval)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.buckets[name] == nil {
+		e.buckets[name] = map[time.Time]*Aggregate{}
+		e.values[name] = map[time.Time][]float64{}
+	}
+	if e.buckets[name][bucket] == nil {
+		e.buckets[name][bucket] = &Aggregate{}
+	}
+	e.buckets[name][bucket].add(value)
+	e.values[name][bucket] = append(e.values[name][bucket], value)
+}
+
+// Names returns the metrics a value was recorded for, sorted
+func (e *Expectation) Names() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	names := make([]string, 0, len(e.buckets))
+	for name := range e.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Intervals returns the start of the intervals that are fully between start and end, so the partial intervals at the
+// edges of a run are never compared
+func (e *Expectation) Intervals(start, end time.Time) []time.Time {
+	var intervals []time.Time
+	first := start.UTC().Truncate(e.interval)
+	if first.Before(start) {
+		first = first.Add(e.interval)
+	}
+	for t := first; !t.Add(e.interval).After(end); t = t.Add(e.interval) {
+		intervals = append(intervals, t)
+	}
+	return intervals
+}
+
+// Bucket returns what was sent for the metric in the interval starting at the time, it is empty when nothing was
+func (e *Expectation) Bucket(name string, interval time.Time) Aggregate {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if a := e.buckets[name][interval.UTC()]; a != nil {
+		return *a
+	}
+	return Aggregate{}
+}
+
+// Total returns what was sent for the metric in the intervals fully between start and end
+func (e *Expectation) Total(name string, start, end time.Time) Aggregate {
+	var total Aggregate
+	for _, interval := range e.Intervals(start, end) {
+		total.merge(e.Bucket(name, interval))
+	}
+	return total
+}
+
+// Values returns the values sent for the metric in the intervals fully between start and end, in the order they were
+// sent in each interval
+func (e *Expectation) Values(name string, start, end time.Time) []float64 {
+	intervals := e.Intervals(start, end)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var values []float64
+	for _, interval := range intervals {
+		values = append(values, e.values[name][interval]...)
+	}
+	return values
+}
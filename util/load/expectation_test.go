@@ -0,0 +1,38 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectation(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewExpectation(time.Minute)
+	// the first interval is partial, it starts before the run
+	e.Add("counter", 100, start.Add(-10*time.Second))
+	for i := 0; i < 6; i++ {
+		at := start.Add(time.Duration(i) * 20 * time.Second)
+		e.Add("counter", 2, at)
+		e.Add("gauge", float64(i), at)
+	}
+
+	assert.Equal(t, []string{"counter", "gauge"}, e.Names())
+	assert.Equal(t, []time.Time{start, start.Add(time.Minute)}, e.Intervals(start.Add(-10*time.Second), start.Add(2*time.Minute+30*time.Second)))
+	assert.Equal(t, Aggregate{SampleCount: 3, Sum: 6, Min: 2, Max: 2}, e.Bucket("counter", start))
+	assert.Equal(t, Aggregate{SampleCount: 3, Sum: 12, Min: 3, Max: 5}, e.Bucket("gauge", start.Add(time.Minute)))
+	assert.Equal(t, Aggregate{}, e.Bucket("gauge", start.Add(time.Hour)))
+
+	// the partial interval before the start is left out of the total
+	assert.Equal(t, Aggregate{SampleCount: 6, Sum: 12, Min: 2, Max: 2}, e.Total("counter", start.Add(-10*time.Second), start.Add(2*time.Minute)))
+	assert.Equal(t, Aggregate{SampleCount: 6, Sum: 15, Min: 0, Max: 5}, e.Total("gauge", start, start.Add(2*time.Minute)))
+	assert.Equal(t, Aggregate{}, e.Total("gauge", start, start.Add(30*time.Second)))
+
+	assert.Equal(t, []float64{0, 1, 2, 3, 4, 5}, e.Values("gauge", start, start.Add(2*time.Minute)))
+	assert.Equal(t, []float64{2, 2, 2}, e.Values("counter", start.Add(-10*time.Second), start.Add(time.Minute)))
+	assert.Empty(t, e.Values("timer", start, start.Add(2*time.Minute)))
+}
@@ -0,0 +1,283 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logcorpus"
+)
+
+// LoadGenerator sends one kind of load to the agent, e.g. log lines or StatsD metrics, from Start until Stop
+type LoadGenerator interface {
+	// Name identifies the generator in the logs and the reports, e.g. statsd
+	Name() string
+	// Start starts sending in the background, it only returns the errors of setting up, e.g. dialing the receiver
+	Start() error
+	// Stop stops sending and waits for the in flight batch
+	Stop() error
+	Stats() Stats
+	// SetProfile shapes the load, it is constant unless set. It must be set before Start.
+	SetProfile(p Profile)
+}
+
+// Stats counts what a generator sent, so a suite can reconcile it with what the agent published
+type Stats struct {
+	Profile string
+	Sent    int64
+	Errors  int64
+	Started time.Time
+	Stopped time.Time
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("%s load sent %d, %d errors in %s", s.Profile, s.Sent, s.Errors, s.Stopped.Sub(s.Started).Round(time.Second))
+}
+
+// Spec declares a generator, so the stress, performance and soak suites can describe their load mix in a config file
+// instead of code, e.g. the load_mix of the validator config
+type Spec struct {
+	// Type is one of logfile, statsd, collectd, emf or otlp
+	Type string `json:"type" yaml:"type"`
+	// Rate is the number of log lines or metrics sent every Interval
+	Rate int `json:"rate" yaml:"rate"`
+	// Interval is parsed with time.ParseDuration, it defaults to a minute
+	Interval string `json:"interval" yaml:"interval"`
+	// Target is the log file of logfile, or the address of the receiver of the others. It defaults to the default
+	// port of the receiver on localhost.
+	Target string `json:"target" yaml:"target"`
+	// LogGroup and Namespace are the log group and namespace of the emf metrics
+	LogGroup  string `json:"log_group" yaml:"log_group"`
+	Namespace string `json:"namespace" yaml:"namespace"`
+	// Profile shapes the rate over time, it defaults to constant
+	Profile ProfileSpec `json:"profile" yaml:"profile"`
+	// Corpus is the mix of formats of the lines of logfile, ex apache=40,json=40,java=10,windows=10. The lines are
+	// numbered load lines when it is empty.
+	Corpus string `json:"corpus" yaml:"corpus"`
+}
+
+// String describes the spec for the report, e.g. statsd 1000/1m ramp(5m0s)
+func (s Spec) String() string {
+	interval := s.Interval
+	if interval == "" {
+		interval = "1m"
+	}
+	profile := s.Profile.Type
+	if profile == "" {
+		profile = "constant"
+	}
+	return fmt.Sprintf("%s %d/%s %s", s.Type, s.Rate, interval, profile)
+}
+
+// New returns the generator the spec declares
+func New(spec Spec) (LoadGenerator, error) {
+	interval := time.Minute
+	if spec.Interval != "" {
+		var err error
+		if interval, err = time.ParseDuration(spec.Interval); err != nil {
+			return nil, fmt.Errorf("invalid interval of %s load: %w", spec.Type, err)
+		}
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval of %s load must be positive, got %s", spec.Type, spec.Interval)
+	}
+	if spec.Rate <= 0 {
+		return nil, fmt.Errorf("rate of %s load must be positive, got %d", spec.Type, spec.Rate)
+	}
+	profile, err := NewProfile(spec.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile of %s load: %w", spec.Type, err)
+	}
+	var g LoadGenerator
+	switch spec.Type {
+	case "logfile":
+		lg := NewLogFileGenerator(spec.Target, spec.Rate, interval)
+		if spec.Corpus != "" {
+			mix, err := logcorpus.ParseMix(spec.Corpus)
+			if err != nil {
+				return nil, fmt.Errorf("invalid corpus of %s load: %w", spec.Type, err)
+			}
+			corpus, err := logcorpus.New(mix, time.Now().UnixNano())
+			if err != nil {
+				return nil, fmt.Errorf("invalid corpus of %s load: %w", spec.Type, err)
+			}
+			lg.SetCorpus(corpus)
+		}
+		g = lg
+	case "statsd":
+		g = NewStatsdGenerator(spec.Target, spec.Rate, interval)
+	case "collectd":
+		g = NewCollectdGenerator(spec.Target, spec.Rate, interval)
+	case "emf":
+		g = NewEMFGenerator(spec.Target, spec.LogGroup, spec.Namespace, spec.Rate, interval)
+	case "otlp":
+		g = NewOTLPGenerator(spec.Target, spec.Rate, interval)
+	default:
+		return nil, fmt.Errorf("unknown load type %q", spec.Type)
+	}
+	g.SetProfile(profile)
+	return g, nil
+}
+
+// Mix runs several generators together, e.g. logs next to StatsD and EMF metrics
+type Mix []LoadGenerator
+
+// NewMix returns the mix of the generators the specs declare
+func NewMix(specs []Spec) (Mix, error) {
+	mix := make(Mix, 0, len(specs))
+	for _, spec := range specs {
+		g, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		mix = append(mix, g)
+	}
+	return mix, nil
+}
+
+// LoadMix reads the specs of a mix from a JSON file with an array of Spec
+func LoadMix(path string) (Mix, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var specs []Spec
+	if err = json.Unmarshal(content, &specs); err != nil {
+		return nil, fmt.Errorf("invalid load mix %s: %w", path, err)
+	}
+	return NewMix(specs)
+}
+
+// Start starts every generator. If one fails to start, the ones already started are stopped.
+func (m Mix) Start() error {
+	for i, g := range m {
+		if err := g.Start(); err != nil {
+			for _, started := range m[:i] {
+				_ = started.Stop()
+			}
+			return fmt.Errorf("failed to start %s load: %w", g.Name(), err)
+		}
+	}
+	return nil
+}
+
+// Stop stops every generator and returns the errors of all of them
+func (m Mix) Stop() error {
+	var errs error
+	for _, g := range m {
+		if err := g.Stop(); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("failed to stop %s load: %w", g.Name(), err))
+		}
+	}
+	return errs
+}
+
+// Stats returns the stats of every generator by name
+func (m Mix) Stats() map[string]Stats {
+	stats := make(map[string]Stats, len(m))
+	for _, g := range m {
+		stats[g.Name()] = g.Stats()
+	}
+	return stats
+}
+
+// ticker calls send every interval from start until stop with the rate of the profile, and counts what it sent. The
+// generators embed it so they only implement the sending of one batch.
+type ticker struct {
+	rate     int
+	interval time.Duration
+	profile  Profile
+	send     func(rate int) (int, error)
+	// expectation records the values sent when set
+	expectation *Expectation
+
+	mu      sync.Mutex
+	stats   Stats
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// start sends the first batch right away and the next ones every interval
+func (t *ticker) start() {
+	if t.profile == nil {
+		t.profile = Constant{}
+	}
+	t.done = make(chan struct{})
+	t.stopped = make(chan struct{})
+	t.mu.Lock()
+	t.stats = Stats{Profile: t.profile.String(), Started: time.Now()}
+	started := t.stats.Started
+	t.mu.Unlock()
+
+	go func() {
+		defer close(t.stopped)
+		tick := time.NewTicker(t.interval)
+		defer tick.Stop()
+		for {
+			t.sendBatch(t.profile.Rate(t.rate, time.Since(started)))
+			select {
+			case <-tick.C:
+			case <-t.done:
+				return
+			}
+		}
+	}()
+}
+
+func (t *ticker) sendBatch(rate int) {
+	if rate <= 0 {
+		return
+	}
+	sent, err := t.send(rate)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Sent += int64(sent)
+	if err != nil {
+		t.stats.Errors++
+	}
+}
+
+// stop is a no-op unless the ticker was started
+func (t *ticker) stop() {
+	if t.done == nil {
+		return
+	}
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+	close(t.done)
+	<-t.stopped
+	t.mu.Lock()
+	t.stats.Stopped = time.Now()
+	t.mu.Unlock()
+}
+
+func (t *ticker) SetProfile(p Profile) {
+	t.profile = p
+}
+
+// SetExpectation records every value the generator sends in e. It must be set before Start.
+func (t *ticker) SetExpectation(e *Expectation) {
+	t.expectation = e
+}
+
+func (t *ticker) expect(name string, value float64) {
+	if t.expectation != nil {
+		t.expectation.Add(name, value, time.Now())
+	}
+}
+
+func (t *ticker) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
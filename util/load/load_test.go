@@ -0,0 +1,129 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGenerator struct {
+	name     string
+	startErr error
+	started  bool
+	stopped  bool
+}
+
+func (f *fakeGenerator) Name() string { return f.name }
+
+func (f *fakeGenerator) Start() error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.started = true
+	return nil
+}
+
+func (f *fakeGenerator) Stop() error {
+	f.stopped = true
+	return nil
+}
+
+func (f *fakeGenerator) Stats() Stats { return Stats{} }
+
+func (f *fakeGenerator) SetProfile(Profile) {}
+
+func TestNew(t *testing.T) {
+	g, err := New(Spec{Type: "statsd", Rate: 10, Interval: "10s"})
+	require.NoError(t, err)
+	assert.Equal(t, "statsd", g.Name())
+
+	_, err = New(Spec{Type: "snmp", Rate: 10})
+	assert.ErrorContains(t, err, "unknown load type")
+	_, err = New(Spec{Type: "logfile", Rate: 0})
+	assert.ErrorContains(t, err, "rate of logfile load must be positive")
+	_, err = New(Spec{Type: "logfile", Rate: 1, Interval: "often"})
+	assert.ErrorContains(t, err, "invalid interval of logfile load")
+	_, err = New(Spec{Type: "statsd", Rate: 1, Interval: "0s"})
+	assert.ErrorContains(t, err, "interval of statsd load must be positive, got 0s")
+	_, err = New(Spec{Type: "statsd", Rate: 1, Interval: "-1m"})
+	assert.ErrorContains(t, err, "interval of statsd load must be positive, got -1m")
+	_, err = New(Spec{Type: "logfile", Rate: 1, Corpus: "syslog=1"})
+	assert.ErrorContains(t, err, "invalid corpus of logfile load")
+}
+
+func TestLogFileGenerator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "load.log")
+	g := NewLogFileGenerator(path, 5, 10*time.Millisecond)
+	require.NoError(t, g.Start())
+	time.Sleep(35 * time.Millisecond)
+	require.NoError(t, g.Stop())
+
+	stats := g.Stats()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.GreaterOrEqual(t, stats.Sent, int64(10))
+	assert.EqualValues(t, stats.Sent, len(lines))
+	assert.Zero(t, stats.Errors)
+	assert.False(t, stats.Stopped.Before(stats.Started))
+
+	// stopping twice does not block or change the stats
+	require.NoError(t, g.Stop())
+	assert.Equal(t, stats, g.Stats())
+}
+
+func TestLogFileGeneratorCorpus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "load.log")
+	g, err := New(Spec{Type: "logfile", Rate: 20, Interval: "10ms", Target: path, Corpus: "java=1"})
+	require.NoError(t, err)
+	require.NoError(t, g.Start())
+	time.Sleep(15 * time.Millisecond)
+	require.NoError(t, g.Stop())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	stats := g.Stats()
+	assert.GreaterOrEqual(t, stats.Sent, int64(20))
+	// the rate counts the stack traces, not their lines
+	assert.EqualValues(t, stats.Sent, strings.Count(string(content), " ERROR [http-nio-8080-exec-"))
+	assert.Greater(t, strings.Count(string(content), "\n"), int(stats.Sent))
+}
+
+func TestMixStartStopsStartedOnFailure(t *testing.T) {
+	first := &fakeGenerator{name: "first"}
+	failing := &fakeGenerator{name: "failing", startErr: errors.New("connection refused")}
+	last := &fakeGenerator{name: "last"}
+
+	err := Mix{first, failing, last}.Start()
+	assert.ErrorContains(t, err, "failed to start failing load: connection refused")
+	assert.True(t, first.stopped)
+	assert.False(t, last.started)
+}
+
+func TestLoadMix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mix.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"type": "logfile", "rate": 100, "target": "/tmp/a.log"},
+		{"type": "emf", "rate": 10, "interval": "30s", "log_group": "group", "namespace": "ns"}
+	]`), 0644))
+
+	mix, err := LoadMix(path)
+	require.NoError(t, err)
+	require.Len(t, mix, 2)
+	assert.Equal(t, "logfile", mix[0].Name())
+	assert.Equal(t, "emf", mix[1].Name())
+}
+
+func TestSpecString(t *testing.T) {
+	assert.Equal(t, "statsd 1000/1m constant", Spec{Type: "statsd", Rate: 1000}.String())
+	assert.Equal(t, "emf 10/10s ramp", Spec{Type: "emf", Rate: 10, Interval: "10s", Profile: ProfileSpec{Type: "ramp", Duration: "5m"}}.String())
+}
@@ -0,0 +1,80 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logcorpus"
+)
+
+const defaultLogFile = "/tmp/load.log"
+
+// LogFileGenerator appends lines to a log file the agent monitors
+type LogFileGenerator struct {
+	ticker
+	path string
+	file *os.File
+	line int64
+	// corpus generates the entries instead of the numbered lines when set
+	corpus *logcorpus.Generator
+}
+
+var _ LoadGenerator = (*LogFileGenerator)(nil)
+
+// NewLogFileGenerator appends rate lines to path every interval
+func NewLogFileGenerator(path string, rate int, interval time.Duration) *LogFileGenerator {
+	if path == "" {
+		path = defaultLogFile
+	}
+	g := &LogFileGenerator{path: path}
+	g.ticker = ticker{rate: rate, interval: interval, send: g.send}
+	return g
+}
+
+// SetCorpus writes the entries of the corpus instead of the numbered lines, the rate counts the entries so a stack
+// trace is one of them. It must be set before Start.
+func (g *LogFileGenerator) SetCorpus(corpus *logcorpus.Generator) {
+	g.corpus = corpus
+}
+
+func (g *LogFileGenerator) Name() string {
+	return "logfile"
+}
+
+func (g *LogFileGenerator) Start() error {
+	f, err := os.OpenFile(g.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	g.file = f
+	g.start()
+	return nil
+}
+
+func (g *LogFileGenerator) Stop() error {
+	g.stop()
+	if g.file == nil {
+		return nil
+	}
+	err := g.file.Close()
+	g.file = nil
+	return err
+}
+
+func (g *LogFileGenerator) send(rate int) (int, error) {
+	if g.corpus != nil {
+		entries, err := g.corpus.Write(g.file, rate)
+		return len(entries), err
+	}
+	for i := 0; i < rate; i++ {
+		g.line++
+		if _, err := fmt.Fprintf(g.file, "%s load line %d\n", time.Now().Format(time.RFC3339Nano), g.line); err != nil {
+			return i, err
+		}
+	}
+	return rate, nil
+}
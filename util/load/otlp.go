@@ -0,0 +1,104 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const defaultOTLPEndpoint = "http://127.0.0.1:4318/v1/metrics"
+
+// OTLPGenerator sends gauges to the OTLP HTTP receiver of the agent with the JSON encoding of OTLP, which needs no
+// OpenTelemetry SDK
+type OTLPGenerator struct {
+	ticker
+	endpoint string
+	client   *http.Client
+}
+
+var _ LoadGenerator = (*OTLPGenerator)(nil)
+
+// NewOTLPGenerator sends rate gauges to endpoint every interval, in one request
+func NewOTLPGenerator(endpoint string, rate int, interval time.Duration) *OTLPGenerator {
+	if endpoint == "" {
+		endpoint = defaultOTLPEndpoint
+	}
+	g := &OTLPGenerator{endpoint: endpoint}
+	g.ticker = ticker{rate: rate, interval: interval, send: g.send}
+	return g
+}
+
+func (g *OTLPGenerator) Name() string {
+	return "otlp"
+}
+
+func (g *OTLPGenerator) Start() error {
+	g.client = &http.Client{Timeout: 10 * time.Second}
+	g.start()
+	return nil
+}
+
+func (g *OTLPGenerator) Stop() error {
+	g.stop()
+	return nil
+}
+
+type otlpAttribute struct {
+	Key   string            `json:"key"`
+	Value map[string]string `json:"value"`
+}
+
+type otlpDataPoint struct {
+	TimeUnixNano string  `json:"timeUnixNano"`
+	AsDouble     float64 `json:"asDouble"`
+}
+
+type otlpMetric struct {
+	Name  string `json:"name"`
+	Gauge struct {
+		DataPoints []otlpDataPoint `json:"dataPoints"`
+	} `json:"gauge"`
+}
+
+func (g *OTLPGenerator) send(rate int) (int, error) {
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	metrics := make([]otlpMetric, rate)
+	for i := range metrics {
+		metrics[i].Name = fmt.Sprint("otlp_gauge_", i+1)
+		metrics[i].Gauge.DataPoints = []otlpDataPoint{{TimeUnixNano: now, AsDouble: float64(i + 1)}}
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceMetrics": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []otlpAttribute{{Key: "service.name", Value: map[string]string{"stringValue": "load"}}},
+				},
+				"scopeMetrics": []interface{}{
+					map[string]interface{}{
+						"scope":   map[string]string{"name": "load"},
+						"metrics": metrics,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := g.client.Post(g.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("otlp receiver returned %s", resp.Status)
+	}
+	return rate, nil
+}
@@ -0,0 +1,148 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Profile shapes the load of a generator over time, e.g. to test the backpressure of the agent with bursts
+type Profile interface {
+	// Rate returns the size of the batch sent elapsed after the start, for the base rate of the generator
+	Rate(base int, elapsed time.Duration) int
+	// String describes the profile for the report, e.g. ramp(5m0s)
+	String() string
+}
+
+// Constant sends the base rate every interval
+type Constant struct{}
+
+func (Constant) Rate(base int, _ time.Duration) int {
+	return base
+}
+
+func (Constant) String() string {
+	return "constant"
+}
+
+// Ramp increases the rate linearly from zero to the base rate over Duration, then stays at the base rate
+type Ramp struct {
+	Duration time.Duration
+}
+
+func (r Ramp) Rate(base int, elapsed time.Duration) int {
+	if elapsed >= r.Duration || r.Duration <= 0 {
+		return base
+	}
+	return int(float64(base) * float64(elapsed) / float64(r.Duration))
+}
+
+func (r Ramp) String() string {
+	return fmt.Sprintf("ramp(%s)", r.Duration)
+}
+
+// SquareWave bursts to Factor times the base rate for the first Duty fraction of every Period, and sends the base
+// rate for the rest of it
+type SquareWave struct {
+	Period time.Duration
+	Duty   float64
+	Factor float64
+}
+
+func (w SquareWave) Rate(base int, elapsed time.Duration) int {
+	if w.Period <= 0 {
+		return base
+	}
+	if float64(elapsed%w.Period) < w.Duty*float64(w.Period) {
+		return int(float64(base) * w.Factor)
+	}
+	return base
+}
+
+func (w SquareWave) String() string {
+	return fmt.Sprintf("square(period=%s,duty=%g,factor=%g)", w.Period, w.Duty, w.Factor)
+}
+
+// Poisson draws every batch from a Poisson distribution with the base rate as its mean, i.e. the arrivals of many
+// independent clients. The draws are reproducible with the seed.
+type Poisson struct {
+	Seed int64
+	rand *rand.Rand
+}
+
+// NewPoisson returns a Poisson profile with its own source seeded with seed
+func NewPoisson(seed int64) *Poisson {
+	return &Poisson{Seed: seed, rand: rand.New(rand.NewSource(seed))}
+}
+
+func (p *Poisson) Rate(base int, _ time.Duration) int {
+	if base <= 0 {
+		return 0
+	}
+	// Knuth's algorithm underflows for large means, where the normal approximation is close enough
+	if base > 500 {
+		return int(math.Max(0, math.Round(float64(base)+p.rand.NormFloat64()*math.Sqrt(float64(base)))))
+	}
+	limit := math.Exp(-float64(base))
+	k, product := 0, p.rand.Float64()
+	for product > limit {
+		k++
+		product *= p.rand.Float64()
+	}
+	return k
+}
+
+func (p *Poisson) String() string {
+	return fmt.Sprintf("poisson(seed=%d)", p.Seed)
+}
+
+// ProfileSpec declares a profile in a Spec
+type ProfileSpec struct {
+	// Type is one of constant, ramp, square or poisson, it defaults to constant
+	Type string `json:"type" yaml:"type"`
+	// Duration is the ramp up of ramp, parsed with time.ParseDuration
+	Duration string `json:"duration" yaml:"duration"`
+	// Period, Duty and Factor are the shape of the bursts of square
+	Period string  `json:"period" yaml:"period"`
+	Duty   float64 `json:"duty" yaml:"duty"`
+	Factor float64 `json:"factor" yaml:"factor"`
+	// Seed seeds poisson, it defaults to the start time so every run differs unless it is set
+	Seed int64 `json:"seed" yaml:"seed"`
+}
+
+// NewProfile returns the profile the spec declares
+func NewProfile(spec ProfileSpec) (Profile, error) {
+	switch spec.Type {
+	case "", "constant":
+		return Constant{}, nil
+	case "ramp":
+		d, err := time.ParseDuration(spec.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration of ramp profile: %w", err)
+		}
+		return Ramp{Duration: d}, nil
+	case "square":
+		period, err := time.ParseDuration(spec.Period)
+		if err != nil {
+			return nil, fmt.Errorf("invalid period of square profile: %w", err)
+		}
+		if spec.Duty <= 0 || spec.Duty >= 1 {
+			return nil, fmt.Errorf("duty of square profile must be between 0 and 1, got %g", spec.Duty)
+		}
+		if spec.Factor <= 0 {
+			return nil, fmt.Errorf("factor of square profile must be positive, got %g", spec.Factor)
+		}
+		return SquareWave{Period: period, Duty: spec.Duty, Factor: spec.Factor}, nil
+	case "poisson":
+		seed := spec.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		return NewPoisson(seed), nil
+	}
+	return nil, fmt.Errorf("unknown load profile %q", spec.Type)
+}
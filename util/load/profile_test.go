@@ -0,0 +1,72 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRamp(t *testing.T) {
+	r := Ramp{Duration: 10 * time.Minute}
+	assert.Equal(t, 0, r.Rate(100, 0))
+	assert.Equal(t, 50, r.Rate(100, 5*time.Minute))
+	assert.Equal(t, 100, r.Rate(100, 10*time.Minute))
+	assert.Equal(t, 100, r.Rate(100, time.Hour))
+}
+
+func TestSquareWave(t *testing.T) {
+	w := SquareWave{Period: time.Minute, Duty: 0.25, Factor: 4}
+	assert.Equal(t, 400, w.Rate(100, 0))
+	assert.Equal(t, 400, w.Rate(100, 14*time.Second))
+	assert.Equal(t, 100, w.Rate(100, 15*time.Second))
+	assert.Equal(t, 100, w.Rate(100, 59*time.Second))
+	assert.Equal(t, 400, w.Rate(100, 61*time.Second))
+}
+
+func TestPoisson(t *testing.T) {
+	for _, mean := range []int{5, 100, 2000} {
+		p := NewPoisson(42)
+		total := 0
+		const draws = 2000
+		for i := 0; i < draws; i++ {
+			rate := p.Rate(mean, 0)
+			require.GreaterOrEqual(t, rate, 0)
+			total += rate
+		}
+		assert.InDelta(t, float64(mean), float64(total)/draws, float64(mean)*0.05, "mean %d", mean)
+	}
+
+	// the same seed draws the same batches
+	a, b := NewPoisson(7), NewPoisson(7)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, a.Rate(50, 0), b.Rate(50, 0))
+	}
+}
+
+func TestNewProfile(t *testing.T) {
+	p, err := NewProfile(ProfileSpec{})
+	require.NoError(t, err)
+	assert.Equal(t, "constant", p.String())
+
+	p, err = NewProfile(ProfileSpec{Type: "ramp", Duration: "5m"})
+	require.NoError(t, err)
+	assert.Equal(t, "ramp(5m0s)", p.String())
+
+	p, err = NewProfile(ProfileSpec{Type: "square", Period: "1m", Duty: 0.5, Factor: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "square(period=1m0s,duty=0.5,factor=3)", p.String())
+
+	p, err = NewProfile(ProfileSpec{Type: "poisson", Seed: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "poisson(seed=3)", p.String())
+
+	_, err = NewProfile(ProfileSpec{Type: "square", Period: "1m", Duty: 1, Factor: 3})
+	assert.ErrorContains(t, err, "duty of square profile")
+	_, err = NewProfile(ProfileSpec{Type: "sine"})
+	assert.ErrorContains(t, err, "unknown load profile")
+}
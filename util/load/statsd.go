@@ -0,0 +1,78 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package load
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-go/statsd"
+)
+
+const (
+	defaultStatsdAddress = "127.0.0.1:8125"
+	// statsdNamespace prefixes the names of the metrics, e.g. statsd.counter_1
+	statsdNamespace = "statsd"
+)
+
+// StatsdGenerator sends counters and gauges to the StatsD receiver of the agent, half of each. Counter_i and gauge_i
+// are sent with the value i.
+type StatsdGenerator struct {
+	ticker
+	address string
+	client  *statsd.Client
+}
+
+var _ LoadGenerator = (*StatsdGenerator)(nil)
+
+// NewStatsdGenerator sends rate metrics to address every interval
+func NewStatsdGenerator(address string, rate int, interval time.Duration) *StatsdGenerator {
+	if address == "" {
+		address = defaultStatsdAddress
+	}
+	g := &StatsdGenerator{address: address}
+	g.ticker = ticker{rate: rate, interval: interval, send: g.send}
+	return g
+}
+
+func (g *StatsdGenerator) Name() string {
+	return "statsd"
+}
+
+func (g *StatsdGenerator) Start() error {
+	client, err := statsd.New(g.address, statsd.WithMaxMessagesPerPayload(100), statsd.WithNamespace(statsdNamespace), statsd.WithoutTelemetry())
+	if err != nil {
+		return err
+	}
+	g.client = client
+	g.start()
+	return nil
+}
+
+func (g *StatsdGenerator) Stop() error {
+	g.stop()
+	if g.client == nil {
+		return nil
+	}
+	err := g.client.Close()
+	g.client = nil
+	return err
+}
+
+func (g *StatsdGenerator) send(rate int) (int, error) {
+	sent := 0
+	for i := 1; i <= rate/2; i++ {
+		counter, gauge := fmt.Sprint("counter_", i), fmt.Sprint("gauge_", i)
+		if err := g.client.Count(counter, int64(i), nil, 1.0); err != nil {
+			return sent, err
+		}
+		g.expect(statsdNamespace+"."+counter, float64(i))
+		if err := g.client.Gauge(gauge, float64(i), nil, 1.0); err != nil {
+			return sent + 1, err
+		}
+		g.expect(statsdNamespace+"."+gauge, float64(i))
+		sent += 2
+	}
+	return sent, nil
+}
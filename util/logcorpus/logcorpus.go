@@ -0,0 +1,294 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package logcorpus generates realistic application logs, Apache access lines, JSON application logs, Java stack
+// traces and Windows event style lines, at a configurable mix. The log filtering, multiline and performance suites
+// share it, so they exercise the payloads of real applications instead of a repeated line.
+package logcorpus
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is the format of an entry
+type Format string
+
+const (
+	// Apache is an access line of the combined log format
+	Apache Format = "apache"
+	// JSON is a structured application log with a level, a logger and a message
+	JSON Format = "json"
+	// JavaStackTrace is an error with the stack trace of its exception and its cause, over several lines
+	JavaStackTrace Format = "java"
+	// Windows is an event log line of the Windows services
+	Windows Format = "windows"
+)
+
+// Formats are every format, in the order ParseMix reports them
+var Formats = []Format{Apache, JSON, JavaStackTrace, Windows}
+
+// StartPattern matches the first line of the entries of every format and none of their continuation lines, it is the
+// multi_line_start_pattern of the agent config collecting the corpus
+const StartPattern = `^[\d{]`
+
+// Levels of the entries, the access lines are INFO, WARN or ERROR by their status
+const (
+	Info  = "INFO"
+	Warn  = "WARN"
+	Error = "ERROR"
+)
+
+// Mix is the weight of each format, e.g. 40 apache and 10 java lines out of every 100
+type Mix map[Format]int
+
+// DefaultMix is mostly access lines and application logs with a few stack traces and event lines
+var DefaultMix = Mix{Apache: 40, JSON: 40, JavaStackTrace: 10, Windows: 10}
+
+// ParseMix parses the comma-delimited format=weight of a mix, ex apache=40,json=40,java=10,windows=10. An empty
+// string is the DefaultMix.
+func ParseMix(s string) (Mix, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultMix, nil
+	}
+	mix := Mix{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, weight, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q of the mix is not format=weight", pair)
+		}
+		format := Format(strings.TrimSpace(name))
+		if !isFormat(format) {
+			return nil, fmt.Errorf("unknown format %q, expected one of %v", format, Formats)
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight of %s must be a non-negative integer, got %q", format, weight)
+		}
+		mix[format] = w
+	}
+	return mix, nil
+}
+
+func (m Mix) String() string {
+	var pairs []string
+	for _, f := range Formats {
+		if w := m[f]; w > 0 {
+			pairs = append(pairs, fmt.Sprintf("%s=%d", f, w))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Entry is one log event, a stack trace spans several lines
+type Entry struct {
+	Format Format
+	Level  string
+	Lines  []string
+}
+
+// String is the entry as it is written and as the agent publishes it when it collects the multiline entries
+func (e Entry) String() string {
+	return strings.Join(e.Lines, "\n")
+}
+
+// Generator generates the entries of a mix. It is not safe for concurrent use.
+type Generator struct {
+	mix    Mix
+	total  int
+	rand   *rand.Rand
+	seq    int64
+	now    func() time.Time
+	counts map[Format]int
+}
+
+// New returns a generator of the mix, the same seed generates the same entries apart from their timestamps
+func New(mix Mix, seed int64) (*Generator, error) {
+	total := 0
+	for f, w := range mix {
+		if !isFormat(f) {
+			return nil, fmt.Errorf("unknown format %q", f)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("the mix has no formats with a positive weight")
+	}
+	return &Generator{
+		mix:    mix,
+		total:  total,
+		rand:   rand.New(rand.NewSource(seed)),
+		now:    time.Now,
+		counts: map[Format]int{},
+	}, nil
+}
+
+// Next generates the next entry, timestamped now
+func (g *Generator) Next() Entry {
+	g.seq++
+	pick := g.rand.Intn(g.total)
+	format := Apache
+	for _, f := range Formats {
+		if pick < g.mix[f] {
+			format = f
+			break
+		}
+		pick -= g.mix[f]
+	}
+	g.counts[format]++
+	now := g.now().UTC()
+	switch format {
+	case JSON:
+		return g.json(now)
+	case JavaStackTrace:
+		return g.stackTrace(now)
+	case Windows:
+		return g.windows(now)
+	default:
+		return g.apache(now)
+	}
+}
+
+// Write writes n entries to the writer, one line each apart from the stack traces, and returns them so the suites
+// can derive what the agent must publish, e.g. the entries a filter keeps
+func (g *Generator) Write(w io.Writer, n int) ([]Entry, error) {
+	entries := make([]Entry, 0, n)
+	for i := 0; i < n; i++ {
+		e := g.Next()
+		if _, err := io.WriteString(w, e.String()+"\n"); err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Counts are the number of entries generated of each format
+func (g *Generator) Counts() map[Format]int {
+	counts := make(map[Format]int, len(g.counts))
+	for f, c := range g.counts {
+		counts[f] = c
+	}
+	return counts
+}
+
+var (
+	paths      = []string{"/", "/index.html", "/api/v1/orders", "/api/v1/orders/%d", "/api/v1/customers/%d", "/static/app.%d.js", "/health"}
+	methods    = []string{"GET", "GET", "GET", "POST", "PUT", "DELETE"}
+	userAgents = []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		"curl/8.5.0",
+		"ELB-HealthChecker/2.0",
+	}
+	loggers  = []string{"com.example.orders.OrderService", "com.example.billing.InvoiceService", "com.example.auth.TokenFilter", "com.example.inventory.StockClient"}
+	messages = map[string][]string{
+		Info:  {"order %d created", "invoice %d sent to the customer", "token refreshed for session %d", "stock of item %d reserved"},
+		Warn:  {"retrying the call to the inventory service, attempt %d", "slow query of %d ms on the orders table", "cache miss for customer %d"},
+		Error: {"failed to charge order %d", "timed out after %d ms waiting for the inventory service"},
+	}
+	exceptions = []string{"java.lang.IllegalStateException", "java.util.concurrent.TimeoutException", "java.lang.NullPointerException"}
+	causes     = []string{"java.sql.SQLTransientConnectionException: connection is not available, request timed out after 30000ms", "java.net.SocketTimeoutException: Read timed out"}
+	frames     = []string{
+		"com.example.orders.OrderService.cancel(OrderService.java:%d)",
+		"com.example.orders.OrderController.handle(OrderController.java:%d)",
+		"org.springframework.web.servlet.FrameworkServlet.service(FrameworkServlet.java:%d)",
+		"org.apache.catalina.core.ApplicationFilterChain.doFilter(ApplicationFilterChain.java:%d)",
+		"java.base/java.lang.Thread.run(Thread.java:%d)",
+	}
+	windowsEvents = []struct {
+		level, source string
+		id            int
+		message       string
+	}{
+		{"Information", "Service Control Manager", 7036, "The Windows Update service entered the running state."},
+		{"Information", "Microsoft-Windows-Security-Auditing", 4624, "An account was successfully logged on."},
+		{"Warning", "Microsoft-Windows-Time-Service", 129, "NtpClient was unable to set a domain peer to use as a time source."},
+		{"Error", "Application Error", 1000, "Faulting application name: w3wp.exe, version: 10.0.20348.1"},
+	}
+)
+
+func (g *Generator) apache(now time.Time) Entry {
+	status, level := 200, Info
+	switch r := g.rand.Intn(100); {
+	case r < 5:
+		status, level = 500+g.rand.Intn(4), Error
+	case r < 15:
+		status, level = []int{401, 403, 404, 429}[g.rand.Intn(4)], Warn
+	case r < 20:
+		status = 304
+	}
+	path := g.pick(paths)
+	if strings.Contains(path, "%d") {
+		path = fmt.Sprintf(path, g.rand.Intn(100000))
+	}
+	line := fmt.Sprintf(`%d.%d.%d.%d - - [%s] "%s %s HTTP/1.1" %d %d "-" "%s"`,
+		10+g.rand.Intn(200), g.rand.Intn(256), g.rand.Intn(256), 1+g.rand.Intn(254),
+		now.Format("02/Jan/2006:15:04:05 -0700"), g.pick(methods), path, status, g.rand.Intn(50000), g.pick(userAgents))
+	return Entry{Format: Apache, Level: level, Lines: []string{line}}
+}
+
+func (g *Generator) json(now time.Time) Entry {
+	level := Info
+	switch r := g.rand.Intn(100); {
+	case r < 5:
+		level = Error
+	case r < 20:
+		level = Warn
+	}
+	message := fmt.Sprintf(g.pick(messages[level]), g.rand.Intn(100000))
+	line := fmt.Sprintf(`{"timestamp":"%s","level":"%s","logger":"%s","thread":"http-nio-8080-exec-%d","message":"%s","trace_id":"%016x%016x","seq":%d}`,
+		now.Format("2006-01-02T15:04:05.000Z07:00"), level, g.pick(loggers), 1+g.rand.Intn(10), message, g.rand.Uint64(), g.rand.Uint64(), g.seq)
+	return Entry{Format: JSON, Level: level, Lines: []string{line}}
+}
+
+func (g *Generator) stackTrace(now time.Time) Entry {
+	lines := []string{
+		fmt.Sprintf("%s ERROR [http-nio-8080-exec-%d] %s - Request %d failed", now.Format("2006-01-02 15:04:05,000"), 1+g.rand.Intn(10), g.pick(loggers), g.seq),
+		fmt.Sprintf("%s: order %d cannot be processed", g.pick(exceptions), g.rand.Intn(100000)),
+	}
+	depth := 2 + g.rand.Intn(len(frames)-1)
+	for _, frame := range frames[:depth] {
+		lines = append(lines, "\tat "+fmt.Sprintf(frame, 20+g.rand.Intn(900)))
+	}
+	if g.rand.Intn(2) == 0 {
+		lines = append(lines, "Caused by: "+g.pick(causes))
+		lines = append(lines, "\tat "+fmt.Sprintf(frames[0], 20+g.rand.Intn(900)))
+		lines = append(lines, fmt.Sprintf("\t... %d more", depth))
+	}
+	return Entry{Format: JavaStackTrace, Level: Error, Lines: lines}
+}
+
+func (g *Generator) windows(now time.Time) Entry {
+	event := windowsEvents[g.rand.Intn(len(windowsEvents))]
+	level := Info
+	switch event.level {
+	case "Warning":
+		level = Warn
+	case "Error":
+		level = Error
+	}
+	line := fmt.Sprintf("%s %s %s %d %s", now.Format("2006-01-02 15:04:05"), event.level, event.source, event.id, event.message)
+	return Entry{Format: Windows, Level: level, Lines: []string{line}}
+}
+
+func (g *Generator) pick(values []string) string {
+	return values[g.rand.Intn(len(values))]
+}
+
+func isFormat(f Format) bool {
+	for _, known := range Formats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
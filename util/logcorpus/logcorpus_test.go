@@ -0,0 +1,87 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logcorpus
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := ParseMix("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMix, mix)
+
+	mix, err = ParseMix("apache=3, java=1")
+	require.NoError(t, err)
+	assert.Equal(t, Mix{Apache: 3, JavaStackTrace: 1}, mix)
+	assert.Equal(t, "apache=3,java=1", mix.String())
+
+	_, err = ParseMix("syslog=1")
+	assert.ErrorContains(t, err, "unknown format")
+	_, err = ParseMix("apache")
+	assert.Error(t, err)
+	_, err = ParseMix("apache=-1")
+	assert.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Mix{}, 1)
+	assert.Error(t, err)
+	_, err = New(Mix{Apache: 0}, 1)
+	assert.Error(t, err)
+	_, err = New(Mix{"syslog": 1}, 1)
+	assert.Error(t, err)
+}
+
+func TestGenerator(t *testing.T) {
+	g, err := New(DefaultMix, 42)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	entries, err := g.Write(&buf, 1000)
+	require.NoError(t, err)
+	require.Len(t, entries, 1000)
+
+	start := regexp.MustCompile(StartPattern)
+	var lines int
+	for _, e := range entries {
+		assert.Contains(t, []string{Info, Warn, Error}, e.Level)
+		require.NotEmpty(t, e.Lines)
+		assert.Regexp(t, start, e.Lines[0], "the first line of %s starts an entry", e.Format)
+		for _, l := range e.Lines[1:] {
+			assert.NotRegexp(t, start, l, "the continuation lines of %s do not start an entry", e.Format)
+		}
+		lines += len(e.Lines)
+		switch e.Format {
+		case JSON:
+			var v map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(e.Lines[0]), &v))
+			assert.Equal(t, e.Level, v["level"])
+		case JavaStackTrace:
+			assert.Greater(t, len(e.Lines), 2)
+		}
+	}
+	assert.Equal(t, lines, strings.Count(buf.String(), "\n"))
+
+	counts := g.Counts()
+	for f, weight := range DefaultMix {
+		// every format is within a few percent of its weight out of 1000 entries
+		assert.InDelta(t, weight*10, counts[f], 40, "entries of %s", f)
+	}
+
+	again, err := New(DefaultMix, 42)
+	require.NoError(t, err)
+	for _, e := range entries[:10] {
+		next := again.Next()
+		assert.Equal(t, e.Format, next.Format)
+		assert.Equal(t, e.Level, next.Level)
+		assert.Len(t, next.Lines, len(e.Lines))
+	}
+}
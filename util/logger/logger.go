@@ -0,0 +1,166 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Level int
+
+const (
+	DEBUG Level = iota
+	INFO
+	WARN
+	ERROR
+)
+
+func (l Level) String() string {
+	switch l {
+	case DEBUG:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case WARN:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+// Fields are attached to every line of a Logger, e.g. runner, metric or log group
+type Fields map[string]string
+
+type Logger struct {
+	fields Fields
+}
+
+var (
+	verbose bool
+
+	captureMu sync.Mutex
+	capturing bool
+	captured  []string
+
+	std = &Logger{}
+
+	// exit is replaced by the tests of Fatalf
+	exit = os.Exit
+)
+
+// SetVerbose enables DEBUG lines in the output. DEBUG lines are always captured.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+// StartCapture begins collecting every line logged through this package, so the lines can be attached to the
+// result of the runner that is executing. Runners execute one at a time, so a single capture is enough.
+func StartCapture() {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	capturing = true
+	captured = nil
+}
+
+// StopCapture ends the capture started by StartCapture and returns the collected lines
+func StopCapture() []string {
+	captureMu.Lock()
+	defer captureMu.Unlock()
+	capturing = false
+	lines := captured
+	captured = nil
+	return lines
+}
+
+// With returns a logger that adds the fields to every line
+func With(fields Fields) *Logger {
+	return std.With(fields)
+}
+
+func (l *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{fields: merged}
+}
+
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.logf(DEBUG, format, args...)
+}
+
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.logf(INFO, format, args...)
+}
+
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.logf(WARN, format, args...)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.logf(ERROR, format, args...)
+}
+
+func Debugf(format string, args ...interface{}) {
+	std.logf(DEBUG, format, args...)
+}
+
+func Infof(format string, args ...interface{}) {
+	std.logf(INFO, format, args...)
+}
+
+func Warnf(format string, args ...interface{}) {
+	std.logf(WARN, format, args...)
+}
+
+func Errorf(format string, args ...interface{}) {
+	std.logf(ERROR, format, args...)
+}
+
+// Fatalf logs an ERROR line and exits, for the configuration errors a run cannot continue with
+func Fatalf(format string, args ...interface{}) {
+	std.logf(ERROR, format, args...)
+	exit(1)
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	line := level.String() + " " + fmt.Sprintf(format, args...) + l.formatFields()
+
+	captureMu.Lock()
+	if capturing {
+		captured = append(captured, line)
+	}
+	captureMu.Unlock()
+
+	if level == DEBUG && !verbose {
+		return
+	}
+	log.Print(line)
+}
+
+// formatFields renders the fields as sorted key=value pairs so lines are stable and greppable
+func (l *Logger) formatFields() string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf(" %s=%q", k, l.fields[k]))
+	}
+	return sb.String()
+}
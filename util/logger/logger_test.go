@@ -0,0 +1,96 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package logger
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureOutput redirects the standard logger to a buffer for the test
+func captureOutput(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+		SetVerbose(false)
+		StopCapture()
+	})
+	return &buf
+}
+
+func outputLines(buf *bytes.Buffer) []string {
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func TestLevelFiltering(t *testing.T) {
+	buf := captureOutput(t)
+
+	Debugf("hidden %d", 1)
+	Infof("info %d", 2)
+	Warnf("warn %d", 3)
+	Errorf("error %d", 4)
+	assert.Equal(t, []string{"INFO info 2", "WARN warn 3", "ERROR error 4"}, outputLines(buf))
+
+	buf.Reset()
+	SetVerbose(true)
+	Debugf("shown %d", 5)
+	assert.Equal(t, []string{"DEBUG shown 5"}, outputLines(buf))
+}
+
+func TestFields(t *testing.T) {
+	buf := captureOutput(t)
+
+	l := With(Fields{"runner": "CPU", "metric": "cpu_usage_idle"})
+	l.Infof("validating")
+	// the fields of the parent are kept and the ones of the child win
+	l.With(Fields{"metric": "cpu usage", "attempt": "2"}).Warnf("retrying")
+	Infof("no fields")
+
+	assert.Equal(t, []string{
+		`INFO validating metric="cpu_usage_idle" runner="CPU"`,
+		`WARN retrying attempt="2" metric="cpu usage" runner="CPU"`,
+		"INFO no fields",
+	}, outputLines(buf))
+}
+
+func TestCapture(t *testing.T) {
+	buf := captureOutput(t)
+
+	Infof("before the capture")
+	StartCapture()
+	With(Fields{"runner": "Mem"}).Infof("running")
+	Debugf("debug is captured even when not printed")
+	lines := StopCapture()
+	Infof("after the capture")
+
+	assert.Equal(t, []string{
+		`INFO running runner="Mem"`,
+		"DEBUG debug is captured even when not printed",
+	}, lines)
+	assert.NotContains(t, buf.String(), "debug is captured")
+
+	// a new capture starts empty
+	StartCapture()
+	assert.Empty(t, StopCapture())
+}
+
+func TestFatalf(t *testing.T) {
+	buf := captureOutput(t)
+	code := 0
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	Fatalf("invalid flag %s", "-cleanupPolicy")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, []string{"ERROR invalid flag -cleanupPolicy"}, outputLines(buf))
+}
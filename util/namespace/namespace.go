@@ -0,0 +1,68 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package namespace
+
+import "sync"
+
+var (
+	override string
+	runId    string
+
+	mu sync.Mutex
+	// scoped are the namespaces Scope rewrote, the only ones Resolve rewrites too
+	scoped = map[string]struct{}{}
+)
+
+// Configure sets the namespace that replaces the namespace of every scoped test, and the run id that scopes it. Empty
+// values keep the namespace of the test and disable scoping respectively.
+func Configure(namespaceOverride, scopeRunId string) {
+	mu.Lock()
+	defer mu.Unlock()
+	override = namespaceOverride
+	runId = scopeRunId
+	scoped = map[string]struct{}{}
+}
+
+// Scope returns the namespace a test publishes the metrics of the default namespace to, e.g. CWAgentTest/<run id>
+// when scoped to a run, so concurrent runs on the same account never validate each other's metrics. It is called by
+// whatever writes the namespace the agent publishes to, so Resolve rewrites the default namespace the same way.
+func Scope(defaultNamespace string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	ns := scope(defaultNamespace)
+	if ns != defaultNamespace {
+		scoped[defaultNamespace] = struct{}{}
+	}
+	return ns
+}
+
+// Resolve returns the namespace the metrics of the default namespace are validated in, the one Scope returned when the
+// namespace was scoped. Namespaces which were never scoped are returned as is, e.g. ContainerInsights which the agent
+// of a DaemonSet publishes to whatever run validates it.
+func Resolve(defaultNamespace string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := scoped[defaultNamespace]; !ok {
+		return defaultNamespace
+	}
+	return scope(defaultNamespace)
+}
+
+func scope(defaultNamespace string) string {
+	ns := defaultNamespace
+	if override != "" {
+		ns = override
+	}
+	if runId != "" && ns != "" {
+		ns = ns + "/" + runId
+	}
+	return ns
+}
+
+// IsScoped is true when Scope rewrites namespaces, so agent configs need to be templated with the result
+func IsScoped() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return override != "" || runId != ""
+}
@@ -0,0 +1,41 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package namespace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveOnlyScopedNamespaces(t *testing.T) {
+	Configure("", "run")
+	t.Cleanup(func() { Configure("", "") })
+
+	assert.True(t, IsScoped())
+	assert.Equal(t, "CWAgent", Resolve("CWAgent"))
+	assert.Equal(t, "CWAgent/run", Scope("CWAgent"))
+	assert.Equal(t, "CWAgent/run", Resolve("CWAgent"))
+	// the namespaces the framework never rewrote, e.g. the one of the Container Insights DaemonSet, are left as is
+	assert.Equal(t, "ContainerInsights", Resolve("ContainerInsights"))
+	// the scoped namespace written to a config is not scoped twice when it is validated
+	assert.Equal(t, "CWAgent/run", Resolve(Scope("CWAgent")))
+}
+
+func TestScopeOverride(t *testing.T) {
+	Configure("CWAgentTest", "run")
+	t.Cleanup(func() { Configure("", "") })
+
+	assert.Equal(t, "CWAgentTest/run", Scope("CWAgent"))
+	assert.Equal(t, "CWAgentTest/run", Resolve("CWAgent"))
+	assert.Equal(t, "ContainerInsights", Resolve("ContainerInsights"))
+}
+
+func TestNotScoped(t *testing.T) {
+	Configure("", "")
+
+	assert.False(t, IsScoped())
+	assert.Equal(t, "CWAgent", Scope("CWAgent"))
+	assert.Equal(t, "CWAgent", Resolve("CWAgent"))
+}
@@ -0,0 +1,128 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+// Summary is the result of a suite as posted to the notifiers
+type Summary struct {
+	Suite    string    `json:"suite"`
+	Status   string    `json:"status"`
+	RunId    string    `json:"run_id"`
+	Passed   int       `json:"passed"`
+	Failures []Failure `json:"failures,omitempty"`
+	// AgentVersion is empty when the version of the agent under test is unknown
+	AgentVersion string `json:"agent_version,omitempty"`
+}
+
+// Failure describes a failed runner, with the failed tests and the location of the uploaded artifacts if any
+type Failure struct {
+	Runner string `json:"runner"`
+	Status string `json:"status"`
+	// Category tells infrastructure flakes (throttled, not_found_yet, infra) from product regressions (validation)
+	Category         string   `json:"category,omitempty"`
+	Tests            []string `json:"tests"`
+	ArtifactLocation string   `json:"artifact_location,omitempty"`
+}
+
+type Notifier interface {
+	Notify(s Summary) error
+}
+
+var notifiers []Notifier
+
+// Configure sets the notifiers suite results are sent to. Empty values disable the corresponding notifier.
+func Configure(snsTopicArn, webhookUrl string) {
+	notifiers = nil
+	if snsTopicArn != "" {
+		notifiers = append(notifiers, &SNSNotifier{TopicArn: snsTopicArn})
+	}
+	if webhookUrl != "" {
+		notifiers = append(notifiers, &WebhookNotifier{Url: webhookUrl})
+	}
+}
+
+// Send posts the summary to every configured notifier. Failures to notify are logged and never fail the suite.
+func Send(s Summary) {
+	s.RunId = runid.Get()
+	for _, n := range notifiers {
+		if err := n.Notify(s); err != nil {
+			logger.Errorf("Failed to send notification for suite %s: %v", s.Suite, err)
+		}
+	}
+}
+
+// Text renders the summary for humans, e.g. for email subscriptions or chat
+func (s Summary) Text() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s (run %s, %d passed, %d failed)\n", s.Suite, s.Status, s.RunId, s.Passed, len(s.Failures)))
+	if s.AgentVersion != "" {
+		sb.WriteString(fmt.Sprintf("agent version: %s\n", s.AgentVersion))
+	}
+	for _, f := range s.Failures {
+		status := strings.ToLower(f.Status)
+		if f.Category != "" {
+			status = fmt.Sprintf("%s (%s)", status, f.Category)
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s: %s\n", f.Runner, status, strings.Join(f.Tests, ", ")))
+		if f.ArtifactLocation != "" {
+			sb.WriteString(fmt.Sprintf("  artifacts: %s\n", f.ArtifactLocation))
+		}
+	}
+	return sb.String()
+}
+
+type SNSNotifier struct {
+	TopicArn string
+}
+
+var _ Notifier = (*SNSNotifier)(nil)
+
+func (n *SNSNotifier) Notify(s Summary) error {
+	return awsservice.PublishToTopic(n.TopicArn, fmt.Sprintf("[%s] %s", s.Status, s.Suite), s.Text())
+}
+
+// WebhookNotifier posts the summary as JSON. The "text" field is set so Slack incoming webhooks render the message
+// without any additional configuration.
+type WebhookNotifier struct {
+	Url string
+}
+
+var _ Notifier = (*WebhookNotifier)(nil)
+
+func (n *WebhookNotifier) Notify(s Summary) error {
+	payload := struct {
+		Text string `json:"text"`
+		Summary
+	}{
+		Text:    s.Text(),
+		Summary: s,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(n.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+// Package ports checks the ports the agent listens on are free before a suite starts it, and finds the processes
+// listening on the ones that are not, e.g. an agent or a load generator a previous run left behind. A conflict
+// otherwise shows up much later as missing StatsD, collectd, OTLP or EMF telemetry.
+package ports
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Port is a port the agent listens on
+type Port struct {
+	Name    string
+	Network string // tcp or udp
+	Number  int
+}
+
+func (p Port) String() string {
+	return fmt.Sprintf("%s %d/%s", p.Name, p.Number, p.Network)
+}
+
+// AgentPorts are the ports of the listeners the agent configs of the suites enable
+var AgentPorts = []Port{
+	{Name: "statsd", Network: "udp", Number: 8125},
+	{Name: "collectd", Network: "udp", Number: 25826},
+	{Name: "otlp grpc", Network: "tcp", Number: 4317},
+	{Name: "otlp http", Network: "tcp", Number: 4318},
+	{Name: "emf", Network: "tcp", Number: 25888},
+	{Name: "emf", Network: "udp", Number: 25888},
+}
+
+// Listener is a process listening on a port
+type Listener struct {
+	Pid     int
+	Command string
+}
+
+func (l Listener) String() string {
+	return fmt.Sprintf("%s (pid %d)", l.Command, l.Pid)
+}
+
+// Conflict is a port which is not free, with the processes listening on it when they could be found
+type Conflict struct {
+	Port      Port
+	Err       error
+	Listeners []Listener
+}
+
+func (c Conflict) String() string {
+	if len(c.Listeners) == 0 {
+		return fmt.Sprintf("%s: %v", c.Port, c.Err)
+	}
+	owners := make([]string, len(c.Listeners))
+	for i, l := range c.Listeners {
+		owners[i] = l.String()
+	}
+	return fmt.Sprintf("%s: in use by %s", c.Port, strings.Join(owners, ", "))
+}
+
+// Free returns an error when the port cannot be bound
+func Free(p Port) error {
+	address := fmt.Sprintf(":%d", p.Number)
+	switch p.Network {
+	case "tcp":
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		return l.Close()
+	case "udp":
+		c, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		return c.Close()
+	default:
+		return fmt.Errorf("unknown network %q of %s", p.Network, p.Name)
+	}
+}
+
+// Check returns the ports which are not free, with their listeners
+func Check(ports []Port) []Conflict {
+	var conflicts []Conflict
+	for _, p := range ports {
+		err := Free(p)
+		if err == nil {
+			continue
+		}
+		// a port which cannot be bound is a conflict even when its listeners cannot be found, e.g. without the
+		// permissions to read the fds of their processes
+		listeners, _ := Listeners(p)
+		conflicts = append(conflicts, Conflict{Port: p, Err: err, Listeners: listeners})
+	}
+	return conflicts
+}
+
+// Listeners returns the processes with a socket bound to the port, found through the socket tables and the fds in
+// /proc
+func Listeners(p Port) ([]Listener, error) {
+	inodes := map[string]bool{}
+	for _, table := range []string{p.Network, p.Network + "6"} {
+		f, err := os.Open(filepath.Join("/proc/net", table))
+		if err != nil {
+			// the host may have IPv6 disabled
+			continue
+		}
+		err = socketInodes(f, p, inodes)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(inodes) == 0 {
+		return nil, nil
+	}
+	pids, err := filepath.Glob("/proc/[0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	var listeners []Listener
+	for _, dir := range pids {
+		pid, err := strconv.Atoi(filepath.Base(dir))
+		if err != nil || !hasSocket(dir, inodes) {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join(dir, "comm"))
+		listeners = append(listeners, Listener{Pid: pid, Command: strings.TrimSpace(string(comm))})
+	}
+	sort.Slice(listeners, func(i, j int) bool { return listeners[i].Pid < listeners[j].Pid })
+	return listeners, nil
+}
+
+// Kill kills the listeners of the conflict
+func Kill(c Conflict) error {
+	for _, l := range c.Listeners {
+		if err := syscall.Kill(l.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+			return fmt.Errorf("failed to kill %s listening on %s: %w", l, c.Port, err)
+		}
+	}
+	return nil
+}
+
+const (
+	// tcpListen is the state of a listening tcp socket in /proc/net/tcp
+	tcpListen = "0A"
+	// udpUnconnected is the state of a bound udp socket in /proc/net/udp
+	udpUnconnected = "07"
+)
+
+// socketInodes adds the inodes of the sockets of the table listening on the port, the table is one of /proc/net/tcp,
+// tcp6, udp or udp6
+func socketInodes(r io.Reader, p Port, inodes map[string]bool) error {
+	state := tcpListen
+	if p.Network == "udp" {
+		state = udpUnconnected
+	}
+	scanner := bufio.NewScanner(r)
+	// the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		_, port, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		number, err := strconv.ParseInt(port, 16, 32)
+		if err != nil || int(number) != p.Number {
+			continue
+		}
+		inodes[fields[9]] = true
+	}
+	return scanner.Err()
+}
+
+func hasSocket(procDir string, inodes map[string]bool) bool {
+	fds, err := os.ReadDir(filepath.Join(procDir, "fd"))
+	if err != nil {
+		return false
+	}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(procDir, "fd", fd.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		if inodes[strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package ports
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tcpTable = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:10E1 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:10E1 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 20 4 30 10 -1
+   2: 00000000:10E2 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 100 0 0 10 0
+`
+
+func TestSocketInodes(t *testing.T) {
+	inodes := map[string]bool{}
+	require.NoError(t, socketInodes(strings.NewReader(tcpTable), Port{Network: "tcp", Number: 4321}, inodes))
+	// the established connection to the port is not a listener
+	assert.Equal(t, map[string]bool{"1001": true}, inodes)
+}
+
+func TestCheckTCP(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	p := Port{Name: "test", Network: "tcp", Number: l.Addr().(*net.TCPAddr).Port}
+
+	conflicts := Check([]Port{p})
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, p, conflicts[0].Port)
+	assert.Contains(t, conflicts[0].Listeners, Listener{Pid: os.Getpid(), Command: command(t)})
+	assert.Contains(t, conflicts[0].String(), "in use by")
+
+	require.NoError(t, l.Close())
+	assert.Empty(t, Check([]Port{p}))
+}
+
+func TestCheckUDP(t *testing.T) {
+	c, err := net.ListenPacket("udp", ":0")
+	require.NoError(t, err)
+	p := Port{Name: "test", Network: "udp", Number: c.LocalAddr().(*net.UDPAddr).Port}
+
+	conflicts := Check([]Port{p})
+	require.Len(t, conflicts, 1)
+	assert.Contains(t, conflicts[0].Listeners, Listener{Pid: os.Getpid(), Command: command(t)})
+
+	require.NoError(t, c.Close())
+	assert.Empty(t, Check([]Port{p}))
+}
+
+func TestFreeUnknownNetwork(t *testing.T) {
+	assert.ErrorContains(t, Free(Port{Name: "test", Network: "sctp", Number: 1}), "unknown network")
+}
+
+func command(t *testing.T) string {
+	comm, err := os.ReadFile("/proc/self/comm")
+	if err != nil {
+		t.Skip("no /proc on this host")
+	}
+	return strings.TrimSpace(string(comm))
+}
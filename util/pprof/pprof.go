@@ -0,0 +1,210 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package pprof
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/artifact"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// ErrNotExposed is returned when the agent does not serve pprof, which release builds do not
+var ErrNotExposed = errors.New("the agent does not expose pprof")
+
+var (
+	// address is empty unless -pprofAddress is provided, which disables the capture
+	address     string
+	cpuDuration time.Duration
+	points      []time.Duration
+)
+
+// Configure sets the host:port the agent serves pprof on, how long CPU profiles sample for and the offsets after the
+// agent starts profiles are captured at. A profile is always captured before the agent stops as well.
+func Configure(addr string, cpu time.Duration, capturePoints []time.Duration) {
+	address = addr
+	cpuDuration = cpu
+	points = append([]time.Duration(nil), capturePoints...)
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+}
+
+// ParseCapturePoints parses a comma separated list of durations, ex "5m,30m"
+func ParseCapturePoints(s string) ([]time.Duration, error) {
+	var result []time.Duration
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		d, err := time.ParseDuration(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pprof capture point %q: %w", p, err)
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+// Enabled is true when an address to capture profiles from is configured
+func Enabled() bool {
+	return address != ""
+}
+
+// Capture writes the heap and goroutine profiles and, when a CPU duration is configured, a CPU profile of the agent
+// to dir. The files are named after the label, ex end-heap.pb.gz.
+func Capture(dir string, label string) ([]string, error) {
+	return capture("http://"+address, dir, label, cpuDuration)
+}
+
+func capture(baseUrl string, dir string, label string, cpu time.Duration) ([]string, error) {
+	profiles := map[string]string{
+		"heap":      "/debug/pprof/heap",
+		"goroutine": "/debug/pprof/goroutine",
+	}
+	if cpu > 0 {
+		profiles["cpu"] = fmt.Sprintf("/debug/pprof/profile?seconds=%d", int(cpu.Seconds()))
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var files []string
+	for _, name := range names {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.pb.gz", label, name))
+		if err := download(baseUrl+profiles[name], path, cpu); err != nil {
+			return files, fmt.Errorf("failed to capture the %s profile: %w", name, err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func download(url string, path string, cpu time.Duration) error {
+	client := http.Client{Timeout: cpu + 30*time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotExposed
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+// Session captures profiles at the configured points while the agent runs and uploads them with the artifacts of the
+// run when stopped
+type Session struct {
+	name    string
+	dir     string
+	capture func(label string) ([]string, error)
+
+	// files and notExposed are only written by the capturing goroutine until it is done, then by Stop
+	files []string
+	// notExposed is set once the agent answered it does not serve pprof
+	notExposed bool
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// Start starts capturing the profiles of the agent for the named run. It returns nil when the capture is disabled, a
+// nil Session can still be stopped.
+func Start(name string) *Session {
+	if !Enabled() {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "pprof")
+	if err != nil {
+		logger.Errorf("failed to create the directory for the profiles of %s: %v", name, err)
+		return nil
+	}
+	s := &Session{name: name, dir: dir, done: make(chan struct{})}
+	s.capture = func(label string) ([]string, error) { return Capture(dir, label) }
+	s.start(points)
+	return s
+}
+
+func (s *Session) start(at []time.Duration) {
+	begin := time.Now()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for _, point := range at {
+			select {
+			case <-time.After(time.Until(begin.Add(point))):
+				if !s.record(point.String()) {
+					return
+				}
+			case <-s.done:
+				return
+			}
+		}
+	}()
+}
+
+// record captures the profiles for the label, it stops the session from capturing more when pprof is not exposed
+func (s *Session) record(label string) bool {
+	files, err := s.capture(label)
+	s.files = append(s.files, files...)
+	s.notExposed = errors.Is(err, ErrNotExposed)
+	if s.notExposed {
+		logger.Warnf("Not capturing profiles for %s: %v", s.name, err)
+		return false
+	}
+	if err != nil {
+		logger.Warnf("Failed to capture the %s profiles for %s: %v", label, s.name, err)
+	}
+	return true
+}
+
+// Stop captures the end profiles, which must be done before the agent stops, and uploads every captured profile. It
+// returns the files captured.
+func (s *Session) Stop() []string {
+	if s == nil {
+		return nil
+	}
+	close(s.done)
+	s.wg.Wait()
+	if !s.notExposed {
+		s.record("end")
+	}
+
+	if len(s.files) > 0 {
+		if _, err := artifact.Upload(s.name+"-pprof", s.files); err != nil {
+			logger.Errorf("failed to upload the profiles of %s: %v", s.name, err)
+		}
+	}
+	return s.files
+}
+
+// Cleanup removes the captured profiles from the host
+func (s *Session) Cleanup() {
+	if s == nil {
+		return
+	}
+	os.RemoveAll(s.dir)
+}
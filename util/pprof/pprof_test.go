@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package pprof
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCapturePoints(t *testing.T) {
+	points, err := ParseCapturePoints("5m, 30s,,1h")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Minute, 30 * time.Second, time.Hour}, points)
+
+	points, err = ParseCapturePoints("")
+	require.NoError(t, err)
+	assert.Empty(t, points)
+
+	_, err = ParseCapturePoints("5m,soon")
+	assert.Error(t, err)
+}
+
+func TestCapture(t *testing.T) {
+	var cpuQuery string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/heap", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("heap")) })
+	mux.HandleFunc("/debug/pprof/goroutine", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("goroutine")) })
+	mux.HandleFunc("/debug/pprof/profile", func(w http.ResponseWriter, r *http.Request) {
+		cpuQuery = r.URL.RawQuery
+		w.Write([]byte("cpu"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	files, err := capture(server.URL, dir, "end", 2*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "end-cpu.pb.gz"),
+		filepath.Join(dir, "end-goroutine.pb.gz"),
+		filepath.Join(dir, "end-heap.pb.gz"),
+	}, files)
+	assert.Equal(t, "seconds=2", cpuQuery)
+	content, err := os.ReadFile(files[2])
+	require.NoError(t, err)
+	assert.Equal(t, "heap", string(content))
+
+	files, err = capture(server.URL, dir, "start", 0)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestCaptureNotExposed(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	files, err := capture(server.URL, t.TempDir(), "end", 0)
+	assert.ErrorIs(t, err, ErrNotExposed)
+	assert.Empty(t, files)
+}
+
+func TestSessionCapturesAtPointsAndEnd(t *testing.T) {
+	var labels []string
+	s := &Session{name: "test", done: make(chan struct{})}
+	s.capture = func(label string) ([]string, error) {
+		labels = append(labels, label)
+		return []string{label + "-heap.pb.gz"}, nil
+	}
+	s.start([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond, time.Hour})
+	time.Sleep(100 * time.Millisecond)
+
+	files := s.Stop()
+	assert.Equal(t, []string{"10ms", "20ms", "end"}, labels)
+	assert.Equal(t, []string{"10ms-heap.pb.gz", "20ms-heap.pb.gz", "end-heap.pb.gz"}, files)
+}
+
+func TestSessionStopsWhenNotExposed(t *testing.T) {
+	calls := 0
+	s := &Session{name: "test", done: make(chan struct{})}
+	s.capture = func(label string) ([]string, error) {
+		calls++
+		return nil, ErrNotExposed
+	}
+	s.start([]time.Duration{0, 10 * time.Millisecond})
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Empty(t, s.Stop())
+	assert.Equal(t, 1, calls)
+}
+
+func TestNilSession(t *testing.T) {
+	var s *Session
+	assert.Nil(t, s.Stop())
+	s.Cleanup()
+}
@@ -0,0 +1,142 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package readiness
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/await"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+const pollInterval = 2 * time.Second
+
+// DefaultMarkers are the agent log lines written once telemetry flows, the first batch written by the telegraf outputs.
+// The agent only logs them with agent.debug set, e.g. by -agentDebug. The start of the OTel pipeline is not one, it is
+// logged before anything was sent.
+var DefaultMarkers = []string{
+	"Wrote batch of",
+}
+
+var (
+	// timeout is 0, which disables the probe, unless -agentReadyTimeout is provided
+	timeout time.Duration
+	markers = DefaultMarkers
+
+	// isAgentRunning is replaced by the tests
+	isAgentRunning = common.IsAgentRunning
+)
+
+// Configure sets how long the probe waits for the agent to be ready, 0 disables it, and the log lines that show the
+// agent flushed. Empty markers keep the defaults.
+func Configure(readyTimeout time.Duration, readyMarkers []string) {
+	timeout = readyTimeout
+	markers = DefaultMarkers
+	var configured []string
+	for _, marker := range readyMarkers {
+		if marker = strings.TrimSpace(marker); marker != "" {
+			configured = append(configured, marker)
+		}
+	}
+	if len(configured) > 0 {
+		markers = configured
+	}
+}
+
+// Probe waits for the agent started after it was created, lines the agent logged before are ignored so a previous
+// run never makes the agent look ready
+type Probe struct {
+	logFile string
+	offset  int64
+}
+
+// Begin creates the probe, call it before the agent starts
+func Begin() *Probe {
+	p := &Probe{logFile: common.AgentLogFile}
+	if info, err := os.Stat(p.logFile); err == nil {
+		p.offset = info.Size()
+	}
+	return p
+}
+
+// Wait blocks until the agent is ready, for agents started before the test, e.g. by the terraform of the validator.
+// The whole agent log is searched.
+func Wait() error {
+	return (&Probe{logFile: common.AgentLogFile}).Wait()
+}
+
+// Wait blocks until the agent process is running and has logged one of the markers, or the configured timeout
+// expires. It returns immediately when the probe is disabled.
+func (p *Probe) Wait() error {
+	if timeout <= 0 {
+		return nil
+	}
+	return p.WaitFor(timeout)
+}
+
+// WaitFor blocks until the agent is ready or the timeout expires whether the probe is enabled or not, for the suites
+// which time the startup of the agent
+func (p *Probe) WaitFor(timeout time.Duration) error {
+	start := time.Now()
+	err := await.WaitUntil(context.Background(), pollInterval, timeout, "the agent to be ready", p.isReady)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Agent is ready after %s", time.Since(start).Round(time.Second))
+	return nil
+}
+
+func (p *Probe) isReady() (bool, error) {
+	running, err := isAgentRunning()
+	if err != nil || !running {
+		// the status is not available while the service restarts, which is expected right after the start
+		logger.Debugf("Agent is not running yet: %v", err)
+		return false, nil
+	}
+	return p.hasMarker()
+}
+
+// hasMarker scans the log from the offset. The log starts over when it was rotated, so a log smaller than the offset
+// is scanned from the start.
+func (p *Probe) hasMarker() (bool, error) {
+	f, err := os.Open(p.logFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to open the agent log: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	offset := p.offset
+	if info.Size() < offset {
+		offset = 0
+	}
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, marker := range markers {
+			if strings.Contains(line, marker) {
+				logger.Debugf("Agent ready marker found: %s", line)
+				return true, nil
+			}
+		}
+	}
+	return false, scanner.Err()
+}
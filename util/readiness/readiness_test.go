@@ -0,0 +1,103 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package readiness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLog(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestProbeIgnoresLinesBeforeTheOffset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.log")
+	writeLog(t, path, "2023-06-01T12:00:00Z D! [outputs.cloudwatch] Wrote batch of 10 metrics in 1ms\n")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	probe := &Probe{logFile: path, offset: info.Size()}
+
+	ready, err := probe.hasMarker()
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	writeLog(t, path, "2023-06-01T12:05:00Z I! Loaded outputs: cloudwatch\n")
+	ready, err = probe.hasMarker()
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	// the OTel pipeline starts before anything was sent
+	writeLog(t, path, "2023-06-01T12:05:01Z I! Everything is ready. Begin running and processing data.\n")
+	ready, err = probe.hasMarker()
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	writeLog(t, path, "2023-06-01T12:05:16Z D! [outputs.cloudwatch] Wrote batch of 12 metrics in 30ms\n")
+	ready, err = probe.hasMarker()
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+func TestProbeRescansRotatedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.log")
+	writeLog(t, path, "Wrote batch of 1 metrics\n")
+	probe := &Probe{logFile: path, offset: 4096}
+
+	ready, err := probe.hasMarker()
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+func TestProbeWaitsForTheProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.log")
+	writeLog(t, path, "Wrote batch of 1 metrics\n")
+	original := isAgentRunning
+	defer func() { isAgentRunning = original }()
+	probe := &Probe{logFile: path}
+
+	isAgentRunning = func() (bool, error) { return false, nil }
+	ready, err := probe.isReady()
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	isAgentRunning = func() (bool, error) { return true, nil }
+	ready, err = probe.isReady()
+	require.NoError(t, err)
+	assert.True(t, ready)
+}
+
+func TestWaitForIgnoresConfigure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "amazon-cloudwatch-agent.log")
+	writeLog(t, path, "Wrote batch of 1 metrics\n")
+	original := isAgentRunning
+	defer func() { isAgentRunning = original }()
+	isAgentRunning = func() (bool, error) { return true, nil }
+
+	Configure(0, nil)
+	assert.NoError(t, (&Probe{logFile: path}).WaitFor(time.Second))
+	assert.Error(t, (&Probe{logFile: filepath.Join(t.TempDir(), "missing")}).WaitFor(time.Millisecond))
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(0, nil)
+
+	Configure(0, []string{" ", "Custom marker"})
+	assert.Equal(t, []string{"Custom marker"}, markers)
+	Configure(0, nil)
+	assert.Equal(t, DefaultMarkers, markers)
+
+	// a disabled probe never blocks
+	assert.NoError(t, (&Probe{logFile: "missing"}).Wait())
+}
@@ -0,0 +1,169 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+// Document is the machine-readable result of a suite, as the file, S3 and CloudWatch Logs sinks write it
+type Document struct {
+	Suite  string    `json:"suite"`
+	Status string    `json:"status"`
+	RunId  string    `json:"run_id"`
+	Time   time.Time `json:"time"`
+	// AgentVersion is empty when the version of the agent under test is unknown
+	AgentVersion string   `json:"agent_version,omitempty"`
+	Runners      []Runner `json:"runners"`
+}
+
+// Runner is the result of a runner of the suite
+type Runner struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Category string `json:"category,omitempty"`
+	// DurationSeconds is the time of every attempt of the runner
+	DurationSeconds  float64 `json:"duration_seconds"`
+	Attempts         int     `json:"attempts,omitempty"`
+	ArtifactLocation string  `json:"artifact_location,omitempty"`
+	// StartupSeconds is the time from the agent start to the first visible datapoint or log event by pipeline
+	StartupSeconds map[string]float64 `json:"startup_seconds,omitempty"`
+	Tests          []Test             `json:"tests"`
+}
+
+// Test is the result of a single check of a runner
+type Test struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Category string `json:"category,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Result is the suite result the sinks report. Print renders it for humans, Document for machines.
+type Result interface {
+	Print()
+	Document() Document
+}
+
+type Reporter interface {
+	Report(r Result) error
+}
+
+var reporters = []Reporter{&StdoutReporter{}}
+
+// Configure sets the sinks suite results are reported to. Empty values disable the corresponding sink, the stdout
+// sink is the only one enabled until Configure is called.
+func Configure(stdout bool, file, bucket, logGroup string) {
+	reporters = nil
+	if stdout {
+		reporters = append(reporters, &StdoutReporter{})
+	}
+	if file != "" {
+		reporters = append(reporters, &FileReporter{Path: file})
+	}
+	if bucket != "" {
+		reporters = append(reporters, &S3Reporter{Bucket: bucket})
+	}
+	if logGroup != "" {
+		reporters = append(reporters, &CloudWatchLogsReporter{LogGroup: logGroup})
+	}
+}
+
+// Send reports the result to every configured sink. Failures to report are logged and never fail the suite.
+func Send(r Result) {
+	for _, reporter := range reporters {
+		if err := reporter.Report(r); err != nil {
+			logger.Errorf("Failed to report suite %s: %v", r.Document().Suite, err)
+		}
+	}
+}
+
+// document returns the document of the result stamped with the run, as the machine-readable sinks write it
+func document(r Result) Document {
+	d := r.Document()
+	d.RunId = runid.Get()
+	if d.Time.IsZero() {
+		d.Time = time.Now().UTC()
+	}
+	return d
+}
+
+// StdoutReporter prints the result for humans
+type StdoutReporter struct{}
+
+var _ Reporter = (*StdoutReporter)(nil)
+
+func (*StdoutReporter) Report(r Result) error {
+	r.Print()
+	return nil
+}
+
+// FileReporter writes each document as a line of JSON to the file, so the suites of a run append to the same file
+type FileReporter struct {
+	Path string
+}
+
+var _ Reporter = (*FileReporter)(nil)
+
+func (f *FileReporter) Report(r Result) error {
+	body, err := json.Marshal(document(r))
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(body, '\n'))
+	return err
+}
+
+// S3Reporter uploads each document to reports/<run id>/<suite>.json of the bucket
+type S3Reporter struct {
+	Bucket string
+}
+
+var _ Reporter = (*S3Reporter)(nil)
+
+func (s *S3Reporter) Report(r Result) error {
+	d := document(r)
+	body, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := d.Suite
+	if name == "" {
+		name = "suite"
+	}
+	return awsservice.PutObject(s.Bucket, fmt.Sprintf("reports/%s/%s.json", d.RunId, name), body)
+}
+
+// CloudWatchLogsReporter puts each document as an event of the log stream named after the run, so a Logs Insights
+// query can aggregate the results of many runs
+type CloudWatchLogsReporter struct {
+	LogGroup string
+}
+
+var _ Reporter = (*CloudWatchLogsReporter)(nil)
+
+func (c *CloudWatchLogsReporter) Report(r Result) error {
+	d := document(r)
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return awsservice.PutLogEvent(c.LogGroup, d.RunId, string(body), d.Time)
+}
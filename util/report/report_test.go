@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package report
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+type fakeResult struct {
+	printed int
+	doc     Document
+}
+
+func (f *fakeResult) Print() {
+	f.printed++
+}
+
+func (f *fakeResult) Document() Document {
+	return f.doc
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(true, "", "", "")
+
+	Configure(true, "", "", "")
+	require.Len(t, reporters, 1)
+	assert.IsType(t, &StdoutReporter{}, reporters[0])
+
+	Configure(false, "/tmp/report.json", "bucket", "group")
+	require.Len(t, reporters, 3)
+	assert.Equal(t, &FileReporter{Path: "/tmp/report.json"}, reporters[0])
+	assert.Equal(t, &S3Reporter{Bucket: "bucket"}, reporters[1])
+	assert.Equal(t, &CloudWatchLogsReporter{LogGroup: "group"}, reporters[2])
+}
+
+func TestSendToStdoutAndFile(t *testing.T) {
+	defer Configure(true, "", "", "")
+	path := filepath.Join(t.TempDir(), "reports", "results.json")
+	Configure(true, path, "", "")
+
+	r := &fakeResult{doc: Document{
+		Suite:  "StatsDTestSuite",
+		Status: "FAILED",
+		Runners: []Runner{{
+			Name:     "StatsdRunner",
+			Status:   "FAILED",
+			Category: "validation",
+			Tests:    []Test{{Name: "statsd_counter", Status: "FAILED", Category: "validation", Reason: "no datapoints"}},
+		}},
+	}}
+	Send(r)
+	r.doc.Suite = "EMFTestSuite"
+	r.doc.Status = "SUCCESSFUL"
+	r.doc.Runners = nil
+	Send(r)
+	assert.Equal(t, 2, r.printed)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	var docs []Document
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var d Document
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &d))
+		docs = append(docs, d)
+	}
+	require.Len(t, docs, 2, "every suite of the run appends a line")
+	assert.Equal(t, "StatsDTestSuite", docs[0].Suite)
+	assert.Equal(t, runid.Get(), docs[0].RunId)
+	assert.False(t, docs[0].Time.IsZero())
+	assert.Equal(t, "no datapoints", docs[0].Runners[0].Tests[0].Reason)
+	assert.Equal(t, "EMFTestSuite", docs[1].Suite)
+}
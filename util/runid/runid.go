@@ -0,0 +1,81 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package runid identifies a test run. The id is embedded in the names of the resources the tests create and in the
+// keys of the artifacts they upload, so the resources of a run can be found from their names alone.
+package runid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Env lets CI share one run id across every test process of a workflow
+	Env = "CWA_TEST_RUN_ID"
+	// NamePrefix starts the name of every resource named with Name
+	NamePrefix = "cwagent-integ-test-"
+)
+
+// pattern is the unix time the run started and a random suffix. The time has 10 digits until 2286, so the ids sort
+// by the time the runs started.
+var pattern = regexp.MustCompile(`(\d{10})-([0-9a-f]{8})$`)
+
+var (
+	once sync.Once
+	id   string
+)
+
+// Get returns the id of the current test run, which is read from CWA_TEST_RUN_ID or generated once per process
+func Get() string {
+	once.Do(func() {
+		id = os.Getenv(Env)
+		if id == "" {
+			id = New(time.Now())
+		}
+	})
+	return id
+}
+
+// New generates the id of a run started at the time
+func New(start time.Time) string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// the suffix only tells apart runs started in the same second
+		copy(suffix, strconv.FormatInt(start.UnixNano(), 16))
+	}
+	return fmt.Sprintf("%d-%s", start.Unix(), hex.EncodeToString(suffix))
+}
+
+// Parse returns the time the run started. Ids from CWA_TEST_RUN_ID that were not generated with New can't be parsed.
+func Parse(runId string) (time.Time, error) {
+	match := pattern.FindStringSubmatch(runId)
+	if match == nil || match[0] != runId {
+		return time.Time{}, fmt.Errorf("%q is not a run id", runId)
+	}
+	seconds, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// Name names a resource of the kind for the current run, e.g. cwagent-integ-test-alarm-<run id>
+func Name(kind string) string {
+	return NamePrefix + kind + "-" + Get()
+}
+
+// FromName returns the run id a resource was named with by Name, false when the name has none
+func FromName(name string) (string, bool) {
+	match := pattern.FindStringSubmatch(name)
+	if match == nil || len(name) == len(match[0]) || name[len(name)-len(match[0])-1] != '-' {
+		return "", false
+	}
+	return match[0], true
+}
@@ -0,0 +1,57 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package runid
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAndParse(t *testing.T) {
+	start := time.Date(2023, 1, 24, 21, 34, 45, 0, time.UTC)
+	runId := New(start)
+	assert.Regexp(t, `^1674596085-[0-9a-f]{8}$`, runId)
+	assert.NotEqual(t, runId, New(start))
+
+	parsed, err := Parse(runId)
+	require.NoError(t, err)
+	assert.True(t, parsed.Equal(start))
+
+	for _, invalid := range []string{"", "ci-run-42", "1674596085-XYZ", "x1674596085-0123abcd", "1674596085-0123abcd-"} {
+		_, err = Parse(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestNewSortsByStart(t *testing.T) {
+	start := time.Date(2023, 1, 24, 21, 34, 45, 0, time.UTC)
+	ids := []string{New(start.Add(time.Hour)), New(start), New(start.Add(time.Minute))}
+	sort.Strings(ids)
+	for i, want := range []time.Time{start, start.Add(time.Minute), start.Add(time.Hour)} {
+		parsed, err := Parse(ids[i])
+		require.NoError(t, err)
+		assert.True(t, parsed.Equal(want))
+	}
+}
+
+func TestNameAndFromName(t *testing.T) {
+	t.Setenv(Env, "1674596085-0123abcd")
+	once.Do(func() {})
+	id = "1674596085-0123abcd"
+
+	name := Name("alarm")
+	assert.Equal(t, "cwagent-integ-test-alarm-1674596085-0123abcd", name)
+	runId, ok := FromName(name)
+	assert.True(t, ok)
+	assert.Equal(t, "1674596085-0123abcd", runId)
+
+	for _, other := range []string{"cwagent-integ-test-alarm", "1674596085-0123abcd", "alarm1674596085-0123abcd", "my-alarm"} {
+		_, ok = FromName(other)
+		assert.False(t, ok, other)
+	}
+}
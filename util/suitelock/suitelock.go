@@ -0,0 +1,102 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package suitelock serializes the exclusive suites of concurrent CI runs in the same account, e.g. the alarm suite,
+// which would otherwise validate each other's side effects.
+package suitelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/runid"
+)
+
+const (
+	// ttl is how long a lock outlives a run that aborted without releasing it
+	ttl = 10 * time.Minute
+	// renewInterval keeps the lock of a run that is still going from expiring
+	renewInterval = ttl / 3
+	pollInterval  = 15 * time.Second
+)
+
+var (
+	// table is empty unless -suiteLockTable is provided, which disables the locking
+	table   string
+	maxWait = 30 * time.Minute
+)
+
+// Configure sets the table the locks are written to and how long Acquire waits for a lock held by another run
+func Configure(tableName string, wait time.Duration) {
+	table = tableName
+	maxWait = wait
+}
+
+// Acquire waits until the lock is free, takes it and keeps renewing it until the returned release is called. Without
+// a configured table it does not lock and returns a release that does nothing.
+func Acquire(name string) (release func(), err error) {
+	if table == "" {
+		return func() {}, nil
+	}
+
+	lock := awsservice.SuiteLock{LockName: name, Owner: owner()}
+	deadline := time.Now().Add(maxWait)
+	for {
+		lock.ExpiresAt = time.Now().Add(ttl).Unix()
+		err = awsservice.AcquireSuiteLock(table, lock)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, awsservice.ErrSuiteLockHeld) {
+			return nil, fmt.Errorf("failed to acquire suite lock %s: %w", name, err)
+		}
+		holder, held, _ := awsservice.GetSuiteLock(table, name)
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("suite lock %s is still held by %s after waiting %s", name, holder.Owner, maxWait)
+		}
+		if held {
+			logger.Infof("Waiting for suite lock %s held by %s until %s", name, holder.Owner, time.Unix(holder.ExpiresAt, 0).Format(time.RFC3339))
+		}
+		time.Sleep(pollInterval)
+	}
+	logger.Infof("Acquired suite lock %s as %s", name, lock.Owner)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				lock.ExpiresAt = time.Now().Add(ttl).Unix()
+				if err := awsservice.AcquireSuiteLock(table, lock); err != nil {
+					logger.Errorf("Failed to renew suite lock %s: %v", name, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		if err := awsservice.ReleaseSuiteLock(table, name, lock.Owner); err != nil {
+			logger.Errorf("Failed to release suite lock %s, it expires at %s: %v", name, time.Unix(lock.ExpiresAt, 0).Format(time.RFC3339), err)
+			return
+		}
+		logger.Infof("Released suite lock %s", name)
+	}, nil
+}
+
+// owner tells apart the processes of a run, which share the run id when CI runs them in parallel
+func owner() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s/%s/%d", runid.Get(), hostname, os.Getpid())
+}
@@ -0,0 +1,29 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package watchdog
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+)
+
+// sampleAgent reads the main pid and the restart count systemd keeps of the agent unit, the pid is zero while the
+// unit is not running
+func sampleAgent() (Process, error) {
+	properties, err := common.GetAgentServiceProperties("MainPID", "NRestarts")
+	if err != nil {
+		return Process{}, err
+	}
+	pid, err := strconv.Atoi(properties["MainPID"])
+	if err != nil {
+		return Process{}, fmt.Errorf("unexpected MainPID %q of the agent unit: %w", properties["MainPID"], err)
+	}
+	// NRestarts is missing on the older versions of systemd, the pid still tells a restart
+	restarts, _ := strconv.Atoi(properties["NRestarts"])
+	return Process{Pid: pid, Restarts: restarts}, nil
+}
@@ -0,0 +1,14 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package watchdog
+
+import (
+	"errors"
+)
+
+func sampleAgent() (Process, error) {
+	return Process{}, errors.New("watching the agent process is not supported on windows")
+}
@@ -0,0 +1,162 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+// Package watchdog detects the agent restarting or crashing while a runner runs. A restarted agent can still
+// deliver values which validate, so the runners fail on the restart itself.
+package watchdog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+)
+
+// excerptLines is how many lines of the agent output an error of Check quotes
+const excerptLines = 20
+
+// failureMarkers are what the agent and systemd log when the agent crashes or exits
+var failureMarkers = []string{"panic:", "fatal error:", "E!", "Main process exited", "Failed with result"}
+
+// Process identifies the agent process, a restart changes the pid or the restart count systemd keeps of the unit
+type Process struct {
+	// Pid is zero when the agent is not running
+	Pid      int
+	Restarts int
+}
+
+// Running is true when the agent process was found
+func (p Process) Running() bool {
+	return p.Pid > 0
+}
+
+func (p Process) String() string {
+	if !p.Running() {
+		return "not running"
+	}
+	return fmt.Sprintf("pid %d, %d restarts", p.Pid, p.Restarts)
+}
+
+// Event is a change of the agent process between two samples
+type Event struct {
+	Time time.Time
+	From Process
+	To   Process
+}
+
+func (e Event) String() string {
+	what := "restarted"
+	if !e.To.Running() {
+		what = "stopped"
+	}
+	return fmt.Sprintf("%s the agent %s (%s -> %s)", e.Time.Format(time.RFC3339), what, e.From, e.To)
+}
+
+var interval = 5 * time.Second
+
+// Watchdog samples the agent process in the background and records every change of it
+type Watchdog struct {
+	sample func() (Process, error)
+
+	mu      sync.Mutex
+	started bool
+	last    Process
+	events  []Event
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Start records the agent process as it is now, and the changes of it until Stop
+func Start() *Watchdog {
+	return start(sampleAgent, interval)
+}
+
+func start(sample func() (Process, error), every time.Duration) *Watchdog {
+	w := &Watchdog{sample: sample, done: make(chan struct{})}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			w.record()
+			select {
+			case <-ticker.C:
+			case <-w.done:
+				return
+			}
+		}
+	}()
+	return w
+}
+
+func (w *Watchdog) record() {
+	p, err := w.sample()
+	if err != nil {
+		logger.Warnf("failed to sample the agent process: %v", err)
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started && p != w.last {
+		e := Event{Time: time.Now(), From: w.last, To: p}
+		logger.Errorf("Watchdog: %s", e)
+		w.events = append(w.events, e)
+	}
+	w.started = true
+	w.last = p
+}
+
+// Stop takes a last sample, so a crash right before it is not missed, and returns the changes of the agent process
+func (w *Watchdog) Stop() []Event {
+	if w == nil {
+		return nil
+	}
+	close(w.done)
+	w.wg.Wait()
+	w.record()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.events
+}
+
+// Check returns a validation error listing the events with an excerpt of the agent output, or nil without events
+func Check(events []Event, agentOutput string) error {
+	if len(events) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(events))
+	for i, e := range events {
+		descriptions[i] = e.String()
+	}
+	return errclass.Validationf("the agent restarted or crashed while the runner ran: %s\nagent log:\n%s",
+		strings.Join(descriptions, "; "), Excerpt(agentOutput))
+}
+
+// Excerpt returns the last lines of the agent output which report a failure, or its last lines when none does
+func Excerpt(agentOutput string) string {
+	var all, failures []string
+	for _, line := range strings.Split(strings.TrimSpace(agentOutput), "\n") {
+		if line == "" {
+			continue
+		}
+		all = append(all, line)
+		for _, marker := range failureMarkers {
+			if strings.Contains(line, marker) {
+				failures = append(failures, line)
+				break
+			}
+		}
+	}
+	lines := failures
+	if len(lines) == 0 {
+		lines = all
+	}
+	if len(lines) > excerptLines {
+		lines = lines[len(lines)-excerptLines:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,84 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package watchdog
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/errclass"
+)
+
+func TestWatchdog(t *testing.T) {
+	var calls int64
+	w := start(func() (Process, error) {
+		switch n := atomic.AddInt64(&calls, 1); {
+		case n == 2:
+			return Process{}, errors.New("systemctl is not available")
+		case n < 4:
+			return Process{Pid: 100}, nil
+		default:
+			return Process{Pid: 200, Restarts: 1}, nil
+		}
+	}, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	events := w.Stop()
+
+	require.Len(t, events, 1, "a failed sample is not a restart")
+	assert.Equal(t, Process{Pid: 100}, events[0].From)
+	assert.Equal(t, Process{Pid: 200, Restarts: 1}, events[0].To)
+
+	var stopped *Watchdog
+	assert.Empty(t, stopped.Stop())
+}
+
+func TestWatchdogCrashBeforeStop(t *testing.T) {
+	var crashed int32
+	w := start(func() (Process, error) {
+		if atomic.LoadInt32(&crashed) == 1 {
+			return Process{}, nil
+		}
+		return Process{Pid: 100}, nil
+	}, time.Hour)
+	time.Sleep(5 * time.Millisecond)
+	atomic.StoreInt32(&crashed, 1)
+
+	events := w.Stop()
+	require.Len(t, events, 1)
+	assert.False(t, events[0].To.Running())
+	assert.Contains(t, events[0].String(), "stopped")
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check(nil, "anything"))
+
+	output := strings.Join([]string{
+		"I! [agent] Config has been loaded",
+		"panic: runtime error: invalid memory address or nil pointer dereference",
+		"amazon-cloudwatch-agent.service: Main process exited, code=exited, status=2/INVALIDARGUMENT",
+		"I! [agent] Starting AmazonCloudWatchAgent",
+	}, "\n")
+	err := Check([]Event{{From: Process{Pid: 100}, To: Process{Pid: 200, Restarts: 1}}}, output)
+	assert.Equal(t, errclass.Validation, errclass.Classify(err))
+	assert.Contains(t, err.Error(), "pid 100, 0 restarts -> pid 200, 1 restarts")
+	assert.Contains(t, err.Error(), "panic: runtime error")
+	assert.NotContains(t, err.Error(), "Starting AmazonCloudWatchAgent")
+}
+
+func TestExcerpt(t *testing.T) {
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, "I! line")
+	}
+	lines = append(lines, "I! last line")
+	excerpt := Excerpt(strings.Join(lines, "\n") + "\n")
+	assert.Len(t, strings.Split(excerpt, "\n"), excerptLines)
+	assert.True(t, strings.HasSuffix(excerpt, "I! last line"))
+}
@@ -11,12 +11,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/test/ground_truth"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/iis"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/nvidia_gpu"
 	"github.com/aws/amazon-cloudwatch-agent-test/test/restart"
+	"github.com/aws/amazon-cloudwatch-agent-test/test/service_recovery"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/artifact"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/pprof"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/readiness"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators"
+	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/performance"
 )
 
 var (
@@ -24,11 +32,26 @@ var (
 	preparationMode = flag.Bool("preparation-mode", false, "Prepare all the resources for the validation (e.g set up config) ")
 	testName        = flag.String("test-name", "", "Test name to execute")
 	assumeRoleArn   = flag.String("role-arn", "", "Arn for assume IAM role if any")
+	artifactBucket  = flag.String("artifact-bucket", "", "s3 bucket the captured agent profiles are uploaded to")
+	pprofAddress    = flag.String("pprof-address", "", "host:port a debug build of the agent serves pprof on, profiles are captured while the load runs when set")
+	pprofCpu        = flag.Duration("pprof-cpu-duration", 30*time.Second, "How long each CPU profile samples the agent for")
+	pprofPoints     = flag.String("pprof-capture-points", "", "Comma delimited offsets after the load starts to capture profiles at, ex 5m,30m")
+	resultsDir      = flag.String("results-dir", "", "Directory the performance results are written to as CSV and Markdown tables")
+	agentReady      = flag.Duration("agent-ready-timeout", 0, "How long to wait for the agent on the host to run and flush before the load starts, 0 does not wait")
 )
 
 func main() {
 	flag.Parse()
 
+	capturePoints, err := pprof.ParseCapturePoints(*pprofPoints)
+	if err != nil {
+		logger.Fatalf("Failed to parse the pprof capture points: %v", err)
+	}
+	artifact.SetBucket(*artifactBucket)
+	pprof.Configure(*pprofAddress, *pprofCpu, capturePoints)
+	performance.SetExportDir(*resultsDir)
+	readiness.Configure(*agentReady, nil)
+
 	startTime := time.Now()
 
 	// validator calls test code to get around OOM issue on windows hosts while running go test
@@ -43,6 +66,12 @@ func main() {
 			err = restart.Validate()
 		case "nvidia_gpu":
 			err = nvidia_gpu.Validate()
+		case "iis":
+			err = iis.Validate()
+		case "service_recovery":
+			err = service_recovery.Validate()
+		case "ground_truth":
+			err = ground_truth.Validate()
 		}
 
 		if err != nil {
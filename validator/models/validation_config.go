@@ -13,6 +13,8 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
+
+	"github.com/aws/amazon-cloudwatch-agent-test/util/load"
 )
 
 var supportedReceivers = []string{"logs", "statsd", "collectd", "system", "emf"}
@@ -31,6 +33,7 @@ type ValidateConfig interface {
 	GetLogValidation() []LogValidation
 	GetCommitInformation() (string, int64)
 	GetUniqueID() string
+	GetLoadMix() []load.Spec
 }
 
 type validatorConfig struct {
@@ -45,6 +48,9 @@ type validatorConfig struct {
 	ValuesPerMinute       string `yaml:"values_per_minute"`       // Number of metrics to be sent or number of log lines to write
 	AgentCollectionPeriod int    `yaml:"agent_collection_period"` // Number of seconds the agent should run and collect the metrics
 
+	// LoadMix replaces the load of the receiver and values_per_minute with the generators it declares, when set
+	LoadMix []load.Spec `yaml:"load_mix"`
+
 	ConfigPath string `yaml:"cloudwatch_agent_config"`
 
 	MetricNamespace  string             `yaml:"metric_namespace"`
@@ -60,8 +66,23 @@ type MetricValidation struct {
 	MetricDimension   []MetricDimension `yaml:"metric_dimension"`
 	MetricValue       float64           `yaml:"metric_value"`
 	MetricSampleCount int               `yaml:"metric_sample_count"`
+	// Bounds are checked by the performance validator when set
+	Bounds *MetricBounds `yaml:"bounds"`
+}
+
+// MetricBounds are the values a metric may have, between Min and Max, or within TolerancePercent of Expected. Expected
+// is read from the host when ExpectedFrom is "host", e.g. the memory of the instance for mem_total.
+type MetricBounds struct {
+	Min              *float64 `yaml:"min"`
+	Max              *float64 `yaml:"max"`
+	Expected         float64  `yaml:"expected"`
+	ExpectedFrom     string   `yaml:"expected_from"`
+	TolerancePercent float64  `yaml:"tolerance_percent"`
 }
 
+// ExpectedFromHost reads the expected value of the metric from the host
+const ExpectedFromHost = "host"
+
 type LogValidation struct {
 	LogValue  string `yaml:"log_value"`
 	LogLines  int    `yaml:"log_lines"`
@@ -171,3 +192,8 @@ func (v *validatorConfig) GetCommitInformation() (string, int64) {
 func (v *validatorConfig) GetUniqueID() string {
 	return uuid.NewString()
 }
+
+// GetLoadMix returns the generators to send the load with instead of the receiver, empty when there are none
+func (v *validatorConfig) GetLoadMix() []load.Spec {
+	return v.LoadMix
+}
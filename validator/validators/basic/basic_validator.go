@@ -15,6 +15,8 @@ import (
 
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/common"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/load"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/util"
 )
@@ -45,6 +47,10 @@ func (s *BasicValidator) GenerateLoad() error {
 		receiver              = s.vConfig.GetPluginsConfig()[0]
 	)
 
+	if specs := s.vConfig.GetLoadMix(); len(specs) > 0 {
+		return generateLoadMix(specs, agentCollectionPeriod)
+	}
+
 	switch dataType {
 	case "logs":
 		return common.StartLogWrite(agentConfigFilePath, agentCollectionPeriod, metricSendingInterval, dataRate)
@@ -54,6 +60,27 @@ func (s *BasicValidator) GenerateLoad() error {
 	}
 }
 
+// generateLoadMix runs the generators of the specs in the background for the duration and logs what each of them sent
+func generateLoadMix(specs []load.Spec, duration time.Duration) error {
+	mix, err := load.NewMix(specs)
+	if err != nil {
+		return err
+	}
+	if err = mix.Start(); err != nil {
+		return err
+	}
+	go func() {
+		time.Sleep(duration)
+		if err := mix.Stop(); err != nil {
+			logger.Errorf("Failed to stop the load mix: %v", err)
+		}
+		for name, stats := range mix.Stats() {
+			logger.Infof("Load %s: %s", name, stats)
+		}
+	}()
+	return nil
+}
+
 func (s *BasicValidator) CheckData(startTime, endTime time.Time) error {
 	var (
 		multiErr         error
@@ -110,7 +137,7 @@ func (s *BasicValidator) ValidateLogs(logStream, logLine, logLevel, logSource st
 		logGroup = awsservice.GetInstanceId()
 	)
 	log.Printf("Start to validate log '%s' with number of logs lines %d within log group %s, log stream %s, start time %v and end time %v", logLine, numberOfLogLine, logGroup, logStream, startTime, endTime)
-	ok, err := awsservice.ValidateLogs(logGroup, logStream, &startTime, &endTime, func(logs []string) bool {
+	ok, err := awsservice.ValidateLogs(logGroup, logStream, &startTime, &endTime, awsservice.StringValidator(func(logs []string) bool {
 		if len(logs) < 1 {
 			return false
 		}
@@ -129,7 +156,7 @@ func (s *BasicValidator) ValidateLogs(logStream, logLine, logLevel, logSource st
 		}
 
 		return numberOfLogLine <= actualNumberOfLogLines
-	})
+	}))
 
 	if !ok || err != nil {
 		return fmt.Errorf("\n the number of log line for '%s' is %d which does not match the actual number with log group %s, log stream %s, start time %v and end time %v with err %v", logLine, numberOfLogLine, logGroup, logStream, startTime, endTime, err)
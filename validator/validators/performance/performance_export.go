@@ -0,0 +1,181 @@
+// Copyright Amazon.com, Inc. or its affiliates. All Rights Reserved.
+// SPDX-License-Identifier: MIT
+
+package performance
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StepMetrics are the columns of the per TPS step tables, the CPU and memory of the agent
+var StepMetrics = []string{"procstat_cpu_usage", "procstat_memory_rss"}
+
+// exportDir is empty unless -results-dir is provided, which disables the export
+var exportDir string
+
+// SetExportDir configures the directory the CSV and Markdown results of the performance validator are written to
+func SetExportDir(dir string) {
+	exportDir = dir
+}
+
+// Results are the statistics of each metric keyed by the data rate (TPS) they were measured at, as in the Results
+// of PerformanceInformation
+type Results map[string]map[string]Stats
+
+// WriteMetricsCSV writes one row per metric and TPS step with the statistics of it
+func WriteMetricsCSV(w io.Writer, results Results) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"metric", "tps", "min", "avg", "max", "p99", "std"}); err != nil {
+		return err
+	}
+	for _, metric := range results.metrics() {
+		for _, rate := range results.rates() {
+			stats, ok := results[rate][metric]
+			if !ok {
+				continue
+			}
+			row := []string{metric, rate, formatValue(stats.Min), formatValue(stats.Average), formatValue(stats.Max), formatValue(stats.P99), formatValue(stats.Std)}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteStepsCSV writes one row per TPS step with the average of each of the metrics
+func WriteStepsCSV(w io.Writer, results Results, metrics []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"tps"}, metrics...)); err != nil {
+		return err
+	}
+	for _, rate := range results.rates() {
+		if err := cw.Write(append([]string{rate}, results.averages(rate, metrics)...)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes the table of the statistics per metric followed by the table of the averages per TPS step, for
+// pasting into release comparisons
+func WriteMarkdown(w io.Writer, results Results, metrics []string) error {
+	var sb strings.Builder
+	sb.WriteString("| Metric | TPS | Min | Avg | Max | P99 |\n")
+	sb.WriteString("|---|---:|---:|---:|---:|---:|\n")
+	for _, metric := range results.metrics() {
+		for _, rate := range results.rates() {
+			stats, ok := results[rate][metric]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n", metric, rate, formatValue(stats.Min),
+				formatValue(stats.Average), formatValue(stats.Max), formatValue(stats.P99))
+		}
+	}
+
+	sb.WriteString("\n| TPS |")
+	for _, metric := range metrics {
+		fmt.Fprintf(&sb, " %s |", metric)
+	}
+	sb.WriteString("\n|---:|" + strings.Repeat("---:|", len(metrics)) + "\n")
+	for _, rate := range results.rates() {
+		fmt.Fprintf(&sb, "| %s | %s |\n", rate, strings.Join(results.averages(rate, metrics), " | "))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// exportResults writes <name>-metrics.csv, <name>-steps.csv and <name>.md to the export directory
+func exportResults(name string, results Results) error {
+	if exportDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return err
+	}
+	writers := map[string]func(io.Writer) error{
+		name + "-metrics.csv": func(w io.Writer) error { return WriteMetricsCSV(w, results) },
+		name + "-steps.csv":   func(w io.Writer) error { return WriteStepsCSV(w, results, StepMetrics) },
+		name + ".md":          func(w io.Writer) error { return WriteMarkdown(w, results, StepMetrics) },
+	}
+	for file, write := range writers {
+		if err := writeFile(filepath.Join(exportDir, file), write); err != nil {
+			return fmt.Errorf("failed to export %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rates returns the TPS steps in numeric order, the ones that are not numbers after them in lexical order
+func (r Results) rates() []string {
+	rates := make([]string, 0, len(r))
+	for rate := range r {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		a, errA := strconv.ParseFloat(rates[i], 64)
+		b, errB := strconv.ParseFloat(rates[j], 64)
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		default:
+			return rates[i] < rates[j]
+		}
+	})
+	return rates
+}
+
+func (r Results) metrics() []string {
+	seen := map[string]bool{}
+	var metrics []string
+	for _, stats := range r {
+		for metric := range stats {
+			if !seen[metric] {
+				seen[metric] = true
+				metrics = append(metrics, metric)
+			}
+		}
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// averages returns the average of each metric at the rate, empty for the ones not measured
+func (r Results) averages(rate string, metrics []string) []string {
+	values := make([]string, len(metrics))
+	for i, metric := range metrics {
+		if stats, ok := r[rate][metric]; ok {
+			values[i] = formatValue(stats.Average)
+		}
+	}
+	return values
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -22,4 +22,6 @@ type PerformanceInformation map[string]interface{}
 		"CollectionPeriod": collectionPeriod,
 		"InstanceAMI":      instanceAMI,
 		"InstanceType":     instanceType,
+		// The generators and their profiles when the load_mix of the validator config is set (e.g statsd 1000/1m ramp)
+		"LoadMix":          loadMix,
 */
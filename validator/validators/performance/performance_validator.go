@@ -6,6 +6,7 @@ package performance
 import (
 	"fmt"
 	"log"
+	"math"
 	"strings"
 	"time"
 
@@ -15,7 +16,10 @@ import (
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/test/metric"
 	"github.com/aws/amazon-cloudwatch-agent-test/util/awsservice"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/groundtruth"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/logger"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/basic"
 )
@@ -55,6 +59,12 @@ func (s *PerformanceValidator) CheckData(startTime, endTime time.Time) error {
 		return err
 	}
 
+	dataRate := fmt.Sprint(s.vConfig.GetDataRate())
+	results := Results{dataRate: perfInfo["Results"].(map[string]interface{})[dataRate].(map[string]Stats)}
+	if err = exportResults(fmt.Sprintf("%s-%s", s.vConfig.GetTestCase(), dataRate), results); err != nil {
+		logger.Errorf("Failed to export the performance results: %v", err)
+	}
+
 	err = s.SendPacketToDatabase(perfInfo)
 	if err != nil {
 		return err
@@ -123,12 +133,24 @@ func (s *PerformanceValidator) CalculateMetricStatsAndPackMetrics(metrics []type
 		if !isAllValuesGreaterThanOrEqualToZero(metricValues) {
 			return nil, fmt.Errorf("\n values are not all greater than or equal to zero for metric %s with values: %v", metricName, metricValues)
 		}
+		if err := s.checkBounds(metricName, metricValues); err != nil {
+			return nil, err
+		}
 		metricStats := CalculateMetricStatisticsBasedOnDataAndPeriod(metricValues, agentCollectionPeriod)
 		log.Printf("Finished calculate metric statictics for metric %s: %v \n", metricName, metricStats)
 		performanceMetricResults[metricName] = metricStats
 	}
 
-	return packIntoPerformanceInformation(uniqueID, receiver, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults}), nil
+	perfInfo := packIntoPerformanceInformation(uniqueID, receiver, dataType, fmt.Sprint(agentCollectionPeriod), commitHash, commitDate, map[string]interface{}{dataRate: performanceMetricResults})
+	// the load mix and the profiles of it are recorded so results of different traffic shapes are not compared
+	if specs := s.vConfig.GetLoadMix(); len(specs) > 0 {
+		loadMix := make([]string, len(specs))
+		for i, spec := range specs {
+			loadMix[i] = spec.String()
+		}
+		perfInfo["LoadMix"] = loadMix
+	}
+	return perfInfo, nil
 }
 
 func (s *PerformanceValidator) GetPerformanceMetrics(startTime, endTime time.Time) ([]types.MetricDataResult, error) {
@@ -202,6 +224,57 @@ func packIntoPerformanceInformation(uniqueID, receiver, dataType, collectionPeri
 	}
 }
 
+// checkBounds checks the values, after the conversion to MB, against the bounds of the metric validation if it has any
+func (s *PerformanceValidator) checkBounds(metricName string, values []float64) error {
+	for _, validation := range s.vConfig.GetMetricValidation() {
+		if validation.MetricName != metricName || validation.Bounds == nil {
+			continue
+		}
+		bounds, err := resolveBounds(metricName, *validation.Bounds)
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			if !bounds.Contains(value) {
+				return fmt.Errorf("expected %s for metric %s, got values: %v", bounds, metricName, values)
+			}
+		}
+		logger.Infof("Values of metric %s are %s", metricName, bounds)
+	}
+	return nil
+}
+
+// resolveBounds reads the expected value from the host when the bounds ask for it, in the unit the validator reports
+// the metric in
+func resolveBounds(metricName string, b models.MetricBounds) (metric.Bounds, error) {
+	if b.TolerancePercent > 0 {
+		expected := b.Expected
+		if b.ExpectedFrom == models.ExpectedFromHost {
+			sample, err := groundtruth.Read()
+			if err != nil {
+				return metric.Bounds{}, fmt.Errorf("failed to read the expected value of metric %s from the host: %w", metricName, err)
+			}
+			value, ok := sample.Values[metricName]
+			if !ok {
+				return metric.Bounds{}, fmt.Errorf("the host does not report metric %s", metricName)
+			}
+			if slices.Contains(metricsConvertToMB, metricName) {
+				value = value / (1024 * 1024)
+			}
+			expected = value
+		}
+		return metric.Within(expected, b.TolerancePercent), nil
+	}
+	bounds := metric.Bounds{Min: math.Inf(-1), Max: math.Inf(1)}
+	if b.Min != nil {
+		bounds.Min = *b.Min
+	}
+	if b.Max != nil {
+		bounds.Max = *b.Max
+	}
+	return bounds, nil
+}
+
 func isAllValuesGreaterThanOrEqualToZero(values []float64) bool {
 	if len(values) == 0 {
 		return false
@@ -8,6 +8,8 @@ import (
 	"log"
 	"time"
 
+	"github.com/aws/amazon-cloudwatch-agent-test/util/pprof"
+	"github.com/aws/amazon-cloudwatch-agent-test/util/readiness"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/models"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/feature"
 	"github.com/aws/amazon-cloudwatch-agent-test/validator/validators/performance"
@@ -30,6 +32,11 @@ func NewValidator(vConfig models.ValidateConfig) (validator models.ValidatorFact
 }
 
 func LaunchValidator(vConfig models.ValidateConfig) error {
+	// the validation window only starts once the agent flushed, data it did not send yet would fail the validation
+	if err := readiness.Wait(); err != nil {
+		return err
+	}
+
 	var (
 		agentCollectionPeriod    = vConfig.GetAgentCollectionPeriod()
 		startTimeValidation      = time.Now().Truncate(time.Minute).Add(time.Minute)
@@ -46,13 +53,17 @@ func LaunchValidator(vConfig models.ValidateConfig) error {
 	time.Sleep(durationBeforeNextMinute)
 
 	log.Printf("Start to generate load in %f s for the agent to collect and send all the metrics to CloudWatch within the datapoint period ", agentCollectionPeriod.Seconds())
+	profiles := pprof.Start(vConfig.GetTestCase())
+	defer profiles.Cleanup()
 	err = validator.GenerateLoad()
 	if err != nil {
+		profiles.Stop()
 		return err
 
 	}
 
 	time.Sleep(agentCollectionPeriod)
+	profiles.Stop()
 	log.Printf("Start to sleep 120s for CloudWatch to process all the metrics")
 	time.Sleep(2 * time.Minute)
 